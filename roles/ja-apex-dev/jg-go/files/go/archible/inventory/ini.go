@@ -0,0 +1,344 @@
+package inventory
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// iniSection is the kind of an INI section.
+type iniSection int
+
+const (
+	// sectionHosts lists hosts, the default before any header.
+	sectionHosts iniSection = iota
+	sectionVars
+	sectionChildren
+)
+
+// iniParser holds the state of ParseINI.
+type iniParser struct {
+	inv     *Inventory
+	group   string
+	section iniSection
+}
+
+// ParseINI adds the hosts and groups of an INI inventory. As in Ansible,
+// variables on host lines are literals, so that port=22 is an integer and
+// debug=True a boolean, while values in [group:vars] sections are strings.
+func (inv *Inventory) ParseINI(r io.Reader) error {
+	p := iniParser{inv: inv}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' || text[0] == ';' {
+			continue
+		}
+
+		if err := p.parseLine(text); err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("inventory: %w", err)
+	}
+
+	return nil
+}
+
+// parseLine reads a line of the current section.
+func (p *iniParser) parseLine(text string) error {
+	if strings.HasPrefix(text, "[") {
+		return p.parseHeader(text)
+	}
+
+	switch p.section {
+	case sectionVars:
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", text)
+		}
+
+		group := p.inv.AddGroup(p.group)
+		group.Vars[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+
+		return nil
+	case sectionChildren:
+		return p.inv.AddChild(p.group, firstField(text))
+	default:
+		return p.parseHost(text)
+	}
+}
+
+// parseHeader starts a [group], [group:vars] or [group:children] section.
+func (p *iniParser) parseHeader(text string) error {
+	name, ok := strings.CutSuffix(text[1:], "]")
+	if !ok || name == "" {
+		return fmt.Errorf("bad section %q", text)
+	}
+
+	p.section = sectionHosts
+
+	switch group, kind, _ := strings.Cut(name, ":"); kind {
+	case "":
+	case "vars":
+		p.section, name = sectionVars, group
+	case "children":
+		p.section, name = sectionChildren, group
+	default:
+		return fmt.Errorf("unknown section type %q", kind)
+	}
+
+	p.group = name
+	p.inv.AddGroup(name)
+
+	return nil
+}
+
+// parseHost reads a host line: a host pattern followed by variables.
+func (p *iniParser) parseHost(text string) error {
+	fields, err := splitFields(text)
+	if err != nil {
+		return err
+	}
+
+	names, err := expand(fields[0])
+	if err != nil {
+		return err
+	}
+
+	vars := make(map[string]any)
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", field)
+		}
+
+		vars[key] = literal(value)
+	}
+
+	for _, name := range names {
+		var groups []string
+		if p.group != "" {
+			groups = []string{p.group}
+		}
+
+		maps.Copy(p.inv.AddHost(name, groups...).Vars, vars)
+	}
+
+	return nil
+}
+
+// splitFields splits a line on blanks outside of quotes, up to a comment.
+func splitFields(text string) ([]string, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quote  byte
+	)
+
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			quote = closeQuote(quote, c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			i = len(text)
+
+			continue
+		case c == ' ' || c == '\t':
+			fields = appendField(fields, &field)
+
+			continue
+		}
+
+		field.WriteByte(text[i])
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", text)
+	}
+
+	return appendField(fields, &field), nil
+}
+
+// closeQuote returns the quote still open after c.
+func closeQuote(quote, c byte) byte {
+	if c == quote {
+		return 0
+	}
+
+	return quote
+}
+
+// appendField moves the pending field, if any, to fields.
+func appendField(fields []string, field *strings.Builder) []string {
+	if field.Len() == 0 {
+		return fields
+	}
+
+	fields = append(fields, field.String())
+	field.Reset()
+
+	return fields
+}
+
+// firstField returns the first field of a line, such as the group name
+// of a [group:children] line.
+func firstField(text string) string {
+	fields, err := splitFields(text)
+	if err != nil || len(fields) == 0 {
+		return text
+	}
+
+	return fields[0]
+}
+
+// literal interprets a host variable the way Python literals are: quoted
+// strings, integers, floats and booleans, anything else being a string.
+func literal(value string) any {
+	if unquoted := unquote(value); unquoted != value {
+		return unquoted
+	}
+
+	if i, err := strconv.Atoi(value); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+
+	switch value {
+	case "True", "true":
+		return true
+	case "False", "false":
+		return false
+	default:
+		return value
+	}
+}
+
+// unquote strips matching single or double quotes.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') &&
+		value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// expand expands the ranges of a host pattern, such as web[01:03] or
+// db-[a:c], with an optional step as in node[1:9:2].
+func expand(pattern string) ([]string, error) {
+	start := strings.IndexByte(pattern, '[')
+	if start < 0 {
+		return []string{pattern}, nil
+	}
+
+	end := strings.IndexByte(pattern[start:], ']')
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated range in %q", pattern)
+	}
+
+	end += start
+
+	values, err := rangeValues(pattern[start+1 : end])
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", pattern, err)
+	}
+
+	suffixes, err := expand(pattern[end+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values)*len(suffixes))
+
+	for _, value := range values {
+		for _, suffix := range suffixes {
+			names = append(names, pattern[:start]+value+suffix)
+		}
+	}
+
+	return names, nil
+}
+
+// rangeValues lists the values of a first:last[:step] range of numbers,
+// zero-padded when first is, or of single letters.
+func rangeValues(spec string) ([]string, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("bad range [%s]", spec)
+	}
+
+	step := 1
+	if len(parts) == 3 {
+		var err error
+		if step, err = strconv.Atoi(parts[2]); err != nil || step < 1 {
+			return nil, fmt.Errorf("bad step in [%s]", spec)
+		}
+	}
+
+	first, last := parts[0], parts[1]
+	if isLetter(first) && isLetter(last) {
+		return letters(first[0], last[0], step), nil
+	}
+
+	values, ok := numbers(first, last, step)
+	if !ok {
+		return nil, fmt.Errorf("bad range [%s]", spec)
+	}
+
+	return values, nil
+}
+
+// numbers lists the numbers from first to last, keeping the width of
+// first when it has leading zeros.
+func numbers(first, last string, step int) ([]string, bool) {
+	low, err := strconv.Atoi(first)
+	if err != nil {
+		return nil, false
+	}
+
+	high, err := strconv.Atoi(last)
+	if err != nil || high < low {
+		return nil, false
+	}
+
+	width := 0
+	if len(first) > 1 && first[0] == '0' {
+		width = len(first)
+	}
+
+	var values []string
+	for i := low; i <= high; i += step {
+		values = append(values, fmt.Sprintf("%0*d", width, i))
+	}
+
+	return values, true
+}
+
+// letters lists the letters from first to last.
+func letters(first, last byte, step int) []string {
+	var values []string
+	for c := int(first); c <= int(last); c += step {
+		values = append(values, string(rune(c)))
+	}
+
+	return values
+}
+
+// isLetter reports whether s is a single ASCII letter.
+func isLetter(s string) bool {
+	return len(s) == 1 &&
+		((s[0] >= 'a' && s[0] <= 'z') || (s[0] >= 'A' && s[0] <= 'Z'))
+}
@@ -0,0 +1,106 @@
+package inventory_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/inventory"
+)
+
+const iniInventory = `# Production hosts
+bastion ansible_host=203.0.113.1
+
+[webservers]
+web[01:03].example.com http_port=8080 tls=True
+proxy ansible_user='deploy # user' # A comment
+
+[dbservers]
+db01 ansible_port=2222 weight=0.5
+db02
+
+[staging]
+web03.example.com
+db02
+
+[datacenter:children]
+webservers
+dbservers
+
+[datacenter:vars]
+ntp_server=ntp.example.com
+mtu = 9000
+`
+
+func parseINI(t *testing.T) *inventory.Inventory {
+	t.Helper()
+
+	inv := inventory.New()
+	require.NoError(t, inv.ParseINI(strings.NewReader(iniInventory)))
+
+	return inv
+}
+
+func TestParseINI(t *testing.T) {
+	t.Parallel()
+
+	inv := parseINI(t)
+
+	assert.Equal(t, []string{
+		"bastion", "web01.example.com", "web02.example.com",
+		"web03.example.com", "proxy", "db01", "db02",
+	}, inv.Hosts())
+	assert.Equal(t, []string{"bastion"}, inv.HostsOf(inventory.Ungrouped))
+
+	web, ok := inv.Host("web02.example.com")
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"http_port": 8080, "tls": true}, web.Vars)
+
+	proxy, _ := inv.Host("proxy")
+	assert.Equal(t, "deploy # user", proxy.Vars["ansible_user"])
+
+	db, _ := inv.Host("db01")
+	assert.Equal(t, map[string]any{"ansible_port": 2222, "weight": 0.5},
+		db.Vars)
+
+	dc, ok := inv.Group("datacenter")
+	require.True(t, ok)
+	assert.Equal(t, []string{"webservers", "dbservers"}, dc.Children)
+	assert.Equal(t, map[string]any{
+		"ntp_server": "ntp.example.com", "mtu": "9000",
+	}, dc.Vars, "group variables are strings")
+}
+
+func TestParseINIErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"[web",
+		"[web:hosts]",
+		"[web:vars]\nnot an assignment",
+		"host key",
+		"host key='unterminated",
+		"web[1:x]",
+		"web[3:1]",
+		"web[1:2",
+		"[a:children]\nb\n[b:children]\na",
+	} {
+		err := inventory.New().ParseINI(strings.NewReader(text))
+		require.ErrorIs(t, err, inventory.ErrSyntax, text)
+	}
+}
+
+func TestHostRanges(t *testing.T) {
+	t.Parallel()
+
+	inv := inventory.New()
+	require.NoError(t, inv.ParseINI(strings.NewReader(
+		"node[1:5:2]-[a:b]\n[x]\nrack[08:10]\n")))
+
+	assert.Equal(t, []string{
+		"node1-a", "node1-b", "node3-a", "node3-b", "node5-a", "node5-b",
+		"rack08", "rack09", "rack10",
+	}, inv.Hosts())
+}
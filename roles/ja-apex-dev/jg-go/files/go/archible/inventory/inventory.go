@@ -0,0 +1,353 @@
+// Package inventory parses Ansible inventories in the INI and YAML formats
+// and matches host patterns against them, so that Go tooling operates on
+// the same hosts as the playbooks. Groups, their children and the
+// variables set in inventory files are resolved as Ansible does; variables
+// from group_vars and host_vars directories are loaded by archible/vars,
+// with GroupsOf giving the groups of a host in the required order.
+package inventory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrSyntax is returned for malformed inventory files.
+	ErrSyntax = errors.New("inventory: syntax error")
+	// ErrCycle is returned when a group would become its own descendant.
+	ErrCycle = errors.New("inventory: group cycle")
+)
+
+// Implicit groups of every inventory.
+const (
+	// All holds every host.
+	All = "all"
+	// Ungrouped holds the hosts without a group other than all.
+	Ungrouped = "ungrouped"
+)
+
+// ignored are the suffixes of files skipped when loading a directory, as
+// by the Ansible inventory plugins.
+var ignored = []string{"~", ".orig", ".bak", ".cfg", ".retry", ".pyc", ".pyo",
+	".md", ".rst", ".txt"}
+
+// Host is a managed host.
+type Host struct {
+	Name string
+	// Vars are the variables set for the host in inventory files.
+	Vars map[string]any
+
+	groups []string
+}
+
+// Group is a named set of hosts and child groups.
+type Group struct {
+	Name string
+	// Vars are the variables set for the group in inventory files.
+	Vars map[string]any
+	// Hosts and Children are the direct members, in inventory order.
+	Hosts    []string
+	Children []string
+
+	parents []string
+}
+
+// Inventory is a set of hosts and groups.
+type Inventory struct {
+	hosts  map[string]*Host
+	order  []string
+	groups map[string]*Group
+}
+
+// New returns an empty inventory with the implicit groups.
+func New() *Inventory {
+	inv := &Inventory{
+		hosts: make(map[string]*Host), groups: make(map[string]*Group),
+	}
+	inv.AddGroup(All)
+	inv.AddGroup(Ungrouped)
+
+	return inv
+}
+
+// Load parses inventory files into a single inventory. Directories are
+// read in lexical order, skipping hidden entries, group_vars and host_vars
+// directories and documentation or backup files. Files ending with .yml,
+// .yaml or .json are parsed as YAML and the others as INI.
+func Load(paths ...string) (*Inventory, error) {
+	inv := New()
+
+	for _, root := range paths {
+		if err := inv.loadTree(root); err != nil {
+			return nil, fmt.Errorf("inventory: %w", err)
+		}
+	}
+
+	return inv, nil
+}
+
+// loadTree parses the file root or the files below the directory root.
+func (inv *Inventory) loadTree(root string) error {
+	return filepath.WalkDir(root,
+		func(path string, entry fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case path != root && skipped(entry):
+				return skip(entry)
+			case entry.IsDir():
+				return nil
+			default:
+				return inv.loadFile(path)
+			}
+		})
+}
+
+func (inv *Inventory) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml", ".json":
+		err = inv.ParseYAML(data)
+	default:
+		err = inv.ParseINI(bytes.NewReader(data))
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+func skipped(entry fs.DirEntry) bool {
+	name := entry.Name()
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	if entry.IsDir() {
+		return name == "group_vars" || name == "host_vars"
+	}
+
+	return slices.ContainsFunc(ignored, func(suffix string) bool {
+		return strings.HasSuffix(name, suffix)
+	})
+}
+
+// skip ignores an entry, with everything below it for directories.
+func skip(entry fs.DirEntry) error {
+	if entry.IsDir() {
+		return fs.SkipDir
+	}
+
+	return nil
+}
+
+// AddHost adds a host, or returns the existing one, and makes it a member
+// of the groups, which are created as needed.
+func (inv *Inventory) AddHost(name string, groups ...string) *Host {
+	host, ok := inv.hosts[name]
+	if !ok {
+		host = &Host{Name: name, Vars: make(map[string]any)}
+		inv.hosts[name] = host
+		inv.order = append(inv.order, name)
+	}
+
+	for _, group := range groups {
+		if group == All || group == Ungrouped ||
+			slices.Contains(host.groups, group) {
+			continue
+		}
+
+		g := inv.AddGroup(group)
+		g.Hosts = append(g.Hosts, name)
+		host.groups = append(host.groups, group)
+	}
+
+	return host
+}
+
+// AddGroup adds a group, or returns the existing one.
+func (inv *Inventory) AddGroup(name string) *Group {
+	group, ok := inv.groups[name]
+	if !ok {
+		group = &Group{Name: name, Vars: make(map[string]any)}
+		inv.groups[name] = group
+	}
+
+	return group
+}
+
+// AddChild makes child, created as needed, a child group of parent.
+func (inv *Inventory) AddChild(parent, child string) error {
+	if child == All || parent == child || inv.isAncestor(child, parent) {
+		return fmt.Errorf("%w: %s in %s", ErrCycle, child, parent)
+	}
+
+	p, c := inv.AddGroup(parent), inv.AddGroup(child)
+	if !slices.Contains(p.Children, child) {
+		p.Children = append(p.Children, child)
+		c.parents = append(c.parents, parent)
+	}
+
+	return nil
+}
+
+// isAncestor reports whether group is an ancestor of name.
+func (inv *Inventory) isAncestor(group, name string) bool {
+	g, ok := inv.groups[name]
+	if !ok {
+		return false
+	}
+
+	for _, parent := range g.parents {
+		if parent == group || inv.isAncestor(group, parent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Host returns the named host.
+func (inv *Inventory) Host(name string) (*Host, bool) {
+	host, ok := inv.hosts[name]
+
+	return host, ok
+}
+
+// Group returns the named group.
+func (inv *Inventory) Group(name string) (*Group, bool) {
+	group, ok := inv.groups[name]
+
+	return group, ok
+}
+
+// Hosts returns the names of all hosts in inventory order.
+func (inv *Inventory) Hosts() []string {
+	return slices.Clone(inv.order)
+}
+
+// Groups returns the names of all groups, sorted.
+func (inv *Inventory) Groups() []string {
+	return slices.Sorted(maps.Keys(inv.groups))
+}
+
+// HostsOf returns the hosts of a group and of its descendants, in
+// inventory order.
+func (inv *Inventory) HostsOf(group string) []string {
+	switch group {
+	case All:
+		return inv.Hosts()
+	case Ungrouped:
+		return slices.DeleteFunc(inv.Hosts(), func(name string) bool {
+			return len(inv.hosts[name].groups) > 0
+		})
+	}
+
+	members := make(map[string]bool)
+	inv.collect(group, members)
+
+	return inv.ordered(members)
+}
+
+func (inv *Inventory) collect(group string, members map[string]bool) {
+	g, ok := inv.groups[group]
+	if !ok {
+		return
+	}
+
+	for _, host := range g.Hosts {
+		members[host] = true
+	}
+
+	for _, child := range g.Children {
+		inv.collect(child, members)
+	}
+}
+
+// ordered returns the hosts of a set in inventory order.
+func (inv *Inventory) ordered(set map[string]bool) []string {
+	return slices.DeleteFunc(inv.Hosts(), func(name string) bool {
+		return !set[name]
+	})
+}
+
+// GroupsOf returns the groups of a host, with their ancestors, in the
+// order of increasing precedence used by Ansible: "all" first, then by
+// depth in the group tree and by name.
+func (inv *Inventory) GroupsOf(host string) []string {
+	h, ok := inv.hosts[host]
+	if !ok {
+		return nil
+	}
+
+	depths := map[string]int{}
+
+	for _, group := range h.groups {
+		inv.depths(group, depths)
+	}
+
+	if len(depths) == 0 {
+		depths[Ungrouped] = 1
+	}
+
+	groups := slices.Collect(maps.Keys(depths))
+	slices.SortFunc(groups, func(a, b string) int {
+		if depths[a] != depths[b] {
+			return depths[a] - depths[b]
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return append([]string{All}, groups...)
+}
+
+// depths records group and its ancestors with their depth below "all",
+// the length of the longest path to a top-level group plus one.
+func (inv *Inventory) depths(group string, depths map[string]int) int {
+	if depth, ok := depths[group]; ok {
+		return depth
+	}
+
+	depth := 1
+	for _, parent := range inv.groups[group].parents {
+		if parent != All {
+			depth = max(depth, inv.depths(parent, depths)+1)
+		}
+	}
+
+	depths[group] = depth
+
+	return depth
+}
+
+// Vars returns the inventory variables of a host: those of its groups in
+// the order of GroupsOf, overridden by its own.
+func (inv *Inventory) Vars(host string) map[string]any {
+	h, ok := inv.hosts[host]
+	if !ok {
+		return nil
+	}
+
+	vars := make(map[string]any)
+	for _, group := range inv.GroupsOf(host) {
+		maps.Copy(vars, inv.groups[group].Vars)
+	}
+
+	maps.Copy(vars, h.Vars)
+
+	return vars
+}
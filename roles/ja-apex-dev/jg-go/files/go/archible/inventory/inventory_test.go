@@ -0,0 +1,62 @@
+package inventory_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/inventory"
+	"example.com/go-template/util/testx"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"10-hosts": "[web]\nweb01\n",
+		"20-cloud.yml": "all:\n  children:\n    web:\n" +
+			"      hosts:\n        web02:\n",
+		"README.md":                "[docs]\nnot-a-host\n",
+		".hidden":                  "[hidden]\nghost\n",
+		"group_vars/web.yml":       "x: 1\n",
+		"host_vars/web01/main.yml": "y: 1\n",
+	})
+
+	inv, err := inventory.Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web01", "web02"}, inv.HostsOf("web"))
+	assert.Equal(t, []string{"all", "ungrouped", "web"}, inv.Groups())
+
+	_, err = inventory.Load(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+
+	broken := testx.TempDirWithFiles(t, map[string]string{"hosts": "[x"})
+	_, err = inventory.Load(filepath.Join(broken, "hosts"))
+	require.ErrorIs(t, err, inventory.ErrSyntax)
+}
+
+func TestGroupsOf(t *testing.T) {
+	t.Parallel()
+
+	inv := inventory.New()
+	inv.AddHost("h", "leaf", "top")
+	require.NoError(t, inv.AddChild("top", "mid"))
+	require.NoError(t, inv.AddChild("mid", "leaf"))
+	require.ErrorIs(t, inv.AddChild("leaf", "top"), inventory.ErrCycle)
+
+	top, _ := inv.Group("top")
+	top.Vars["level"] = "top"
+	leaf, _ := inv.Group("leaf")
+	leaf.Vars["level"] = "leaf"
+
+	assert.Equal(t, []string{"all", "top", "mid", "leaf"}, inv.GroupsOf("h"))
+	assert.Equal(t, "leaf", inv.Vars("h")["level"])
+
+	solo := inventory.New()
+	solo.AddHost("s")
+	assert.Equal(t, []string{"all", "ungrouped"}, solo.GroupsOf("s"))
+	assert.Nil(t, inv.GroupsOf("missing"))
+	assert.Nil(t, inv.Vars("missing"))
+}
@@ -0,0 +1,153 @@
+package inventory
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Match returns the hosts selected by an Ansible host pattern, in
+// inventory order. Terms are separated by commas, or colons when there is
+// no comma, and are group or host names, globs such as web*, or regular
+// expressions prefixed with "~". Terms prefixed with "&" intersect the
+// selection and those prefixed with "!" exclude hosts; as in Ansible, the
+// plain terms are applied first, then the intersections and exclusions,
+// and a pattern without plain terms starts from all hosts. For instance,
+// "webservers:&staging:!db01" selects the staging web servers but db01.
+// Terms matching nothing select nothing.
+func (inv *Inventory) Match(pattern string) ([]string, error) {
+	plain, intersections, exclusions := splitPattern(pattern)
+	if len(plain) == 0 {
+		plain = []string{All}
+	}
+
+	selected := make(map[string]bool)
+
+	err := inv.apply(plain, func(hosts map[string]bool) {
+		maps.Copy(selected, hosts)
+	})
+	if err == nil {
+		err = inv.apply(intersections, func(hosts map[string]bool) {
+			maps.DeleteFunc(selected, func(host string, _ bool) bool {
+				return !hosts[host]
+			})
+		})
+	}
+
+	if err == nil {
+		err = inv.apply(exclusions, func(hosts map[string]bool) {
+			maps.DeleteFunc(selected, func(host string, _ bool) bool {
+				return hosts[host]
+			})
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return inv.ordered(selected), nil
+}
+
+// apply calls fn with the hosts of each term.
+func (inv *Inventory) apply(terms []string, fn func(map[string]bool)) error {
+	for _, term := range terms {
+		hosts, err := inv.resolve(term)
+		if err != nil {
+			return err
+		}
+
+		fn(hosts)
+	}
+
+	return nil
+}
+
+// splitPattern sorts the terms of a pattern by kind, without their
+// prefixes.
+func splitPattern(pattern string) (plain, intersections, exclusions []string) {
+	separator := ":"
+	if strings.Contains(pattern, ",") {
+		separator = ","
+	}
+
+	for _, term := range strings.Split(pattern, separator) {
+		switch term = strings.TrimSpace(term); {
+		case term == "":
+		case term[0] == '&':
+			intersections = append(intersections, term[1:])
+		case term[0] == '!':
+			exclusions = append(exclusions, term[1:])
+		default:
+			plain = append(plain, term)
+		}
+	}
+
+	return plain, intersections, exclusions
+}
+
+// resolve returns the hosts of a single term: the members of the matching
+// groups and the matching hosts.
+func (inv *Inventory) resolve(term string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+
+	if term == All || term == "*" {
+		inv.collectAll(All, selected)
+
+		return selected, nil
+	}
+
+	match, err := matcher(term)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range inv.groups {
+		if match(name) {
+			inv.collectAll(name, selected)
+		}
+	}
+
+	for name := range inv.hosts {
+		if match(name) {
+			selected[name] = true
+		}
+	}
+
+	return selected, nil
+}
+
+// collectAll adds the hosts of a group, implicit ones included, to set.
+func (inv *Inventory) collectAll(group string, set map[string]bool) {
+	for _, host := range inv.HostsOf(group) {
+		set[host] = true
+	}
+}
+
+// matcher returns the name test of a term.
+func matcher(term string) (func(string) bool, error) {
+	if expr, ok := strings.CutPrefix(term, "~"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("inventory: pattern %q: %w", term, err)
+		}
+
+		return re.MatchString, nil
+	}
+
+	if !strings.ContainsAny(term, "*?[") {
+		return func(name string) bool { return name == term }, nil
+	}
+
+	if _, err := path.Match(term, ""); err != nil {
+		return nil, fmt.Errorf("inventory: pattern %q: %w", term, err)
+	}
+
+	return func(name string) bool {
+		ok, _ := path.Match(term, name)
+
+		return ok
+	}, nil
+}
@@ -0,0 +1,48 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	inv := parseINI(t)
+
+	webs := []string{
+		"web01.example.com", "web02.example.com", "web03.example.com",
+		"proxy",
+	}
+
+	cases := map[string][]string{
+		"all":                         inv.Hosts(),
+		"webservers:&staging":         {"web03.example.com"},
+		"datacenter:!staging":         {webs[0], webs[1], "proxy", "db01"},
+		"webservers:&staging:!web03*": nil,
+		"db*,bastion":                 {"bastion", "db01", "db02"},
+		"~web0[12]":                   webs[:2],
+		"!datacenter":                 {"bastion"},
+		"missing":                     nil,
+		"dbservers:webservers":        append(webs, "db01", "db02"),
+	}
+
+	for pattern, want := range cases {
+		hosts, err := inv.Match(pattern)
+		require.NoError(t, err, pattern)
+
+		if want == nil {
+			assert.Empty(t, hosts, pattern)
+		} else {
+			assert.Equal(t, want, hosts, pattern)
+		}
+	}
+
+	_, err := inv.Match("~(")
+	require.Error(t, err)
+
+	_, err = inv.Match("web[")
+	require.Error(t, err)
+}
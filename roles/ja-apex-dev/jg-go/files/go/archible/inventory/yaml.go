@@ -0,0 +1,117 @@
+package inventory
+
+import (
+	"fmt"
+	"maps"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseYAML adds the hosts and groups of a YAML or JSON inventory, a
+// mapping of groups with optional hosts, vars and children keys:
+//
+//	all:
+//	  hosts:
+//	    db01: {ansible_host: 10.0.0.5}
+//	  children:
+//	    webservers:
+//	      hosts:
+//	        web[01:03]:
+//	      vars:
+//	        http_port: 80
+func (inv *Inventory) ParseYAML(data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	return eachPair(root.Content[0], func(name string, node *yaml.Node) error {
+		return inv.parseGroup(name, node)
+	})
+}
+
+// parseGroup reads the definition of a group.
+func (inv *Inventory) parseGroup(name string, node *yaml.Node) error {
+	inv.AddGroup(name)
+
+	return eachPair(node, func(key string, value *yaml.Node) error {
+		switch key {
+		case "hosts":
+			return eachPair(value, func(pattern string, vars *yaml.Node) error {
+				return inv.parseHosts(name, pattern, vars)
+			})
+		case "vars":
+			return decodeVars(value, inv.groups[name].Vars)
+		case "children":
+			return eachPair(value, func(child string, group *yaml.Node) error {
+				if err := inv.AddChild(name, child); err != nil {
+					return err
+				}
+
+				return inv.parseGroup(child, group)
+			})
+		default:
+			return fmt.Errorf("%w: line %d: unknown key %q in group %s",
+				ErrSyntax, value.Line, key, name)
+		}
+	})
+}
+
+// parseHosts adds the hosts of a pattern to group with their variables.
+func (inv *Inventory) parseHosts(group, pattern string, node *yaml.Node) error {
+	names, err := expand(pattern)
+	if err != nil {
+		return fmt.Errorf("%w: line %d: %w", ErrSyntax, node.Line, err)
+	}
+
+	for _, name := range names {
+		groups := []string{group}
+		if group == All {
+			groups = nil
+		}
+
+		host := inv.AddHost(name, groups...)
+		if err := decodeVars(node, host.Vars); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeVars merges a mapping of variables, which may be empty, into vars.
+func decodeVars(node *yaml.Node, vars map[string]any) error {
+	var decoded map[string]any
+	if err := node.Decode(&decoded); err != nil {
+		return fmt.Errorf("%w: line %d: %w", ErrSyntax, node.Line, err)
+	}
+
+	maps.Copy(vars, decoded)
+
+	return nil
+}
+
+// eachPair calls fn for the entries of a mapping node in document order.
+// Null nodes, as left by empty keys, have no entries.
+func eachPair(node *yaml.Node, fn func(string, *yaml.Node) error) error {
+	if node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: line %d: expected a mapping",
+			ErrSyntax, node.Line)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if err := fn(node.Content[i].Value, node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
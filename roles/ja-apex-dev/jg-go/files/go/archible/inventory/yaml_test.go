@@ -0,0 +1,73 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/inventory"
+)
+
+const yamlInventory = `
+all:
+  hosts:
+    bastion:
+      ansible_host: 203.0.113.1
+  vars:
+    ansible_user: admin
+  children:
+    webservers:
+      hosts:
+        web[01:02]:
+          http_port: 80
+      vars:
+        ansible_user: deploy
+      children:
+        canary:
+          hosts:
+            web02:
+              http_port: 8080
+    dbservers:
+      hosts:
+        db01:
+`
+
+func TestParseYAML(t *testing.T) {
+	t.Parallel()
+
+	inv := inventory.New()
+	require.NoError(t, inv.ParseYAML([]byte(yamlInventory)))
+
+	assert.Equal(t, []string{"bastion", "web01", "web02", "db01"},
+		inv.Hosts())
+	assert.Equal(t, []string{"web01", "web02"}, inv.HostsOf("webservers"))
+	assert.Equal(t, []string{"bastion"}, inv.HostsOf(inventory.Ungrouped))
+	assert.Equal(t, []string{"all", "webservers", "canary"},
+		inv.GroupsOf("web02"))
+
+	assert.Equal(t, map[string]any{
+		"ansible_user": "deploy", "http_port": 8080,
+	}, inv.Vars("web02"))
+	assert.Equal(t, map[string]any{
+		"ansible_user": "admin", "ansible_host": "203.0.113.1",
+	}, inv.Vars("bastion"))
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"all: [a, b]",
+		"all:\n  host:\n    a:",
+		"all:\n  hosts:\n    a: [1]",
+		"all:\n  hosts:\n    web[1:",
+		"all:\n  children:\n    all:",
+		"all: {",
+	} {
+		err := inventory.New().ParseYAML([]byte(text))
+		require.Error(t, err, text)
+	}
+
+	require.NoError(t, inventory.New().ParseYAML(nil))
+}
@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tag is a Jinja delimiter pair found in a template: an expression
+// ({{ }}), a statement ({% %}) or a comment ({# #}).
+type tag struct {
+	opening string
+	body    string
+	line    int
+}
+
+// delimiters maps the opening delimiters of Jinja to their closing ones.
+var delimiters = map[string]string{"{{": "}}", "{%": "%}", "{#": "#}"}
+
+// endraw closes a {% raw %} block, whose content is not parsed.
+var endraw = regexp.MustCompile(`\{%[-+]?\s*endraw\s*[-+]?%\}`)
+
+// syntaxError reports a template that cannot be analyzed.
+type syntaxError struct {
+	line    int
+	message string
+}
+
+// Error implements error.
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.message)
+}
+
+// tags splits text into its expression and statement tags.
+func tags(text string) ([]tag, error) {
+	var found []tag
+
+	for offset := 0; ; {
+		t, next, err := readTag(text, offset)
+		if err != nil || next < 0 {
+			return found, err
+		}
+
+		offset = next
+
+		switch {
+		case t.opening == "{#":
+		case t.opening == "{%" && firstWord(t.body) == "raw":
+			if offset, err = rawEnd(text, offset, t.line); err != nil {
+				return nil, err
+			}
+		default:
+			found = append(found, t)
+		}
+	}
+}
+
+// readTag reads the first tag at or after offset and returns it with the
+// index after it, or -1 when there is none left.
+func readTag(text string, offset int) (tag, int, error) {
+	start := nextOpening(text, offset)
+	if start < 0 {
+		return tag{}, -1, nil
+	}
+
+	opening := text[start : start+2]
+	line := 1 + strings.Count(text[:start], "\n")
+
+	end := closing(text, start+2, opening)
+	if end < 0 {
+		return tag{}, 0, &syntaxError{line, "unterminated " + opening}
+	}
+
+	body := trimControl(text[start+2 : end])
+
+	return tag{opening, body, line}, end + 2, nil
+}
+
+// closing returns the index of the delimiter closing opening at or after
+// from, skipping the string literals of expressions and statements, which
+// may contain delimiters of their own as in "{{ '{{x}}' }}".
+func closing(text string, from int, opening string) int {
+	delimiter := delimiters[opening]
+
+	for i := from; i+1 < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], delimiter):
+			return i
+		case opening != "{#" && (text[i] == '"' || text[i] == '\''):
+			i = stringEnd(text, i) - 1
+		}
+	}
+
+	return -1
+}
+
+// rawEnd returns the index after the endraw tag closing the raw block
+// that starts at offset.
+func rawEnd(text string, offset, line int) (int, error) {
+	end := endraw.FindStringIndex(text[offset:])
+	if end == nil {
+		return 0, &syntaxError{line, "unterminated raw block"}
+	}
+
+	return offset + end[1], nil
+}
+
+// nextOpening returns the index of the first opening delimiter at or
+// after offset, or -1.
+func nextOpening(text string, offset int) int {
+	for i := offset; i+1 < len(text); i++ {
+		if text[i] == '{' && strings.ContainsRune("{%#", rune(text[i+1])) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// trimControl drops the whitespace control marks and spaces of a body.
+func trimControl(body string) string {
+	return strings.TrimSpace(strings.Trim(body, "-+"))
+}
+
+func firstWord(body string) string {
+	word, _, _ := strings.Cut(body, " ")
+
+	return word
+}
+
+// token is a lexical element of a Jinja expression.
+type token struct {
+	kind byte // 'i' identifier, 's' string, 'n' number, 'p' punctuation.
+	text string
+}
+
+// lex splits a Jinja expression or statement into tokens.
+func lex(body string) []token {
+	var tokens []token
+
+	for i := 0; i < len(body); {
+		if strings.ContainsRune(" \t\r\n", rune(body[i])) {
+			i++
+
+			continue
+		}
+
+		var tok token
+
+		tok, i = scan(body, i)
+		tokens = append(tokens, tok)
+	}
+
+	return tokens
+}
+
+// scan reads the token starting at i and returns it with the index after
+// it.
+func scan(body string, i int) (token, int) {
+	var (
+		kind byte
+		end  int
+	)
+
+	switch c := body[i]; {
+	case c == '"' || c == '\'':
+		kind, end = 's', stringEnd(body, i)
+	case isIdentStart(c):
+		kind, end = 'i', span(body, i, isIdentPart)
+	case c >= '0' && c <= '9':
+		kind, end = 'n', span(body, i, func(c byte) bool {
+			return isIdentPart(c) || c == '.'
+		})
+	default:
+		kind, end = 'p', punctEnd(body, i)
+	}
+
+	return token{kind, body[i:end]}, end
+}
+
+// span returns the index after the run of bytes matching fn, starting
+// with the byte at i.
+func span(body string, i int, fn func(byte) bool) int {
+	end := i + 1
+	for end < len(body) && fn(body[end]) {
+		end++
+	}
+
+	return end
+}
+
+// stringEnd returns the index after the string literal starting at i.
+func stringEnd(body string, i int) int {
+	quote := body[i]
+
+	for j := i + 1; j < len(body); j++ {
+		switch body[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		}
+	}
+
+	return len(body)
+}
+
+// punctEnd returns the index after the operator starting at i, keeping
+// comparisons such as "==" apart from the assignment "=".
+func punctEnd(body string, i int) int {
+	if i+1 < len(body) && body[i+1] == '=' &&
+		strings.ContainsRune("=!<>", rune(body[i])) {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,80 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/lint"
+	"example.com/go-template/util/testx"
+)
+
+// undefined lints a role with a single template and returns the names of
+// the undefined variables, or the syntax error message.
+func undefined(t *testing.T, text string) []string {
+	t.Helper()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"role/defaults/main.yml": "known: 1\nrows: []\n",
+		"role/templates/test.j2": text,
+	})
+
+	diagnostics, err := lint.Run(dir)
+	require.NoError(t, err)
+
+	var found []string
+
+	for _, d := range diagnostics {
+		switch d.Rule {
+		case lint.RuleSyntax:
+			return []string{d.Message}
+		case lint.RuleUndefined:
+			found = append(found, d.Message)
+		}
+	}
+
+	return found
+}
+
+func TestJinjaBindings(t *testing.T) {
+	t.Parallel()
+
+	text := `{%- set _prefix = known ~ "-" -%}
+{%- macro _render(_row, _sep=",") -%}{{ _row | join(_sep) }}{%- endmacro -%}
+{% for _key, _value in rows if _value is not none %}
+{{ _prefix }}{{ _render(_value, _sep=";") }} {{ loop.index }} {{ _key }}
+{% endfor %}
+{% import "macros.j2" as _m %}{{ _m.field(name="x") }}
+{% set _block %}text{% endset %}{{ _block }}`
+
+	assert.Empty(t, undefined(t, text))
+}
+
+func TestJinjaReferences(t *testing.T) {
+	t.Parallel()
+
+	text := `{{ first.attr[second] | filter(third) }}
+{# {{ commented }} #}
+{% raw %}{{ raw_text }}{% endraw %}
+{{ "{{ quoted }}" ~ known }}
+{% if fourth is defined and known in fifth %}{% endif %}
+{{ first }}`
+
+	assert.Equal(t, []string{
+		`variable "first" is not defined`,
+		`variable "second" is not defined`,
+		`variable "third" is not defined`,
+		`variable "fourth" is not defined`,
+		`variable "fifth" is not defined`,
+	}, undefined(t, text))
+}
+
+func TestJinjaSyntax(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"unterminated {{"},
+		undefined(t, "ok\n{{ known "))
+	assert.Equal(t, []string{"unterminated raw block"},
+		undefined(t, "{% raw %}{{ x }}"))
+}
@@ -0,0 +1,151 @@
+// Package lint checks the templates of Ansible roles against the variables
+// the roles define. It reports variables that templates use but nothing
+// defines, role defaults that nothing uses and templates that do not
+// parse, turning silent template bugs into errors a CI job can catch.
+//
+// The checks are heuristics: Jinja templates are scanned rather than
+// compiled, every variable defined by any role counts as defined for all
+// templates, and a default counts as used when its name appears anywhere
+// else in the roles.
+package lint
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Rules reported by Run.
+const (
+	RuleUndefined = "undefined-variable"
+	RuleUnused    = "unused-default"
+	RuleSyntax    = "template-syntax"
+)
+
+// Diagnostic is a problem found in a file.
+type Diagnostic struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic as "path:line: message (rule)", the way
+// compilers do, so that editors can jump to it.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", d.Path, d.Line, d.Message, d.Rule)
+}
+
+// Option configures Run.
+type Option func(*settings)
+
+type settings struct {
+	globals     []string
+	definitions []string
+	users       []string
+}
+
+// WithGlobals declares variables defined outside of the roles, such as
+// inventory host variables and facts. Names ending with "*" match every
+// variable with that prefix.
+func WithGlobals(names ...string) Option {
+	return func(s *settings) {
+		s.globals = append(s.globals, names...)
+	}
+}
+
+// WithDefinitions declares the top-level variables of the YAML files at
+// paths, files or directories such as group_vars and host_vars.
+func WithDefinitions(paths ...string) Option {
+	return func(s *settings) {
+		s.definitions = append(s.definitions, paths...)
+	}
+}
+
+// WithUsers adds files, or directories of files, whose references count
+// as uses of role defaults, such as playbooks and inventories.
+func WithUsers(paths ...string) Option {
+	return func(s *settings) {
+		s.users = append(s.users, paths...)
+	}
+}
+
+// Run lints the roles found below dir, any directory with a tasks,
+// defaults, vars or templates subdirectory, and returns the diagnostics
+// sorted by path and line.
+func Run(dir string, opts ...Option) ([]Diagnostic, error) {
+	var s settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	roles, err := findRoles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := loadProject(roles, s)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics, err := project.checkTemplates(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics = append(diagnostics, project.checkDefaults()...)
+
+	slices.SortFunc(diagnostics, func(a, b Diagnostic) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+
+		return a.Line - b.Line
+	})
+
+	return diagnostics, nil
+}
+
+// roleDirs are the subdirectories marking a role.
+var roleDirs = []string{"tasks", "defaults", "vars", "templates"}
+
+// findRoles lists the role directories below dir, without looking inside
+// roles for nested ones.
+func findRoles(dir string) ([]string, error) {
+	var roles []string
+
+	err := filepath.WalkDir(dir,
+		func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.IsDir() {
+				return err
+			}
+
+			if !isRole(path) {
+				return nil
+			}
+
+			roles = append(roles, path)
+
+			return fs.SkipDir
+		})
+	if err != nil {
+		return nil, fmt.Errorf("lint: %w", err)
+	}
+
+	return roles, nil
+}
+
+func isRole(dir string) bool {
+	for _, sub := range roleDirs {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err == nil &&
+			info.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package lint_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/lint"
+	"example.com/go-template/util/testx"
+)
+
+// roles returns a roles tree with a shared role and a role using it.
+func roles(t *testing.T) string {
+	t.Helper()
+
+	return testx.TempDirWithFiles(t, map[string]string{
+		"group/shared/defaults/main.yml": "---\n" +
+			"shared_user: root\n" +
+			"shared_unused: 1\n" +
+			"_shared_anchors: &a {}\n",
+		"group/app/defaults/main.yml": "app_port: 80\napp_tls: false\n",
+		"group/app/vars/main.yml":     "app_conf: /etc/app.conf\n",
+		"group/app/tasks/main.yml": "- name: Probe\n" +
+			"  ansible.builtin.command: probe\n" +
+			"  register: app_probe\n" +
+			"- name: Remember\n" +
+			"  ansible.builtin.set_fact:\n" +
+			"    app_fact: 1\n" +
+			"  when: app_tls\n",
+		"group/app/templates/app.conf.j2": "user={{ shared_user }}\n" +
+			"port={{ app_port }} {{ app_probe.rc }} {{ app_fact }}\n" +
+			"host={{ inventory_hostname }} {{ ansible_host }}\n" +
+			"{{ app_missing | default('x') }}\n",
+		"group/app/files/main.go.tmpl": "{{ .Name | snake }}\n",
+		"group/app/files/broken.tmpl":  "{{ .Name",
+		"group/app/files/plain.txt":    "{{ not_a_template }}\n",
+		"group/README.md":              "not a role\n",
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := roles(t)
+
+	diagnostics, err := lint.Run(dir)
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 3)
+
+	broken := diagnostics[0]
+	assert.Equal(t, filepath.Join(dir, "group/app/files/broken.tmpl"),
+		broken.Path)
+	assert.Equal(t, lint.RuleSyntax, broken.Rule)
+	assert.Equal(t, 1, broken.Line)
+
+	assert.Equal(t, lint.Diagnostic{
+		Path:    filepath.Join(dir, "group/app/templates/app.conf.j2"),
+		Line:    4,
+		Rule:    lint.RuleUndefined,
+		Message: `variable "app_missing" is not defined`,
+	}, diagnostics[1])
+
+	assert.Equal(t, lint.Diagnostic{
+		Path:    filepath.Join(dir, "group/shared/defaults/main.yml"),
+		Line:    3,
+		Rule:    lint.RuleUnused,
+		Message: `default "shared_unused" is never used`,
+	}, diagnostics[2])
+	assert.Equal(t, filepath.Join(dir, "group/shared/defaults/main.yml")+
+		`:3: default "shared_unused" is never used (unused-default)`,
+		diagnostics[2].String())
+}
+
+func TestRunOptions(t *testing.T) {
+	t.Parallel()
+
+	dir := roles(t)
+	extra := testx.TempDirWithFiles(t, map[string]string{
+		"playbook.yml": "- hosts: all\n" +
+			"  vars:\n    x: '{{ shared_unused }}'\n",
+		"group_vars/all/app.yml": "app_missing: yes\n",
+	})
+
+	diagnostics, err := lint.Run(dir,
+		lint.WithDefinitions(filepath.Join(extra, "group_vars")),
+		lint.WithUsers(filepath.Join(extra, "playbook.yml")))
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, lint.RuleSyntax, diagnostics[0].Rule)
+
+	diagnostics, err = lint.Run(dir, lint.WithGlobals("app_*", "shared_*"))
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 2)
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"role/defaults/main.yml": "- not\n- a mapping\n",
+	})
+
+	_, err := lint.Run(dir)
+	require.Error(t, err)
+
+	diagnostics, err := lint.Run(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+	assert.Empty(t, diagnostics)
+}
@@ -0,0 +1,380 @@
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	gotemplate "text/template"
+
+	"example.com/go-template/archible/vars"
+	"example.com/go-template/util/tpl"
+	"example.com/go-template/util/yamlx"
+)
+
+// builtins are the variables and global functions provided by Ansible and
+// Jinja. Names ending with "*" are prefixes.
+var builtins = []string{
+	"ansible_*", "item", "loop", "omit", "vars", "hostvars", "groups",
+	"group_names", "inventory_hostname", "inventory_hostname_short",
+	"inventory_dir", "inventory_file", "playbook_dir", "role_path",
+	"role_name", "role_names", "play_hosts", "environment", "lookup",
+	"query", "q", "now", "undef", "range", "dict", "lipsum", "cycler",
+	"joiner", "namespace", "caller", "varargs", "kwargs", "self",
+}
+
+// identifier matches the words counted as potential uses of defaults.
+var identifier = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// goTemplateLine finds the line number in text/template parse errors.
+var goTemplateLine = regexp.MustCompile(`:(\d+):`)
+
+// definition is a role default.
+type definition struct {
+	name string
+	path string
+	line int
+}
+
+// project is the knowledge gathered from all roles.
+type project struct {
+	defined  map[string]bool
+	prefixes []string
+	defaults []definition
+	words    map[string]int
+}
+
+func loadProject(roles []string, s settings) (*project, error) {
+	p := &project{defined: make(map[string]bool), words: make(map[string]int)}
+	p.declare(builtins...)
+	p.declare(s.globals...)
+
+	for _, role := range roles {
+		if err := p.loadRole(role); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range s.definitions {
+		if err := walkFiles(path, p.define); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range s.users {
+		if err := walkFiles(path, p.scan); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// define declares the top-level keys of a YAML variables file.
+func (p *project) define(path string) error {
+	if !isYAML(path) {
+		return nil
+	}
+
+	sources, err := vars.LoadExtraVars(path)
+	if err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+
+	p.declare(slices.Collect(maps.Keys(sources[0].Vars))...)
+
+	return nil
+}
+
+func (p *project) declare(names ...string) {
+	for _, name := range names {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			p.prefixes = append(p.prefixes, prefix)
+		} else {
+			p.defined[name] = true
+		}
+	}
+}
+
+func (p *project) isDefined(name string) bool {
+	if p.defined[name] {
+		return true
+	}
+
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadRole records the defaults and vars of a role, the variables its
+// tasks bind and the words of its files other than files/.
+func (p *project) loadRole(dir string) error {
+	sources, err := vars.LoadRole(dir)
+	if err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+
+	for _, source := range sources {
+		for name := range source.Vars {
+			p.declare(name)
+
+			if source.Level == vars.RoleDefaults {
+				p.defaults = append(p.defaults, definition{
+					name, source.Path, keyLine(source.Path, name),
+				})
+			}
+		}
+	}
+
+	return walkFiles(dir, func(path string) error {
+		rel, _ := filepath.Rel(dir, path)
+		if strings.HasPrefix(rel, "files"+string(filepath.Separator)) {
+			return nil
+		}
+
+		return p.scan(path)
+	})
+}
+
+// scan counts the words of a text file and, for YAML files, declares the
+// variables bound by tasks and plays.
+func (p *project) scan(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil
+	}
+
+	for _, word := range identifier.FindAll(data, -1) {
+		p.words[string(word)]++
+	}
+
+	if isYAML(path) {
+		var document any
+		if yamlx.UnmarshalStrict(data, &document) == nil {
+			bound(document, p.declare)
+		}
+	}
+
+	return nil
+}
+
+// checkTemplates analyzes the Jinja and Go templates of the roles.
+func (p *project) checkTemplates(roles []string) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+
+	for _, role := range roles {
+		for _, sub := range []string{"templates", "files"} {
+			err := walkFiles(filepath.Join(role, sub), func(path string) error {
+				found, err := p.checkTemplate(path)
+				diagnostics = append(diagnostics, found...)
+
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return diagnostics, nil
+}
+
+func (p *project) checkTemplate(path string) ([]Diagnostic, error) {
+	switch filepath.Ext(path) {
+	case ".j2":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("lint: %w", err)
+		}
+
+		return p.checkJinja(path, string(data)), nil
+	case ".tmpl", ".gotmpl":
+		return checkGo(path)
+	default:
+		return nil, nil
+	}
+}
+
+// checkJinja reports the undefined variables of a Jinja template, once per
+// name at its first use.
+func (p *project) checkJinja(path, text string) []Diagnostic {
+	t, err := analyze(text)
+
+	var syntaxErr *syntaxError
+	if errors.As(err, &syntaxErr) {
+		return []Diagnostic{
+			{path, syntaxErr.line, RuleSyntax, syntaxErr.message},
+		}
+	}
+
+	var diagnostics []Diagnostic
+
+	reported := make(map[string]bool)
+
+	for _, ref := range t.references {
+		if t.locals[ref.name] || p.isDefined(ref.name) || reported[ref.name] {
+			continue
+		}
+
+		reported[ref.name] = true
+		diagnostics = append(diagnostics, Diagnostic{
+			path, ref.line, RuleUndefined,
+			fmt.Sprintf("variable %q is not defined", ref.name),
+		})
+	}
+
+	return diagnostics
+}
+
+// checkGo parses a Go template with the functions of util/tpl.
+func checkGo(path string) ([]Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("lint: %w", err)
+	}
+
+	_, err = gotemplate.New(filepath.Base(path)).
+		Funcs(tpl.FuncMap()).Parse(string(data))
+	if err == nil {
+		return nil, nil
+	}
+
+	line := 1
+	if match := goTemplateLine.FindStringSubmatch(err.Error()); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+
+	return []Diagnostic{{path, line, RuleSyntax, err.Error()}}, nil
+}
+
+// checkDefaults reports the defaults whose name appears nowhere but in
+// their own definitions. Names starting with an underscore are private
+// helpers, such as holders of YAML anchors, and are not reported.
+func (p *project) checkDefaults() []Diagnostic {
+	definitions := make(map[string]int)
+	for _, d := range p.defaults {
+		definitions[d.name]++
+	}
+
+	var diagnostics []Diagnostic
+
+	for _, d := range p.defaults {
+		if p.words[d.name] > definitions[d.name] ||
+			strings.HasPrefix(d.name, "_") {
+			continue
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			d.path, d.line, RuleUnused,
+			fmt.Sprintf("default %q is never used", d.name),
+		})
+	}
+
+	return diagnostics
+}
+
+// bound calls declare with the variables that a task or play tree binds:
+// registered results, facts, vars and loop variables.
+func bound(node any, declare func(...string)) {
+	switch node := node.(type) {
+	case []any:
+		for _, item := range node {
+			bound(item, declare)
+		}
+	case map[string]any:
+		for key, value := range node {
+			declare(boundBy(key, value)...)
+			bound(value, declare)
+		}
+	}
+}
+
+// boundBy returns the variables bound by a single task keyword.
+func boundBy(key string, value any) []string {
+	switch key {
+	case "register", "loop_var", "index_var":
+		if name, ok := value.(string); ok {
+			return []string{name}
+		}
+	case "vars", "set_fact", "ansible.builtin.set_fact":
+		if m, ok := value.(map[string]any); ok {
+			return slices.Collect(maps.Keys(m))
+		}
+	}
+
+	return nil
+}
+
+// keyLine returns the line defining the top-level key name in a YAML file,
+// or 1 when not found.
+func keyLine(path, name string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 1
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, name+":") {
+			continue
+		}
+
+		return i + 1
+	}
+
+	return 1
+}
+
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// walkFiles calls fn for every regular file below dir, skipping hidden
+// entries. A missing dir has no files.
+func walkFiles(dir string, fn func(path string) error) error {
+	err := filepath.WalkDir(dir,
+		func(path string, entry fs.DirEntry, err error) error {
+			switch {
+			case errors.Is(err, fs.ErrNotExist) && path == dir:
+				return fs.SkipAll
+			case err != nil:
+				return err
+			case path != dir && strings.HasPrefix(entry.Name(), "."):
+				return skip(entry)
+			case entry.Type().IsRegular():
+				return fn(path)
+			default:
+				return nil
+			}
+		})
+	if err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+
+	return nil
+}
+
+// skip ignores a hidden entry, with everything below it for directories.
+func skip(entry fs.DirEntry) error {
+	if entry.IsDir() {
+		return fs.SkipDir
+	}
+
+	return nil
+}
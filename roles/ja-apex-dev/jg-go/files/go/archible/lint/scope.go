@@ -0,0 +1,192 @@
+package lint
+
+import "slices"
+
+// keywords are the Jinja words that are never variables.
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "is": true,
+	"if": true, "else": true, "recursive": true, "as": true,
+	"true": true, "false": true, "none": true,
+	"True": true, "False": true, "None": true,
+}
+
+// reference is a variable used by a template.
+type reference struct {
+	name string
+	line int
+}
+
+// template holds the variables a Jinja template uses and binds itself.
+type template struct {
+	references []reference
+	locals     map[string]bool
+}
+
+// analyze finds the variables of a Jinja template. Bindings are collected
+// for the whole file, ignoring the nesting of blocks and macros, which
+// errs on the side of reporting nothing.
+func analyze(text string) (template, error) {
+	found, err := tags(text)
+	if err != nil {
+		return template{}, err
+	}
+
+	t := template{locals: make(map[string]bool)}
+
+	for _, tag := range found {
+		tokens := lex(tag.body)
+		if tag.opening == "{%" {
+			tokens = t.bind(tokens)
+		}
+
+		for _, name := range uses(tokens) {
+			t.references = append(t.references, reference{name, tag.line})
+		}
+	}
+
+	return t, nil
+}
+
+// bind records the names a statement defines and returns the tokens that
+// remain to be checked for uses.
+func (t *template) bind(tokens []token) []token {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	rest := tokens[1:]
+
+	switch tokens[0].text {
+	case "for":
+		return t.bindUntil(rest, "in")
+	case "set", "with":
+		return t.bindUntil(rest, "=")
+	case "macro":
+		return t.bindMacro(rest)
+	case "import", "from":
+		t.bindAfter(rest, "as", "import")
+
+		return nil
+	case "filter", "block", "call":
+		return nil
+	default:
+		return rest
+	}
+}
+
+// bindUntil binds the identifiers before the first separator token and
+// returns the tokens after it. Block assignments without separator bind
+// every identifier.
+func (t *template) bindUntil(tokens []token, separator string) []token {
+	for i, tok := range tokens {
+		if tok.text == separator {
+			return tokens[i+1:]
+		}
+
+		if tok.kind == 'i' {
+			t.locals[tok.text] = true
+		}
+	}
+
+	return nil
+}
+
+// bindMacro binds the name and parameters of a macro and returns the
+// tokens of the parameter defaults.
+func (t *template) bindMacro(tokens []token) []token {
+	var defaults []token
+
+	inDefault := false
+
+	for i, tok := range tokens {
+		switch {
+		case tok.text == "," || tok.text == "(":
+			inDefault = false
+		case tok.text == "=":
+			inDefault = true
+		case inDefault:
+			defaults = append(defaults, tok)
+		case tok.kind == 'i' && (i == 0 || isParamStart(tokens[i-1])):
+			t.locals[tok.text] = true
+		}
+	}
+
+	return defaults
+}
+
+func isParamStart(tok token) bool {
+	return tok.text == "(" || tok.text == ","
+}
+
+// bindAfter binds the identifiers following any of the marker words, as
+// in "import 'forms' as forms" or "from 'forms' import input".
+func (t *template) bindAfter(tokens []token, markers ...string) {
+	binding := false
+
+	for _, tok := range tokens {
+		switch {
+		case tok.kind != 'i':
+		case slices.Contains(markers, tok.text):
+			binding = true
+		case binding:
+			t.locals[tok.text] = true
+		}
+	}
+}
+
+// uses returns the root variables of an expression: identifiers that are
+// not keywords, attributes, filter or test names, or keyword arguments.
+func uses(tokens []token) []string {
+	var names []string
+
+	depth := 0
+
+	for i, tok := range tokens {
+		depth += nesting(tok)
+
+		if tok.kind != 'i' || keywords[tok.text] {
+			continue
+		}
+
+		if i > 0 && isQualifier(tokens, i) {
+			continue
+		}
+
+		if depth > 0 && i+1 < len(tokens) && tokens[i+1].text == "=" {
+			continue
+		}
+
+		names = append(names, tok.text)
+	}
+
+	return names
+}
+
+// isQualifier reports whether the identifier at i names an attribute, a
+// filter or a test rather than a variable.
+func isQualifier(tokens []token, i int) bool {
+	switch tokens[i-1].text {
+	case ".", "|", "is":
+		return true
+	case "not":
+		return i > 1 && tokens[i-2].text == "is"
+	default:
+		return false
+	}
+}
+
+// nesting returns how tok changes the bracket depth.
+func nesting(tok token) int {
+	if tok.kind != 'p' {
+		return 0
+	}
+
+	switch tok.text {
+	case "(", "[", "{":
+		return 1
+	case ")", "]", "}":
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,217 @@
+package pins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util/hashx"
+	"example.com/go-template/util/platform"
+	"example.com/go-template/util/yamlx"
+)
+
+// ErrNoChecksum is returned by Lock when the checksums file of a pin does
+// not list its download.
+var ErrNoChecksum = errors.New("pins: download missing from checksums")
+
+// Lockfile holds the downloads resolved from a manifest for a platform.
+type Lockfile struct {
+	// Platform is the GOOS/GOARCH pair of the downloads.
+	Platform string `yaml:"platform"`
+	// Tools maps tool names to their downloads.
+	Tools map[string]Locked `yaml:"tools"`
+}
+
+// Locked is the resolved download of a pinned tool.
+type Locked struct {
+	Version string `yaml:"version"`
+	URL     string `yaml:"url"`
+	SHA256  string `yaml:"sha256"`
+}
+
+// Lock resolves the download of every pin with a URL and its checksum,
+// which is read from the checksums file of the pin or computed by
+// downloading the file.
+func Lock(ctx context.Context, m *Manifest, opts ...Option) (*Lockfile, error) {
+	s := newSettings(opts)
+	lock := &Lockfile{
+		Platform: s.goos + "/" + s.goarch, Tools: make(map[string]Locked),
+	}
+
+	for _, name := range m.Names() {
+		pin := m.Tools[name]
+		if pin.URL == "" {
+			continue
+		}
+
+		locked, err := s.resolve(ctx, pin)
+		if err != nil {
+			return nil, fmt.Errorf("%w (%s)", err, name)
+		}
+
+		lock.Tools[name] = locked
+	}
+
+	return lock, nil
+}
+
+// LoadLock reads the lockfile at path.
+func LoadLock(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("pins: %w", err)
+	}
+
+	var lock Lockfile
+	if err := yamlx.UnmarshalStrict(data, &lock); err != nil {
+		return nil, fmt.Errorf("pins: %s: %w", path, err)
+	}
+
+	for name, locked := range lock.Tools {
+		if locked.URL == "" || locked.SHA256 == "" {
+			return nil, fmt.Errorf("%w: %s: url and sha256 are required (%s)",
+				ErrInvalid, name, path)
+		}
+	}
+
+	return &lock, nil
+}
+
+// Save writes the lockfile to path atomically, with tools sorted by name.
+func (l *Lockfile) Save(path string) error {
+	return save(path, l)
+}
+
+// Stale returns the tools, in name order, whose pins in m were added,
+// changed or removed since l was locked.
+func (l *Lockfile) Stale(m *Manifest) []string {
+	var stale []string
+
+	for _, name := range m.Names() {
+		pin := m.Tools[name]
+		locked, ok := l.Tools[name]
+
+		if pin.URL != "" && (!ok || locked.Version != pin.Version) {
+			stale = append(stale, name)
+		}
+	}
+
+	for name := range l.Tools {
+		if pin, ok := m.Tools[name]; !ok || pin.URL == "" {
+			stale = append(stale, name)
+		}
+	}
+
+	slices.Sort(stale)
+
+	return stale
+}
+
+// resolve resolves the download of pin.
+func (s *settings) resolve(ctx context.Context, pin Pin) (Locked, error) {
+	locked := Locked{Version: pin.Version, URL: s.expand(pin.URL, pin)}
+
+	var err error
+	if pin.Checksums != "" {
+		locked.SHA256, err = s.listed(ctx, s.expand(pin.Checksums, pin),
+			locked.URL)
+	} else {
+		locked.SHA256, err = s.hash(ctx, locked.URL)
+	}
+
+	return locked, err
+}
+
+// expand replaces the placeholders of a URL template.
+func (s *settings) expand(template string, pin Pin) string {
+	return strings.NewReplacer(
+		"{version}", pin.Version, "{os}", s.goos, "{arch}", s.goarch,
+		"{machine}", platform.Machine(s.goarch),
+	).Replace(template)
+}
+
+// listed returns the checksum of download in the SHA256SUMS file at sums,
+// whose lines are a digest and a file name, optionally marked binary with
+// a "*".
+func (s *settings) listed(ctx context.Context, sums, download string) (
+	string, error,
+) {
+	body, err := s.get(ctx, sums)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	name := fileName(download)
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		digest, file, ok := strings.Cut(scanner.Text(), " ")
+		if ok && strings.TrimLeft(file, " *") == name {
+			return strings.ToLower(digest), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("pins: %s: %w", sums, err)
+	}
+
+	return "", fmt.Errorf("%w: %s in %s", ErrNoChecksum, name, sums)
+}
+
+// hash downloads the file at location and returns its checksum.
+func (s *settings) hash(ctx context.Context, location string) (
+	string, error,
+) {
+	body, err := s.get(ctx, location)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	digest, err := hashx.SHA256Reader(body)
+	if err != nil {
+		return "", fmt.Errorf("pins: %s: %w", location, err)
+	}
+
+	return digest, nil
+}
+
+// get requests location and returns the body of a successful response.
+func (s *settings) get(ctx context.Context, location string) (
+	io.ReadCloser, error,
+) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("pins: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("pins: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("pins: %s: %s", location, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// fileName returns the last path element of a download URL.
+func fileName(download string) string {
+	if u, err := url.Parse(download); err == nil {
+		download = u.Path
+	}
+
+	return download[strings.LastIndex(download, "/")+1:]
+}
@@ -0,0 +1,110 @@
+package pins_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/pins"
+	"example.com/go-template/util/hashx"
+)
+
+// mirror serves release files and a SHA256SUMS file.
+func mirror(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/go1.23.2.linux-arm64.tar.gz",
+		func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("go archive"))
+		})
+	mux.HandleFunc("/tf/1.9.5/SHA256SUMS",
+		func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(
+				"AAAA  tf_1.9.5_linux_amd64.zip\n" +
+					"BBBB *tf_1.9.5_linux_arm64.zip\n"))
+		})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+
+	server := mirror(t)
+	m := &pins.Manifest{Tools: map[string]pins.Pin{
+		"go": {
+			Version: "1.23.2",
+			URL:     server.URL + "/go{version}.{os}-{arch}.tar.gz",
+		},
+		"tf": {
+			Version: "1.9.5",
+			URL: server.URL + "/tf/{version}/tf_{version}_{os}_{arch}" +
+				".zip",
+			Checksums: server.URL + "/tf/{version}/SHA256SUMS",
+		},
+		"local": {Version: "1.0.0"},
+	}}
+
+	lock, err := pins.Lock(t.Context(), m,
+		pins.WithClient(server.Client()), pins.WithPlatform("linux", "arm64"))
+	require.NoError(t, err)
+	assert.Equal(t, &pins.Lockfile{
+		Platform: "linux/arm64",
+		Tools: map[string]pins.Locked{
+			"go": {
+				Version: "1.23.2",
+				URL:     server.URL + "/go1.23.2.linux-arm64.tar.gz",
+				SHA256:  hashx.SHA256([]byte("go archive")),
+			},
+			"tf": {
+				Version: "1.9.5",
+				URL:     server.URL + "/tf/1.9.5/tf_1.9.5_linux_arm64.zip",
+				SHA256:  "bbbb",
+			},
+		},
+	}, lock)
+	assert.Empty(t, lock.Stale(m))
+
+	path := filepath.Join(t.TempDir(), "pins.lock")
+	require.NoError(t, lock.Save(path))
+
+	loaded, err := pins.LoadLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, lock, loaded)
+
+	require.NoError(t, m.Set("go", "1.23.3"))
+	delete(m.Tools, "tf")
+	m.Tools["node"] = pins.Pin{Version: "22.9.0", URL: server.URL + "/node"}
+	assert.Equal(t, []string{"go", "node", "tf"}, lock.Stale(m))
+}
+
+func TestLockErrors(t *testing.T) {
+	t.Parallel()
+
+	server := mirror(t)
+	opts := []pins.Option{
+		pins.WithClient(server.Client()), pins.WithPlatform("darwin", "amd64"),
+	}
+
+	_, err := pins.Lock(t.Context(), &pins.Manifest{Tools: map[string]pins.Pin{
+		"tf": {
+			Version:   "1.9.5",
+			URL:       server.URL + "/tf_{version}_{os}_{arch}.zip",
+			Checksums: server.URL + "/tf/{version}/SHA256SUMS",
+		},
+	}}, opts...)
+	require.ErrorIs(t, err, pins.ErrNoChecksum)
+
+	_, err = pins.Lock(t.Context(), &pins.Manifest{Tools: map[string]pins.Pin{
+		"go": {Version: "1.23.2", URL: server.URL + "/missing"},
+	}}, opts...)
+	require.ErrorContains(t, err, "404")
+}
@@ -0,0 +1,228 @@
+// Package pins manages the version pins of the tools set up by the roles,
+// such as go, node or terraform, in a single manifest instead of scattered
+// role defaults:
+//
+//	tools:
+//	  go:
+//	    version: 1.23.2
+//	    url: https://go.dev/dl/go{version}.{os}-{arch}.tar.gz
+//	    command: [go, version]
+//	  terraform:
+//	    version: 1.9.5
+//	    url: https://example.com/terraform_{version}_{os}_{arch}.zip
+//	    checksums: https://example.com/terraform_{version}_SHA256SUMS
+//	    command: [terraform, version]
+//
+// Verify compares the installed versions with the pins, and Lock resolves
+// the download URLs of a platform with their checksums into a lockfile.
+package pins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/fsx"
+	"example.com/go-template/util/httpx"
+	"example.com/go-template/util/semver"
+	"example.com/go-template/util/yamlx"
+)
+
+var (
+	// ErrInvalid is returned for manifests and lockfiles with missing or
+	// malformed fields.
+	ErrInvalid = errors.New("pins: invalid pin")
+	// ErrUnknown is returned for tools without a pin.
+	ErrUnknown = errors.New("pins: unknown tool")
+)
+
+// filePerm is the permission of the written manifests and lockfiles.
+const filePerm = 0o644
+
+// Pin is the pinned version of a tool. URL and Checksums may use the
+// placeholders {version}, {os}, {arch} (GOARCH) and {machine} (uname -m).
+type Pin struct {
+	// Version is the exact version, such as "1.9.5".
+	Version string `yaml:"version"`
+	// URL is the download URL of the release archive or binary.
+	URL string `yaml:"url,omitempty"`
+	// Checksums is the URL of a SHA256SUMS file listing the download.
+	// Without it, Lock downloads the file to hash it.
+	Checksums string `yaml:"checksums,omitempty"`
+	// Command prints the installed version, such as [go, version].
+	Command []string `yaml:"command,omitempty"`
+}
+
+// Manifest maps tool names to their pins.
+type Manifest struct {
+	Tools map[string]Pin `yaml:"tools"`
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("pins: %w", err)
+	}
+
+	var m Manifest
+	if err := yamlx.UnmarshalStrict(data, &m); err != nil {
+		return nil, fmt.Errorf("pins: %s: %w", path, err)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+
+	return &m, nil
+}
+
+// Save writes the manifest to path atomically, with tools sorted by
+// name.
+func (m *Manifest) Save(path string) error {
+	return save(path, m)
+}
+
+// Validate checks that every pin has a valid version.
+func (m *Manifest) Validate() error {
+	for _, name := range m.Names() {
+		if err := checkVersion(m.Tools[name].Version); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalid, name, err)
+		}
+	}
+
+	return nil
+}
+
+// Names returns the pinned tools in lexical order.
+func (m *Manifest) Names() []string {
+	return slices.Sorted(maps.Keys(m.Tools))
+}
+
+// Get returns the pin of the tool name.
+func (m *Manifest) Get(name string) (Pin, bool) {
+	pin, ok := m.Tools[name]
+
+	return pin, ok
+}
+
+// Set pins the tool name to version, keeping the other fields of an
+// existing pin.
+func (m *Manifest) Set(name, version string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty tool name", ErrInvalid)
+	}
+
+	if err := checkVersion(version); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalid, name, err)
+	}
+
+	if m.Tools == nil {
+		m.Tools = make(map[string]Pin)
+	}
+
+	pin := m.Tools[name]
+	pin.Version = version
+	m.Tools[name] = pin
+
+	return nil
+}
+
+// Remove drops the pin of the tool name and reports whether it existed.
+func (m *Manifest) Remove(name string) bool {
+	_, ok := m.Tools[name]
+	delete(m.Tools, name)
+
+	return ok
+}
+
+// checkVersion accepts exact versions, with an optional "v" prefix or
+// missing patch number as tools commonly print them.
+func checkVersion(version string) error {
+	if version == "" || strings.ContainsAny(version, " <>=~^*,|") {
+		return fmt.Errorf("version %q is not exact", version)
+	}
+
+	_, err := semver.Coerce(version)
+
+	return err
+}
+
+// save encodes v as YAML into path atomically.
+func save(path string, v any) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("pins: %w", err)
+	}
+
+	if err := fsx.AtomicWriteFile(path, data, filePerm); err != nil {
+		return fmt.Errorf("pins: %w", err)
+	}
+
+	return nil
+}
+
+// Runner executes commands; *execx.Runner implements it.
+type Runner interface {
+	// Run executes name with args and waits for it.
+	Run(ctx context.Context, name string, args ...string) (execx.Result, error)
+}
+
+// Option configures Verify and Lock.
+type Option func(*settings)
+
+type settings struct {
+	runner Runner
+	client *http.Client
+	goos   string
+	goarch string
+}
+
+// WithRunner runs the version commands of Verify with r.
+func WithRunner(r Runner) Option {
+	return func(s *settings) {
+		s.runner = r
+	}
+}
+
+// WithClient sets the HTTP client Lock fetches downloads and checksums
+// with.
+func WithClient(client *http.Client) Option {
+	return func(s *settings) {
+		s.client = client
+	}
+}
+
+// WithPlatform locks the downloads of goos and goarch instead of the
+// current platform.
+func WithPlatform(goos, goarch string) Option {
+	return func(s *settings) {
+		s.goos, s.goarch = goos, goarch
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{goos: runtime.GOOS, goarch: runtime.GOARCH}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.runner == nil {
+		s.runner = execx.New()
+	}
+
+	if s.client == nil {
+		s.client = httpx.NewClient(httpx.WithTimeout(0))
+	}
+
+	return s
+}
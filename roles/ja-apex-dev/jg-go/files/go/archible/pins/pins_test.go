@@ -0,0 +1,85 @@
+package pins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/pins"
+	"example.com/go-template/util/testx"
+)
+
+const manifest = `tools:
+  terraform:
+    version: 1.9.5
+    url: https://example.com/terraform_{version}_{os}_{arch}.zip
+    command: [terraform, version]
+  go:
+    version: 1.23.2
+`
+
+func TestLoadSave(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{"pins.yml": manifest})
+
+	m, err := pins.Load(filepath.Join(dir, "pins.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "terraform"}, m.Names())
+
+	pin, ok := m.Get("terraform")
+	require.True(t, ok)
+	assert.Equal(t, pins.Pin{
+		Version: "1.9.5",
+		URL:     "https://example.com/terraform_{version}_{os}_{arch}.zip",
+		Command: []string{"terraform", "version"},
+	}, pin)
+
+	require.NoError(t, m.Set("terraform", "1.10.0"))
+	require.NoError(t, m.Set("node", "v22.9.0"))
+	assert.True(t, m.Remove("go"))
+	assert.False(t, m.Remove("go"))
+
+	path := filepath.Join(dir, "out.yml")
+	require.NoError(t, m.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "tools:\n"+
+		"    node:\n        version: v22.9.0\n"+
+		"    terraform:\n        version: 1.10.0\n"+
+		"        url: https://example.com/"+
+		"terraform_{version}_{os}_{arch}.zip\n"+
+		"        command:\n            - terraform\n            - version\n",
+		string(data))
+
+	saved, err := pins.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, m, saved)
+}
+
+func TestInvalid(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"range.yml":   "tools:\n  go: {version: '>=1.22'}\n",
+		"missing.yml": "tools:\n  go: {url: https://go.dev}\n",
+		"unknown.yml": "tools:\n  go: {version: 1.23.2, sha: abc}\n",
+	})
+
+	for _, name := range []string{"range.yml", "missing.yml"} {
+		_, err := pins.Load(filepath.Join(dir, name))
+		require.ErrorIs(t, err, pins.ErrInvalid, name)
+	}
+
+	_, err := pins.Load(filepath.Join(dir, "unknown.yml"))
+	require.Error(t, err)
+
+	var m pins.Manifest
+	require.ErrorIs(t, m.Set("go", "latest"), pins.ErrInvalid)
+	require.ErrorIs(t, m.Set("", "1.0.0"), pins.ErrInvalid)
+	require.NoError(t, m.Set("go", "1.23rc1"))
+}
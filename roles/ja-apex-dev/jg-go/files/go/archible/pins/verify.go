@@ -0,0 +1,126 @@
+package pins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"example.com/go-template/util/semver"
+)
+
+// ErrMismatch is returned by Verify when a tool is missing or differs from
+// its pin.
+var ErrMismatch = errors.New("pins: installed version differs from pin")
+
+// printed finds the version in the output of a version command, as in
+// "go version go1.23.2 linux/amd64" or "Terraform v1.9.5".
+var printed = regexp.MustCompile(`\d+(?:\.\d+)+[0-9A-Za-z.+-]*`)
+
+// Status is the installed version of a pinned tool.
+type Status struct {
+	// Name is the tool.
+	Name string
+	// Pinned is the version of the pin.
+	Pinned string
+	// Installed is the version printed by the command of the pin, or
+	// empty when the command failed.
+	Installed string
+	// Err is the failure of the command, such as a missing executable.
+	Err error
+}
+
+// OK reports whether the installed version is the pinned one.
+func (s Status) OK() bool {
+	return s.Err == nil && sameVersion(s.Installed, s.Pinned)
+}
+
+// String describes the status, such as "go 1.23.2: installed 1.22.0".
+func (s Status) String() string {
+	switch {
+	case s.Err != nil:
+		return fmt.Sprintf("%s %s: %v", s.Name, s.Pinned, s.Err)
+	case s.OK():
+		return fmt.Sprintf("%s %s: ok", s.Name, s.Pinned)
+	default:
+		return fmt.Sprintf("%s %s: installed %s", s.Name, s.Pinned, s.Installed)
+	}
+}
+
+// Verify runs the version command of every pin, in name order, and
+// returns ErrMismatch with the statuses unless all tools match. Pins
+// without a command are skipped.
+func Verify(ctx context.Context, m *Manifest, opts ...Option) (
+	[]Status, error,
+) {
+	s := newSettings(opts)
+
+	var (
+		statuses []Status
+		failed   []string
+	)
+
+	for _, name := range m.Names() {
+		pin := m.Tools[name]
+		if len(pin.Command) == 0 {
+			continue
+		}
+
+		status := Status{Name: name, Pinned: pin.Version}
+		status.Installed, status.Err = installed(ctx, s.runner, pin.Command)
+
+		if !status.OK() {
+			failed = append(failed, name)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	if len(failed) > 0 {
+		return statuses, fmt.Errorf("%w: %s", ErrMismatch,
+			strings.Join(failed, ", "))
+	}
+
+	return statuses, nil
+}
+
+// installed runs command and returns the version it prints on stdout or,
+// as some tools do, on stderr.
+func installed(
+	ctx context.Context, r Runner, command []string,
+) (string, error) {
+	result, err := r.Run(ctx, command[0], command[1:]...)
+	if err != nil {
+		return "", fmt.Errorf("pins: %w", err)
+	}
+
+	version := printed.FindString(result.Stdout + "\n" + result.Stderr)
+	if version == "" {
+		return "", fmt.Errorf("pins: no version in the output of %s",
+			strings.Join(command, " "))
+	}
+
+	return version, nil
+}
+
+// sameVersion reports whether a and b name the same version, ignoring a
+// "v" prefix and missing trailing zeros as in "1.23" and "v1.23.0".
+func sameVersion(a, b string) bool {
+	va, errA := semver.Coerce(a)
+	vb, errB := semver.Coerce(b)
+
+	return errA == nil && errB == nil && va.Compare(vb) == 0 &&
+		suffix(a) == suffix(b)
+}
+
+// suffix returns what follows the numbers of a version, such as "rc1" in
+// "1.23rc1".
+func suffix(version string) string {
+	match := printed.FindStringIndex(version)
+	if match == nil {
+		return ""
+	}
+
+	return strings.TrimLeft(version[match[0]:], "0123456789.")
+}
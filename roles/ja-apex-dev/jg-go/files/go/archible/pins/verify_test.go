@@ -0,0 +1,64 @@
+package pins_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/pins"
+	"example.com/go-template/util/execx"
+)
+
+// fake answers commands from a table keyed by the command line; unknown
+// commands are missing executables.
+type fake map[string]execx.Result
+
+func (f fake) Run(
+	_ context.Context, name string, args ...string,
+) (execx.Result, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	if result, ok := f[line]; ok {
+		return result, nil
+	}
+
+	return execx.Result{}, errors.New(name + ": executable file not found")
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	m := &pins.Manifest{Tools: map[string]pins.Pin{
+		"go":   {Version: "1.23.2", Command: []string{"go", "version"}},
+		"node": {Version: "22.9", Command: []string{"node", "--version"}},
+		"java": {Version: "21.0.4", Command: []string{"java", "-version"}},
+		"tf":   {Version: "1.9.5"},
+	}}
+	runner := fake{
+		"go version":     {Stdout: "go version go1.23.2 linux/amd64\n"},
+		"node --version": {Stdout: "v22.9.0\n"},
+		"java -version": {Stderr: `openjdk version "21.0.4" 2024-07-16` +
+			"\n"},
+	}
+
+	statuses, err := pins.Verify(t.Context(), m, pins.WithRunner(runner))
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "go 1.23.2: ok", statuses[0].String())
+	assert.Equal(t, "21.0.4", statuses[1].Installed)
+	assert.True(t, statuses[2].OK())
+
+	runner["go version"] = execx.Result{Stdout: "go version go1.23rc1\n"}
+	delete(runner, "node --version")
+
+	statuses, err = pins.Verify(t.Context(), m, pins.WithRunner(runner))
+	require.ErrorIs(t, err, pins.ErrMismatch)
+	assert.EqualError(t, err, pins.ErrMismatch.Error()+": go, node")
+	assert.Equal(t, "go 1.23.2: installed 1.23rc1", statuses[0].String())
+	assert.False(t, statuses[0].OK())
+	assert.Equal(t, "node 22.9: pins: node: executable file not found",
+		statuses[2].String())
+}
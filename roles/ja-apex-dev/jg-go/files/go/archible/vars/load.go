@@ -0,0 +1,216 @@
+package vars
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util/yamlx"
+)
+
+var (
+	// ErrEncrypted is returned for files encrypted as a whole with
+	// ansible-vault, which cannot be read without the vault password.
+	ErrEncrypted = errors.New("vars: vault-encrypted file")
+	// ErrNotMapping is returned for files whose top level is not a
+	// dictionary of variables.
+	ErrNotMapping = errors.New("vars: top level is not a mapping")
+)
+
+// vaultHeader starts the files encrypted with ansible-vault.
+var vaultHeader = []byte("$ANSIBLE_VAULT;")
+
+// extensions are the file extensions Ansible reads variables from.
+var extensions = []string{"", ".yml", ".yaml", ".json"}
+
+// allGroup is the implicit group every host belongs to.
+const allGroup = "all"
+
+// LoadRole loads the defaults and vars of the role in dir, from the main
+// file or the main directory of its defaults and vars directories.
+func LoadRole(dir string) ([]Source, error) {
+	defaults, err := load(filepath.Join(dir, "defaults"), "main", RoleDefaults)
+	if err != nil {
+		return nil, err
+	}
+
+	roleVars, err := load(filepath.Join(dir, "vars"), "main", RoleVars)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(defaults, roleVars...), nil
+}
+
+// LoadGroupVars loads the group_vars directory dir for the groups of a
+// host: "all" first, then groups in the given order, which should list
+// parents before children since later groups override earlier ones.
+func LoadGroupVars(dir string, groups ...string) ([]Source, error) {
+	sources, err := load(dir, allGroup, GroupVarsAll)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if group == allGroup {
+			continue
+		}
+
+		groupSources, err := load(dir, group, GroupVars)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, groupSources...)
+	}
+
+	return sources, nil
+}
+
+// LoadHostVars loads the variables of host from the host_vars directory
+// dir.
+func LoadHostVars(dir, host string) ([]Source, error) {
+	return load(dir, host, HostVars)
+}
+
+// LoadExtraVars loads the given files, each optionally prefixed with "@"
+// as on the ansible-playbook command line.
+func LoadExtraVars(paths ...string) ([]Source, error) {
+	sources := make([]Source, 0, len(paths))
+
+	for _, path := range paths {
+		source, err := loadFile(strings.TrimPrefix(path, "@"), ExtraVars)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// load reads every file holding the variables of name in dir. Missing
+// files are not an error, as for Ansible.
+func load(dir, name string, level Level) ([]Source, error) {
+	paths, err := varsFiles(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]Source, 0, len(paths))
+
+	for _, path := range paths {
+		source, err := loadFile(path, level)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// varsFiles lists the files holding the variables of name in dir as
+// Ansible finds them: name itself or with one of the extensions, and the
+// files below a directory of that name in lexical order.
+func varsFiles(dir, name string) ([]string, error) {
+	var files []string
+
+	for _, ext := range extensions {
+		path := filepath.Join(dir, name+ext)
+
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("vars: %w", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+
+			continue
+		}
+
+		nested, err := dirFiles(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, nested...)
+	}
+
+	return files, nil
+}
+
+// dirFiles lists the variable files below root, skipping hidden entries
+// and files with other extensions.
+func dirFiles(root string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(root,
+		func(path string, entry fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case path != root && strings.HasPrefix(entry.Name(), "."):
+				return skip(entry)
+			case !entry.IsDir() &&
+				slices.Contains(extensions, filepath.Ext(path)):
+				files = append(files, path)
+			}
+
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("vars: %w", err)
+	}
+
+	return files, nil
+}
+
+// skip ignores a hidden entry, with everything below it for directories.
+func skip(entry fs.DirEntry) error {
+	if entry.IsDir() {
+		return fs.SkipDir
+	}
+
+	return nil
+}
+
+// loadFile decodes the variables of a single YAML or JSON file.
+func loadFile(path string, level Level) (Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Source{}, fmt.Errorf("vars: %w", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), vaultHeader) {
+		return Source{}, fmt.Errorf("%w: %s", ErrEncrypted, path)
+	}
+
+	var document any
+	if err := yamlx.UnmarshalStrict(data, &document); err != nil {
+		return Source{}, fmt.Errorf("vars: %s: %w", path, err)
+	}
+
+	values, ok := document.(map[string]any)
+	if !ok && document != nil {
+		return Source{}, fmt.Errorf("%w: %s", ErrNotMapping, path)
+	}
+
+	if values == nil {
+		values = make(map[string]any)
+	}
+
+	return Source{Level: level, Path: path, Vars: values}, nil
+}
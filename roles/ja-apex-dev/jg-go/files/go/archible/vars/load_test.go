@@ -0,0 +1,93 @@
+package vars_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/archible/vars"
+	"example.com/go-template/util/testx"
+)
+
+func TestLoadRole(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"defaults/main.yml":      "---\nuser: root\nshell: bash\n",
+		"vars/main/10-base.yml":  "shell: zsh\n",
+		"vars/main/20-extra.yml": "# nothing yet\n",
+		"vars/main/.hidden.yml":  "shell: fish\n",
+		"vars/main/README.md":    "# notes\n",
+	})
+
+	sources, err := vars.LoadRole(dir)
+	require.NoError(t, err)
+	require.Len(t, sources, 3)
+	assert.Equal(t, vars.RoleDefaults, sources[0].Level)
+	assert.Equal(t, filepath.Join(dir, "vars/main/10-base.yml"),
+		sources[1].Path)
+	assert.Empty(t, sources[2].Vars)
+
+	result := vars.Resolve(sources)
+	assert.Equal(t, map[string]any{"user": "root", "shell": "zsh"},
+		result.Values)
+}
+
+func TestLoadInventory(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"group_vars/all.yml":         "wipe: never\nlocale: C\n",
+		"group_vars/example/aa.yml":  "wipe: ask\n",
+		"group_vars/example_apex":    "locale: en_US\n",
+		"host_vars/box.yaml":         "wipe: always\n",
+		"extra.json":                 `{"locale": "uk_UA"}`,
+		"group_vars/unrelated.yml":   "wipe: now\n",
+		"group_vars/example/zz.json": `{"editor": "vi"}`,
+	})
+
+	groups, err := vars.LoadGroupVars(filepath.Join(dir, "group_vars"),
+		"all", "example", "example_apex")
+	require.NoError(t, err)
+	require.Len(t, groups, 4)
+
+	hosts, err := vars.LoadHostVars(filepath.Join(dir, "host_vars"), "box")
+	require.NoError(t, err)
+
+	extra, err := vars.LoadExtraVars("@" + filepath.Join(dir, "extra.json"))
+	require.NoError(t, err)
+
+	result := vars.Resolve(append(append(extra, hosts...), groups...))
+	assert.Equal(t, map[string]any{
+		"wipe": "always", "locale": "uk_UA", "editor": "vi",
+	}, result.Values)
+	assert.Equal(t, vars.HostVars, result.Origins["wipe"].Level)
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"vault.yml": "$ANSIBLE_VAULT;1.1;AES256\n6162\n",
+		"list.yml":  "- a\n- b\n",
+		"bad.yml":   "a: [\n",
+	})
+
+	_, err := vars.LoadExtraVars(filepath.Join(dir, "vault.yml"))
+	require.ErrorIs(t, err, vars.ErrEncrypted)
+
+	_, err = vars.LoadExtraVars(filepath.Join(dir, "list.yml"))
+	require.ErrorIs(t, err, vars.ErrNotMapping)
+
+	_, err = vars.LoadExtraVars(filepath.Join(dir, "bad.yml"))
+	require.ErrorContains(t, err, "bad.yml")
+
+	_, err = vars.LoadExtraVars(filepath.Join(dir, "missing.yml"))
+	require.Error(t, err)
+
+	sources, err := vars.LoadRole(filepath.Join(dir, "no-role"))
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+}
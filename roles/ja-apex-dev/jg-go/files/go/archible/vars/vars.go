@@ -0,0 +1,134 @@
+// Package vars resolves the variables of Ansible roles the way Ansible
+// does for a host, from role defaults, group_vars, host_vars, role vars and
+// extra-vars files, so that Go tooling such as documentation generators
+// and validators can reason about them without running a playbook.
+//
+// Only the file-based sources are modeled. Their precedence, from lowest
+// to highest, follows the Ansible documentation:
+//
+//  1. role defaults (defaults/main)
+//  2. group_vars/all
+//  3. group_vars of the other groups, parents before children
+//  4. host_vars of the host
+//  5. role vars (vars/main)
+//  6. extra vars (-e @file), which always win
+//
+// Jinja2 expressions are kept as strings and vault-encrypted values as
+// their ciphertext.
+package vars
+
+import (
+	"cmp"
+	"slices"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/yamlx"
+)
+
+// Level is the precedence of a source; higher levels override lower ones.
+type Level int
+
+// Source levels, in increasing precedence.
+const (
+	RoleDefaults Level = iota
+	GroupVarsAll
+	GroupVars
+	HostVars
+	RoleVars
+	ExtraVars
+)
+
+// String returns the name of the level as used in the Ansible
+// documentation.
+func (l Level) String() string {
+	switch l {
+	case RoleDefaults:
+		return "role defaults"
+	case GroupVarsAll:
+		return "group_vars/all"
+	case GroupVars:
+		return "group_vars/*"
+	case HostVars:
+		return "host_vars/*"
+	case RoleVars:
+		return "role vars"
+	case ExtraVars:
+		return "extra vars"
+	default:
+		return "unknown"
+	}
+}
+
+// Source holds the variables defined by a single file.
+type Source struct {
+	Level Level
+	Path  string
+	Vars  map[string]any
+}
+
+// Result is the outcome of Resolve.
+type Result struct {
+	// Values maps every variable to its effective value.
+	Values map[string]any
+	// Origins maps every variable to the source of its effective value,
+	// the last one that defined it when dictionaries are merged.
+	Origins map[string]Source
+}
+
+// Option configures Resolve.
+type Option func(*settings)
+
+type settings struct {
+	merge bool
+}
+
+// WithHashMerge merges dictionaries recursively across sources instead of
+// replacing them, as hash_behaviour = merge does in ansible.cfg. Lists are
+// still replaced.
+func WithHashMerge() Option {
+	return func(s *settings) {
+		s.merge = true
+	}
+}
+
+// Resolve combines sources into the effective variables. Sources are
+// ordered by level; sources of the same level keep their order, later
+// ones overriding earlier ones, as the loaders of this package return
+// them. Values are copied, so the result does not alias the sources.
+func Resolve(sources []Source, opts ...Option) Result {
+	var s settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	ordered := slices.Clone(sources)
+	slices.SortStableFunc(ordered, func(a, b Source) int {
+		return cmp.Compare(a.Level, b.Level)
+	})
+
+	result := Result{
+		Values:  make(map[string]any),
+		Origins: make(map[string]Source),
+	}
+
+	for _, source := range ordered {
+		for name, value := range source.Vars {
+			result.Values[name] = s.combine(result.Values[name], value)
+			result.Origins[name] = source
+		}
+	}
+
+	return result
+}
+
+// combine returns the value overriding previous with value.
+func (s settings) combine(previous, value any) any {
+	previousMap, ok := previous.(map[string]any)
+	valueMap, isMap := value.(map[string]any)
+
+	if !s.merge || !ok || !isMap {
+		return yamlx.DeepCopy(value)
+	}
+
+	return util.DeepMerge(util.DeepMerge(nil, previousMap), valueMap)
+}
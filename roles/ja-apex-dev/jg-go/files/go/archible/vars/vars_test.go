@@ -0,0 +1,51 @@
+package vars_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/archible/vars"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	sources := []vars.Source{
+		{Level: vars.ExtraVars, Path: "extra.yml", Vars: map[string]any{
+			"wipe": "always",
+		}},
+		{Level: vars.GroupVars, Path: "group.yml", Vars: map[string]any{
+			"wipe": "never", "net": map[string]any{"dhcp": false},
+		}},
+		{Level: vars.RoleDefaults, Path: "defaults.yml", Vars: map[string]any{
+			"wipe": "ask", "user": "root",
+			"net": map[string]any{"dhcp": true, "mtu": 1500},
+		}},
+	}
+
+	result := vars.Resolve(sources)
+
+	assert.Equal(t, map[string]any{
+		"wipe": "always",
+		"user": "root",
+		"net":  map[string]any{"dhcp": false},
+	}, result.Values)
+	assert.Equal(t, "extra.yml", result.Origins["wipe"].Path)
+	assert.Equal(t, vars.RoleDefaults, result.Origins["user"].Level)
+
+	merged := vars.Resolve(sources, vars.WithHashMerge())
+	assert.Equal(t, map[string]any{"dhcp": false, "mtu": 1500},
+		merged.Values["net"])
+	assert.Equal(t, map[string]any{"dhcp": true, "mtu": 1500},
+		sources[2].Vars["net"], "sources are not modified")
+}
+
+func TestLevelString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "role defaults", vars.RoleDefaults.String())
+	assert.Equal(t, "group_vars/all", vars.GroupVarsAll.String())
+	assert.Equal(t, "extra vars", vars.ExtraVars.String())
+	assert.Equal(t, "unknown", vars.Level(42).String())
+}
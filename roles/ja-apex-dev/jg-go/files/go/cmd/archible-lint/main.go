@@ -0,0 +1,85 @@
+// Command archible-lint checks the templates of the Ansible roles of a
+// repository, reporting undefined variables, unused defaults and templates
+// that do not parse. It exits with a non-zero status when it finds any, so
+// that CI jobs fail on template bugs.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"example.com/go-template/archible/lint"
+	"example.com/go-template/util/cli"
+)
+
+// errFindings makes the command fail when diagnostics were reported.
+var errFindings = errors.New("problems found")
+
+// settings are the command-line flags.
+type settings struct {
+	Roles   string   `flag:"roles"   default:"roles" usage:"roles directory"`
+	Vars    []string `flag:"vars"    usage:"group_vars and host_vars paths"`
+	Users   []string `flag:"users"   usage:"playbooks using the roles"`
+	Globals []string `flag:"globals" usage:"variables defined elsewhere"`
+}
+
+// report is the output of a run.
+type report []lint.Diagnostic
+
+// String prints one diagnostic per line.
+func (r report) String() string {
+	var text string
+	for i, d := range r {
+		if i > 0 {
+			text += "\n"
+		}
+
+		text += d.String()
+	}
+
+	return text
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	var cfg settings
+
+	root := &cli.Command{
+		Name:    "archible-lint",
+		Summary: "Check the templates of Ansible roles.",
+		Config:  &cfg,
+		Run: func(_ context.Context, inv *cli.Invocation) error {
+			diagnostics, err := lint.Run(cfg.Roles,
+				lint.WithDefinitions(cfg.Vars...),
+				lint.WithUsers(cfg.Users...),
+				lint.WithGlobals(cfg.Globals...))
+			if err != nil {
+				return err
+			}
+
+			if len(diagnostics) == 0 && !inv.JSON {
+				return nil
+			}
+
+			if err := inv.Output(report(diagnostics)); err != nil {
+				return err
+			}
+
+			if len(diagnostics) > 0 {
+				return fmt.Errorf("%w: %d", errFindings, len(diagnostics))
+			}
+
+			return nil
+		},
+	}
+
+	return cli.Run(ctx, root, args)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/testx"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"roles/app/defaults/main.yml":     "app_port: 80\n",
+		"roles/app/templates/app.conf.j2": "port={{ app_port }}\n",
+		"roles/bad/templates/bad.conf.j2": "{{ missing }}\n",
+	})
+
+	require.NoError(t, run(t.Context(),
+		[]string{"--roles", filepath.Join(dir, "roles/app")}))
+
+	err := run(t.Context(), []string{"--roles", filepath.Join(dir, "roles")})
+	require.ErrorIs(t, err, errFindings)
+	require.ErrorContains(t, err, "problems found: 1")
+}
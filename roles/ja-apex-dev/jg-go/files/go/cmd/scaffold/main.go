@@ -0,0 +1,74 @@
+// Command scaffold generates a new Go project from this template. It copies
+// the tree, renames the module path, rewrites imports, keeps only the
+// selected optional components, renders a matching main.go and runs
+// go mod tidy:
+//
+//	go run ./cmd/scaffold -module github.com/acme/app -out ../app \
+//		-with http,cli
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var errUsage = errors.New("scaffold: -module and -out are required")
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stderr io.Writer) error {
+	var (
+		opts options
+		with string
+	)
+
+	flags := flag.NewFlagSet("scaffold", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.src, "src", ".", "root of the template")
+	flags.StringVar(&opts.out, "out", "",
+		"directory of the new project, which must not exist")
+	flags.StringVar(&opts.module, "module", "",
+		"module path of the new project")
+	flags.StringVar(&with, "with", "",
+		"comma-separated optional components: "+componentNames())
+	flags.BoolVar(&opts.tidy, "tidy", true, "run go mod tidy when done")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("scaffold: %w", err)
+	}
+
+	if opts.module == "" || opts.out == "" {
+		flags.Usage()
+
+		return errUsage
+	}
+
+	selected, err := parseComponents(with)
+	if err != nil {
+		return err
+	}
+
+	opts.components = selected
+
+	return generate(ctx, opts)
+}
+
+// componentNames lists the known components for the usage message.
+func componentNames() string {
+	names := make([]string, len(components))
+	for i, c := range components {
+		names[i] = c.name + " (" + c.summary + ")"
+	}
+
+	return strings.Join(names, ", ")
+}
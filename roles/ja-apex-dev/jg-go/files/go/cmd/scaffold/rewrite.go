@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// modulePath returns the path of the module directive in a go.mod file.
+func modulePath(gomod []byte) string {
+	for line := range strings.Lines(string(gomod)) {
+		if rest, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+
+	return ""
+}
+
+// setModulePath replaces the module directive of a go.mod file.
+func setModulePath(gomod []byte, module string) []byte {
+	var out bytes.Buffer
+
+	for line := range strings.Lines(string(gomod)) {
+		if strings.HasPrefix(line, "module ") {
+			line = "module " + module + "\n"
+		}
+
+		out.WriteString(line)
+	}
+
+	return out.Bytes()
+}
+
+// rewriteImports moves the imports of Go source below the module from to
+// the module to, keeping the import groups sorted. It also returns the
+// imported packages of from, relative to the module root.
+func rewriteImports(src []byte, from, to string) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var imported []string
+
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rel, ok := strings.CutPrefix(importPath, from+"/")
+		if importPath == from {
+			rel, ok = ".", true
+		}
+
+		if !ok {
+			continue
+		}
+
+		imported = append(imported, rel)
+		moved := to + strings.TrimPrefix(importPath, from)
+		spec.Path.Value = strconv.Quote(moved)
+	}
+
+	if imported == nil {
+		return src, nil, nil
+	}
+
+	ast.SortImports(fset, file)
+
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, file); err != nil {
+		return nil, nil, err
+	}
+
+	return out.Bytes(), imported, nil
+}
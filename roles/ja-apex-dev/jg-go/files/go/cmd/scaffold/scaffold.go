@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/fsx"
+	"example.com/go-template/util/tpl"
+)
+
+// templateModule is the module path of the template when the source tree
+// has no go.mod yet.
+const templateModule = "example.com/go-template"
+
+var (
+	errOutputExists     = errors.New("scaffold: output directory exists")
+	errUnknownComponent = errors.New("scaffold: unknown component")
+	errDroppedImport    = errors.New("scaffold: import of a dropped package")
+)
+
+//go:embed main.go.tmpl
+var mainTemplate string
+
+// component is an optional part of the generated project. Its packages
+// are only copied when it is selected.
+type component struct {
+	name     string
+	summary  string
+	packages []string
+}
+
+var components = []component{
+	{
+		name:    "http",
+		summary: "HTTP and gRPC servers, client, downloads and health checks",
+		packages: []string{
+			"util/httpx", "util/download", "util/health", "util/middleware",
+			"util/httpserver", "util/grpcserver", "util/goinstall",
+			"util/dbx",
+		},
+	},
+	{
+		name:     "cli",
+		summary:  "subcommands, flags and shell completion",
+		packages: []string{"util/cli"},
+	},
+	{
+		name:    "worker",
+		summary: "periodic worker, scheduler, job queue and pools",
+		packages: []string{
+			"util/pool", "util/rate", "util/sched", "util/queue",
+		},
+	},
+}
+
+// alwaysSkipped lists the template paths never copied. The root main.go is
+// rendered from mainTemplate instead, and the archible packages only serve
+// the tooling of this repository.
+var alwaysSkipped = []string{
+	".git", "dist", "cmd/scaffold", "cmd/archible-lint", "archible",
+	"main.go",
+}
+
+// parseComponents parses a comma-separated component list.
+func parseComponents(list string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+
+	for name := range strings.SplitSeq(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if !slices.ContainsFunc(components, func(c component) bool {
+			return c.name == name
+		}) {
+			return nil, fmt.Errorf("%w %q", errUnknownComponent, name)
+		}
+
+		selected[name] = true
+	}
+
+	return selected, nil
+}
+
+type options struct {
+	src, out   string
+	module     string
+	components map[string]bool
+	tidy       bool
+}
+
+// scaffolder copies one template tree.
+type scaffolder struct {
+	options
+
+	// from is the module path of the template.
+	from string
+	// skipped holds the slash-separated paths left out of the copy.
+	skipped []string
+	// dropped collects imports of skipped packages by copied files.
+	dropped []error
+}
+
+// generate creates the project described by opts.
+func generate(ctx context.Context, opts options) error {
+	if ok, err := fsx.Exists(opts.out); err != nil || ok {
+		return errors.Join(err, fmt.Errorf("%w: %s", errOutputExists, opts.out))
+	}
+
+	from, err := sourceModule(opts.src)
+	if err != nil {
+		return err
+	}
+
+	s := &scaffolder{options: opts, from: from, skipped: alwaysSkipped}
+
+	for _, c := range components {
+		if !opts.components[c.name] {
+			s.skipped = append(s.skipped, c.packages...)
+		}
+	}
+
+	if err := filepath.WalkDir(opts.src, s.visit); err != nil {
+		return err
+	}
+
+	if err := errors.Join(s.dropped...); err != nil {
+		return errors.Join(err, os.RemoveAll(opts.out))
+	}
+
+	if err := s.finish(); err != nil {
+		return err
+	}
+
+	if !opts.tidy {
+		return nil
+	}
+
+	_, err = execx.New(execx.WithDir(opts.out)).Run(ctx, "go", "mod", "tidy")
+
+	return err
+}
+
+// sourceModule reads the module path from the go.mod of src, falling back
+// to templateModule for trees not initialized yet.
+func sourceModule(src string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(src, "go.mod"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return templateModule, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("scaffold: %w", err)
+	}
+
+	if module := modulePath(data); module != "" {
+		return module, nil
+	}
+
+	return templateModule, nil
+}
+
+// visit copies a walked path unless it is skipped.
+func (s *scaffolder) visit(p string, entry fs.DirEntry, err error) error {
+	if err != nil {
+		return fmt.Errorf("scaffold: %w", err)
+	}
+
+	rel, err := filepath.Rel(s.src, p)
+	if err != nil {
+		return fmt.Errorf("scaffold: %w", err)
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	switch {
+	case slices.Contains(s.skipped, rel) && entry.IsDir():
+		return filepath.SkipDir
+	case slices.Contains(s.skipped, rel):
+		return nil
+	case entry.IsDir():
+		return fsx.EnsureDir(s.target(rel), 0o755)
+	default:
+		return s.copyFile(p, rel)
+	}
+}
+
+func (s *scaffolder) target(rel string) string {
+	return filepath.Join(s.out, filepath.FromSlash(rel))
+}
+
+// copyFile copies a file, renaming the module in its content.
+func (s *scaffolder) copyFile(p, rel string) error {
+	info, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("scaffold: %w", err)
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("scaffold: %w", err)
+	}
+
+	switch {
+	case strings.HasSuffix(rel, ".go"):
+		data, err = s.rewriteGo(rel, data)
+	case rel == "go.mod":
+		data = setModulePath(data, s.module)
+	case isText(data):
+		data = s.rewriteText(data)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return fsx.AtomicWriteFile(s.target(rel), data, info.Mode().Perm())
+}
+
+// rewriteGo rewrites the imports of a Go file, recording imports of
+// skipped packages.
+func (s *scaffolder) rewriteGo(rel string, data []byte) ([]byte, error) {
+	out, imported, err := rewriteImports(data, s.from, s.module)
+	if err != nil {
+		return nil, fmt.Errorf("scaffold: %s: %w", rel, err)
+	}
+
+	for _, pkg := range imported {
+		if slices.Contains(s.skipped, pkg) {
+			s.dropped = append(s.dropped, fmt.Errorf("%w: %s imports %s",
+				errDroppedImport, rel, pkg))
+		}
+	}
+
+	return out, nil
+}
+
+// rewriteText renames the module and the project, based on the last
+// element of the module path, in documentation and configuration files.
+func (s *scaffolder) rewriteText(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte(s.from), []byte(s.module))
+
+	return bytes.ReplaceAll(data, []byte(path.Base(s.from)),
+		[]byte(path.Base(s.module)))
+}
+
+// finish writes the rendered main.go and a go.mod for trees lacking one.
+func (s *scaffolder) finish() error {
+	source, err := s.renderMain()
+	if err != nil {
+		return err
+	}
+
+	err = fsx.AtomicWriteFile(s.target("main.go"), source, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := fsx.Exists(s.target("go.mod")); err != nil || ok {
+		return err
+	}
+
+	return fsx.AtomicWriteFile(s.target("go.mod"),
+		[]byte("module "+s.module+"\n"), 0o644)
+}
+
+// mainData is the input of mainTemplate.
+type mainData struct {
+	Name, Module      string
+	HTTP, CLI, Worker bool
+	// Service is set when long-running components need the lifecycle
+	// runner.
+	Service bool
+}
+
+func (s *scaffolder) renderMain() ([]byte, error) {
+	data := mainData{
+		Name:   path.Base(s.module),
+		Module: s.module,
+		HTTP:   s.components["http"],
+		CLI:    s.components["cli"],
+		Worker: s.components["worker"],
+	}
+	data.Service = data.HTTP || data.Worker
+
+	text, err := tpl.RenderString(mainTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("scaffold: %w", err)
+	}
+
+	source, err := format.Source([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("scaffold: rendered main.go: %w", err)
+	}
+
+	return source, nil
+}
+
+// isText reports whether data looks like text rather than binary content.
+func isText(data []byte) bool {
+	return !bytes.Contains(data[:min(len(data), 8000)], []byte{0})
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/testx"
+)
+
+const module = "github.com/acme/app"
+
+func fixture(t *testing.T, extra map[string]string) string {
+	t.Helper()
+
+	files := map[string]string{
+		"go.mod":               "module example.com/go-template\n\ngo 1.27\n",
+		"README.md":            "# go-template\n",
+		"main.go":              "package main\n",
+		"cmd/scaffold/main.go": "package main\n",
+		"util/util.go":         "package util\n",
+		"util/httpx/httpx.go":  "package httpx\n",
+		"util/pool/pool.go":    "package pool\n",
+		"util/log/log.go": "package log\n\nimport (\n\t\"fmt\"\n\n" +
+			"\t\"example.com/go-template/util\"\n)\n",
+	}
+
+	for name, content := range extra {
+		files[name] = content
+	}
+
+	return testx.TempDirWithFiles(t, files)
+}
+
+func generateTo(t *testing.T, src string, with ...string) (string, error) {
+	t.Helper()
+
+	out := filepath.Join(t.TempDir(), "app")
+	selected := make(map[string]bool)
+
+	for _, name := range with {
+		selected[name] = true
+	}
+
+	return out, generate(t.Context(), options{
+		src: src, out: out, module: module, components: selected,
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	out, err := generateTo(t, fixture(t, nil), "http")
+	require.NoError(t, err)
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	assert.Equal(t, "module "+module+"\n\ngo 1.27\n", read("go.mod"))
+	assert.Equal(t, "# app\n", read("README.md"))
+	assert.Contains(t, read("util/log/log.go"), `"`+module+`/util"`)
+	assert.FileExists(t, filepath.Join(out, "util/httpx/httpx.go"))
+	assert.NoDirExists(t, filepath.Join(out, "util/pool"))
+	assert.NoDirExists(t, filepath.Join(out, "cmd/scaffold"))
+	assert.Contains(t, read("main.go"), `"`+module+`/util/lifecycle"`)
+}
+
+func TestGenerateWithoutGoMod(t *testing.T) {
+	t.Parallel()
+
+	src := fixture(t, nil)
+	require.NoError(t, os.Remove(filepath.Join(src, "go.mod")))
+
+	out, err := generateTo(t, src)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(out, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, "module "+module+"\n", string(data))
+}
+
+func TestGenerateDroppedImport(t *testing.T) {
+	t.Parallel()
+
+	src := fixture(t, map[string]string{
+		"util/jobs.go": "package util\n\n" +
+			"import _ \"example.com/go-template/util/pool\"\n",
+	})
+
+	out, err := generateTo(t, src)
+	require.ErrorIs(t, err, errDroppedImport)
+	assert.ErrorContains(t, err, "util/jobs.go imports util/pool")
+	assert.NoDirExists(t, out)
+
+	_, err = generateTo(t, src, "worker")
+	require.NoError(t, err)
+}
+
+func TestGenerateOutputExists(t *testing.T) {
+	t.Parallel()
+
+	err := generate(t.Context(), options{
+		src: fixture(t, nil), out: t.TempDir(), module: module,
+	})
+	require.ErrorIs(t, err, errOutputExists)
+}
+
+func TestRenderMain(t *testing.T) {
+	t.Parallel()
+
+	for mask := range 1 << len(components) {
+		selected := make(map[string]bool)
+
+		for i, c := range components {
+			selected[c.name] = mask&(1<<i) != 0
+		}
+
+		s := &scaffolder{options: options{module: module, components: selected}}
+
+		source, err := s.renderMain()
+		require.NoError(t, err, selected)
+
+		_, err = parser.ParseFile(token.NewFileSet(), "main.go", source, 0)
+		require.NoError(t, err, selected)
+
+		// The packages kept by every selection build without the others.
+		err = generate(t.Context(), options{
+			src:        filepath.Join("..", ".."),
+			out:        filepath.Join(t.TempDir(), "app"),
+			module:     module,
+			components: selected,
+		})
+		require.NoError(t, err, selected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var stderr bytes.Buffer
+
+	err := run(t.Context(), []string{"-out", "app"}, &stderr)
+	require.ErrorIs(t, err, errUsage)
+	assert.Contains(t, stderr.String(), "-module")
+
+	err = run(t.Context(),
+		[]string{"-module", module, "-out", "app", "-with", "http,gui"},
+		&stderr)
+	require.ErrorIs(t, err, errUnknownComponent)
+}
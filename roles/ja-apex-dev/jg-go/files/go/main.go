@@ -1,3 +1,4 @@
+// Command go-template is the entry point of the project.
 package main
 
 import (
@@ -0,0 +1,376 @@
+// Package archive extracts and creates tar.gz and zip archives. Extraction
+// is hardened against hostile archives: entries may not escape the
+// destination through absolute paths, ".." components (zip-slip) or
+// previously extracted symbolic links, link targets are checked against a
+// SymlinkPolicy and the total size can be bounded with WithMaxBytes.
+//
+//	err := archive.Extract(ctx, "go1.22.3.linux-amd64.tar.gz", "/opt/go",
+//		archive.WithStripComponents(1))
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnsafePath is returned for entries escaping the destination.
+	ErrUnsafePath = errors.New("archive: unsafe path")
+	// ErrSymlink is returned for symbolic links rejected by the policy.
+	ErrSymlink = errors.New("archive: symlink not allowed")
+	// ErrTooLarge is returned once the extracted files exceed the limit set
+	// by WithMaxBytes.
+	ErrTooLarge = errors.New("archive: size limit exceeded")
+	// ErrUnsupportedEntry is returned for devices, FIFOs and other special
+	// entries.
+	ErrUnsupportedEntry = errors.New("archive: unsupported entry type")
+	// ErrUnknownFormat is returned by Extract for unrecognized file names.
+	ErrUnknownFormat = errors.New("archive: unknown archive format")
+)
+
+// SymlinkPolicy decides what happens to symbolic links during extraction.
+type SymlinkPolicy int
+
+const (
+	// SymlinksWithin creates links whose target stays inside the
+	// destination and rejects the others. It is the default.
+	SymlinksWithin SymlinkPolicy = iota
+	// SymlinksSkip silently ignores every link.
+	SymlinksSkip
+	// SymlinksDeny rejects every link.
+	SymlinksDeny
+)
+
+// Progress describes the extraction or creation so far.
+type Progress struct {
+	// Name is the archive path of the entry just processed.
+	Name string
+	// Entries is the number of entries processed.
+	Entries int
+	// Bytes is the total size of the regular files processed.
+	Bytes int64
+}
+
+// Option configures extraction and creation.
+type Option func(*settings)
+
+type settings struct {
+	symlinks SymlinkPolicy
+	progress func(Progress)
+	strip    int
+	maxBytes int64
+}
+
+// WithSymlinks sets the symbolic link policy of extraction.
+func WithSymlinks(policy SymlinkPolicy) Option {
+	return func(s *settings) {
+		s.symlinks = policy
+	}
+}
+
+// WithProgress calls fn after every entry.
+func WithProgress(fn func(Progress)) Option {
+	return func(s *settings) {
+		s.progress = fn
+	}
+}
+
+// WithStripComponents drops the first n path components of every entry
+// during extraction, as tar --strip-components. Entries left without a
+// name are skipped.
+func WithStripComponents(n int) Option {
+	return func(s *settings) {
+		s.strip = n
+	}
+}
+
+// WithMaxBytes bounds the total size of the extracted files. Zero, the
+// default, means unbounded.
+func WithMaxBytes(n int64) Option {
+	return func(s *settings) {
+		s.maxBytes = n
+	}
+}
+
+func newSettings(opts []Option) settings {
+	var s settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+// Extract unpacks the archive at path into dest, choosing the format from
+// the extension: .tar.gz, .tgz, .tar or .zip.
+func Extract(ctx context.Context, path, dest string, opts ...Option) error {
+	name := strings.ToLower(path)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ExtractZip(ctx, path, dest, opts...)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"),
+		strings.HasSuffix(name, ".tar"):
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(name, ".tar") {
+		return ExtractTar(ctx, file, dest, opts...)
+	}
+
+	return ExtractTarGz(ctx, file, dest, opts...)
+}
+
+// extractor writes the entries of one archive below dest.
+type extractor struct {
+	settings
+
+	dest     string
+	progress Progress
+	// dirModes defers directory permissions until the end, so read-only
+	// directories can still be populated.
+	dirModes map[string]fs.FileMode
+}
+
+func newExtractor(dest string, opts []Option) (*extractor, error) {
+	abs, err := filepath.Abs(dest)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	return &extractor{
+		settings: newSettings(opts),
+		dest:     abs,
+		dirModes: make(map[string]fs.FileMode),
+	}, nil
+}
+
+// target returns the slash-separated relative path of the entry called
+// name, or false when it is stripped away.
+func (e *extractor) target(name string) (string, bool, error) {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '/' })
+	if len(parts) <= e.strip {
+		return "", false, nil
+	}
+
+	rel := strings.Join(parts[e.strip:], "/")
+
+	local := filepath.IsLocal(filepath.FromSlash(rel))
+	if !local || strings.HasPrefix(name, "/") {
+		return "", false, fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	if err := e.checkParents(rel); err != nil {
+		return "", false, err
+	}
+
+	return rel, true, nil
+}
+
+// checkParents rejects paths going through an extracted symbolic link,
+// which could otherwise redirect writes outside the destination.
+func (e *extractor) checkParents(rel string) error {
+	path := e.dest
+
+	parents := strings.Split(rel, "/")
+	for _, part := range parents[:len(parents)-1] {
+		path = filepath.Join(path, part)
+
+		info, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s goes through a symlink",
+				ErrUnsafePath, rel)
+		}
+	}
+
+	return nil
+}
+
+func (e *extractor) path(rel string) string {
+	return filepath.Join(e.dest, filepath.FromSlash(rel))
+}
+
+func (e *extractor) dir(rel string, mode fs.FileMode) error {
+	path := e.path(rel)
+	if err := os.MkdirAll(path, 0o750); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	e.dirModes[path] = mode.Perm()
+
+	return nil
+}
+
+// file writes the content of a regular file, replacing whatever exists at
+// its path.
+func (e *extractor) file(rel string, mode fs.FileMode, r io.Reader) error {
+	path, err := e.prepare(rel)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+		mode.Perm())
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	_, err = io.Copy(out, e.limit(r))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("archive: %s: %w", rel, err)
+	}
+
+	// OpenFile is subject to the umask.
+	if err := os.Chmod(path, mode.Perm()); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	return nil
+}
+
+// limit counts the bytes read from r against the size limit.
+func (e *extractor) limit(r io.Reader) io.Reader {
+	return &countingReader{reader: r, extractor: e}
+}
+
+func (e *extractor) symlink(rel, link string) error {
+	switch e.symlinks {
+	case SymlinksSkip:
+		return nil
+	case SymlinksDeny:
+		return fmt.Errorf("%w: %s", ErrSymlink, rel)
+	default:
+	}
+
+	resolved := filepath.Join(filepath.Dir(filepath.FromSlash(rel)),
+		filepath.FromSlash(link))
+	if filepath.IsAbs(link) || !filepath.IsLocal(resolved) {
+		return fmt.Errorf("%w: %s points outside to %s", ErrSymlink, rel, link)
+	}
+
+	path, err := e.prepare(rel)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Symlink(link, path); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	return nil
+}
+
+// hardlink links rel to the previously extracted entry at link.
+func (e *extractor) hardlink(rel, link string) error {
+	source, ok, err := e.target(link)
+	if err != nil || !ok {
+		return fmt.Errorf("%w: hard link %s to %s", ErrUnsafePath, rel, link)
+	}
+
+	path, err := e.prepare(rel)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Link(e.path(source), path); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	return nil
+}
+
+// prepare creates the parent directories of rel and removes an existing
+// non-directory entry at its path.
+func (e *extractor) prepare(rel string) (string, error) {
+	path := e.path(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("archive: %w", err)
+	}
+
+	info, err := os.Lstat(path)
+	if err == nil && !info.IsDir() {
+		err = os.Remove(path)
+	}
+
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("archive: %w", err)
+	}
+
+	return path, nil
+}
+
+func (e *extractor) report(name string) {
+	e.progress.Name = name
+	e.progress.Entries++
+
+	if e.settings.progress != nil {
+		e.settings.progress(e.progress)
+	}
+}
+
+// finish applies the directory permissions, deepest first.
+func (e *extractor) finish() error {
+	paths := make([]string, 0, len(e.dirModes))
+	for path := range e.dirModes {
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+
+	for _, path := range slices.Backward(paths) {
+		if err := os.Chmod(path, e.dirModes[path]); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// countingReader adds the bytes read to the progress of an extractor and
+// fails once they exceed the size limit.
+type countingReader struct {
+	reader    io.Reader
+	extractor *extractor
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.extractor.progress.Bytes += int64(n)
+
+	limit := r.extractor.maxBytes
+	if limit > 0 && r.extractor.progress.Bytes > limit {
+		return n, fmt.Errorf("%w: over %d bytes", ErrTooLarge, limit)
+	}
+
+	return n, err
+}
@@ -0,0 +1,118 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/archive"
+)
+
+// entry describes an archive member built by the helpers.
+type entry struct {
+	name    string
+	content string
+	link    string
+	mode    fs.FileMode
+}
+
+func tarGz(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		header := &tar.Header{
+			Name: e.name, Mode: int64(e.mode.Perm()), Typeflag: tar.TypeReg,
+			Size: int64(len(e.content)),
+		}
+
+		switch {
+		case e.mode.IsDir():
+			header.Typeflag = tar.TypeDir
+		case e.mode&fs.ModeSymlink != 0:
+			header.Typeflag, header.Linkname = tar.TypeSymlink, e.link
+		}
+
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func zipFile(t *testing.T, entries ...entry) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		header.SetMode(e.mode)
+
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+
+		content := e.content
+		if e.mode&fs.ModeSymlink != 0 {
+			content = e.link
+		}
+
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	path := filepath.Join(t.TempDir(), "test.zip")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join(t.TempDir(), "tool.tgz")
+	require.NoError(t, os.WriteFile(src, tarGz(t,
+		entry{name: "tool/bin/run", content: "#!/bin/sh\n", mode: 0o755},
+	), 0o600))
+
+	dest := t.TempDir()
+	require.NoError(t, archive.Extract(t.Context(), src, dest,
+		archive.WithStripComponents(1)))
+	assert.Equal(t, "#!/bin/sh\n", readFile(t, filepath.Join(dest, "bin/run")))
+
+	zipped := zipFile(t, entry{name: "a.txt", content: "a", mode: 0o644})
+	require.NoError(t, archive.Extract(t.Context(), zipped, dest))
+	assert.Equal(t, "a", readFile(t, filepath.Join(dest, "a.txt")))
+
+	err := archive.Extract(t.Context(), "tool.rar", dest)
+	require.ErrorIs(t, err, archive.ErrUnknownFormat)
+}
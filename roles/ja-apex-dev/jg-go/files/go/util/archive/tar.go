@@ -0,0 +1,227 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ExtractTarGz unpacks the gzip-compressed tar stream r into dest.
+func ExtractTarGz(
+	ctx context.Context, r io.Reader, dest string, opts ...Option,
+) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer gz.Close()
+
+	return ExtractTar(ctx, gz, dest, opts...)
+}
+
+// ExtractTar unpacks the uncompressed tar stream r into dest, stopping
+// between entries once ctx is done.
+func ExtractTar(
+	ctx context.Context, r io.Reader, dest string, opts ...Option,
+) error {
+	e, err := newExtractor(dest, opts)
+	if err != nil {
+		return err
+	}
+
+	reader := tar.NewReader(r)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return e.finish()
+		}
+
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		if err := e.tarEntry(header, reader); err != nil {
+			return err
+		}
+	}
+}
+
+// tarEntry extracts a single entry. Global pax headers carry no file and
+// are skipped.
+func (e *extractor) tarEntry(header *tar.Header, r io.Reader) error {
+	if header.Typeflag == tar.TypeXGlobalHeader {
+		return nil
+	}
+
+	rel, ok, err := e.target(header.Name)
+	if err != nil || !ok {
+		return err
+	}
+
+	mode := header.FileInfo().Mode()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		err = e.dir(rel, mode)
+	case tar.TypeReg:
+		err = e.file(rel, mode, r)
+	case tar.TypeSymlink:
+		err = e.symlink(rel, header.Linkname)
+	case tar.TypeLink:
+		err = e.hardlink(rel, header.Linkname)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedEntry, header.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	e.report(header.Name)
+
+	return nil
+}
+
+// CreateTarGz writes the tree rooted at src to w as a gzip-compressed tar
+// stream, keeping permission bits, modification times and symbolic links.
+// Entry names are relative to src.
+func CreateTarGz(
+	ctx context.Context, w io.Writer, src string, opts ...Option,
+) error {
+	gz := gzip.NewWriter(w)
+	writer := tar.NewWriter(gz)
+	c := &creator{settings: newSettings(opts), src: src, writer: writer}
+
+	if err := filepath.WalkDir(src, func(path string, entry fs.DirEntry,
+		err error,
+	) error {
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		return c.add(path, entry)
+	}); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	return nil
+}
+
+// creator adds the files of one tree to a tar stream.
+type creator struct {
+	settings
+
+	src      string
+	writer   *tar.Writer
+	progress Progress
+}
+
+// add writes the header and content of the walked path, skipping the root
+// itself.
+func (c *creator) add(path string, entry fs.DirEntry) error {
+	rel, err := filepath.Rel(c.src, path)
+	if err != nil || rel == "." {
+		return err
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	header, err := c.header(path, rel, info)
+	if err != nil {
+		return err
+	}
+
+	if err := c.writer.WriteHeader(header); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	if info.Mode().IsRegular() {
+		if err := c.copyFile(path); err != nil {
+			return err
+		}
+
+		c.progress.Bytes += info.Size()
+	}
+
+	c.progress.Name = header.Name
+	c.progress.Entries++
+
+	if c.settings.progress != nil {
+		c.settings.progress(c.progress)
+	}
+
+	return nil
+}
+
+// header describes path as a tar entry named after rel, reading the target
+// of symbolic links.
+func (c *creator) header(
+	path, rel string, info fs.FileInfo,
+) (*tar.Header, error) {
+	var link string
+
+	switch mode := info.Mode(); {
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, fmt.Errorf("archive: %w", err)
+		}
+
+		link = target
+	case !mode.IsRegular() && !mode.IsDir():
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEntry, path)
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	header.Name = filepath.ToSlash(rel)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	return header, nil
+}
+
+// copyFile streams the content of path after its header.
+func (c *creator) copyFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(c.writer, file); err != nil {
+		return fmt.Errorf("archive: %s: %w", path, err)
+	}
+
+	return nil
+}
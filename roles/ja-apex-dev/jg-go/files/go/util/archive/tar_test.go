@@ -0,0 +1,153 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/archive"
+)
+
+func TestExtractTarGz(t *testing.T) {
+	t.Parallel()
+
+	data := tarGz(t,
+		entry{name: "go/", mode: fs.ModeDir | 0o755},
+		entry{name: "go/bin/go", content: "binary", mode: 0o755},
+		entry{name: "go/VERSION", content: "go1.22.3", mode: 0o644},
+		entry{name: "go/current", link: "VERSION", mode: fs.ModeSymlink},
+	)
+
+	var progress []archive.Progress
+
+	dest := t.TempDir()
+	require.NoError(t, archive.ExtractTarGz(t.Context(),
+		bytes.NewReader(data), dest,
+		archive.WithProgress(func(p archive.Progress) {
+			progress = append(progress, p)
+		})))
+
+	assert.Equal(t, "go1.22.3", readFile(t, filepath.Join(dest, "go/current")))
+
+	info, err := os.Stat(filepath.Join(dest, "go/bin/go"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o755), info.Mode().Perm())
+
+	require.Len(t, progress, 4)
+	assert.Equal(t, archive.Progress{
+		Name: "go/current", Entries: 4, Bytes: 14,
+	}, progress[3])
+}
+
+func TestExtractTarUnsafe(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]entry{
+		"parent":   {{name: "../evil", content: "x", mode: 0o644}},
+		"nested":   {{name: "a/../../evil", content: "x", mode: 0o644}},
+		"absolute": {{name: "/etc/evil", content: "x", mode: 0o644}},
+		"through link": {
+			{name: "link", link: ".", mode: fs.ModeSymlink},
+			{name: "link/evil", content: "x", mode: 0o644},
+		},
+	}
+
+	for name, entries := range tests {
+		dest := t.TempDir()
+		err := archive.ExtractTarGz(t.Context(),
+			bytes.NewReader(tarGz(t, entries...)), dest)
+		require.ErrorIs(t, err, archive.ErrUnsafePath, name)
+	}
+}
+
+func TestExtractTarSymlinkPolicy(t *testing.T) {
+	t.Parallel()
+
+	outside := tarGz(t,
+		entry{name: "passwd", link: "../../etc/passwd", mode: fs.ModeSymlink})
+	err := archive.ExtractTarGz(t.Context(), bytes.NewReader(outside),
+		t.TempDir())
+	require.ErrorIs(t, err, archive.ErrSymlink)
+
+	absolute := tarGz(t,
+		entry{name: "passwd", link: "/etc/passwd", mode: fs.ModeSymlink})
+	err = archive.ExtractTarGz(t.Context(), bytes.NewReader(absolute),
+		t.TempDir())
+	require.ErrorIs(t, err, archive.ErrSymlink)
+
+	inside := tarGz(t,
+		entry{name: "a", content: "a", mode: 0o644},
+		entry{name: "b", link: "a", mode: fs.ModeSymlink})
+	err = archive.ExtractTarGz(t.Context(), bytes.NewReader(inside),
+		t.TempDir(), archive.WithSymlinks(archive.SymlinksDeny))
+	require.ErrorIs(t, err, archive.ErrSymlink)
+
+	dest := t.TempDir()
+	require.NoError(t, archive.ExtractTarGz(t.Context(),
+		bytes.NewReader(inside), dest,
+		archive.WithSymlinks(archive.SymlinksSkip)))
+
+	_, err = os.Lstat(filepath.Join(dest, "b"))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestExtractTarLimits(t *testing.T) {
+	t.Parallel()
+
+	data := tarGz(t,
+		entry{name: "a", content: "12345", mode: 0o644},
+		entry{name: "b", content: "67890", mode: 0o644},
+	)
+
+	err := archive.ExtractTarGz(t.Context(), bytes.NewReader(data),
+		t.TempDir(), archive.WithMaxBytes(8))
+	require.ErrorIs(t, err, archive.ErrTooLarge)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err = archive.ExtractTarGz(ctx, bytes.NewReader(data), t.TempDir())
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCreateTarGz(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "bin"), 0o750))
+	require.NoError(t,
+		os.WriteFile(filepath.Join(src, "bin/tool"), []byte("run"), 0o755))
+	require.NoError(t, os.Chmod(filepath.Join(src, "bin/tool"), 0o755))
+	require.NoError(t,
+		os.WriteFile(filepath.Join(src, "README"), []byte("hi"), 0o600))
+	require.NoError(t, os.Symlink("bin/tool", filepath.Join(src, "tool")))
+	require.NoError(t, os.Chmod(filepath.Join(src, "bin"), 0o700))
+
+	var buf bytes.Buffer
+
+	entries := 0
+	require.NoError(t, archive.CreateTarGz(t.Context(), &buf, src,
+		archive.WithProgress(func(p archive.Progress) { entries = p.Entries })))
+	assert.Equal(t, 4, entries)
+
+	dest := t.TempDir()
+	require.NoError(t, archive.ExtractTarGz(t.Context(), &buf, dest))
+
+	for path, want := range map[string]fs.FileMode{
+		"bin": fs.ModeDir | 0o700, "bin/tool": 0o755, "README": 0o600,
+	} {
+		info, err := os.Stat(filepath.Join(dest, path))
+		require.NoError(t, err)
+		assert.Equal(t, want, info.Mode(), path)
+	}
+
+	link, err := os.Readlink(filepath.Join(dest, "tool"))
+	require.NoError(t, err)
+	assert.Equal(t, "bin/tool", link)
+}
@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// maxLinkSize bounds the target of a zipped symbolic link.
+const maxLinkSize = 4096
+
+// ExtractZip unpacks the zip file at path into dest, stopping between
+// entries once ctx is done.
+func ExtractZip(ctx context.Context, path, dest string, opts ...Option) error {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer reader.Close()
+
+	e, err := newExtractor(dest, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		if err := e.zipEntry(file); err != nil {
+			return err
+		}
+	}
+
+	return e.finish()
+}
+
+// zipEntry extracts a single entry according to its mode bits.
+func (e *extractor) zipEntry(file *zip.File) error {
+	rel, ok, err := e.target(file.Name)
+	if err != nil || !ok {
+		return err
+	}
+
+	mode := file.Mode()
+
+	switch {
+	case mode.IsDir():
+		err = e.dir(rel, mode)
+	case mode&fs.ModeSymlink != 0:
+		err = e.zipSymlink(rel, file)
+	case mode.IsRegular():
+		err = e.zipFile(rel, file)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedEntry, file.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	e.report(file.Name)
+
+	return nil
+}
+
+// zipFile decompresses a regular file entry.
+func (e *extractor) zipFile(rel string, file *zip.File) error {
+	content, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer content.Close()
+
+	return e.file(rel, file.Mode(), content)
+}
+
+// zipSymlink creates a link whose target is stored as the entry content.
+func (e *extractor) zipSymlink(rel string, file *zip.File) error {
+	content, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer content.Close()
+
+	link, err := io.ReadAll(io.LimitReader(content, maxLinkSize))
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+
+	return e.symlink(rel, string(link))
+}
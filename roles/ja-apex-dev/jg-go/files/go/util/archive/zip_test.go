@@ -0,0 +1,44 @@
+package archive_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/archive"
+)
+
+func TestExtractZip(t *testing.T) {
+	t.Parallel()
+
+	path := zipFile(t,
+		entry{name: "dist/", mode: fs.ModeDir | 0o755},
+		entry{name: "dist/tool", content: "binary", mode: 0o755},
+		entry{name: "dist/latest", link: "tool", mode: fs.ModeSymlink},
+	)
+
+	dest := t.TempDir()
+	require.NoError(t, archive.ExtractZip(t.Context(), path, dest))
+	assert.Equal(t, "binary", readFile(t, filepath.Join(dest, "dist/latest")))
+
+	info, err := os.Stat(filepath.Join(dest, "dist/tool"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o755), info.Mode().Perm())
+}
+
+func TestExtractZipUnsafe(t *testing.T) {
+	t.Parallel()
+
+	slip := zipFile(t, entry{name: "../../evil", content: "x", mode: 0o644})
+	err := archive.ExtractZip(t.Context(), slip, t.TempDir())
+	require.ErrorIs(t, err, archive.ErrUnsafePath)
+
+	link := zipFile(t,
+		entry{name: "evil", link: "../outside", mode: fs.ModeSymlink})
+	err = archive.ExtractZip(t.Context(), link, t.TempDir())
+	require.ErrorIs(t, err, archive.ErrSymlink)
+}
@@ -0,0 +1,193 @@
+// Package audit records who changed what, as a tamper-evident log of
+// structured events: every event holds the hash of the previous one, so
+// that editing, removing or reordering events breaks the chain, which
+// Verify detects.
+//
+// A Writer buffers the events and flushes them to a Sink, such as an
+// append-only JSON Lines file rotated by size or an HTTP collector:
+//
+//	sink, err := audit.NewFileSink("/var/log/archible/audit.jsonl")
+//	...
+//	w, err := audit.New(sink)
+//	...
+//	defer w.Close()
+//	err = w.Record(ctx, audit.Event{
+//		Actor:  "deploy",
+//		Action: "package.install",
+//		Target: "nginx",
+//		Diff:   diff.Compare(before, after),
+//	})
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"example.com/go-template/util/diff"
+)
+
+var (
+	// ErrTampered is returned by Verify for a broken chain.
+	ErrTampered = errors.New("audit: chain broken")
+	// ErrClosed is returned by the writes to a closed Writer.
+	ErrClosed = errors.New("audit: writer closed")
+	// ErrBacklog is returned by Record when the sink keeps failing and
+	// too many events are pending.
+	ErrBacklog = errors.New("audit: too many pending events")
+)
+
+// Event is a change made by an actor. Seq, Time, Prev and Hash are set by
+// the Writer.
+type Event struct {
+	// Seq numbers the events of a chain from 1.
+	Seq uint64 `json:"seq"`
+	// Time is when the event was recorded, in UTC.
+	Time time.Time `json:"time"`
+	// Actor is who made the change, such as a user or a service.
+	Actor string `json:"actor"`
+	// Action is what was done, such as "package.install".
+	Action string `json:"action"`
+	// Target is what the action applied to.
+	Target string `json:"target,omitempty"`
+	// Diff lists the changes made to the target.
+	Diff diff.Changes `json:"diff,omitempty"`
+	// Meta holds additional context, such as a request ID.
+	Meta map[string]any `json:"meta,omitempty"`
+	// Prev is the hash of the previous event, empty for the first one.
+	Prev string `json:"prev,omitempty"`
+	// Hash is the hex SHA-256 of Prev followed by the JSON encoding of
+	// the event without Hash. It must remain the last field.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Sink stores sealed events, each a JSON object without a trailing
+// newline.
+type Sink interface {
+	// Write stores all the lines or none, in order.
+	Write(ctx context.Context, lines [][]byte) error
+	// Close releases the sink once the Writer is closed.
+	Close() error
+}
+
+// Resumer is implemented by the sinks able to read their events back,
+// which lets a new Writer extend their chain.
+type Resumer interface {
+	// Last returns the last line stored, or nil when there is none.
+	Last() ([]byte, error)
+}
+
+// hashField precedes the hash at the end of a sealed event.
+const hashField = `,"hash":"`
+
+// seal sets the hash of e and returns its encoding.
+func seal(e *Event) ([]byte, error) {
+	e.Hash = ""
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+
+	e.Hash = digest(e.Prev, body)
+
+	line := append(body[:len(body)-1], hashField...)
+
+	return append(append(line, e.Hash...), '"', '}'), nil
+}
+
+// digest hashes prev and the encoding of an event without its hash.
+func digest(prev string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// unseal decodes a sealed line, checking its own hash.
+func unseal(line []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(line, &e); err != nil {
+		return e, fmt.Errorf("%w: %w", ErrTampered, err)
+	}
+
+	pos := bytes.LastIndex(line, []byte(hashField))
+	if pos < 0 || !bytes.Equal(line[pos:],
+		[]byte(hashField+e.Hash+`"}`)) {
+		return e, fmt.Errorf("%w: hash is not the last field", ErrTampered)
+	}
+
+	body := append(bytes.Clone(line[:pos]), '}')
+	if digest(e.Prev, body) != e.Hash {
+		return e, fmt.Errorf("%w: hash mismatch", ErrTampered)
+	}
+
+	return e, nil
+}
+
+// Verify checks the chain of the events read from r, one per line, and
+// returns the last one. The first event may follow events no longer
+// available, such as deleted rotated files.
+func Verify(r io.Reader) (Event, error) {
+	var (
+		last    Event
+		scanner = bufio.NewScanner(r)
+	)
+
+	scanner.Buffer(nil, maxLine)
+
+	for n := 1; scanner.Scan(); n++ {
+		e, err := unseal(scanner.Bytes())
+		if err == nil && n > 1 && (e.Seq != last.Seq+1 || e.Prev != last.Hash) {
+			err = fmt.Errorf("%w: event %d follows %d", ErrTampered, e.Seq,
+				last.Seq)
+		}
+
+		if err != nil {
+			return last, fmt.Errorf("%w (line %d)", err, n)
+		}
+
+		last = e
+	}
+
+	if err := scanner.Err(); err != nil {
+		return last, fmt.Errorf("audit: %w", err)
+	}
+
+	return last, nil
+}
+
+// maxLine bounds the size of an event read back.
+const maxLine = 16 << 20
+
+// VerifyFiles checks the chain running through the files at paths, in
+// order, as listed by Files.
+func VerifyFiles(paths ...string) (Event, error) {
+	readers := make([]io.Reader, 0, len(paths))
+
+	defer func() {
+		for _, r := range readers {
+			_ = r.(io.Closer).Close()
+		}
+	}()
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return Event{}, fmt.Errorf("audit: %w", err)
+		}
+
+		readers = append(readers, f)
+	}
+
+	return Verify(io.MultiReader(readers...))
+}
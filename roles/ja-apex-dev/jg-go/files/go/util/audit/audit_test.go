@@ -0,0 +1,205 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/audit"
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/diff"
+	"example.com/go-template/util/testx"
+)
+
+var epoch = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+var errDown = errors.New("down")
+
+// memorySink keeps the lines, failing while down is set.
+type memorySink struct {
+	mu     sync.Mutex
+	lines  [][]byte
+	down   bool
+	closed bool
+}
+
+func (s *memorySink) Write(_ context.Context, lines [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		return errDown
+	}
+
+	s.lines = append(s.lines, lines...)
+
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	return nil
+}
+
+func (s *memorySink) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.down = down
+}
+
+// log returns the lines as a JSON Lines document.
+func (s *memorySink) log() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return string(bytes.Join(s.lines, []byte("\n")))
+}
+
+func (s *memorySink) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.lines)
+}
+
+func record(t *testing.T, w *audit.Writer, actions ...string) {
+	t.Helper()
+
+	for _, action := range actions {
+		require.NoError(t, w.Record(t.Context(), audit.Event{
+			Actor:  "tester",
+			Action: action,
+			Target: "nginx",
+			Diff: diff.Compare(map[string]any{"version": "1.0"},
+				map[string]any{"version": "1.1"}),
+		}))
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{}
+	w, err := audit.New(sink, audit.WithBufferSize(2),
+		audit.WithFlushInterval(0), audit.WithClock(clock.NewFake(epoch)))
+	require.NoError(t, err)
+
+	record(t, w, "install")
+	assert.Zero(t, sink.len(), "buffered")
+
+	record(t, w, "configure", "restart")
+	assert.Equal(t, 2, sink.len(), "flushed when full")
+
+	require.NoError(t, w.Close())
+	assert.True(t, sink.closed)
+	assert.ErrorIs(t, w.Record(t.Context(), audit.Event{}), audit.ErrClosed)
+
+	last, err := audit.Verify(strings.NewReader(sink.log()))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), last.Seq)
+	assert.Equal(t, "restart", last.Action)
+	assert.Equal(t, epoch, last.Time)
+	assert.Equal(t, diff.Changes{{
+		Path: "version", Op: diff.Changed, From: "1.0", To: "1.1",
+	}}, last.Diff)
+}
+
+func TestWriter_sinkFailure(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{down: true}
+	w, err := audit.New(sink, audit.WithBufferSize(1),
+		audit.WithFlushInterval(0), audit.WithMaxPending(2))
+	require.NoError(t, err)
+
+	for range 2 {
+		err := w.Record(t.Context(), audit.Event{Action: "install"})
+		require.ErrorIs(t, err, errDown)
+	}
+
+	err = w.Record(t.Context(), audit.Event{Action: "install"})
+	require.ErrorIs(t, err, audit.ErrBacklog)
+
+	// The pending events are written once the sink recovers.
+	sink.setDown(false)
+	require.NoError(t, w.Flush(t.Context()))
+	assert.Equal(t, 2, sink.len())
+
+	_, err = audit.Verify(strings.NewReader(sink.log()))
+	require.NoError(t, err)
+}
+
+func TestWriter_background(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	sink := &memorySink{}
+	w, err := audit.New(sink, audit.WithClock(fake),
+		audit.WithFlushInterval(time.Second))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, w.Close())
+	})
+
+	record(t, w, "install")
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+	fake.Advance(time.Second)
+
+	testx.RequireEventually(t, func() bool {
+		return sink.len() == 1
+	}, time.Second)
+}
+
+func TestVerify_tampered(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{}
+	w, err := audit.New(sink, audit.WithFlushInterval(0))
+	require.NoError(t, err)
+
+	record(t, w, "install", "configure", "restart")
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(sink.log(), "\n")
+
+	tests := []struct {
+		name  string
+		lines []string
+		line  string
+	}{
+		{"edited", []string{
+			lines[0], strings.Replace(lines[1], "tester", "intruder", 1),
+			lines[2],
+		}, "line 2"},
+		{"removed", []string{lines[0], lines[2]}, "line 2"},
+		{"reordered", []string{lines[0], lines[2], lines[1]}, "line 2"},
+		{"hash moved", []string{
+			lines[0], lines[1],
+			strings.Replace(lines[2], `{"seq":3,`, `{"seq":3,"hash":"x",`, 1),
+		}, "line 3"},
+		{"not json", []string{lines[0], "{"}, "line 2"},
+	}
+
+	for _, tt := range tests {
+		_, err := audit.Verify(strings.NewReader(strings.Join(tt.lines, "\n")))
+		require.ErrorIs(t, err, audit.ErrTampered, tt.name)
+		assert.ErrorContains(t, err, tt.line, tt.name)
+	}
+
+	// A suffix of the chain is valid on its own.
+	_, err = audit.Verify(strings.NewReader(lines[1] + "\n" + lines[2]))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,248 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the size from which FileSink rotates its file.
+const DefaultMaxSize = 64 << 20
+
+// rotatedLayout names the rotated files after the time of rotation, which
+// sorts them by age.
+const rotatedLayout = "20060102T150405.000000000Z"
+
+// FileOption configures NewFileSink.
+type FileOption func(*FileSink)
+
+// WithMaxSize sets the size from which the file is rotated,
+// DefaultMaxSize by default. Zero disables rotation.
+func WithMaxSize(size int64) FileOption {
+	return func(s *FileSink) {
+		s.maxSize = size
+	}
+}
+
+// WithMaxBackups bounds the rotated files kept, deleting the oldest ones.
+// Zero, the default, keeps them all.
+func WithMaxBackups(n int) FileOption {
+	return func(s *FileSink) {
+		s.maxBackups = n
+	}
+}
+
+// FileSink appends events to a JSON Lines file, synced on every write.
+// Full files are renamed with the time of rotation inserted before their
+// extension, as in audit-20261014T120000.000000000Z.jsonl, and the chain
+// continues in a new file.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens the file at path for appending, creating it and its
+// directory as needed, readable by the owner only.
+func NewFileSink(path string, opts ...FileOption) (*FileSink, error) {
+	s := &FileSink{path: path, maxSize: DefaultMaxSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	s.file, s.size = f, info.Size()
+
+	return nil
+}
+
+// Write implements Sink, rotating the file first when the lines would
+// make it exceed the maximum size. A failed write is truncated away.
+func (s *FileSink) Write(_ context.Context, lines [][]byte) error {
+	data := append(bytes.Join(lines, []byte("\n")), '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrClosed
+	}
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.file.Write(data)
+	if err == nil {
+		err = s.file.Sync()
+	}
+
+	if err != nil {
+		return errors.Join(fmt.Errorf("audit: %w", err),
+			s.file.Truncate(s.size))
+	}
+
+	s.size += int64(len(data))
+
+	return nil
+}
+
+// rotate renames the full file and opens a new one.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	s.file = nil
+
+	ext := filepath.Ext(s.path)
+	rotated := strings.TrimSuffix(s.path, ext) + "-" +
+		time.Now().UTC().Format(rotatedLayout) + ext
+
+	if err := os.Rename(s.path, rotated); err != nil {
+		return errors.Join(fmt.Errorf("audit: %w", err), s.open())
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	return s.prune()
+}
+
+// prune deletes the oldest rotated files beyond the maximum.
+func (s *FileSink) prune() error {
+	if s.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := rotatedFiles(s.path)
+	if err != nil || len(backups) <= s.maxBackups {
+		return err
+	}
+
+	var failures []error
+
+	for _, path := range backups[:len(backups)-s.maxBackups] {
+		if err := os.Remove(path); err != nil {
+			failures = append(failures, fmt.Errorf("audit: %w", err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+// Last implements Resumer, reading the newest rotated file when the
+// current one is empty.
+func (s *FileSink) Last() ([]byte, error) {
+	paths, err := Files(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range slices.Backward(paths) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("audit: %w", err)
+		}
+
+		data = bytes.TrimRight(data, "\n")
+		if len(data) > 0 {
+			return data[bytes.LastIndexByte(data, '\n')+1:], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Close implements Sink.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrClosed
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
+
+// Files lists the rotated files of the file at path from the oldest, then
+// the file itself when it exists, for VerifyFiles.
+func Files(path string) ([]string, error) {
+	paths, err := rotatedFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// rotatedFiles lists the rotated files of the file at path, sorted from
+// the oldest.
+func rotatedFiles(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	pattern := strings.TrimSuffix(path, ext) + "-*" + ext
+
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+
+	prefix := len(pattern) - len("*"+ext)
+	paths = slices.DeleteFunc(paths, func(p string) bool {
+		_, err := time.Parse(rotatedLayout, strings.TrimSuffix(p[prefix:], ext))
+
+		return err != nil
+	})
+	slices.Sort(paths)
+
+	return paths, nil
+}
@@ -0,0 +1,84 @@
+package audit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/audit"
+)
+
+func openFileWriter(
+	t *testing.T, path string, opts ...audit.FileOption,
+) *audit.Writer {
+	t.Helper()
+
+	sink, err := audit.NewFileSink(path, opts...)
+	require.NoError(t, err)
+
+	w, err := audit.New(sink, audit.WithFlushInterval(0),
+		audit.WithBufferSize(1))
+	require.NoError(t, err)
+
+	return w
+}
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+
+	w := openFileWriter(t, path)
+	record(t, w, "install", "configure")
+	require.NoError(t, w.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A new writer extends the chain of the file.
+	w = openFileWriter(t, path)
+	record(t, w, "restart")
+	require.NoError(t, w.Close())
+
+	last, err := audit.VerifyFiles(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), last.Seq)
+}
+
+func TestFileSink_rotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// Every event is bigger than the maximum, so each one has a file.
+	w := openFileWriter(t, path, audit.WithMaxSize(1))
+	record(t, w, "install", "configure", "restart")
+	require.NoError(t, w.Close())
+
+	paths, err := audit.Files(path)
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+	assert.Equal(t, path, paths[2])
+
+	last, err := audit.VerifyFiles(paths...)
+	require.NoError(t, err)
+	assert.Equal(t, "restart", last.Action)
+
+	// The chain continues after a rotation and a restart, and old files are
+	// pruned.
+	w = openFileWriter(t, path, audit.WithMaxSize(1), audit.WithMaxBackups(2))
+	record(t, w, "stop")
+	require.NoError(t, w.Close())
+
+	paths, err = audit.Files(path)
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+
+	last, err = audit.VerifyFiles(paths...)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), last.Seq)
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ContentType is the media type of the bodies sent by HTTPSink.
+const ContentType = "application/x-ndjson"
+
+// HTTPOption configures NewHTTPSink.
+type HTTPOption func(*HTTPSink)
+
+// WithClient sets the client sending the events, http.DefaultClient by
+// default. A client from httpx.NewClient adds retries.
+func WithClient(client *http.Client) HTTPOption {
+	return func(s *HTTPSink) {
+		s.client = client
+	}
+}
+
+// WithHeader sets a header of the requests, such as Authorization.
+func WithHeader(key, value string) HTTPOption {
+	return func(s *HTTPSink) {
+		s.header.Set(key, value)
+	}
+}
+
+// HTTPSink posts every batch of events as a JSON Lines body to a
+// collector, which must answer with a 2xx status once it stored them all.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+	header http.Header
+}
+
+// NewHTTPSink returns a sink posting to url.
+func NewHTTPSink(url string, opts ...HTTPOption) *HTTPSink {
+	s := &HTTPSink{url: url, client: http.DefaultClient, header: http.Header{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Write implements Sink.
+func (s *HTTPSink) Write(ctx context.Context, lines [][]byte) error {
+	body := append(bytes.Join(lines, []byte("\n")), '\n')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url,
+		bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	req.Header = s.header.Clone()
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit: post %s: %s", s.url, resp.Status)
+	}
+
+	return nil
+}
+
+// Close implements Sink.
+func (*HTTPSink) Close() error {
+	return nil
+}
@@ -0,0 +1,67 @@
+package audit_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/audit"
+)
+
+func TestHTTPSink(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		received bytes.Buffer
+		status   = http.StatusNoContent
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, audit.ContentType, r.Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if status == http.StatusNoContent {
+				_, _ = io.Copy(&received, r.Body)
+			}
+
+			w.WriteHeader(status)
+		}))
+	t.Cleanup(server.Close)
+
+	sink := audit.NewHTTPSink(server.URL,
+		audit.WithClient(server.Client()),
+		audit.WithHeader("Authorization", "Bearer token"))
+	w, err := audit.New(sink, audit.WithFlushInterval(0))
+	require.NoError(t, err)
+
+	record(t, w, "install", "configure")
+	require.NoError(t, w.Flush(t.Context()))
+
+	mu.Lock()
+	status = http.StatusServiceUnavailable
+	mu.Unlock()
+
+	record(t, w, "restart")
+	require.ErrorContains(t, w.Flush(t.Context()), "503")
+
+	mu.Lock()
+	status = http.StatusNoContent
+	mu.Unlock()
+
+	require.NoError(t, w.Close())
+
+	last, err := audit.Verify(&received)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), last.Seq)
+}
@@ -0,0 +1,242 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// Writer defaults.
+const (
+	DefaultBufferSize    = 64
+	DefaultFlushInterval = time.Second
+	DefaultMaxPending    = 4096
+)
+
+// Option configures New.
+type Option func(*Writer)
+
+// WithBufferSize sets how many events are pending before Record flushes
+// them, DefaultBufferSize by default. One flushes on every event.
+func WithBufferSize(size int) Option {
+	return func(w *Writer) {
+		w.bufferSize = max(size, 1)
+	}
+}
+
+// WithFlushInterval sets how often the pending events are flushed in the
+// background, DefaultFlushInterval by default. Zero disables background
+// flushes.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(w *Writer) {
+		w.interval = interval
+	}
+}
+
+// WithMaxPending bounds the events kept while the sink fails,
+// DefaultMaxPending by default, beyond which Record fails with
+// ErrBacklog.
+func WithMaxPending(limit int) Option {
+	return func(w *Writer) {
+		w.maxPending = limit
+	}
+}
+
+// WithClock sets the clock timing the events and the background flushes.
+func WithClock(c clock.Clock) Option {
+	return func(w *Writer) {
+		w.clock = c
+	}
+}
+
+// WithLogger sets the logger reporting the failed background flushes.
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Writer) {
+		w.logger = logger
+	}
+}
+
+// Writer seals events into a chain and writes them to a Sink. It is safe
+// for concurrent use.
+type Writer struct {
+	sink       Sink
+	bufferSize int
+	interval   time.Duration
+	maxPending int
+	clock      clock.Clock
+	logger     *slog.Logger
+
+	// flushMu serializes the writes to the sink, keeping the order.
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	pending [][]byte
+	seq     uint64
+	head    string
+	closed  bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a writer extending the chain of sink when it is a Resumer.
+func New(sink Sink, opts ...Option) (*Writer, error) {
+	w := &Writer{
+		sink:       sink,
+		bufferSize: DefaultBufferSize,
+		interval:   DefaultFlushInterval,
+		maxPending: DefaultMaxPending,
+		clock:      clock.Real(),
+		logger:     slog.New(slog.DiscardHandler),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := w.resume(); err != nil {
+		return nil, err
+	}
+
+	if w.interval > 0 {
+		go w.loop()
+	} else {
+		close(w.done)
+	}
+
+	return w, nil
+}
+
+// resume continues the chain of the sink.
+func (w *Writer) resume() error {
+	resumer, ok := w.sink.(Resumer)
+	if !ok {
+		return nil
+	}
+
+	line, err := resumer.Last()
+	if err != nil || line == nil {
+		return err
+	}
+
+	last, err := unseal(line)
+	if err != nil {
+		return fmt.Errorf("%w (last event)", err)
+	}
+
+	w.seq, w.head = last.Seq, last.Hash
+
+	return nil
+}
+
+// Record seals e into the chain and queues it, flushing the pending events
+// once the buffer is full.
+func (w *Writer) Record(ctx context.Context, e Event) error {
+	full, err := w.append(e)
+	if err != nil || !full {
+		return err
+	}
+
+	return w.Flush(ctx)
+}
+
+// append seals e and reports whether the buffer is full.
+func (w *Writer) append(e Event) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case w.closed:
+		return false, ErrClosed
+	case len(w.pending) >= w.maxPending:
+		return false, ErrBacklog
+	}
+
+	e.Seq, e.Prev = w.seq+1, w.head
+	e.Time = w.clock.Now().UTC()
+
+	line, err := seal(&e)
+	if err != nil {
+		return false, err
+	}
+
+	w.seq, w.head = e.Seq, e.Hash
+	w.pending = append(w.pending, line)
+
+	return len(w.pending) >= w.bufferSize, nil
+}
+
+// Flush writes the pending events. Events failing to be written stay
+// pending, to be written by the next flush.
+func (w *Writer) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := w.sink.Write(ctx, batch); err != nil {
+		w.mu.Lock()
+		w.pending = slices.Concat(batch, w.pending)
+		w.mu.Unlock()
+
+		return fmt.Errorf("audit: flush: %w", err)
+	}
+
+	return nil
+}
+
+// loop flushes in the background until Close.
+func (w *Writer) loop() {
+	defer close(w.done)
+
+	ticker := w.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			err := w.Flush(context.Background())
+			if err != nil {
+				w.logger.Warn("audit flush failed", "error", err)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Close flushes the pending events and closes the sink. Later writes fail
+// with ErrClosed.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	closed := w.closed
+	w.closed = true
+	w.mu.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	if w.interval > 0 {
+		close(w.stop)
+	}
+
+	<-w.done
+
+	return errors.Join(w.Flush(context.Background()), w.sink.Close())
+}
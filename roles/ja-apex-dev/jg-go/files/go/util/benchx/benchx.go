@@ -0,0 +1,50 @@
+// Package benchx guards the performance of hot paths in tests: allocation
+// assertions fail the regular test run when a helper starts allocating,
+// and reports compare the benchmarks of alternative implementations:
+//
+//	func TestJoinStringsAllocs(t *testing.T) {
+//		benchx.AssertAllocsPerOp(t, 1, func() {
+//			sink = util.JoinStrings("a", "b", "c")
+//		})
+//	}
+//
+// Allocations are counted process-wide, so the tests asserting them must
+// not run in parallel with others.
+package benchx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// DefaultRuns is the number of calls averaged by AllocsPerOp.
+const DefaultRuns = 100
+
+// AllocsPerOp returns the average number of heap allocations of a call to
+// fn, after a warm-up call.
+func AllocsPerOp(fn func()) float64 {
+	return testing.AllocsPerRun(DefaultRuns, fn)
+}
+
+// AssertAllocsPerOp fails the test when a call to fn allocates more than
+// maxAllocs times on average, and reports whether it passed. It always
+// passes under the race detector.
+func AssertAllocsPerOp(
+	t testing.TB, maxAllocs float64, fn func(), msgAndArgs ...any,
+) bool {
+	t.Helper()
+
+	if RaceEnabled {
+		return true
+	}
+
+	allocs := AllocsPerOp(fn)
+	if allocs <= maxAllocs {
+		return true
+	}
+
+	return assert.Fail(t, fmt.Sprintf("%v allocs/op, want at most %v",
+		allocs, maxAllocs), msgAndArgs...)
+}
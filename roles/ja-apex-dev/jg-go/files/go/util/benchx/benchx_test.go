@@ -0,0 +1,48 @@
+package benchx_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/benchx"
+)
+
+var sink string
+
+// recorder is a testing.TB recording the failures instead of reporting
+// them.
+type recorder struct {
+	testing.TB
+
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+//nolint:paralleltest // Allocations are counted process-wide.
+func TestAssertAllocsPerOp(t *testing.T) {
+	assert.True(t, benchx.AssertAllocsPerOp(t, 0, func() {
+		sink = strings.TrimSpace(" static ")
+	}))
+
+	if benchx.RaceEnabled {
+		return
+	}
+
+	rec := &recorder{TB: t}
+	ok := benchx.AssertAllocsPerOp(rec, 0, func() {
+		sink = strings.Repeat("x", 64)
+	}, "repeating %d bytes", 64)
+
+	assert.False(t, ok)
+	assert.Len(t, rec.failures, 1)
+	assert.Contains(t, rec.failures[0], "1 allocs/op, want at most 0")
+	assert.Contains(t, rec.failures[0], "repeating 64 bytes")
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package benchx
+
+// RaceEnabled reports whether the race detector is on, which allocates
+// on its own and so disables the allocation assertions.
+const RaceEnabled = true
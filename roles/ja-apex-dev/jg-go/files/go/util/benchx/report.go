@@ -0,0 +1,99 @@
+package benchx
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"example.com/go-template/util/table"
+)
+
+// Result is the outcome of a benchmark.
+type Result struct {
+	Name        string
+	N           int
+	NsPerOp     float64
+	BytesPerOp  int64
+	AllocsPerOp int64
+}
+
+// Measure runs fn as a benchmark, for the -test.benchtime duration.
+func Measure(name string, fn func(b *testing.B)) Result {
+	r := testing.Benchmark(fn)
+
+	return Result{
+		Name:        name,
+		N:           r.N,
+		NsPerOp:     float64(r.T.Nanoseconds()) / float64(max(r.N, 1)),
+		BytesPerOp:  r.AllocedBytesPerOp(),
+		AllocsPerOp: r.AllocsPerOp(),
+	}
+}
+
+// Report compares the benchmarks of alternative implementations, the
+// first being the baseline.
+type Report struct {
+	results []Result
+}
+
+// Run measures fn under name and adds its result.
+func (r *Report) Run(name string, fn func(b *testing.B)) Result {
+	result := Measure(name, fn)
+	r.Add(result)
+
+	return result
+}
+
+// Add adds a result measured elsewhere, such as parsed from go test
+// -bench output.
+func (r *Report) Add(result Result) {
+	r.results = append(r.results, result)
+}
+
+// Results returns the results in the order they were added.
+func (r *Report) Results() []Result {
+	return r.results
+}
+
+// Write renders a row per result with its time relative to the baseline,
+// such as 0.50x for twice as fast, and its allocations.
+func (r *Report) Write(w io.Writer, opts ...table.Option) error {
+	t := table.New([]string{
+		"benchmark", "ns/op", "vs " + r.baseline().Name, "B/op", "allocs/op",
+	}, opts...)
+
+	for _, result := range r.results {
+		t.Append(
+			result.Name,
+			strconv.FormatFloat(result.NsPerOp, 'f', 1, 64),
+			r.relative(result),
+			strconv.FormatInt(result.BytesPerOp, 10),
+			strconv.FormatInt(result.AllocsPerOp, 10),
+		)
+	}
+
+	if err := t.Render(w); err != nil {
+		return fmt.Errorf("benchx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Report) baseline() Result {
+	if len(r.results) == 0 {
+		return Result{}
+	}
+
+	return r.results[0]
+}
+
+// relative returns the time of result over the baseline.
+func (r *Report) relative(result Result) string {
+	base := r.baseline().NsPerOp
+	if base == 0 {
+		return "-"
+	}
+
+	return strconv.FormatFloat(result.NsPerOp/base, 'f', 2, 64) + "x"
+}
@@ -0,0 +1,46 @@
+package benchx_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/benchx"
+	"example.com/go-template/util/table"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	var report benchx.Report
+
+	report.Add(benchx.Result{
+		Name: "strings.Join", NsPerOp: 40, BytesPerOp: 16, AllocsPerOp: 1,
+	})
+	report.Add(benchx.Result{Name: "JoinStrings", NsPerOp: 20})
+
+	var out strings.Builder
+	require.NoError(t, report.Write(&out, table.WithFormat(table.CSV)))
+
+	assert.Equal(t, "benchmark,ns/op,vs strings.Join,B/op,allocs/op\n"+
+		"strings.Join,40.0,1.00x,16,1\n"+
+		"JoinStrings,20.0,0.50x,0,0\n", out.String())
+}
+
+//nolint:paralleltest // Benchmarks measure allocations process-wide.
+func TestReportRun(t *testing.T) {
+	var report benchx.Report
+
+	result := report.Run("measured", func(b *testing.B) {
+		for b.Loop() {
+			sink = strings.Repeat("x", 64)
+		}
+	})
+
+	assert.Equal(t, "measured", result.Name)
+	assert.Positive(t, result.N)
+	assert.Positive(t, result.NsPerOp)
+	assert.Equal(t, []benchx.Result{result}, report.Results())
+}
@@ -0,0 +1,321 @@
+// Package breaker implements a circuit breaker that stops calling a
+// failing dependency for a while, failing fast instead of piling up
+// requests, and probes it before resuming normal traffic.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// ErrOpen is returned instead of calling the dependency while the circuit
+// is open or its probes are busy.
+var ErrOpen = errors.New("breaker: circuit open")
+
+// ErrPanic is the outcome recorded by Do for the calls that panic.
+var ErrPanic = errors.New("breaker: call panicked")
+
+// Default breaker settings.
+const (
+	DefaultThreshold = 5
+	DefaultCooldown  = 30 * time.Second
+	DefaultProbes    = 1
+)
+
+// State is the position of the circuit.
+type State int
+
+// Circuit states.
+const (
+	// Closed lets every call through, counting consecutive failures.
+	Closed State = iota
+	// Open rejects every call until the cooldown has elapsed.
+	Open
+	// HalfOpen lets a few probe calls through to decide whether to close
+	// the circuit again.
+	HalfOpen
+)
+
+// String returns the lowercase name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	threshold int
+	cooldown  time.Duration
+	probes    int
+	onChange  func(from, to State)
+	isFailure func(error) bool
+	clock     clock.Clock
+}
+
+// WithThreshold sets the number of consecutive failures opening the
+// circuit, DefaultThreshold by default.
+func WithThreshold(n int) Option {
+	return func(s *settings) {
+		s.threshold = max(n, 1)
+	}
+}
+
+// WithCooldown sets how long the circuit stays open before probing,
+// DefaultCooldown by default.
+func WithCooldown(d time.Duration) Option {
+	return func(s *settings) {
+		s.cooldown = d
+	}
+}
+
+// WithProbes sets the number of calls let through while half-open, all
+// of which must succeed to close the circuit, DefaultProbes by default.
+func WithProbes(n int) Option {
+	return func(s *settings) {
+		s.probes = max(n, 1)
+	}
+}
+
+// WithOnStateChange registers fn to observe transitions, for logging and
+// metrics. It is called without holding the breaker lock.
+func WithOnStateChange(fn func(from, to State)) Option {
+	return func(s *settings) {
+		s.onChange = fn
+	}
+}
+
+// WithFailure sets the predicate deciding which errors count as failures
+// of the dependency; the other errors are neutral and count as neither
+// failures nor successes. By default every error is a failure except
+// context.Canceled, which means the caller gave up.
+func WithFailure(isFailure func(error) bool) Option {
+	return func(s *settings) {
+		s.isFailure = isFailure
+	}
+}
+
+// WithClock sets the clock timing the cooldown.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.clock = c
+	}
+}
+
+// Breaker tracks the health of one dependency. It is safe for concurrent
+// use, and a nil *Breaker lets every call through.
+type Breaker struct {
+	settings
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	// generation changes with the state, so that calls started before a
+	// transition do not affect the new state.
+	generation uint64
+	openedAt   time.Time
+	inFlight   int
+	successes  int
+}
+
+// New returns a closed breaker.
+func New(opts ...Option) *Breaker {
+	b := &Breaker{settings: settings{
+		threshold: DefaultThreshold,
+		cooldown:  DefaultCooldown,
+		probes:    DefaultProbes,
+		isFailure: defaultFailure,
+	}}
+
+	for _, opt := range opts {
+		opt(&b.settings)
+	}
+
+	b.clock = clock.OrReal(b.clock)
+
+	return b
+}
+
+// State returns the current state, moving from Open to HalfOpen once the
+// cooldown has elapsed. A nil *Breaker is always Closed.
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+
+	b.mu.Lock()
+	change := b.refresh()
+	state := b.state
+	b.mu.Unlock()
+
+	b.notify(change)
+
+	return state
+}
+
+// Allow asks permission to call the dependency, returning ErrOpen when
+// the call must be skipped. Otherwise the caller must report the outcome
+// of the call to done exactly once.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+
+	b.mu.Lock()
+	change := b.refresh()
+	allowed := b.admit()
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.notify(change)
+
+	if !allowed {
+		return nil, ErrOpen
+	}
+
+	return func(err error) {
+		b.record(generation, err)
+	}, nil
+}
+
+// Do calls fn unless the circuit is open, recording its outcome. A panic
+// of fn is recorded as ErrPanic before it propagates, so that it releases
+// its probe slot.
+func (b *Breaker) Do(
+	ctx context.Context, fn func(context.Context) error,
+) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	returned := false
+
+	defer func() {
+		if !returned {
+			done(ErrPanic)
+		}
+	}()
+
+	err = fn(ctx)
+	returned = true
+
+	done(err)
+
+	return err
+}
+
+// transition is a state change to report once the lock is released.
+type transition struct {
+	from, to State
+}
+
+// refresh opens the half-open window once the cooldown has elapsed.
+func (b *Breaker) refresh() *transition {
+	if b.state != Open || b.clock.Since(b.openedAt) < b.cooldown {
+		return nil
+	}
+
+	return b.enter(HalfOpen)
+}
+
+// admit reserves a call, limiting probes while half-open.
+func (b *Breaker) admit() bool {
+	switch b.state {
+	case Closed:
+		return true
+	case HalfOpen:
+		if b.inFlight >= b.probes {
+			return false
+		}
+
+		b.inFlight++
+
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *Breaker) record(generation uint64, err error) {
+	b.mu.Lock()
+
+	var change *transition
+
+	switch {
+	case generation != b.generation:
+	case err != nil && !b.isFailure(err):
+		// Neutral outcomes only release their probe slot.
+		if b.state == HalfOpen {
+			b.inFlight--
+		}
+	default:
+		change = b.update(err != nil)
+	}
+
+	b.mu.Unlock()
+
+	b.notify(change)
+}
+
+// update applies the outcome of a call admitted in the current state.
+func (b *Breaker) update(failed bool) *transition {
+	switch {
+	case b.state == Closed && failed:
+		b.failures++
+		if b.failures >= b.threshold {
+			return b.enter(Open)
+		}
+	case b.state == Closed:
+		b.failures = 0
+	case failed:
+		return b.enter(Open)
+	default:
+		b.inFlight--
+		b.successes++
+
+		if b.successes >= b.probes {
+			return b.enter(Closed)
+		}
+	}
+
+	return nil
+}
+
+// enter switches to state, resetting the counters of the previous one.
+func (b *Breaker) enter(state State) *transition {
+	change := &transition{from: b.state, to: state}
+
+	b.state = state
+	b.generation++
+	b.failures, b.inFlight, b.successes = 0, 0, 0
+
+	if state == Open {
+		b.openedAt = b.clock.Now()
+	}
+
+	return change
+}
+
+func (b *Breaker) notify(change *transition) {
+	if change != nil && b.onChange != nil {
+		b.onChange(change.from, change.to)
+	}
+}
+
+func defaultFailure(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,149 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/breaker"
+	"example.com/go-template/util/clock"
+)
+
+var errDown = errors.New("down")
+
+func fail(context.Context) error {
+	return errDown
+}
+
+func succeed(context.Context) error {
+	return nil
+}
+
+func TestBreakerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	var transitions []string
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	circuit := breaker.New(
+		breaker.WithThreshold(2),
+		breaker.WithCooldown(time.Minute),
+		breaker.WithProbes(2),
+		breaker.WithClock(fake),
+		breaker.WithOnStateChange(func(from, to breaker.State) {
+			transitions = append(transitions, from.String()+">"+to.String())
+		}))
+
+	require.ErrorIs(t, circuit.Do(t.Context(), fail), errDown)
+	require.NoError(t, circuit.Do(t.Context(), succeed), "successes reset")
+	require.ErrorIs(t, circuit.Do(t.Context(), fail), errDown)
+	require.ErrorIs(t, circuit.Do(t.Context(), fail), errDown)
+	require.ErrorIs(t, circuit.Do(t.Context(), succeed), breaker.ErrOpen)
+
+	fake.Advance(time.Minute)
+	assert.Equal(t, breaker.HalfOpen, circuit.State())
+
+	first, err := circuit.Allow()
+	require.NoError(t, err)
+
+	second, err := circuit.Allow()
+	require.NoError(t, err)
+
+	_, err = circuit.Allow()
+	require.ErrorIs(t, err, breaker.ErrOpen, "probes are limited")
+
+	first(nil)
+	second(nil)
+
+	assert.Equal(t, breaker.Closed, circuit.State())
+	assert.Equal(t, []string{
+		"closed>open", "open>half-open", "half-open>closed",
+	}, transitions)
+}
+
+func TestBreakerProbeFailureReopens(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	circuit := breaker.New(breaker.WithThreshold(1), breaker.WithClock(fake))
+
+	require.Error(t, circuit.Do(t.Context(), fail))
+	fake.Advance(breaker.DefaultCooldown)
+
+	require.ErrorIs(t, circuit.Do(t.Context(), fail), errDown)
+	assert.Equal(t, breaker.Open, circuit.State())
+}
+
+func TestBreakerPanicReleasesProbe(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	circuit := breaker.New(breaker.WithThreshold(1), breaker.WithClock(fake))
+
+	require.Error(t, circuit.Do(t.Context(), fail))
+	fake.Advance(breaker.DefaultCooldown)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = circuit.Do(t.Context(), func(context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, breaker.Open, circuit.State(), "panics are failures")
+
+	fake.Advance(breaker.DefaultCooldown)
+	require.NoError(t, circuit.Do(t.Context(), succeed))
+	assert.Equal(t, breaker.Closed, circuit.State())
+}
+
+func TestBreakerNeutralErrors(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	circuit := breaker.New(breaker.WithThreshold(1), breaker.WithClock(fake))
+
+	canceled := func(context.Context) error { return context.Canceled }
+
+	require.ErrorIs(t, circuit.Do(t.Context(), canceled), context.Canceled)
+	assert.Equal(t, breaker.Closed, circuit.State())
+
+	require.Error(t, circuit.Do(t.Context(), fail))
+	fake.Advance(breaker.DefaultCooldown)
+
+	require.ErrorIs(t, circuit.Do(t.Context(), canceled), context.Canceled)
+	assert.Equal(t, breaker.HalfOpen, circuit.State(), "the probe is freed")
+	require.NoError(t, circuit.Do(t.Context(), succeed))
+	assert.Equal(t, breaker.Closed, circuit.State())
+}
+
+func TestBreakerStaleOutcome(t *testing.T) {
+	t.Parallel()
+
+	circuit := breaker.New(breaker.WithThreshold(1))
+
+	stale, err := circuit.Allow()
+	require.NoError(t, err)
+	require.Error(t, circuit.Do(t.Context(), fail))
+
+	stale(nil)
+	assert.Equal(t, breaker.Open, circuit.State(),
+		"calls started before opening are ignored")
+}
+
+func TestNilBreaker(t *testing.T) {
+	t.Parallel()
+
+	var circuit *breaker.Breaker
+
+	done, err := circuit.Allow()
+	require.NoError(t, err)
+	done(errDown)
+
+	assert.Equal(t, breaker.Closed, circuit.State())
+	require.ErrorIs(t, circuit.Do(t.Context(), func(context.Context) error {
+		return errDown
+	}), errDown)
+}
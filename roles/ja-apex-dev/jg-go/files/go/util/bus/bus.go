@@ -0,0 +1,167 @@
+// Package bus publishes messages to subjects and delivers them to the
+// handlers subscribed to them, in memory for tests and single processes or
+// over NATS in production:
+//
+//	b, err := bus.ConnectNATS(url)
+//	sub, err := b.Subscribe(ctx, "orders.*", handle,
+//		bus.WithQueue("billing"),
+//		bus.WithDeadLetter(bus.DeadLetterTo(b, "orders.dead")))
+//	err = b.Publish(ctx, &bus.Message{Subject: "orders.created", Data: data})
+//
+// Subjects are dot-separated tokens, matched by patterns where * stands
+// for a token and a final > for the remaining ones. A failing handler is
+// retried with backoff within the receiving process, and the message is
+// handed to the dead-letter hook once the attempts are exhausted. Delivery
+// is at most once: neither bus stores messages, so those published
+// without subscribers, or received by a process that stops, are lost.
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"example.com/go-template/util"
+)
+
+// ErrClosed is returned when publishing or subscribing on a closed bus.
+var ErrClosed = errors.New("bus: closed")
+
+// Headers set on the messages handed to DeadLetterTo.
+const (
+	HeaderError   = "Bus-Error"
+	HeaderSubject = "Bus-Subject"
+)
+
+// Message is a payload published to a subject.
+type Message struct {
+	Subject string
+	Data    []byte
+	Header  map[string]string
+	// Attempt counts the deliveries of the message to the handler, from 1.
+	Attempt int
+}
+
+// clone returns a copy of m sharing its data.
+func (m *Message) clone() *Message {
+	return &Message{Subject: m.Subject, Data: m.Data,
+		Header: maps.Clone(m.Header)}
+}
+
+// Handler handles a message. Its failures are retried, except those
+// marked by util.Permanent.
+type Handler func(ctx context.Context, msg *Message) error
+
+// DeadLetterFunc receives the messages whose handling failed for good with
+// the last error.
+type DeadLetterFunc func(ctx context.Context, msg *Message, err error)
+
+// Bus is a publish-subscribe transport, safe for concurrent use.
+type Bus interface {
+	// Publish sends msg to the subscribers of its subject.
+	Publish(ctx context.Context, msg *Message) error
+	// Subscribe calls h with ctx for every message published to the
+	// subjects matching pattern, one message at a time.
+	Subscribe(
+		ctx context.Context, pattern string, h Handler, opts ...SubscribeOption,
+	) (Subscription, error)
+	// Close unsubscribes every subscription and releases the transport.
+	Close(ctx context.Context) error
+}
+
+// Subscription is the registration of a handler.
+type Subscription interface {
+	// Unsubscribe stops receiving messages and waits until the received
+	// ones are handled or ctx is done.
+	Unsubscribe(ctx context.Context) error
+}
+
+// SubscribeOption configures Bus.Subscribe.
+type SubscribeOption func(*subscribeSettings)
+
+type subscribeSettings struct {
+	queue      string
+	retry      util.RetryPolicy
+	deadLetter DeadLetterFunc
+}
+
+func newSubscribeSettings(opts []SubscribeOption) subscribeSettings {
+	s := subscribeSettings{
+		retry:      util.DefaultRetryPolicy(),
+		deadLetter: func(context.Context, *Message, error) {},
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+// WithQueue joins the subscription to the queue group, whose members
+// receive every message once between them instead of once each.
+func WithQueue(group string) SubscribeOption {
+	return func(s *subscribeSettings) {
+		s.queue = group
+	}
+}
+
+// WithRetryPolicy retries the failed handlers with policy instead of
+// util.DefaultRetryPolicy.
+func WithRetryPolicy(policy util.RetryPolicy) SubscribeOption {
+	return func(s *subscribeSettings) {
+		s.retry = policy
+	}
+}
+
+// WithDeadLetter hands the messages still failing after the last attempt
+// to fn. They are dropped by default.
+func WithDeadLetter(fn DeadLetterFunc) SubscribeOption {
+	return func(s *subscribeSettings) {
+		s.deadLetter = fn
+	}
+}
+
+// DeadLetterTo republishes the dead messages to subject on b, with their
+// original subject in HeaderSubject and the error in HeaderError.
+func DeadLetterTo(b Bus, subject string) DeadLetterFunc {
+	return func(ctx context.Context, msg *Message, err error) {
+		dead := msg.clone()
+		dead.Subject = subject
+
+		if dead.Header == nil {
+			dead.Header = map[string]string{}
+		}
+
+		dead.Header[HeaderSubject] = msg.Subject
+		dead.Header[HeaderError] = err.Error()
+
+		_ = b.Publish(context.WithoutCancel(ctx), dead)
+	}
+}
+
+// deliver calls h with msg until it succeeds or the attempts are
+// exhausted, then hands msg to the dead-letter hook. A panic fails the
+// attempt.
+func (s subscribeSettings) deliver(
+	ctx context.Context, h Handler, msg *Message,
+) {
+	err := util.Retry(ctx, s.retry, func(ctx context.Context) error {
+		msg.Attempt++
+
+		return call(ctx, h, msg)
+	})
+	if err != nil {
+		s.deadLetter(ctx, msg, err)
+	}
+}
+
+func call(ctx context.Context, h Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("bus: handler panicked: %v", r)
+		}
+	}()
+
+	return h(ctx, msg)
+}
@@ -0,0 +1,209 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultBuffer is the number of messages a Memory subscription queues
+// before Publish blocks.
+const DefaultBuffer = 64
+
+// Memory is a Bus within the process. Publish queues the message for
+// every matching subscription, blocking while one of their queues is
+// full.
+type Memory struct {
+	mu     sync.Mutex
+	subs   []*memorySub
+	groups map[string]int
+	closed bool
+}
+
+// NewMemory returns an empty in-memory bus.
+func NewMemory() *Memory {
+	return &Memory{groups: map[string]int{}}
+}
+
+type memorySub struct {
+	bus      *Memory
+	pattern  string
+	settings subscribeSettings
+	queue    chan *Message
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// Publish implements Bus.
+func (b *Memory) Publish(ctx context.Context, msg *Message) error {
+	targets, err := b.targets(msg.Subject)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range targets {
+		select {
+		case sub.queue <- msg.clone():
+		case <-sub.done:
+		case <-ctx.Done():
+			return fmt.Errorf("bus: publish %s: %w", msg.Subject, ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+// Subscribe implements Bus.
+func (b *Memory) Subscribe(
+	ctx context.Context, pattern string, h Handler, opts ...SubscribeOption,
+) (Subscription, error) {
+	sub := &memorySub{
+		bus:      b,
+		pattern:  pattern,
+		settings: newSubscribeSettings(opts),
+		queue:    make(chan *Message, DefaultBuffer),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	b.subs = append(b.subs, sub)
+
+	go sub.run(ctx, h)
+
+	return sub, nil
+}
+
+// Close implements Bus.
+func (b *Memory) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	subs := slices.Clone(b.subs)
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		if err := sub.Unsubscribe(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// targets returns the subscriptions receiving a message published to
+// subject: every matching one outside queue groups and one per group, in
+// turn.
+func (b *Memory) targets(subject string) ([]*memorySub, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	var targets []*memorySub
+
+	members := map[string][]*memorySub{}
+
+	for _, sub := range b.subs {
+		if !Match(sub.pattern, subject) {
+			continue
+		}
+
+		if group := sub.settings.queue; group != "" {
+			members[group] = append(members[group], sub)
+		} else {
+			targets = append(targets, sub)
+		}
+	}
+
+	for group, subs := range members {
+		targets = append(targets, subs[b.groups[group]%len(subs)])
+		b.groups[group]++
+	}
+
+	return targets, nil
+}
+
+// remove forgets sub, so that it receives no more messages.
+func (b *Memory) remove(sub *memorySub) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs = slices.DeleteFunc(b.subs, func(s *memorySub) bool {
+		return s == sub
+	})
+}
+
+// run delivers the queued messages until the subscription is stopped and
+// its queue drained.
+func (s *memorySub) run(ctx context.Context, h Handler) {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case msg := <-s.queue:
+			s.settings.deliver(ctx, h, msg)
+		case <-s.done:
+			s.drain(ctx, h)
+
+			return
+		}
+	}
+}
+
+// drain delivers the messages left in the queue.
+func (s *memorySub) drain(ctx context.Context, h Handler) {
+	for {
+		select {
+		case msg := <-s.queue:
+			s.settings.deliver(ctx, h, msg)
+		default:
+			return
+		}
+	}
+}
+
+// Unsubscribe implements Subscription.
+func (s *memorySub) Unsubscribe(ctx context.Context) error {
+	s.once.Do(func() {
+		s.bus.remove(s)
+		close(s.done)
+	})
+
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("bus: unsubscribe %s: %w", s.pattern, ctx.Err())
+	}
+}
+
+// Match reports whether subject matches pattern, where * stands for a
+// token and a final > for one or more tokens.
+func Match(pattern, subject string) bool {
+	patterns := strings.Split(pattern, ".")
+	tokens := strings.Split(subject, ".")
+
+	for i, p := range patterns {
+		switch {
+		case p == ">" && i == len(patterns)-1:
+			return len(tokens) > i
+		case i >= len(tokens):
+			return false
+		case p != "*" && p != tokens[i]:
+			return false
+		}
+	}
+
+	return len(tokens) == len(patterns)
+}
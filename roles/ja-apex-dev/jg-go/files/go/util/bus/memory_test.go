@@ -0,0 +1,216 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/bus"
+	"example.com/go-template/util/testx"
+)
+
+// fastRetry retries the handlers without waiting.
+var fastRetry = bus.WithRetryPolicy(util.RetryPolicy{
+	MaxAttempts: 3, InitialDelay: time.Microsecond,
+})
+
+// collector records the messages it handles.
+type collector struct {
+	mu       sync.Mutex
+	messages []*bus.Message
+}
+
+func (c *collector) handle(_ context.Context, msg *bus.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, msg)
+
+	return nil
+}
+
+func (c *collector) subjects() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subjects := make([]string, 0, len(c.messages))
+	for _, msg := range c.messages {
+		subjects = append(subjects, msg.Subject)
+	}
+
+	return subjects
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.messages)
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.deleted", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.*.eu", "orders.created.eu", true},
+		{"orders.>", "orders.created.eu", true},
+		{"orders.>", "orders", false},
+		{">", "orders", true},
+		{"orders", "orders.created", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, bus.Match(tt.pattern, tt.subject),
+			"%s ~ %s", tt.pattern, tt.subject)
+	}
+}
+
+func TestMemoryFanOut(t *testing.T) {
+	t.Parallel()
+
+	b := bus.NewMemory()
+
+	var all, created collector
+
+	_, err := b.Subscribe(t.Context(), "orders.>", all.handle)
+	require.NoError(t, err)
+	_, err = b.Subscribe(t.Context(), "orders.created", created.handle)
+	require.NoError(t, err)
+
+	for _, subject := range []string{"orders.created", "orders.paid", "x"} {
+		require.NoError(t, b.Publish(t.Context(), &bus.Message{
+			Subject: subject, Header: map[string]string{"Id": "1"},
+		}))
+	}
+
+	require.NoError(t, b.Close(t.Context()))
+	assert.Equal(t, []string{"orders.created", "orders.paid"}, all.subjects())
+	assert.Equal(t, []string{"orders.created"}, created.subjects())
+	assert.Equal(t, "1", created.messages[0].Header["Id"])
+	assert.Equal(t, 1, created.messages[0].Attempt)
+
+	require.ErrorIs(t, b.Publish(t.Context(), &bus.Message{Subject: "x"}),
+		bus.ErrClosed)
+}
+
+func TestMemoryQueue(t *testing.T) {
+	t.Parallel()
+
+	b := bus.NewMemory()
+
+	var first, second collector
+
+	_, err := b.Subscribe(t.Context(), "jobs", first.handle,
+		bus.WithQueue("workers"))
+	require.NoError(t, err)
+	_, err = b.Subscribe(t.Context(), "jobs", second.handle,
+		bus.WithQueue("workers"))
+	require.NoError(t, err)
+
+	for range 10 {
+		require.NoError(t, b.Publish(t.Context(),
+			&bus.Message{Subject: "jobs"}))
+	}
+
+	require.NoError(t, b.Close(t.Context()))
+	assert.Equal(t, 5, first.count())
+	assert.Equal(t, 5, second.count())
+}
+
+func TestMemoryRetry(t *testing.T) {
+	t.Parallel()
+
+	b := bus.NewMemory()
+
+	var attempts atomic.Int32
+
+	_, err := b.Subscribe(t.Context(), "flaky",
+		func(_ context.Context, msg *bus.Message) error {
+			attempts.Add(1)
+
+			if msg.Attempt < 2 {
+				return errors.New("not yet")
+			}
+
+			return nil
+		}, fastRetry)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(t.Context(), &bus.Message{Subject: "flaky"}))
+	require.NoError(t, b.Close(t.Context()))
+	assert.EqualValues(t, 2, attempts.Load())
+}
+
+func TestMemoryDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	b := bus.NewMemory()
+
+	var dead collector
+
+	_, err := b.Subscribe(t.Context(), "dead", dead.handle)
+	require.NoError(t, err)
+	_, err = b.Subscribe(t.Context(), "orders.*",
+		func(context.Context, *bus.Message) error {
+			panic("broken")
+		}, fastRetry, bus.WithDeadLetter(bus.DeadLetterTo(b, "dead")))
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(t.Context(), &bus.Message{
+		Subject: "orders.created", Data: []byte("order"),
+	}))
+	testx.RequireEventually(t, func() bool {
+		return dead.count() == 1
+	}, time.Second)
+	require.NoError(t, b.Close(t.Context()))
+
+	msg := dead.messages[0]
+	assert.Equal(t, "order", string(msg.Data))
+	assert.Equal(t, "orders.created", msg.Header[bus.HeaderSubject])
+	assert.Contains(t, msg.Header[bus.HeaderError], "broken")
+}
+
+func TestMemoryUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	b := bus.NewMemory()
+	release := make(chan struct{})
+
+	var handled collector
+
+	sub, err := b.Subscribe(t.Context(), "slow",
+		func(ctx context.Context, msg *bus.Message) error {
+			<-release
+
+			return handled.handle(ctx, msg)
+		})
+	require.NoError(t, err)
+
+	for range 3 {
+		require.NoError(t, b.Publish(t.Context(),
+			&bus.Message{Subject: "slow"}))
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, sub.Unsubscribe(ctx), context.DeadlineExceeded)
+	require.NoError(t, b.Publish(t.Context(), &bus.Message{Subject: "slow"}))
+
+	close(release)
+	require.NoError(t, sub.Unsubscribe(t.Context()))
+	assert.Equal(t, 3, handled.count())
+}
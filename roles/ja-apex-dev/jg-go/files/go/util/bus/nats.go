@@ -0,0 +1,167 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATS is a Bus over a NATS connection. Core NATS keeps no messages, so
+// the retries only cover the failures of the handlers: messages published
+// without subscribers, or received by a crashing process, are lost.
+type NATS struct {
+	conn  *nats.Conn
+	owned bool
+
+	mu   sync.Mutex
+	subs map[*natsSub]struct{}
+}
+
+// NewNATS returns a bus over conn, which the caller keeps closing.
+func NewNATS(conn *nats.Conn) *NATS {
+	return &NATS{conn: conn, subs: map[*natsSub]struct{}{}}
+}
+
+// ConnectNATS connects to the NATS servers at url, a comma-separated
+// list, and returns a bus closing the connection on Close.
+func ConnectNATS(url string, opts ...nats.Option) (*NATS, error) {
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("bus: connect: %w", err)
+	}
+
+	b := NewNATS(conn)
+	b.owned = true
+
+	return b, nil
+}
+
+// Conn returns the underlying connection.
+func (b *NATS) Conn() *nats.Conn {
+	return b.conn
+}
+
+// Publish implements Bus.
+func (b *NATS) Publish(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("bus: publish %s: %w", msg.Subject, err)
+	}
+
+	out := nats.NewMsg(msg.Subject)
+	out.Data = msg.Data
+
+	for key, value := range msg.Header {
+		out.Header.Set(key, value)
+	}
+
+	if err := b.conn.PublishMsg(out); err != nil {
+		return fmt.Errorf("bus: publish %s: %w", msg.Subject, natsError(err))
+	}
+
+	return nil
+}
+
+// Subscribe implements Bus.
+func (b *NATS) Subscribe(
+	ctx context.Context, pattern string, h Handler, opts ...SubscribeOption,
+) (Subscription, error) {
+	settings := newSubscribeSettings(opts)
+	callback := func(in *nats.Msg) {
+		msg := &Message{Subject: in.Subject, Data: in.Data}
+		if len(in.Header) > 0 {
+			msg.Header = make(map[string]string, len(in.Header))
+			for key := range in.Header {
+				msg.Header[key] = in.Header.Get(key)
+			}
+		}
+
+		settings.deliver(ctx, h, msg)
+	}
+
+	var (
+		sub *nats.Subscription
+		err error
+	)
+	if settings.queue != "" {
+		sub, err = b.conn.QueueSubscribe(pattern, settings.queue, callback)
+	} else {
+		sub, err = b.conn.Subscribe(pattern, callback)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("bus: subscribe %s: %w", pattern, natsError(err))
+	}
+
+	s := &natsSub{bus: b, sub: sub}
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	return s, nil
+}
+
+// Close implements Bus, draining the subscriptions, then closing the
+// connection if ConnectNATS opened it.
+func (b *NATS) Close(ctx context.Context) error {
+	b.mu.Lock()
+	subs := make([]*natsSub, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	var errs []error
+	for _, sub := range subs {
+		errs = append(errs, sub.Unsubscribe(ctx))
+	}
+
+	if b.owned {
+		b.conn.Close()
+	}
+
+	return errors.Join(errs...)
+}
+
+type natsSub struct {
+	bus *NATS
+	sub *nats.Subscription
+}
+
+// Unsubscribe implements Subscription, draining the subscription.
+func (s *natsSub) Unsubscribe(ctx context.Context) error {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s)
+	s.bus.mu.Unlock()
+
+	closed := s.sub.StatusChanged(nats.SubscriptionClosed)
+
+	if err := s.sub.Drain(); err != nil &&
+		!errors.Is(err, nats.ErrBadSubscription) {
+		return fmt.Errorf("bus: unsubscribe %s: %w", s.sub.Subject, err)
+	}
+
+	for {
+		select {
+		case _, ok := <-closed:
+			if !ok {
+				return nil
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("bus: unsubscribe %s: %w", s.sub.Subject,
+				ctx.Err())
+		}
+	}
+}
+
+// natsError reports the operations on closed connections as ErrClosed.
+func natsError(err error) error {
+	if errors.Is(err, nats.ErrConnectionClosed) {
+		return fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+
+	return err
+}
@@ -0,0 +1,105 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/bus"
+	"example.com/go-template/util/testx"
+)
+
+// natsServer starts an embedded NATS server on a free port.
+func natsServer(t *testing.T) string {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+
+	return srv.ClientURL()
+}
+
+func dial(t *testing.T, url string) *bus.NATS {
+	t.Helper()
+
+	b, err := bus.ConnectNATS(url)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close(context.Background()) })
+
+	return b
+}
+
+func TestNATS(t *testing.T) {
+	t.Parallel()
+
+	url := natsServer(t)
+	publisher, consumer := dial(t, url), dial(t, url)
+
+	var got, dead collector
+
+	_, err := consumer.Subscribe(t.Context(), "orders.*", got.handle)
+	require.NoError(t, err)
+	_, err = consumer.Subscribe(t.Context(), "orders.dead", dead.handle)
+	require.NoError(t, err)
+	_, err = consumer.Subscribe(t.Context(), "orders.created",
+		func(context.Context, *bus.Message) error {
+			return errors.New("rejected")
+		}, fastRetry,
+		bus.WithDeadLetter(bus.DeadLetterTo(consumer, "orders.dead")))
+	require.NoError(t, err)
+	require.NoError(t, consumer.Conn().Flush())
+
+	require.NoError(t, publisher.Publish(t.Context(), &bus.Message{
+		Subject: "orders.created", Data: []byte("order"),
+		Header: map[string]string{"Id": "1"},
+	}))
+	testx.RequireEventually(t, func() bool {
+		return got.count() == 2 && dead.count() == 1
+	}, 5*time.Second)
+
+	require.NoError(t, consumer.Close(t.Context()))
+	assert.Equal(t, []string{"orders.created", "orders.dead"}, got.subjects())
+	assert.Equal(t, "1", got.messages[0].Header["Id"])
+	assert.Equal(t, "order", string(dead.messages[0].Data))
+	header := dead.messages[0].Header
+	assert.Equal(t, "orders.created", header[bus.HeaderSubject])
+	assert.Contains(t, header[bus.HeaderError], "rejected")
+
+	require.ErrorIs(t, consumer.Publish(t.Context(),
+		&bus.Message{Subject: "x"}), bus.ErrClosed)
+}
+
+func TestNATSQueue(t *testing.T) {
+	t.Parallel()
+
+	b := dial(t, natsServer(t))
+
+	var first, second collector
+
+	_, err := b.Subscribe(t.Context(), "jobs", first.handle,
+		bus.WithQueue("workers"))
+	require.NoError(t, err)
+	_, err = b.Subscribe(t.Context(), "jobs", second.handle,
+		bus.WithQueue("workers"))
+	require.NoError(t, err)
+
+	for range 20 {
+		require.NoError(t, b.Publish(t.Context(),
+			&bus.Message{Subject: "jobs"}))
+	}
+
+	testx.RequireEventually(t, func() bool {
+		return first.count()+second.count() == 20
+	}, 5*time.Second)
+}
@@ -0,0 +1,149 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSize is returned by ParseBytes for malformed input.
+var ErrInvalidSize = errors.New("util: invalid byte size")
+
+// Byte size multiples.
+const (
+	KB int64 = 1000
+	MB       = KB * 1000
+	GB       = MB * 1000
+	TB       = GB * 1000
+	PB       = TB * 1000
+	EB       = PB * 1000
+
+	KiB int64 = 1 << 10
+	MiB       = KiB << 10
+	GiB       = MiB << 10
+	TiB       = GiB << 10
+	PiB       = TiB << 10
+	EiB       = PiB << 10
+)
+
+// DefaultBytesPrecision is the number of decimals printed by FormatBytes.
+const DefaultBytesPrecision = 1
+
+var (
+	siPrefixes  = []string{"kB", "MB", "GB", "TB", "PB", "EB"}
+	iecPrefixes = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+// BytesOption configures FormatBytes.
+type BytesOption func(*bytesFormat)
+
+type bytesFormat struct {
+	base      float64
+	units     []string
+	precision int
+}
+
+// WithSIUnits formats with powers of 1000 (kB, MB) instead of powers of 1024
+// (KiB, MiB).
+func WithSIUnits() BytesOption {
+	return func(f *bytesFormat) {
+		f.base = float64(KB)
+		f.units = siPrefixes
+	}
+}
+
+// WithBytesPrecision sets the number of decimals, DefaultBytesPrecision by
+// default. Plain byte counts never have decimals.
+func WithBytesPrecision(decimals int) BytesOption {
+	return func(f *bytesFormat) {
+		f.precision = max(decimals, 0)
+	}
+}
+
+// FormatBytes renders n with the largest fitting IEC unit, such as
+// "512 B" or "1.5 MiB".
+func FormatBytes(n int64, opts ...BytesOption) string {
+	format := bytesFormat{
+		base:      float64(KiB),
+		units:     iecPrefixes,
+		precision: DefaultBytesPrecision,
+	}
+
+	for _, opt := range opts {
+		opt(&format)
+	}
+
+	sign := ""
+	value := float64(n)
+
+	if n < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	if value < format.base {
+		return sign + strconv.FormatInt(int64(value), 10) + " B"
+	}
+
+	unit := -1
+	for unit < len(format.units)-1 && value >= format.base {
+		value /= format.base
+		unit++
+	}
+
+	// Rounding may carry into the next unit, e.g. 1023.96 KiB.
+	text := strconv.FormatFloat(value, 'f', format.precision, 64)
+	if rounded, _ := strconv.ParseFloat(text, 64); rounded >= format.base &&
+		unit < len(format.units)-1 {
+		value /= format.base
+		unit++
+		text = strconv.FormatFloat(value, 'f', format.precision, 64)
+	}
+
+	return sign + text + " " + format.units[unit]
+}
+
+var byteMultiples = map[string]int64{
+	"": 1, "b": 1,
+	"k": KB, "kb": KB, "ki": KiB, "kib": KiB,
+	"m": MB, "mb": MB, "mi": MiB, "mib": MiB,
+	"g": GB, "gb": GB, "gi": GiB, "gib": GiB,
+	"t": TB, "tb": TB, "ti": TiB, "tib": TiB,
+	"p": PB, "pb": PB, "pi": PiB, "pib": PiB,
+	"e": EB, "eb": EB, "ei": EiB, "eib": EiB,
+}
+
+// ParseBytes parses sizes such as "1024", "10 KB", "1.5GiB" or "512Mi".
+// Units are case-insensitive: decimal ones (k, KB) are powers of 1000 while
+// binary ones (Ki, KiB) are powers of 1024.
+func ParseBytes(s string) (int64, error) {
+	input := strings.TrimSpace(s)
+
+	end := strings.IndexFunc(input, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end < 0 {
+		end = len(input)
+	}
+
+	value, err := strconv.ParseFloat(input[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+
+	unit := strings.ToLower(strings.TrimSpace(input[end:]))
+
+	multiple, ok := byteMultiples[unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit in %q", ErrInvalidSize, s)
+	}
+
+	size := value * float64(multiple)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %q overflows", ErrInvalidSize, s)
+	}
+
+	return int64(size), nil
+}
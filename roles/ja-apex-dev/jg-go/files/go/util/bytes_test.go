@@ -0,0 +1,90 @@
+package util_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	for n, want := range map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		util.KiB:          "1.0 KiB",
+		1536 * util.KiB:   "1.5 MiB",
+		util.KiB*1024 - 1: "1.0 MiB",
+		-2 * util.GiB:     "-2.0 GiB",
+		math.MaxInt64:     "8.0 EiB",
+	} {
+		assert.Equal(t, want, util.FormatBytes(n), n)
+	}
+
+	assert.Equal(t, "1.50 MB",
+		util.FormatBytes(1500*util.KB, util.WithSIUnits(),
+			util.WithBytesPrecision(2)))
+	assert.Equal(t, "2 GiB",
+		util.FormatBytes(2*util.GiB+5, util.WithBytesPrecision(0)))
+	assert.Equal(t, "999 B", util.FormatBytes(999, util.WithSIUnits()))
+}
+
+func TestParseBytes(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]int64{
+		"1024":    1024,
+		"10 B":    10,
+		"10 KB":   10 * util.KB,
+		"1.5GiB":  util.GiB + 512*util.MiB,
+		"512Mi":   512 * util.MiB,
+		" 2 tib ": 2 * util.TiB,
+		"1k":      util.KB,
+	} {
+		got, err := util.ParseBytes(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{
+		"", "KB", "-1 KB", "10 XB", "1..5 MB", "16 EiB",
+	} {
+		_, err := util.ParseBytes(input)
+		require.ErrorIs(t, err, util.ErrInvalidSize, input)
+	}
+}
+
+func TestBytesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int64{100, 3 * util.MiB, 5 * util.PiB} {
+		got, err := util.ParseBytes(util.FormatBytes(n))
+		require.NoError(t, err)
+		assert.Equal(t, n, got)
+	}
+}
+
+func FuzzParseBytes(f *testing.F) {
+	for _, seed := range []string{
+		"1024", "10 KB", "1.5GiB", "512Mi", "8 EiB", "9223372036854775807",
+		"-1", ".", "1..5k", "", "k",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := util.ParseBytes(s)
+		if err != nil {
+			require.ErrorIs(t, err, util.ErrInvalidSize)
+			assert.Zero(t, n)
+
+			return
+		}
+
+		assert.GreaterOrEqual(t, n, int64(0))
+	})
+}
@@ -0,0 +1,286 @@
+// Package cache provides a generic in-memory cache with per-entry TTL,
+// least-recently-used eviction, deduplicated loading and metrics hooks.
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// EvictReason tells why an entry left the cache.
+type EvictReason int
+
+// Eviction reasons reported to Hooks.OnEvict.
+const (
+	// EvictCapacity removes the least recently used entry of a full cache.
+	EvictCapacity EvictReason = iota
+	// EvictExpired removes an entry past its TTL.
+	EvictExpired
+)
+
+// String returns the reason name, suitable as a metric label.
+func (r EvictReason) String() string {
+	if r == EvictExpired {
+		return "expired"
+	}
+
+	return "capacity"
+}
+
+// Hooks observe cache activity, typically to feed metrics. They run while
+// the cache is locked and must be fast and not use the cache.
+type Hooks struct {
+	OnHit   func()
+	OnMiss  func()
+	OnEvict func(reason EvictReason)
+	// OnLoad reports every loader call made by GetOrLoad.
+	OnLoad func(elapsed time.Duration, err error)
+}
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+	hooks      Hooks
+}
+
+// WithMaxEntries bounds the number of entries, evicting the least recently
+// used ones. Zero, the default, means unbounded.
+func WithMaxEntries(n int) Option {
+	return func(s *settings) {
+		s.maxEntries = n
+	}
+}
+
+// WithTTL sets the lifetime of entries stored by Set and GetOrLoad. Zero,
+// the default, keeps entries until evicted.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *settings) {
+		s.ttl = ttl
+	}
+}
+
+// WithClock sets the clock used for expiry and load timings.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.now = c.Now
+	}
+}
+
+// WithHooks sets the metrics hooks.
+func WithHooks(hooks Hooks) Option {
+	return func(s *settings) {
+		s.hooks = hooks
+	}
+}
+
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	expires    time.Time
+	prev, next *entry[K, V]
+}
+
+// Cache maps keys to values. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	settings
+
+	mu      sync.Mutex
+	entries map[K]*entry[K, V]
+	// newest and oldest are the ends of the recency list.
+	newest, oldest *entry[K, V]
+	calls          map[K]*call[V]
+}
+
+// New returns an empty cache.
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
+	c := &Cache[K, V]{
+		settings: settings{now: time.Now},
+		entries:  make(map[K]*entry[K, V]),
+		calls:    make(map[K]*call[V]),
+	}
+
+	for _, opt := range opts {
+		opt(&c.settings)
+	}
+
+	return c
+}
+
+// Get returns the live value stored under key and marks it as recently
+// used.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookup(key)
+}
+
+// Set stores value under key with the default TTL.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value under key for ttl, or forever when ttl is not
+// positive.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(key, value, ttl)
+}
+
+// Delete removes key, reporting whether it was present.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if ok {
+		c.remove(e)
+	}
+
+	return ok
+}
+
+// DeleteExpired removes every expired entry, returning how many were
+// dropped. Expired entries are otherwise only removed when looked up or
+// evicted, so long-lived caches may call it periodically.
+func (c *Cache[K, V]) DeleteExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	removed := 0
+
+	for e := c.oldest; e != nil; {
+		prev := e.prev
+		if e.expired(now) {
+			c.evict(e, EvictExpired)
+			removed++
+		}
+
+		e = prev
+	}
+
+	return removed
+}
+
+// Len returns the number of entries, including expired ones not yet
+// removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// Purge removes every entry.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.newest, c.oldest = nil, nil
+}
+
+func (c *Cache[K, V]) lookup(key K) (V, bool) {
+	e, ok := c.entries[key]
+
+	switch {
+	case !ok:
+	case e.expired(c.now()):
+		c.evict(e, EvictExpired)
+	default:
+		c.unlink(e)
+		c.pushNewest(e)
+		c.hook(c.hooks.OnHit)
+
+		return e.value, true
+	}
+
+	c.hook(c.hooks.OnMiss)
+
+	var zero V
+
+	return zero, false
+}
+
+func (c *Cache[K, V]) store(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	if e, ok := c.entries[key]; ok {
+		e.value, e.expires = value, expires
+		c.unlink(e)
+		c.pushNewest(e)
+
+		return
+	}
+
+	e := &entry[K, V]{key: key, value: value, expires: expires}
+	c.entries[key] = e
+	c.pushNewest(e)
+
+	if c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+		c.evict(c.oldest, EvictCapacity)
+	}
+}
+
+func (c *Cache[K, V]) evict(e *entry[K, V], reason EvictReason) {
+	c.remove(e)
+
+	if c.hooks.OnEvict != nil {
+		c.hooks.OnEvict(reason)
+	}
+}
+
+func (c *Cache[K, V]) remove(e *entry[K, V]) {
+	c.unlink(e)
+	delete(c.entries, e.key)
+}
+
+func (c *Cache[K, V]) pushNewest(e *entry[K, V]) {
+	e.prev, e.next = nil, c.newest
+	if c.newest != nil {
+		c.newest.prev = e
+	}
+
+	c.newest = e
+	if c.oldest == nil {
+		c.oldest = e
+	}
+}
+
+func (c *Cache[K, V]) unlink(e *entry[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		c.newest = e.next
+	}
+
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		c.oldest = e.prev
+	}
+
+	e.prev, e.next = nil, nil
+}
+
+func (c *Cache[K, V]) hook(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
@@ -0,0 +1,107 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/cache"
+	"example.com/go-template/util/clock"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestCacheGetSet(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1)
+	c.Set("a", 2)
+
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	_, ok = c.Get("missing")
+	assert.False(t, ok)
+
+	assert.True(t, c.Delete("a"))
+	assert.False(t, c.Delete("a"))
+
+	c.Set("b", 1)
+	c.Purge()
+	assert.Zero(t, c.Len())
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+
+	var evictions []cache.EvictReason
+
+	c := cache.New[string, int](
+		cache.WithTTL(time.Minute),
+		cache.WithClock(fake),
+		cache.WithHooks(cache.Hooks{OnEvict: func(r cache.EvictReason) {
+			evictions = append(evictions, r)
+		}}),
+	)
+
+	c.Set("short", 1)
+	c.SetWithTTL("long", 2, time.Hour)
+	c.SetWithTTL("forever", 3, 0)
+
+	fake.Advance(time.Minute)
+
+	_, ok := c.Get("short")
+	assert.False(t, ok)
+
+	_, ok = c.Get("long")
+	assert.True(t, ok)
+
+	fake.Advance(time.Hour)
+	assert.Equal(t, 1, c.DeleteExpired())
+
+	_, ok = c.Get("forever")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t,
+		[]cache.EvictReason{cache.EvictExpired, cache.EvictExpired}, evictions)
+	assert.Equal(t, "expired", cache.EvictExpired.String())
+}
+
+func TestCacheLRU(t *testing.T) {
+	t.Parallel()
+
+	var hits, misses, evicted int
+
+	c := cache.New[int, string](
+		cache.WithMaxEntries(2),
+		cache.WithHooks(cache.Hooks{
+			OnHit:   func() { hits++ },
+			OnMiss:  func() { misses++ },
+			OnEvict: func(cache.EvictReason) { evicted++ },
+		}),
+	)
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Get(1)
+	c.Set(3, "three")
+
+	_, ok := c.Get(2)
+	assert.False(t, ok, "least recently used entry is evicted")
+
+	_, ok = c.Get(1)
+	assert.True(t, ok)
+
+	_, ok = c.Get(3)
+	assert.True(t, ok)
+
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 3, hits)
+	assert.Equal(t, 1, misses)
+	assert.Equal(t, 1, evicted)
+}
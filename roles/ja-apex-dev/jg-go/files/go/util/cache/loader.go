@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrLoaderPanic is returned by GetOrLoad when the loader panics.
+var ErrLoaderPanic = errors.New("cache: loader panicked")
+
+// LoadFunc computes the value of a missing key.
+type LoadFunc[V any] func(ctx context.Context) (V, error)
+
+// call is a load in flight, shared by concurrent callers.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// GetOrLoad returns the value stored under key, calling load on a miss and
+// caching its result with the default TTL. Concurrent callers missing the
+// same key share a single load, which runs with the context of the first
+// one; the others stop waiting when their own context ends. Errors are not
+// cached.
+func (c *Cache[K, V]) GetOrLoad(
+	ctx context.Context, key K, load LoadFunc[V],
+) (V, error) {
+	c.mu.Lock()
+
+	if value, ok := c.lookup(key); ok {
+		c.mu.Unlock()
+
+		return value, nil
+	}
+
+	pending, ok := c.calls[key]
+	if !ok {
+		pending = &call[V]{done: make(chan struct{})}
+		c.calls[key] = pending
+	}
+
+	c.mu.Unlock()
+
+	if !ok {
+		c.load(ctx, key, pending, load)
+
+		return pending.value, pending.err
+	}
+
+	select {
+	case <-pending.done:
+		return pending.value, pending.err
+	case <-ctx.Done():
+		var zero V
+
+		return zero, fmt.Errorf("cache: %w", ctx.Err())
+	}
+}
+
+func (c *Cache[K, V]) load(
+	ctx context.Context, key K, pending *call[V], load LoadFunc[V],
+) {
+	start := c.now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			pending.err = fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+
+		c.mu.Lock()
+		delete(c.calls, key)
+
+		if pending.err == nil {
+			c.store(key, pending.value, c.ttl)
+		}
+
+		if c.hooks.OnLoad != nil {
+			c.hooks.OnLoad(c.now().Sub(start), pending.err)
+		}
+
+		c.mu.Unlock()
+
+		close(pending.done)
+	}()
+
+	pending.value, pending.err = load(ctx)
+}
@@ -0,0 +1,127 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/cache"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New[string, int]()
+
+	var calls atomic.Int32
+
+	load := func(context.Context) (int, error) {
+		calls.Add(1)
+
+		return 42, nil
+	}
+
+	for range 2 {
+		value, err := c.GetOrLoad(t.Context(), "k", load)
+		require.NoError(t, err)
+		assert.Equal(t, 42, value)
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetOrLoadDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	var loads atomic.Int32
+
+	c := cache.New[string, int](cache.WithHooks(cache.Hooks{
+		OnLoad: func(time.Duration, error) { loads.Add(1) },
+	}))
+
+	release := make(chan struct{})
+
+	var (
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+
+	for range 10 {
+		wg.Go(func() {
+			value, err := c.GetOrLoad(t.Context(), "k",
+				func(context.Context) (int, error) {
+					calls.Add(1)
+					<-release
+
+					return 7, nil
+				})
+			assert.NoError(t, err)
+			assert.Equal(t, 7, value)
+		})
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestGetOrLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New[string, int]()
+
+	_, err := c.GetOrLoad(t.Context(), "k",
+		func(context.Context) (int, error) { return 0, assert.AnError })
+	require.ErrorIs(t, err, assert.AnError)
+
+	_, ok := c.Get("k")
+	assert.False(t, ok, "errors are not cached")
+
+	_, err = c.GetOrLoad(t.Context(), "k",
+		func(context.Context) (int, error) { panic("boom") })
+	require.ErrorIs(t, err, cache.ErrLoaderPanic)
+
+	value, err := c.GetOrLoad(t.Context(), "k",
+		func(context.Context) (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+}
+
+func TestGetOrLoadWaiterContext(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New[string, int]()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		_, _ = c.GetOrLoad(context.Background(), "k",
+			func(context.Context) (int, error) {
+				close(started)
+				<-release
+
+				return 1, nil
+			})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.GetOrLoad(ctx, "k", func(context.Context) (int, error) {
+		return 0, errors.New("not called")
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+}
@@ -0,0 +1,266 @@
+package util
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCycle is returned by CanonicalString for values referencing
+	// themselves.
+	ErrCycle = errors.New("util: value contains a cycle")
+	// ErrNotCanonical is returned by CanonicalString for functions,
+	// channels and unsafe pointers, which have no stable representation.
+	ErrNotCanonical = errors.New("util: value has no canonical form")
+)
+
+// CanonicalString renders v deterministically, for cache keys and change
+// detection: equal values give equal strings whatever the map iteration
+// order or the pointers in between.
+//
+// Structs are rendered with their type name and their exported fields
+// sorted by name, maps with their entries sorted by key and pointers and
+// interfaces as the value they hold, nil being "nil". Values implementing
+// encoding.TextMarshaler, such as time.Time, are rendered as their quoted
+// text, and byte slices and arrays in hexadecimal:
+//
+//	main.Config{Name: "api", Tags: {"env": "prod"}, Timeout: 5000000000}
+//
+// Values shared by several fields are rendered each time; a value
+// containing itself returns ErrCycle.
+func CanonicalString(v any) (string, error) {
+	c := canonicalizer{visiting: make(map[visit]struct{})}
+	if err := c.write(reflect.ValueOf(v)); err != nil {
+		return "", err
+	}
+
+	return c.b.String(), nil
+}
+
+// Fingerprint returns the hex-encoded SHA-256 of the CanonicalString of v.
+func Fingerprint(v any) (string, error) {
+	s, err := CanonicalString(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// visit identifies a pointer, map or slice being rendered.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type canonicalizer struct {
+	b        strings.Builder
+	visiting map[visit]struct{}
+}
+
+func (c *canonicalizer) write(value reflect.Value) error {
+	if isNil(value) {
+		c.b.WriteString("nil")
+
+		return nil
+	}
+
+	if value.CanInterface() && value.Type().Implements(textMarshalerType) {
+		return c.writeText(value)
+	}
+
+	return c.writeKind(value)
+}
+
+func (c *canonicalizer) writeKind(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		return c.enter(value, func() error { return c.write(value.Elem()) })
+	case reflect.Interface:
+		return c.write(value.Elem())
+	case reflect.Struct:
+		return c.writeStruct(value)
+	case reflect.Map:
+		return c.enter(value, func() error { return c.writeMap(value) })
+	case reflect.Slice:
+		return c.enter(value, func() error { return c.writeList(value) })
+	case reflect.Array:
+		return c.writeList(value)
+	default:
+		return c.writeScalar(value)
+	}
+}
+
+// enter renders the value of a pointer, map or slice with fn, failing
+// when it is already being rendered higher in the tree.
+func (c *canonicalizer) enter(value reflect.Value, fn func() error) error {
+	key := visit{ptr: value.Pointer(), typ: value.Type()}
+	if value.Kind() == reflect.Slice {
+		key.len = value.Len()
+	}
+
+	if _, ok := c.visiting[key]; ok {
+		return fmt.Errorf("%w: %s", ErrCycle, value.Type())
+	}
+
+	c.visiting[key] = struct{}{}
+	defer delete(c.visiting, key)
+
+	return fn()
+}
+
+func (c *canonicalizer) writeText(value reflect.Value) error {
+	text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return fmt.Errorf("util: marshal %s: %w", value.Type(), err)
+	}
+
+	c.b.WriteString(strconv.Quote(string(text)))
+
+	return nil
+}
+
+func (c *canonicalizer) writeStruct(value reflect.Value) error {
+	t := value.Type()
+
+	var fields []reflect.StructField
+
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			fields = append(fields, t.Field(i))
+		}
+	}
+
+	slices.SortFunc(fields, func(a, b reflect.StructField) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	c.b.WriteString(t.String())
+	c.b.WriteByte('{')
+
+	for i, field := range fields {
+		if i > 0 {
+			c.b.WriteString(", ")
+		}
+
+		c.b.WriteString(field.Name + ": ")
+
+		if err := c.write(value.FieldByIndex(field.Index)); err != nil {
+			return err
+		}
+	}
+
+	c.b.WriteByte('}')
+
+	return nil
+}
+
+func (c *canonicalizer) writeMap(value reflect.Value) error {
+	entries := make([][2]string, 0, value.Len())
+
+	for iter := value.MapRange(); iter.Next(); {
+		key, err := c.format(iter.Key())
+		if err != nil {
+			return err
+		}
+
+		elem, err := c.format(iter.Value())
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, [2]string{key, elem})
+	}
+
+	slices.SortFunc(entries, func(a, b [2]string) int {
+		return cmp.Compare(a[0], b[0])
+	})
+
+	c.b.WriteByte('{')
+
+	for i, entry := range entries {
+		if i > 0 {
+			c.b.WriteString(", ")
+		}
+
+		c.b.WriteString(entry[0] + ": " + entry[1])
+	}
+
+	c.b.WriteByte('}')
+
+	return nil
+}
+
+// format renders value on its own, sharing the values being visited.
+func (c *canonicalizer) format(value reflect.Value) (string, error) {
+	sub := canonicalizer{visiting: c.visiting}
+	if err := sub.write(value); err != nil {
+		return "", err
+	}
+
+	return sub.b.String(), nil
+}
+
+func (c *canonicalizer) writeList(value reflect.Value) error {
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		c.b.WriteString("0x")
+
+		for i := range value.Len() {
+			fmt.Fprintf(&c.b, "%02x", value.Index(i).Uint())
+		}
+
+		return nil
+	}
+
+	c.b.WriteByte('[')
+
+	for i := range value.Len() {
+		if i > 0 {
+			c.b.WriteString(", ")
+		}
+
+		if err := c.write(value.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	c.b.WriteByte(']')
+
+	return nil
+}
+
+func (c *canonicalizer) writeScalar(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Bool:
+		c.b.WriteString(strconv.FormatBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		c.b.WriteString(strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		c.b.WriteString(strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		c.b.WriteString(strconv.FormatFloat(value.Float(), 'g', -1,
+			value.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		c.b.WriteString(strconv.FormatComplex(value.Complex(), 'g', -1,
+			value.Type().Bits()))
+	case reflect.String:
+		c.b.WriteString(strconv.Quote(value.String()))
+	default:
+		return fmt.Errorf("%w: %s", ErrNotCanonical, value.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package util_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/quick"
+)
+
+type service struct {
+	Timeout time.Duration
+	Name    string
+	Tags    map[string]string
+	Deploy  *time.Time
+	Ports   []uint16
+	Digest  [2]byte
+	Owner   any
+	secret  string
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestCanonicalString(t *testing.T) {
+	t.Parallel()
+
+	deploy := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	got, err := util.CanonicalString(&service{
+		Timeout: time.Second, Name: "api",
+		Tags:   map[string]string{"tier": "web", "env": "prod"},
+		Deploy: &deploy, Ports: []uint16{80, 443}, Digest: [2]byte{0xca, 0xfe},
+		secret: "hidden",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `util_test.service{Deploy: "2024-01-10T10:00:00Z", `+
+		`Digest: 0xcafe, Name: "api", Owner: nil, Ports: [80, 443], `+
+		`Tags: {"env": "prod", "tier": "web"}, Timeout: 1000000000}`, got)
+
+	for v, want := range map[any]string{
+		nil: "nil", true: "true", -3: "-3", 1.5: "1.5", "a\"b": `"a\"b"`,
+		complex(1, -2): "(1-2i)",
+	} {
+		got, err := util.CanonicalString(v)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestCanonicalStringMaps(t *testing.T) {
+	t.Parallel()
+
+	got, err := util.CanonicalString(map[any][]any{
+		2: {"b", nil}, 10: {}, "1": {map[int]bool{3: true, 1: false}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"1": [{1: false, 3: true}], 10: [], 2: ["b", nil]}`,
+		got, "keys sort by their canonical string")
+}
+
+func TestCanonicalStringCycles(t *testing.T) {
+	t.Parallel()
+
+	shared := &node{Name: "shared"}
+	got, err := util.CanonicalString([]*node{shared, shared})
+	require.NoError(t, err)
+	assert.Equal(t, `[util_test.node{Name: "shared", Next: nil}, `+
+		`util_test.node{Name: "shared", Next: nil}]`, got)
+
+	loop := &node{Name: "loop"}
+	loop.Next = &node{Name: "back", Next: loop}
+
+	list := []any{nil}
+	list[0] = list
+
+	tree := map[string]any{}
+	tree["self"] = tree
+
+	for _, v := range []any{loop, list, tree} {
+		_, err := util.CanonicalString(v)
+		require.ErrorIs(t, err, util.ErrCycle)
+	}
+
+	_, err = util.CanonicalString(map[string]any{"handler": t.Log})
+	require.ErrorIs(t, err, util.ErrNotCanonical)
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	a, err := util.Fingerprint(service{Name: "api", Ports: []uint16{80}})
+	require.NoError(t, err)
+	assert.Len(t, a, 64)
+
+	b, err := util.Fingerprint(&service{Name: "api", Ports: []uint16{80}})
+	require.NoError(t, err)
+	assert.Equal(t, a, b, "pointers are transparent")
+
+	c, err := util.Fingerprint(service{Name: "api", Ports: []uint16{81}})
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	_, err = util.Fingerprint(make(chan int))
+	require.ErrorIs(t, err, util.ErrNotCanonical)
+}
+
+func TestFingerprintProperty(t *testing.T) {
+	t.Parallel()
+
+	quick.Check(t, quick.NestedMaps(3), func(t *quick.T, m map[string]any) {
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(util.MustMarshal(m), &decoded))
+
+		want, err := util.Fingerprint(m)
+		require.NoError(t, err)
+
+		got, err := util.Fingerprint(decoded)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "stable across a JSON round-trip")
+	})
+}
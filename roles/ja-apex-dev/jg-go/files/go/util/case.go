@@ -0,0 +1,138 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitWords splits s into words on separators, lower-to-upper transitions
+// and acronym boundaries, so "HTTPServer_v2" yields "HTTP", "Server", "v2".
+// Digits stick to the preceding word. A plural "s" or a single lowercase
+// letter before digits stays with the acronym, as in "IDs" and "IPv6".
+func SplitWords(s string) []string {
+	runes := []rune(s)
+	words := []string{}
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+
+			continue
+		}
+
+		if start >= 0 && isWordBoundary(runes, i) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// isWordBoundary reports whether a new word starts at runes[i], assuming
+// runes[i-1] belongs to the current word.
+func isWordBoundary(runes []rune, i int) bool {
+	if !unicode.IsUpper(runes[i]) {
+		return false
+	}
+
+	prev := runes[i-1]
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+
+	// The last capital of an acronym starts the next word: "HTTPServer".
+	return unicode.IsUpper(prev) && i+1 < len(runes) &&
+		unicode.IsLower(runes[i+1]) && !isAcronymSuffix(runes, i+1)
+}
+
+// isAcronymSuffix reports whether the lowercase runes[i] ends the acronym
+// before it, as the plural in "IDs" or the letter in "IPv6".
+func isAcronymSuffix(runes []rune, i int) bool {
+	if i+1 == len(runes) || !unicode.IsLetter(runes[i+1]) {
+		return runes[i] == 's' ||
+			i+1 < len(runes) && unicode.IsDigit(runes[i+1])
+	}
+
+	return runes[i] == 's' && unicode.IsUpper(runes[i+1])
+}
+
+// ToSnakeCase converts s to snake_case: "HTTPServer" becomes "http_server".
+func ToSnakeCase(s string) string {
+	return joinLower(s, "_")
+}
+
+// ToKebabCase converts s to kebab-case: "HTTPServer" becomes "http-server".
+func ToKebabCase(s string) string {
+	return joinLower(s, "-")
+}
+
+// ToCamelCase converts s to camelCase: "http_server" becomes "httpServer".
+func ToCamelCase(s string) string {
+	words := SplitWords(s)
+
+	var b strings.Builder
+
+	for i, word := range words {
+		if i == 0 {
+			b.WriteString(strings.ToLower(word))
+		} else {
+			b.WriteString(capitalize(word))
+		}
+	}
+
+	return b.String()
+}
+
+// ToPascalCase converts s to PascalCase: "http_server" becomes "HttpServer".
+func ToPascalCase(s string) string {
+	var b strings.Builder
+	for _, word := range SplitWords(s) {
+		b.WriteString(capitalize(word))
+	}
+
+	return b.String()
+}
+
+// ToTitle converts s to space separated capitalized words: "http_server"
+// becomes "Http Server".
+func ToTitle(s string) string {
+	words := SplitWords(s)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func joinLower(s, sep string) string {
+	words := SplitWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+
+	return strings.Join(words, sep)
+}
+
+// capitalize upper-cases the first rune and lower-cases the rest.
+func capitalize(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if size == 0 {
+		return word
+	}
+
+	return string(unicode.ToUpper(first)) + strings.ToLower(word[size:])
+}
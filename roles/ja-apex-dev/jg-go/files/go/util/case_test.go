@@ -0,0 +1,82 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util"
+)
+
+func TestSplitWords(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"HTTPServer":       {"HTTP", "Server"},
+		"userID":           {"user", "ID"},
+		"parseJSONBody":    {"parse", "JSON", "Body"},
+		"snake_case-kebab": {"snake", "case", "kebab"},
+		"Base64Encode":     {"Base64", "Encode"},
+		"  spaced  out ":   {"spaced", "out"},
+		"ÜberStraße":       {"Über", "Straße"},
+		"userIDs":          {"user", "IDs"},
+		"URLsByHost":       {"URLs", "By", "Host"},
+		"IPv6Address":      {"IPv6", "Address"},
+		"HTTPSet":          {"HTTP", "Set"},
+		"":                 {},
+	}
+	for input, want := range cases {
+		assert.Equal(t, want, util.SplitWords(input), input)
+	}
+}
+
+func TestCaseConversions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input, snake, kebab, camel, pascal, title string
+	}{
+		{
+			"HTTPServer",
+			"http_server", "http-server", "httpServer", "HttpServer",
+			"Http Server",
+		},
+		{
+			"user_id",
+			"user_id", "user-id", "userId", "UserId",
+			"User Id",
+		},
+		{
+			"APIKeyV2",
+			"api_key_v2", "api-key-v2", "apiKeyV2", "ApiKeyV2",
+			"Api Key V2",
+		},
+		{
+			"userID",
+			"user_id", "user-id", "userId", "UserId",
+			"User Id",
+		},
+		{
+			"userIDs",
+			"user_ids", "user-ids", "userIds", "UserIds",
+			"User Ids",
+		},
+		{
+			"IPv6Address",
+			"ipv6_address", "ipv6-address", "ipv6Address", "Ipv6Address",
+			"Ipv6 Address",
+		},
+		{
+			"already-kebab case",
+			"already_kebab_case", "already-kebab-case", "alreadyKebabCase",
+			"AlreadyKebabCase", "Already Kebab Case",
+		},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.snake, util.ToSnakeCase(tc.input), tc.input)
+		assert.Equal(t, tc.kebab, util.ToKebabCase(tc.input), tc.input)
+		assert.Equal(t, tc.camel, util.ToCamelCase(tc.input), tc.input)
+		assert.Equal(t, tc.pascal, util.ToPascalCase(tc.input), tc.input)
+		assert.Equal(t, tc.title, util.ToTitle(tc.input), tc.input)
+	}
+}
@@ -0,0 +1,104 @@
+package chans
+
+import (
+	"context"
+	"time"
+)
+
+// Batch groups the values of in into slices of up to size values. A
+// batch is emitted once full or maxWait after its first value arrived,
+// whichever comes first, and the last partial batch is emitted when in is
+// closed. The output is closed after that or once ctx is done, dropping
+// any pending values. A non-positive size is treated as one and a
+// non-positive maxWait waits for full batches only.
+func Batch[T any](
+	ctx context.Context, in <-chan T, size int, maxWait time.Duration,
+) <-chan []T {
+	b := &batcher[T]{size: max(size, 1), maxWait: maxWait, out: make(chan []T)}
+
+	go b.run(ctx, in)
+
+	return b.out
+}
+
+// batcher holds the state of one Batch pipeline.
+type batcher[T any] struct {
+	size    int
+	maxWait time.Duration
+	out     chan []T
+	pending []T
+	timer   *time.Timer
+}
+
+func (b *batcher[T]) run(ctx context.Context, in <-chan T) {
+	defer close(b.out)
+	defer b.stopTimer()
+
+	for b.step(ctx, in) {
+	}
+}
+
+// step waits for the next event, reporting false once the pipeline ends.
+func (b *batcher[T]) step(ctx context.Context, in <-chan T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-b.deadline():
+		return b.flush(ctx)
+	case value, ok := <-in:
+		if !ok {
+			b.flush(ctx)
+
+			return false
+		}
+
+		return b.add(ctx, value)
+	}
+}
+
+// add appends value to the pending batch, flushing it when full. It
+// reports false if ctx is done before the batch is delivered.
+func (b *batcher[T]) add(ctx context.Context, value T) bool {
+	b.pending = append(b.pending, value)
+
+	if len(b.pending) == 1 && b.maxWait > 0 {
+		b.timer = time.NewTimer(b.maxWait)
+	}
+
+	if len(b.pending) < b.size {
+		return true
+	}
+
+	return b.flush(ctx)
+}
+
+// flush emits the pending batch, if any, and starts a new one.
+func (b *batcher[T]) flush(ctx context.Context) bool {
+	b.stopTimer()
+
+	if len(b.pending) == 0 {
+		return true
+	}
+
+	batch := b.pending
+	b.pending = make([]T, 0, b.size)
+
+	return send(ctx, b.out, batch)
+}
+
+// deadline fires when the pending batch has waited long enough, and never
+// without a pending batch.
+func (b *batcher[T]) deadline() <-chan time.Time {
+	if b.timer == nil {
+		return nil
+	}
+
+	return b.timer.C
+}
+
+func (b *batcher[T]) stopTimer() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+}
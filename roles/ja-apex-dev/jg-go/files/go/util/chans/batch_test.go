@@ -0,0 +1,62 @@
+package chans_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/chans"
+)
+
+func TestBatchBySize(t *testing.T) {
+	t.Parallel()
+
+	batches, err := chans.DrainUntil(t.Context(),
+		chans.Batch(t.Context(), source(1, 2, 3, 4, 5), 2, time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+}
+
+func TestBatchByTime(t *testing.T) {
+	t.Parallel()
+
+	in := make(chan int)
+	batches := chans.Batch(t.Context(), in, 10, 20*time.Millisecond)
+
+	in <- 1
+	in <- 2
+
+	select {
+	case batch := <-batches:
+		assert.Equal(t, []int{1, 2}, batch)
+	case <-time.After(time.Second):
+		require.Fail(t, "partial batch not emitted after maxWait")
+	}
+
+	in <- 3
+	close(in)
+
+	assert.Equal(t, []int{3}, <-batches)
+
+	_, ok := <-batches
+	assert.False(t, ok)
+}
+
+func TestBatchCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	in := make(chan int, 1)
+	batches := chans.Batch(ctx, in, 2, 0)
+
+	in <- 1
+
+	cancel()
+
+	_, ok := <-batches
+	assert.False(t, ok, "pending values are dropped")
+}
@@ -0,0 +1,97 @@
+// Package chans provides context-aware helpers to merge, split, batch and
+// drain channels. Every helper stops its goroutines once the context is
+// done, so abandoning a pipeline early does not leak them.
+package chans
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge forwards the values of every input to a single channel, closed
+// once all inputs are closed or ctx is done. Values of one input keep
+// their order; values of different inputs interleave.
+func Merge[T any](ctx context.Context, inputs ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+
+	for _, in := range inputs {
+		wg.Go(func() {
+			forward(ctx, in, out)
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Split distributes the values of in over n channels, each value going
+// to whichever consumer is ready first, so that slow consumers do not hold
+// back the others. The outputs are closed once in is closed or ctx is
+// done. A non-positive n is treated as one.
+func Split[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outputs := make([]<-chan T, max(n, 1))
+
+	for i := range outputs {
+		out := make(chan T)
+		outputs[i] = out
+
+		go func() {
+			defer close(out)
+
+			forward(ctx, in, out)
+		}()
+	}
+
+	return outputs
+}
+
+// DrainUntil receives the values of in until it is closed or ctx is done,
+// returning them with the cause of ctx in the latter case. Deferring a
+// drain unblocks producers whose results are no longer needed.
+func DrainUntil[T any](ctx context.Context, in <-chan T) ([]T, error) {
+	var values []T
+
+	for {
+		select {
+		case <-ctx.Done():
+			return values, context.Cause(ctx)
+		case value, ok := <-in:
+			if !ok {
+				return values, nil
+			}
+
+			values = append(values, value)
+		}
+	}
+}
+
+// forward copies the values of in to out until in is closed or ctx is
+// done.
+func forward[T any](ctx context.Context, in <-chan T, out chan<- T) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case value, ok := <-in:
+			if !ok || !send(ctx, out, value) {
+				return
+			}
+		}
+	}
+}
+
+// send delivers value to out, reporting false if ctx is done first.
+func send[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- value:
+		return true
+	}
+}
@@ -0,0 +1,84 @@
+package chans_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/chans"
+)
+
+// source returns a closed channel holding values.
+func source[T any](values ...T) <-chan T {
+	ch := make(chan T, len(values))
+	for _, value := range values {
+		ch <- value
+	}
+
+	close(ch)
+
+	return ch
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	merged := chans.Merge(t.Context(), source(1, 2), source(3), source[int]())
+
+	values, err := chans.DrainUntil(t.Context(), merged)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{1, 2, 3}, values)
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	outputs := chans.Split(t.Context(), source(1, 2, 3, 4, 5), 3)
+	require.Len(t, outputs, 3)
+
+	var (
+		mu  sync.Mutex
+		all []int
+		wg  sync.WaitGroup
+	)
+
+	for _, out := range outputs {
+		wg.Go(func() {
+			values, err := chans.DrainUntil(t.Context(), out)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			all = append(all, values...)
+		})
+	}
+
+	wg.Wait()
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, all)
+}
+
+func TestCanceledPipelineCloses(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	blocked := make(chan int)
+	merged := chans.Merge(ctx, blocked)
+	outputs := chans.Split(ctx, blocked, 2)
+
+	cancel()
+
+	_, err := chans.DrainUntil(t.Context(), merged)
+	require.NoError(t, err, "the merged channel is closed")
+
+	for _, out := range outputs {
+		_, ok := <-out
+		assert.False(t, ok)
+	}
+
+	_, err = chans.DrainUntil(ctx, blocked)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,140 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"example.com/go-template/util/lifecycle"
+)
+
+// ErrCleanupPanic wraps a panic recovered from a cleanup function.
+var ErrCleanupPanic = errors.New("util: cleanup panicked")
+
+// Cleanups is a stack of cleanup functions registered across setup steps,
+// run in reverse order when a step fails or at shutdown, like defer
+// statements outliving the function registering them:
+//
+//	func install(ctx context.Context) (err error) {
+//		var cleanups util.Cleanups
+//		defer cleanups.OnFailure(ctx, &err)
+//
+//		ws, err := util.TempWorkspace(&cleanups)
+//		...
+//		cleanups.Defer(func() error { return os.Remove(unit) })
+//		...
+//	}
+//
+// The zero value is ready for use. It is safe for concurrent use.
+type Cleanups struct {
+	mu  sync.Mutex
+	fns []func(context.Context) error
+}
+
+// DeferContext pushes fn, given the context of Run.
+func (c *Cleanups) DeferContext(fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fns = append(c.fns, fn)
+}
+
+// Defer pushes fn.
+func (c *Cleanups) Defer(fn func() error) {
+	c.DeferContext(func(context.Context) error {
+		return fn()
+	})
+}
+
+// Cleanup pushes fn, which makes Cleanups a Cleaner, accepted by
+// TempWorkspace.
+func (c *Cleanups) Cleanup(fn func()) {
+	c.DeferContext(func(context.Context) error {
+		fn()
+
+		return nil
+	})
+}
+
+// Len returns the number of pending functions.
+func (c *Cleanups) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.fns)
+}
+
+// Release forgets the pending functions without running them, once the
+// resources they clean up are handed over, and returns them as a new
+// stack.
+func (c *Cleanups) Release() *Cleanups {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	released := &Cleanups{fns: c.fns}
+	c.fns = nil
+
+	return released
+}
+
+// Run pops and runs the functions, from the last one pushed, and returns
+// their joined errors. Every function runs even when others fail or
+// panic, and functions pushed meanwhile run too.
+func (c *Cleanups) Run(ctx context.Context) error {
+	var errs []error
+
+	for {
+		fn := c.pop()
+		if fn == nil {
+			return errors.Join(errs...)
+		}
+
+		if err := runCleanup(ctx, fn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+}
+
+// OnFailure runs the functions when *errp is not nil, joining their
+// errors to it, and keeps them otherwise. It is meant to be deferred
+// with the address of a named error result.
+func (c *Cleanups) OnFailure(ctx context.Context, errp *error) {
+	if *errp != nil {
+		*errp = errors.Join(*errp, c.Run(ctx))
+	}
+}
+
+// Hook returns a lifecycle hook running the functions on stop, for the
+// resources living until shutdown.
+func (c *Cleanups) Hook(name string) lifecycle.Hook {
+	return lifecycle.Hook{Name: name, Stop: c.Run}
+}
+
+// pop removes the last function, returning nil when there is none.
+func (c *Cleanups) pop() func(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.fns) == 0 {
+		return nil
+	}
+
+	fn := c.fns[len(c.fns)-1]
+	c.fns = c.fns[:len(c.fns)-1]
+
+	return fn
+}
+
+// runCleanup runs fn, turning its panics into errors.
+func runCleanup(ctx context.Context, fn func(context.Context) error) (
+	err error,
+) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = fmt.Errorf("%w: %v", ErrCleanupPanic, value)
+		}
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,127 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/lifecycle"
+)
+
+func TestCleanups(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cleanups util.Cleanups
+		order    []string
+	)
+
+	errFirst, errThird := errors.New("first"), errors.New("third")
+
+	cleanups.Defer(func() error {
+		order = append(order, "first")
+
+		return errFirst
+	})
+	cleanups.Cleanup(func() {
+		order = append(order, "second")
+	})
+	cleanups.DeferContext(func(ctx context.Context) error {
+		order = append(order, "third")
+		assert.Equal(t, "value", ctx.Value(ctxKey{}))
+
+		// Functions pushed while running run too.
+		cleanups.Cleanup(func() {
+			order = append(order, "late")
+		})
+
+		return errThird
+	})
+	cleanups.Cleanup(func() {
+		panic("boom")
+	})
+	assert.Equal(t, 4, cleanups.Len())
+
+	ctx := context.WithValue(t.Context(), ctxKey{}, "value")
+	err := cleanups.Run(ctx)
+
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errThird)
+	require.ErrorIs(t, err, util.ErrCleanupPanic)
+	assert.Equal(t, []string{"third", "late", "second", "first"}, order)
+	assert.Zero(t, cleanups.Len())
+	assert.NoError(t, cleanups.Run(ctx), "emptied")
+}
+
+type ctxKey struct{}
+
+// setup pushes a cleanup, then fails when fail is set.
+func setup(ctx context.Context, cleanups *util.Cleanups, fail error) (
+	err error,
+) {
+	defer cleanups.OnFailure(ctx, &err)
+
+	cleanups.Defer(func() error {
+		return errors.New("undo")
+	})
+
+	return fail
+}
+
+func TestCleanupsOnFailure(t *testing.T) {
+	t.Parallel()
+
+	var cleanups util.Cleanups
+
+	require.NoError(t, setup(t.Context(), &cleanups, nil))
+	assert.Equal(t, 1, cleanups.Len(), "kept on success")
+
+	errStep := errors.New("step")
+	err := setup(t.Context(), &cleanups, errStep)
+	require.ErrorIs(t, err, errStep)
+	require.ErrorContains(t, err, "undo")
+	assert.Zero(t, cleanups.Len())
+}
+
+func TestCleanupsRelease(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cleanups util.Cleanups
+		ran      bool
+	)
+
+	cleanups.Cleanup(func() {
+		ran = true
+	})
+
+	released := cleanups.Release()
+	require.NoError(t, cleanups.Run(t.Context()))
+	assert.False(t, ran)
+
+	require.NoError(t, released.Run(t.Context()))
+	assert.True(t, ran)
+}
+
+func TestCleanupsIntegration(t *testing.T) {
+	t.Parallel()
+
+	var cleanups util.Cleanups
+
+	ws, err := util.TempWorkspace(&cleanups,
+		util.WithWorkspaceParent(t.TempDir()))
+	require.NoError(t, err)
+	assert.DirExists(t, ws.Dir())
+
+	// The runner stops the hook, which removes the workspace.
+	runner := lifecycle.New(lifecycle.WithSignals())
+	runner.Append(cleanups.Hook("cleanups"))
+	runner.Shutdown(nil)
+
+	require.NoError(t, runner.Run(t.Context()))
+	assert.NoDirExists(t, ws.Dir())
+}
@@ -0,0 +1,227 @@
+// Package cli runs command-line programs made of nested subcommands. Flags
+// are bound to tagged config structs through util/config, so that every
+// command also reads environment variables and config files, and every
+// command gets a --json output mode, help output and shell completion.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"example.com/go-template/util/config"
+)
+
+var (
+	// ErrUnknownCommand is returned for arguments naming no subcommand of
+	// a command without Run.
+	ErrUnknownCommand = errors.New("cli: unknown command")
+	// ErrMissingCommand is returned when a command without Run is given no
+	// subcommand.
+	ErrMissingCommand = errors.New("cli: missing command")
+)
+
+// JSONFlag is the flag selecting the JSON output mode.
+const JSONFlag = "json"
+
+// RunFunc implements a command.
+type RunFunc func(ctx context.Context, inv *Invocation) error
+
+// Command is a node of the command tree. Commands without Run only group
+// their subcommands.
+type Command struct {
+	// Name is the word selecting the command; the root one names the
+	// program in help and completion scripts.
+	Name string
+	// Summary is the one-line description shown in help.
+	Summary string
+	// Usage describes the positional arguments, such as "<file>...".
+	Usage string
+	// Config points to a struct loaded by util/config before Run, whose
+	// `flag` tags define the flags of the command.
+	Config any
+	// Run is called with the remaining positional arguments.
+	Run RunFunc
+	// Commands are the subcommands.
+	Commands []*Command
+}
+
+// Invocation describes the command being run.
+type Invocation struct {
+	// Path holds the names of the commands from the root one.
+	Path []string
+	// Args are the positional arguments left after the flags.
+	Args   []string
+	Stdout io.Writer
+	Stderr io.Writer
+	// JSON is set by the --json flag.
+	JSON bool
+}
+
+// Output prints v, as indented JSON in JSON mode and with the default
+// fmt formatting otherwise, so that commands only build their result once.
+func (inv *Invocation) Output(v any) error {
+	if inv.JSON {
+		encoder := json.NewEncoder(inv.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("cli: encode output: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(inv.Stdout, v); err != nil {
+		return fmt.Errorf("cli: write output: %w", err)
+	}
+
+	return nil
+}
+
+// Option configures Run.
+type Option func(*settings)
+
+type settings struct {
+	stdout io.Writer
+	stderr io.Writer
+	config []config.Option
+}
+
+// WithOutput replaces the standard output and error streams.
+func WithOutput(stdout, stderr io.Writer) Option {
+	return func(s *settings) {
+		s.stdout, s.stderr = stdout, stderr
+	}
+}
+
+// WithConfig adds util/config options, such as config.WithEnv or
+// config.WithOptionalFiles, used to load the Config of the command.
+func WithConfig(opts ...config.Option) Option {
+	return func(s *settings) {
+		s.config = append(s.config, opts...)
+	}
+}
+
+// Run selects the command named by the leading args, loads its Config and
+// flags from the remaining ones and calls it. Help requested with -h
+// prints the usage and returns nil. The "completion" and hidden
+// "__complete" subcommands of the root implement shell completion unless
+// the root defines them.
+func Run(
+	ctx context.Context, root *Command, args []string, opts ...Option,
+) error {
+	s := settings{stdout: os.Stdout, stderr: os.Stderr}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if builtin, ok := builtins(root)[first(args)]; ok {
+		return builtin(root, args[1:], s.stdout)
+	}
+
+	path, rest := resolve(root, args)
+	cmd := path[len(path)-1]
+	inv := &Invocation{Path: names(path), Stdout: s.stdout, Stderr: s.stderr}
+
+	set := newFlagSet(inv)
+
+	err := parse(cmd, set, rest, s.config)
+	if errors.Is(err, flag.ErrHelp) {
+		return writeUsage(s.stdout, path, set)
+	}
+
+	if err != nil {
+		return usageError(s.stderr, path, set, err)
+	}
+
+	inv.Args = set.Args()
+
+	if cmd.Run == nil {
+		return usageError(s.stderr, path, set, missing(inv.Args))
+	}
+
+	return cmd.Run(ctx, inv)
+}
+
+// resolve follows the leading args naming subcommands, returning the path
+// from the root and the arguments left.
+func resolve(root *Command, args []string) ([]*Command, []string) {
+	path := []*Command{root}
+
+	for len(args) > 0 {
+		sub := path[len(path)-1].find(args[0])
+		if sub == nil {
+			break
+		}
+
+		path = append(path, sub)
+		args = args[1:]
+	}
+
+	return path, args
+}
+
+// find returns the subcommand named name, or nil.
+func (c *Command) find(name string) *Command {
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// newFlagSet returns a silent flag set holding the --json flag; errors
+// and help are printed by Run.
+func newFlagSet(inv *Invocation) *flag.FlagSet {
+	set := flag.NewFlagSet(strings.Join(inv.Path, " "), flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	set.BoolVar(&inv.JSON, JSONFlag, false, "print the output as JSON")
+
+	return set
+}
+
+// parse binds the flags of cmd to set and parses args.
+func parse(
+	cmd *Command, set *flag.FlagSet, args []string, opts []config.Option,
+) error {
+	if cmd.Config == nil {
+		return set.Parse(args)
+	}
+
+	opts = append(opts[:len(opts):len(opts)], config.WithFlags(set, args))
+
+	return config.Load(cmd.Config, opts...)
+}
+
+func missing(args []string) error {
+	if len(args) == 0 {
+		return ErrMissingCommand
+	}
+
+	return fmt.Errorf("%w %q", ErrUnknownCommand, args[0])
+}
+
+func names(path []*Command) []string {
+	result := make([]string, len(path))
+	for i, cmd := range path {
+		result[i] = cmd.Name
+	}
+
+	return result
+}
+
+func first(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return args[0]
+}
@@ -0,0 +1,110 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/cli"
+	"example.com/go-template/util/config"
+)
+
+type greetConfig struct {
+	Name  string `flag:"name"  env:"NAME" usage:"who to greet" default:"world"`
+	Shout bool   `flag:"shout" usage:"greet loudly"`
+}
+
+type greeting struct {
+	Message string `json:"message"`
+}
+
+// String is the text output of the greeting.
+func (g greeting) String() string {
+	return g.Message
+}
+
+// app returns a program with a "greet" command under a "say" group.
+func app(cfg *greetConfig) *cli.Command {
+	return &cli.Command{
+		Name:    "demo",
+		Summary: "Demo program.",
+		Commands: []*cli.Command{{
+			Name:    "say",
+			Summary: "Say things.",
+			Commands: []*cli.Command{{
+				Name:    "greet",
+				Summary: "Greet someone.",
+				Usage:   "[suffix]",
+				Config:  cfg,
+				Run: func(_ context.Context, inv *cli.Invocation) error {
+					message := "hello " + cfg.Name + strings.Join(inv.Args, "")
+					if cfg.Shout {
+						message = strings.ToUpper(message)
+					}
+
+					return inv.Output(greeting{Message: message})
+				},
+			}},
+		}},
+	}
+}
+
+// invoke executes the demo program, returning its standard output and error.
+func invoke(
+	t *testing.T, args []string, opts ...cli.Option,
+) (stdout, stderr string, err error) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+
+	err = cli.Run(t.Context(), app(&greetConfig{}), args,
+		append(opts, cli.WithOutput(&out, &errOut))...)
+
+	return out.String(), errOut.String(), err
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, err := invoke(t, []string{"say", "greet", "--shout", "!"})
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO WORLD!\n", stdout)
+
+	stdout, _, err = invoke(t,
+		[]string{"say", "greet", "--json", "-name", "ops"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message": "hello ops"}`, stdout)
+}
+
+func TestRunConfig(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) (string, bool) {
+		return "env", key == "DEMO_NAME"
+	}
+
+	stdout, _, err := invoke(t, []string{"say", "greet"},
+		cli.WithConfig(config.WithEnv("DEMO"), config.WithLookup(lookup)))
+	require.NoError(t, err)
+	assert.Equal(t, "hello env\n", stdout)
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	_, stderr, err := invoke(t, []string{"say"})
+	require.ErrorIs(t, err, cli.ErrMissingCommand)
+	assert.Contains(t, stderr, "Usage: demo say [flags] <command>")
+
+	_, _, err = invoke(t, []string{"say", "wave"})
+	require.ErrorIs(t, err, cli.ErrUnknownCommand)
+	require.ErrorContains(t, err, `demo say: cli: unknown command "wave"`)
+
+	_, stderr, err = invoke(t, []string{"say", "greet", "--bogus"})
+	require.ErrorContains(t, err, "bogus")
+	assert.Contains(t, stderr, "--shout")
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Names of the completion subcommands added to the root.
+const (
+	CompletionCommand = "completion"
+	completeCommand   = "__complete"
+)
+
+// ErrUnknownShell is returned by the completion command for shells other
+// than bash, zsh and fish.
+var ErrUnknownShell = errors.New("cli: unknown shell")
+
+// builtin implements a subcommand added to the root.
+type builtin func(root *Command, args []string, w io.Writer) error
+
+// builtins returns the completion subcommands not shadowed by the root.
+func builtins(root *Command) map[string]builtin {
+	result := map[string]builtin{
+		CompletionCommand: writeScript,
+		completeCommand:   writeCandidates,
+	}
+
+	for name := range result {
+		if root.find(name) != nil {
+			delete(result, name)
+		}
+	}
+
+	return result
+}
+
+// scripts hold the completion scripts, which ask the program itself for
+// candidates through the hidden __complete command. The verb is the
+// program name.
+var scripts = map[string]string{
+	"bash": `_%[1]s_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s __complete "${COMP_WORDS[@]:1:COMP_CWORD}"))
+}
+complete -o default -F _%[1]s_complete %[1]s
+`,
+	"zsh": `#compdef %[1]s
+_%[1]s() {
+    local -a candidates
+    candidates=(${(f)"$(%[1]s __complete "${words[@]:1:$CURRENT-1}")"})
+    compadd -a candidates
+}
+compdef _%[1]s %[1]s
+`,
+	"fish": "complete -c %[1]s -f -a " +
+		"'(%[1]s __complete (commandline -opc)[2..-1] (commandline -ct))'\n",
+}
+
+// writeScript prints the completion script of the shell named by args, to
+// be sourced from the shell profile, e.g.
+// source <(prog completion bash).
+func writeScript(root *Command, args []string, w io.Writer) error {
+	script, ok := scripts[first(args)]
+	if !ok {
+		return fmt.Errorf("%w %q, want bash, zsh or fish", ErrUnknownShell,
+			first(args))
+	}
+
+	if _, err := fmt.Fprintf(w, script, root.Name); err != nil {
+		return fmt.Errorf("cli: write completion: %w", err)
+	}
+
+	return nil
+}
+
+// writeCandidates prints the completions of the last word of args, one
+// per line: flags of the selected command when the word starts with a
+// dash, its subcommands otherwise.
+func writeCandidates(root *Command, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	word := args[len(args)-1]
+	path, _ := resolve(root, args[:len(args)-1])
+
+	for _, candidate := range candidates(path, word) {
+		if _, err := fmt.Fprintln(w, candidate); err != nil {
+			return fmt.Errorf("cli: write completion: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func candidates(path []*Command, word string) []string {
+	cmd := path[len(path)-1]
+
+	var all []string
+
+	if strings.HasPrefix(word, "-") {
+		set := newFlagSet(&Invocation{Path: names(path)})
+		if cmd.Config != nil {
+			// Registration errors only affect the listed flags.
+			_ = parse(cmd, set, nil, nil)
+		}
+
+		set.VisitAll(func(f *flag.Flag) {
+			all = append(all, "--"+f.Name)
+		})
+	} else {
+		for _, sub := range cmd.Commands {
+			all = append(all, sub.Name)
+		}
+	}
+
+	return slices.DeleteFunc(all, func(candidate string) bool {
+		return !strings.HasPrefix(candidate, word)
+	})
+}
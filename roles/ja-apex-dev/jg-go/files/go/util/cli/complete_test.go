@@ -0,0 +1,45 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/cli"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	t.Parallel()
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		stdout, _, err := invoke(t, []string{cli.CompletionCommand, shell})
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "demo __complete", shell)
+	}
+
+	_, _, err := invoke(t, []string{cli.CompletionCommand, "tcsh"})
+	require.ErrorIs(t, err, cli.ErrUnknownShell)
+}
+
+func TestCompleteCandidates(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"":              "say\n",
+		"s":             "say\n",
+		"say ":          "greet\n",
+		"say greet -":   "--json\n--name\n--shout\n",
+		"say greet --s": "--shout\n",
+		"x":             "",
+	}
+
+	for line, want := range cases {
+		args := append([]string{"__complete"}, strings.Split(line, " ")...)
+
+		stdout, _, err := invoke(t, args)
+		require.NoError(t, err)
+		assert.Equal(t, want, stdout, line)
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// writeUsage prints the help of the last command of path.
+func writeUsage(w io.Writer, path []*Command, set *flag.FlagSet) error {
+	cmd := path[len(path)-1]
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Usage: %s\n", synopsis(path))
+
+	if cmd.Summary != "" {
+		fmt.Fprintf(tw, "\n%s\n", cmd.Summary)
+	}
+
+	if len(cmd.Commands) > 0 {
+		fmt.Fprint(tw, "\nCommands:\n")
+
+		for _, sub := range cmd.Commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.Name, sub.Summary)
+		}
+	}
+
+	fmt.Fprint(tw, "\nFlags:\n")
+	set.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(tw, "  %s\t%s\n", flagSynopsis(f), flagUsage(f))
+	})
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("cli: write usage: %w", err)
+	}
+
+	return nil
+}
+
+// usageError prints the help of the command to w, usually the standard
+// error, and returns err for the caller to report.
+func usageError(
+	w io.Writer, path []*Command, set *flag.FlagSet, err error,
+) error {
+	if usageErr := writeUsage(w, path, set); usageErr != nil {
+		return usageErr
+	}
+
+	return fmt.Errorf("%s: %w", strings.Join(names(path), " "), err)
+}
+
+// synopsis is the usage line of the last command of path.
+func synopsis(path []*Command) string {
+	cmd := path[len(path)-1]
+	parts := append(names(path), "[flags]")
+
+	if len(cmd.Commands) > 0 {
+		parts = append(parts, "<command>")
+	}
+
+	if cmd.Usage != "" {
+		parts = append(parts, cmd.Usage)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// flagSynopsis shows the flag with the name of its value, taken from the
+// back-quoted word of its usage or from its type.
+func flagSynopsis(f *flag.Flag) string {
+	name, _ := flag.UnquoteUsage(f)
+	if name == "" {
+		return "--" + f.Name
+	}
+
+	return "--" + f.Name + " " + name
+}
+
+// flagUsage is the usage message of the flag, mentioning meaningful
+// defaults.
+func flagUsage(f *flag.Flag) string {
+	_, usage := flag.UnquoteUsage(f)
+	if f.DefValue == "" || f.DefValue == "false" || f.DefValue == "0" {
+		return usage
+	}
+
+	return fmt.Sprintf("%s (default %s)", usage, f.DefValue)
+}
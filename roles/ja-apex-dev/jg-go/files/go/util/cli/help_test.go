@@ -0,0 +1,28 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelp(t *testing.T) {
+	t.Parallel()
+
+	stdout, _, err := invoke(t, []string{"say", "greet", "-h"})
+	require.NoError(t, err)
+	assert.Equal(t, `Usage: demo say greet [flags] [suffix]
+
+Greet someone.
+
+Flags:
+  --json        print the output as JSON
+  --name value  who to greet (default world)
+  --shout       greet loudly
+`, stdout)
+
+	stdout, _, err = invoke(t, []string{"--help"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Commands:\n  say  Say things.\n")
+}
@@ -0,0 +1,162 @@
+// Package clock abstracts time so that code waiting on timers, tickers or
+// deadlines can be tested deterministically. Production code takes a Clock
+// and defaults to Real; tests pass a Fake and move it forward explicitly:
+//
+//	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	go worker(fake)
+//	fake.BlockUntil(ctx, 1) // the worker waits on a timer
+//	fake.Advance(time.Minute)
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and schedules wake-ups.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// After waits for d and sends the current time on the channel.
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses for d, returning early with the error of ctx when it is
+	// done first.
+	Sleep(ctx context.Context, d time.Duration) error
+	// NewTimer returns a timer firing once after d.
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a ticker firing every d. It panics if d is not
+	// positive.
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f after d. The timer has no channel.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a single scheduled event, as time.Timer.
+type Timer interface {
+	// C returns the channel receiving the firing time, nil for AfterFunc.
+	C() <-chan time.Time
+	// Stop cancels the timer and reports whether it was pending.
+	Stop() bool
+	// Reset reschedules the timer after d and reports whether it was
+	// pending.
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a recurring event, as time.Ticker.
+type Ticker interface {
+	// C returns the channel receiving the tick times.
+	C() <-chan time.Time
+	// Stop turns the ticker off.
+	Stop()
+	// Reset changes the period to d and restarts the ticker.
+	Reset(d time.Duration)
+}
+
+// Real returns the system clock.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns c, or the system clock when c is nil. It lets zero-value
+// configurations leave their clock unset.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real()
+	}
+
+	return c
+}
+
+type realClock struct{}
+
+// Now implements Clock.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since implements Clock.
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// After implements Clock.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep implements Clock.
+func (c realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, c, d)
+}
+
+// NewTimer implements Clock.
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+// NewTicker implements Clock.
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+// AfterFunc implements Clock.
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{timer: time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+// C implements Timer.
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop implements Timer.
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// Reset implements Timer.
+func (t realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+// C implements Ticker.
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop implements Ticker.
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// Reset implements Ticker.
+func (t realTicker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
+
+// sleep implements Sleep on top of the timers of c.
+func sleep(ctx context.Context, c Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
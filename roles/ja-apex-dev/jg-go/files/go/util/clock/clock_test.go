@@ -0,0 +1,53 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+)
+
+func TestReal(t *testing.T) {
+	t.Parallel()
+
+	c := clock.Real()
+	start := c.Now()
+
+	require.NoError(t, c.Sleep(t.Context(), time.Millisecond))
+	assert.GreaterOrEqual(t, c.Since(start), time.Millisecond)
+
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.False(t, timer.Stop())
+
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+
+	fired := make(chan struct{})
+	c.AfterFunc(time.Millisecond, func() { close(fired) })
+	<-fired
+	<-c.After(time.Millisecond)
+}
+
+func TestRealSleepCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.ErrorIs(t, clock.Real().Sleep(ctx, time.Hour), context.Canceled)
+}
+
+func TestOrReal(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+
+	assert.Same(t, fake, clock.OrReal(fake))
+	assert.NotNil(t, clock.OrReal(nil))
+}
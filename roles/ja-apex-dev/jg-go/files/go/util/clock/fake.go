@@ -0,0 +1,304 @@
+package clock
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a manually driven clock. Time only moves through Advance and
+// SetTime, which fire the timers and tickers falling due in deadline order,
+// each with the clock set to its deadline. Timer channels are buffered like
+// the runtime ones and AfterFunc callbacks run in the goroutine moving the
+// clock, so their effects are visible once Advance returns. It is safe for
+// concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	// changed is closed and replaced whenever waiters changes.
+	changed chan struct{}
+}
+
+// waiter is a pending timer, ticker or AfterFunc callback.
+type waiter struct {
+	fake *Fake
+	when time.Time
+	// period is non-zero for tickers.
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+}
+
+// NewFake returns a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel receiving the fake time once d has passed.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock is advanced by d or ctx is done.
+func (f *Fake) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, f, d)
+}
+
+// NewTimer returns a timer firing once the clock is advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &waiter{fake: f, ch: make(chan time.Time, 1)}
+	w.start(d)
+
+	return (*fakeTimer)(w)
+}
+
+// NewTicker returns a ticker firing every d of fake time. It panics if d is
+// not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	w := &waiter{fake: f, period: d, ch: make(chan time.Time, 1)}
+	w.start(d)
+
+	return (*fakeTicker)(w)
+}
+
+// AfterFunc calls fn once the clock is advanced by d.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &waiter{fake: f, fn: fn}
+	w.start(d)
+
+	return (*fakeTimer)(w)
+}
+
+// Advance moves the clock forward by d, firing everything falling due.
+func (f *Fake) Advance(d time.Duration) {
+	f.advanceTo(f.Now().Add(d))
+}
+
+// SetTime moves the clock to t. Moving forward behaves as Advance, while
+// moving backward fires nothing and leaves pending deadlines untouched.
+func (f *Fake) SetTime(t time.Time) {
+	f.mu.Lock()
+
+	if !t.After(f.now) {
+		f.now = t
+		f.mu.Unlock()
+
+		return
+	}
+
+	f.mu.Unlock()
+	f.advanceTo(t)
+}
+
+// Waiters returns the number of pending timers, tickers and sleeps.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers, tickers or sleeps are pending,
+// so that a test advances the clock only once the code under test waits
+// on it. It returns the error of ctx if it is done first.
+func (f *Fake) BlockUntil(ctx context.Context, n int) error {
+	for {
+		f.mu.Lock()
+		pending, changed := len(f.waiters), f.changed
+		f.mu.Unlock()
+
+		if pending >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// advanceTo fires the waiters due by target one at a time, without holding
+// the lock so that callbacks may use the clock.
+func (f *Fake) advanceTo(target time.Time) {
+	for {
+		w, now, ok := f.pop(target)
+		if !ok {
+			return
+		}
+
+		w.fire(now)
+	}
+}
+
+// pop removes the earliest waiter due by target, rescheduling tickers, and
+// moves the clock to its deadline. Once nothing is due the clock is moved
+// to target.
+func (f *Fake) pop(target time.Time) (*waiter, time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var next *waiter
+
+	for _, w := range f.waiters {
+		if !w.when.After(target) && (next == nil || w.when.Before(next.when)) {
+			next = w
+		}
+	}
+
+	if next == nil {
+		if target.After(f.now) {
+			f.now = target
+		}
+
+		return nil, f.now, false
+	}
+
+	if next.when.After(f.now) {
+		f.now = next.when
+	}
+
+	f.remove(next)
+
+	if next.period > 0 {
+		f.schedule(next, next.period)
+	}
+
+	return next, f.now, true
+}
+
+// schedule adds w to fire after d. The caller holds the lock.
+func (f *Fake) schedule(w *waiter, d time.Duration) {
+	w.when = f.now.Add(d)
+	f.waiters = append(f.waiters, w)
+	f.notify()
+}
+
+// remove drops w and reports whether it was pending. The caller holds the
+// lock.
+func (f *Fake) remove(w *waiter) bool {
+	i := slices.Index(f.waiters, w)
+	if i < 0 {
+		return false
+	}
+
+	f.waiters = slices.Delete(f.waiters, i, i+1)
+	f.notify()
+
+	return true
+}
+
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// start schedules w after d and reports whether it was pending. A
+// non-positive d fires at once, as with runtime timers.
+func (w *waiter) start(d time.Duration) bool {
+	w.fake.mu.Lock()
+	active := w.fake.remove(w)
+	w.drain()
+	w.fake.schedule(w, d)
+	w.fake.mu.Unlock()
+
+	if d <= 0 {
+		w.fake.advanceTo(w.fake.Now())
+	}
+
+	return active
+}
+
+func (w *waiter) stop() bool {
+	w.fake.mu.Lock()
+	defer w.fake.mu.Unlock()
+
+	w.drain()
+
+	return w.fake.remove(w)
+}
+
+// drain discards an unreceived firing so that stopped and reset timers
+// never deliver stale values, as with runtime timers since Go 1.23.
+func (w *waiter) drain() {
+	select {
+	case <-w.ch:
+	default:
+	}
+}
+
+func (w *waiter) fire(now time.Time) {
+	if w.fn != nil {
+		w.fn()
+
+		return
+	}
+
+	// Like runtime tickers, drop ticks nobody received in time.
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+type fakeTimer waiter
+
+// C implements Timer.
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop implements Timer.
+func (t *fakeTimer) Stop() bool {
+	return (*waiter)(t).stop()
+}
+
+// Reset implements Timer.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return (*waiter)(t).start(d)
+}
+
+type fakeTicker waiter
+
+// C implements Ticker.
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop implements Ticker.
+func (t *fakeTicker) Stop() {
+	(*waiter)(t).stop()
+}
+
+// Reset implements Ticker.
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+
+	t.fake.mu.Lock()
+	t.period = d
+	t.fake.mu.Unlock()
+
+	(*waiter)(t).start(d)
+}
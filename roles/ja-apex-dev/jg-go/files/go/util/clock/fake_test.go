@@ -0,0 +1,155 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeNow(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	assert.Equal(t, epoch, fake.Now())
+
+	fake.Advance(time.Hour)
+	assert.Equal(t, epoch.Add(time.Hour), fake.Now())
+	assert.Equal(t, time.Hour, fake.Since(epoch))
+
+	fake.SetTime(epoch)
+	assert.Equal(t, epoch, fake.Now(), "the clock may move backward")
+}
+
+func TestFakeTimer(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	timer := fake.NewTimer(time.Minute)
+	assert.Equal(t, 1, fake.Waiters())
+
+	fake.Advance(59 * time.Second)
+	assert.Empty(t, timer.C())
+
+	fake.Advance(time.Second)
+	assert.Equal(t, epoch.Add(time.Minute), <-timer.C())
+	assert.Zero(t, fake.Waiters())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	fake.Advance(time.Hour)
+	assert.Empty(t, timer.C(), "stopped timers do not fire")
+
+	assert.Equal(t, epoch.Add(time.Hour+time.Minute), <-fake.After(0))
+}
+
+func TestFakeTimerResetDrains(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	timer := fake.NewTimer(time.Second)
+	fake.Advance(time.Second)
+
+	timer.Reset(time.Second)
+	assert.Empty(t, timer.C(), "the stale firing is discarded")
+
+	fake.Advance(time.Second)
+	assert.Equal(t, epoch.Add(2*time.Second), <-timer.C())
+}
+
+func TestFakeTicker(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	ticker := fake.NewTicker(time.Second)
+
+	for i := 1; i <= 3; i++ {
+		fake.Advance(time.Second)
+		assert.Equal(t, epoch.Add(time.Duration(i)*time.Second), <-ticker.C())
+	}
+
+	fake.Advance(5 * time.Second)
+	assert.Len(t, ticker.C(), 1, "missed ticks are dropped")
+	<-ticker.C()
+
+	ticker.Reset(time.Minute)
+	fake.Advance(time.Second)
+	assert.Empty(t, ticker.C())
+
+	ticker.Stop()
+	fake.Advance(time.Hour)
+	assert.Empty(t, ticker.C())
+	assert.Panics(t, func() { fake.NewTicker(0) })
+}
+
+func TestFakeAfterFunc(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+
+	var order []string
+
+	fake.AfterFunc(2*time.Second, func() {
+		order = append(order, "second")
+	})
+	fake.AfterFunc(time.Second, func() {
+		order = append(order, "first")
+		// Callbacks observe their own deadline and may schedule more.
+		assert.Equal(t, epoch.Add(time.Second), fake.Now())
+		fake.AfterFunc(time.Second/2, func() {
+			order = append(order, "nested")
+		})
+	})
+
+	fake.SetTime(epoch.Add(time.Hour))
+	assert.Equal(t, []string{"first", "nested", "second"}, order)
+	assert.Equal(t, epoch.Add(time.Hour), fake.Now())
+
+	stopped := fake.AfterFunc(time.Second, func() { t.Fail() })
+	assert.True(t, stopped.Stop())
+	assert.Nil(t, stopped.C())
+	fake.Advance(time.Second)
+}
+
+func TestFakeSleep(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	done := make(chan error)
+
+	go func() {
+		done <- fake.Sleep(t.Context(), time.Minute)
+	}()
+
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+	fake.Advance(time.Minute)
+	require.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		done <- fake.Sleep(ctx, time.Minute)
+	}()
+
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	assert.Zero(t, fake.Waiters())
+}
+
+func TestFakeBlockUntilCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	err := clock.NewFake(epoch).BlockUntil(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,251 @@
+// Package config loads settings into tagged structs from defaults, YAML or
+// JSON files, environment variables and command line flags.
+//
+// Sources are applied in increasing precedence: `default` tags, files in the
+// order given, environment variables and finally flags that were set
+// explicitly. Fields are described with the following tags:
+//
+//	config:"name"   key in files, defaults to the lowercase field name
+//	env:"NAME"      environment variable, prefixed by WithEnv
+//	flag:"name"     command line flag registered by WithFlags
+//	usage:"text"    flag usage message
+//	default:"text"  default value in the flag/env string syntax
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidTarget is returned when the destination is not a struct
+	// pointer.
+	ErrInvalidTarget = errors.New("config: target must be a struct pointer")
+	// ErrUnsupportedFormat is returned for files with unknown extension.
+	ErrUnsupportedFormat = errors.New("config: unsupported file format")
+	// ErrUnknownKey is returned when a file defines a key without a
+	// matching field.
+	ErrUnknownKey = errors.New("config: unknown key")
+	// ErrUnsupportedType is returned for fields that cannot be decoded.
+	ErrUnsupportedType = errors.New("config: unsupported field type")
+)
+
+// LookupFunc resolves an environment variable.
+type LookupFunc func(key string) (string, bool)
+
+// Option configures Load.
+type Option func(*loader)
+
+type file struct {
+	path     string
+	optional bool
+}
+
+type loader struct {
+	files  []file
+	prefix string
+	lookup LookupFunc
+	flags  *flag.FlagSet
+	args   []string
+}
+
+// WithFiles loads the files in order, failing if any of them is missing.
+// The format is selected by the extension: .yaml, .yml or .json.
+func WithFiles(paths ...string) Option {
+	return func(l *loader) {
+		for _, path := range paths {
+			l.files = append(l.files, file{path: path})
+		}
+	}
+}
+
+// WithOptionalFiles loads the files in order, skipping missing ones.
+func WithOptionalFiles(paths ...string) Option {
+	return func(l *loader) {
+		for _, path := range paths {
+			l.files = append(l.files, file{path: path, optional: true})
+		}
+	}
+}
+
+// WithEnv enables environment variables, prefixing every `env` tag with
+// prefix and an underscore unless prefix is empty.
+func WithEnv(prefix string) Option {
+	return func(l *loader) {
+		l.prefix = prefix
+		if l.lookup == nil {
+			l.lookup = os.LookupEnv
+		}
+	}
+}
+
+// WithLookup overrides the environment lookup, mostly useful in tests.
+func WithLookup(lookup LookupFunc) Option {
+	return func(l *loader) {
+		l.lookup = lookup
+	}
+}
+
+// WithFlags registers the `flag` tagged fields on the flag set and parses
+// args unless the set has already been parsed.
+func WithFlags(set *flag.FlagSet, args []string) Option {
+	return func(l *loader) {
+		l.flags = set
+		l.args = args
+	}
+}
+
+// Load populates dst, which must be a pointer to a struct.
+func Load(dst any, opts ...Option) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() ||
+		target.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	l := &loader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	root := target.Elem()
+
+	steps := []func(reflect.Value) error{
+		applyDefaults, l.applyFiles, l.applyEnv, l.applyFlags,
+	}
+	for _, step := range steps {
+		if err := step(root); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyDefaults(root reflect.Value) error {
+	return walkLeaves(root, "", func(f field) error {
+		value, ok := f.tag.Lookup("default")
+		if !ok {
+			return nil
+		}
+
+		return f.wrap(setString(f.value, value))
+	})
+}
+
+func (l *loader) applyFiles(root reflect.Value) error {
+	for _, f := range l.files {
+		raw, err := readFile(f.path)
+		if f.optional && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := decodeStruct(root, raw, ""); err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+func (l *loader) applyEnv(root reflect.Value) error {
+	if l.lookup == nil {
+		return nil
+	}
+
+	return walkLeaves(root, "", func(f field) error {
+		name := f.tag.Get("env")
+		if name == "" {
+			return nil
+		}
+
+		if l.prefix != "" {
+			name = l.prefix + "_" + name
+		}
+
+		value, ok := l.lookup(name)
+		if !ok {
+			return nil
+		}
+
+		return f.wrap(setString(f.value, value))
+	})
+}
+
+func (l *loader) applyFlags(root reflect.Value) error {
+	if l.flags == nil {
+		return nil
+	}
+
+	fields, err := l.bindFlags(root)
+	if err != nil {
+		return err
+	}
+
+	if !l.flags.Parsed() {
+		if err := l.flags.Parse(l.args); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+
+	l.flags.Visit(func(fl *flag.Flag) {
+		if f, ok := fields[fl.Name]; ok && err == nil {
+			err = f.wrap(setString(f.value, fl.Value.String()))
+		}
+	})
+
+	return err
+}
+
+// bindFlags registers missing flags and indexes the flag tagged fields.
+func (l *loader) bindFlags(root reflect.Value) (map[string]field, error) {
+	fields := map[string]field{}
+
+	err := walkLeaves(root, "", func(f field) error {
+		name := f.tag.Get("flag")
+		if name == "" {
+			return nil
+		}
+
+		fields[name] = f
+		if l.flags.Lookup(name) == nil {
+			registerFlag(l.flags, name, f)
+		}
+
+		return nil
+	})
+
+	return fields, err
+}
+
+func readFile(path string) (map[string]any, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	// JSON documents are valid YAML, so a single decoder covers both.
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,135 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/config"
+)
+
+type settings struct {
+	Addr    string        `config:"addr" env:"ADDR" flag:"addr" default:":8080"`
+	Timeout time.Duration `config:"timeout" env:"TIMEOUT" default:"5s"`
+	Debug   bool          `flag:"debug"`
+	Tags    []string      `config:"tags" env:"TAGS"`
+	DB      struct {
+		DSN   string `config:"dsn" env:"DB_DSN"`
+		Pool  int    `config:"pool" default:"4"`
+		Extra map[string]string
+	} `config:"db"`
+	Ignored string `config:"-"`
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func lookup(env map[string]string) config.LookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+
+		return value, ok
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Parallel()
+
+	var cfg settings
+	require.NoError(t, config.Load(&cfg))
+
+	assert.Equal(t, ":8080", cfg.Addr)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, 4, cfg.DB.Pool)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Parallel()
+
+	yamlPath := writeFile(t, "base.yaml", `
+addr: ":9000"
+timeout: 1m
+tags: [a, b]
+db:
+  dsn: postgres://yaml
+  pool: 8
+  extra:
+    mode: ro
+`)
+	jsonPath := writeFile(t, "override.json", `{"db": {"pool": 16}}`)
+
+	var cfg settings
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := config.Load(&cfg,
+		config.WithFiles(yamlPath, jsonPath),
+		config.WithEnv("APP"),
+		config.WithLookup(lookup(map[string]string{
+			"APP_ADDR":   ":7000",
+			"APP_TAGS":   "x, y",
+			"APP_DB_DSN": "postgres://env",
+		})),
+		config.WithFlags(fs, []string{"-addr", ":6000", "-debug"}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":6000", cfg.Addr)
+	assert.Equal(t, time.Minute, cfg.Timeout)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags)
+	assert.Equal(t, "postgres://env", cfg.DB.DSN)
+	assert.Equal(t, 16, cfg.DB.Pool)
+	assert.Equal(t, map[string]string{"mode": "ro"}, cfg.DB.Extra)
+}
+
+func TestLoadFlagDefaultsDoNotOverride(t *testing.T) {
+	t.Parallel()
+
+	var cfg settings
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := config.Load(&cfg,
+		config.WithLookup(lookup(map[string]string{"ADDR": ":7000"})),
+		config.WithFlags(fs, nil),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":7000", cfg.Addr)
+	assert.Equal(t, ":8080", fs.Lookup("addr").DefValue)
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	var cfg settings
+
+	require.ErrorIs(t, config.Load(cfg), config.ErrInvalidTarget)
+
+	unknown := writeFile(t, "bad.yml", "db: {dns: typo}")
+	require.ErrorIs(t,
+		config.Load(&cfg, config.WithFiles(unknown)), config.ErrUnknownKey)
+
+	toml := writeFile(t, "app.toml", "")
+	require.ErrorIs(t,
+		config.Load(&cfg, config.WithFiles(toml)), config.ErrUnsupportedFormat)
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	require.Error(t, config.Load(&cfg, config.WithFiles(missing)))
+	require.NoError(t, config.Load(&cfg, config.WithOptionalFiles(missing)))
+
+	err := config.Load(&cfg, config.WithLookup(lookup(map[string]string{
+		"TIMEOUT": "soon",
+	})))
+	require.ErrorContains(t, err, "timeout")
+}
@@ -0,0 +1,360 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType    = reflect.TypeFor[time.Duration]()
+	timeType        = reflect.TypeFor[time.Time]()
+	unmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// field is a settable struct field with its dotted path.
+type field struct {
+	value reflect.Value
+	tag   reflect.StructTag
+	path  string
+}
+
+// wrap annotates err with the field path.
+func (f field) wrap(err error) error {
+	return wrapPath(f.path, err)
+}
+
+// rawValue is a flag.Value that keeps the textual flag argument.
+type rawValue struct {
+	value  string
+	isBool bool
+}
+
+// String implements flag.Value.
+func (r *rawValue) String() string {
+	if r == nil {
+		return ""
+	}
+
+	return r.value
+}
+
+// Set implements flag.Value.
+func (r *rawValue) Set(value string) error {
+	r.value = value
+
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be set without an argument.
+func (r *rawValue) IsBoolFlag() bool {
+	return r.isBool
+}
+
+// registerFlag defines a textual flag for the field on the set.
+func registerFlag(set *flag.FlagSet, name string, f field) {
+	value := &rawValue{
+		value:  f.tag.Get("default"),
+		isBool: f.value.Kind() == reflect.Bool,
+	}
+	set.Var(value, name, f.tag.Get("usage"))
+}
+
+// key returns the file key of a struct field, or false if it is skipped.
+func key(sf reflect.StructField) (string, bool) {
+	if !sf.IsExported() {
+		return "", false
+	}
+
+	name := sf.Tag.Get("config")
+	if name == "-" {
+		return "", false
+	}
+
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+
+	return name, true
+}
+
+// isNested reports whether the value is a struct decoded field by field.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType &&
+		!reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+// join builds a dotted field path.
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+// walkLeaves calls fn for every non-nested field, depth first.
+func walkLeaves(v reflect.Value, prefix string, fn func(field) error) error {
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+
+		name, ok := key(sf)
+		if !ok {
+			continue
+		}
+
+		path := join(prefix, name)
+		if isNested(sf.Type) {
+			if err := walkLeaves(v.Field(i), path, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		leaf := field{value: v.Field(i), tag: sf.Tag, path: path}
+		if err := fn(leaf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeStruct assigns the raw map produced by the YAML decoder to v.
+func decodeStruct(v reflect.Value, raw map[string]any, prefix string) error {
+	fields := map[string]int{}
+
+	for i := range v.NumField() {
+		if name, ok := key(v.Type().Field(i)); ok {
+			fields[name] = i
+		}
+	}
+
+	for name, item := range raw {
+		path := join(prefix, name)
+
+		index, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownKey, path)
+		}
+
+		if err := decodeValue(v.Field(index), item, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeValue assigns a decoded YAML node, allocating pointers as needed.
+func decodeValue(v reflect.Value, raw any, path string) error {
+	if raw == nil {
+		v.SetZero()
+
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return decodeValue(v.Elem(), raw, path)
+	}
+
+	switch typed := raw.(type) {
+	case map[string]any:
+		return decodeMap(v, typed, path)
+	case []any:
+		return decodeSlice(v, typed, path)
+	case time.Time:
+		return wrapPath(path, setString(v, typed.Format(time.RFC3339Nano)))
+	default:
+		return wrapPath(path, setString(v, fmt.Sprint(typed)))
+	}
+}
+
+// decodeMap assigns a YAML mapping to a nested struct or string-keyed map.
+func decodeMap(v reflect.Value, raw map[string]any, path string) error {
+	if isNested(v.Type()) {
+		return decodeStruct(v, raw, path)
+	}
+
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return wrapPath(path, unsupported(v))
+	}
+
+	result := reflect.MakeMapWithSize(v.Type(), len(raw))
+
+	for name, item := range raw {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(elem, item, join(path, name)); err != nil {
+			return err
+		}
+
+		mapKey := reflect.ValueOf(name).Convert(v.Type().Key())
+		result.SetMapIndex(mapKey, elem)
+	}
+
+	v.Set(result)
+
+	return nil
+}
+
+// decodeSlice assigns a YAML sequence to a slice.
+func decodeSlice(v reflect.Value, raw []any, path string) error {
+	if v.Kind() != reflect.Slice {
+		return wrapPath(path, unsupported(v))
+	}
+
+	result := reflect.MakeSlice(v.Type(), len(raw), len(raw))
+
+	for i, item := range raw {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		if err := decodeValue(result.Index(i), item, elemPath); err != nil {
+			return err
+		}
+	}
+
+	v.Set(result)
+
+	return nil
+}
+
+func unsupported(v reflect.Value) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+}
+
+func wrapPath(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("config: %s: %w", path, err)
+}
+
+// setString assigns the textual representation used by defaults, env and
+// flags. Slices are comma separated.
+func setString(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	if v.Type() == durationType {
+		return setDuration(v, s)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+		return nil
+	case reflect.Bool:
+		return setBool(v, s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt(v, s)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return setUint(v, s)
+	case reflect.Float32, reflect.Float64:
+		return setFloat(v, s)
+	case reflect.Slice:
+		return setSlice(v, s)
+	case reflect.Pointer:
+		return setPointer(v, s)
+	default:
+		return unsupported(v)
+	}
+}
+
+// setDuration parses time.ParseDuration syntax.
+func setDuration(v reflect.Value, s string) error {
+	d, err := time.ParseDuration(s)
+	if err == nil {
+		v.SetInt(int64(d))
+	}
+
+	return err
+}
+
+// setBool parses strconv.ParseBool syntax.
+func setBool(v reflect.Value, s string) error {
+	b, err := strconv.ParseBool(s)
+	if err == nil {
+		v.SetBool(b)
+	}
+
+	return err
+}
+
+// setInt parses signed integers with base prefixes.
+func setInt(v reflect.Value, s string) error {
+	n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+	if err == nil {
+		v.SetInt(n)
+	}
+
+	return err
+}
+
+// setUint parses unsigned integers with base prefixes.
+func setUint(v reflect.Value, s string) error {
+	n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+	if err == nil {
+		v.SetUint(n)
+	}
+
+	return err
+}
+
+// setFloat parses floating point numbers.
+func setFloat(v reflect.Value, s string) error {
+	n, err := strconv.ParseFloat(s, v.Type().Bits())
+	if err == nil {
+		v.SetFloat(n)
+	}
+
+	return err
+}
+
+// setPointer allocates a new value and assigns s to it.
+func setPointer(v reflect.Value, s string) error {
+	elem := reflect.New(v.Type().Elem())
+	if err := setString(elem.Elem(), s); err != nil {
+		return err
+	}
+
+	v.Set(elem)
+
+	return nil
+}
+
+// setSlice splits s by commas and assigns every trimmed part.
+func setSlice(v reflect.Value, s string) error {
+	if s == "" {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	result := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if err := setString(result.Index(i), part); err != nil {
+			return err
+		}
+	}
+
+	v.Set(result)
+
+	return nil
+}
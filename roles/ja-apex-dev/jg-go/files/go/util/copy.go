@@ -0,0 +1,260 @@
+package util
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// UnexportedStrategy selects what DeepCopy does with unexported fields,
+// which reflection cannot traverse.
+type UnexportedStrategy int
+
+// Unexported field strategies.
+const (
+	// UnexportedShare copies unexported fields as an assignment would:
+	// the references they hold are shared with the original.
+	UnexportedShare UnexportedStrategy = iota
+	// UnexportedZero leaves unexported fields zero in the copy.
+	UnexportedZero
+)
+
+// CopyOption configures DeepCopy.
+type CopyOption func(*copySettings)
+
+// copyFunc copies a value of the type it is registered for.
+type copyFunc func(reflect.Value) reflect.Value
+
+type copySettings struct {
+	unexported UnexportedStrategy
+	copiers    map[reflect.Type]copyFunc
+}
+
+// WithUnexported sets the strategy for unexported fields, UnexportedShare
+// by default.
+func WithUnexported(strategy UnexportedStrategy) CopyOption {
+	return func(s *copySettings) {
+		s.unexported = strategy
+	}
+}
+
+// WithCopier copies the values of type T with fn for this call, taking
+// precedence over RegisterCopier.
+func WithCopier[T any](fn func(T) T) CopyOption {
+	return func(s *copySettings) {
+		if s.copiers == nil {
+			s.copiers = make(map[reflect.Type]copyFunc)
+		}
+
+		s.copiers[reflect.TypeFor[T]()] = typedCopier(fn)
+	}
+}
+
+// copiers holds the copyFunc of the types given to RegisterCopier.
+var copiers sync.Map
+
+// builtinCopiers share the values of standard types that are immutable
+// but built from unexported fields.
+var builtinCopiers = map[reflect.Type]copyFunc{
+	reflect.TypeFor[time.Time]():      identity,
+	reflect.TypeFor[*time.Location](): identity,
+}
+
+// RegisterCopier makes DeepCopy copy the values of type T with fn, for
+// types whose invariants live in unexported fields or that must not be
+// duplicated, such as handles. Types are matched exactly: registering T
+// does not cover *T.
+func RegisterCopier[T any](fn func(T) T) {
+	copiers.Store(reflect.TypeFor[T](), typedCopier(fn))
+}
+
+func typedCopier[T any](fn func(T) T) copyFunc {
+	return func(value reflect.Value) reflect.Value {
+		v, _ := value.Interface().(T)
+		copied := fn(v)
+
+		return reflect.ValueOf(&copied).Elem()
+	}
+}
+
+func identity(value reflect.Value) reflect.Value {
+	return value
+}
+
+// DeepCopy returns a copy of v sharing no memory with it, so that either
+// can be changed without affecting the other. Maps, slices, arrays,
+// pointers, interfaces and the exported fields of structs are copied
+// recursively; map keys, functions and channels are shared. Pointers,
+// maps and slices referenced several times, cycles included, are copied
+// once and referenced the same way in the copy.
+func DeepCopy[T any](v T, opts ...CopyOption) T {
+	c := copier{seen: make(map[visit]reflect.Value)}
+	for _, opt := range opts {
+		opt(&c.copySettings)
+	}
+
+	var copied T
+
+	reflect.ValueOf(&copied).Elem().Set(c.copy(reflect.ValueOf(&v).Elem()))
+
+	return copied
+}
+
+type copier struct {
+	copySettings
+
+	seen map[visit]reflect.Value
+}
+
+func (c *copier) copy(value reflect.Value) reflect.Value {
+	if fn := c.lookup(value.Type()); fn != nil {
+		return fn(value)
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		return c.copyPointer(value)
+	case reflect.Interface:
+		return c.copyInterface(value)
+	case reflect.Struct:
+		return c.copyStruct(value)
+	case reflect.Map:
+		return c.copyMap(value)
+	case reflect.Slice:
+		return c.copySlice(value)
+	case reflect.Array:
+		return c.copyArray(value)
+	default:
+		return value
+	}
+}
+
+func (c *copier) lookup(t reflect.Type) copyFunc {
+	if fn, ok := c.copiers[t]; ok {
+		return fn
+	}
+
+	if registered, ok := copiers.Load(t); ok {
+		fn, _ := registered.(copyFunc)
+
+		return fn
+	}
+
+	return builtinCopiers[t]
+}
+
+// remember returns the copy of the pointer, map or slice value when
+// already made, or records copied as its copy.
+func (c *copier) remember(value, copied reflect.Value) (reflect.Value, bool) {
+	key := visit{ptr: value.Pointer(), typ: value.Type()}
+	if value.Kind() == reflect.Slice {
+		key.len = value.Len()
+	}
+
+	if seen, ok := c.seen[key]; ok {
+		return seen, true
+	}
+
+	c.seen[key] = copied
+
+	return copied, false
+}
+
+func (c *copier) copyPointer(value reflect.Value) reflect.Value {
+	if value.IsNil() {
+		return value
+	}
+
+	copied, seen := c.remember(value, reflect.New(value.Type().Elem()))
+	if !seen {
+		copied.Elem().Set(c.copy(value.Elem()))
+	}
+
+	return copied
+}
+
+func (c *copier) copyInterface(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	if !value.IsNil() {
+		copied.Set(c.copy(value.Elem()))
+	}
+
+	return copied
+}
+
+func (c *copier) copyStruct(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	if c.unexported == UnexportedShare {
+		copied.Set(value)
+	}
+
+	for i := range value.NumField() {
+		if value.Type().Field(i).IsExported() {
+			copied.Field(i).Set(c.copy(value.Field(i)))
+		}
+	}
+
+	return copied
+}
+
+func (c *copier) copyMap(value reflect.Value) reflect.Value {
+	if value.IsNil() {
+		return value
+	}
+
+	copied, seen := c.remember(value,
+		reflect.MakeMapWithSize(value.Type(), value.Len()))
+	if seen {
+		return copied
+	}
+
+	for iter := value.MapRange(); iter.Next(); {
+		copied.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+	}
+
+	return copied
+}
+
+func (c *copier) copySlice(value reflect.Value) reflect.Value {
+	if value.IsNil() {
+		return value
+	}
+
+	copied, seen := c.remember(value,
+		reflect.MakeSlice(value.Type(), value.Len(), value.Len()))
+	if seen {
+		return copied
+	}
+
+	c.copyElems(copied, value)
+
+	return copied
+}
+
+func (c *copier) copyArray(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	c.copyElems(copied, value)
+
+	return copied
+}
+
+// copyElems copies the elements of the slice or array value into dst,
+// all at once when they hold no references.
+func (c *copier) copyElems(dst, value reflect.Value) {
+	elem := value.Type().Elem()
+	if isPlain(elem.Kind()) && c.lookup(elem) == nil {
+		reflect.Copy(dst, value)
+
+		return
+	}
+
+	for i := range value.Len() {
+		dst.Index(i).Set(c.copy(value.Index(i)))
+	}
+}
+
+// isPlain reports whether values of kind hold no references.
+func isPlain(kind reflect.Kind) bool {
+	return kind >= reflect.Bool && kind <= reflect.Complex128 ||
+		kind == reflect.String
+}
@@ -0,0 +1,147 @@
+package util_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/quick"
+)
+
+type layer struct {
+	Name     string
+	Values   map[string]any
+	Includes []*layer
+	Loaded   time.Time
+	Checksum [4]byte
+	Parent   *layer
+	Hook     func() string
+	cache    map[string]string
+}
+
+func TestDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	loaded := time.Date(2024, 1, 10, 10, 0, 0, 0, time.Local)
+	base := &layer{Name: "base", Values: map[string]any{"port": 80}}
+	original := &layer{
+		Name:     "prod",
+		Values:   map[string]any{"tags": []any{"a"}, "db": map[string]any{}},
+		Includes: []*layer{base, base},
+		Loaded:   loaded,
+		Checksum: [4]byte{1, 2, 3, 4},
+		Hook:     func() string { return "hook" },
+		cache:    map[string]string{"k": "v"},
+	}
+	original.Parent = original
+
+	copied := util.DeepCopy(original)
+	require.NotSame(t, original, copied)
+	assert.Equal(t, original.Values, copied.Values)
+	assert.Equal(t, loaded, copied.Loaded)
+	assert.Same(t, loaded.Location(), copied.Loaded.Location())
+	assert.Equal(t, "hook", copied.Hook())
+	assert.Same(t, copied, copied.Parent, "cycles are preserved")
+	assert.Same(t, copied.Includes[0], copied.Includes[1],
+		"shared pointers stay shared")
+	assert.NotSame(t, base, copied.Includes[0])
+	assert.Equal(t, map[string]string{"k": "v"}, copied.cache)
+
+	copied.Values["tags"].([]any)[0] = "changed"
+	copied.Values["db"].(map[string]any)["host"] = "changed"
+	copied.Includes[0].Values["port"] = 8080
+	copied.Checksum[0] = 9
+
+	assert.Equal(t, []any{"a"}, original.Values["tags"])
+	assert.Empty(t, original.Values["db"])
+	assert.Equal(t, 80, base.Values["port"])
+	assert.Equal(t, byte(1), original.Checksum[0])
+}
+
+func TestDeepCopyUnexported(t *testing.T) {
+	t.Parallel()
+
+	original := layer{Name: "prod", cache: map[string]string{"k": "v"}}
+
+	shared := util.DeepCopy(original)
+	shared.cache["k"] = "changed"
+	assert.Equal(t, "changed", original.cache["k"])
+
+	zeroed := util.DeepCopy(original, util.WithUnexported(util.UnexportedZero))
+	assert.Equal(t, "prod", zeroed.Name)
+	assert.Nil(t, zeroed.cache)
+}
+
+func TestDeepCopyValues(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	assert.NoError(t, util.DeepCopy(err))
+	assert.Nil(t, util.DeepCopy[map[string]int](nil))
+	assert.Equal(t, 42, util.DeepCopy(42))
+	assert.Equal(t, []string{"a", "b"}, util.DeepCopy([]string{"a", "b"}))
+
+	list := []any{nil}
+	list[0] = list
+	copied := util.DeepCopy(list)
+	copied[0].([]any)[0] = "changed"
+	assert.Equal(t, "changed", copied[0])
+	assert.IsType(t, []any{}, list[0])
+}
+
+type handle struct {
+	mu   *sync.Mutex
+	Name string
+}
+
+func TestDeepCopyCopiers(t *testing.T) {
+	t.Parallel()
+
+	util.RegisterCopier(func(h *handle) *handle { return h })
+
+	h := &handle{mu: &sync.Mutex{}, Name: "conn"}
+	copied := util.DeepCopy(map[string]*handle{"db": h})
+	assert.Same(t, h, copied["db"], "registered copiers are used")
+
+	renamed := util.DeepCopy([]*handle{h}, util.WithCopier(
+		func(h *handle) *handle { return &handle{Name: h.Name + "-copy"} }))
+	assert.Equal(t, "conn-copy", renamed[0].Name)
+}
+
+// scramble overwrites every map value and list item of a tree in place.
+func scramble(tree any) {
+	switch tree := tree.(type) {
+	case map[string]any:
+		for key, item := range tree {
+			scramble(item)
+			tree[key] = "changed"
+		}
+	case []any:
+		for i, item := range tree {
+			scramble(item)
+			tree[i] = "changed"
+		}
+	}
+}
+
+func TestDeepCopyProperty(t *testing.T) {
+	t.Parallel()
+
+	quick.Check(t, quick.NestedMaps(3), func(t *quick.T, m map[string]any) {
+		want, err := util.CanonicalString(m)
+		require.NoError(t, err)
+
+		copied := util.DeepCopy(m)
+		assert.Equal(t, m, copied)
+
+		scramble(copied)
+
+		got, err := util.CanonicalString(m)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "the original is left untouched")
+	})
+}
@@ -0,0 +1,146 @@
+package csvx
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types handled before falling back to basic kinds.
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// parseCell stores raw into the addressable value v. Text unmarshalers
+// see the raw cell, strings keep their spaces and other kinds are trimmed,
+// empty cells producing zero values.
+func parseCell(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Pointer {
+		if raw == "" {
+			v.SetZero()
+
+			return nil
+		}
+
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		unmarshaler, _ := v.Addr().Interface().(encoding.TextUnmarshaler)
+
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	if v.Kind() == reflect.String {
+		v.SetString(raw)
+
+		return nil
+	}
+
+	if raw = strings.TrimSpace(raw); raw == "" {
+		v.SetZero()
+
+		return nil
+	}
+
+	// Durations are int64 kinds, so detect them before parseKind.
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		v.SetInt(int64(d))
+
+		return err
+	}
+
+	return parseKind(v, raw)
+}
+
+// parseKind converts trimmed, non-empty raw for basic kinds.
+func parseKind(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+
+	return nil
+}
+
+// formatCell renders v, the inverse of parseCell. Nil pointers become
+// empty cells.
+func formatCell(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		marshaler, _ := v.Interface().(encoding.TextMarshaler)
+		text, err := marshaler.MarshalText()
+
+		return string(text), err
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	return formatKind(v)
+}
+
+// formatKind renders basic kinds with strconv.
+func formatKind(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+}
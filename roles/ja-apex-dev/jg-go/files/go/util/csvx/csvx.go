@@ -0,0 +1,175 @@
+// Package csvx reads CSV and TSV rows into tagged structs and writes struct
+// slices back. Columns are matched to fields by header name:
+//
+//	type User struct {
+//		Name  string        `csv:"name,required"`
+//		Age   int           `csv:"age"`
+//		Seen  time.Time     `csv:"last_seen"`
+//		Quota time.Duration `csv:"quota"`
+//		Notes string        `csv:"-"`
+//	}
+//
+// Untagged fields use their Go name. Header matching ignores case and
+// surrounding spaces, and unknown columns are skipped. Fields of embedded
+// structs are promoted. Values are converted from strings, booleans,
+// numbers, time.Duration and encoding.TextUnmarshaler implementations such
+// as time.Time; empty cells leave pointer fields nil.
+package csvx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagName is the struct tag naming the column of a field.
+const TagName = "csv"
+
+var (
+	// ErrNotStruct is returned when the row type is not a struct.
+	ErrNotStruct = errors.New("csvx: row type must be a struct")
+	// ErrMissingColumn is returned when the header lacks a required column.
+	ErrMissingColumn = errors.New("csvx: missing required column")
+	// ErrRequired is reported for empty cells of required columns.
+	ErrRequired = errors.New("csvx: value is required")
+	// ErrUnsupportedType is returned for fields without a conversion.
+	ErrUnsupportedType = errors.New("csvx: unsupported field type")
+)
+
+// RowError locates a conversion failure.
+type RowError struct {
+	// Line is the 1-based line number in the input.
+	Line int
+	// Column is the header name of the failing cell, empty for malformed
+	// lines.
+	Column string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("csvx: line %d: %v", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("csvx: line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures readers and writers.
+type Option func(*settings)
+
+type settings struct {
+	comma   rune
+	comment rune
+}
+
+// WithComma sets the field delimiter, ',' by default.
+func WithComma(comma rune) Option {
+	return func(s *settings) {
+		s.comma = comma
+	}
+}
+
+// TSV uses tabs as the field delimiter.
+func TSV() Option {
+	return WithComma('\t')
+}
+
+// WithComment skips input lines starting with comment.
+func WithComment(comment rune) Option {
+	return func(s *settings) {
+		s.comment = comment
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{comma: ','}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+func (s settings) reader(r *csv.Reader) *csv.Reader {
+	r.Comma = s.comma
+	r.Comment = s.comment
+	r.ReuseRecord = true
+	// Short and long rows are handled per row rather than failing the
+	// whole input.
+	r.FieldsPerRecord = -1
+
+	return r
+}
+
+// field maps a column to a possibly promoted struct field.
+type field struct {
+	name     string
+	index    []int
+	required bool
+}
+
+// fieldsOf lists the columns of struct type t in declaration order.
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w, got %s", ErrNotStruct, t)
+	}
+
+	var fields []field
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get(TagName)
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		if !sf.Anonymous || sf.Type.Kind() != reflect.Struct || tag != "" {
+			fields = append(fields, tagged(i, sf.Name, tag))
+
+			continue
+		}
+
+		nested, err := promoted(i, sf.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, nested...)
+	}
+
+	return fields, nil
+}
+
+// tagged builds the field at index i from its tag.
+func tagged(i int, goName, tag string) field {
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = goName
+	}
+
+	return field{name: name, index: []int{i}, required: options == "required"}
+}
+
+// promoted lists the fields of the embedded struct t at index i.
+func promoted(i int, t reflect.Type) ([]field, error) {
+	fields, err := fieldsOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	for j := range fields {
+		fields[j].index = append([]int{i}, fields[j].index...)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,173 @@
+package csvx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+)
+
+// Decoder reads rows of type T from CSV input with a header line.
+type Decoder[T any] struct {
+	reader *csv.Reader
+	// columns holds the field of every input column, nil when unmapped.
+	columns []*field
+	names   []string
+}
+
+// NewDecoder reads the header of r and maps its columns to the fields of
+// T. It fails with ErrMissingColumn when a required column is absent.
+func NewDecoder[T any](r io.Reader, opts ...Option) (*Decoder[T], error) {
+	fields, err := fieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	reader := newSettings(opts).reader(csv.NewReader(r))
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csvx: read header: %w", err)
+	}
+
+	d := &Decoder[T]{
+		reader:  reader,
+		columns: make([]*field, len(header)),
+		names:   make([]string, len(header)),
+	}
+
+	if err := d.mapColumns(header, fields); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// mapColumns matches header names to fields, checking required ones.
+func (d *Decoder[T]) mapColumns(header []string, fields []field) error {
+	for i, name := range header {
+		d.names[i] = strings.TrimSpace(name)
+
+		for j := range fields {
+			if strings.EqualFold(fields[j].name, d.names[i]) {
+				d.columns[i] = &fields[j]
+			}
+		}
+	}
+
+	for j := range fields {
+		if fields[j].required && !d.mapped(&fields[j]) {
+			return fmt.Errorf("%w: %s", ErrMissingColumn, fields[j].name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder[T]) mapped(f *field) bool {
+	for _, column := range d.columns {
+		if column == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Decode returns the next row. It returns io.EOF after the last row and a
+// *RowError when a cell cannot be converted; decoding may continue after
+// a RowError.
+func (d *Decoder[T]) Decode() (T, error) {
+	var row T
+
+	record, err := d.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return row, io.EOF
+		case errors.As(err, &parseErr):
+			return row, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		default:
+			return row, fmt.Errorf("csvx: %w", err)
+		}
+	}
+
+	line, _ := d.reader.FieldPos(0)
+	value := reflect.ValueOf(&row).Elem()
+
+	for i, column := range d.columns {
+		if column == nil {
+			continue
+		}
+
+		var cell string
+		if i < len(record) {
+			cell = record[i]
+		}
+
+		err := d.parse(value.FieldByIndex(column.index), column, cell)
+		if err != nil {
+			return row, &RowError{Line: line, Column: d.names[i], Err: err}
+		}
+	}
+
+	return row, nil
+}
+
+func (d *Decoder[T]) parse(v reflect.Value, column *field, cell string) error {
+	if column.required && strings.TrimSpace(cell) == "" {
+		return ErrRequired
+	}
+
+	return parseCell(v, cell)
+}
+
+// All iterates over the remaining rows with their errors, stopping after
+// the first error that is not a *RowError.
+func (d *Decoder[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			row, err := d.Decode()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			var rowErr *RowError
+			if !yield(row, err) || (err != nil && !errors.As(err, &rowErr)) {
+				return
+			}
+		}
+	}
+}
+
+// ReadAll decodes every row of r. Rows failing conversion are skipped and
+// their *RowError values joined into the returned error, so callers get
+// both the valid rows and a report of every invalid one.
+func ReadAll[T any](r io.Reader, opts ...Option) ([]T, error) {
+	decoder, err := NewDecoder[T](r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		rows []T
+		errs []error
+	)
+
+	for row, err := range decoder.All() {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, errors.Join(errs...)
+}
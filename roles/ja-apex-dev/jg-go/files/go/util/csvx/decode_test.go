@@ -0,0 +1,124 @@
+package csvx_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/csvx"
+)
+
+type Audit struct {
+	Seen time.Time `csv:"last_seen"`
+}
+
+type user struct {
+	Audit
+
+	Name   string        `csv:"name,required"`
+	Age    int           `csv:"age"`
+	Admin  bool          `csv:"admin"`
+	Quota  time.Duration `csv:"quota"`
+	Score  *float64      `csv:"score"`
+	Plain  uint8
+	Ignore string `csv:"-"`
+}
+
+const users = `name, AGE ,admin,quota,score,last_seen,Plain,extra
+alice,30,true,1h,9.5,2024-01-02T03:04:05Z,7,x
+bob,,false,,,2024-01-02T03:04:05Z,,y
+`
+
+func TestReadAll(t *testing.T) {
+	t.Parallel()
+
+	rows, err := csvx.ReadAll[user](strings.NewReader(users))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	score := 9.5
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, user{
+		Audit: Audit{Seen: seen},
+		Name:  "alice", Age: 30, Admin: true, Quota: time.Hour,
+		Score: &score, Plain: 7,
+	}, rows[0])
+	assert.Equal(t, user{Audit: Audit{Seen: seen}, Name: "bob"}, rows[1])
+}
+
+func TestReadAllRowErrors(t *testing.T) {
+	t.Parallel()
+
+	input := "name,age\nalice,30\n,40\ncarol,old\ndave,1,extra\n" +
+		"erin\n\"bad\"quote,1\nfrank,300\n"
+
+	rows, err := csvx.ReadAll[struct {
+		Name string `csv:"name,required"`
+		Age  int8   `csv:"age"`
+	}](strings.NewReader(input))
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+
+	assert.Equal(t, []string{"alice", "dave", "erin"}, names)
+
+	var (
+		joined interface{ Unwrap() []error }
+		lines  []int
+	)
+
+	require.ErrorAs(t, err, &joined)
+
+	for _, e := range joined.Unwrap() {
+		var rowErr *csvx.RowError
+		require.ErrorAs(t, e, &rowErr)
+
+		lines = append(lines, rowErr.Line)
+	}
+
+	assert.Equal(t, []int{3, 4, 7, 8}, lines)
+	require.ErrorIs(t, err, csvx.ErrRequired)
+	assert.ErrorContains(t, err, `csvx: line 4, column "age": strconv.`)
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	decoder, err := csvx.NewDecoder[user](
+		strings.NewReader("# export\nname\tage\ncarol\t41\n"),
+		csvx.TSV(), csvx.WithComment('#'))
+	require.NoError(t, err)
+
+	row, err := decoder.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "carol", row.Name)
+	assert.Equal(t, 41, row.Age)
+
+	_, err = decoder.Decode()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := csvx.NewDecoder[user](strings.NewReader("age\n1\n"))
+	require.ErrorIs(t, err, csvx.ErrMissingColumn)
+
+	_, err = csvx.NewDecoder[string](strings.NewReader("a\n"))
+	require.ErrorIs(t, err, csvx.ErrNotStruct)
+
+	_, err = csvx.NewDecoder[user](strings.NewReader(""))
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = csvx.ReadAll[struct {
+		Items []string `csv:"items"`
+	}](strings.NewReader("items\na\n"))
+	require.ErrorIs(t, err, csvx.ErrUnsupportedType)
+}
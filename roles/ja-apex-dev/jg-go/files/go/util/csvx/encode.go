@@ -0,0 +1,116 @@
+package csvx
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// Encoder writes rows of type T as CSV, preceded by a header line.
+type Encoder[T any] struct {
+	writer *csv.Writer
+	fields []field
+	record []string
+	header bool
+}
+
+// NewEncoder returns an encoder writing to w.
+func NewEncoder[T any](w io.Writer, opts ...Option) (*Encoder[T], error) {
+	fields, err := fieldsOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = newSettings(opts).comma
+
+	return &Encoder[T]{
+		writer: writer,
+		fields: fields,
+		record: make([]string, len(fields)),
+	}, nil
+}
+
+// Encode writes row, writing the header first if needed. Output is
+// buffered until Flush.
+func (e *Encoder[T]) Encode(row T) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(row)
+
+	for i, f := range e.fields {
+		cell, err := formatCell(value.FieldByIndex(f.index))
+		if err != nil {
+			return fmt.Errorf("csvx: column %q: %w", f.name, err)
+		}
+
+		e.record[i] = cell
+	}
+
+	if err := e.writer.Write(e.record); err != nil {
+		return fmt.Errorf("csvx: %w", err)
+	}
+
+	return nil
+}
+
+// Flush writes buffered rows, including the header when no row was
+// encoded.
+func (e *Encoder[T]) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.writer.Flush()
+
+	if err := e.writer.Error(); err != nil {
+		return fmt.Errorf("csvx: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Encoder[T]) writeHeader() error {
+	if e.header {
+		return nil
+	}
+
+	e.header = true
+
+	for i, f := range e.fields {
+		e.record[i] = f.name
+	}
+
+	if err := e.writer.Write(e.record); err != nil {
+		return fmt.Errorf("csvx: %w", err)
+	}
+
+	return nil
+}
+
+// WriteAll streams rows to w as CSV with a header line.
+func WriteAll[T any](w io.Writer, rows []T, opts ...Option) error {
+	return WriteSeq(w, slices.Values(rows), opts...)
+}
+
+// WriteSeq streams the rows of seq to w as CSV with a header line, for
+// sources too large to hold in memory.
+func WriteSeq[T any](w io.Writer, seq iter.Seq[T], opts ...Option) error {
+	encoder, err := NewEncoder[T](w, opts...)
+	if err != nil {
+		return err
+	}
+
+	for row := range seq {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	return encoder.Flush()
+}
@@ -0,0 +1,72 @@
+package csvx_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/csvx"
+)
+
+func TestWriteAll(t *testing.T) {
+	t.Parallel()
+
+	score := 1.25
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []user{
+		{
+			Audit: Audit{Seen: seen},
+			Name:  "alice, jr", Age: 30, Admin: true, Quota: time.Hour,
+			Score: &score, Plain: 7, Ignore: "skipped",
+		},
+		{Name: "bob"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, csvx.WriteAll(&buf, rows))
+	assert.Equal(t,
+		"last_seen,name,age,admin,quota,score,Plain\n"+
+			"2024-01-02T03:04:05Z,\"alice, jr\",30,true,1h0m0s,1.25,7\n"+
+			"0001-01-01T00:00:00Z,bob,0,false,0s,,0\n",
+		buf.String())
+
+	decoded, err := csvx.ReadAll[user](&buf)
+	require.NoError(t, err)
+
+	rows[0].Ignore = ""
+	rows[1].Seen = time.Time{}
+	assert.Equal(t, rows, decoded)
+}
+
+func TestWriteSeq(t *testing.T) {
+	t.Parallel()
+
+	type pair struct {
+		Key   string `csv:"key"`
+		Value int    `csv:"value"`
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, csvx.WriteSeq(&buf,
+		slices.Values([]pair{{"a", 1}, {"b", 2}}), csvx.TSV()))
+	assert.Equal(t, "key\tvalue\na\t1\nb\t2\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, csvx.WriteAll(&buf, []pair(nil)))
+	assert.Equal(t, "key,value\n", buf.String(), "header only")
+}
+
+func TestEncoderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := csvx.NewEncoder[int](&strings.Builder{})
+	require.ErrorIs(t, err, csvx.ErrNotStruct)
+
+	err = csvx.WriteAll(&strings.Builder{}, []struct{ M map[string]int }{{}})
+	require.ErrorIs(t, err, csvx.ErrUnsupportedType)
+}
@@ -0,0 +1,158 @@
+// Package dbx opens database/sql pools from a typed configuration: Connect
+// waits for the database with backoff, and options register a readiness
+// check with util/health and time every statement into util/metrics.
+//
+// The package registers no driver, so the binary imports the one matching
+// Config.Driver, such as github.com/jackc/pgx/v5/stdlib for "pgx".
+package dbx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrUnsupportedDriver is returned for drivers without a known DSN syntax.
+var ErrUnsupportedDriver = errors.New("dbx: unsupported driver")
+
+// redactedPassword replaces the password in Config.String.
+const redactedPassword = "xxxxx"
+
+// Config describes a database and the tuning of its connection pool. The
+// tags let util/config load it, usually nested under a "db" key. Zero
+// pool fields keep the database/sql defaults.
+type Config struct {
+	// Driver is the database/sql driver name: postgres or pgx, mysql,
+	// sqlite or sqlite3.
+	Driver string `config:"driver" env:"DB_DRIVER"`
+	// Host is the server name or address.
+	Host string `config:"host" env:"DB_HOST"`
+	// Port is the server port, the default port of the driver when zero.
+	Port int `config:"port" env:"DB_PORT"`
+	// User and Password authenticate to the server.
+	User     string `config:"user" env:"DB_USER"`
+	Password string `config:"password" env:"DB_PASSWORD"`
+	// Database is the database name, or the file path for SQLite.
+	Database string `config:"database" env:"DB_NAME"`
+	// Params holds the driver parameters, such as sslmode for PostgreSQL.
+	Params map[string]string `config:"params"`
+
+	// MaxOpenConns bounds the connections in use and idle.
+	MaxOpenConns int `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	// MaxIdleConns bounds the idle connections.
+	MaxIdleConns int `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	// ConnMaxLifetime closes the connections older than it.
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime"`
+	// ConnMaxIdleTime closes the connections idle for longer than it.
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time"`
+}
+
+// DSN returns the data source name of the database in the syntax of the
+// driver.
+func (c Config) DSN() (string, error) {
+	return c.format(c.Password)
+}
+
+// String returns the DSN with the password redacted, for logs.
+func (c Config) String() string {
+	password := c.Password
+	if password != "" {
+		password = redactedPassword
+	}
+
+	dsn, err := c.format(password)
+	if err != nil {
+		return c.Driver + ": " + err.Error()
+	}
+
+	return dsn
+}
+
+// format returns the DSN with password in place of the actual one.
+func (c Config) format(password string) (string, error) {
+	switch c.Driver {
+	case "postgres", "pgx":
+		return c.postgres(password), nil
+	case "mysql":
+		return c.mysql(password), nil
+	case "sqlite", "sqlite3":
+		return c.sqlite(), nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnsupportedDriver, c.Driver)
+	}
+}
+
+// postgres returns a URL such as postgres://user:pw@host:5432/db?k=v.
+func (c Config) postgres(password string) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     c.address(),
+		Path:     "/" + c.Database,
+		RawQuery: c.query(),
+	}
+
+	switch {
+	case password != "":
+		u.User = url.UserPassword(c.User, password)
+	case c.User != "":
+		u.User = url.User(c.User)
+	}
+
+	return u.String()
+}
+
+// mysql returns a go-sql-driver DSN such as user:pw@tcp(host:3306)/db?k=v.
+func (c Config) mysql(password string) string {
+	dsn := c.User
+	if password != "" {
+		dsn += ":" + password
+	}
+
+	if dsn != "" {
+		dsn += "@"
+	}
+
+	if c.Host != "" {
+		dsn += "tcp(" + c.address() + ")"
+	}
+
+	dsn += "/" + c.Database
+
+	if query := c.query(); query != "" {
+		dsn += "?" + query
+	}
+
+	return dsn
+}
+
+// sqlite returns the database path, as a file: URI with parameters.
+func (c Config) sqlite() string {
+	query := c.query()
+	if query == "" {
+		return c.Database
+	}
+
+	return "file:" + c.Database + "?" + query
+}
+
+// address returns the host with the port, if set.
+func (c Config) address() string {
+	if c.Port == 0 {
+		return c.Host
+	}
+
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// query encodes the parameters, sorted by name.
+func (c Config) query() string {
+	values := make(url.Values, len(c.Params))
+	for name, value := range c.Params {
+		values.Set(name, value)
+	}
+
+	return values.Encode()
+}
@@ -0,0 +1,65 @@
+package dbx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/dbx"
+)
+
+func TestDSN(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		cfg      dbx.Config
+		dsn      string
+		redacted string
+	}{
+		{
+			dbx.Config{
+				Driver: "pgx", Host: "db", Port: 5432, User: "app",
+				Password: "p@ss", Database: "main",
+				Params: map[string]string{"sslmode": "disable", "a": "b c"},
+			},
+			"postgres://app:p%40ss@db:5432/main?a=b+c&sslmode=disable",
+			"postgres://app:xxxxx@db:5432/main?a=b+c&sslmode=disable",
+		},
+		{
+			dbx.Config{Driver: "postgres", Host: "/run/postgresql"},
+			"postgres://%2Frun%2Fpostgresql/",
+			"postgres://%2Frun%2Fpostgresql/",
+		},
+		{
+			dbx.Config{
+				Driver: "mysql", Host: "db", Port: 3306, User: "app",
+				Password: "pw", Database: "main",
+				Params: map[string]string{"parseTime": "true"},
+			},
+			"app:pw@tcp(db:3306)/main?parseTime=true",
+			"app:xxxxx@tcp(db:3306)/main?parseTime=true",
+		},
+		{
+			dbx.Config{Driver: "sqlite", Database: "/var/lib/app.db"},
+			"/var/lib/app.db", "/var/lib/app.db",
+		},
+		{
+			dbx.Config{
+				Driver: "sqlite3", Database: "app.db",
+				Params: map[string]string{"mode": "ro"},
+			},
+			"file:app.db?mode=ro", "file:app.db?mode=ro",
+		},
+	} {
+		dsn, err := tc.cfg.DSN()
+		require.NoError(t, err)
+		assert.Equal(t, tc.dsn, dsn)
+		assert.Equal(t, tc.redacted, tc.cfg.String())
+	}
+
+	cfg := dbx.Config{Driver: "oracle"}
+	_, err := cfg.DSN()
+	require.ErrorIs(t, err, dbx.ErrUnsupportedDriver)
+	assert.Equal(t, `oracle: dbx: unsupported driver "oracle"`, cfg.String())
+}
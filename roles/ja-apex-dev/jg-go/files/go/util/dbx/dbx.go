@@ -0,0 +1,205 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/health"
+	"example.com/go-template/util/metrics"
+)
+
+// DefaultName identifies the database in health checks and metrics.
+const DefaultName = "db"
+
+// DefaultRetryPolicy returns the policy of Connect: 10 attempts with
+// backoff from 250ms to 5s, enough for a database starting alongside the
+// service.
+func DefaultRetryPolicy() util.RetryPolicy {
+	policy := util.DefaultRetryPolicy()
+	policy.MaxAttempts = 10
+	policy.InitialDelay = 250 * time.Millisecond
+	policy.MaxDelay = 5 * time.Second
+
+	return policy
+}
+
+// Option configures Open and Connect.
+type Option func(*settings)
+
+type settings struct {
+	name    string
+	retry   util.RetryPolicy
+	health  *health.Registry
+	metrics *metrics.Registry
+	hooks   []Hook
+}
+
+// WithName identifies the database as name instead of DefaultName.
+func WithName(name string) Option {
+	return func(s *settings) {
+		s.name = name
+	}
+}
+
+// WithRetryPolicy sets how Connect retries the first ping instead of
+// DefaultRetryPolicy.
+func WithRetryPolicy(policy util.RetryPolicy) Option {
+	return func(s *settings) {
+		s.retry = policy
+	}
+}
+
+// WithHealth registers a readiness check pinging the database, under its
+// name.
+func WithHealth(registry *health.Registry) Option {
+	return func(s *settings) {
+		s.health = registry
+	}
+}
+
+// WithMetrics exports the pool statistics as the go_sql_* metrics and the
+// statement durations as the db_query_duration_seconds histogram, both
+// labeled with the name of the database.
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(s *settings) {
+		s.metrics = registry
+	}
+}
+
+// WithHook calls hook after every statement.
+func WithHook(hook Hook) Option {
+	return func(s *settings) {
+		s.hooks = append(s.hooks, hook)
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{name: DefaultName, retry: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.metrics != nil {
+		s.hooks = append(s.hooks, durationHook(s.metrics, s.name))
+	}
+
+	return s
+}
+
+// Open returns the pool of cfg without connecting, like sql.Open.
+func Open(cfg Config, opts ...Option) (*sql.DB, error) {
+	s := newSettings(opts)
+
+	db, err := openPool(cfg, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.register(db); err != nil {
+		return nil, closing(db, err)
+	}
+
+	return db, nil
+}
+
+// Connect returns the pool of cfg once the database answers a ping,
+// retrying with backoff until the policy gives up or ctx is done.
+func Connect(
+	ctx context.Context, cfg Config, opts ...Option,
+) (*sql.DB, error) {
+	s := newSettings(opts)
+
+	db, err := openPool(cfg, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.Retry(ctx, s.retry, db.PingContext); err != nil {
+		return nil, closing(db, fmt.Errorf("dbx: %s: %w", s.name, err))
+	}
+
+	if err := s.register(db); err != nil {
+		return nil, closing(db, err)
+	}
+
+	return db, nil
+}
+
+// HealthCheck returns a check pinging db.
+func HealthCheck(db *sql.DB) health.Check {
+	return db.PingContext
+}
+
+// openPool opens the pool, observed by the hooks, and tunes it.
+func openPool(cfg Config, s settings) (*sql.DB, error) {
+	dsn, err := cfg.DSN()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(cfg.Driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("dbx: %w", err)
+	}
+
+	if len(s.hooks) > 0 {
+		observed, err := observe(db, dsn, s.hooks)
+		if err != nil {
+			return nil, closing(db, err)
+		}
+
+		db.Close()
+		db = observed
+	}
+
+	tune(db, cfg)
+
+	return db, nil
+}
+
+// tune applies the non-zero pool settings of cfg.
+func tune(db *sql.DB, cfg Config) {
+	if cfg.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+
+	if cfg.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+
+	if cfg.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
+	if cfg.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// register adds db to the health and metrics registries.
+func (s settings) register(db *sql.DB) error {
+	if s.metrics != nil {
+		err := s.metrics.Register(collectors.NewDBStatsCollector(db, s.name))
+		if err != nil {
+			return fmt.Errorf("dbx: %w", err)
+		}
+	}
+
+	if s.health != nil {
+		s.health.AddReadiness(s.name, HealthCheck(db))
+	}
+
+	return nil
+}
+
+// closing closes db after a failure, returning err.
+func closing(db *sql.DB, err error) error {
+	db.Close()
+
+	return err
+}
@@ -0,0 +1,131 @@
+package dbx_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/dbx"
+	"example.com/go-template/util/health"
+	"example.com/go-template/util/metrics"
+)
+
+// recorder collects the queries reported to its hook.
+type recorder struct {
+	mu      sync.Mutex
+	queries []dbx.Query
+}
+
+func (r *recorder) hook(_ context.Context, q dbx.Query) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queries = append(r.queries, q)
+}
+
+func sqlite(t *testing.T) dbx.Config {
+	t.Helper()
+
+	return dbx.Config{
+		Driver: "sqlite", Database: filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+	}
+}
+
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequestWithContext(
+		t.Context(), http.MethodGet, metrics.Path, nil))
+
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestConnect(t *testing.T) {
+	t.Parallel()
+
+	var queries recorder
+
+	checks := health.New()
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+
+	db, err := dbx.Connect(t.Context(), sqlite(t), dbx.WithName("main"),
+		dbx.WithHealth(checks), dbx.WithMetrics(registry),
+		dbx.WithHook(queries.hook))
+	require.NoError(t, err)
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (v TEXT)")
+	require.NoError(t, err)
+
+	stmt, err := db.PrepareContext(t.Context(), "INSERT INTO t VALUES (?)")
+	require.NoError(t, err)
+
+	_, err = stmt.ExecContext(t.Context(), "a")
+	require.NoError(t, err)
+	require.NoError(t, stmt.Close())
+
+	var value string
+	require.NoError(t, db.QueryRowContext(t.Context(),
+		"SELECT v FROM t WHERE v = ?", "a").Scan(&value))
+
+	_, err = db.ExecContext(t.Context(), "nonsense")
+	require.Error(t, err)
+
+	require.Len(t, queries.queries, 4)
+	assert.Equal(t, dbx.OpExec, queries.queries[1].Op)
+	assert.Equal(t, "INSERT INTO t VALUES (?)", queries.queries[1].SQL)
+	assert.Equal(t, dbx.OpQuery, queries.queries[2].Op)
+	assert.Error(t, queries.queries[3].Err)
+
+	exposition := scrape(t, registry)
+	assert.Contains(t, exposition, "db_query_duration_seconds_count"+
+		`{db="main",op="exec",status="ok"} 2`)
+	assert.Contains(t, exposition, "db_query_duration_seconds_count"+
+		`{db="main",op="exec",status="error"} 1`)
+	assert.Contains(t, exposition,
+		`go_sql_max_open_connections{db_name="main"} 1`)
+
+	assert.True(t, checks.Readiness(t.Context()).OK())
+	require.NoError(t, db.Close())
+	assert.False(t, checks.Readiness(t.Context()).OK())
+}
+
+func TestConnectErrors(t *testing.T) {
+	t.Parallel()
+
+	cfg := sqlite(t)
+	cfg.Database = filepath.Join(cfg.Database, "missing", "test.db")
+
+	_, err := dbx.Connect(t.Context(), cfg, dbx.WithRetryPolicy(
+		util.RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}))
+	require.ErrorIs(t, err, util.ErrRetriesExhausted)
+
+	_, err = dbx.Open(dbx.Config{Driver: "unknown"})
+	require.ErrorIs(t, err, dbx.ErrUnsupportedDriver)
+
+	_, err = dbx.Open(dbx.Config{Driver: "mysql"})
+	require.ErrorContains(t, err, "unknown driver")
+
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	db, err := dbx.Open(sqlite(t), dbx.WithMetrics(registry))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = dbx.Open(sqlite(t), dbx.WithMetrics(registry))
+	require.Error(t, err, "same name registered twice")
+}
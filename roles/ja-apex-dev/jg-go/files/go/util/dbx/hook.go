@@ -0,0 +1,326 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/go-template/util/metrics"
+)
+
+// Statement kinds reported to hooks.
+const (
+	OpExec  = "exec"
+	OpQuery = "query"
+)
+
+// errTxOptions is returned for transaction options the driver ignores.
+var errTxOptions = errors.New("dbx: driver does not support transaction " +
+	"isolation levels or read-only transactions")
+
+// Query describes a statement run by the database.
+type Query struct {
+	// Op is OpExec or OpQuery.
+	Op string
+	// SQL is the statement text.
+	SQL string
+	// Duration is the time the driver took to run the statement, without
+	// reading the rows of queries.
+	Duration time.Duration
+	// Err is the error of the statement, if any.
+	Err error
+}
+
+// Hook observes the statements, from the goroutine running them.
+type Hook func(ctx context.Context, q Query)
+
+// hooks are called in order.
+type hooks []Hook
+
+// durationHook records the durations of the statements of the database
+// name in r.
+func durationHook(r *metrics.Registry, name string) Hook {
+	histogram := r.Histogram("db_query_duration_seconds",
+		"Duration of the database statements.", nil, "db", "op", "status")
+
+	return func(_ context.Context, q Query) {
+		status := "ok"
+		if q.Err != nil {
+			status = "error"
+		}
+
+		histogram.Observe(q.Duration.Seconds(), name, q.Op, status)
+	}
+}
+
+// observe returns a pool of the driver of db whose connections report
+// their statements to hooks.
+func observe(db *sql.DB, dsn string, h hooks) (*sql.DB, error) {
+	var base driver.Connector = dsnConnector{dsn, db.Driver()}
+
+	if d, ok := db.Driver().(driver.DriverContext); ok {
+		var err error
+		if base, err = d.OpenConnector(dsn); err != nil {
+			return nil, fmt.Errorf("dbx: %w", err)
+		}
+	}
+
+	return sql.OpenDB(&connector{base, h}), nil
+}
+
+// dsnConnector opens connections of drivers without their own connector.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+// Connect implements driver.Connector.
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver implements driver.Connector.
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// connector wraps the connections of base.
+type connector struct {
+	base  driver.Connector
+	hooks hooks
+}
+
+// Connect implements driver.Connector.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	base, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{base, c.hooks}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *connector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// conn reports the statements of a driver connection, forwarding the
+// optional interfaces of database/sql to it.
+type conn struct {
+	driver.Conn
+
+	hooks hooks
+}
+
+// Prepare implements driver.Conn.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	base, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stmt{base, query, c.hooks}, nil
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *conn) PrepareContext(
+	ctx context.Context, query string,
+) (driver.Stmt, error) {
+	preparer, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+
+	base, err := preparer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stmt{base, query, c.hooks}, nil
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *conn) BeginTx(
+	ctx context.Context, opts driver.TxOptions,
+) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errTxOptions
+	}
+
+	return c.Conn.Begin() //nolint:staticcheck // The driver has no BeginTx.
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *conn) ExecContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.hooks.report(ctx, Query{OpExec, query, time.Since(start), err})
+
+	return result, err
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *conn) QueryContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.hooks.report(ctx, Query{OpQuery, query, time.Since(start), err})
+
+	return rows, err
+}
+
+// Ping implements driver.Pinger.
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *conn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *conn) CheckNamedValue(value *driver.NamedValue) error {
+	return checkNamedValue(c.Conn, value)
+}
+
+// stmt reports the executions of a prepared statement.
+type stmt struct {
+	driver.Stmt
+
+	query string
+	hooks hooks
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *stmt) ExecContext(
+	ctx context.Context, args []driver.NamedValue,
+) (driver.Result, error) {
+	start := time.Now()
+	result, err := s.exec(ctx, args)
+	s.hooks.report(ctx, Query{OpExec, s.query, time.Since(start), err})
+
+	return result, err
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *stmt) QueryContext(
+	ctx context.Context, args []driver.NamedValue,
+) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.run(ctx, args)
+	s.hooks.report(ctx, Query{OpQuery, s.query, time.Since(start), err})
+
+	return rows, err
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (s *stmt) CheckNamedValue(value *driver.NamedValue) error {
+	return checkNamedValue(s.Stmt, value)
+}
+
+func (s *stmt) exec(
+	ctx context.Context, args []driver.NamedValue,
+) (driver.Result, error) {
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+
+	values, err := positional(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Exec(values) //nolint:staticcheck // No ExecContext.
+}
+
+func (s *stmt) run(
+	ctx context.Context, args []driver.NamedValue,
+) (driver.Rows, error) {
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+
+	values, err := positional(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Query(values) //nolint:staticcheck // No QueryContext.
+}
+
+// report calls the hooks, except for statements the driver skipped.
+func (h hooks) report(ctx context.Context, q Query) {
+	if errors.Is(q.Err, driver.ErrSkip) {
+		return
+	}
+
+	for _, hook := range h {
+		hook(ctx, q)
+	}
+}
+
+// checkNamedValue forwards to the checker of base, if any, and lets
+// database/sql convert the value otherwise.
+func checkNamedValue(base any, value *driver.NamedValue) error {
+	if checker, ok := base.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+// positional returns the values of args for drivers without named
+// arguments.
+func positional(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("dbx: driver does not support named "+
+				"argument %q", arg.Name)
+		}
+
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
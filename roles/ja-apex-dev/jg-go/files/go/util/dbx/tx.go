@@ -0,0 +1,182 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"example.com/go-template/util"
+)
+
+// SQLSTATE codes of the transactions aborted by conflicts, which succeed
+// when retried.
+const (
+	stateSerializationFailure = "40001"
+	stateDeadlockDetected     = "40P01"
+)
+
+type txKey struct{}
+
+// txState is the transaction of a WithTx callback with its savepoint
+// depth.
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// TxFunc runs the statements of a transaction. Its context carries the
+// transaction, which nested WithTx calls join.
+type TxFunc func(ctx context.Context, tx *sql.Tx) error
+
+// TxOption configures WithTx.
+type TxOption func(*txSettings)
+
+type txSettings struct {
+	options *sql.TxOptions
+	retry   util.RetryPolicy
+}
+
+// WithTxOptions begins the transactions with options, such as an
+// isolation level, instead of the driver defaults.
+func WithTxOptions(options *sql.TxOptions) TxOption {
+	return func(s *txSettings) {
+		s.options = options
+	}
+}
+
+// WithTxRetryPolicy sets how the transactions aborted by conflicts are
+// retried instead of util.DefaultRetryPolicy. Without Retryable, the
+// policy retries the errors reported by IsSerializationFailure.
+func WithTxRetryPolicy(policy util.RetryPolicy) TxOption {
+	return func(s *txSettings) {
+		s.retry = policy
+	}
+}
+
+// WithTx runs fn in a transaction of db, committed when fn returns nil and
+// rolled back when it fails or panics. Transactions aborted by conflicts
+// are retried with fn called again, so fn must not have other side
+// effects.
+//
+// Called from within fn with its context, WithTx runs the nested fn in a
+// savepoint of the same transaction instead, whatever db, so that only
+// the statements of the nested fn are rolled back when it fails.
+func WithTx(
+	ctx context.Context, db *sql.DB, fn TxFunc, opts ...TxOption,
+) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.savepoint(ctx, fn)
+	}
+
+	s := txSettings{retry: util.DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.retry.Retryable == nil {
+		s.retry.Retryable = IsSerializationFailure
+	}
+
+	return util.Retry(ctx, s.retry, func(ctx context.Context) error {
+		return transaction(ctx, db, fn, s.options)
+	})
+}
+
+// TxFromContext returns the transaction carried by the context of a
+// TxFunc.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+
+	return state.tx, true
+}
+
+// IsSerializationFailure reports whether err is a serialization failure
+// or a deadlock, as reported by the drivers exposing the SQLSTATE code
+// with a SQLState method, such as pgx.
+func IsSerializationFailure(err error) bool {
+	var coded interface{ SQLState() string }
+	if !errors.As(err, &coded) {
+		return false
+	}
+
+	state := coded.SQLState()
+
+	return state == stateSerializationFailure || state == stateDeadlockDetected
+}
+
+// transaction runs fn in a new transaction.
+func transaction(
+	ctx context.Context, db *sql.DB, fn TxFunc, options *sql.TxOptions,
+) error {
+	tx, err := db.BeginTx(ctx, options)
+	if err != nil {
+		return fmt.Errorf("dbx: begin: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+
+			panic(r)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, &txState{tx: tx}), tx)
+	if err != nil {
+		if rbErr := rollback(tx); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("dbx: commit: %w", err)
+	}
+
+	return nil
+}
+
+// rollback rolls tx back, ignoring the transactions already done, such
+// as those of canceled contexts.
+func rollback(tx *sql.Tx) error {
+	err := tx.Rollback()
+	if err == nil || errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+
+	return fmt.Errorf("dbx: rollback: %w", err)
+}
+
+// savepoint runs fn in a savepoint of the transaction. A panic is left to
+// the outermost WithTx, which rolls everything back.
+func (s *txState) savepoint(ctx context.Context, fn TxFunc) error {
+	nested := &txState{tx: s.tx, depth: s.depth + 1}
+	name := "dbx_savepoint_" + strconv.Itoa(nested.depth)
+
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("dbx: savepoint: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, nested), s.tx); err != nil {
+		_, rbErr := s.tx.ExecContext(context.WithoutCancel(ctx),
+			"ROLLBACK TO SAVEPOINT "+name)
+		if rbErr != nil {
+			return errors.Join(err,
+				fmt.Errorf("dbx: rollback to savepoint: %w", rbErr))
+		}
+
+		return err
+	}
+
+	if _, err := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("dbx: release savepoint: %w", err)
+	}
+
+	return nil
+}
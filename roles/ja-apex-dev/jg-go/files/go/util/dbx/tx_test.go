@@ -0,0 +1,170 @@
+package dbx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/dbx"
+)
+
+var errBoom = errors.New("boom")
+
+// conflictError is a driver error carrying a SQLSTATE code.
+type conflictError struct {
+	state string
+}
+
+func (e *conflictError) Error() string {
+	return "conflict " + e.state
+}
+
+func (e *conflictError) SQLState() string {
+	return e.state
+}
+
+// table opens a database with an empty table t.
+func table(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := dbx.Open(sqlite(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (v TEXT)")
+	require.NoError(t, err)
+
+	return db
+}
+
+func insert(ctx context.Context, tx *sql.Tx, value string) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO t VALUES (?)", value)
+
+	return err
+}
+
+func values(t *testing.T, db *sql.DB) (count int) {
+	t.Helper()
+
+	require.NoError(t, db.QueryRowContext(t.Context(),
+		"SELECT COUNT(*) FROM t").Scan(&count))
+
+	return count
+}
+
+func TestWithTx(t *testing.T) {
+	t.Parallel()
+
+	db := table(t)
+
+	require.NoError(t, dbx.WithTx(t.Context(), db,
+		func(ctx context.Context, tx *sql.Tx) error {
+			current, ok := dbx.TxFromContext(ctx)
+			assert.True(t, ok)
+			assert.Same(t, tx, current)
+
+			return insert(ctx, tx, "a")
+		}))
+	assert.Equal(t, 1, values(t, db))
+
+	err := dbx.WithTx(t.Context(), db,
+		func(ctx context.Context, tx *sql.Tx) error {
+			require.NoError(t, insert(ctx, tx, "b"))
+
+			return errBoom
+		})
+	require.ErrorIs(t, err, errBoom)
+	assert.Equal(t, 1, values(t, db), "rolled back")
+
+	assert.Panics(t, func() {
+		_ = dbx.WithTx(t.Context(), db,
+			func(ctx context.Context, tx *sql.Tx) error {
+				require.NoError(t, insert(ctx, tx, "c"))
+				panic("boom")
+			})
+	})
+	assert.Equal(t, 1, values(t, db), "rolled back on panic")
+
+	_, ok := dbx.TxFromContext(t.Context())
+	assert.False(t, ok)
+}
+
+func TestWithTxNested(t *testing.T) {
+	t.Parallel()
+
+	db := table(t)
+
+	require.NoError(t, dbx.WithTx(t.Context(), db,
+		func(ctx context.Context, tx *sql.Tx) error {
+			require.NoError(t, insert(ctx, tx, "outer"))
+
+			err := dbx.WithTx(ctx, db,
+				func(ctx context.Context, tx *sql.Tx) error {
+					require.NoError(t, insert(ctx, tx, "inner"))
+
+					return dbx.WithTx(ctx, db,
+						func(ctx context.Context, tx *sql.Tx) error {
+							require.NoError(t, insert(ctx, tx, "innermost"))
+
+							return errBoom
+						})
+				})
+			require.ErrorIs(t, err, errBoom)
+
+			return dbx.WithTx(ctx, db,
+				func(ctx context.Context, tx *sql.Tx) error {
+					return insert(ctx, tx, "sibling")
+				})
+		}))
+	assert.Equal(t, 2, values(t, db), "outer and sibling")
+}
+
+func TestWithTxRetry(t *testing.T) {
+	t.Parallel()
+
+	db := table(t)
+	attempts := 0
+	policy := util.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	require.NoError(t, dbx.WithTx(t.Context(), db,
+		func(ctx context.Context, tx *sql.Tx) error {
+			attempts++
+			require.NoError(t, insert(ctx, tx, "v"))
+
+			if attempts < 3 {
+				return fmt.Errorf("wrapped: %w", &conflictError{"40001"})
+			}
+
+			return nil
+		}, dbx.WithTxRetryPolicy(policy)))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 1, values(t, db), "failed attempts rolled back")
+
+	attempts = 0
+	err := dbx.WithTx(t.Context(), db,
+		func(context.Context, *sql.Tx) error {
+			attempts++
+
+			return &conflictError{"23505"}
+		}, dbx.WithTxRetryPolicy(policy),
+		dbx.WithTxOptions(&sql.TxOptions{}))
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts, "not a conflict")
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, dbx.IsSerializationFailure(&conflictError{"40001"}))
+	assert.True(t, dbx.IsSerializationFailure(
+		fmt.Errorf("x: %w", &conflictError{"40P01"})))
+	assert.False(t, dbx.IsSerializationFailure(&conflictError{"23505"}))
+	assert.False(t, dbx.IsSerializationFailure(errBoom))
+}
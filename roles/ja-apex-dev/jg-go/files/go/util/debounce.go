@@ -0,0 +1,132 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer delays a function until calls have stopped for a quiet period,
+// collapsing bursts such as file watcher events into a single run. It is
+// safe for concurrent use and never runs the function concurrently with
+// itself.
+type Debouncer struct {
+	fn    func()
+	delay time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+	run     sync.Mutex
+}
+
+// Debounce returns a Debouncer running fn once delay has elapsed since the
+// last Call.
+func Debounce(fn func(), delay time.Duration) *Debouncer {
+	return &Debouncer{fn: fn, delay: delay}
+}
+
+// Call schedules fn, postponing any pending run.
+func (d *Debouncer) Call() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	d.timer = time.AfterFunc(d.delay, d.fire)
+}
+
+// Stop cancels the pending run and ignores further calls. It reports
+// whether a run was cancelled.
+func (d *Debouncer) Stop() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+
+	return d.timer != nil && d.timer.Stop()
+}
+
+func (d *Debouncer) fire() {
+	d.run.Lock()
+	defer d.run.Unlock()
+
+	d.fn()
+}
+
+// Throttler runs a function at most once per interval: the first call runs
+// right away and calls made during the interval collapse into a single
+// trailing run at its end. It is safe for concurrent use and never runs the
+// function concurrently with itself.
+type Throttler struct {
+	fn       func()
+	interval time.Duration
+
+	mu      sync.Mutex
+	last    time.Time
+	timer   *time.Timer
+	stopped bool
+	run     sync.Mutex
+}
+
+// Throttle returns a Throttler running fn at most once per interval.
+func Throttle(fn func(), interval time.Duration) *Throttler {
+	return &Throttler{fn: fn, interval: interval}
+}
+
+// Call runs fn now if the interval has elapsed since the last run, or
+// schedules a trailing run otherwise.
+func (t *Throttler) Call() {
+	t.mu.Lock()
+
+	if t.stopped || t.timer != nil {
+		t.mu.Unlock()
+
+		return
+	}
+
+	wait := t.interval - time.Since(t.last)
+	if wait > 0 {
+		t.timer = time.AfterFunc(wait, t.trailing)
+		t.mu.Unlock()
+
+		return
+	}
+
+	t.last = time.Now()
+	t.mu.Unlock()
+
+	t.invoke()
+}
+
+// Stop cancels the trailing run and ignores further calls. It reports
+// whether a run was cancelled.
+func (t *Throttler) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+
+	return t.timer != nil && t.timer.Stop()
+}
+
+func (t *Throttler) trailing() {
+	t.mu.Lock()
+	t.timer = nil
+	t.last = time.Now()
+	t.mu.Unlock()
+
+	t.invoke()
+}
+
+func (t *Throttler) invoke() {
+	t.run.Lock()
+	defer t.run.Unlock()
+
+	t.fn()
+}
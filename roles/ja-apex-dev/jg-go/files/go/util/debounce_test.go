@@ -0,0 +1,88 @@
+package util_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util"
+)
+
+func TestDebounce(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	debounced := util.Debounce(func() { calls.Add(1) }, 30*time.Millisecond)
+
+	for range 5 {
+		debounced.Call()
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	assert.Zero(t, calls.Load(), "bursts postpone the run")
+	assert.Eventually(t, func() bool { return calls.Load() == 1 },
+		time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load(), "a burst runs once")
+}
+
+func TestDebounceStop(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	debounced := util.Debounce(func() { calls.Add(1) }, 20*time.Millisecond)
+	debounced.Call()
+
+	assert.True(t, debounced.Stop())
+	assert.False(t, debounced.Stop())
+
+	debounced.Call()
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, calls.Load())
+}
+
+func TestThrottle(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	throttled := util.Throttle(func() { calls.Add(1) }, 40*time.Millisecond)
+
+	throttled.Call()
+	assert.Equal(t, int32(1), calls.Load(), "the first call runs at once")
+
+	for range 5 {
+		throttled.Call()
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Eventually(t, func() bool { return calls.Load() == 2 },
+		time.Second, 5*time.Millisecond, "calls collapse into a trailing run")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+
+	throttled.Call()
+	assert.Equal(t, int32(3), calls.Load(), "an idle throttler runs at once")
+}
+
+func TestThrottleStop(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	throttled := util.Throttle(func() { calls.Add(1) }, 20*time.Millisecond)
+	throttled.Call()
+	throttled.Call()
+
+	assert.True(t, throttled.Stop())
+
+	time.Sleep(40 * time.Millisecond)
+	throttled.Call()
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTag is the struct tag read by ApplyDefaults.
+const DefaultTag = "default"
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// ApplyDefaults fills the zero fields of the struct pointed to by target
+// from their `default` tag, replacing hand-written default constructors:
+//
+//	type Config struct {
+//		Addr    string        `default:":8080"`
+//		Timeout time.Duration `default:"30s"`
+//		Tags    []string      `default:"web,api"`
+//		TLS     struct {
+//			Enabled *bool `default:"true"`
+//		}
+//	}
+//
+// Values are parsed as by strconv, integers in base 10 whatever their
+// prefix, durations by ParseHumanDuration and types implementing
+// encoding.TextUnmarshaler by UnmarshalText; slices are comma separated
+// and pointers allocated. Nested and embedded structs
+// are filled recursively, through pointers when not nil. As zero values
+// are replaced, a field defaulting to true or non-zero cannot be set to
+// false or zero: use a pointer for those.
+func ApplyDefaults(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() ||
+		value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w, got %T", ErrInvalidTarget, target)
+	}
+
+	return fillDefaults(value.Elem(), "")
+}
+
+func fillDefaults(value reflect.Value, prefix string) error {
+	for i := range value.NumField() {
+		sf := value.Type().Field(i)
+		if !sf.IsExported() && !isEmbeddedStruct(sf) {
+			continue
+		}
+
+		if err := fillField(value.Field(i), sf,
+			joinPath(prefix, sf.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fillField sets field from its default tag when zero and otherwise
+// fills the struct it holds.
+func fillField(field reflect.Value, sf reflect.StructField, path string) error {
+	tag, ok := sf.Tag.Lookup(DefaultTag)
+	if !ok {
+		return fillNested(field, path)
+	}
+
+	if !sf.IsExported() || !field.IsZero() {
+		return nil
+	}
+
+	if err := setDefault(field, tag); err != nil {
+		return fmt.Errorf("util: default of field %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// isEmbeddedStruct reports whether sf embeds a struct by value, whose
+// exported fields are settable even when its type is unexported.
+func isEmbeddedStruct(sf reflect.StructField) bool {
+	return sf.Anonymous && sf.Type.Kind() == reflect.Struct
+}
+
+// fillNested fills the struct held by field, directly or through a
+// non-nil pointer.
+func fillNested(field reflect.Value, path string) error {
+	if field.Kind() == reflect.Pointer && !field.IsNil() {
+		field = field.Elem()
+	}
+
+	if field.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return fillDefaults(field, path)
+}
+
+// setDefault parses s into value.
+func setDefault(value reflect.Value, s string) error {
+	if u, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch {
+	case value.Type() == durationType:
+		d, err := ParseHumanDuration(s)
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(d))
+
+		return nil
+	case value.Kind() == reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+		if err := setDefault(elem.Elem(), s); err != nil {
+			return err
+		}
+
+		value.Set(elem)
+
+		return nil
+	case value.Kind() == reflect.Slice:
+		return setDefaultSlice(value, s)
+	default:
+		return setDefaultScalar(value, s)
+	}
+}
+
+// setDefaultSlice splits s by commas and parses every trimmed item.
+func setDefaultSlice(value reflect.Value, s string) error {
+	var items []string
+	if s != "" {
+		items = strings.Split(s, ",")
+	}
+
+	slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+
+	for i, item := range items {
+		err := setDefault(slice.Index(i), strings.TrimSpace(item))
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+
+	value.Set(slice)
+
+	return nil
+}
+
+func setDefaultScalar(value reflect.Value, s string) error {
+	parsed, err := parseScalar(value.Type(), s)
+	if err != nil {
+		return err
+	}
+
+	value.Set(reflect.ValueOf(parsed).Convert(value.Type()))
+
+	return nil
+}
+
+// parseScalar parses s as a value of the basic kind of t.
+func parseScalar(t reflect.Type, s string) (any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.ParseInt(s, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.ParseUint(s, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, t.Bits())
+	default:
+		return nil, fmt.Errorf("%w into %s", ErrConvert, t)
+	}
+}
@@ -0,0 +1,125 @@
+package util_test
+
+import (
+	"log/slog"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+type Listener struct {
+	Addr    netip.AddrPort `default:"127.0.0.1:8080"`
+	Backlog uint16         `default:"0128"`
+}
+
+type tlsSettings struct {
+	Enabled *bool  `default:"true"`
+	Cert    string `default:"/etc/tls/cert.pem"`
+}
+
+type serverConfig struct {
+	Listener
+	tlsSettings
+
+	Name    string        `default:"api"`
+	Level   level         `default:"info"`
+	Timeout time.Duration `default:"1m 30s"`
+	Ratio   float32       `default:"0.5"`
+	Tags    []string      `default:"web, api"`
+	Ports   []int         `default:""`
+	Log     slog.Level    `default:"warn"`
+	Limit   *int          `default:"10"`
+	Metrics struct {
+		Path string `default:"/metrics"`
+	}
+	Tracing *struct {
+		URL string `default:"http://jaeger"`
+	}
+	Disabled *struct {
+		URL string `default:"http://unused"`
+	}
+	Verbose bool
+	secret  string `default:"ignored"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg := serverConfig{Tracing: &struct {
+		URL string `default:"http://jaeger"`
+	}{}}
+	require.NoError(t, util.ApplyDefaults(&cfg))
+
+	assert.Equal(t, netip.MustParseAddrPort("127.0.0.1:8080"), cfg.Addr)
+	assert.Equal(t, uint16(128), cfg.Backlog)
+	assert.True(t, *cfg.Enabled)
+	assert.Equal(t, "/etc/tls/cert.pem", cfg.Cert)
+	assert.Equal(t, "api", cfg.Name)
+	assert.Equal(t, level("info"), cfg.Level)
+	assert.Equal(t, 90*time.Second, cfg.Timeout)
+	assert.InDelta(t, 0.5, cfg.Ratio, 0)
+	assert.Equal(t, []string{"web", "api"}, cfg.Tags)
+	assert.Equal(t, []int{}, cfg.Ports)
+	assert.Equal(t, slog.LevelWarn, cfg.Log)
+	assert.Equal(t, 10, *cfg.Limit)
+	assert.Equal(t, "/metrics", cfg.Metrics.Path)
+	assert.Equal(t, "http://jaeger", cfg.Tracing.URL)
+	assert.Nil(t, cfg.Disabled, "nil struct pointers are left nil")
+	assert.Empty(t, cfg.secret)
+}
+
+func TestApplyDefaultsKeepsValues(t *testing.T) {
+	t.Parallel()
+
+	disabled := false
+	cfg := serverConfig{
+		Name: "worker", Tags: []string{"batch"},
+		tlsSettings: tlsSettings{Enabled: &disabled},
+	}
+	require.NoError(t, util.ApplyDefaults(&cfg))
+
+	assert.Equal(t, "worker", cfg.Name)
+	assert.Equal(t, []string{"batch"}, cfg.Tags)
+	assert.False(t, *cfg.Enabled)
+	assert.Equal(t, "/etc/tls/cert.pem", cfg.Cert)
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	t.Parallel()
+
+	var badInt struct {
+		Nested struct {
+			Port int8 `default:"300"`
+		}
+	}
+	err := util.ApplyDefaults(&badInt)
+	require.ErrorIs(t, err, strconv.ErrRange)
+	assert.ErrorContains(t, err, "field Nested.Port")
+	assert.Zero(t, badInt.Nested.Port)
+
+	var hex struct {
+		Mask int `default:"0x80"`
+	}
+	require.ErrorIs(t, util.ApplyDefaults(&hex), strconv.ErrSyntax)
+
+	var badItem struct {
+		Ports []uint `default:"80,http"`
+	}
+	err = util.ApplyDefaults(&badItem)
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.ErrorContains(t, err, "item 1")
+
+	var unsupported struct {
+		Labels map[string]string `default:"a=b"`
+	}
+	require.ErrorIs(t, util.ApplyDefaults(&unsupported), util.ErrConvert)
+
+	require.ErrorIs(t, util.ApplyDefaults(serverConfig{}),
+		util.ErrInvalidTarget)
+}
@@ -0,0 +1,239 @@
+// Package devrun supervises the processes of a local development
+// environment, such as an API, a worker and a file watcher, declared in a
+// Procfile. Processes start in dependency order, their output is
+// interleaved line by line behind colored name prefixes, and they restart
+// when the files they watch change:
+//
+//	procs, err := devrun.LoadProcfile("Procfile")
+//	if err != nil {
+//		return err
+//	}
+//
+//	s, err := devrun.New(procs)
+//	if err != nil {
+//		return err
+//	}
+//
+//	return s.Run(ctx)
+//
+// As with foreman, the exit of any process stops all the others.
+package devrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/watch"
+)
+
+// Defaults of the supervisor settings.
+const (
+	DefaultShell       = "/bin/sh"
+	DefaultDebounce    = watch.DefaultDebounce
+	DefaultStopTimeout = 5 * time.Second
+)
+
+var (
+	// ErrExited is returned by Run when a process exits on its own.
+	ErrExited = errors.New("devrun: process exited")
+	// ErrInvalid is returned by New for duplicate names, unknown
+	// dependencies and dependency cycles.
+	ErrInvalid = errors.New("devrun: invalid processes")
+)
+
+// Process is a supervised command.
+type Process struct {
+	// Name identifies the process in the output.
+	Name string
+	// Command is run by the shell.
+	Command string
+	// After lists the processes to start, and wait for, first.
+	After []string
+	// Watch holds glob patterns, relative to the directory of the
+	// supervisor, whose changes restart the process.
+	Watch []string
+	// Ready is a TCP address accepting connections once the process is
+	// ready, which its dependents wait for.
+	Ready string
+}
+
+// Option configures New.
+type Option func(*Supervisor)
+
+// WithOutput writes the prefixed output to w instead of os.Stdout. Colors
+// are used when w is a terminal.
+func WithOutput(w io.Writer) Option {
+	return func(s *Supervisor) {
+		s.out = w
+	}
+}
+
+// WithDir runs the processes and resolves watch patterns in dir instead
+// of the working directory.
+func WithDir(dir string) Option {
+	return func(s *Supervisor) {
+		s.dir = dir
+	}
+}
+
+// WithShell runs the commands with shell -c instead of DefaultShell.
+func WithShell(shell string) Option {
+	return func(s *Supervisor) {
+		s.shell = shell
+	}
+}
+
+// WithDebounce sets the quiet period after changes to watched files
+// before the process restarts.
+func WithDebounce(d time.Duration) Option {
+	return func(s *Supervisor) {
+		s.debounce = d
+	}
+}
+
+// WithStopTimeout sets how long processes may take to exit once
+// interrupted before they are killed.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(s *Supervisor) {
+		s.stopTimeout = timeout
+	}
+}
+
+// Supervisor runs a set of processes.
+type Supervisor struct {
+	procs       []Process
+	out         io.Writer
+	dir         string
+	shell       string
+	debounce    time.Duration
+	stopTimeout time.Duration
+
+	output *output
+	ready  map[string]chan struct{}
+}
+
+// New returns a supervisor of procs, ordered so that dependencies come
+// first.
+func New(procs []Process, opts ...Option) (*Supervisor, error) {
+	ordered, err := order(procs)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Supervisor{
+		procs: ordered, out: os.Stdout, dir: ".", shell: DefaultShell,
+		debounce: DefaultDebounce, stopTimeout: DefaultStopTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	names := make([]string, len(ordered))
+	for i, p := range ordered {
+		names[i] = p.Name
+	}
+
+	s.output = newOutput(s.out, names)
+
+	return s, nil
+}
+
+// Run starts the processes and supervises them until ctx is done, which
+// stops them and returns nil, or until one exits, which stops the others
+// and returns ErrExited.
+func (s *Supervisor) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	s.ready = make(map[string]chan struct{}, len(s.procs))
+	for _, p := range s.procs {
+		s.ready[p.Name] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+
+	for _, p := range s.procs {
+		wg.Go(func() {
+			if err := s.supervise(ctx, p); err != nil {
+				cancel(err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	if err := context.Cause(ctx); errors.Is(err, ErrExited) {
+		return err
+	}
+
+	return nil
+}
+
+// order sorts procs so that every process follows its dependencies,
+// keeping the given order otherwise.
+func order(procs []Process) ([]Process, error) {
+	o := &sorter{
+		byName: make(map[string]Process, len(procs)),
+		done:   make(map[string]bool, len(procs)),
+	}
+
+	for _, p := range procs {
+		if _, ok := o.byName[p.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate name %q", ErrInvalid, p.Name)
+		}
+
+		o.byName[p.Name] = p
+	}
+
+	for _, p := range procs {
+		if err := o.visit(p, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return o.ordered, nil
+}
+
+// sorter sorts processes depth first.
+type sorter struct {
+	byName  map[string]Process
+	done    map[string]bool
+	ordered []Process
+}
+
+// visit appends the dependencies of p and then p, unless already done.
+// path holds the dependents being visited, to detect cycles.
+func (o *sorter) visit(p Process, path []string) error {
+	if o.done[p.Name] {
+		return nil
+	}
+
+	if slices.Contains(path, p.Name) {
+		return fmt.Errorf("%w: cycle %s", ErrInvalid,
+			strings.Join(append(path, p.Name), " -> "))
+	}
+
+	for _, dep := range p.After {
+		next, ok := o.byName[dep]
+		if !ok {
+			return fmt.Errorf("%w: %s depends on unknown %q", ErrInvalid,
+				p.Name, dep)
+		}
+
+		if err := o.visit(next, append(path, p.Name)); err != nil {
+			return err
+		}
+	}
+
+	o.done[p.Name] = true
+	o.ordered = append(o.ordered, p)
+
+	return nil
+}
@@ -0,0 +1,173 @@
+//go:build unix
+
+package devrun_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/devrun"
+	"example.com/go-template/util/testx"
+)
+
+// buffer is a bytes.Buffer safe for concurrent use.
+type buffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestNewInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, procs := range [][]devrun.Process{
+		{{Name: "a", Command: "true"}, {Name: "a", Command: "true"}},
+		{{Name: "a", Command: "true", After: []string{"b"}}},
+		{
+			{Name: "a", Command: "true", After: []string{"b"}},
+			{Name: "b", Command: "true", After: []string{"a"}},
+		},
+	} {
+		_, err := devrun.New(procs)
+		require.ErrorIs(t, err, devrun.ErrInvalid)
+	}
+}
+
+func TestRunOrderAndExit(t *testing.T) {
+	t.Parallel()
+
+	var out buffer
+
+	s, err := devrun.New([]devrun.Process{
+		{Name: "worker", Command: "echo done; exit 3", After: []string{"db"}},
+		{Name: "db", Command: "printf 'up'; exec sleep 30"},
+	}, devrun.WithOutput(&out))
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = s.Run(t.Context())
+	require.ErrorIs(t, err, devrun.ErrExited)
+	assert.ErrorContains(t, err, "worker: exited: exit status 3")
+	assert.Less(t, time.Since(start), 5*time.Second, "db was stopped")
+
+	lines := out.String()
+	assert.Contains(t, lines, "db     | up\n")
+	assert.Contains(t, lines, "worker | done\n")
+	assert.Less(t, strings.Index(lines, "db     | started"),
+		strings.Index(lines, "worker | started"))
+}
+
+func TestRunWaitsForReady(t *testing.T) {
+	t.Parallel()
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := probe.Addr().String()
+	require.NoError(t, probe.Close())
+
+	var out buffer
+
+	s, err := devrun.New([]devrun.Process{
+		{Name: "server", Command: "exec sleep 30", Ready: addr},
+		{Name: "client", Command: "echo connected", After: []string{"server"}},
+	}, devrun.WithOutput(&out))
+	require.NoError(t, err)
+
+	delay := 300 * time.Millisecond
+	listening := time.AfterFunc(delay, func() {
+		if listener, err := net.Listen("tcp", addr); err == nil {
+			t.Cleanup(func() { listener.Close() })
+		}
+	})
+	t.Cleanup(func() { listening.Stop() })
+
+	start := time.Now()
+	require.ErrorIs(t, s.Run(t.Context()), devrun.ErrExited)
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+	assert.Contains(t, out.String(), "server | ready on "+addr)
+	assert.Contains(t, out.String(), "client | connected")
+}
+
+func TestRunRestartsOnChange(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{"src/app.go": "v1"})
+
+	var out buffer
+
+	s, err := devrun.New([]devrun.Process{{
+		Name: "app", Command: "echo run $(cat src/app.go); exec sleep 30",
+		Watch: []string{"**/*.go"},
+	}}, devrun.WithOutput(&out), devrun.WithDir(dir),
+		devrun.WithDebounce(20*time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- s.Run(ctx) }()
+
+	testx.RequireEventually(t, func() bool {
+		return strings.Contains(out.String(), "app | run v1")
+	}, 5*time.Second)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "app.go"),
+		[]byte("v2-longer"), 0o600))
+
+	testx.RequireEventually(t, func() bool {
+		return strings.Contains(out.String(), "app | run v2-longer")
+	}, 5*time.Second)
+	assert.Contains(t, out.String(),
+		"app | "+filepath.Join("src", "app.go")+" changed, restarting")
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestRunKillsAfterStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	var out buffer
+
+	s, err := devrun.New([]devrun.Process{{
+		Name: "stubborn", Command: "trap '' TERM; echo up; sleep 30",
+	}}, devrun.WithOutput(&out), devrun.WithStopTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- s.Run(ctx) }()
+
+	testx.RequireEventually(t, func() bool {
+		return strings.Contains(out.String(), "stubborn | up")
+	}, 5*time.Second)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Contains(t, out.String(), "did not stop in time, killing")
+}
@@ -0,0 +1,99 @@
+package devrun
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"example.com/go-template/util/term"
+)
+
+// output interleaves the lines of the processes behind their prefixes.
+type output struct {
+	mu       sync.Mutex
+	w        io.Writer
+	styler   term.Styler
+	width    int
+	painters map[string]func(string) string
+}
+
+func newOutput(w io.Writer, names []string) *output {
+	o := &output{
+		w: w, styler: term.NewStyler(w),
+		painters: make(map[string]func(string) string, len(names)),
+	}
+
+	palette := []func(string) string{
+		o.styler.Cyan, o.styler.Yellow, o.styler.Green, o.styler.Blue,
+		o.styler.Red,
+	}
+
+	for i, name := range names {
+		o.width = max(o.width, len(name))
+		o.painters[name] = palette[i%len(palette)]
+	}
+
+	return o
+}
+
+// line writes a line of the process name.
+func (o *output) line(name, text string) {
+	prefix := o.painters[name](name + strings.Repeat(" ", o.width-len(name)) +
+		" | ")
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_, _ = io.WriteString(o.w, prefix+text+"\n")
+}
+
+// system writes a message of the supervisor about the process name.
+func (o *output) system(name, message string) {
+	o.line(name, o.styler.Dim(message))
+}
+
+// writer returns a writer of the output of the process name.
+func (o *output) writer(name string) *lineWriter {
+	return &lineWriter{output: o, name: name}
+}
+
+// lineWriter splits the output of a process into lines.
+type lineWriter struct {
+	output *output
+	name   string
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+// Write implements io.Writer.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.output.line(w.name, strings.TrimSuffix(string(w.pending[:i]), "\r"))
+		w.pending = w.pending[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the last line when it has no newline.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 {
+		w.output.line(w.name, string(w.pending))
+		w.pending = nil
+	}
+}
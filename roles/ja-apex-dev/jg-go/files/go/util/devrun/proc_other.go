@@ -0,0 +1,17 @@
+//go:build !unix
+
+package devrun
+
+import "os/exec"
+
+// detach is a no-op without process groups.
+func detach(*exec.Cmd) {}
+
+// interrupt kills the shell, since there is no graceful signal.
+func interrupt(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
+
+func kill(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,115 @@
+package devrun
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrSyntax is returned for malformed Procfile lines.
+var ErrSyntax = errors.New("devrun: procfile syntax error")
+
+// name matches the process names allowed by Procfile.
+var name = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// LoadProcfile reads the Procfile at path.
+func LoadProcfile(path string) ([]Process, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("devrun: %w", err)
+	}
+	defer f.Close()
+
+	procs, err := ParseProcfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+
+	return procs, nil
+}
+
+// ParseProcfile parses "name: command" lines, skipping blank lines and
+// "#" comments. Attributes may follow the name before the colon:
+//
+//	db ready=localhost:5432: postgres -D .data
+//	api after=db watch=**/*.go,go.mod: go run ./cmd/api
+//
+// after lists the processes to start first, watch the glob patterns whose
+// changes restart the process and ready the TCP address it listens on
+// once started.
+func ParseProcfile(r io.Reader) ([]Process, error) {
+	var procs []Process
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		p, err := parseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+		}
+
+		procs = append(procs, p)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("devrun: %w", err)
+	}
+
+	return procs, nil
+}
+
+// parseLine parses a process line. The head ends at the first colon
+// followed by a space, since ready addresses contain colons.
+func parseLine(text string) (Process, error) {
+	head, command, ok := strings.Cut(text, ": ")
+	if !ok {
+		head, ok = strings.CutSuffix(text, ":")
+	}
+
+	fields := strings.Fields(head)
+	command = strings.TrimSpace(command)
+
+	if !ok || len(fields) == 0 || !name.MatchString(fields[0]) ||
+		command == "" {
+		return Process{}, errors.New("want \"name: command\"")
+	}
+
+	p := Process{Name: fields[0], Command: command}
+
+	for _, field := range fields[1:] {
+		if err := p.set(field); err != nil {
+			return Process{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// set applies a key=value attribute.
+func (p *Process) set(field string) error {
+	key, value, _ := strings.Cut(field, "=")
+	if value == "" {
+		return fmt.Errorf("attribute %q has no value", field)
+	}
+
+	switch key {
+	case "after":
+		p.After = append(p.After, strings.Split(value, ",")...)
+	case "watch":
+		p.Watch = append(p.Watch, strings.Split(value, ",")...)
+	case "ready":
+		p.Ready = value
+	default:
+		return fmt.Errorf("unknown attribute %q", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package devrun_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/devrun"
+	"example.com/go-template/util/testx"
+)
+
+func TestParseProcfile(t *testing.T) {
+	t.Parallel()
+
+	procs, err := devrun.ParseProcfile(strings.NewReader(`
+# Local stack.
+db ready=localhost:5432: postgres -D .data
+api after=db watch=**/*.go,go.mod: go run ./cmd/api -addr :8080
+worker after=db after=api:   go run ./cmd/worker
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []devrun.Process{
+		{Name: "db", Command: "postgres -D .data", Ready: "localhost:5432"},
+		{
+			Name: "api", Command: "go run ./cmd/api -addr :8080",
+			After: []string{"db"}, Watch: []string{"**/*.go", "go.mod"},
+		},
+		{
+			Name: "worker", Command: "go run ./cmd/worker",
+			After: []string{"db", "api"},
+		},
+	}, procs)
+}
+
+func TestParseProcfileErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"api go run .\n",
+		"web:npm run dev\n",
+		"api:\n",
+		"bad name: run\n",
+		"api color=red: run\n",
+		"api after=: run\n",
+	} {
+		_, err := devrun.ParseProcfile(strings.NewReader(text))
+		require.ErrorIs(t, err, devrun.ErrSyntax, text)
+	}
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"Procfile": "ok: true\n\nbroken\n",
+	})
+
+	_, err := devrun.LoadProcfile(filepath.Join(dir, "Procfile"))
+	require.ErrorContains(t, err, "line 3")
+
+	_, err = devrun.LoadProcfile(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package devrun
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"time"
+)
+
+// readyPoll is the interval between connection attempts to Ready
+// addresses.
+const readyPoll = 100 * time.Millisecond
+
+// running is a started command.
+type running struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// supervise runs p once its dependencies are ready, restarting it on
+// changes, until ctx is done or p exits.
+func (s *Supervisor) supervise(ctx context.Context, p Process) error {
+	for _, dep := range p.After {
+		select {
+		case <-s.ready[dep]:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	changes := s.watch(ctx, p)
+	signalReady := true
+
+	for {
+		r, err := s.start(p)
+		if err != nil {
+			return err
+		}
+
+		if signalReady {
+			signalReady = false
+
+			go s.waitReady(ctx, p)
+		}
+
+		select {
+		case <-r.done:
+			s.output.system(p.Name, describeExit(r.err))
+
+			return fmt.Errorf("%w: %s: %s", ErrExited, p.Name,
+				describeExit(r.err))
+		case <-ctx.Done():
+			s.stop(p, r)
+
+			return nil
+		case path := <-changes:
+			s.output.system(p.Name, path+" changed, restarting")
+			s.stop(p, r)
+		}
+	}
+}
+
+// start starts p with its output prefixed.
+func (s *Supervisor) start(p Process) (*running, error) {
+	w := s.output.writer(p.Name)
+	cmd := exec.Command(s.shell, "-c", p.Command)
+	cmd.Dir = s.dir
+	cmd.Stdout, cmd.Stderr = w, w
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrExited, p.Name, err)
+	}
+
+	s.output.system(p.Name,
+		fmt.Sprintf("started with pid %d", cmd.Process.Pid))
+
+	r := &running{cmd: cmd, done: make(chan struct{})}
+
+	go func() {
+		r.err = cmd.Wait()
+		w.Flush()
+		close(r.done)
+	}()
+
+	return r, nil
+}
+
+// stop interrupts the process group of r, killing it after the stop
+// timeout.
+func (s *Supervisor) stop(p Process, r *running) {
+	interrupt(r.cmd)
+
+	select {
+	case <-r.done:
+	case <-time.After(s.stopTimeout):
+		s.output.system(p.Name, "did not stop in time, killing")
+		kill(r.cmd)
+		<-r.done
+	}
+}
+
+// waitReady marks p ready once its Ready address accepts connections, or
+// right away without one.
+func (s *Supervisor) waitReady(ctx context.Context, p Process) {
+	defer close(s.ready[p.Name])
+
+	if p.Ready == "" {
+		return
+	}
+
+	var dialer net.Dialer
+
+	for ctx.Err() == nil {
+		conn, err := dialer.DialContext(ctx, "tcp", p.Ready)
+		if err == nil {
+			conn.Close()
+			s.output.system(p.Name, "ready on "+p.Ready)
+
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(readyPoll):
+		}
+	}
+}
+
+// describeExit describes the outcome of a process.
+func describeExit(err error) string {
+	if err == nil {
+		return "exited"
+	}
+
+	return "exited: " + err.Error()
+}
@@ -0,0 +1,39 @@
+package devrun
+
+import (
+	"context"
+	"path/filepath"
+
+	"example.com/go-template/util/watch"
+)
+
+// watch sends the path of one changed, added or removed file matching the
+// patterns of p per batch of changes. It never sends without patterns or
+// when the directory cannot be watched.
+func (s *Supervisor) watch(ctx context.Context, p Process) <-chan string {
+	changes := make(chan string)
+	if len(p.Watch) == 0 {
+		return changes
+	}
+
+	w, err := watch.New(s.dir,
+		watch.WithInclude(p.Watch...), watch.WithDebounce(s.debounce))
+	if err != nil {
+		s.output.system(p.Name, "not watching: "+err.Error())
+
+		return changes
+	}
+
+	go func() {
+		defer w.Close()
+
+		_ = w.Run(ctx, func(events []watch.Event) {
+			select {
+			case changes <- filepath.FromSlash(events[0].Path):
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	return changes
+}
@@ -0,0 +1,316 @@
+// Package diff compares arbitrary Go values, such as a desired and an
+// actual configuration, and reports the changes both as readable
+// "check mode" output and as JSON-friendly records.
+//
+// Paths join struct fields and map keys with dots and index slices with
+// brackets, for example "spec.containers[0].image". Struct fields use
+// their JSON name when tagged. Cyclic values are compared up to the point
+// where they loop back.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Op is the kind of a change.
+type Op string
+
+// Change kinds.
+const (
+	Added   Op = "add"
+	Removed Op = "remove"
+	Changed Op = "change"
+)
+
+// Change is a single difference between the compared values.
+type Change struct {
+	Path string `json:"path"`
+	Op   Op     `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// String renders the change as a single line prefixed with "+", "-" or
+// "~".
+func (c Change) String() string {
+	switch c.Op {
+	case Added:
+		return "+ " + c.Path + ": " + format(c.To)
+	case Removed:
+		return "- " + c.Path + ": " + format(c.From)
+	default:
+		return "~ " + c.Path + ": " + format(c.From) + " -> " + format(c.To)
+	}
+}
+
+// Changes lists differences in path order.
+type Changes []Change
+
+// String renders one change per line.
+func (c Changes) String() string {
+	lines := make([]string, len(c))
+	for i, change := range c {
+		lines[i] = change.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Option configures Compare.
+type Option func(*differ)
+
+// WithIgnore skips paths matching any of patterns, along with everything
+// below them. A "*" segment matches any single field, key or index, as in
+// "metadata.annotations.*" or "items[*].status".
+func WithIgnore(patterns ...string) Option {
+	return func(d *differ) {
+		for _, pattern := range patterns {
+			d.ignore = append(d.ignore, segments(pattern))
+		}
+	}
+}
+
+type differ struct {
+	ignore   [][]string
+	changes  Changes
+	visiting map[visit]struct{}
+}
+
+// visit identifies a pair of values being compared by their addresses.
+type visit struct {
+	from, to uintptr
+	typ      reflect.Type
+}
+
+// Compare returns the changes turning from into to.
+func Compare(from, to any, opts ...Option) Changes {
+	d := &differ{visiting: make(map[visit]struct{})}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.compare("", reflect.ValueOf(from), reflect.ValueOf(to))
+
+	return d.changes
+}
+
+// Equal reports whether Compare finds no change.
+func Equal(from, to any, opts ...Option) bool {
+	return len(Compare(from, to, opts...)) == 0
+}
+
+func (d *differ) compare(path string, from, to reflect.Value) {
+	if d.ignored(path) {
+		return
+	}
+
+	from, to = unwrap(from), unwrap(to)
+
+	switch {
+	case !from.IsValid() && !to.IsValid():
+	case !from.IsValid():
+		d.add(Change{Path: path, Op: Added, To: to.Interface()})
+	case !to.IsValid():
+		d.add(Change{Path: path, Op: Removed, From: from.Interface()})
+	case from.Type() != to.Type():
+		d.changed(path, from, to)
+	default:
+		d.enter(from, to, func() { d.compareSameType(path, from, to) })
+	}
+}
+
+// enter compares the values with fn unless the same pair is already being
+// compared higher in the tree: cyclic values have no change left to report
+// there.
+func (d *differ) enter(from, to reflect.Value, fn func()) {
+	a, ok := address(from)
+	b, ok2 := address(to)
+
+	if !ok || !ok2 {
+		fn()
+
+		return
+	}
+
+	key := visit{from: a, to: b, typ: from.Type()}
+	if _, ok := d.visiting[key]; ok {
+		return
+	}
+
+	d.visiting[key] = struct{}{}
+	defer delete(d.visiting, key)
+
+	fn()
+}
+
+func (d *differ) compareSameType(path string, from, to reflect.Value) {
+	switch from.Kind() {
+	case reflect.Struct:
+		d.compareStruct(path, from, to)
+	case reflect.Map:
+		d.compareMap(path, from, to)
+	case reflect.Slice, reflect.Array:
+		d.compareSlice(path, from, to)
+	default:
+		if !reflect.DeepEqual(from.Interface(), to.Interface()) {
+			d.changed(path, from, to)
+		}
+	}
+}
+
+func (d *differ) compareStruct(path string, from, to reflect.Value) {
+	exported := 0
+
+	for i := range from.NumField() {
+		field := from.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		exported++
+		d.compare(join(path, fieldName(field)), from.Field(i), to.Field(i))
+	}
+
+	// Opaque structs such as time.Time are compared as a whole.
+	if exported == 0 && !reflect.DeepEqual(from.Interface(), to.Interface()) {
+		d.changed(path, from, to)
+	}
+}
+
+func (d *differ) compareMap(path string, from, to reflect.Value) {
+	keys := from.MapKeys()
+	for _, key := range to.MapKeys() {
+		if !from.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+
+	for _, key := range keys {
+		d.compare(join(path, fmt.Sprint(key)),
+			from.MapIndex(key), to.MapIndex(key))
+	}
+}
+
+func (d *differ) compareSlice(path string, from, to reflect.Value) {
+	for i := range max(from.Len(), to.Len()) {
+		var a, b reflect.Value
+		if i < from.Len() {
+			a = from.Index(i)
+		}
+
+		if i < to.Len() {
+			b = to.Index(i)
+		}
+
+		d.compare(path+"["+strconv.Itoa(i)+"]", a, b)
+	}
+}
+
+func (d *differ) changed(path string, from, to reflect.Value) {
+	d.add(Change{
+		Path: path, Op: Changed, From: from.Interface(), To: to.Interface(),
+	})
+}
+
+func (d *differ) add(change Change) {
+	if change.Path == "" {
+		change.Path = "."
+	}
+
+	d.changes = append(d.changes, change)
+}
+
+// ignored reports whether path or one of its parents matches a pattern.
+func (d *differ) ignored(path string) bool {
+	parts := segments(path)
+
+	for _, pattern := range d.ignore {
+		if len(pattern) > len(parts) {
+			continue
+		}
+
+		if matches(pattern, parts[:len(pattern)]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matches(pattern, parts []string) bool {
+	for i, segment := range pattern {
+		if segment != "*" && segment != parts[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// unwrap dereferences interfaces and pointers, mapping nil to the invalid
+// value.
+func unwrap(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
+
+// address returns the address of a map, slice or value reached through a
+// pointer, the only values that can contain themselves.
+func address(value reflect.Value) (uintptr, bool) {
+	switch {
+	case value.Kind() == reflect.Map || value.Kind() == reflect.Slice:
+		return value.Pointer(), true
+	case value.CanAddr():
+		return value.UnsafeAddr(), true
+	default:
+		return 0, false
+	}
+}
+
+// segments splits "a.b[0]" into "a", "b" and "0".
+func segments(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '.' })
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func format(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
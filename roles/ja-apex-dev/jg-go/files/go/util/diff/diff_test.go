@@ -0,0 +1,145 @@
+package diff_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/diff"
+)
+
+type container struct {
+	Image string `json:"image"`
+	Ports []int  `json:"ports"`
+}
+
+type spec struct {
+	Replicas   int               `json:"replicas"`
+	Labels     map[string]string `json:"labels"`
+	Containers []container       `json:"containers"`
+	Updated    time.Time         `json:"updated"`
+	Owner      *string           `json:"owner"`
+	internal   int
+}
+
+func TestCompareStructs(t *testing.T) {
+	t.Parallel()
+
+	owner := "ops"
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	desired := spec{
+		Replicas:   3,
+		Labels:     map[string]string{"app": "api", "team": "core"},
+		Containers: []container{{Image: "api:2", Ports: []int{80, 443}}},
+		Updated:    now.Add(time.Hour),
+		Owner:      &owner,
+		internal:   1,
+	}
+	actual := spec{
+		Replicas:   2,
+		Labels:     map[string]string{"app": "api", "tier": "web"},
+		Containers: []container{{Image: "api:1", Ports: []int{80}}},
+		Updated:    now,
+	}
+
+	changes := diff.Compare(actual, desired)
+	assert.Equal(t, `~ replicas: 2 -> 3
++ labels.team: "core"
+- labels.tier: "web"
+~ containers[0].image: "api:1" -> "api:2"
++ containers[0].ports[1]: 443
+~ updated: 2024-01-01 00:00:00 +0000 UTC -> 2024-01-01 01:00:00 +0000 UTC
++ owner: "ops"`, changes.String())
+
+	assert.True(t, diff.Equal(desired, desired))
+	assert.Empty(t, diff.Compare(&desired, &desired))
+}
+
+func TestCompareDynamic(t *testing.T) {
+	t.Parallel()
+
+	from := map[string]any{"a": 1, "b": []any{"x"}, "c": map[string]any{}}
+	to := map[string]any{"a": "1", "b": []any{}, "d": nil}
+
+	assert.Equal(t, diff.Changes{
+		{Path: "a", Op: diff.Changed, From: 1, To: "1"},
+		{Path: "b[0]", Op: diff.Removed, From: "x"},
+		{Path: "c", Op: diff.Removed, From: map[string]any{}},
+	}, diff.Compare(from, to))
+
+	assert.Equal(t, diff.Changes{{Path: ".", Op: diff.Changed, From: 1, To: 2}},
+		diff.Compare(1, 2))
+	assert.Empty(t, diff.Compare(nil, nil))
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestCompareCycles(t *testing.T) {
+	t.Parallel()
+
+	a, b := &node{Name: "a"}, &node{Name: "b"}
+	a.Next, b.Next = a, b
+
+	assert.Equal(t, diff.Changes{
+		{Path: "Name", Op: diff.Changed, From: "a", To: "b"},
+	}, diff.Compare(a, b))
+	assert.True(t, diff.Equal(a, a))
+
+	from, to := map[string]any{"x": 1}, map[string]any{"x": 2}
+	from["self"], to["self"] = from, to
+
+	list := []any{nil}
+	list[0] = list
+
+	assert.Equal(t, []string{"x"}, paths(diff.Compare(from, to)))
+	assert.Empty(t, diff.Compare(list, list))
+}
+
+func TestCompareIgnore(t *testing.T) {
+	t.Parallel()
+
+	from := map[string]any{
+		"metadata": map[string]any{"name": "a", "annotations": map[string]any{
+			"generated": "1",
+		}},
+		"items": []any{map[string]any{"status": "ok", "name": "x"}},
+	}
+	to := map[string]any{
+		"metadata": map[string]any{"name": "b", "annotations": map[string]any{
+			"generated": "2",
+		}},
+		"items": []any{map[string]any{"status": "failed", "name": "y"}},
+	}
+
+	changes := diff.Compare(from, to,
+		diff.WithIgnore("metadata.annotations", "items[*].status"))
+	assert.Equal(t, []string{"items[0].name", "metadata.name"}, paths(changes))
+}
+
+func TestChangesJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(diff.Compare(
+		map[string]int{"a": 1}, map[string]int{"a": 2, "b": 3}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"path": "a", "op": "change", "from": 1, "to": 2},
+		{"path": "b", "op": "add", "to": 3}
+	]`, string(data))
+}
+
+func paths(changes diff.Changes) []string {
+	result := make([]string, len(changes))
+	for i, change := range changes {
+		result[i] = change.Path
+	}
+
+	return result
+}
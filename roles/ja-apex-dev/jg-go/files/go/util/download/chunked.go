@@ -0,0 +1,122 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// chunked fetches a resource of the given size with concurrent range
+// requests written in place into a preallocated partial file. Chunked
+// downloads are not resumable, so a failure discards the partial file.
+func (d *Downloader) chunked(
+	ctx context.Context, req Request, size int64, result *Result,
+) error {
+	file, err := os.OpenFile(partPath(req.Dest),
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	err = d.fill(ctx, req.URL, chunkedFile{file: file, size: size}, result)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("download: %w", closeErr)
+	}
+
+	if err != nil {
+		return joinCleanup(err, req.Dest)
+	}
+
+	return nil
+}
+
+// chunkedFile is the partial file of a chunked download.
+type chunkedFile struct {
+	file *os.File
+	size int64
+}
+
+// fill runs the chunk requests, at most d.concurrency at a time, and
+// returns the first failure.
+func (d *Downloader) fill(
+	ctx context.Context, url string, part chunkedFile, r *Result,
+) error {
+	file, size := part.file, part.size
+	if err := file.Truncate(size); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg      sync.WaitGroup
+		written atomic.Int64
+		slots   = make(chan struct{}, d.concurrency)
+	)
+
+	r.Chunks = int((size + d.chunkSize - 1) / d.chunkSize)
+
+	for i := range r.Chunks {
+		start := int64(i) * d.chunkSize
+		end := min(start+d.chunkSize, size) - 1
+
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			n, err := d.fetchChunk(ctx, url, io.NewOffsetWriter(file, start),
+				[2]int64{start, end})
+			written.Add(n)
+
+			if err != nil {
+				cancel(err)
+			}
+		})
+	}
+
+	wg.Wait()
+	r.Bytes = written.Load()
+
+	return context.Cause(ctx)
+}
+
+// fetchChunk copies the inclusive byte span of the resource into w.
+func (d *Downloader) fetchChunk(
+	ctx context.Context, url string, w io.Writer, span [2]int64,
+) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("download: %w", err)
+	}
+
+	resp, err := d.get(ctx, url, func(r *http.Request) {
+		r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", span[0], span[1]))
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	partial := resp.StatusCode == http.StatusPartialContent
+	if !partial || !startsAt(resp, span[0]) {
+		return 0, fmt.Errorf("%w: %s for range %d-%d", ErrStatus, resp.Status,
+			span[0], span[1])
+	}
+
+	want := span[1] - span[0] + 1
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, want))
+	if err == nil && n < want {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return n, fmt.Errorf("download: chunk %d-%d: %w", span[0], span[1], err)
+	}
+
+	return n, nil
+}
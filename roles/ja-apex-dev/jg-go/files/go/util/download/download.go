@@ -0,0 +1,348 @@
+// Package download fetches URLs to disk. Every download must declare the
+// SHA-256 digest of its content, which is verified before the file appears
+// at its destination:
+//
+//	res, err := download.Fetch(ctx, download.Request{
+//		URL:    "https://go.dev/dl/go1.22.3.linux-amd64.tar.gz",
+//		Dest:   "/var/cache/go1.22.3.tar.gz",
+//		SHA256: "8920ea52...",
+//	})
+//
+// A destination already holding the expected content is not fetched again.
+// Data is first written to Dest+".part". Interrupted downloads resume with
+// HTTP range requests, validated with the ETag saved next to the partial
+// file so that a changed resource restarts from scratch. Large files are
+// fetched in concurrent chunks when the server supports ranges.
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"example.com/go-template/util/hashx"
+	"example.com/go-template/util/httpx"
+)
+
+// Default downloader settings.
+const (
+	DefaultConcurrency = 4
+	DefaultChunkSize   = 8 << 20
+)
+
+var (
+	// ErrChecksumRequired is returned for requests without a digest.
+	ErrChecksumRequired = errors.New("download: SHA256 checksum is required")
+	// ErrStatus is returned for unexpected HTTP response statuses.
+	ErrStatus = errors.New("download: unexpected status")
+)
+
+// Request describes a file to fetch.
+type Request struct {
+	// URL is the location of the file.
+	URL string
+	// Dest is the path the verified file is moved to.
+	Dest string
+	// SHA256 is the hex-encoded digest of the content.
+	SHA256 string
+}
+
+// Result describes a completed download.
+type Result struct {
+	// Path is the destination of the file.
+	Path string
+	// Bytes is the amount of data transferred by this call.
+	Bytes int64
+	// Cached reports that the destination already matched the checksum.
+	Cached bool
+	// Resumed reports that a partial download was continued.
+	Resumed bool
+	// Chunks is the number of concurrent range requests used, or zero for
+	// a sequential download.
+	Chunks int
+}
+
+// Option configures New.
+type Option func(*Downloader)
+
+// WithClient sets the HTTP client. The default is an httpx client without
+// an overall timeout, as downloads may take long; bound them through the
+// context instead.
+func WithClient(client *http.Client) Option {
+	return func(d *Downloader) {
+		d.client = client
+	}
+}
+
+// WithConcurrency sets the number of concurrent chunk requests. One
+// disables chunked downloads.
+func WithConcurrency(n int) Option {
+	return func(d *Downloader) {
+		d.concurrency = max(n, 1)
+	}
+}
+
+// WithChunkSize sets the size of the chunks of concurrent downloads. Files
+// no larger than one chunk are fetched sequentially.
+func WithChunkSize(size int64) Option {
+	return func(d *Downloader) {
+		d.chunkSize = max(size, 1)
+	}
+}
+
+// Downloader fetches files. It is safe for concurrent use, provided that
+// concurrent requests use distinct destinations.
+type Downloader struct {
+	client      *http.Client
+	concurrency int
+	chunkSize   int64
+}
+
+// New returns a downloader.
+func New(opts ...Option) *Downloader {
+	d := &Downloader{
+		concurrency: DefaultConcurrency,
+		chunkSize:   DefaultChunkSize,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if d.client == nil {
+		d.client = httpx.NewClient(httpx.WithTimeout(0))
+	}
+
+	return d
+}
+
+var defaultDownloader = New()
+
+// Fetch downloads req with the default downloader.
+func Fetch(ctx context.Context, req Request) (Result, error) {
+	return defaultDownloader.Fetch(ctx, req)
+}
+
+// Fetch downloads req.URL to req.Dest unless it already holds the expected
+// content. A partial file failing verification is discarded, so the next
+// attempt starts over.
+func (d *Downloader) Fetch(ctx context.Context, req Request) (Result, error) {
+	if req.SHA256 == "" {
+		return Result{}, ErrChecksumRequired
+	}
+
+	result := Result{Path: req.Dest}
+
+	if hashx.VerifyFile(req.Dest, req.SHA256) == nil {
+		result.Cached = true
+
+		return result, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(req.Dest), 0o750); err != nil {
+		return Result{}, fmt.Errorf("download: %w", err)
+	}
+
+	if err := d.transfer(ctx, req, &result); err != nil {
+		return Result{}, err
+	}
+
+	return result, finish(req)
+}
+
+// transfer fills the partial file, resuming, chunking or starting over.
+func (d *Downloader) transfer(
+	ctx context.Context, req Request, result *Result,
+) error {
+	part := partPath(req.Dest)
+
+	etag, _ := os.ReadFile(etagPath(req.Dest))
+	if info, err := os.Stat(part); err == nil && len(etag) > 0 {
+		return d.sequential(ctx, req, resumeState{
+			offset: info.Size(), etag: string(etag), result: result,
+		})
+	}
+
+	size, ok := d.probe(ctx, req.URL)
+	if ok && d.concurrency > 1 && size > d.chunkSize {
+		return d.chunked(ctx, req, size, result)
+	}
+
+	return d.sequential(ctx, req, resumeState{result: result})
+}
+
+// probe returns the size of the resource if it can be fetched in ranges.
+func (d *Downloader) probe(ctx context.Context, url string) (int64, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, false
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+
+	ranges := resp.Header.Get("Accept-Ranges") == "bytes"
+
+	return resp.ContentLength, resp.StatusCode == http.StatusOK && ranges &&
+		resp.ContentLength > 0
+}
+
+// resumeState is the starting point of a sequential download.
+type resumeState struct {
+	offset int64
+	etag   string
+	result *Result
+}
+
+// sequential streams the resource into the partial file, continuing from
+// offset when the server still serves the version tagged etag.
+func (d *Downloader) sequential(
+	ctx context.Context, req Request, t resumeState,
+) error {
+	resp, err := d.get(ctx, req.URL, func(r *http.Request) {
+		if t.offset > 0 {
+			r.Header.Set("Range", fmt.Sprintf("bytes=%d-", t.offset))
+			r.Header.Set("If-Range", t.etag)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if !startsAt(resp, t.offset) {
+			return fmt.Errorf("%w: range %s, want offset %d", ErrStatus,
+				resp.Header.Get("Content-Range"), t.offset)
+		}
+
+		flags |= os.O_APPEND
+		t.result.Resumed = true
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is complete; verification has the last word.
+		return nil
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+	default:
+		return fmt.Errorf("%w: %s", ErrStatus, resp.Status)
+	}
+
+	return writePart(req.Dest, flags, resp, t.result)
+}
+
+// startsAt reports whether the partial response resp begins at offset.
+func startsAt(resp *http.Response, offset int64) bool {
+	var start int64
+
+	_, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start)
+
+	return err == nil && start == offset
+}
+
+// writePart saves the validator of resp, so that an interruption can be
+// resumed, then appends or writes its body to the partial file.
+func writePart(dest string, flags int, resp *http.Response, r *Result) error {
+	if err := saveETag(dest, resp.Header.Get("ETag")); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(partPath(dest), flags, 0o600)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	n, err := io.Copy(file, resp.Body)
+	r.Bytes += n
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	return nil
+}
+
+// get sends a GET request adjusted by prepare.
+func (d *Downloader) get(
+	ctx context.Context, url string, prepare func(*http.Request),
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+
+	prepare(req)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+
+	return resp, nil
+}
+
+// saveETag records a strong validator of the partial file. Weak ETags
+// cannot validate byte ranges and are not kept.
+func saveETag(dest, etag string) error {
+	path := etagPath(dest)
+	if etag == "" || etag[0] != '"' {
+		return removeIfExists(path)
+	}
+
+	if err := os.WriteFile(path, []byte(etag), 0o600); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	return nil
+}
+
+// finish verifies the partial file and moves it to the destination.
+func finish(req Request) error {
+	part := partPath(req.Dest)
+
+	if err := hashx.VerifyFile(part, req.SHA256); err != nil {
+		return joinCleanup(err, req.Dest)
+	}
+
+	if err := os.Rename(part, req.Dest); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	return removeIfExists(etagPath(req.Dest))
+}
+
+// joinCleanup discards the partial file of dest and its validator after
+// err, reporting failures to do so along with err.
+func joinCleanup(err error, dest string) error {
+	return errors.Join(err, removeIfExists(partPath(dest)),
+		removeIfExists(etagPath(dest)))
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	return nil
+}
+
+func partPath(dest string) string {
+	return dest + ".part"
+}
+
+func etagPath(dest string) string {
+	return dest + ".part.etag"
+}
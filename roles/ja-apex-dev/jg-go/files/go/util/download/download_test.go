@@ -0,0 +1,155 @@
+package download_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/download"
+	"example.com/go-template/util/hashx"
+)
+
+// server serves content with range support under the given ETag and
+// counts GET requests.
+func server(t *testing.T, content []byte, etag string) (string, *atomic.Int32) {
+	t.Helper()
+
+	var gets atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				gets.Add(1)
+			}
+
+			w.Header().Set("ETag", etag)
+			http.ServeContent(w, r, "file", time.Time{},
+				bytes.NewReader(content))
+		}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL, &gets
+}
+
+func payload(size int) ([]byte, string) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), size/16)
+	sum := sha256.Sum256(content)
+
+	return content, hex.EncodeToString(sum[:])
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	content, sum := payload(4096)
+	url, gets := server(t, content, `"v1"`)
+	dest := filepath.Join(t.TempDir(), "nested", "file.bin")
+	d := download.New(download.WithConcurrency(1))
+
+	res, err := d.Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest, SHA256: sum})
+	require.NoError(t, err)
+	assert.Equal(t, download.Result{Path: dest, Bytes: 4096}, res)
+	require.NoError(t, hashx.VerifyFile(dest, sum))
+	assert.NoFileExists(t, dest+".part")
+	assert.NoFileExists(t, dest+".part.etag")
+
+	res, err = d.Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest, SHA256: sum})
+	require.NoError(t, err)
+	assert.True(t, res.Cached)
+	assert.Equal(t, int32(1), gets.Load())
+}
+
+func TestFetchChecksum(t *testing.T) {
+	t.Parallel()
+
+	content, _ := payload(64)
+	url, _ := server(t, content, `"v1"`)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+
+	_, err := download.Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest})
+	require.ErrorIs(t, err, download.ErrChecksumRequired)
+
+	_, err = download.Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest, SHA256: hashx.SHA256(nil)})
+	require.ErrorIs(t, err, hashx.ErrChecksumMismatch)
+	assert.NoFileExists(t, dest)
+	assert.NoFileExists(t, dest+".part", "the corrupt part is discarded")
+}
+
+func TestFetchResume(t *testing.T) {
+	t.Parallel()
+
+	content, sum := payload(1024)
+	url, _ := server(t, content, `"v1"`)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+
+	require.NoError(t, os.WriteFile(dest+".part", content[:300], 0o600))
+	require.NoError(t, os.WriteFile(dest+".part.etag", []byte(`"v1"`), 0o600))
+
+	res, err := download.New().Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest, SHA256: sum})
+	require.NoError(t, err)
+	assert.True(t, res.Resumed)
+	assert.Equal(t, int64(1024-300), res.Bytes)
+	require.NoError(t, hashx.VerifyFile(dest, sum))
+}
+
+func TestFetchResumeChangedResource(t *testing.T) {
+	t.Parallel()
+
+	content, sum := payload(1024)
+	url, _ := server(t, content, `"v2"`)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+
+	require.NoError(t, os.WriteFile(dest+".part", []byte("stale"), 0o600))
+	require.NoError(t, os.WriteFile(dest+".part.etag", []byte(`"v1"`), 0o600))
+
+	res, err := download.New().Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest, SHA256: sum})
+	require.NoError(t, err)
+	assert.False(t, res.Resumed, "the If-Range validator no longer matches")
+	assert.Equal(t, int64(1024), res.Bytes)
+	require.NoError(t, hashx.VerifyFile(dest, sum))
+}
+
+func TestFetchChunked(t *testing.T) {
+	t.Parallel()
+
+	content, sum := payload(10000)
+	url, gets := server(t, content, `"v1"`)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+
+	d := download.New(download.WithConcurrency(3), download.WithChunkSize(1000))
+	res, err := d.Fetch(t.Context(),
+		download.Request{URL: url, Dest: dest, SHA256: sum})
+	require.NoError(t, err)
+	assert.Equal(t, 10, res.Chunks)
+	assert.Equal(t, int64(len(content)), res.Bytes)
+	assert.Equal(t, int32(10), gets.Load())
+	require.NoError(t, hashx.VerifyFile(dest, sum))
+}
+
+func TestFetchStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	_, err := download.Fetch(t.Context(), download.Request{
+		URL: srv.URL, Dest: filepath.Join(t.TempDir(), "f"), SHA256: "00",
+	})
+	require.ErrorIs(t, err, download.ErrStatus)
+}
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidDuration is returned by ParseHumanDuration for malformed input.
+var ErrInvalidDuration = errors.New("util: invalid duration")
+
+// Day and Week extend the time package units. They assume days without
+// daylight saving transitions.
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// DefaultDurationUnits is the number of units printed by HumanDuration.
+const DefaultDurationUnits = 2
+
+var humanUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"d", Day},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+var parseUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "us": time.Microsecond, "µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second, "sec": time.Second, "secs": time.Second,
+	"second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+	"hour": time.Hour, "hours": time.Hour,
+	"d": Day, "day": Day, "days": Day,
+	"w": Week, "wk": Week, "wks": Week, "week": Week, "weeks": Week,
+}
+
+// HumanDuration formats d with its two most significant units, such as
+// "2h 15m" or "3d 4h". Durations below a second use time.Duration syntax.
+func HumanDuration(d time.Duration) string {
+	return HumanDurationUnits(d, DefaultDurationUnits)
+}
+
+// HumanDurationUnits is like HumanDuration with at most units components,
+// the remainder being truncated.
+func HumanDurationUnits(d time.Duration, units int) string {
+	if d < 0 {
+		// Negating math.MinInt64 overflows, so compute the magnitude in
+		// unsigned arithmetic.
+		return "-" + humanMagnitude(uint64(-(d+1))+1, units)
+	}
+
+	return humanMagnitude(uint64(d), units)
+}
+
+func humanMagnitude(n uint64, units int) string {
+	if n < uint64(time.Second) {
+		return time.Duration(n).String()
+	}
+
+	parts := make([]string, 0, len(humanUnits))
+	remaining := max(units, 1)
+
+	for _, unit := range humanUnits {
+		size := uint64(unit.size)
+		if remaining == 0 || (len(parts) == 0 && n < size) {
+			continue
+		}
+
+		// Once started, every unit uses a slot even when zero, so 1d 5m is
+		// printed as "1d" rather than skipping the hours.
+		remaining--
+
+		if count := n / size; count > 0 {
+			parts = append(parts, strconv.FormatUint(count, 10)+unit.suffix)
+		}
+
+		n %= size
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ParseHumanDuration parses durations such as "1d 4h", "90s", "2 weeks",
+// "1 hour, 30 minutes" or "1h30m". Numbers may be fractional and a leading
+// minus sign negates the total.
+func ParseHumanDuration(s string) (time.Duration, error) {
+	input := strings.TrimSpace(strings.ToLower(s))
+
+	negative := strings.HasPrefix(input, "-")
+	input = strings.TrimPrefix(input, "-")
+
+	var (
+		total float64
+		parts int
+	)
+
+	for input = skipSeparators(input); input != ""; parts++ {
+		value, unit, rest, err := nextComponent(input)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", err, s)
+		}
+
+		// MaxInt64 rounds up to 2^63 as a float, which already overflows.
+		total += value * float64(unit)
+		if total >= math.MaxInt64 {
+			return 0, fmt.Errorf("%w: %q overflows", ErrInvalidDuration, s)
+		}
+
+		input = skipSeparators(rest)
+	}
+
+	if parts == 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+
+	if negative {
+		total = -total
+	}
+
+	return time.Duration(total), nil
+}
+
+// nextComponent parses a "<number> <unit>" pair.
+func nextComponent(input string) (float64, time.Duration, string, error) {
+	end := strings.IndexFunc(input, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if end < 0 {
+		end = len(input)
+	}
+
+	value, err := strconv.ParseFloat(input[:end], 64)
+	if err != nil || end == 0 {
+		return 0, 0, "", ErrInvalidDuration
+	}
+
+	input = strings.TrimLeft(input[end:], " ")
+
+	end = strings.IndexFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end < 0 {
+		end = len(input)
+	}
+
+	unit, ok := parseUnits[input[:end]]
+	if !ok {
+		return 0, 0, "", ErrInvalidDuration
+	}
+
+	return value, unit, input[end:], nil
+}
+
+// skipSeparators drops spaces, commas and the word "and" between
+// components.
+func skipSeparators(input string) string {
+	for {
+		trimmed := strings.TrimLeft(input, " \t,")
+		if rest, ok := strings.CutPrefix(trimmed, "and "); ok {
+			trimmed = rest
+		}
+
+		if trimmed == input {
+			return input
+		}
+
+		input = trimmed
+	}
+}
@@ -0,0 +1,103 @@
+package util_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+func TestHumanDuration(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[time.Duration]string{
+		0:                                "0s",
+		1500 * time.Millisecond:          "1s",
+		250 * time.Millisecond:           "250ms",
+		90 * time.Second:                 "1m 30s",
+		2*time.Hour + 15*time.Minute + 9: "2h 15m",
+		util.Day + 5*time.Minute:         "1d",
+		3*util.Day + 4*time.Hour:         "3d 4h",
+		-45 * time.Minute:                "-45m",
+	} {
+		assert.Equal(t, want, util.HumanDuration(input), input)
+	}
+
+	assert.Equal(t, "1d 5m",
+		util.HumanDurationUnits(util.Day+5*time.Minute, 3))
+	assert.Equal(t, "-106751d 23h",
+		util.HumanDuration(math.MinInt64))
+}
+
+func TestParseHumanDuration(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]time.Duration{
+		"90s":                90 * time.Second,
+		"1d 4h":              util.Day + 4*time.Hour,
+		"2 weeks":            2 * util.Week,
+		"1h30m":              90 * time.Minute,
+		"1 hour, 30 minutes": 90 * time.Minute,
+		"1 Day and 2 hours":  26 * time.Hour,
+		"1.5h":               90 * time.Minute,
+		"-2m":                -2 * time.Minute,
+		"  250ms ":           250 * time.Millisecond,
+		"1w 1d 1h 1m 1s 1ms 1ns": util.Week + util.Day + time.Hour +
+			time.Minute + time.Second + time.Millisecond + 1,
+	} {
+		got, err := util.ParseHumanDuration(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{
+		"", "-", "5", "h", "3 fortnights", "1h -2m", "1..5s", "1h and",
+		"9999999999 weeks", "9223372036854775807ns",
+	} {
+		_, err := util.ParseHumanDuration(input)
+		require.ErrorIs(t, err, util.ErrInvalidDuration, input)
+	}
+}
+
+func TestHumanDurationRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []time.Duration{
+		time.Second, 61 * time.Minute, 2*util.Day + time.Hour,
+	} {
+		got, err := util.ParseHumanDuration(util.HumanDuration(d))
+		require.NoError(t, err)
+		assert.Equal(t, d, got)
+	}
+}
+
+func FuzzParseHumanDuration(f *testing.F) {
+	for _, seed := range []string{
+		"1d 4h", "90s", "2 weeks", "1 hour, 30 minutes and 5s", "1h30m",
+		"-1.5h", "0.000000001ns", "1e3s", "", "--1s", "106751d 23h 47m 17s",
+		"1µs", "9223372036854775807ns",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := util.ParseHumanDuration(s)
+		if err != nil {
+			require.ErrorIs(t, err, util.ErrInvalidDuration)
+			assert.Zero(t, d)
+
+			return
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(s), "-") {
+			assert.LessOrEqual(t, d, time.Duration(0))
+		} else {
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+		}
+	})
+}
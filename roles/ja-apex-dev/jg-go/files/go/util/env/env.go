@@ -0,0 +1,132 @@
+// Package env parses environment variables into typed values.
+//
+// Slices are comma separated and every item is trimmed.
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotSet is returned by Lookup for unset variables.
+	ErrNotSet = errors.New("env: variable not set")
+	// ErrUnsupportedType is returned for destinations without a parser.
+	ErrUnsupportedType = errors.New("env: unsupported type")
+)
+
+// Value lists the types supported by Get and Lookup.
+type Value interface {
+	string | int | int64 | uint | float64 | bool | time.Duration |
+		*url.URL | []string | []int
+}
+
+// Get returns the parsed variable, or fallback when it is unset or invalid.
+func Get[T Value](key string, fallback T) T {
+	value, err := Lookup[T](key)
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// Lookup returns the parsed variable, ErrNotSet when it is unset, or the
+// parsing error.
+func Lookup[T Value](key string) (T, error) {
+	var value T
+
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		return value, fmt.Errorf("%w: %s", ErrNotSet, key)
+	}
+
+	if err := Parse(raw, &value); err != nil {
+		return value, fmt.Errorf("env: %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Parse parses raw into dst, which must point to one of the Value types.
+// dst is left unchanged when raw is invalid.
+//
+//nolint:cyclop // Flat dispatch over the supported types.
+func Parse(raw string, dst any) error {
+	trimmed := strings.TrimSpace(raw)
+
+	switch target := dst.(type) {
+	case *string:
+		*target = raw
+	case *int:
+		return set(target)(strconv.Atoi(trimmed))
+	case *int64:
+		return set(target)(strconv.ParseInt(trimmed, 0, 64))
+	case *uint:
+		n, err := strconv.ParseUint(trimmed, 0, 0)
+
+		return set(target)(uint(n), err)
+	case *float64:
+		return set(target)(strconv.ParseFloat(trimmed, 64))
+	case *bool:
+		return set(target)(strconv.ParseBool(trimmed))
+	case *time.Duration:
+		return set(target)(time.ParseDuration(trimmed))
+	case **url.URL:
+		return set(target)(url.Parse(trimmed))
+	case *[]string:
+		*target = split(raw)
+	case *[]int:
+		return set(target)(parseInts(raw))
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, dst)
+	}
+
+	return nil
+}
+
+// set returns a function storing a parsed value into dst unless parsing
+// failed, for the two results of the parsing functions.
+func set[T any](dst *T) func(T, error) error {
+	return func(value T, err error) error {
+		if err == nil {
+			*dst = value
+		}
+
+		return err
+	}
+}
+
+func split(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return []string{}
+	}
+
+	parts := strings.Split(raw, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+
+	return parts
+}
+
+func parseInts(raw string) ([]int, error) {
+	parts := split(raw)
+	result := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, n)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package env_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/env"
+)
+
+//nolint:paralleltest // Modifies the process environment.
+func TestGet(t *testing.T) {
+	t.Setenv("ENV_TEST_PORT", " 8080 ")
+	t.Setenv("ENV_TEST_DEBUG", "true")
+	t.Setenv("ENV_TEST_TIMEOUT", "1m30s")
+	t.Setenv("ENV_TEST_HOSTS", "a, b ,c")
+	t.Setenv("ENV_TEST_IDS", "1,2")
+	t.Setenv("ENV_TEST_URL", "https://example.com/x")
+	t.Setenv("ENV_TEST_BAD", "nope")
+
+	assert.Equal(t, 8080, env.Get("ENV_TEST_PORT", 0))
+	assert.True(t, env.Get("ENV_TEST_DEBUG", false))
+	assert.Equal(t, 90*time.Second, env.Get("ENV_TEST_TIMEOUT", time.Second))
+	assert.Equal(t, []string{"a", "b", "c"},
+		env.Get("ENV_TEST_HOSTS", []string{}))
+	assert.Equal(t, []int{1, 2}, env.Get[[]int]("ENV_TEST_IDS", nil))
+	assert.Equal(t, "example.com",
+		env.Get[*url.URL]("ENV_TEST_URL", nil).Host)
+
+	assert.Equal(t, "fallback", env.Get("ENV_TEST_UNSET", "fallback"))
+	assert.Equal(t, 3, env.Get("ENV_TEST_BAD", 3))
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestLookup(t *testing.T) {
+	t.Setenv("ENV_TEST_BAD", "nope")
+
+	_, err := env.Lookup[int]("ENV_TEST_UNSET")
+	require.ErrorIs(t, err, env.ErrNotSet)
+
+	_, err = env.Lookup[bool]("ENV_TEST_BAD")
+	require.ErrorContains(t, err, "ENV_TEST_BAD")
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	port, ids := 8080, []int{1}
+	require.Error(t, env.Parse("http", &port))
+	require.Error(t, env.Parse("1,x", &ids))
+	assert.Equal(t, 8080, port, "invalid values keep the current one")
+	assert.Equal(t, []int{1}, ids)
+}
+
+func TestParseUnsupported(t *testing.T) {
+	t.Parallel()
+
+	var value complex128
+	require.ErrorIs(t, env.Parse("1", &value), env.ErrUnsupportedType)
+}
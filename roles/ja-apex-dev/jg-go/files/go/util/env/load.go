@@ -0,0 +1,102 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"example.com/go-template/util"
+)
+
+// ErrInvalidTarget is returned when Load is not given a struct pointer.
+var ErrInvalidTarget = errors.New("env: target must be a struct pointer")
+
+// LoadError reports every missing and invalid variable found by Load.
+type LoadError struct {
+	Missing []string
+	Invalid []error
+}
+
+// Error implements error.
+func (e *LoadError) Error() string {
+	parts := make([]string, 0, len(e.Invalid)+1)
+	if len(e.Missing) > 0 {
+		parts = append(parts,
+			"missing required variables "+util.JoinStrings(e.Missing...))
+	}
+
+	for _, err := range e.Invalid {
+		parts = append(parts, err.Error())
+	}
+
+	return "env: " + strings.Join(parts, "; ")
+}
+
+// Unwrap exposes the parsing errors to errors.Is and errors.As.
+func (e *LoadError) Unwrap() []error {
+	return e.Invalid
+}
+
+// Load populates the struct fields tagged `env:"NAME"` or
+// `env:"NAME,required"` from the environment. Unset and invalid fields keep
+// their current value. All problems are reported at once as a *LoadError.
+func Load(dst any) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() ||
+		target.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	result := &LoadError{}
+	collect(target.Elem(), result)
+
+	if len(result.Missing) == 0 && len(result.Invalid) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// MustLoad is like Load but panics, typically at startup.
+func MustLoad(dst any) {
+	if err := Load(dst); err != nil {
+		panic(err)
+	}
+}
+
+// collect walks the struct fields, descending into untagged nested structs.
+func collect(v reflect.Value, result *LoadError) {
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("env")
+		if ok {
+			loadField(v.Field(i), tag, result)
+		} else if sf.Type.Kind() == reflect.Struct {
+			collect(v.Field(i), result)
+		}
+	}
+}
+
+// loadField assigns a single tagged field, recording any problem.
+func loadField(field reflect.Value, tag string, result *LoadError) {
+	name, options, _ := strings.Cut(tag, ",")
+
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		if options == "required" {
+			result.Missing = append(result.Missing, name)
+		}
+
+		return
+	}
+
+	if err := Parse(raw, field.Addr().Interface()); err != nil {
+		result.Invalid = append(result.Invalid, fmt.Errorf("%s: %w", name, err))
+	}
+}
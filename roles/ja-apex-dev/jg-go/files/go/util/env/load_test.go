@@ -0,0 +1,67 @@
+package env_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/env"
+)
+
+type settings struct {
+	Addr    string        `env:"ENV_LOAD_ADDR,required"`
+	Token   string        `env:"ENV_LOAD_TOKEN,required"`
+	Secret  string        `env:"ENV_LOAD_SECRET,required"`
+	Timeout time.Duration `env:"ENV_LOAD_TIMEOUT"`
+	Nested  struct {
+		Workers int `env:"ENV_LOAD_WORKERS"`
+	}
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestLoad(t *testing.T) {
+	t.Setenv("ENV_LOAD_ADDR", ":80")
+	t.Setenv("ENV_LOAD_TOKEN", "t")
+	t.Setenv("ENV_LOAD_SECRET", "s")
+	t.Setenv("ENV_LOAD_WORKERS", "4")
+
+	cfg := settings{Timeout: time.Second}
+	require.NoError(t, env.Load(&cfg))
+
+	assert.Equal(t, ":80", cfg.Addr)
+	assert.Equal(t, time.Second, cfg.Timeout)
+	assert.Equal(t, 4, cfg.Nested.Workers)
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestLoadReportsAllProblems(t *testing.T) {
+	t.Setenv("ENV_LOAD_ADDR", ":80")
+	t.Setenv("ENV_LOAD_TIMEOUT", "later")
+	t.Setenv("ENV_LOAD_WORKERS", "many")
+
+	cfg := settings{Timeout: time.Second}
+
+	err := env.Load(&cfg)
+	assert.Equal(t, time.Second, cfg.Timeout, "invalid values are ignored")
+
+	var loadErr *env.LoadError
+	require.ErrorAs(t, err, &loadErr)
+	assert.Equal(t,
+		[]string{"ENV_LOAD_TOKEN", "ENV_LOAD_SECRET"}, loadErr.Missing)
+	assert.Len(t, loadErr.Invalid, 2)
+
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(err, &numErr))
+	assert.Contains(t, err.Error(), "ENV_LOAD_TOKEN, ENV_LOAD_SECRET")
+
+	assert.Panics(t, func() { env.MustLoad(&cfg) })
+}
+
+func TestLoadInvalidTarget(t *testing.T) {
+	t.Parallel()
+	require.ErrorIs(t, env.Load(settings{}), env.ErrInvalidTarget)
+}
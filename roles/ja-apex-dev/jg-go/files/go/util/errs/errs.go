@@ -0,0 +1,203 @@
+// Package errs provides errors carrying machine-readable codes and the stack
+// of their construction, compatible with errors.Is and errors.As.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// Code classifies an error. Codes are errors themselves, so
+// errors.Is(err, errs.NotFound) matches any *Error with that code.
+type Code string
+
+// Common codes, named after their gRPC counterparts.
+const (
+	Unknown          Code = "unknown"
+	InvalidArgument  Code = "invalid_argument"
+	NotFound         Code = "not_found"
+	AlreadyExists    Code = "already_exists"
+	PermissionDenied Code = "permission_denied"
+	Unauthenticated  Code = "unauthenticated"
+	FailedPrecond    Code = "failed_precondition"
+	Unavailable      Code = "unavailable"
+	DeadlineExceeded Code = "deadline_exceeded"
+	Internal         Code = "internal"
+)
+
+// Error implements error.
+func (c Code) Error() string {
+	return string(c)
+}
+
+const maxStackDepth = 32
+
+// Error is an error with a code, an optional cause and a captured stack.
+type Error struct {
+	Code    Code
+	Message string
+	Cause   error
+	stack   []uintptr
+}
+
+// New creates an error with the code and message, capturing the stack.
+func New(code Code, message string) *Error {
+	return newError(code, message, nil)
+}
+
+// Newf is like New with a formatted message.
+func Newf(code Code, format string, args ...any) *Error {
+	return newError(code, fmt.Sprintf(format, args...), nil)
+}
+
+// Wrap annotates err with the code and message, returning nil for a nil err.
+func Wrap(err error, code Code, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return newError(code, message, err)
+}
+
+// Wrapf is like Wrap with a formatted message.
+func Wrapf(err error, code Code, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return newError(code, fmt.Sprintf(format, args...), err)
+}
+
+func newError(code Code, message string, cause error) *Error {
+	var pcs [maxStackDepth]uintptr
+
+	// Skip runtime.Callers, newError and the exported constructor.
+	n := runtime.Callers(3, pcs[:])
+
+	return &Error{Code: code, Message: message, Cause: cause, stack: pcs[:n]}
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	switch {
+	case e.Cause == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Cause.Error()
+	default:
+		return e.Message + ": " + e.Cause.Error()
+	}
+}
+
+// Unwrap returns the cause.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches the error code, so that codes act as sentinels.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(Code)
+
+	return ok && code == e.Code
+}
+
+// StackTrace returns the frames captured on construction.
+func (e *Error) StackTrace() []runtime.Frame {
+	frames := runtime.CallersFrames(e.stack)
+	result := make([]runtime.Frame, 0, len(e.stack))
+
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+
+		if !more {
+			return result
+		}
+	}
+}
+
+// Format prints the stack trace for the %+v verb.
+func (e *Error) Format(state fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && state.Flag('+'):
+		_, _ = fmt.Fprintf(state, "[%s] %s", e.Code, e.Error())
+
+		for _, frame := range e.StackTrace() {
+			_, _ = fmt.Fprintf(state, "\n\t%s\n\t\t%s:%d",
+				frame.Function, frame.File, frame.Line)
+		}
+	case verb == 'q':
+		_, _ = fmt.Fprintf(state, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(state, e.Error())
+	}
+}
+
+// CodeOf returns the code of the outermost *Error in the chain, or Unknown.
+func CodeOf(err error) Code {
+	var target *Error
+	if errors.As(err, &target) {
+		return target.Code
+	}
+
+	return Unknown
+}
+
+// Multi aggregates several errors. The zero value is ready to use.
+type Multi struct {
+	errs []error
+}
+
+// Append records err unless it is nil, flattening nested Multi errors.
+// Only a *Multi itself is flattened; a wrapped one keeps its wrapper.
+func (m *Multi) Append(errs ...error) {
+	for _, err := range errs {
+		//nolint:errorlint // Unwrapping would drop the outer context.
+		nested, ok := err.(*Multi)
+
+		switch {
+		case err == nil:
+		case ok && nested != m:
+			m.errs = append(m.errs, nested.errs...)
+		default:
+			m.errs = append(m.errs, err)
+		}
+	}
+}
+
+// Len returns the number of recorded errors.
+func (m *Multi) Len() int {
+	return len(m.errs)
+}
+
+// ErrorOrNil returns m when it holds errors and nil otherwise, avoiding the
+// typed nil interface pitfall.
+func (m *Multi) ErrorOrNil() error {
+	if m == nil || len(m.errs) == 0 {
+		return nil
+	}
+
+	return m
+}
+
+// Error implements error.
+func (m *Multi) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+
+	parts := make([]string, 0, len(m.errs))
+	for _, err := range m.errs {
+		parts = append(parts, err.Error())
+	}
+
+	return fmt.Sprintf("%d errors: %s", len(m.errs), strings.Join(parts, "; "))
+}
+
+// Unwrap exposes the recorded errors to errors.Is and errors.As.
+func (m *Multi) Unwrap() []error {
+	return m.errs
+}
@@ -0,0 +1,80 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/errs"
+)
+
+func TestCodesAreSentinels(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("loading user: %w", errs.New(errs.NotFound, "no user"))
+
+	require.ErrorIs(t, err, errs.NotFound)
+	require.NotErrorIs(t, err, errs.Internal)
+	assert.Equal(t, errs.NotFound, errs.CodeOf(err))
+	assert.Equal(t, errs.Unknown, errs.CodeOf(errors.New("plain")))
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, errs.Wrap(nil, errs.Internal, "ignored"))
+
+	err := errs.Wrapf(fs.ErrNotExist, errs.NotFound, "open %s", "cfg.yml")
+	assert.Equal(t, "open cfg.yml: file does not exist", err.Error())
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorIs(t, err, errs.NotFound)
+
+	var target *errs.Error
+	require.ErrorAs(t, err, &target)
+	assert.Equal(t, fs.ErrNotExist, target.Cause)
+}
+
+func TestStackTrace(t *testing.T) {
+	t.Parallel()
+
+	err := errs.Newf(errs.Internal, "boom %d", 1)
+
+	frames := err.StackTrace()
+	require.NotEmpty(t, frames)
+	assert.Contains(t, frames[0].Function, "TestStackTrace")
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.Contains(t, verbose, "[internal] boom 1")
+	assert.Contains(t, verbose, "errs_test.go")
+	assert.Equal(t, "boom 1", fmt.Sprintf("%v", err))
+}
+
+func TestMulti(t *testing.T) {
+	t.Parallel()
+
+	var multi errs.Multi
+	require.NoError(t, multi.ErrorOrNil())
+
+	multi.Append(nil, fs.ErrNotExist)
+	assert.Equal(t, "file does not exist", multi.Error())
+
+	var nested errs.Multi
+	nested.Append(errs.New(errs.InvalidArgument, "bad port"), nil)
+	multi.Append(nested.ErrorOrNil())
+
+	err := multi.ErrorOrNil()
+	require.Error(t, err)
+	assert.Equal(t, 2, multi.Len())
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorIs(t, err, errs.InvalidArgument)
+	assert.Equal(t, "2 errors: file does not exist; bad port", err.Error())
+
+	var wrapped errs.Multi
+	wrapped.Append(fmt.Errorf("ctx: %w", err), fs.ErrClosed)
+	assert.Equal(t, 2, wrapped.Len(), "wrapped Multi errors are kept whole")
+	assert.Contains(t, wrapped.Error(), "ctx: 2 errors: ")
+}
@@ -0,0 +1,233 @@
+// Package execx runs external commands with captured output, exit codes,
+// timeouts, environment injection, optional sudo elevation and live output
+// logging.
+package execx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"example.com/go-template/util/breaker"
+)
+
+// ErrTimeout is returned when a command outlives the WithTimeout limit.
+var ErrTimeout = errors.New("execx: command timed out")
+
+// Result holds the outcome of a finished command.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// ExitError reports a command exiting with a non-zero status. The result
+// is still available to inspect the output.
+type ExitError struct {
+	Command string
+	Result  Result
+	Err     error
+}
+
+// Error includes the trimmed standard error, which usually explains the
+// failure.
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("execx: %s: exit status %d",
+		e.Command, e.Result.ExitCode)
+	if stderr := strings.TrimSpace(e.Result.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying *exec.ExitError.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Runner.
+type Option func(*Runner)
+
+// WithEnv adds "KEY=value" variables on top of the current environment.
+func WithEnv(vars ...string) Option {
+	return func(r *Runner) {
+		r.env = append(r.env, vars...)
+	}
+}
+
+// WithDir sets the working directory.
+func WithDir(dir string) Option {
+	return func(r *Runner) {
+		r.dir = dir
+	}
+}
+
+// WithTimeout kills commands running longer than timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.timeout = timeout
+	}
+}
+
+// WithStdin feeds stdin to commands.
+func WithStdin(stdin io.Reader) Option {
+	return func(r *Runner) {
+		r.stdin = stdin
+	}
+}
+
+// WithLogger streams output lines to logger while commands run, standard
+// output at debug level and standard error at warn level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Runner) {
+		r.logger = logger
+	}
+}
+
+// WithSudo runs commands through non-interactive sudo, failing instead of
+// prompting when a password would be needed. Variables from WithEnv are
+// passed through env(1) since sudo resets the environment.
+func WithSudo() Option {
+	return func(r *Runner) {
+		r.sudo = true
+	}
+}
+
+// WithBreaker guards commands with b, so that a failing tool or the
+// service behind it is not invoked again until the circuit closes.
+// Rejected commands fail with breaker.ErrOpen without being started.
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(r *Runner) {
+		r.breaker = b
+	}
+}
+
+// Runner executes commands with shared settings.
+type Runner struct {
+	env     []string
+	dir     string
+	timeout time.Duration
+	stdin   io.Reader
+	logger  *slog.Logger
+	sudo    bool
+	breaker *breaker.Breaker
+}
+
+// New returns a runner.
+func New(opts ...Option) *Runner {
+	r := &Runner{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run executes name with args using default settings.
+func Run(ctx context.Context, name string, args ...string) (Result, error) {
+	return New().Run(ctx, name, args...)
+}
+
+// Run executes name with args and waits for it. A non-zero exit status
+// yields an *ExitError alongside the result.
+func (r *Runner) Run(
+	ctx context.Context, name string, args ...string,
+) (Result, error) {
+	done, err := r.breaker.Allow()
+	if err != nil {
+		return Result{}, fmt.Errorf("execx: %s: %w", describe(name, args), err)
+	}
+
+	result, err := r.execute(ctx, name, args)
+	done(err)
+
+	return result, err
+}
+
+func (r *Runner) execute(
+	ctx context.Context, name string, args []string,
+) (Result, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeoutCause(ctx, r.timeout, ErrTimeout)
+		defer cancel()
+	}
+
+	name, args = r.argv(name, args)
+	command := exec.CommandContext(ctx, name, args...)
+	command.Dir = r.dir
+	command.Stdin = r.stdin
+
+	if !r.sudo && len(r.env) > 0 {
+		command.Env = append(os.Environ(), r.env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	outLog := r.stream(ctx, slog.LevelDebug, "stdout")
+	errLog := r.stream(ctx, slog.LevelWarn, "stderr")
+	command.Stdout = io.MultiWriter(&stdout, outLog)
+	command.Stderr = io.MultiWriter(&stderr, errLog)
+
+	start := time.Now()
+	err := command.Run()
+
+	outLog.Flush()
+	errLog.Flush()
+
+	result := Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: command.ProcessState.ExitCode(),
+		Duration: time.Since(start),
+	}
+
+	return result, r.wrap(ctx, describe(name, args), result, err)
+}
+
+// argv applies sudo elevation.
+func (r *Runner) argv(name string, args []string) (string, []string) {
+	if !r.sudo {
+		return name, args
+	}
+
+	argv := []string{"-n", "--"}
+	if len(r.env) > 0 {
+		argv = append(append(argv, "env"), r.env...)
+	}
+
+	return "sudo", append(append(argv, name), args...)
+}
+
+func (r *Runner) wrap(
+	ctx context.Context, command string, result Result, err error,
+) error {
+	var exitErr *exec.ExitError
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(context.Cause(ctx), ErrTimeout):
+		return fmt.Errorf("%w: %s after %s", ErrTimeout, command, r.timeout)
+	case ctx.Err() != nil:
+		return fmt.Errorf("execx: %s: %w", command, ctx.Err())
+	case errors.As(err, &exitErr):
+		return &ExitError{Command: command, Result: result, Err: err}
+	default:
+		return fmt.Errorf("execx: %s: %w", command, err)
+	}
+}
+
+func describe(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
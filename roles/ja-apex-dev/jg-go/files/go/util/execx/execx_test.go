@@ -0,0 +1,132 @@
+package execx_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/breaker"
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/log"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	result, err := execx.Run(t.Context(), "sh", "-c", "echo out; echo err >&2")
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", result.Stdout)
+	assert.Equal(t, "err\n", result.Stderr)
+	assert.Zero(t, result.ExitCode)
+	assert.Positive(t, result.Duration)
+}
+
+func TestRunExitError(t *testing.T) {
+	t.Parallel()
+
+	result, err := execx.Run(t.Context(), "sh", "-c", "echo nope >&2; exit 3")
+
+	var exitErr *execx.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, 3, exitErr.Result.ExitCode)
+	require.EqualError(t, err,
+		"execx: sh -c echo nope >&2; exit 3: exit status 3: nope")
+
+	var osExit *exec.ExitError
+	require.ErrorAs(t, err, &osExit)
+
+	_, err = execx.Run(t.Context(), "definitely-not-a-command")
+	require.ErrorIs(t, err, exec.ErrNotFound)
+}
+
+func TestRunOptions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runner := execx.New(
+		execx.WithDir(dir),
+		execx.WithEnv("EXECX_TEST=injected"),
+		execx.WithStdin(strings.NewReader("piped")),
+	)
+
+	result, err := runner.Run(t.Context(),
+		"sh", "-c", `pwd; echo "$EXECX_TEST"; cat`)
+	require.NoError(t, err)
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	assert.Equal(t, resolved+"\ninjected\npiped", result.Stdout)
+}
+
+func TestRunTimeout(t *testing.T) {
+	t.Parallel()
+
+	runner := execx.New(execx.WithTimeout(50 * time.Millisecond))
+
+	start := time.Now()
+	_, err := runner.Run(t.Context(), "sleep", "5")
+	require.ErrorIs(t, err, execx.ErrTimeout)
+	assert.Less(t, time.Since(start), 4*time.Second)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = execx.Run(ctx, "sleep", "5")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunLogger(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	runner := execx.New(execx.WithLogger(sink.Logger()))
+
+	_, err := runner.Run(t.Context(), "sh", "-c",
+		`printf 'one\ntwo\n'; printf 'warn' >&2`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"one", "two", "warn"}, sink.Messages())
+
+	entries := sink.Entries()
+	assert.Equal(t, "stdout", entries[0].Attrs["stream"])
+	assert.Equal(t, "stderr", entries[2].Attrs["stream"])
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestWithSudo(t *testing.T) {
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "sudo"),
+		[]byte("#!/bin/sh\nprintf '%s\\n' \"$*\"\n"), 0o700))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result, err := execx.New(execx.WithSudo()).Run(t.Context(), "id", "-u")
+	require.NoError(t, err)
+	assert.Equal(t, "-n -- id -u\n", result.Stdout)
+
+	result, err = execx.New(execx.WithSudo(), execx.WithEnv("A=1")).Run(
+		t.Context(), "id")
+	require.NoError(t, err)
+	assert.Equal(t, "-n -- env A=1 id\n", result.Stdout)
+}
+
+func TestWithBreaker(t *testing.T) {
+	t.Parallel()
+
+	runner := execx.New(execx.WithBreaker(breaker.New(
+		breaker.WithThreshold(1), breaker.WithCooldown(time.Hour))))
+
+	_, err := runner.Run(t.Context(), "false")
+	require.Error(t, err)
+
+	_, err = runner.Run(t.Context(), "true")
+	require.ErrorIs(t, err, breaker.ErrOpen)
+	assert.EqualError(t, err, "execx: true: breaker: circuit open")
+}
@@ -0,0 +1,57 @@
+package execx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+)
+
+// lineLogger logs every complete line written to it. The zero logger
+// discards everything.
+type lineLogger struct {
+	ctx    context.Context //nolint:containedctx // Scoped to one command.
+	logger *slog.Logger
+	level  slog.Level
+	stream string
+	buf    []byte
+}
+
+func (r *Runner) stream(
+	ctx context.Context, level slog.Level, stream string,
+) *lineLogger {
+	return &lineLogger{ctx: ctx, logger: r.logger, level: level, stream: stream}
+}
+
+// Write implements io.Writer.
+func (l *lineLogger) Write(p []byte) (int, error) {
+	if l.logger == nil {
+		return len(p), nil
+	}
+
+	l.buf = append(l.buf, p...)
+
+	for {
+		line, rest, found := bytes.Cut(l.buf, []byte("\n"))
+		if !found {
+			break
+		}
+
+		l.log(line)
+		l.buf = rest
+	}
+
+	return len(p), nil
+}
+
+// Flush logs a trailing line without a newline.
+func (l *lineLogger) Flush() {
+	if l.logger != nil && len(l.buf) > 0 {
+		l.log(l.buf)
+		l.buf = nil
+	}
+}
+
+func (l *lineLogger) log(line []byte) {
+	l.logger.Log(l.ctx, l.level, string(bytes.TrimRight(line, "\r")),
+		"stream", l.stream)
+}
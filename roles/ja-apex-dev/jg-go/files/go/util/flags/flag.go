@@ -0,0 +1,143 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"example.com/go-template/util/yamlx"
+)
+
+// buckets is the resolution of percentage rollouts, in hundredths of a
+// percent.
+const buckets = 10000
+
+// ErrInvalid is returned for malformed flag files and overrides.
+var ErrInvalid = errors.New("flags: invalid flag")
+
+// Bucketing keys of percentage rollouts.
+const (
+	ByUser   = "user"
+	ByTenant = "tenant"
+)
+
+// Flag is the definition of a feature flag.
+type Flag struct {
+	// Enabled turns the flag on, for the targets selected by the other
+	// fields.
+	Enabled bool `yaml:"enabled"`
+	// Rollout is the percentage of targets the flag is on for, 100 for
+	// everyone.
+	Rollout float64 `yaml:"rollout"`
+	// By selects the target key hashed into rollout buckets, ByUser, the
+	// default, or ByTenant. Targets without it are left out of partial
+	// rollouts.
+	By string `yaml:"by"`
+	// Users and Tenants list the targets the flag is always on for while
+	// enabled.
+	Users   []string `yaml:"users"`
+	Tenants []string `yaml:"tenants"`
+}
+
+// UnmarshalYAML accepts a boolean, for flags on or off for everyone, or a
+// mapping of the fields, Rollout defaulting to 100 and By to ByUser.
+func (f *Flag) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+
+		*f = Flag{Enabled: enabled, Rollout: 100, By: ByUser}
+
+		return nil
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	type plain Flag
+
+	decoded := plain{Rollout: 100, By: ByUser}
+	if err := yamlx.UnmarshalStrict(data, &decoded); err != nil {
+		return err
+	}
+
+	*f = Flag(decoded)
+
+	return nil
+}
+
+// validate checks the rollout percentage and bucketing key.
+func (f Flag) validate() error {
+	if f.Rollout < 0 || f.Rollout > 100 {
+		return fmt.Errorf("%w: rollout %v is not a percentage",
+			ErrInvalid, f.Rollout)
+	}
+
+	if f.By != "" && f.By != ByUser && f.By != ByTenant {
+		return fmt.Errorf("%w: rollout by %q", ErrInvalid, f.By)
+	}
+
+	return nil
+}
+
+// Target is the subject a flag is evaluated for.
+type Target struct {
+	User   string
+	Tenant string
+}
+
+type targetKey struct{}
+
+// WithTarget returns a context carrying target, which Set.Enabled
+// evaluates the flags for.
+func WithTarget(ctx context.Context, target Target) context.Context {
+	return context.WithValue(ctx, targetKey{}, target)
+}
+
+// TargetFrom returns the target stored by WithTarget, or the zero target.
+func TargetFrom(ctx context.Context) Target {
+	target, _ := ctx.Value(targetKey{}).(Target)
+
+	return target
+}
+
+// Evaluate reports whether the flag name is on for target. A target falls
+// in the same bucket for every evaluation of a flag, so that its rollout
+// only grows with the percentage, while buckets differ across flags.
+func (f Flag) Evaluate(name string, target Target) bool {
+	switch {
+	case !f.Enabled:
+		return false
+	case slices.Contains(f.Users, target.User) && target.User != "",
+		slices.Contains(f.Tenants, target.Tenant) && target.Tenant != "",
+		f.Rollout >= 100:
+		return true
+	}
+
+	key := target.User
+	if f.By == ByTenant {
+		key = target.Tenant
+	}
+
+	if key == "" {
+		return false
+	}
+
+	return float64(bucket(name, key)) < f.Rollout*buckets/100
+}
+
+// bucket hashes the key of a target for the flag name.
+func bucket(name, key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name + "\x00" + key))
+
+	return h.Sum64() % buckets
+}
@@ -0,0 +1,245 @@
+// Package flags evaluates feature flags defined in a YAML file, which is
+// reloaded when it changes:
+//
+//	flags:
+//	  new-search: true
+//	  checkout-v2:
+//	    enabled: true
+//	    rollout: 25        # percent of the users
+//	    users: [alice]     # always on for them
+//	  billing-export:
+//	    enabled: true
+//	    rollout: 10
+//	    by: tenant
+//	    tenants: [acme]
+//
+// Environment variables override the file, such as FLAG_CHECKOUT_V2=false
+// or FLAG_CHECKOUT_V2=50%. Values are read as booleans first, so 1 turns
+// the flag fully on and 0 off; a 1% rollout is written FLAG_CHECKOUT_V2=1%.
+// Requests carry their Target in their context, which Set.Enabled
+// evaluates the flags for.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"example.com/go-template/util/watch"
+	"example.com/go-template/util/yamlx"
+)
+
+// DefaultEnvPrefix starts the names of the override variables.
+const DefaultEnvPrefix = "FLAG_"
+
+// LookupFunc looks up an environment variable, as os.LookupEnv.
+type LookupFunc func(key string) (string, bool)
+
+// Option configures Load.
+type Option func(*settings)
+
+type settings struct {
+	prefix  string
+	lookup  LookupFunc
+	onError func(error)
+}
+
+// WithEnvPrefix starts the names of the override variables with prefix
+// instead of DefaultEnvPrefix.
+func WithEnvPrefix(prefix string) Option {
+	return func(s *settings) {
+		s.prefix = prefix
+	}
+}
+
+// WithLookup reads the override variables with lookup instead of
+// os.LookupEnv.
+func WithLookup(lookup LookupFunc) Option {
+	return func(s *settings) {
+		s.lookup = lookup
+	}
+}
+
+// WithOnError reports the failed reloads of Watch, which keep the flags
+// loaded before.
+func WithOnError(fn func(error)) Option {
+	return func(s *settings) {
+		s.onError = fn
+	}
+}
+
+// file is the layout of a flag file.
+type file struct {
+	Flags map[string]Flag `yaml:"flags"`
+}
+
+// Set holds the flags of a file. It is safe for concurrent use.
+type Set struct {
+	path string
+	settings
+
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// Load reads the flags of the file at path with their overrides.
+func Load(path string, opts ...Option) (*Set, error) {
+	s := &Set{
+		path: path,
+		settings: settings{
+			prefix: DefaultEnvPrefix, lookup: os.LookupEnv,
+			onError: func(error) {},
+		},
+	}
+	for _, opt := range opts {
+		opt(&s.settings)
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload reads the file again. The flags stay as they were on failure.
+func (s *Set) Reload() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("flags: %w", err)
+	}
+
+	var f file
+	if err := yamlx.UnmarshalStrict(data, &f); err != nil {
+		return fmt.Errorf("flags: %s: %w", s.path, err)
+	}
+
+	for name, flag := range f.Flags {
+		flag, err := s.override(name, flag)
+		if err != nil {
+			return err
+		}
+
+		if err := flag.validate(); err != nil {
+			return fmt.Errorf("%w: %s", err, name)
+		}
+
+		f.Flags[name] = flag
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flags = f.Flags
+
+	return nil
+}
+
+// Watch reloads the file whenever it changes until ctx is done, reporting
+// failures to the WithOnError function.
+func (s *Set) Watch(ctx context.Context) error {
+	dir, base := filepath.Split(s.path)
+	if dir == "" {
+		dir = "."
+	}
+
+	return watch.Watch(ctx, dir, func([]watch.Event) {
+		if err := s.Reload(); err != nil {
+			s.onError(err)
+		}
+	}, watch.WithInclude(base))
+}
+
+// Enabled reports whether the flag name is on for the target of ctx.
+// Unknown flags are off.
+func (s *Set) Enabled(ctx context.Context, name string) bool {
+	return s.Evaluate(name, TargetFrom(ctx))
+}
+
+// Evaluate reports whether the flag name is on for target.
+func (s *Set) Evaluate(name string, target Target) bool {
+	flag, ok := s.Get(name)
+
+	return ok && flag.Evaluate(name, target)
+}
+
+// Bool reports whether the flag name is on for everyone.
+func (s *Set) Bool(name string) bool {
+	return s.Evaluate(name, Target{})
+}
+
+// Percent returns the rollout percentage of the flag name, 0 when it is
+// off or unknown.
+func (s *Set) Percent(name string) float64 {
+	flag, ok := s.Get(name)
+	if !ok || !flag.Enabled {
+		return 0
+	}
+
+	return flag.Rollout
+}
+
+// Get returns the definition of the flag name.
+func (s *Set) Get(name string) (Flag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flag, ok := s.flags[name]
+
+	return flag, ok
+}
+
+// Names returns the names of the flags, sorted.
+func (s *Set) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(s.flags))
+}
+
+// override applies the environment variable of the flag name, either a
+// boolean turning it on or off for everyone or a percentage setting its
+// rollout. Booleans win, as strconv.ParseBool accepts "1" and "0".
+func (s *Set) override(name string, flag Flag) (Flag, error) {
+	key := s.prefix + envName(name)
+
+	value, ok := s.lookup(key)
+	if !ok {
+		return flag, nil
+	}
+
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		return Flag{Enabled: enabled, Rollout: 100}, nil
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return flag, fmt.Errorf("%w: %s=%q is neither a boolean nor a "+
+			"percentage", ErrInvalid, key, value)
+	}
+
+	flag.Enabled, flag.Rollout = true, percent
+
+	return flag, nil
+}
+
+// envName returns name in upper case with the characters other than
+// letters and digits replaced by underscores.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
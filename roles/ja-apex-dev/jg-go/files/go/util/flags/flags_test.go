@@ -0,0 +1,181 @@
+package flags_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/flags"
+	"example.com/go-template/util/testx"
+	"example.com/go-template/util/watch"
+)
+
+const definitions = "flags:\n" +
+	"  search: true\n" +
+	"  legacy: false\n" +
+	"  checkout:\n" +
+	"    enabled: true\n" +
+	"    rollout: 25\n" +
+	"    users: [alice]\n" +
+	"  export:\n" +
+	"    enabled: true\n" +
+	"    rollout: 0\n" +
+	"    by: tenant\n" +
+	"    tenants: [acme]\n"
+
+func loadFile(t *testing.T, env map[string]string) (*flags.Set, string) {
+	t.Helper()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"flags.yml": definitions,
+	})
+	path := filepath.Join(dir, "flags.yml")
+
+	set, err := flags.Load(path, flags.WithLookup(
+		func(key string) (string, bool) {
+			value, ok := env[key]
+
+			return value, ok
+		}))
+	require.NoError(t, err)
+
+	return set, path
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	set, _ := loadFile(t, nil)
+
+	assert.Equal(t, []string{"checkout", "export", "legacy", "search"},
+		set.Names())
+	assert.True(t, set.Bool("search"))
+	assert.False(t, set.Bool("legacy"))
+	assert.False(t, set.Bool("checkout"), "partial rollout")
+	assert.False(t, set.Bool("missing"))
+	assert.InDelta(t, 25.0, set.Percent("checkout"), 0)
+	assert.Zero(t, set.Percent("legacy"))
+
+	ctx := flags.WithTarget(t.Context(), flags.Target{User: "alice"})
+	assert.True(t, set.Enabled(ctx, "checkout"), "listed user")
+	assert.False(t, set.Enabled(ctx, "export"))
+	assert.True(t, set.Evaluate("export", flags.Target{Tenant: "acme"}))
+	assert.Equal(t, flags.Target{User: "alice"}, flags.TargetFrom(ctx))
+}
+
+func TestRollout(t *testing.T) {
+	t.Parallel()
+
+	flag := flags.Flag{Enabled: true, Rollout: 25}
+	on := 0
+
+	for i := range 4000 {
+		target := flags.Target{User: fmt.Sprintf("user-%d", i)}
+		if !flag.Evaluate("checkout", target) {
+			continue
+		}
+
+		on++
+
+		wider := flags.Flag{Enabled: true, Rollout: 50}
+		assert.True(t, wider.Evaluate("checkout", target), "stable")
+	}
+
+	assert.InDelta(t, 1000, on, 100)
+	assert.False(t, flag.Evaluate("checkout", flags.Target{}), "no key")
+}
+
+func TestOverrides(t *testing.T) {
+	t.Parallel()
+
+	set, _ := loadFile(t, map[string]string{
+		"FLAG_SEARCH":   "false",
+		"FLAG_LEGACY":   "1",
+		"FLAG_CHECKOUT": "100%",
+		"FLAG_EXPORT":   "1%",
+	})
+	assert.False(t, set.Bool("search"))
+	assert.True(t, set.Bool("legacy"))
+	assert.InDelta(t, 100, set.Percent("legacy"), 0, "1 is a boolean")
+	assert.True(t, set.Bool("checkout"))
+	assert.InDelta(t, 1, set.Percent("export"), 0)
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"flags.yml": definitions,
+	})
+
+	_, err := flags.Load(filepath.Join(dir, "flags.yml"),
+		flags.WithEnvPrefix("APP_"),
+		flags.WithLookup(func(key string) (string, bool) {
+			return "soon", key == "APP_EXPORT"
+		}))
+	require.ErrorIs(t, err, flags.ErrInvalid)
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range map[string]string{
+		"rollout": "flags:\n  a: {enabled: true, rollout: 120}\n",
+		"by":      "flags:\n  a: {enabled: true, by: team}\n",
+		"field":   "flags:\n  a: {enabled: true, percent: 5}\n",
+		"scalar":  "flags:\n  a: maybe\n",
+	} {
+		dir := testx.TempDirWithFiles(t, map[string]string{"f.yml": content})
+
+		_, err := flags.Load(filepath.Join(dir, "f.yml"))
+		require.Error(t, err, name)
+	}
+
+	_, err := flags.Load(filepath.Join(t.TempDir(), "missing.yml"))
+	require.Error(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	_, path := loadFile(t, nil)
+	failures := make(chan error, 10)
+
+	set, err := flags.Load(path, flags.WithOnError(func(err error) {
+		failures <- err
+	}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- set.Watch(ctx) }()
+
+	// The watch starts asynchronously, so write again until seen, less
+	// often than the debounce period.
+	var written time.Time
+
+	testx.RequireEventually(t, func() bool {
+		if time.Since(written) > 5*watch.DefaultDebounce {
+			written = time.Now()
+			_ = os.WriteFile(path, []byte("flags:\n  legacy: true\n"), 0o600)
+		}
+
+		return set.Bool("legacy")
+	}, 5*time.Second)
+	assert.Equal(t, []string{"legacy"}, set.Names())
+
+	require.NoError(t, os.WriteFile(path, []byte("flags: [\n"), 0o600))
+	select {
+	case err := <-failures:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload failure reported")
+	}
+	assert.True(t, set.Bool("legacy"), "previous flags kept")
+
+	cancel()
+	require.NoError(t, <-done)
+}
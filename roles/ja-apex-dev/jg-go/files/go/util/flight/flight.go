@@ -0,0 +1,186 @@
+// Package flight deduplicates concurrent calls sharing a key, so that a
+// burst of identical requests, such as config reloads or remote metadata
+// lookups, runs the underlying work once and shares its result.
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// ErrPanic is returned by Do when the function panics.
+var ErrPanic = errors.New("flight: function panicked")
+
+// Func computes the result shared under a key.
+type Func[V any] func(ctx context.Context) (V, error)
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+// WithTTL keeps successful results for ttl after they complete, serving
+// later calls without running the function again. Zero, the default,
+// only shares results between overlapping calls. Errors are never kept,
+// and expired results are dropped even if their key is never asked again.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *settings) {
+		s.ttl = ttl
+	}
+}
+
+// WithClock sets the clock used to expire kept results.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.clock = c
+	}
+}
+
+// call is an execution of a function, in flight or kept until expires,
+// when evict drops it.
+type call[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	expires time.Time
+	evict   clock.Timer
+}
+
+// Group coalesces the calls made under the same key. It is safe for
+// concurrent use.
+type Group[K comparable, V any] struct {
+	settings
+
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// New returns an empty Group.
+func New[K comparable, V any](opts ...Option) *Group[K, V] {
+	g := &Group[K, V]{
+		settings: settings{clock: clock.Real()},
+		calls:    make(map[K]*call[V]),
+	}
+
+	for _, opt := range opts {
+		opt(&g.settings)
+	}
+
+	return g
+}
+
+// Do returns the result of fn for key. Concurrent callers of the same key
+// share a single execution, which runs with the context of the first one;
+// the others stop waiting when their own context ends.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn Func[V]) (V, error) {
+	g.mu.Lock()
+
+	current, ok := g.calls[key]
+	if ok && g.expired(current) {
+		ok = false
+	}
+
+	if !ok {
+		current = &call[V]{done: make(chan struct{})}
+		g.calls[key] = current
+	}
+
+	g.mu.Unlock()
+
+	if !ok {
+		g.run(ctx, key, current, fn)
+
+		return current.value, current.err
+	}
+
+	select {
+	case <-current.done:
+		return current.value, current.err
+	case <-ctx.Done():
+		var zero V
+
+		return zero, fmt.Errorf("flight: %w", ctx.Err())
+	}
+}
+
+// Forget drops the result kept for key, so that the next call runs the
+// function again. Calls in flight are not interrupted.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if current, ok := g.calls[key]; ok && current.evict != nil {
+		current.evict.Stop()
+	}
+
+	delete(g.calls, key)
+}
+
+// Len returns the number of keys in flight or kept.
+func (g *Group[K, V]) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return len(g.calls)
+}
+
+// expired reports whether a completed call is past its lifetime. Calls in
+// flight never expire.
+func (g *Group[K, V]) expired(c *call[V]) bool {
+	select {
+	case <-c.done:
+		return !g.clock.Now().Before(c.expires)
+	default:
+		return false
+	}
+}
+
+func (g *Group[K, V]) run(
+	ctx context.Context, key K, current *call[V], fn Func[V],
+) {
+	defer func() {
+		if r := recover(); r != nil {
+			current.err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+
+		g.mu.Lock()
+
+		if current.err == nil && g.ttl > 0 {
+			current.expires = g.clock.Now().Add(g.ttl)
+			current.evict = g.clock.AfterFunc(g.ttl, func() {
+				g.drop(key, current)
+			})
+		} else {
+			g.dropLocked(key, current)
+		}
+
+		g.mu.Unlock()
+
+		close(current.done)
+	}()
+
+	current.value, current.err = fn(ctx)
+}
+
+// drop removes current unless key was reused meanwhile.
+func (g *Group[K, V]) drop(key K, current *call[V]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.dropLocked(key, current)
+}
+
+// dropLocked is drop for callers holding the lock.
+func (g *Group[K, V]) dropLocked(key K, current *call[V]) {
+	if g.calls[key] == current {
+		delete(g.calls, key)
+	}
+}
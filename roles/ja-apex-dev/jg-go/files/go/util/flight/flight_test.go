@@ -0,0 +1,156 @@
+package flight_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/flight"
+)
+
+var errLookup = errors.New("lookup failed")
+
+func TestDoCoalesces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+
+	group := flight.New[string, int]()
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+
+		return 42, nil
+	}
+
+	for range 10 {
+		wg.Go(func() {
+			value, err := group.Do(t.Context(), "key", fn)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		})
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err := group.Do(t.Context(), "key", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load(), "results are not kept")
+}
+
+func TestDoTTL(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	group := flight.New[string, int](
+		flight.WithTTL(time.Minute), flight.WithClock(fake))
+
+	var calls atomic.Int32
+
+	fn := func(context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	}
+
+	for range 3 {
+		value, err := group.Do(t.Context(), "key", fn)
+		require.NoError(t, err)
+		assert.Equal(t, 1, value)
+	}
+
+	fake.Advance(time.Minute)
+
+	value, _ := group.Do(t.Context(), "key", fn)
+	assert.Equal(t, 2, value)
+
+	group.Forget("key")
+
+	value, _ = group.Do(t.Context(), "key", fn)
+	assert.Equal(t, 3, value)
+}
+
+func TestDoTTLEvicts(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	group := flight.New[string, int](
+		flight.WithTTL(time.Minute), flight.WithClock(fake))
+
+	fn := func(context.Context) (int, error) {
+		return 1, nil
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := group.Do(t.Context(), key, fn)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, group.Len())
+
+	group.Forget("a")
+	assert.Equal(t, 2, group.Len())
+
+	fake.Advance(time.Minute)
+	assert.Zero(t, group.Len())
+	assert.Zero(t, fake.Waiters())
+}
+
+func TestDoErrors(t *testing.T) {
+	t.Parallel()
+
+	group := flight.New[string, int](flight.WithTTL(time.Hour))
+
+	_, err := group.Do(t.Context(), "key",
+		func(context.Context) (int, error) {
+			return 0, errLookup
+		})
+	require.ErrorIs(t, err, errLookup)
+
+	_, err = group.Do(t.Context(), "key",
+		func(context.Context) (int, error) {
+			panic("boom")
+		})
+	require.ErrorIs(t, err, flight.ErrPanic, "errors are not kept")
+}
+
+func TestDoWaiterCanceled(t *testing.T) {
+	t.Parallel()
+
+	group := flight.New[string, int]()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		_, _ = group.Do(context.Background(), "key",
+			func(context.Context) (int, error) {
+				close(started)
+				<-release
+
+				return 1, nil
+			})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := group.Do(ctx, "key", nil)
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+}
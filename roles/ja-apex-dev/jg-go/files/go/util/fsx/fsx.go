@@ -0,0 +1,226 @@
+// Package fsx provides filesystem helpers: crash-safe atomic writes,
+// file and tree copies, and existence checks.
+package fsx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrNotDir is returned by EnsureDir when the path exists as a file.
+	ErrNotDir = errors.New("fsx: not a directory")
+	// ErrUnsupportedFile is returned by CopyDir for devices, sockets and
+	// other special files.
+	ErrUnsupportedFile = errors.New("fsx: unsupported file type")
+)
+
+// Exists reports whether path exists, following symbolic links. Errors
+// other than fs.ErrNotExist, such as permission failures, are returned.
+func Exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, fmt.Errorf("fsx: %w", err)
+	}
+}
+
+// IsDir reports whether path exists and is a directory.
+func IsDir(path string) (bool, error) {
+	info, err := os.Stat(path)
+
+	switch {
+	case err == nil:
+		return info.IsDir(), nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, fmt.Errorf("fsx: %w", err)
+	}
+}
+
+// EnsureDir creates path and its parents with mode, and resets the mode of
+// an existing directory.
+func EnsureDir(path string, mode fs.FileMode) error {
+	if err := os.MkdirAll(path, mode.Perm()); err != nil {
+		// MkdirAll only fails on an existing path that is not a directory.
+		if ok, _ := Exists(path); ok {
+			return fmt.Errorf("%w: %s", ErrNotDir, path)
+		}
+
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	// MkdirAll is subject to the umask and leaves existing directories
+	// untouched.
+	if info.Mode().Perm() != mode.Perm() {
+		if err := os.Chmod(path, mode.Perm()); err != nil {
+			return fmt.Errorf("fsx: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// AtomicWriteFile writes data to path so that readers and crashes observe
+// either the old content or the new one, never a torn file. The data is
+// written to a temporary file in the same directory, synced, renamed over
+// path and the directory is synced.
+func AtomicWriteFile(path string, data []byte, perm fs.FileMode) error {
+	return atomicWrite(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+
+		return err
+	})
+}
+
+// CopyFile atomically copies the regular file src to dst, keeping its
+// permission bits.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFile, src)
+	}
+
+	return atomicWrite(dst, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+
+		return err
+	})
+}
+
+// CopyDir recursively copies the tree rooted at src into dst, creating it
+// when needed. Existing files are replaced, directories and permission bits
+// are preserved and symbolic links are recreated as is.
+func CopyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry,
+		err error,
+	) error {
+		if err != nil {
+			return fmt.Errorf("fsx: %w", err)
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("fsx: %w", err)
+		}
+
+		target := filepath.Join(dst, rel)
+
+		switch mode := entry.Type(); {
+		case mode.IsDir():
+			info, err := entry.Info()
+			if err != nil {
+				return fmt.Errorf("fsx: %w", err)
+			}
+
+			return EnsureDir(target, info.Mode().Perm())
+		case mode&fs.ModeSymlink != 0:
+			return copySymlink(path, target)
+		case mode.IsRegular():
+			return CopyFile(path, target)
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedFile, path)
+		}
+	})
+}
+
+func copySymlink(src, dst string) error {
+	link, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	if err := os.Remove(dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	if err := os.Symlink(link, dst); err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	return nil
+}
+
+// atomicWrite streams write into a synced temporary file renamed over path.
+func atomicWrite(
+	path string, perm fs.FileMode, write func(w io.Writer) error,
+) (err error) {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return fmt.Errorf("fsx: write %s: %w", path, err)
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir persists a rename by syncing the parent directory.
+func syncDir(dir string) error {
+	handle, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("fsx: %w", err)
+	}
+	defer handle.Close()
+
+	if err := handle.Sync(); err != nil {
+		return fmt.Errorf("fsx: sync %s: %w", dir, err)
+	}
+
+	return nil
+}
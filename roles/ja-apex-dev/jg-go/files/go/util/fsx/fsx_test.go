@@ -0,0 +1,138 @@
+package fsx_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/fsx"
+)
+
+func write(t *testing.T, path, content string, perm fs.FileMode) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), perm))
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func perm(t *testing.T, path string) fs.FileMode {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	return info.Mode().Perm()
+}
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	write(t, file, "x", 0o600)
+
+	ok, err := fsx.Exists(file)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = fsx.Exists(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = fsx.IsDir(dir)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = fsx.IsDir(file)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEnsureDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	require.NoError(t, fsx.EnsureDir(dir, 0o700))
+	assert.Equal(t, fs.FileMode(0o700), perm(t, dir))
+
+	require.NoError(t, fsx.EnsureDir(dir, 0o750))
+	assert.Equal(t, fs.FileMode(0o750), perm(t, dir))
+
+	file := filepath.Join(dir, "file")
+	write(t, file, "x", 0o600)
+	require.ErrorIs(t, fsx.EnsureDir(file, 0o700), fsx.ErrNotDir)
+}
+
+func TestAtomicWriteFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	require.NoError(t, fsx.AtomicWriteFile(path, []byte("one"), 0o640))
+	require.NoError(t, fsx.AtomicWriteFile(path, []byte("two"), 0o600))
+
+	assert.Equal(t, "two", read(t, path))
+	assert.Equal(t, fs.FileMode(0o600), perm(t, path))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are renamed away")
+
+	require.Error(t, fsx.AtomicWriteFile(
+		filepath.Join(dir, "missing", "file"), nil, 0o600))
+}
+
+func TestCopyFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "run.sh")
+	write(t, src, "#!/bin/sh", 0o750)
+
+	dst := filepath.Join(dir, "copy.sh")
+	require.NoError(t, fsx.CopyFile(src, dst))
+	assert.Equal(t, "#!/bin/sh", read(t, dst))
+	assert.Equal(t, fs.FileMode(0o750), perm(t, dst))
+
+	require.ErrorIs(t, fsx.CopyFile(dir, dst), fsx.ErrUnsupportedFile)
+	require.ErrorIs(t, fsx.CopyFile(filepath.Join(dir, "missing"), dst),
+		fs.ErrNotExist)
+}
+
+func TestCopyDir(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join(t.TempDir(), "src")
+	write(t, filepath.Join(src, "a.txt"), "a", 0o600)
+	write(t, filepath.Join(src, "nested", "b.txt"), "b", 0o644)
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(src, "link")))
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	write(t, filepath.Join(dst, "a.txt"), "stale", 0o600)
+
+	require.NoError(t, fsx.CopyDir(src, dst))
+	require.NoError(t, fsx.CopyDir(src, dst), "copies are repeatable")
+
+	assert.Equal(t, "a", read(t, filepath.Join(dst, "a.txt")))
+	assert.Equal(t, "b", read(t, filepath.Join(dst, "nested", "b.txt")))
+	assert.Equal(t, fs.FileMode(0o644),
+		perm(t, filepath.Join(dst, "nested", "b.txt")))
+
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", link)
+}
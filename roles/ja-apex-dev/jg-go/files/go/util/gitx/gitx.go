@@ -0,0 +1,239 @@
+// Package gitx manages git repositories through the git command: clones,
+// including shallow ones, fetches, checkouts, tag resolution and status.
+// Output is always requested in machine-readable forms, such as porcelain
+// v2 for the status, and parsed into structs.
+//
+// Commands never prompt for credentials. HTTPS remotes authenticate with
+// WithToken and SSH remotes with the SSH agent or WithSSHKey; secrets are
+// passed in the environment of git, so they stay out of error messages
+// and process listings.
+package gitx
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"example.com/go-template/util/execx"
+)
+
+var (
+	// ErrNotRepository is returned by Open for directories outside of a
+	// work tree.
+	ErrNotRepository = errors.New("gitx: not a git repository")
+	// ErrNoTag is returned when no tag matches a name or constraint.
+	ErrNoTag = errors.New("gitx: no matching tag")
+)
+
+// TokenUser is the user name sent with WithToken, accepted by GitHub and
+// Gitea alongside personal access tokens.
+const TokenUser = "x-access-token"
+
+// Option configures Clone, Open, Fetch and RemoteTags.
+type Option func(*settings)
+
+type settings struct {
+	exec   []execx.Option
+	env    []string
+	config [][2]string
+	depth  int
+	branch string
+}
+
+// WithExecOptions configures the runner of git commands, for instance
+// with a logger or a timeout.
+func WithExecOptions(opts ...execx.Option) Option {
+	return func(s *settings) {
+		s.exec = append(s.exec, opts...)
+	}
+}
+
+// WithToken authenticates to HTTPS remotes with a personal access token,
+// sent as the password of TokenUser.
+func WithToken(token string) Option {
+	return func(s *settings) {
+		credentials := base64.StdEncoding.EncodeToString(
+			[]byte(TokenUser + ":" + token))
+		s.config = append(s.config, [2]string{
+			"http.extraHeader", "Authorization: Basic " + credentials,
+		})
+	}
+}
+
+// WithSSHAgent authenticates to SSH remotes with the agent listening on
+// socket instead of $SSH_AUTH_SOCK.
+func WithSSHAgent(socket string) Option {
+	return func(s *settings) {
+		s.env = append(s.env, "SSH_AUTH_SOCK="+socket)
+	}
+}
+
+// WithSSHKey authenticates to SSH remotes with the private key file at
+// path only.
+func WithSSHKey(path string) Option {
+	return func(s *settings) {
+		s.env = append(s.env, "GIT_SSH_COMMAND=ssh -o IdentitiesOnly=yes -i "+
+			shellQuote(path))
+	}
+}
+
+// shellQuote quotes s for the POSIX shell running GIT_SSH_COMMAND.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// WithDepth makes Clone and Fetch shallow, limited to the last depth
+// commits.
+func WithDepth(depth int) Option {
+	return func(s *settings) {
+		s.depth = depth
+	}
+}
+
+// WithBranch selects the branch or tag that Clone checks out and Fetch
+// fetches, instead of the default branch and all branches.
+func WithBranch(name string) Option {
+	return func(s *settings) {
+		s.branch = name
+	}
+}
+
+func newSettings(opts []Option) settings {
+	var s settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+// runner returns a runner whose environment disables prompts and holds
+// the credentials, using GIT_CONFIG_* variables for configuration.
+func (s *settings) runner() *execx.Runner {
+	env := append([]string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_COUNT=" + strconv.Itoa(len(s.config)),
+	}, s.env...)
+
+	for i, entry := range s.config {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, entry[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, entry[1]))
+	}
+
+	return execx.New(append(s.exec, execx.WithEnv(env...))...)
+}
+
+// Repo is a local repository.
+type Repo struct {
+	dir    string
+	runner *execx.Runner
+}
+
+// Clone clones the repository at url into dir and returns it.
+func Clone(
+	ctx context.Context, url, dir string, opts ...Option,
+) (*Repo, error) {
+	s := newSettings(opts)
+	args := append([]string{"clone", "--quiet"}, s.fetchArgs()...)
+
+	if s.branch != "" {
+		args = append(args, "--branch", s.branch)
+	}
+
+	repo := &Repo{dir: dir, runner: s.runner()}
+	if _, err := repo.run(ctx, append(args, "--", url, dir)...); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Open returns the repository whose work tree contains dir.
+func Open(ctx context.Context, dir string, opts ...Option) (*Repo, error) {
+	s := newSettings(opts)
+	repo := &Repo{dir: dir, runner: s.runner()}
+
+	top, err := repo.git(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrNotRepository, dir, err)
+	}
+
+	repo.dir = top
+
+	return repo, nil
+}
+
+// Dir returns the top directory of the work tree.
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Fetch fetches the branches and tags of origin, pruning deleted ones.
+// WithDepth deepens or shortens a shallow history and WithBranch only
+// fetches that branch; other options are ignored.
+func (r *Repo) Fetch(ctx context.Context, opts ...Option) error {
+	s := newSettings(opts)
+	args := append([]string{"fetch", "--quiet", "--tags", "--prune"},
+		s.fetchArgs()...)
+	args = append(args, "--", "origin")
+
+	if s.branch != "" {
+		args = append(args, s.branch)
+	}
+
+	_, err := r.git(ctx, args...)
+
+	return err
+}
+
+// Checkout checks out ref, a branch, tag or commit. Tags and commits
+// detach HEAD. Refs starting with "-", which git would take for options,
+// are rejected.
+func (r *Repo) Checkout(ctx context.Context, ref string) error {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("gitx: invalid ref %q", ref)
+	}
+
+	_, err := r.git(ctx, "checkout", "--quiet", ref, "--")
+
+	return err
+}
+
+// Head returns the commit checked out.
+func (r *Repo) Head(ctx context.Context) (string, error) {
+	return r.git(ctx, "rev-parse", "--verify", "HEAD")
+}
+
+// fetchArgs returns the shallow clone arguments.
+func (s *settings) fetchArgs() []string {
+	if s.depth <= 0 {
+		return nil
+	}
+
+	return []string{"--depth", strconv.Itoa(s.depth)}
+}
+
+// git runs git in the work tree and returns its trimmed output.
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	return r.run(ctx, append([]string{"-C", r.dir}, args...)...)
+}
+
+// run runs git with args, reporting the error message of git on failure.
+func (r *Repo) run(ctx context.Context, args ...string) (string, error) {
+	result, err := r.runner.Run(ctx, "git", args...)
+
+	var exitErr *execx.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("gitx: %s: %w",
+			strings.TrimSpace(result.Stderr), err)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("gitx: %w", err)
+	}
+
+	return strings.TrimSpace(result.Stdout), nil
+}
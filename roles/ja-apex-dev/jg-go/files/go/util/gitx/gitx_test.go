@@ -0,0 +1,194 @@
+package gitx_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/gitx"
+)
+
+// git runs a git command in dir with a fixed identity.
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return strings.TrimSpace(string(out))
+}
+
+// upstream creates a repository with three commits tagged v1.0.0, v1.1.0
+// (annotated) and v2.0.0-rc.1, returning its directory.
+func upstream(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git(t, dir, "init", "--quiet", "--initial-branch=main")
+
+	tags := [][]string{
+		{"tag", "v1.0.0"},
+		{"tag", "-a", "-m", "Release", "v1.1.0"},
+		{"tag", "v2.0.0-rc.1"},
+	}
+	for i, tag := range tags {
+		name := filepath.Join(dir, "file.txt")
+		require.NoError(t, os.WriteFile(name, []byte{byte('a' + i)}, 0o600))
+		git(t, dir, "add", "file.txt")
+		git(t, dir, "commit", "--quiet", "-m", tag[len(tag)-1])
+		git(t, dir, tag...)
+	}
+
+	return dir
+}
+
+func TestCloneFetchCheckout(t *testing.T) {
+	t.Parallel()
+
+	origin := upstream(t)
+	dir := filepath.Join(t.TempDir(), "clone")
+
+	repo, err := gitx.Clone(t.Context(), "file://"+origin, dir,
+		gitx.WithDepth(1), gitx.WithBranch("v1.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, dir, repo.Dir())
+	assert.Equal(t, "1", git(t, dir, "rev-list", "--count", "HEAD"))
+
+	head, err := repo.Head(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, git(t, origin, "rev-parse", "v1.1.0^{commit}"), head)
+
+	require.NoError(t, repo.Fetch(t.Context(), gitx.WithDepth(3)))
+	assert.Equal(t, "3", git(t, dir, "rev-list", "--count", "v2.0.0-rc.1"))
+
+	commit, err := repo.ResolveTag(t.Context(), "v1.0.0")
+	require.NoError(t, err)
+	require.NoError(t, repo.Checkout(t.Context(), "v1.0.0"))
+
+	head, err = repo.Head(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, commit, head)
+
+	for _, ref := range []string{"", "--orphan=x", "-b"} {
+		require.Error(t, repo.Checkout(t.Context(), ref), ref)
+	}
+
+	_, err = repo.ResolveTag(t.Context(), "v9")
+	require.ErrorIs(t, err, gitx.ErrNoTag)
+
+	opened, err := gitx.Open(t.Context(), filepath.Join(dir, ".git"))
+	require.Error(t, err)
+	assert.Nil(t, opened)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o750))
+	opened, err = gitx.Open(t.Context(), filepath.Join(dir, "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, dir, opened.Dir())
+
+	_, err = gitx.Open(t.Context(), t.TempDir())
+	require.ErrorIs(t, err, gitx.ErrNotRepository)
+}
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	origin := upstream(t)
+
+	refs, err := gitx.RemoteTags(t.Context(), origin)
+	require.NoError(t, err)
+	require.Len(t, refs, 3)
+	assert.Equal(t, gitx.Ref{
+		Name: "v1.1.0", Commit: git(t, origin, "rev-parse", "v1.1.0^{commit}"),
+	}, refs[1])
+
+	repo, err := gitx.Open(t.Context(), origin)
+	require.NoError(t, err)
+
+	tags, err := repo.Tags(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0", "v2.0.0-rc.1"}, tags)
+
+	latest, err := gitx.LatestTag(append(tags, "nightly"), "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", latest)
+
+	latest, err = gitx.LatestTag(tags, "~1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", latest)
+
+	_, err = gitx.LatestTag(tags, ">=3")
+	require.ErrorIs(t, err, gitx.ErrNoTag)
+
+	_, err = gitx.LatestTag(tags, "not a constraint")
+	require.Error(t, err)
+}
+
+func TestWithToken(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	var header atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			header.Store(r.Header.Get("Authorization"))
+			http.NotFound(w, r)
+		}))
+	t.Cleanup(server.Close)
+
+	_, err := gitx.RemoteTags(t.Context(), server.URL+"/repo.git",
+		gitx.WithToken("secret"))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString(
+		[]byte(gitx.TokenUser+":secret")), header.Load())
+}
+
+func TestWithSSHKey(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// The fake ssh records the key it is given and fails.
+	bin, out := t.TempDir(), filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(
+		"#!/bin/sh\nwhile [ $# -gt 0 ]; do\n"+
+			"[ \"$1\" = -i ] && printf %s \"$2\" > "+out+"\nshift\n"+
+			"done\nexit 1\n"), 0o700))
+
+	key := filepath.Join(t.TempDir(), "it's $HOME `id`")
+
+	_, err := gitx.RemoteTags(t.Context(), "ssh://example.com/repo.git",
+		gitx.WithSSHKey(key), gitx.WithExecOptions(execx.WithEnv(
+			"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))))
+	require.Error(t, err)
+
+	recorded, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, key, string(recorded))
+}
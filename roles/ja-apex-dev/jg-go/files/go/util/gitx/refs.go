@@ -0,0 +1,133 @@
+package gitx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/semver"
+)
+
+// Ref is a named commit, such as a tag.
+type Ref struct {
+	Name   string
+	Commit string
+}
+
+// Tags returns the tags of the repository in lexical order.
+func (r *Repo) Tags(ctx context.Context) ([]string, error) {
+	out, err := r.git(ctx, "tag", "--list")
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(out), nil
+}
+
+// ResolveTag returns the commit tagged name, peeling annotated tags, or
+// ErrNoTag.
+func (r *Repo) ResolveTag(ctx context.Context, name string) (string, error) {
+	commit, err := r.git(ctx,
+		"rev-parse", "--verify", "--quiet", "refs/tags/"+name+"^{commit}")
+
+	var exitErr *execx.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("%w: %s", ErrNoTag, name)
+	}
+
+	return commit, err
+}
+
+// RemoteTags lists the tags of the repository at url without cloning it,
+// in lexical order, with the commits of annotated tags.
+func RemoteTags(ctx context.Context, url string, opts ...Option) (
+	[]Ref, error,
+) {
+	s := newSettings(opts)
+	repo := &Repo{runner: s.runner()}
+
+	out, err := repo.run(ctx, "ls-remote", "--tags", "--", url)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTags(out), nil
+}
+
+// parseTags parses ls-remote lines, whose "^{}" entries give the commits
+// of the annotated tags listed before them.
+func parseTags(out string) []Ref {
+	var refs []Ref
+
+	index := make(map[string]int)
+
+	for _, line := range strings.Split(out, "\n") {
+		commit, ref, ok := strings.Cut(line, "\t")
+		name, found := strings.CutPrefix(ref, "refs/tags/")
+
+		if !ok || !found {
+			continue
+		}
+
+		if base, peeled := strings.CutSuffix(name, "^{}"); peeled {
+			if i, ok := index[base]; ok {
+				refs[i].Commit = commit
+			}
+
+			continue
+		}
+
+		index[name] = len(refs)
+		refs = append(refs, Ref{Name: name, Commit: commit})
+	}
+
+	return refs
+}
+
+// LatestTag returns the tag with the highest semantic version matching
+// constraint, such as "^1.4", ignoring tags that are not versions. An
+// empty constraint or "latest" matches every release but prereleases.
+func LatestTag(tags []string, constraint string) (string, error) {
+	check, err := matcher(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		best    string
+		version semver.Version
+	)
+
+	for _, tag := range tags {
+		v, err := semver.Parse(tag)
+		if err != nil || !check(v) {
+			continue
+		}
+
+		if best == "" || version.Less(v) {
+			best, version = tag, v
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("%w: %q", ErrNoTag, constraint)
+	}
+
+	return best, nil
+}
+
+// matcher returns the check of the versions allowed by constraint.
+func matcher(constraint string) (func(semver.Version) bool, error) {
+	if constraint == "" || constraint == "latest" {
+		return func(v semver.Version) bool { return v.Prerelease == "" }, nil
+	}
+
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("gitx: %w", err)
+	}
+
+	return c.Check, nil
+}
@@ -0,0 +1,157 @@
+package gitx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformed is returned by ParseStatus for output that is not the
+// porcelain v2 format.
+var ErrMalformed = errors.New("gitx: malformed status")
+
+// Kind is the kind of a status entry, as the first field of porcelain v2.
+type Kind byte
+
+// Kinds of status entries.
+const (
+	Changed   Kind = '1'
+	Renamed   Kind = '2'
+	Unmerged  Kind = 'u'
+	Untracked Kind = '?'
+	Ignored   Kind = '!'
+)
+
+// fields are the numbers of space-separated fields of the entry kinds,
+// the path included.
+var fields = map[Kind]int{Changed: 9, Renamed: 10, Unmerged: 11}
+
+// Entry is a path whose state differs from HEAD.
+type Entry struct {
+	// Kind is the kind of entry.
+	Kind Kind
+	// Index and Worktree are the XY status letters, such as 'M' for
+	// modified or '.' for unchanged, or '?' and '!' for untracked and
+	// ignored paths.
+	Index, Worktree byte
+	// Path is the path relative to the top of the work tree.
+	Path string
+	// Orig is the path a renamed or copied entry comes from.
+	Orig string
+}
+
+// Status is the state of a work tree.
+type Status struct {
+	// Commit is the commit checked out, or empty before the first commit.
+	Commit string
+	// Branch is the branch checked out, or empty when HEAD is detached.
+	Branch string
+	// Upstream is the upstream branch, such as "origin/main", if any.
+	Upstream string
+	// Ahead and Behind count the commits that differ from the upstream.
+	Ahead, Behind int
+	// Entries are the changed, untracked and ignored paths.
+	Entries []Entry
+}
+
+// Clean reports whether the work tree has no changes or untracked files.
+func (s *Status) Clean() bool {
+	for _, entry := range s.Entries {
+		if entry.Kind != Ignored {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Status returns the state of the work tree, listing untracked files one
+// by one.
+func (r *Repo) Status(ctx context.Context) (*Status, error) {
+	out, err := r.git(ctx, "status", "--porcelain=v2", "--branch", "-z",
+		"--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseStatus(out)
+}
+
+// ParseStatus parses the output of git status --porcelain=v2 --branch -z.
+func ParseStatus(out string) (*Status, error) {
+	status := &Status{}
+	records := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(record, "# "); ok {
+			status.header(header)
+
+			continue
+		}
+
+		entry, err := parseEntry(record)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Kind == Renamed && i+1 < len(records) {
+			i++
+			entry.Orig = records[i]
+		}
+
+		status.Entries = append(status.Entries, entry)
+	}
+
+	return status, nil
+}
+
+// header records a "# branch.*" header line.
+func (s *Status) header(line string) {
+	key, value, _ := strings.Cut(line, " ")
+
+	switch key {
+	case "branch.oid":
+		s.Commit = strings.TrimPrefix(value, "(initial)")
+	case "branch.head":
+		s.Branch = strings.TrimPrefix(value, "(detached)")
+	case "branch.upstream":
+		s.Upstream = value
+	case "branch.ab":
+		ahead, behind, _ := strings.Cut(value, " ")
+		s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+		s.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+	}
+}
+
+// parseEntry parses an entry record, except the original path of renames
+// that follows it.
+func parseEntry(record string) (Entry, error) {
+	if len(record) < 3 || record[1] != ' ' {
+		return Entry{}, fmt.Errorf("%w: %q", ErrMalformed, record)
+	}
+
+	kind := Kind(record[0])
+
+	switch kind {
+	case Untracked, Ignored:
+		return Entry{kind, byte(kind), byte(kind), record[2:], ""}, nil
+	case Changed, Renamed, Unmerged:
+		parts := strings.SplitN(record, " ", fields[kind])
+		if len(parts) != fields[kind] || len(parts[1]) != 2 {
+			return Entry{}, fmt.Errorf("%w: %q", ErrMalformed, record)
+		}
+
+		xy, path := parts[1], parts[len(parts)-1]
+
+		return Entry{kind, xy[0], xy[1], path, ""}, nil
+	default:
+		return Entry{}, fmt.Errorf("%w: %q", ErrMalformed, record)
+	}
+}
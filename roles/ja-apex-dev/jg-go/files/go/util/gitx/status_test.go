@@ -0,0 +1,81 @@
+package gitx_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/gitx"
+)
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	status, err := gitx.ParseStatus("# branch.oid 1f2e3d\x00" +
+		"# branch.head main\x00# branch.upstream origin/main\x00" +
+		"# branch.ab +2 -1\x00" +
+		"1 .M N... 100644 100644 100644 aaa aaa my file.go\x00" +
+		"2 R. N... 100644 100644 100644 bbb bbb R100 new.go\x00old.go\x00" +
+		"u UU N... 100644 100644 100644 100644 c1 c2 c3 conflict.go\x00" +
+		"? notes.txt\x00! build/\x00")
+	require.NoError(t, err)
+
+	assert.Equal(t, &gitx.Status{
+		Commit: "1f2e3d", Branch: "main", Upstream: "origin/main",
+		Ahead: 2, Behind: 1,
+		Entries: []gitx.Entry{
+			{Kind: gitx.Changed, Index: '.', Worktree: 'M', Path: "my file.go"},
+			{
+				Kind: gitx.Renamed, Index: 'R', Worktree: '.', Path: "new.go",
+				Orig: "old.go",
+			},
+			{
+				Kind: gitx.Unmerged, Index: 'U', Worktree: 'U',
+				Path: "conflict.go",
+			},
+			{
+				Kind: gitx.Untracked, Index: '?', Worktree: '?',
+				Path: "notes.txt",
+			},
+			{Kind: gitx.Ignored, Index: '!', Worktree: '!', Path: "build/"},
+		},
+	}, status)
+	assert.False(t, status.Clean())
+
+	status, err = gitx.ParseStatus("# branch.oid (initial)\x00" +
+		"# branch.head (detached)\x00! tmp\x00")
+	require.NoError(t, err)
+	assert.Equal(t, "", status.Commit)
+	assert.Equal(t, "", status.Branch)
+	assert.True(t, status.Clean())
+
+	for _, out := range []string{"x", "1 M file", "9 ab", "1 MM a b\x00"} {
+		_, err = gitx.ParseStatus(out)
+		require.ErrorIs(t, err, gitx.ErrMalformed, out)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	dir := upstream(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"),
+		[]byte("changed"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new file"),
+		[]byte("new"), 0o600))
+
+	repo, err := gitx.Open(t.Context(), dir)
+	require.NoError(t, err)
+
+	status, err := repo.Status(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "main", status.Branch)
+	assert.Equal(t, git(t, dir, "rev-parse", "HEAD"), status.Commit)
+	assert.Equal(t, []gitx.Entry{
+		{Kind: gitx.Changed, Index: '.', Worktree: 'M', Path: "file.txt"},
+		{Kind: gitx.Untracked, Index: '?', Worktree: '?', Path: "new file"},
+	}, status.Entries)
+}
@@ -0,0 +1,129 @@
+// Package goinstall installs Go toolchains from the official downloads:
+// it resolves versions such as "latest" or "1.22" against the go.dev
+// release feed, fetches the archive with checksum verification, unpacks it
+// into a versioned directory and points symbolic links at it. Plan
+// describes the changes without performing them, for dry runs.
+//
+// The layout below the root directory is:
+//
+//	go1.22.3/   the unpacked toolchain
+//	current ->  go1.22.3, the active version
+//
+// and, with WithBinDir, the bin directory links go and gofmt to
+// current/bin.
+package goinstall
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"example.com/go-template/util/download"
+	"example.com/go-template/util/httpx"
+)
+
+// DefaultBaseURL is the location of the official downloads and their
+// feed.
+const DefaultBaseURL = "https://go.dev/dl/"
+
+// CurrentLink is the name of the link to the active version.
+const CurrentLink = "current"
+
+// binaries are linked into the bin directory.
+var binaries = []string{"go", "gofmt"}
+
+// Option configures an Installer.
+type Option func(*Installer)
+
+// WithRoot sets the directory holding the versions, ~/sdk by default as
+// for golang.org/dl.
+func WithRoot(dir string) Option {
+	return func(i *Installer) {
+		i.root = dir
+	}
+}
+
+// WithBinDir links the binaries of the active version into dir, such as
+// ~/.local/bin.
+func WithBinDir(dir string) Option {
+	return func(i *Installer) {
+		i.binDir = dir
+	}
+}
+
+// WithCacheDir sets where archives are downloaded, the goinstall directory
+// of the user cache by default.
+func WithCacheDir(dir string) Option {
+	return func(i *Installer) {
+		i.cacheDir = dir
+	}
+}
+
+// WithPlatform installs toolchains for goos and goarch instead of the
+// current platform.
+func WithPlatform(goos, goarch string) Option {
+	return func(i *Installer) {
+		i.goos, i.goarch = goos, goarch
+	}
+}
+
+// WithBaseURL fetches the feed and the archives from a mirror of
+// DefaultBaseURL.
+func WithBaseURL(url string) Option {
+	return func(i *Installer) {
+		i.baseURL = url
+	}
+}
+
+// WithClient sets the HTTP client of the feed and the downloads.
+func WithClient(client *http.Client) Option {
+	return func(i *Installer) {
+		i.client = client
+	}
+}
+
+// Installer installs Go toolchains into a root directory.
+type Installer struct {
+	root     string
+	binDir   string
+	cacheDir string
+	goos     string
+	goarch   string
+	baseURL  string
+	client   *http.Client
+}
+
+// New returns an installer.
+func New(opts ...Option) *Installer {
+	i := &Installer{
+		goos: runtime.GOOS, goarch: runtime.GOARCH, baseURL: DefaultBaseURL,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && i.root == "" {
+		i.root = filepath.Join(home, "sdk")
+	}
+
+	if cache, err := os.UserCacheDir(); err == nil && i.cacheDir == "" {
+		i.cacheDir = filepath.Join(cache, "goinstall")
+	}
+
+	if i.client == nil {
+		i.client = httpx.NewClient(httpx.WithTimeout(0))
+	}
+
+	return i
+}
+
+// Root returns the directory holding the versions.
+func (i *Installer) Root() string {
+	return i.root
+}
+
+func (i *Installer) downloader() *download.Downloader {
+	return download.New(download.WithClient(i.client))
+}
@@ -0,0 +1,219 @@
+package goinstall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util/archive"
+	"example.com/go-template/util/download"
+	"example.com/go-template/util/fsx"
+	"example.com/go-template/util/state"
+)
+
+// Plan describes an installation.
+type Plan struct {
+	Release Release
+	Archive File
+	// URL is the location of the archive.
+	URL string
+	// Dir is the directory of the version.
+	Dir string
+	// Download reports that the version is not installed yet.
+	Download bool
+	// Links are the changes of the symbolic links.
+	Links state.Changes
+
+	resources []state.Resource
+}
+
+// Empty reports whether the plan changes nothing.
+func (p Plan) Empty() bool {
+	return !p.Download && len(p.Links) == 0
+}
+
+// String prints one step per line.
+func (p Plan) String() string {
+	steps := []string{}
+	if p.Download {
+		steps = append(steps, fmt.Sprintf("install %s into %s from %s",
+			p.Release.Version, p.Dir, p.URL))
+	}
+
+	for _, change := range p.Links {
+		steps = append(steps, change.String())
+	}
+
+	return strings.Join(steps, "\n")
+}
+
+// Plan resolves spec, as documented by Resolve, and returns the steps
+// installing and activating it without performing them.
+func (i *Installer) Plan(ctx context.Context, spec string) (Plan, error) {
+	releases, err := fetchReleases(ctx, i.client,
+		i.baseURL+"?mode=json&include=all")
+	if err != nil {
+		return Plan{}, err
+	}
+
+	release, err := Resolve(releases, spec)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	file, err := release.Archive(i.goos, i.goarch)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	p := Plan{
+		Release: release, Archive: file, URL: i.baseURL + file.Filename,
+		Dir: filepath.Join(i.root, release.Version),
+	}
+
+	installed, err := fsx.IsDir(p.Dir)
+	if err != nil {
+		return Plan{}, fmt.Errorf("goinstall: %w", err)
+	}
+
+	p.Download = !installed
+	p.resources = i.links(release.Version)
+
+	if p.Links, err = state.Plan(p.resources...); err != nil {
+		return Plan{}, fmt.Errorf("goinstall: %w", err)
+	}
+
+	return p, nil
+}
+
+// Install installs the version resolved from spec unless it is already,
+// activates it and returns the plan that was carried out, whose Links are
+// the changes actually made.
+func (i *Installer) Install(ctx context.Context, spec string) (Plan, error) {
+	p, err := i.Plan(ctx, spec)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if p.Download {
+		if err := i.unpack(ctx, p); err != nil {
+			return Plan{}, err
+		}
+	}
+
+	if p.Links, err = state.Apply(ctx, p.resources...); err != nil {
+		return Plan{}, fmt.Errorf("goinstall: %w", err)
+	}
+
+	return p, nil
+}
+
+// links declares the symbolic links activating version.
+func (i *Installer) links(version string) []state.Resource {
+	current := filepath.Join(i.root, CurrentLink)
+	resources := []state.Resource{
+		state.Dir(i.root), state.Symlink(current, version),
+	}
+
+	if i.binDir == "" {
+		return resources
+	}
+
+	resources = append(resources, state.Dir(i.binDir))
+
+	for _, name := range binaries {
+		if i.goos == "windows" {
+			name += ".exe"
+		}
+
+		resources = append(resources, state.Symlink(
+			filepath.Join(i.binDir, name), filepath.Join(current, "bin", name)))
+	}
+
+	return resources
+}
+
+// unpack downloads the archive of a plan and extracts it into a temporary
+// directory renamed to the directory of the version, so that an
+// interrupted install never looks complete.
+func (i *Installer) unpack(ctx context.Context, p Plan) error {
+	result, err := i.downloader().Fetch(ctx, download.Request{
+		URL: p.URL, Dest: filepath.Join(i.cacheDir, p.Archive.Filename),
+		SHA256: p.Archive.SHA256,
+	})
+	if err != nil {
+		return fmt.Errorf("goinstall: %w", err)
+	}
+
+	if err := os.MkdirAll(i.root, 0o755); err != nil {
+		return fmt.Errorf("goinstall: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(i.root, "."+p.Release.Version+".tmp-")
+	if err != nil {
+		return fmt.Errorf("goinstall: %w", err)
+	}
+
+	// MkdirTemp creates the directory private to the user.
+	err = os.Chmod(tmp, 0o755)
+	if err == nil {
+		err = archive.Extract(ctx, result.Path, tmp,
+			archive.WithStripComponents(1))
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, p.Dir)
+	}
+
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+
+		return fmt.Errorf("goinstall: %w", err)
+	}
+
+	return nil
+}
+
+// Installed returns the installed versions, oldest first.
+func (i *Installer) Installed() ([]string, error) {
+	entries, err := os.ReadDir(i.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("goinstall: %w", err)
+	}
+
+	var versions []string
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "go") {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	slices.SortFunc(versions, compareVersions)
+
+	return versions, nil
+}
+
+// Current returns the active version, or an empty string when there is
+// none.
+func (i *Installer) Current() (string, error) {
+	target, err := os.Readlink(filepath.Join(i.root, CurrentLink))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("goinstall: %w", err)
+	}
+
+	return filepath.Base(target), nil
+}
@@ -0,0 +1,141 @@
+package goinstall_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/archive"
+	"example.com/go-template/util/goinstall"
+	"example.com/go-template/util/hashx"
+	"example.com/go-template/util/testx"
+)
+
+// mirror serves a release feed with one archive per version, holding a
+// go/bin/go script printing the version.
+func mirror(t *testing.T, versions ...string) string {
+	t.Helper()
+
+	files := make(map[string][]byte)
+
+	var feed []goinstall.Release
+
+	for _, version := range versions {
+		src := testx.TempDirWithFiles(t, map[string]string{
+			"go/bin/go":    "echo " + version + "\n",
+			"go/bin/gofmt": "",
+		})
+
+		var buf bytes.Buffer
+		require.NoError(t, archive.CreateTarGz(t.Context(), &buf, src))
+
+		name := version + ".linux-amd64.tar.gz"
+		files[name] = buf.Bytes()
+		feed = append(feed, goinstall.Release{
+			Version: version, Stable: true, Files: []goinstall.File{{
+				Filename: name, OS: "linux", Arch: "amd64", Kind: "archive",
+				SHA256: hashx.SHA256(buf.Bytes()),
+			}},
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/dl/" && r.URL.Query().Get("mode") == "json" {
+				_ = json.NewEncoder(w).Encode(feed)
+
+				return
+			}
+
+			data, ok := files[filepath.Base(r.URL.Path)]
+			if !ok {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			_, _ = w.Write(data)
+		}))
+	t.Cleanup(server.Close)
+
+	return server.URL + "/dl/"
+}
+
+func TestInstall(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	installer := goinstall.New(
+		goinstall.WithBaseURL(mirror(t, "go1.22.3", "go1.21.9")),
+		goinstall.WithRoot(filepath.Join(dir, "sdk")),
+		goinstall.WithBinDir(filepath.Join(dir, "bin")),
+		goinstall.WithCacheDir(filepath.Join(dir, "cache")),
+		goinstall.WithPlatform("linux", "amd64"))
+
+	plan, err := installer.Plan(t.Context(), "latest")
+	require.NoError(t, err)
+	assert.True(t, plan.Download)
+	assert.Contains(t, plan.String(), "install go1.22.3 into "+
+		filepath.Join(dir, "sdk", "go1.22.3"))
+	assert.NoDirExists(t, installer.Root(), "planning changes nothing")
+
+	plan, err = installer.Install(t.Context(), "latest")
+	require.NoError(t, err)
+	assert.NotEmpty(t, plan.Links)
+
+	data, err := os.ReadFile(filepath.Join(dir, "bin", "go"))
+	require.NoError(t, err)
+	assert.Equal(t, "echo go1.22.3\n", string(data))
+
+	info, err := os.Stat(plan.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm(),
+		"other users can run the toolchain")
+
+	plan, err = installer.Plan(t.Context(), "1.22")
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+
+	_, err = installer.Install(t.Context(), "1.21")
+	require.NoError(t, err)
+
+	versions, err := installer.Installed()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go1.21.9", "go1.22.3"}, versions)
+
+	current, err := installer.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "go1.21.9", current)
+}
+
+func TestInstallErrors(t *testing.T) {
+	t.Parallel()
+
+	base := mirror(t, "go1.22.3")
+	root := t.TempDir()
+	installer := goinstall.New(goinstall.WithBaseURL(base),
+		goinstall.WithRoot(root), goinstall.WithPlatform("linux", "arm64"))
+
+	_, err := installer.Install(t.Context(), "latest")
+	require.ErrorIs(t, err, goinstall.ErrNoArchive)
+
+	_, err = goinstall.New(goinstall.WithBaseURL(base+"missing/")).
+		Plan(t.Context(), "latest")
+	require.Error(t, err)
+
+	current, err := installer.Current()
+	require.NoError(t, err)
+	assert.Empty(t, current)
+
+	versions, err := goinstall.New(goinstall.WithRoot(
+		filepath.Join(root, "missing"))).Installed()
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
@@ -0,0 +1,135 @@
+package goinstall
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util/semver"
+)
+
+var (
+	// ErrNoRelease is returned when no release matches a version spec.
+	ErrNoRelease = errors.New("goinstall: no matching release")
+	// ErrNoArchive is returned when a release has no archive for the
+	// platform.
+	ErrNoArchive = errors.New("goinstall: no archive for platform")
+)
+
+// Release is a Go release as listed by the download feed.
+type Release struct {
+	// Version is the release name, such as "go1.22.3".
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+	Files   []File `json:"files"`
+}
+
+// File is a downloadable file of a release.
+type File struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	// Kind is "archive", "installer" or "source".
+	Kind string `json:"kind"`
+}
+
+// Archive returns the archive of the release for goos and goarch.
+func (r Release) Archive(goos, goarch string) (File, error) {
+	for _, f := range r.Files {
+		if f.Kind == "archive" && f.OS == goos && f.Arch == goarch {
+			return f, nil
+		}
+	}
+
+	return File{}, fmt.Errorf("%w: %s %s/%s", ErrNoArchive, r.Version, goos,
+		goarch)
+}
+
+// fetchReleases reads the release feed at url.
+func fetchReleases(
+	ctx context.Context, client *http.Client, url string,
+) ([]Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("goinstall: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("goinstall: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("goinstall: %s: %s", url, resp.Status)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("goinstall: decode releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+// Resolve picks the release matching spec among releases:
+//
+//   - "latest" is the newest stable release;
+//   - a minor version such as "1.22" is its newest stable patch release;
+//   - a full version such as "1.22.3", "go1.22.3" or "1.23rc1" is that
+//     exact release, stable or not.
+func Resolve(releases []Release, spec string) (Release, error) {
+	spec = strings.TrimPrefix(strings.TrimSpace(spec), "go")
+	series := spec == "latest" || minorVersion.MatchString(spec)
+
+	var candidates []Release
+
+	for _, r := range releases {
+		name := strings.TrimPrefix(r.Version, "go")
+
+		switch {
+		case !series && name == spec:
+			return r, nil
+		case series && r.Stable && inSeries(name, spec):
+			candidates = append(candidates, r)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return Release{}, fmt.Errorf("%w: %q", ErrNoRelease, spec)
+	}
+
+	return slices.MaxFunc(candidates, func(a, b Release) int {
+		return compareVersions(a.Version, b.Version)
+	}), nil
+}
+
+// minorVersion matches specs naming a release series.
+var minorVersion = regexp.MustCompile(`^\d+\.\d+$`)
+
+// inSeries reports whether the release name belongs to a series spec,
+// which is either "latest" or a minor version. Before Go 1.21, the first
+// release of a series had no patch number, as in go1.20.
+func inSeries(name, spec string) bool {
+	return spec == "latest" || name == spec || strings.HasPrefix(name, spec+".")
+}
+
+// compareVersions orders stable Go versions such as "go1.9" and
+// "go1.22.3" numerically.
+func compareVersions(a, b string) int {
+	va, errA := semver.Coerce(a)
+	vb, errB := semver.Coerce(b)
+
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	return va.Compare(vb)
+}
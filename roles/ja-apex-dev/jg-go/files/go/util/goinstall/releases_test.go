@@ -0,0 +1,64 @@
+package goinstall_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/goinstall"
+)
+
+var releases = []goinstall.Release{
+	{Version: "go1.23rc1"},
+	{Version: "go1.22.10", Stable: true},
+	{Version: "go1.22.9", Stable: true},
+	{Version: "go1.21.0", Stable: true},
+	{Version: "go1.20.14", Stable: true},
+	{Version: "go1.20", Stable: true},
+	{Version: "go1.9", Stable: true},
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"latest":    "go1.22.10",
+		"1.22":      "go1.22.10",
+		"go1.20":    "go1.20.14",
+		"1.22.9":    "go1.22.9",
+		"go1.23rc1": "go1.23rc1",
+		" 1.9 ":     "go1.9",
+	}
+
+	for spec, want := range cases {
+		release, err := goinstall.Resolve(releases, spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, want, release.Version, spec)
+	}
+
+	for _, spec := range []string{"1.19", "1.22.11", "1.2"} {
+		_, err := goinstall.Resolve(releases, spec)
+		require.ErrorIs(t, err, goinstall.ErrNoRelease, spec)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+
+	files := []goinstall.File{
+		{Filename: "go1.22.3.src.tar.gz", Kind: "source"},
+		{Filename: "go1.22.3.linux-amd64.msi", OS: "linux", Arch: "amd64",
+			Kind: "installer"},
+		{Filename: "go1.22.3.linux-amd64.tar.gz", OS: "linux", Arch: "amd64",
+			Kind: "archive"},
+	}
+	release := goinstall.Release{Version: "go1.22.3", Files: files}
+
+	file, err := release.Archive("linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, "go1.22.3.linux-amd64.tar.gz", file.Filename)
+
+	_, err = release.Archive("plan9", "arm")
+	require.ErrorIs(t, err, goinstall.ErrNoArchive)
+}
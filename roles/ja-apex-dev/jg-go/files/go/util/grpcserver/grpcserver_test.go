@@ -0,0 +1,339 @@
+package grpcserver_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"example.com/go-template/util/grpcserver"
+	"example.com/go-template/util/health"
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/metrics"
+	"example.com/go-template/util/testx"
+)
+
+const echoMethod = "/test.Echo/Echo"
+
+// echoService answers its input, and panics on "panic", without generated
+// code.
+var echoService = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(
+			_ any, ctx context.Context, decode func(any) error,
+			interceptor grpc.UnaryServerInterceptor,
+		) (any, error) {
+			in := new(wrapperspb.StringValue)
+			if err := decode(in); err != nil {
+				return nil, err
+			}
+
+			echo := func(_ context.Context, req any) (any, error) {
+				value, _ := req.(*wrapperspb.StringValue)
+				if value.GetValue() == "panic" {
+					panic("boom")
+				}
+
+				return value, nil
+			}
+
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{
+				FullMethod: echoMethod,
+			}, echo)
+		},
+	}},
+}
+
+// start runs server in a runner until the test ends, returning its
+// address.
+func start(t *testing.T, server *grpcserver.Server) net.Addr {
+	t.Helper()
+
+	server.RegisterService(&echoService, struct{}{})
+
+	runner := lifecycle.New(lifecycle.WithSignals())
+	server.Register(runner)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runner.Run(context.Background())
+	}()
+
+	t.Cleanup(func() {
+		runner.Shutdown(nil)
+		assert.NoError(t, <-done)
+	})
+
+	testx.RequireEventually(t, func() bool {
+		return server.Addr() != nil
+	}, time.Second)
+
+	return server.Addr()
+}
+
+func dial(
+	t *testing.T, addr net.Addr, creds credentials.TransportCredentials,
+) *grpc.ClientConn {
+	t.Helper()
+
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(addr.String(),
+		grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func echo(ctx context.Context, conn *grpc.ClientConn, value string) error {
+	out := new(wrapperspb.StringValue)
+
+	err := conn.Invoke(ctx, echoMethod, wrapperspb.String(value), out)
+	if err == nil && out.GetValue() != value {
+		return errors.New("wrong echo " + out.GetValue())
+	}
+
+	return err
+}
+
+func check(
+	t *testing.T, conn *grpc.ClientConn, service string,
+) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(t.Context(),
+		&healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+
+	return resp.GetStatus()
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	server := grpcserver.New(
+		grpcserver.WithAddr("127.0.0.1:0"),
+		grpcserver.WithLogger(sink.Logger()),
+		grpcserver.WithMetrics(registry),
+		grpcserver.WithShutdownTimeout(time.Second))
+
+	assert.Nil(t, server.Addr())
+
+	conn := dial(t, start(t, server), nil)
+
+	require.NoError(t, echo(t.Context(), conn, "hello"))
+
+	err := echo(t.Context(), conn, "panic")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, sink.Messages(), "grpc handler panicked")
+	assert.Contains(t, sink.Messages(), "grpc call")
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, conn, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING,
+		check(t, conn, "test.Echo"))
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequestWithContext(
+		t.Context(), http.MethodGet, metrics.Path, nil))
+	assert.Contains(t, recorder.Body.String(),
+		`grpc_server_handled_total{code="Internal",method="/test.Echo/Echo"} 1`)
+
+	// Reflection is off by default.
+	_, err = reflection(t, conn)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// reflection lists the services through the reflection service.
+func reflection(t *testing.T, conn *grpc.ClientConn) ([]string, error) {
+	t.Helper()
+
+	client, err := reflectionpb.NewServerReflectionClient(conn).
+		ServerReflectionInfo(t.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	resp, err := client.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		names = append(names, service.GetName())
+	}
+
+	return names, client.CloseSend()
+}
+
+func TestServerReflection(t *testing.T) {
+	t.Parallel()
+
+	server := grpcserver.New(
+		grpcserver.WithAddr("127.0.0.1:0"),
+		grpcserver.WithReflection())
+
+	names, err := reflection(t, dial(t, start(t, server), nil))
+	require.NoError(t, err)
+	assert.Contains(t, names, "test.Echo")
+	assert.Contains(t, names, "grpc.health.v1.Health")
+}
+
+func TestServerHealth(t *testing.T) {
+	t.Parallel()
+
+	registry := health.New()
+	registry.AddReadiness("db", func(context.Context) error {
+		return errors.New("down")
+	})
+
+	server := grpcserver.New(
+		grpcserver.WithAddr("127.0.0.1:0"),
+		grpcserver.WithHealth(registry))
+	conn := dial(t, start(t, server), nil)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING,
+		check(t, conn, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING,
+		check(t, conn, "test.Echo"))
+
+	_, err := healthpb.NewHealthClient(conn).Check(t.Context(),
+		&healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServerAuth(t *testing.T) {
+	t.Parallel()
+
+	server := grpcserver.New(
+		grpcserver.WithAddr("127.0.0.1:0"),
+		grpcserver.WithAuth(func(ctx context.Context, method string) (
+			context.Context, error,
+		) {
+			assert.Equal(t, echoMethod, method)
+
+			token, _ := grpcserver.BearerToken(ctx)
+			switch token {
+			case "secret":
+				return ctx, nil
+			case "":
+				return nil, errors.New("no token")
+			default:
+				return nil, status.Error(codes.PermissionDenied, "wrong token")
+			}
+		}))
+	conn := dial(t, start(t, server), nil)
+
+	err := echo(t.Context(), conn, "hello")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(),
+		"authorization", "Bearer other")
+	err = echo(ctx, conn, "hello")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(t.Context(),
+		"authorization", "bearer secret")
+	require.NoError(t, echo(ctx, conn, "hello"))
+
+	// The health service stays public.
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, conn, ""))
+}
+
+func TestServerTLS(t *testing.T) {
+	t.Parallel()
+
+	// Borrow the self-signed certificate of httptest.
+	reference := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(reference.Close)
+
+	server := grpcserver.New(
+		grpcserver.WithAddr("127.0.0.1:0"),
+		grpcserver.WithTLS(&tls.Config{
+			Certificates: reference.TLS.Certificates,
+		}))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(reference.Certificate())
+
+	conn := dial(t, start(t, server), credentials.NewTLS(&tls.Config{
+		RootCAs:    roots,
+		ServerName: "example.com",
+		MinVersion: tls.VersionTLS12,
+	}))
+	require.NoError(t, echo(t.Context(), conn, "hello"))
+}
+
+func TestServerStartFailure(t *testing.T) {
+	t.Parallel()
+
+	server := grpcserver.New(grpcserver.WithAddr("256.0.0.1:0"))
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	require.Error(t, server.Run(ctx))
+}
+
+func TestBearerToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer abc", "abc", true},
+		{"BEARER abc", "abc", true},
+		{"Basic abc", "", false},
+		{"Bearer ", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(t.Context(),
+			metadata.Pairs("authorization", tt.header))
+
+		token, ok := grpcserver.BearerToken(ctx)
+		assert.Equal(t, tt.token, token, tt.header)
+		assert.Equal(t, tt.ok, ok, tt.header)
+	}
+}
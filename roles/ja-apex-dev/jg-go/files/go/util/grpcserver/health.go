@@ -0,0 +1,61 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"example.com/go-template/util/health"
+)
+
+// healthService is the standard health service, reporting every service
+// as serving between start and stop. Checks of the overall status, the
+// empty service name, also run the readiness checks of the registry.
+// Watch only follows start and stop.
+type healthService struct {
+	*grpchealth.Server
+
+	registry *health.Registry
+}
+
+func newHealthService(registry *health.Registry) *healthService {
+	h := &healthService{Server: grpchealth.NewServer(), registry: registry}
+	h.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return h
+}
+
+// register adds the service to server.
+func (h *healthService) register(server *grpc.Server) {
+	healthpb.RegisterHealthServer(server, h)
+}
+
+// resume reports the services of server as serving.
+func (h *healthService) resume(server *grpc.Server) {
+	h.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	for name := range server.GetServiceInfo() {
+		h.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+// Check implements healthpb.HealthServer.
+func (h *healthService) Check(
+	ctx context.Context, req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	resp, err := h.Server.Check(ctx, req)
+	if err != nil || req.GetService() != "" || h.registry == nil ||
+		resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return resp, err
+	}
+
+	if !h.registry.Readiness(ctx).OK() {
+		return &healthpb.HealthCheckResponse{
+			Status: healthpb.HealthCheckResponse_NOT_SERVING,
+		}, nil
+	}
+
+	return resp, nil
+}
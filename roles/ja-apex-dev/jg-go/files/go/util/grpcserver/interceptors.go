@@ -0,0 +1,229 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthFunc authenticates the call of the full method name, such as
+// "/pkg.Service/Method", returning the context of the handler, which may
+// carry the identity of the caller. Errors without a gRPC status are
+// reported as codes.Unauthenticated.
+type AuthFunc func(ctx context.Context, method string) (context.Context, error)
+
+// BearerToken returns the token of the "authorization: Bearer <token>"
+// metadata of the incoming call, for AuthFunc implementations.
+func BearerToken(ctx context.Context) (string, bool) {
+	for _, value := range metadata.ValueFromIncomingContext(
+		ctx, "authorization") {
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, "bearer") && token != "" {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+// interceptor wraps the handling of a unary or stream call, next running
+// the handler with the given context.
+type interceptor func(
+	ctx context.Context, method string, next func(context.Context) error,
+) error
+
+// interceptors returns the default stack, the first one being the
+// outermost.
+func (s *settings) interceptors() []interceptor {
+	chain := []interceptor{logging(s.logger)}
+
+	if s.metrics != nil {
+		chain = append(chain, s.instrument())
+	}
+
+	chain = append(chain, recovery(s.logger))
+
+	if s.auth != nil {
+		chain = append(chain, authenticate(s.auth))
+	}
+
+	return chain
+}
+
+// unary adapts chain to unary calls.
+func unary(chain []interceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req any, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		var resp any
+
+		call := func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+
+			return err
+		}
+		err := run(ctx, info.FullMethod, chain, call)
+
+		return resp, err
+	}
+}
+
+// stream adapts chain to streaming calls.
+func stream(chain []interceptor) grpc.StreamServerInterceptor {
+	return func(
+		srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return run(ss.Context(), info.FullMethod, chain,
+			func(ctx context.Context) error {
+				return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+			})
+	}
+}
+
+// run calls handler through chain.
+func run(
+	ctx context.Context, method string, chain []interceptor,
+	handler func(context.Context) error,
+) error {
+	next := handler
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		inner, wrap := next, chain[i]
+		next = func(ctx context.Context) error {
+			return wrap(ctx, method, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// logging logs every call once handled, at info level, or warn level for
+// the codes blaming the server, with its method, code and duration.
+func logging(logger *slog.Logger) interceptor {
+	return func(
+		ctx context.Context, method string, next func(context.Context) error,
+	) error {
+		start := time.Now()
+		err := next(ctx)
+		code := status.Code(err)
+
+		level := slog.LevelInfo
+		if serverFault(code) {
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(ctx, level, "grpc call",
+			slog.String("method", method),
+			slog.String("code", code.String()),
+			slog.Duration("elapsed", time.Since(start)))
+
+		return err
+	}
+}
+
+// serverFault reports whether code blames the server, like the 5xx HTTP
+// statuses.
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// instrument counts and times the calls.
+func (s *settings) instrument() interceptor {
+	handled := s.metrics.Counter("grpc_server_handled_total",
+		"Number of gRPC calls handled.", "method", "code")
+	seconds := s.metrics.Histogram("grpc_server_handling_seconds",
+		"Duration of the gRPC calls.", nil, "method")
+
+	return func(
+		ctx context.Context, method string, next func(context.Context) error,
+	) error {
+		start := time.Now()
+		err := next(ctx)
+
+		seconds.ObserveSince(start, method)
+		handled.Inc(method, status.Code(err).String())
+
+		return err
+	}
+}
+
+// recovery turns panics of the handlers into codes.Internal errors,
+// logging the panic value and stack at error level.
+func recovery(logger *slog.Logger) interceptor {
+	return func(
+		ctx context.Context, method string, next func(context.Context) error,
+	) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				logger.ErrorContext(ctx, "grpc handler panicked",
+					"method", method,
+					"panic", fmt.Sprint(value),
+					"stack", string(debug.Stack()))
+
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return next(ctx)
+	}
+}
+
+// publicServices are the services answering without authentication.
+var publicServices = []string{
+	"/" + healthpb.Health_ServiceDesc.ServiceName + "/",
+	"/grpc.reflection.",
+}
+
+// authenticate rejects the calls auth fails, but those of publicServices.
+func authenticate(auth AuthFunc) interceptor {
+	return func(
+		ctx context.Context, method string, next func(context.Context) error,
+	) error {
+		for _, prefix := range publicServices {
+			if strings.HasPrefix(method, prefix) {
+				return next(ctx)
+			}
+		}
+
+		ctx, err := auth(ctx, method)
+		if err != nil {
+			if _, ok := status.FromError(err); !ok {
+				return status.Error(codes.Unauthenticated, err.Error())
+			}
+
+			return err
+		}
+
+		return next(ctx)
+	}
+}
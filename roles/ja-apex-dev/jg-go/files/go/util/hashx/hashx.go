@@ -0,0 +1,88 @@
+// Package hashx computes SHA-256 checksums and HMACs and compares digests in
+// constant time.
+package hashx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned by VerifyFile when the digests differ.
+var ErrChecksumMismatch = errors.New("hashx: checksum mismatch")
+
+// SHA256 returns the hex-encoded SHA-256 digest of data.
+func SHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// SHA256Reader streams r and returns its hex-encoded SHA-256 digest.
+func SHA256Reader(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", fmt.Errorf("hashx: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// SHA256File returns the hex-encoded SHA-256 digest of the file at path.
+func SHA256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("hashx: %w", err)
+	}
+	defer file.Close()
+
+	return SHA256Reader(file)
+}
+
+// VerifyFile checks the file at path against a hex-encoded SHA-256 digest,
+// as published next to downloadable artifacts. The comparison ignores case.
+func VerifyFile(path, expected string) error {
+	actual, err := SHA256File(path)
+	if err != nil {
+		return err
+	}
+
+	if !Equal(actual, strings.ToLower(strings.TrimSpace(expected))) {
+		return fmt.Errorf("%w: %s is %s, want %s",
+			ErrChecksumMismatch, path, actual, expected)
+	}
+
+	return nil
+}
+
+// HMACSHA256 returns the HMAC-SHA256 of data under key.
+func HMACSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// HMACSHA256Hex is HMACSHA256 with a hex-encoded result, as commonly sent
+// in webhook signature headers.
+func HMACSHA256Hex(key, data []byte) string {
+	return hex.EncodeToString(HMACSHA256(key, data))
+}
+
+// VerifyHMACSHA256 reports whether mac is the HMAC-SHA256 of data under
+// key, in constant time.
+func VerifyHMACSHA256(key, data, mac []byte) bool {
+	return hmac.Equal(HMACSHA256(key, data), mac)
+}
+
+// Equal compares two secrets or digests in constant time. Only the length
+// of the inputs may leak through timing.
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,72 @@
+package hashx_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/hashx"
+)
+
+// Digest of "hello".
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e" +
+	"1b161e5c1fa7425e73043362938b9824"
+
+func TestSHA256(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, helloSHA256, hashx.SHA256([]byte("hello")))
+
+	sum, err := hashx.SHA256Reader(strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, helloSHA256, sum)
+
+	_, err = hashx.SHA256Reader(iotest.ErrReader(errors.New("boom")))
+	require.ErrorContains(t, err, "boom")
+}
+
+func TestVerifyFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	sum, err := hashx.SHA256File(path)
+	require.NoError(t, err)
+	assert.Equal(t, helloSHA256, sum)
+
+	require.NoError(t, hashx.VerifyFile(path, strings.ToUpper(helloSHA256)))
+	require.ErrorIs(t, hashx.VerifyFile(path, hashx.SHA256(nil)),
+		hashx.ErrChecksumMismatch)
+	require.ErrorIs(t, hashx.VerifyFile(path+".missing", helloSHA256),
+		os.ErrNotExist)
+}
+
+func TestHMAC(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("key")
+	data := []byte("The quick brown fox jumps over the lazy dog")
+
+	assert.Equal(t,
+		"f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		hashx.HMACSHA256Hex(key, data))
+
+	mac := hashx.HMACSHA256(key, data)
+	assert.True(t, hashx.VerifyHMACSHA256(key, data, mac))
+	assert.False(t, hashx.VerifyHMACSHA256([]byte("other"), data, mac))
+}
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, hashx.Equal("token", "token"))
+	assert.False(t, hashx.Equal("token", "tokem"))
+	assert.False(t, hashx.Equal("token", "token2"))
+}
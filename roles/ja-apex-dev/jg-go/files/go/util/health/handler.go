@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Endpoint paths served by Handler.
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Handler serves the liveness report on LivenessPath and the readiness
+// report on ReadinessPath as JSON, answering 200 when healthy and 503
+// otherwise so that load balancers and orchestrators need not parse the
+// body.
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+LivenessPath, reportHandler(r.Liveness))
+	mux.Handle("GET "+ReadinessPath, reportHandler(r.Readiness))
+
+	return mux
+}
+
+// reportHandler serves the report returned by evaluate.
+func reportHandler(evaluate func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := evaluate(req.Context())
+
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+
+		// The status line already went out, so encoding errors, only
+		// caused by a gone client, cannot be reported.
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
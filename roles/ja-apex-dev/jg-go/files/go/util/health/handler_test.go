@@ -0,0 +1,59 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/health"
+)
+
+func get(t *testing.T, handler http.Handler, path string) (int, health.Report) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder,
+		httptest.NewRequestWithContext(t.Context(), http.MethodGet, path, nil))
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	return recorder.Code, report
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	registry := health.New()
+	registry.AddLiveness("process", healthy)
+	registry.AddReadiness("db", func(context.Context) error {
+		return errDown
+	})
+
+	handler := registry.Handler()
+
+	code, report := get(t, handler, health.LivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, report.Checks["process"].Status)
+
+	code, report = get(t, handler, health.ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, "database down", report.Checks["db"].Error)
+}
+
+func TestHandlerMethods(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	health.New().Handler().ServeHTTP(recorder, httptest.NewRequestWithContext(
+		t.Context(), http.MethodPost, health.LivenessPath, nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -0,0 +1,201 @@
+// Package health collects the liveness and readiness checks of service
+// components and serves their aggregate state on the standard /healthz
+// and /readyz endpoints.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds every check unless overridden.
+const DefaultTimeout = 5 * time.Second
+
+// ErrPanic wraps a panic recovered from a check.
+var ErrPanic = errors.New("health: check panicked")
+
+// Check reports the health of a component, returning nil when healthy. It
+// must honor the cancellation of ctx.
+type Check func(ctx context.Context) error
+
+// Status is the outcome of a check or of a whole report.
+type Status string
+
+// Check statuses.
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status   Status        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report aggregates the results of a set of checks, failing when any of
+// them fails.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// OK reports whether every check passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Option configures a check.
+type Option func(*entry)
+
+// WithTimeout bounds the check, DefaultTimeout by default. A check that
+// outlives it fails with context.DeadlineExceeded.
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *entry) {
+		e.timeout = timeout
+	}
+}
+
+// entry is a registered check.
+type entry struct {
+	check   Check
+	timeout time.Duration
+}
+
+// Registry holds the checks of a service. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	liveness  map[string]entry
+	readiness map[string]entry
+}
+
+// New returns an empty registry, which reports healthy and ready.
+func New() *Registry {
+	return &Registry{
+		liveness:  make(map[string]entry),
+		readiness: make(map[string]entry),
+	}
+}
+
+// AddLiveness registers a check telling whether the process works at all,
+// such as a deadlock detector. Failing liveness usually gets the process
+// restarted, so it must not depend on external services. A check already
+// registered under name is replaced.
+func (r *Registry) AddLiveness(name string, check Check, opts ...Option) {
+	r.add(r.liveness, name, check, opts)
+}
+
+// AddReadiness registers a check telling whether the process can serve
+// traffic, such as a database ping. Failing readiness only takes the
+// process out of rotation. A check already registered under name is
+// replaced.
+func (r *Registry) AddReadiness(name string, check Check, opts ...Option) {
+	r.add(r.readiness, name, check, opts)
+}
+
+// Remove unregisters the liveness and readiness checks named name.
+func (r *Registry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.liveness, name)
+	delete(r.readiness, name)
+}
+
+// Liveness runs the liveness checks concurrently.
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return evaluate(ctx, r.snapshot(r.liveness))
+}
+
+// Readiness runs the readiness checks concurrently.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	return evaluate(ctx, r.snapshot(r.readiness))
+}
+
+func (r *Registry) add(
+	checks map[string]entry, name string, check Check, opts []Option,
+) {
+	e := entry{check: check, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checks[name] = e
+}
+
+// snapshot copies checks so that they run without holding the lock.
+func (r *Registry) snapshot(checks map[string]entry) map[string]entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return maps.Clone(checks)
+}
+
+func evaluate(ctx context.Context, checks map[string]entry) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for name, e := range checks {
+		wg.Go(func() {
+			result := run(ctx, e)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// run executes a single check within its timeout.
+func run(ctx context.Context, e entry) Result {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := call(ctx, e.check)
+	result := Result{Status: StatusOK, Duration: time.Since(start)}
+
+	if err == nil && ctx.Err() != nil {
+		// The check ignored its deadline but still ran past it.
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		result.Status, result.Error = StatusFail, err.Error()
+	}
+
+	return result
+}
+
+func call(ctx context.Context, check Check) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	return check(ctx)
+}
@@ -0,0 +1,70 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/health"
+)
+
+var errDown = errors.New("database down")
+
+func healthy(context.Context) error {
+	return nil
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	registry := health.New()
+	registry.AddReadiness("cache", healthy)
+	registry.AddReadiness("db", func(context.Context) error {
+		return errDown
+	})
+	registry.AddReadiness("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, health.WithTimeout(10*time.Millisecond))
+	registry.AddLiveness("loop", func(context.Context) error {
+		panic("stuck")
+	})
+
+	report := registry.Readiness(t.Context())
+
+	assert.False(t, report.OK())
+	assert.Len(t, report.Checks, 3)
+	assert.Equal(t, health.StatusOK, report.Checks["cache"].Status)
+	assert.Equal(t, "database down", report.Checks["db"].Error)
+	assert.Equal(t, context.DeadlineExceeded.Error(),
+		report.Checks["slow"].Error)
+
+	live := registry.Liveness(t.Context())
+	assert.Contains(t, live.Checks["loop"].Error, "panicked: stuck")
+
+	registry.Remove("db")
+	registry.Remove("slow")
+	registry.Remove("loop")
+
+	assert.True(t, registry.Readiness(t.Context()).OK())
+	assert.True(t, registry.Liveness(t.Context()).OK())
+}
+
+func TestCheckIgnoringDeadline(t *testing.T) {
+	t.Parallel()
+
+	registry := health.New()
+	registry.AddLiveness("sleepy", func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+
+		return nil
+	}, health.WithTimeout(time.Millisecond))
+
+	result := registry.Liveness(t.Context()).Checks["sleepy"]
+	assert.Equal(t, health.StatusFail, result.Status)
+	assert.GreaterOrEqual(t, result.Duration, 20*time.Millisecond)
+}
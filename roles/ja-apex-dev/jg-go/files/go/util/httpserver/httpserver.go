@@ -0,0 +1,237 @@
+// Package httpserver builds production-ready HTTP servers: bounded
+// timeouts, a default middleware stack, optional TLS and graceful
+// shutdown driven by a lifecycle.Runner.
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/middleware"
+)
+
+// DefaultAddr is the listen address used unless WithAddr is given.
+const DefaultAddr = ":8080"
+
+// Timeouts bound the phases of a connection. Zero fields keep their
+// default.
+type Timeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+	// Shutdown bounds the graceful shutdown, after which the remaining
+	// connections are closed.
+	Shutdown time.Duration
+}
+
+// DefaultTimeouts returns the timeouts used unless WithTimeouts is given.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		ReadHeader: 5 * time.Second,
+		Read:       30 * time.Second,
+		Write:      60 * time.Second,
+		Idle:       2 * time.Minute,
+		Shutdown:   lifecycle.DefaultStopTimeout,
+	}
+}
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	addr        string
+	tls         *tls.Config
+	timeouts    Timeouts
+	logger      *slog.Logger
+	middlewares []middleware.Middleware
+	custom      bool
+}
+
+// WithAddr sets the listen address, DefaultAddr by default. Port 0 picks
+// a free port, reported by Addr once started.
+func WithAddr(addr string) Option {
+	return func(s *settings) {
+		s.addr = addr
+	}
+}
+
+// WithTLS serves HTTPS with config, which must hold the certificates.
+// MinVersion defaults to TLS 1.2. It panics on a nil config rather than
+// serving plain HTTP.
+func WithTLS(config *tls.Config) Option {
+	if config == nil {
+		panic("httpserver: nil TLS config")
+	}
+
+	return func(s *settings) {
+		s.tls = config.Clone()
+		if s.tls.MinVersion == 0 {
+			s.tls.MinVersion = tls.VersionTLS12
+		}
+	}
+}
+
+// WithTimeouts overrides the non-zero timeouts of DefaultTimeouts.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *settings) {
+		s.timeouts = mergeTimeouts(s.timeouts, timeouts)
+	}
+}
+
+// WithLogger sets the logger of the server and of the default middleware
+// stack.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *settings) {
+		s.logger = logger
+	}
+}
+
+// WithMiddleware replaces the default stack, made of request IDs, access
+// logging and panic recovery, with middlewares, the first one being the
+// outermost. No middlewares serves the handler as is.
+func WithMiddleware(middlewares ...middleware.Middleware) Option {
+	return func(s *settings) {
+		s.middlewares = middlewares
+		s.custom = true
+	}
+}
+
+// Server is an HTTP server managed by a lifecycle.Runner.
+type Server struct {
+	settings
+
+	server *http.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+// New returns a server for handler, which is not listening yet.
+func New(handler http.Handler, opts ...Option) *Server {
+	s := &Server{settings: settings{
+		addr:     DefaultAddr,
+		timeouts: DefaultTimeouts(),
+		logger:   slog.New(slog.DiscardHandler),
+	}}
+
+	for _, opt := range opts {
+		opt(&s.settings)
+	}
+
+	if !s.custom {
+		s.middlewares = []middleware.Middleware{
+			middleware.RequestID(),
+			middleware.AccessLog(s.logger),
+			middleware.Recover(s.logger),
+		}
+	}
+
+	s.server = &http.Server{
+		Addr:              s.addr,
+		Handler:           middleware.Chain(handler, s.middlewares...),
+		TLSConfig:         s.tls,
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+		ErrorLog: slog.NewLogLogger(
+			s.logger.Handler(), slog.LevelWarn),
+	}
+
+	return s
+}
+
+// Register appends the server to runner, which starts listening on Run
+// and shuts the server down gracefully on stop. A server failing after
+// start shuts the runner down with the error.
+func (s *Server) Register(runner *lifecycle.Runner) {
+	runner.Append(lifecycle.Hook{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			return s.start(ctx, runner.Shutdown)
+		},
+		Stop:        s.server.Shutdown,
+		StopTimeout: s.timeouts.Shutdown,
+	})
+}
+
+// Run serves until ctx is done or the process receives SIGINT or SIGTERM,
+// then shuts down gracefully. It is the one-call alternative to Register
+// for programs running nothing else.
+func (s *Server) Run(ctx context.Context) error {
+	runner := lifecycle.New(lifecycle.WithLogger(s.logger))
+	s.Register(runner)
+
+	return runner.Run(ctx)
+}
+
+// Addr returns the address the server listens on, or nil before start.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+// start listens and serves in the background, reporting serve failures to
+// fail.
+func (s *Server) start(ctx context.Context, fail func(error)) error {
+	var listenConfig net.ListenConfig
+
+	listener, err := listenConfig.Listen(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("httpserver: %w", err)
+	}
+
+	if s.tls != nil {
+		listener = tls.NewListener(listener, s.tls)
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	go func() {
+		err := s.server.Serve(listener)
+		if !errors.Is(err, http.ErrServerClosed) {
+			fail(fmt.Errorf("httpserver: serve: %w", err))
+		}
+	}()
+
+	s.logger.InfoContext(ctx, "listening", "addr", listener.Addr().String(),
+		"tls", s.tls != nil)
+
+	return nil
+}
+
+// mergeTimeouts overrides the fields of base set in override.
+func mergeTimeouts(base, override Timeouts) Timeouts {
+	pick := func(current, value time.Duration) time.Duration {
+		if value > 0 {
+			return value
+		}
+
+		return current
+	}
+
+	return Timeouts{
+		ReadHeader: pick(base.ReadHeader, override.ReadHeader),
+		Read:       pick(base.Read, override.Read),
+		Write:      pick(base.Write, override.Write),
+		Idle:       pick(base.Idle, override.Idle),
+		Shutdown:   pick(base.Shutdown, override.Shutdown),
+	}
+}
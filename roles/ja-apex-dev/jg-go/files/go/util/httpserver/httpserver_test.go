@@ -0,0 +1,133 @@
+package httpserver_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/httpserver"
+	"example.com/go-template/util/httpx"
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/testx"
+)
+
+var hello = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/panic" {
+		panic("boom")
+	}
+
+	_, _ = io.WriteString(w, "hello")
+})
+
+// start runs server in a runner until the test ends, returning its
+// address.
+func start(t *testing.T, server *httpserver.Server) net.Addr {
+	t.Helper()
+
+	runner := lifecycle.New(lifecycle.WithSignals())
+	server.Register(runner)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runner.Run(context.Background())
+	}()
+
+	t.Cleanup(func() {
+		runner.Shutdown(nil)
+		assert.NoError(t, <-done)
+	})
+
+	testx.RequireEventually(t, func() bool {
+		return server.Addr() != nil
+	}, time.Second)
+
+	return server.Addr()
+}
+
+func fetch(t *testing.T, client *http.Client, url string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(
+		t.Context(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+
+	return resp
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	server := httpserver.New(hello,
+		httpserver.WithAddr("127.0.0.1:0"),
+		httpserver.WithLogger(sink.Logger()),
+		httpserver.WithTimeouts(httpserver.Timeouts{Shutdown: time.Second}))
+
+	assert.Nil(t, server.Addr())
+
+	base := "http://" + start(t, server).String()
+
+	resp := fetch(t, http.DefaultClient, base+"/")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(httpx.HeaderRequestID))
+
+	resp = fetch(t, http.DefaultClient, base+"/panic")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, sink.Messages(), "http handler panicked")
+}
+
+func TestServerTLS(t *testing.T) {
+	t.Parallel()
+
+	// Borrow the self-signed certificate of httptest and its client.
+	reference := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(reference.Close)
+
+	server := httpserver.New(hello,
+		httpserver.WithAddr("127.0.0.1:0"),
+		httpserver.WithMiddleware(),
+		httpserver.WithTLS(&tls.Config{
+			Certificates: reference.TLS.Certificates,
+			MinVersion:   tls.VersionTLS13,
+		}))
+
+	resp := fetch(t, reference.Client(),
+		"https://"+start(t, server).String()+"/")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(httpx.HeaderRequestID),
+		"no middleware")
+	assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+
+	assert.PanicsWithValue(t, "httpserver: nil TLS config", func() {
+		httpserver.WithTLS(nil)
+	})
+}
+
+func TestServerStartFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httpserver.New(hello, httpserver.WithAddr("256.0.0.1:0"))
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	require.Error(t, server.Run(ctx))
+}
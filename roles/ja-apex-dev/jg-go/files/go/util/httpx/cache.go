@@ -0,0 +1,217 @@
+package httpx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// HeaderCache is set on the responses of a CacheTransport to how they
+// were obtained: CacheHit, CacheMiss, CacheRevalidated or CacheStale.
+const HeaderCache = "X-Httpx-Cache"
+
+// Values of HeaderCache.
+const (
+	CacheHit         = "hit"
+	CacheMiss        = "miss"
+	CacheRevalidated = "revalidated"
+	CacheStale       = "stale"
+)
+
+// CacheTransport is an http.RoundTripper caching the successful responses
+// of GET requests in a directory, as a private cache following the
+// Cache-Control, Expires, ETag and Last-Modified headers:
+//
+//   - fresh responses are served without any request;
+//   - stale responses are revalidated with a conditional request, and
+//     served again when the server answers 304 Not Modified;
+//   - within the stale-while-revalidate window of the response or the
+//     StaleWhileRevalidate default, stale responses are served at once and
+//     revalidated in the background.
+//
+// Requests with a Range, a conditional header or Cache-Control: no-store
+// bypass the cache, and no-cache forces a revalidation. Responses are
+// stored as their body is read to the end, so an aborted read stores
+// nothing.
+type CacheTransport struct {
+	// Base sends the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+	// Dir holds the cached responses. It is created on first use.
+	Dir string
+	// StaleWhileRevalidate is the window in which stale responses are
+	// served while revalidating, for responses without their own
+	// stale-while-revalidate directive.
+	StaleWhileRevalidate time.Duration
+	// Clock is the time source, the system clock when nil.
+	Clock clock.Clock
+
+	mu      sync.Mutex
+	pending map[string]bool
+	wg      sync.WaitGroup
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !cacheable(req) {
+		return t.base().RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+
+	e := t.load(key, req)
+	if e == nil {
+		return t.fetch(req, key, nil)
+	}
+
+	age := t.clock().Since(e.Stored)
+	_, noCache := directives(req.Header.Get("Cache-Control"))["no-cache"]
+	fresh := e.lifetime()
+
+	switch {
+	case noCache:
+		return t.fetch(req, key, e)
+	case age < fresh:
+		return t.serve(req, e, CacheHit)
+	case age < fresh+e.staleWindow(t.StaleWhileRevalidate):
+		t.revalidate(req, key, e)
+
+		return t.serve(req, e, CacheStale)
+	default:
+		return t.fetch(req, key, e)
+	}
+}
+
+// Wait waits for the background revalidations to finish.
+func (t *CacheTransport) Wait() {
+	t.wg.Wait()
+}
+
+// fetch sends req, conditionally when a stale entry e is known, and
+// stores the response.
+func (t *CacheTransport) fetch(
+	req *http.Request, key string, e *entry,
+) (*http.Response, error) {
+	conditional := req.Clone(req.Context())
+	if e != nil {
+		e.condition(conditional)
+	}
+
+	resp, err := t.base().RoundTrip(conditional)
+	if err != nil {
+		return nil, err
+	}
+
+	if e != nil && resp.StatusCode == http.StatusNotModified {
+		drain(resp)
+		e.refresh(resp.Header, t.clock().Now())
+		t.rewrite(e)
+
+		return t.serve(req, e, CacheRevalidated)
+	}
+
+	if !storable(resp) {
+		return resp, nil
+	}
+
+	resp.Header.Set(HeaderCache, CacheMiss)
+	t.record(req, key, resp)
+
+	return resp, nil
+}
+
+// revalidate refreshes e in the background, once per key at a time.
+func (t *CacheTransport) revalidate(req *http.Request, key string, e *entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pending[key] {
+		return
+	}
+
+	if t.pending == nil {
+		t.pending = make(map[string]bool)
+	}
+
+	t.pending[key] = true
+	t.wg.Add(1)
+
+	stale := *e
+	stale.Header = e.Header.Clone()
+
+	ctx, cancel := context.WithTimeout(
+		context.WithoutCancel(req.Context()), DefaultTimeout)
+
+	go func() {
+		defer t.wg.Done()
+		defer cancel()
+
+		if resp, err := t.fetch(req.WithContext(ctx), key, &stale); err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		t.mu.Lock()
+		delete(t.pending, key)
+		t.mu.Unlock()
+	}()
+}
+
+func (t *CacheTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+
+	return t.Base
+}
+
+func (t *CacheTransport) clock() clock.Clock {
+	return clock.OrReal(t.Clock)
+}
+
+// path returns the file of the entry key.
+func (t *CacheTransport) path(key string) string {
+	return filepath.Join(t.Dir, key+".cache")
+}
+
+// cacheable reports whether the cache may answer req.
+func cacheable(req *http.Request) bool {
+	if req.Method != "" && req.Method != http.MethodGet {
+		return false
+	}
+
+	for _, name := range []string{
+		"Range", "If-None-Match", "If-Modified-Since", "If-Match",
+		"If-Unmodified-Since", "If-Range",
+	} {
+		if req.Header.Get(name) != "" {
+			return false
+		}
+	}
+
+	_, noStore := directives(req.Header.Get("Cache-Control"))["no-store"]
+
+	return !noStore
+}
+
+// storable reports whether resp may be stored.
+func storable(resp *http.Response) bool {
+	control := directives(resp.Header.Get("Cache-Control"))
+	_, noStore := control["no-store"]
+
+	return resp.StatusCode == http.StatusOK && !noStore &&
+		resp.Header.Get("Vary") != "*"
+}
+
+// cacheKey identifies the entry of req.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,277 @@
+package httpx_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/httpx"
+)
+
+// origin serves a versioned body with the given Cache-Control and an
+// ETag, answering 304 to matching conditional requests.
+type origin struct {
+	control  string
+	vary     string
+	version  atomic.Int32
+	requests atomic.Int32
+	notMod   atomic.Int32
+}
+
+func (o *origin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.requests.Add(1)
+
+	etag := `"v` + string(rune('0'+o.version.Load())) + `"`
+	w.Header().Set("Cache-Control", o.control)
+	w.Header().Set("ETag", etag)
+
+	if o.vary != "" {
+		w.Header().Set("Vary", o.vary)
+	}
+
+	if r.Header.Get("If-None-Match") == etag {
+		o.notMod.Add(1)
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	_, _ = io.WriteString(w, "body "+etag+r.Header.Get("Accept-Language"))
+}
+
+// cached returns a caching client of a new origin and its fake clock.
+func cached(
+	t *testing.T, control string,
+) (*origin, *httptest.Server, *httpx.CacheTransport, *clock.Fake) {
+	t.Helper()
+
+	o := &origin{control: control}
+	server := httptest.NewServer(o)
+	t.Cleanup(server.Close)
+
+	fake := clock.NewFake(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	transport := &httpx.CacheTransport{
+		Base: server.Client().Transport, Dir: t.TempDir(), Clock: fake,
+	}
+
+	return o, server, transport, fake
+}
+
+// get fetches url and returns the body and the cache status.
+func get(
+	t *testing.T, rt http.RoundTripper, url string, header ...string,
+) (body, how string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url,
+		nil)
+	require.NoError(t, err)
+
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return string(data), resp.Header.Get(httpx.HeaderCache)
+}
+
+func TestCacheFreshAndRevalidated(t *testing.T) {
+	t.Parallel()
+
+	o, server, transport, fake := cached(t, "max-age=60")
+
+	body, how := get(t, transport, server.URL)
+	assert.Equal(t, `body "v0"`, body)
+	assert.Equal(t, httpx.CacheMiss, how)
+
+	fake.Advance(30 * time.Second)
+	body, how = get(t, transport, server.URL)
+	assert.Equal(t, `body "v0"`, body)
+	assert.Equal(t, httpx.CacheHit, how)
+	assert.Equal(t, int32(1), o.requests.Load())
+
+	fake.Advance(time.Minute)
+	body, how = get(t, transport, server.URL)
+	assert.Equal(t, `body "v0"`, body)
+	assert.Equal(t, httpx.CacheRevalidated, how)
+	assert.Equal(t, int32(1), o.notMod.Load())
+
+	fake.Advance(30 * time.Second)
+	_, how = get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheHit, how, "a 304 refreshes the entry")
+
+	o.version.Store(1)
+	body, how = get(t, transport, server.URL, "Cache-Control", "no-cache")
+	assert.Equal(t, `body "v1"`, body)
+	assert.Equal(t, httpx.CacheMiss, how)
+
+	body, how = get(t, transport, server.URL)
+	assert.Equal(t, `body "v1"`, body)
+	assert.Equal(t, httpx.CacheHit, how)
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	o, server, transport, fake := cached(t,
+		"max-age=10, stale-while-revalidate=30")
+
+	get(t, transport, server.URL)
+	o.version.Store(1)
+	fake.Advance(20 * time.Second)
+
+	body, how := get(t, transport, server.URL)
+	assert.Equal(t, `body "v0"`, body)
+	assert.Equal(t, httpx.CacheStale, how)
+
+	transport.Wait()
+	assert.Equal(t, int32(2), o.requests.Load())
+
+	body, how = get(t, transport, server.URL)
+	assert.Equal(t, `body "v1"`, body)
+	assert.Equal(t, httpx.CacheHit, how)
+
+	fake.Advance(time.Minute)
+	_, how = get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheRevalidated, how, "past the stale window")
+}
+
+func TestCacheDefaultStaleWindow(t *testing.T) {
+	t.Parallel()
+
+	_, server, transport, fake := cached(t, "max-age=10")
+	transport.StaleWhileRevalidate = time.Minute
+
+	get(t, transport, server.URL)
+	fake.Advance(30 * time.Second)
+
+	_, how := get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheStale, how)
+	transport.Wait()
+
+	_, server, transport, fake = cached(t, "max-age=10, must-revalidate")
+	transport.StaleWhileRevalidate = time.Minute
+
+	get(t, transport, server.URL)
+	fake.Advance(30 * time.Second)
+
+	_, how = get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheRevalidated, how)
+}
+
+func TestCacheBypass(t *testing.T) {
+	t.Parallel()
+
+	o, server, transport, _ := cached(t, "no-store")
+
+	get(t, transport, server.URL)
+	_, how := get(t, transport, server.URL)
+	assert.Empty(t, how)
+	assert.Equal(t, int32(2), o.requests.Load())
+
+	o.control = "max-age=60"
+	get(t, transport, server.URL, "Range", "bytes=0-1")
+	get(t, transport, server.URL, "Cache-Control", "no-store")
+
+	entries, err := os.ReadDir(transport.Dir)
+	if !os.IsNotExist(err) {
+		require.NoError(t, err)
+	}
+
+	assert.Empty(t, entries)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet,
+		server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	_, how = get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheMiss, how, "unread bodies are not stored")
+}
+
+func TestCacheVary(t *testing.T) {
+	t.Parallel()
+
+	o, server, transport, _ := cached(t, "max-age=60")
+	o.vary = "Accept-Language"
+
+	body, _ := get(t, transport, server.URL, "Accept-Language", "uk")
+	assert.Equal(t, `body "v0"uk`, body)
+
+	_, how := get(t, transport, server.URL, "Accept-Language", "uk")
+	assert.Equal(t, httpx.CacheHit, how)
+
+	body, how = get(t, transport, server.URL, "Accept-Language", "en")
+	assert.Equal(t, `body "v0"en`, body)
+	assert.Equal(t, httpx.CacheMiss, how)
+}
+
+func TestCacheHeuristicFreshness(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Date", "Wed, 01 May 2024 00:00:00 GMT")
+			w.Header().Set("Last-Modified", "Sat, 27 Apr 2024 00:00:00 GMT")
+			_, _ = io.WriteString(w, "index")
+		}))
+	t.Cleanup(server.Close)
+
+	fake := clock.NewFake(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	transport := &httpx.CacheTransport{Dir: t.TempDir(), Clock: fake}
+
+	get(t, transport, server.URL)
+	fake.Advance(9 * time.Hour)
+
+	_, how := get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheHit, how, "fresh for a tenth of its age")
+
+	fake.Advance(time.Hour)
+	_, how = get(t, transport, server.URL)
+	assert.Equal(t, httpx.CacheMiss, how)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestClientWithCache(t *testing.T) {
+	t.Parallel()
+
+	o := &origin{control: "max-age=60"}
+	server := httptest.NewServer(o)
+	t.Cleanup(server.Close)
+
+	client := httpx.NewClient(httpx.WithCache(t.TempDir()),
+		httpx.WithStaleWhileRevalidate(time.Minute))
+
+	for range 3 {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	assert.Equal(t, int32(1), o.requests.Load())
+}
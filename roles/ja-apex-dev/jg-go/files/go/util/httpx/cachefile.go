@@ -0,0 +1,334 @@
+package httpx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache file settings.
+const (
+	cacheDirMode = 0o750
+	// maxHeuristic bounds the freshness guessed from Last-Modified.
+	maxHeuristic = 24 * time.Hour
+)
+
+// entry is a cached response. Its file holds the entry as a JSON line
+// followed by the body.
+type entry struct {
+	URL    string            `json:"url"`
+	Status int               `json:"status"`
+	Header http.Header       `json:"header"`
+	Vary   map[string]string `json:"vary,omitempty"`
+	Stored time.Time         `json:"stored"`
+
+	key    string
+	path   string
+	offset int64
+	size   int64
+}
+
+// load returns the entry key if it exists and matches the Vary headers
+// of req. Unreadable entries are misses.
+func (t *CacheTransport) load(key string, req *http.Request) *entry {
+	f, err := os.Open(t.path(key))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return nil
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+
+	e := &entry{key: key, path: f.Name(), offset: int64(len(line))}
+	if json.Unmarshal(line, e) != nil {
+		return nil
+	}
+
+	e.size = info.Size() - e.offset
+
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return nil
+		}
+	}
+
+	return e
+}
+
+// serve answers req with the body of e.
+func (t *CacheTransport) serve(
+	req *http.Request, e *entry, how string,
+) (*http.Response, error) {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return t.fetch(req, cacheKey(req), nil)
+	}
+
+	if _, err := f.Seek(e.offset, io.SeekStart); err != nil {
+		f.Close()
+
+		return t.fetch(req, cacheKey(req), nil)
+	}
+
+	header := e.Header.Clone()
+	header.Set(HeaderCache, how)
+
+	return &http.Response{
+		Status:        strconv.Itoa(e.Status) + " " + http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          f,
+		ContentLength: e.size,
+		Request:       req,
+	}, nil
+}
+
+// record stores resp under key as its body is read. Failures to store
+// leave resp untouched.
+func (t *CacheTransport) record(
+	req *http.Request, key string, resp *http.Response,
+) {
+	e := &entry{
+		key: key, URL: req.URL.Redacted(), Status: resp.StatusCode,
+		Header: resp.Header.Clone(), Stored: t.clock().Now(),
+	}
+	e.Header.Del(HeaderCache)
+
+	for _, name := range resp.Header.Values("Vary") {
+		for _, field := range strings.Split(name, ",") {
+			field = http.CanonicalHeaderKey(strings.TrimSpace(field))
+			if e.Vary == nil {
+				e.Vary = make(map[string]string)
+			}
+
+			e.Vary[field] = req.Header.Get(field)
+		}
+	}
+
+	f, err := t.create(key, e)
+	if err != nil {
+		return
+	}
+
+	resp.Body = &recorder{body: resp.Body, file: f, path: t.path(key)}
+}
+
+// rewrite replaces the stored header of e, keeping its body.
+func (t *CacheTransport) rewrite(e *entry) {
+	old, err := os.Open(e.path)
+	if err != nil {
+		return
+	}
+	defer old.Close()
+
+	f, err := t.create(e.key, e)
+	if err != nil {
+		return
+	}
+
+	body := io.NewSectionReader(old, e.offset, e.size)
+	if _, err := io.Copy(f, body); err != nil {
+		discard(f)
+
+		return
+	}
+
+	commit(f, e.path)
+}
+
+// create opens a temporary file for the entry key and writes e to it.
+func (t *CacheTransport) create(key string, e *entry) (*os.File, error) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: cache: %w", err)
+	}
+
+	if err := os.MkdirAll(t.Dir, cacheDirMode); err != nil {
+		return nil, fmt.Errorf("httpx: cache: %w", err)
+	}
+
+	f, err := os.CreateTemp(t.Dir, key+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("httpx: cache: %w", err)
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		discard(f)
+
+		return nil, fmt.Errorf("httpx: cache: %w", err)
+	}
+
+	return f, nil
+}
+
+// commit closes the temporary file f and moves it to path.
+func commit(f *os.File, path string) {
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+
+		return
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// discard closes and removes the temporary file f.
+func discard(f *os.File) {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// recorder copies a response body to a cache file, committed once the
+// body has been read to the end.
+type recorder struct {
+	body   io.ReadCloser
+	file   *os.File
+	path   string
+	failed bool
+	done   bool
+}
+
+// Read implements io.Reader.
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 && !r.failed && !r.done {
+		if _, werr := r.file.Write(p[:n]); werr != nil {
+			r.failed = true
+		}
+	}
+
+	if err == io.EOF && !r.done {
+		r.done = true
+		r.finish()
+	}
+
+	return n, err
+}
+
+// Close implements io.Closer, discarding a partially read body.
+func (r *recorder) Close() error {
+	if !r.done {
+		r.done = true
+		discard(r.file)
+	}
+
+	return r.body.Close()
+}
+
+// finish stores the body read in full, unless writing it failed.
+func (r *recorder) finish() {
+	if r.failed {
+		discard(r.file)
+	} else {
+		commit(r.file, r.path)
+	}
+}
+
+// condition makes req conditional on the validators of e.
+func (e *entry) condition(req *http.Request) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	if modified := e.Header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+}
+
+// refresh applies the headers of a 304 response to e.
+func (e *entry) refresh(header http.Header, now time.Time) {
+	for name, values := range header {
+		if name != "Content-Length" {
+			e.Header[name] = values
+		}
+	}
+
+	e.Stored = now
+}
+
+// lifetime returns how long e stays fresh after it was stored: max-age,
+// Expires or a tenth of the time since Last-Modified.
+func (e *entry) lifetime() time.Duration {
+	control := directives(e.Header.Get("Cache-Control"))
+	if _, ok := control["no-cache"]; ok {
+		return 0
+	}
+
+	if seconds, ok := control["max-age"]; ok {
+		return parseSeconds(seconds)
+	}
+
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		date = e.Stored
+	}
+
+	if expires, err := http.ParseTime(e.Header.Get("Expires")); err == nil {
+		return max(expires.Sub(date), 0)
+	}
+
+	modified, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	if err != nil {
+		return 0
+	}
+
+	return min(max(date.Sub(modified)/10, 0), maxHeuristic)
+}
+
+// staleWindow returns how long after its lifetime e may be served while
+// revalidating.
+func (e *entry) staleWindow(fallback time.Duration) time.Duration {
+	control := directives(e.Header.Get("Cache-Control"))
+	if _, ok := control["must-revalidate"]; ok {
+		return 0
+	}
+
+	if seconds, ok := control["stale-while-revalidate"]; ok {
+		return parseSeconds(seconds)
+	}
+
+	return fallback
+}
+
+// directives parses a Cache-Control header into lowercase directive names
+// and their values.
+func directives(value string) map[string]string {
+	parsed := make(map[string]string)
+
+	for _, field := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(field), "=")
+		if name != "" {
+			parsed[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+
+	return parsed
+}
+
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
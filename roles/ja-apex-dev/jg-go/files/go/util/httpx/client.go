@@ -0,0 +1,221 @@
+// Package httpx builds HTTP clients with sane timeouts, automatic retries
+// of throttled and failed requests, logging hooks, correlation ID
+// propagation and an optional disk-backed response cache.
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/breaker"
+)
+
+// Default client settings.
+const (
+	DefaultTimeout        = 30 * time.Second
+	DefaultDialTimeout    = 5 * time.Second
+	DefaultTLSTimeout     = 5 * time.Second
+	DefaultIdleConnection = 90 * time.Second
+)
+
+// ResponseHook receives the outcome of an attempt.
+type ResponseHook func(
+	req *http.Request, resp *http.Response, err error, elapsed time.Duration,
+)
+
+// Hooks observe every attempt made by the Transport.
+type Hooks struct {
+	// OnRequest is called before every attempt.
+	OnRequest func(req *http.Request)
+	// OnResponse is called after every attempt with its outcome.
+	OnResponse ResponseHook
+}
+
+// Option configures NewClient.
+type Option func(*settings)
+
+type settings struct {
+	timeout   time.Duration
+	base      http.RoundTripper
+	policy    util.RetryPolicy
+	noRetry   bool
+	hooks     []Hooks
+	requestID string
+	breaker   *breaker.Breaker
+	cacheDir  string
+	stale     time.Duration
+}
+
+// WithTimeout bounds the whole request, including retries.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.timeout = timeout
+	}
+}
+
+// WithTransport sets the underlying round tripper.
+func WithTransport(base http.RoundTripper) Option {
+	return func(s *settings) {
+		s.base = base
+	}
+}
+
+// WithRetry sets the retry policy, util.DefaultRetryPolicy by default.
+func WithRetry(policy util.RetryPolicy) Option {
+	return func(s *settings) {
+		s.policy = policy
+		s.noRetry = false
+	}
+}
+
+// WithoutRetry disables retries.
+func WithoutRetry() Option {
+	return func(s *settings) {
+		s.noRetry = true
+	}
+}
+
+// WithHooks adds hooks observing every attempt.
+func WithHooks(hooks Hooks) Option {
+	return func(s *settings) {
+		s.hooks = append(s.hooks, hooks)
+	}
+}
+
+// WithLogger logs every attempt at debug level and failures at warn level.
+func WithLogger(logger *slog.Logger) Option {
+	return WithHooks(Hooks{
+		OnResponse: func(
+			req *http.Request, resp *http.Response, err error,
+			elapsed time.Duration,
+		) {
+			attrs := []any{
+				"method", req.Method,
+				"url", req.URL.Redacted(),
+				"elapsed", elapsed,
+			}
+
+			switch {
+			case err != nil:
+				logger.WarnContext(req.Context(), "http request failed",
+					append(attrs, "error", err)...)
+			case resp.StatusCode >= http.StatusInternalServerError:
+				logger.WarnContext(req.Context(), "http request",
+					append(attrs, "status", resp.StatusCode)...)
+			default:
+				logger.DebugContext(req.Context(), "http request",
+					append(attrs, "status", resp.StatusCode)...)
+			}
+		},
+	})
+}
+
+// WithRequestIDHeader changes the header carrying the correlation ID.
+func WithRequestIDHeader(name string) Option {
+	return func(s *settings) {
+		s.requestID = name
+	}
+}
+
+// WithBreaker guards every attempt with b, which counts network errors,
+// 429 and 5xx responses as failures. Attempts rejected by an open circuit
+// fail with breaker.ErrOpen and are not retried.
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(s *settings) {
+		s.breaker = b
+	}
+}
+
+// WithCache caches responses in dir with a CacheTransport in front of the
+// retries.
+func WithCache(dir string) Option {
+	return func(s *settings) {
+		s.cacheDir = dir
+	}
+}
+
+// WithStaleWhileRevalidate serves cached responses up to window after they
+// expire while revalidating them in the background, unless they set their
+// own window. It requires WithCache.
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(s *settings) {
+		s.stale = window
+	}
+}
+
+// NewClient creates a client using a Transport over a tuned
+// http.Transport, unless WithTransport is given.
+func NewClient(opts ...Option) *http.Client {
+	s := &settings{
+		timeout:   DefaultTimeout,
+		policy:    util.DefaultRetryPolicy(),
+		requestID: HeaderRequestID,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.base == nil {
+		s.base = NewBaseTransport()
+	}
+
+	transport := &Transport{
+		Base:            s.base,
+		Policy:          s.policy,
+		Hooks:           s.hooks,
+		RequestIDHeader: s.requestID,
+		Breaker:         s.breaker,
+	}
+	if s.noRetry {
+		transport.Policy = util.RetryPolicy{MaxAttempts: 1}
+	}
+
+	if s.cacheDir != "" {
+		return &http.Client{Timeout: s.timeout, Transport: &CacheTransport{
+			Base: transport, Dir: s.cacheDir, StaleWhileRevalidate: s.stale,
+		}}
+	}
+
+	return &http.Client{Timeout: s.timeout, Transport: transport}
+}
+
+// NewBaseTransport returns an http.Transport with bounded dial, TLS and idle
+// timeouts, honoring the proxy environment variables.
+func NewBaseTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   DefaultDialTimeout,
+		KeepAlive: DefaultIdleConnection,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       DefaultIdleConnection,
+		TLSHandshakeTimeout:   DefaultTLSTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+type requestIDKey struct{}
+
+// HeaderRequestID is the default correlation ID header.
+const HeaderRequestID = "X-Request-Id"
+
+// WithRequestID stores the correlation ID propagated by the Transport.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the correlation ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
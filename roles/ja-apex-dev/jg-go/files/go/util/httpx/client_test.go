@@ -0,0 +1,105 @@
+package httpx_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/httpx"
+	"example.com/go-template/util/log"
+)
+
+func fastRetry() httpx.Option {
+	return httpx.WithRetry(util.RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+	})
+}
+
+func TestClientPropagatesRequestID(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.Header.Get(httpx.HeaderRequestID))
+		}))
+	t.Cleanup(server.Close)
+
+	ctx := httpx.WithRequestID(t.Context(), "req-42")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := httpx.NewClient().Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", string(body))
+	assert.Equal(t, "req-42", httpx.RequestID(ctx))
+}
+
+func TestClientLogsAttempts(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+
+				return
+			}
+		}))
+	t.Cleanup(server.Close)
+
+	sink := log.NewSink()
+	client := httpx.NewClient(fastRetry(), httpx.WithLogger(sink.Logger()))
+
+	req, err := http.NewRequestWithContext(
+		t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	entries := sink.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(http.StatusBadGateway), entries[0].Attrs["status"])
+	assert.Equal(t, int64(http.StatusOK), entries[1].Attrs["status"])
+}
+
+func TestClientWithoutRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost,
+		server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+
+	resp, err := httpx.NewClient(httpx.WithoutRetry()).Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
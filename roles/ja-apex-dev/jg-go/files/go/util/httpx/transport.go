@@ -0,0 +1,234 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/breaker"
+)
+
+// ErrRetryableStatus marks attempts answered with 429 or a 5xx status.
+var ErrRetryableStatus = errors.New("httpx: retryable status")
+
+// maxDrain bounds the body bytes read to reuse a connection.
+const maxDrain = 64 << 10
+
+// Transport is an http.RoundTripper retrying idempotent requests on network
+// errors, 429 and 5xx responses, honoring Retry-After up to the MaxDelay of
+// the policy. When retries are exhausted the last response is returned as
+// is.
+//
+// Requests are replayed only when their body can be recreated with
+// GetBody. The AttemptTimeout of the policy is ignored, since cancelling it
+// would abort reading the returned body; use the client timeout instead.
+// When Breaker is set, every attempt goes through it.
+type Transport struct {
+	Base            http.RoundTripper
+	Policy          util.RetryPolicy
+	Hooks           []Hooks
+	RequestIDHeader string
+	Breaker         *breaker.Breaker
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = t.withRequestID(req)
+
+	if !replayable(req) {
+		return t.attempt(req)
+	}
+
+	policy := t.Policy
+	policy.AttemptTimeout = 0
+
+	var last *http.Response
+
+	err := util.Retry(req.Context(), policy, func(ctx context.Context) error {
+		if last != nil {
+			drain(last)
+			last = nil
+		}
+
+		resp, err := t.replay(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		last = resp
+
+		return statusError(resp)
+	})
+
+	if last != nil && req.Context().Err() == nil &&
+		(err == nil || errors.Is(err, ErrRetryableStatus)) {
+		return last, nil
+	}
+
+	if last != nil {
+		drain(last)
+	}
+
+	return nil, err
+}
+
+func (t *Transport) withRequestID(req *http.Request) *http.Request {
+	id := RequestID(req.Context())
+	if id == "" || t.RequestIDHeader == "" ||
+		req.Header.Get(t.RequestIDHeader) != "" {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(t.RequestIDHeader, id)
+
+	return req
+}
+
+// replay sends a fresh copy of req, marking the errors that retrying
+// cannot fix as permanent.
+func (t *Transport) replay(
+	ctx context.Context, req *http.Request,
+) (*http.Response, error) {
+	attemptReq, err := rewind(ctx, req)
+	if err != nil {
+		return nil, util.Permanent(err)
+	}
+
+	resp, err := t.attempt(attemptReq)
+	if errors.Is(err, breaker.ErrOpen) {
+		return nil, util.Permanent(err)
+	}
+
+	return resp, err
+}
+
+// attempt sends req once through the breaker.
+func (t *Transport) attempt(req *http.Request) (*http.Response, error) {
+	done, err := t.Breaker.Allow()
+	if err != nil {
+		return nil, fmt.Errorf("httpx: %s %s: %w", req.Method,
+			req.URL.Redacted(), err)
+	}
+
+	resp, err := t.send(req)
+	if err == nil {
+		done(statusError(resp))
+	} else {
+		done(err)
+	}
+
+	return resp, err
+}
+
+func (t *Transport) send(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for _, hooks := range t.Hooks {
+		if hooks.OnRequest != nil {
+			hooks.OnRequest(req)
+		}
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	for _, hooks := range t.Hooks {
+		if hooks.OnResponse != nil {
+			hooks.OnResponse(req, resp, err, elapsed)
+		}
+	}
+
+	return resp, err
+}
+
+// replayable reports whether the request is idempotent and its body, if
+// any, can be recreated.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+func rewind(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+	if req.GetBody == nil {
+		return clone, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("httpx: rewind body: %w", err)
+	}
+
+	clone.Body = body
+
+	return clone, nil
+}
+
+// statusError returns a retryable error for 429 and 5xx responses other
+// than 501, annotated with the Retry-After delay when present.
+func statusError(resp *http.Response) error {
+	code := resp.StatusCode
+	if code != http.StatusTooManyRequests &&
+		(code < http.StatusInternalServerError ||
+			code == http.StatusNotImplemented) {
+		return nil
+	}
+
+	err := fmt.Errorf("%w: %s", ErrRetryableStatus, resp.Status)
+	header := resp.Header.Get("Retry-After")
+	if delay, ok := ParseRetryAfter(header, time.Now()); ok {
+		return util.RetryAfter(err, delay)
+	}
+
+	return err
+}
+
+// ParseRetryAfter parses a Retry-After value in seconds or as an HTTP date
+// relative to now. Delays too long for a time.Duration are clamped.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	// ParseInt saturates out of range values, which are clamped as well.
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		const longest = int64(math.MaxInt64 / time.Second)
+
+		return time.Duration(min(max(seconds, 0), longest)) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+	_ = resp.Body.Close()
+}
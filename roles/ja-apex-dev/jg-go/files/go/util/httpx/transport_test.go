@@ -0,0 +1,193 @@
+package httpx_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/breaker"
+	"example.com/go-template/util/httpx"
+)
+
+// flaky answers with the given statuses in order, then with 200 and the
+// request body.
+func flaky(t *testing.T, calls *atomic.Int32, statuses ...int) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := int(calls.Add(1))
+			if n <= len(statuses) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(statuses[n-1])
+
+				return
+			}
+
+			_, _ = io.Copy(w, r.Body)
+		}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func request(t *testing.T, method, url, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(
+		t.Context(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	return req
+}
+
+func do(
+	t *testing.T, client *http.Client, req *http.Request,
+) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(data)
+}
+
+func TestTransportRetriesStatuses(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	url := flaky(t, &calls,
+		http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+	resp, body := do(t, httpx.NewClient(fastRetry()),
+		request(t, http.MethodPut, url, "payload"))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", body)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransportReturnsLastResponse(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	url := flaky(t, &calls, 500, 500, 500, 500)
+
+	resp, _ := do(t, httpx.NewClient(fastRetry()),
+		request(t, http.MethodGet, url, ""))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransportSkipsNonIdempotent(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	url := flaky(t, &calls, http.StatusBadGateway)
+
+	resp, _ := do(t, httpx.NewClient(fastRetry()),
+		request(t, http.MethodPost, url, "x"))
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTransportDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	url := flaky(t, &calls, http.StatusNotFound)
+
+	resp, _ := do(t, httpx.NewClient(fastRetry()),
+		request(t, http.MethodGet, url, ""))
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTransportBoundsRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+	t.Cleanup(server.Close)
+
+	client := httpx.NewClient(httpx.WithRetry(util.RetryPolicy{
+		MaxAttempts: 2,
+		MaxDelay:    time.Millisecond,
+	}))
+
+	start := time.Now()
+	resp, _ := do(t, client, request(t, http.MethodGet, server.URL, ""))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := httpx.ParseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = httpx.ParseRetryAfter("Mon, 01 Jan 2024 12:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	_, ok = httpx.ParseRetryAfter("soon", now)
+	assert.False(t, ok)
+
+	for _, huge := range []string{"9223372037", strings.Repeat("9", 30)} {
+		delay, ok = httpx.ParseRetryAfter(huge, now)
+		assert.True(t, ok)
+		assert.Greater(t, delay, 290*365*24*time.Hour, huge)
+	}
+
+	delay, ok = httpx.ParseRetryAfter("-5", now)
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+}
+
+func TestTransportBreaker(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	url := flaky(t, &calls, 500, 500, 500, 500)
+	circuit := breaker.New(breaker.WithThreshold(2))
+	client := httpx.NewClient(fastRetry(), httpx.WithBreaker(circuit))
+
+	_, err := client.Do(request(t, http.MethodGet, url, ""))
+
+	require.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Equal(t, int32(2), calls.Load(), "open circuits stop retries")
+	assert.Equal(t, breaker.Open, circuit.State())
+}
@@ -0,0 +1,130 @@
+package i18nfmt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"example.com/go-template/util"
+)
+
+// DefaultCurrencyDecimals is the number of decimals of amounts of money,
+// but for the currencies listed in currencyDecimals.
+const DefaultCurrencyDecimals = 2
+
+// currencySymbols maps ISO 4217 codes to their symbol, shared by all
+// locales; other currencies are printed with their code.
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "BRL": "R$",
+	"INR": "₹",
+}
+
+// currencyDecimals lists the currencies without cents.
+var currencyDecimals = map[string]int{
+	"JPY": 0, "KRW": 0, "ISK": 0,
+}
+
+// Int formats n with grouped thousands, such as "1,234,567".
+func (l Locale) Int(n int64) string {
+	sign, number := l.number(strconv.FormatInt(n, 10))
+
+	return sign + number
+}
+
+// Float formats f with grouped thousands and the given number of
+// decimals. NaN and infinities are formatted as by strconv.
+func (l Locale) Float(f float64, decimals int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	sign, number := l.number(strconv.FormatFloat(f, 'f', decimals, 64))
+
+	return sign + number
+}
+
+// Percent formats ratio as a percentage with the given number of
+// decimals, 0.125 being "12.5%".
+func (l Locale) Percent(ratio float64, decimals int) string {
+	number := l.Float(ratio*100, decimals)
+
+	return strings.Replace(l.PercentPattern, "#", number, 1)
+}
+
+// Currency formats amount in the currency of the ISO 4217 code, such as
+// "$1,234.50" for USD.
+func (l Locale) Currency(amount float64, code string) string {
+	code = strings.ToUpper(code)
+
+	decimals, ok := currencyDecimals[code]
+	if !ok {
+		decimals = DefaultCurrencyDecimals
+	}
+
+	symbol, ok := currencySymbols[code]
+	if !ok {
+		symbol = code
+	}
+
+	formatted := strconv.FormatFloat(amount, 'f', decimals, 64)
+	sign, number := l.number(formatted)
+
+	return sign + strings.NewReplacer("#", number, "¤", symbol).
+		Replace(l.CurrencyPattern)
+}
+
+// List joins items with the list separator and the conjunction, such as
+// "A, B and C".
+func (l Locale) List(items ...string) string {
+	return l.Joiner().Join(items...)
+}
+
+// Joiner returns a util.Joiner producing the lists of List, with opts
+// applied on top.
+func (l Locale) Joiner(opts ...util.JoinOption) *util.Joiner {
+	return util.NewJoiner(l.ListSeparator,
+		append([]util.JoinOption{util.WithConjunction(l.And)}, opts...)...)
+}
+
+// number localizes the separators of a number formatted by strconv,
+// returning its sign apart for the patterns to follow it. The sign of
+// numbers rounded to zero is dropped.
+func (l Locale) number(s string) (sign, number string) {
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		s = rest
+		if strings.Trim(s, "0.") != "" {
+			sign = "-"
+		}
+	}
+
+	integer, fraction, ok := strings.Cut(s, ".")
+	integer = l.group(integer)
+
+	if !ok {
+		return sign, integer
+	}
+
+	return sign, integer + l.Decimal + fraction
+}
+
+// group inserts the group separator every three digits from the right.
+func (l Locale) group(digits string) string {
+	if len(digits) < 3+max(l.MinGrouping, 1) {
+		return digits
+	}
+
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+
+	var b strings.Builder
+	b.WriteString(digits[:head])
+
+	for i := head; i < len(digits); i += 3 {
+		b.WriteString(l.Group)
+		b.WriteString(digits[i : i+3])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,136 @@
+// Package i18nfmt formats numbers, percentages, amounts of money and lists
+// for human readers, following the conventions of their locale:
+//
+//	loc := i18nfmt.FromEnv() // LANG=fr_FR.UTF-8
+//	loc.Int(1234567)            // "1 234 567"
+//	loc.Percent(0.125, 1)       // "12,5 %"
+//	loc.Currency(1234.5, "EUR") // "1 234,50 €"
+//	loc.List("A", "B", "C")     // "A, B et C"
+//
+// The French and German output holds no-break spaces. Locales are per
+// language, the region of codes such as fr_CA being ignored; languages
+// beyond the built-in ones can be declared as Locale values.
+package i18nfmt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrUnknownLocale is returned by Parse for languages without a Locale.
+var ErrUnknownLocale = errors.New("i18nfmt: unknown locale")
+
+// Environment variables selecting the locale, by precedence.
+const (
+	EnvLCAll = "LC_ALL"
+	EnvLang  = "LANG"
+)
+
+// Locale holds the formatting conventions of a language. In the patterns,
+// # stands for the number and ¤ for the currency symbol.
+type Locale struct {
+	// Tag is the language code, such as "fr".
+	Tag string
+	// Decimal separates the integer part from the fraction.
+	Decimal string
+	// Group separates the groups of three digits of the integer part.
+	Group string
+	// MinGrouping is the number of digits required in the leading group
+	// for grouping to apply, 1 when zero: with 2, 1234 is not grouped
+	// but 12345 is.
+	MinGrouping int
+	// PercentPattern is the pattern of percentages, such as "#%".
+	PercentPattern string
+	// CurrencyPattern is the pattern of amounts of money, such as "¤#".
+	CurrencyPattern string
+	// ListSeparator separates the items of lists but the last two.
+	ListSeparator string
+	// And is the conjunction before the last item of lists.
+	And string
+}
+
+// Locales of the supported languages.
+var (
+	English = Locale{
+		Tag: "en", Decimal: ".", Group: ",",
+		PercentPattern: "#%", CurrencyPattern: "¤#",
+		ListSeparator: ", ", And: "and",
+	}
+	French = Locale{
+		Tag: "fr", Decimal: ",", Group: "\u202f",
+		PercentPattern: "#\u202f%", CurrencyPattern: "#\u00a0¤",
+		ListSeparator: ", ", And: "et",
+	}
+	German = Locale{
+		Tag: "de", Decimal: ",", Group: ".",
+		PercentPattern: "#\u00a0%", CurrencyPattern: "#\u00a0¤",
+		ListSeparator: ", ", And: "und",
+	}
+	Spanish = Locale{
+		Tag: "es", Decimal: ",", Group: ".", MinGrouping: 2,
+		PercentPattern: "#\u00a0%", CurrencyPattern: "#\u00a0¤",
+		ListSeparator: ", ", And: "y",
+	}
+	Italian = Locale{
+		Tag: "it", Decimal: ",", Group: ".",
+		PercentPattern: "#%", CurrencyPattern: "#\u00a0¤",
+		ListSeparator: ", ", And: "e",
+	}
+	Dutch = Locale{
+		Tag: "nl", Decimal: ",", Group: ".",
+		PercentPattern: "#%", CurrencyPattern: "¤\u00a0#",
+		ListSeparator: ", ", And: "en",
+	}
+	Portuguese = Locale{
+		Tag: "pt", Decimal: ",", Group: ".",
+		PercentPattern: "#%", CurrencyPattern: "¤\u00a0#",
+		ListSeparator: ", ", And: "e",
+	}
+)
+
+var locales = map[string]Locale{
+	"en": English, "fr": French, "de": German, "es": Spanish,
+	"it": Italian, "nl": Dutch, "pt": Portuguese,
+	// The portable locales, as set in containers and CI.
+	"c": English, "posix": English,
+}
+
+// Parse returns the locale of a code in the BCP 47 (fr-FR) or POSIX
+// (fr_FR.UTF-8@euro) form. An empty code selects English.
+func Parse(code string) (Locale, error) {
+	lang := code
+	if i := strings.IndexAny(lang, "-_.@"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return English, nil
+	}
+
+	locale, ok := locales[lang]
+	if !ok {
+		return Locale{}, fmt.Errorf("%w: %q", ErrUnknownLocale, code)
+	}
+
+	return locale, nil
+}
+
+// FromEnv returns the locale of the first of LC_ALL and LANG naming a
+// known language, or English.
+func FromEnv() Locale {
+	for _, key := range []string{EnvLCAll, EnvLang} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+
+		if locale, err := Parse(value); err == nil {
+			return locale
+		}
+	}
+
+	return English
+}
@@ -0,0 +1,109 @@
+package i18nfmt_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/i18nfmt"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for code, want := range map[string]string{
+		"": "en", "C": "en", "C.UTF-8": "en", "POSIX": "en",
+		"en_US.UTF-8": "en", "fr": "fr", "fr-CA": "fr",
+		"de_DE.UTF-8@euro": "de", "ES_es": "es",
+	} {
+		locale, err := i18nfmt.Parse(code)
+		require.NoError(t, err, code)
+		assert.Equal(t, want, locale.Tag, code)
+	}
+
+	_, err := i18nfmt.Parse("tlh_QO")
+	require.ErrorIs(t, err, i18nfmt.ErrUnknownLocale)
+	assert.ErrorContains(t, err, `"tlh_QO"`)
+}
+
+//nolint:paralleltest // The test sets environment variables.
+func TestFromEnv(t *testing.T) {
+	t.Setenv(i18nfmt.EnvLCAll, "")
+	t.Setenv(i18nfmt.EnvLang, "fr_FR.UTF-8")
+	assert.Equal(t, "fr", i18nfmt.FromEnv().Tag)
+
+	t.Setenv(i18nfmt.EnvLCAll, "de_AT.UTF-8")
+	assert.Equal(t, "de", i18nfmt.FromEnv().Tag)
+
+	t.Setenv(i18nfmt.EnvLCAll, "tlh")
+	assert.Equal(t, "fr", i18nfmt.FromEnv().Tag, "unknown languages skipped")
+
+	t.Setenv(i18nfmt.EnvLang, "")
+	assert.Equal(t, i18nfmt.English, i18nfmt.FromEnv())
+}
+
+func TestInt(t *testing.T) {
+	t.Parallel()
+
+	en, fr := i18nfmt.English, i18nfmt.French
+	assert.Equal(t, "0", en.Int(0))
+	assert.Equal(t, "999", en.Int(999))
+	assert.Equal(t, "1,000", en.Int(1000))
+	assert.Equal(t, "-1,234,567", en.Int(-1234567))
+	assert.Equal(t, "-9,223,372,036,854,775,808", en.Int(math.MinInt64))
+	assert.Equal(t, "123\u202f456", fr.Int(123456))
+	assert.Equal(t, "1.234", i18nfmt.German.Int(1234))
+	assert.Equal(t, "1234", i18nfmt.Spanish.Int(1234))
+	assert.Equal(t, "12.345", i18nfmt.Spanish.Int(12345))
+}
+
+func TestFloat(t *testing.T) {
+	t.Parallel()
+
+	en, de := i18nfmt.English, i18nfmt.German
+	assert.Equal(t, "1,234.57", en.Float(1234.567, 2))
+	assert.Equal(t, "1.234,6", de.Float(1234.567, 1))
+	assert.Equal(t, "1.235", de.Float(1234.567, 0))
+	assert.Equal(t, "0.00", en.Float(-0.001, 2), "no negative zero")
+	assert.Equal(t, "-0.01", en.Float(-0.006, 2))
+	assert.Equal(t, "NaN", en.Float(math.NaN(), 2))
+	assert.Equal(t, "-Inf", en.Float(math.Inf(-1), 2))
+}
+
+func TestPercent(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "12.5%", i18nfmt.English.Percent(0.125, 1))
+	assert.Equal(t, "-3%", i18nfmt.English.Percent(-0.03, 0))
+	assert.Equal(t, "12,5\u202f%", i18nfmt.French.Percent(0.125, 1))
+	assert.Equal(t, "100\u00a0%", i18nfmt.German.Percent(1, 0))
+}
+
+func TestCurrency(t *testing.T) {
+	t.Parallel()
+
+	en, fr := i18nfmt.English, i18nfmt.French
+	assert.Equal(t, "$1,234.50", en.Currency(1234.5, "USD"))
+	assert.Equal(t, "-€3.00", en.Currency(-3, "eur"))
+	assert.Equal(t, "¥1,235", en.Currency(1234.6, "JPY"))
+	assert.Equal(t, "CHF10.00", en.Currency(10, "CHF"))
+	assert.Equal(t, "1\u202f234,50\u00a0€", fr.Currency(1234.5, "EUR"))
+	assert.Equal(t, "-3,00\u00a0€", fr.Currency(-3, "EUR"))
+	assert.Equal(t, "€\u00a01.234,50", i18nfmt.Dutch.Currency(1234.5, "EUR"))
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "A, B and C", i18nfmt.English.List("A", "B", "C"))
+	assert.Equal(t, "A, B et C", i18nfmt.French.List("A", "B", "C"))
+	assert.Equal(t, "A und B", i18nfmt.German.List("A", "B"))
+	assert.Equal(t, "A", i18nfmt.Spanish.List("A"))
+	assert.Empty(t, i18nfmt.Italian.List())
+
+	joiner := i18nfmt.French.Joiner(util.WithSkipEmpty())
+	assert.Equal(t, "A et C", joiner.Join("A", "", "C"))
+}
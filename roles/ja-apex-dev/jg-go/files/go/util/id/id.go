@@ -0,0 +1,101 @@
+// Package id generates and parses random UUIDv4, time-ordered UUIDv7 and
+// ULID identifiers. Generators take an injectable entropy source and clock
+// so tests can produce deterministic values.
+package id
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithEntropy sets the source of random bits, crypto/rand by default.
+// A seeded reader such as math/rand/v2.ChaCha8 yields reproducible IDs.
+func WithEntropy(entropy io.Reader) Option {
+	return func(g *Generator) {
+		g.entropy = entropy
+	}
+}
+
+// WithNow sets the clock used by time-ordered identifiers.
+func WithNow(now func() time.Time) Option {
+	return func(g *Generator) {
+		g.now = now
+	}
+}
+
+// Generator creates identifiers. It is safe for concurrent use when its
+// entropy source is.
+type Generator struct {
+	entropy io.Reader
+	now     func() time.Time
+}
+
+// NewGenerator returns a generator using crypto/rand and the system clock
+// unless overridden.
+func NewGenerator(opts ...Option) *Generator {
+	g := &Generator{entropy: rand.Reader, now: time.Now}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+var defaultGenerator = NewGenerator()
+
+// NewUUIDv4 returns a random UUID. Reading crypto/rand never fails, so
+// there is no error to handle.
+func NewUUIDv4() UUID {
+	return must(defaultGenerator.UUIDv4())
+}
+
+// NewUUIDv7 returns a UUID sorting by creation time.
+func NewUUIDv7() UUID {
+	return must(defaultGenerator.UUIDv7())
+}
+
+// NewULID returns a ULID sorting by creation time.
+func NewULID() ULID {
+	return must(defaultGenerator.ULID())
+}
+
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// random fills dst from the entropy source.
+func (g *Generator) random(dst []byte) error {
+	if _, err := io.ReadFull(g.entropy, dst); err != nil {
+		return fmt.Errorf("id: read entropy: %w", err)
+	}
+
+	return nil
+}
+
+// putMillis stores the 48-bit Unix millisecond timestamp used by UUIDv7
+// and ULID.
+func putMillis(dst []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := range 6 {
+		dst[i] = byte(ms >> (40 - 8*i))
+	}
+}
+
+// millis reads a timestamp stored by putMillis.
+func millis(src []byte) time.Time {
+	var ms int64
+	for i := range 6 {
+		ms = ms<<8 | int64(src[i])
+	}
+
+	return time.UnixMilli(ms)
+}
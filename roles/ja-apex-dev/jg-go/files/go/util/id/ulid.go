@@ -0,0 +1,136 @@
+package id
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidULID is returned when parsing malformed ULIDs.
+var ErrInvalidULID = errors.New("id: invalid ULID")
+
+// ULID is a lexicographically sortable identifier made of a 48-bit
+// millisecond timestamp and 80 random bits, printed as 26 characters of
+// Crockford base32.
+type ULID [16]byte
+
+const (
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	ulidLen   = 26
+	// ulidPad is the number of leading zero bits making 128 bits fit in
+	// 26 five-bit characters.
+	ulidPad = ulidLen*5 - 128
+)
+
+// ULID returns a ULID for the current time.
+func (g *Generator) ULID() (ULID, error) {
+	var u ULID
+	if err := g.random(u[6:]); err != nil {
+		return ULID{}, err
+	}
+
+	putMillis(u[:6], g.now())
+
+	return u, nil
+}
+
+// Time returns the creation time stored in u.
+func (u ULID) Time() time.Time {
+	return millis(u[:6])
+}
+
+// String returns the 26-character upper-case form.
+func (u ULID) String() string {
+	var buf [ulidLen]byte
+
+	for i := range buf {
+		var group byte
+
+		for bit := i*5 - ulidPad; bit < i*5-ulidPad+5; bit++ {
+			group <<= 1
+			if bit >= 0 {
+				group |= u[bit/8] >> (7 - bit%8) & 1
+			}
+		}
+
+		buf[i] = crockford[group]
+	}
+
+	return string(buf[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *ULID) UnmarshalText(text []byte) error {
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = parsed
+
+	return nil
+}
+
+// ParseULID parses a ULID case-insensitively, accepting the Crockford
+// aliases I and L for 1 and O for 0.
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+
+	if len(s) != ulidLen {
+		return u, fmt.Errorf("%w: %q", ErrInvalidULID, s)
+	}
+
+	for i := range ulidLen {
+		group, ok := crockfordValue(s[i])
+		if !ok || (i == 0 && group>>(5-ulidPad) != 0) {
+			return ULID{}, fmt.Errorf("%w: %q", ErrInvalidULID, s)
+		}
+
+		u.setGroup(i, group)
+	}
+
+	return u, nil
+}
+
+// setGroup stores the five bits of the character at index i.
+func (u *ULID) setGroup(i int, group byte) {
+	for offset := range 5 {
+		bit := i*5 - ulidPad + offset
+		if bit >= 0 && group>>(4-offset)&1 == 1 {
+			u[bit/8] |= 1 << (7 - bit%8)
+		}
+	}
+}
+
+// IsULID reports whether s parses as a ULID.
+func IsULID(s string) bool {
+	_, err := ParseULID(s)
+
+	return err == nil
+}
+
+func crockfordValue(c byte) (byte, bool) {
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+
+	switch c {
+	case 'I', 'L':
+		return 1, true
+	case 'O':
+		return 0, true
+	}
+
+	for i := range len(crockford) {
+		if crockford[i] == c {
+			return byte(i), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,85 @@
+package id_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/id"
+)
+
+func TestULID(t *testing.T) {
+	t.Parallel()
+
+	zero := id.NewGenerator(
+		id.WithEntropy(bytes.NewReader(make([]byte, 10))),
+		id.WithNow(func() time.Time { return fixedTime }),
+	)
+
+	u, err := zero.ULID()
+	require.NoError(t, err)
+	assert.Equal(t, "01ARYZ6S410000000000000000", u.String())
+	assert.Equal(t, fixedTime, u.Time())
+
+	first, err := deterministic().ULID()
+	require.NoError(t, err)
+
+	second, err := deterministic().ULID()
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	earlier := id.NewULID()
+	time.Sleep(2 * time.Millisecond)
+	assert.Less(t, earlier.String(), id.NewULID().String())
+}
+
+func TestParseULID(t *testing.T) {
+	t.Parallel()
+
+	for range 100 {
+		u := id.NewULID()
+
+		parsed, err := id.ParseULID(u.String())
+		require.NoError(t, err)
+		assert.Equal(t, u, parsed)
+
+		parsed, err = id.ParseULID(strings.ToLower(u.String()))
+		require.NoError(t, err)
+		assert.Equal(t, u, parsed)
+	}
+
+	aliased, err := id.ParseULID("01ARYZ6S4IOOOOOOOOOOOOOOOL")
+	require.NoError(t, err)
+	assert.Equal(t, "01ARYZ6S410000000000000001", aliased.String())
+
+	maxULID, err := id.ParseULID("7ZZZZZZZZZZZZZZZZZZZZZZZZZ")
+	require.NoError(t, err)
+	assert.Equal(t, id.ULID(bytes.Repeat([]byte{0xff}, 16)), maxULID)
+
+	for _, input := range []string{
+		"", "01ARYZ6S41", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ",
+		"01ARYZ6S41000000000000000U",
+	} {
+		_, err := id.ParseULID(input)
+		require.ErrorIs(t, err, id.ErrInvalidULID, input)
+		assert.False(t, id.IsULID(input))
+	}
+}
+
+func TestULIDJSON(t *testing.T) {
+	t.Parallel()
+
+	in := id.NewULID()
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, `"`+in.String()+`"`, string(data))
+
+	var out id.ULID
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+}
@@ -0,0 +1,130 @@
+package id
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidUUID is returned when parsing malformed UUIDs.
+var ErrInvalidUUID = errors.New("id: invalid UUID")
+
+// UUID is an RFC 9562 universally unique identifier.
+type UUID [16]byte
+
+// Nil is the all-zero UUID.
+var Nil UUID
+
+// UUIDv4 returns a random UUID.
+func (g *Generator) UUIDv4() (UUID, error) {
+	var u UUID
+	if err := g.random(u[:]); err != nil {
+		return Nil, err
+	}
+
+	u.setVersion(4)
+
+	return u, nil
+}
+
+// UUIDv7 returns a UUID starting with the Unix time in milliseconds.
+func (g *Generator) UUIDv7() (UUID, error) {
+	var u UUID
+	if err := g.random(u[6:]); err != nil {
+		return Nil, err
+	}
+
+	putMillis(u[:6], g.now())
+	u.setVersion(7)
+
+	return u, nil
+}
+
+func (u *UUID) setVersion(version byte) {
+	u[6] = u[6]&0x0f | version<<4
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant.
+}
+
+// Version returns the version number stored in u.
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Time returns the creation time of a UUIDv7, or the zero time for other
+// versions.
+func (u UUID) Time() time.Time {
+	if u.Version() != 7 {
+		return time.Time{}
+	}
+
+	return millis(u[:6])
+}
+
+// String returns the canonical hyphenated form.
+func (u UUID) String() string {
+	var buf [36]byte
+
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = parsed
+
+	return nil
+}
+
+// ParseUUID parses the hyphenated form, optionally prefixed by "urn:uuid:",
+// or 32 bare hex digits, in any case.
+func ParseUUID(s string) (UUID, error) {
+	text := strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+
+	if len(text) == 36 {
+		if text[8] != '-' || text[13] != '-' || text[18] != '-' ||
+			text[23] != '-' {
+			return Nil, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+		}
+
+		text = text[:8] + text[9:13] + text[14:18] + text[19:23] + text[24:]
+	}
+
+	var u UUID
+	if len(text) != 32 {
+		return Nil, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+	}
+
+	if _, err := hex.Decode(u[:], []byte(text)); err != nil {
+		return Nil, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+	}
+
+	return u, nil
+}
+
+// IsUUID reports whether s parses as a UUID.
+func IsUUID(s string) bool {
+	_, err := ParseUUID(s)
+
+	return err == nil
+}
@@ -0,0 +1,113 @@
+package id_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/id"
+)
+
+var fixedTime = time.UnixMilli(1469918176385)
+
+func deterministic() *id.Generator {
+	return id.NewGenerator(
+		id.WithEntropy(rand.NewChaCha8([32]byte{})),
+		id.WithNow(func() time.Time { return fixedTime }),
+	)
+}
+
+func TestUUIDv4(t *testing.T) {
+	t.Parallel()
+
+	u := id.NewUUIDv4()
+	assert.Equal(t, 4, u.Version())
+	assert.NotEqual(t, id.NewUUIDv4(), u)
+	assert.True(t, id.IsUUID(u.String()))
+	assert.Contains(t, "89ab", u.String()[19:20], "RFC 9562 variant")
+
+	zero := id.NewGenerator(id.WithEntropy(bytes.NewReader(make([]byte, 16))))
+	u, err := zero.UUIDv4()
+	require.NoError(t, err)
+	assert.Equal(t, "00000000-0000-4000-8000-000000000000", u.String())
+
+	_, err = id.NewGenerator(id.WithEntropy(
+		iotest.ErrReader(assert.AnError))).UUIDv4()
+	require.ErrorIs(t, err, assert.AnError)
+}
+
+func TestUUIDv7(t *testing.T) {
+	t.Parallel()
+
+	first, err := deterministic().UUIDv7()
+	require.NoError(t, err)
+
+	second, err := deterministic().UUIDv7()
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second, "same entropy and clock")
+	assert.Equal(t, 7, first.Version())
+	assert.Equal(t, fixedTime, first.Time())
+	assert.Equal(t, "01563df3-6481-7", first.String()[:15])
+
+	earlier := id.NewUUIDv7()
+	time.Sleep(2 * time.Millisecond)
+	assert.Less(t, earlier.String(), id.NewUUIDv7().String())
+	assert.True(t, id.NewUUIDv4().Time().IsZero())
+}
+
+func TestParseUUID(t *testing.T) {
+	t.Parallel()
+
+	want := id.UUID{
+		0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1,
+		0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8,
+	}
+
+	for _, input := range []string{
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"6ba7b8109dad11d180b400c04fd430c8",
+	} {
+		got, err := id.ParseUUID(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{
+		"", "6ba7b810-9dad-11d1-80b4-00c04fd430c", "6ba7b810x9dad-11d1",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430cz",
+		"6ba7b8109-dad-11d1-80b4-00c04fd430c8",
+	} {
+		_, err := id.ParseUUID(input)
+		require.ErrorIs(t, err, id.ErrInvalidUUID, input)
+		assert.False(t, id.IsUUID(input))
+	}
+}
+
+func TestUUIDJSON(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		ID id.UUID `json:"id"`
+	}
+
+	in := record{ID: id.NewUUIDv7()}
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"`+in.ID.String()+`"}`, string(data))
+
+	var out record
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"id":"nope"}`), &out),
+		id.ErrInvalidUUID)
+}
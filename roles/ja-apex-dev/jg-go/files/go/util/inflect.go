@@ -0,0 +1,224 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// inflectionTable holds the English words that escape the suffix rules of
+// Pluralize and Singularize.
+type inflectionTable struct {
+	mu           sync.RWMutex
+	plurals      map[string]string
+	singulars    map[string]string
+	uncountables map[string]bool
+}
+
+var inflections = newInflectionTable()
+
+func newInflectionTable() *inflectionTable {
+	t := &inflectionTable{
+		plurals:      make(map[string]string),
+		singulars:    make(map[string]string),
+		uncountables: make(map[string]bool),
+	}
+
+	for singular, plural := range map[string]string{
+		"person": "people", "child": "children", "man": "men",
+		"woman": "women", "mouse": "mice", "goose": "geese",
+		"foot": "feet", "tooth": "teeth", "ox": "oxen",
+		"index": "indices", "matrix": "matrices", "vertex": "vertices",
+		"analysis": "analyses", "basis": "bases", "crisis": "crises",
+		"axis": "axes", "criterion": "criteria", "datum": "data",
+		"medium": "media", "schema": "schemas", "status": "statuses",
+		"bus": "buses", "alias": "aliases", "quiz": "quizzes",
+		"hero": "heroes", "potato": "potatoes", "echo": "echoes",
+		"knife": "knives", "life": "lives", "wife": "wives",
+		"leaf": "leaves", "half": "halves", "shelf": "shelves",
+		"wolf": "wolves", "thief": "thieves", "cache": "caches",
+		"niche": "niches", "move": "moves", "movie": "movies",
+		"cookie": "cookies", "menu": "menus",
+	} {
+		t.plurals[singular] = plural
+		t.singulars[plural] = singular
+	}
+
+	for _, word := range []string{
+		"sheep", "fish", "deer", "series", "species", "information",
+		"equipment", "metadata", "news", "software", "feedback",
+	} {
+		t.uncountables[word] = true
+	}
+
+	return t
+}
+
+// RegisterIrregular adds or replaces an irregular word pair used by
+// Pluralize and Singularize. Both words are matched case-insensitively.
+func RegisterIrregular(singular, plural string) {
+	singular, plural = strings.ToLower(singular), strings.ToLower(plural)
+
+	inflections.mu.Lock()
+	defer inflections.mu.Unlock()
+
+	inflections.plurals[singular] = plural
+	inflections.singulars[plural] = singular
+}
+
+// RegisterUncountable marks words whose plural and singular forms match,
+// like "sheep".
+func RegisterUncountable(words ...string) {
+	inflections.mu.Lock()
+	defer inflections.mu.Unlock()
+
+	for _, word := range words {
+		inflections.uncountables[strings.ToLower(word)] = true
+	}
+}
+
+// Pluralize returns word as is for a count of one and its plural form
+// otherwise, so that fmt.Sprintf("%d %s changed", n, Pluralize("file", n))
+// reads naturally. Only the last word of a phrase is inflected, and the
+// capitalization of the input is kept.
+func Pluralize(word string, count int) string {
+	if count == 1 {
+		return word
+	}
+
+	return inflect(word, func(lower string) string {
+		if plural, ok := inflections.plurals[lower]; ok {
+			return plural
+		}
+
+		return pluralSuffix(lower)
+	})
+}
+
+// Singularize returns the singular form of word, the reverse of
+// Pluralize. Words already singular are returned unchanged.
+func Singularize(word string) string {
+	return inflect(word, func(lower string) string {
+		if singular, ok := inflections.singulars[lower]; ok {
+			return singular
+		}
+
+		if _, ok := inflections.plurals[lower]; ok {
+			return lower
+		}
+
+		return singularSuffix(lower)
+	})
+}
+
+// Ordinal returns n followed by its English ordinal suffix: "1st", "2nd",
+// "3rd", "4th", "11th", "22nd".
+func Ordinal(n int) string {
+	suffix := "th"
+
+	abs := max(n, -n)
+	if tens := abs % 100; tens < 11 || tens > 13 {
+		switch abs % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return strconv.Itoa(n) + suffix
+}
+
+// inflect applies transform to the lower-cased last word of phrase,
+// leaving uncountable words alone.
+func inflect(phrase string, transform func(lower string) string) string {
+	start := len(phrase)
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(phrase[:start])
+		if !unicode.IsLetter(r) {
+			break
+		}
+
+		start -= size
+	}
+
+	word := phrase[start:]
+	if word == "" {
+		return phrase
+	}
+
+	lower := strings.ToLower(word)
+
+	inflections.mu.RLock()
+	defer inflections.mu.RUnlock()
+
+	if inflections.uncountables[lower] {
+		return phrase
+	}
+
+	return phrase[:start] + matchCase(word, transform(lower))
+}
+
+// pluralSuffix applies the regular English plural rules.
+func pluralSuffix(word string) string {
+	switch {
+	case hasAnySuffix(word, "s", "x", "z", "ch", "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && !endsInVowelY(word):
+		return strings.TrimSuffix(word, "y") + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// singularSuffix reverses pluralSuffix.
+func singularSuffix(word string) string {
+	switch {
+	case !strings.HasSuffix(word, "s") || hasAnySuffix(word, "ss", "us", "is"):
+		return word
+	case strings.HasSuffix(word, "ies") && len(word) > len("ties"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case hasAnySuffix(word, "sses", "xes", "zzes", "ches", "shes"):
+		return strings.TrimSuffix(word, "es")
+	default:
+		return strings.TrimSuffix(word, "s")
+	}
+}
+
+func hasAnySuffix(word string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// endsInVowelY reports whether word ends in a vowel followed by "y", as in
+// "day", which takes a plain "s".
+func endsInVowelY(word string) bool {
+	return len(word) > 1 &&
+		strings.ContainsRune("aeiou", rune(word[len(word)-2]))
+}
+
+// matchCase applies the capitalization of word to its inflection: all
+// upper case, a leading capital or lower case.
+func matchCase(word, inflection string) string {
+	if strings.ToUpper(word) == word && strings.ToLower(word) != word {
+		return strings.ToUpper(inflection)
+	}
+
+	first, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(first) {
+		return inflection
+	}
+
+	head, size := utf8.DecodeRuneInString(inflection)
+
+	return string(unicode.ToUpper(head)) + inflection[size:]
+}
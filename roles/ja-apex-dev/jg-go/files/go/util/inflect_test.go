@@ -0,0 +1,88 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util"
+)
+
+func TestPluralize(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"file":        "files",
+		"box":         "boxes",
+		"match":       "matches",
+		"policy":      "policies",
+		"day":         "days",
+		"person":      "people",
+		"status":      "statuses",
+		"knife":       "knives",
+		"sheep":       "sheep",
+		"File":        "Files",
+		"CHILD":       "CHILDREN",
+		"Person":      "People",
+		"user group":  "user groups",
+		"config_file": "config_files",
+		"":            "",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, util.Pluralize(word, 3), word)
+		assert.Equal(t, word, util.Pluralize(word, 1), word)
+	}
+
+	assert.Equal(t, "files", util.Pluralize("file", 0))
+}
+
+func TestSingularize(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"files":      "file",
+		"boxes":      "box",
+		"matches":    "match",
+		"policies":   "policy",
+		"days":       "day",
+		"people":     "person",
+		"statuses":   "status",
+		"caches":     "cache",
+		"ties":       "tie",
+		"knives":     "knife",
+		"series":     "series",
+		"class":      "class",
+		"status":     "status",
+		"file":       "file",
+		"Children":   "Child",
+		"job QUEUES": "job QUEUE",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, util.Singularize(word), word)
+	}
+}
+
+func TestRegisterIrregular(t *testing.T) {
+	t.Parallel()
+
+	util.RegisterIrregular("Cactus", "Cacti")
+	util.RegisterUncountable("Kudos")
+
+	assert.Equal(t, "cacti", util.Pluralize("cactus", 2))
+	assert.Equal(t, "Cactus", util.Singularize("Cacti"))
+	assert.Equal(t, "kudos", util.Pluralize("kudos", 2))
+	assert.Equal(t, "kudos", util.Singularize("kudos"))
+}
+
+func TestOrdinal(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int]string{
+		0: "0th", 1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th",
+		12: "12th", 13: "13th", 21: "21st", 22: "22nd", 101: "101st",
+		111: "111th", 1003: "1003rd", -1: "-1st", -12: "-12th",
+	}
+	for n, want := range cases {
+		assert.Equal(t, want, util.Ordinal(n), n)
+	}
+}
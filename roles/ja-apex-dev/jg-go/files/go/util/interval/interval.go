@@ -0,0 +1,170 @@
+// Package interval computes with half-open ranges of numbers and times,
+// such as maintenance windows and scheduled runs:
+//
+//	windows := interval.NewSet(
+//		interval.Interval[time.Time]{Start: mon2am, End: mon4am},
+//		interval.Interval[time.Time]{Start: mon3am, End: mon5am},
+//	)
+//	windows.Intervals()      // [mon2am, mon5am)
+//	windows.Overlaps(deploy) // whether deploy hits a window
+//	windows.Coverage(week)   // fraction of the week in windows
+//	interval.Conflicts(jobs) // pairs of overlapping jobs
+//
+// An Interval contains its Start but not its End, so that back-to-back
+// intervals do not overlap; it is empty when End is not after Start.
+package interval
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Point is the type of interval bounds: numbers, durations included, and
+// times.
+type Point interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | time.Time
+}
+
+// Interval is the half-open range [Start, End).
+type Interval[T Point] struct {
+	Start T
+	End   T
+}
+
+// New returns the interval [start, end).
+func New[T Point](start, end T) Interval[T] {
+	return Interval[T]{Start: start, End: end}
+}
+
+// String formats the interval as [Start, End).
+func (i Interval[T]) String() string {
+	return fmt.Sprintf("[%v, %v)", i.Start, i.End)
+}
+
+// IsEmpty reports whether the interval contains no point.
+func (i Interval[T]) IsEmpty() bool {
+	return compare(i.Start, i.End) >= 0
+}
+
+// Contains reports whether p is in the interval.
+func (i Interval[T]) Contains(p T) bool {
+	return compare(i.Start, p) <= 0 && compare(p, i.End) < 0
+}
+
+// Overlaps reports whether the intervals share a point.
+func (i Interval[T]) Overlaps(other Interval[T]) bool {
+	return !i.IsEmpty() && !other.IsEmpty() &&
+		compare(i.Start, other.End) < 0 && compare(other.Start, i.End) < 0
+}
+
+// Intersect returns the points shared by the intervals, and whether there
+// are any.
+func (i Interval[T]) Intersect(other Interval[T]) (Interval[T], bool) {
+	shared := Interval[T]{
+		Start: maxPoint(i.Start, other.Start), End: minPoint(i.End, other.End),
+	}
+	if shared.IsEmpty() {
+		return Interval[T]{}, false
+	}
+
+	return shared, true
+}
+
+// Subtract returns the parts of the interval outside other: none, one or
+// two intervals in order.
+func (i Interval[T]) Subtract(other Interval[T]) []Interval[T] {
+	if i.IsEmpty() {
+		return nil
+	}
+
+	if !i.Overlaps(other) {
+		return []Interval[T]{i}
+	}
+
+	var parts []Interval[T]
+	if compare(i.Start, other.Start) < 0 {
+		parts = append(parts, Interval[T]{Start: i.Start, End: other.Start})
+	}
+
+	if compare(other.End, i.End) < 0 {
+		parts = append(parts, Interval[T]{Start: other.End, End: i.End})
+	}
+
+	return parts
+}
+
+// Len returns the length of the interval, in nanoseconds for times, and
+// 0 when empty.
+func (i Interval[T]) Len() float64 {
+	if i.IsEmpty() {
+		return 0
+	}
+
+	if start, ok := any(i.Start).(time.Time); ok {
+		end, _ := any(i.End).(time.Time)
+
+		return float64(end.Sub(start))
+	}
+
+	return toFloat(i.End) - toFloat(i.Start)
+}
+
+// Duration returns the length of an interval of times or durations.
+func (i Interval[T]) Duration() time.Duration {
+	return time.Duration(i.Len())
+}
+
+// compare orders points like cmp.Compare, which the time.Time member of
+// Point rules out.
+func compare[T Point](a, b T) int {
+	if a, ok := any(a).(time.Time); ok {
+		b, _ := any(b).(time.Time)
+
+		return a.Compare(b)
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	switch {
+	case va.CanInt():
+		return cmp.Compare(va.Int(), vb.Int())
+	case va.CanUint():
+		return cmp.Compare(va.Uint(), vb.Uint())
+	default:
+		return cmp.Compare(va.Float(), vb.Float())
+	}
+}
+
+// toFloat converts a numeric point.
+func toFloat[T Point](p T) float64 {
+	v := reflect.ValueOf(p)
+
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func minPoint[T Point](a, b T) T {
+	if compare(a, b) <= 0 {
+		return a
+	}
+
+	return b
+}
+
+func maxPoint[T Point](a, b T) T {
+	if compare(a, b) >= 0 {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,81 @@
+package interval_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/interval"
+)
+
+var start = time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+
+// hours returns the interval of times from hour from to hour to of the
+// start day.
+func hours(from, to int) interval.Interval[time.Time] {
+	return interval.New(start.Add(time.Duration(from)*time.Hour),
+		start.Add(time.Duration(to)*time.Hour))
+}
+
+func TestInterval(t *testing.T) {
+	t.Parallel()
+
+	i := interval.New(2, 5)
+	assert.Equal(t, "[2, 5)", i.String())
+	assert.False(t, i.IsEmpty())
+	assert.True(t, interval.New(5, 5).IsEmpty())
+	assert.True(t, interval.New(5, 2).IsEmpty())
+
+	assert.True(t, i.Contains(2))
+	assert.True(t, i.Contains(4))
+	assert.False(t, i.Contains(5), "the end is excluded")
+	assert.False(t, i.Contains(1))
+
+	assert.True(t, i.Overlaps(interval.New(4, 8)))
+	assert.False(t, i.Overlaps(interval.New(5, 8)), "back-to-back")
+	assert.False(t, i.Overlaps(interval.New(3, 3)), "empty")
+
+	assert.InDelta(t, 3, i.Len(), 0)
+	assert.Zero(t, interval.New(5, 2).Len())
+	assert.InDelta(t, 0.25, interval.New(-0.5, -0.25).Len(), 1e-9)
+	assert.InDelta(t, 10, interval.New[uint8](0, 10).Len(), 0)
+}
+
+func TestIntersect(t *testing.T) {
+	t.Parallel()
+
+	shared, ok := interval.New(2, 6).Intersect(interval.New(4, 9))
+	assert.True(t, ok)
+	assert.Equal(t, interval.New(4, 6), shared)
+
+	_, ok = interval.New(2, 4).Intersect(interval.New(4, 9))
+	assert.False(t, ok)
+}
+
+func TestSubtract(t *testing.T) {
+	t.Parallel()
+
+	i := interval.New(0, 10)
+	assert.Equal(t, []interval.Interval[int]{interval.New(0, 3),
+		interval.New(6, 10)}, i.Subtract(interval.New(3, 6)))
+	assert.Equal(t, []interval.Interval[int]{interval.New(5, 10)},
+		i.Subtract(interval.New(-5, 5)))
+	assert.Equal(t, []interval.Interval[int]{i},
+		i.Subtract(interval.New(10, 12)))
+	assert.Empty(t, i.Subtract(interval.New(-1, 11)))
+	assert.Empty(t, interval.New(3, 3).Subtract(interval.New(0, 1)))
+}
+
+func TestIntervalTimes(t *testing.T) {
+	t.Parallel()
+
+	window := hours(2, 4)
+	assert.True(t, window.Contains(start.Add(3*time.Hour)))
+	assert.True(t, window.Overlaps(hours(3, 5)))
+	assert.Equal(t, 2*time.Hour, window.Duration())
+
+	durations := interval.New(time.Minute, time.Hour)
+	assert.True(t, durations.Contains(30*time.Minute))
+	assert.Equal(t, 59*time.Minute, durations.Duration())
+}
@@ -0,0 +1,172 @@
+package interval
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
+
+// Merge returns the union of the intervals as sorted, disjoint intervals,
+// joining the overlapping and back-to-back ones and dropping empty ones.
+func Merge[T Point](intervals ...Interval[T]) []Interval[T] {
+	sorted := slices.DeleteFunc(slices.Clone(intervals), Interval[T].IsEmpty)
+	slices.SortFunc(sorted, func(a, b Interval[T]) int {
+		return compare(a.Start, b.Start)
+	})
+
+	var merged []Interval[T]
+
+	for _, i := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && compare(i.Start, merged[last].End) <= 0 {
+			merged[last].End = maxPoint(merged[last].End, i.End)
+
+			continue
+		}
+
+		merged = append(merged, i)
+	}
+
+	return merged
+}
+
+// Conflicts returns the index pairs of the overlapping intervals, the
+// lower index first, sorted.
+func Conflicts[T Point](intervals []Interval[T]) [][2]int {
+	order := make([]int, len(intervals))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return compare(intervals[a].Start, intervals[b].Start)
+	})
+
+	var (
+		pairs  [][2]int
+		active []int
+	)
+
+	for _, i := range order {
+		// Intervals ending before i starts cannot overlap the next ones.
+		active = slices.DeleteFunc(active, func(j int) bool {
+			return compare(intervals[j].End, intervals[i].Start) <= 0
+		})
+
+		for _, j := range active {
+			if intervals[i].Overlaps(intervals[j]) {
+				pairs = append(pairs, [2]int{min(i, j), max(i, j)})
+			}
+		}
+
+		if !intervals[i].IsEmpty() {
+			active = append(active, i)
+		}
+	}
+
+	slices.SortFunc(pairs, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	return pairs
+}
+
+// Set is a union of intervals. The zero value is an empty set.
+type Set[T Point] struct {
+	// intervals are sorted, disjoint and not back-to-back.
+	intervals []Interval[T]
+}
+
+// NewSet returns the union of the intervals.
+func NewSet[T Point](intervals ...Interval[T]) *Set[T] {
+	return &Set[T]{intervals: Merge(intervals...)}
+}
+
+// Intervals returns the sorted, disjoint intervals making the set.
+func (s *Set[T]) Intervals() []Interval[T] {
+	return slices.Clone(s.intervals)
+}
+
+// IsEmpty reports whether the set contains no point.
+func (s *Set[T]) IsEmpty() bool {
+	return len(s.intervals) == 0
+}
+
+// Add adds the intervals to the set.
+func (s *Set[T]) Add(intervals ...Interval[T]) {
+	s.intervals = Merge(append(s.intervals, intervals...)...)
+}
+
+// Remove removes the points of the intervals from the set.
+func (s *Set[T]) Remove(intervals ...Interval[T]) {
+	for _, removed := range intervals {
+		var kept []Interval[T]
+		for _, i := range s.intervals {
+			kept = append(kept, i.Subtract(removed)...)
+		}
+
+		s.intervals = kept
+	}
+}
+
+// Contains reports whether p is in the set.
+func (s *Set[T]) Contains(p T) bool {
+	// The first interval ending after p is the only one that may hold it.
+	i := sort.Search(len(s.intervals), func(i int) bool {
+		return compare(p, s.intervals[i].End) < 0
+	})
+
+	return i < len(s.intervals) && s.intervals[i].Contains(p)
+}
+
+// Overlaps reports whether the set shares a point with other.
+func (s *Set[T]) Overlaps(other Interval[T]) bool {
+	return slices.ContainsFunc(s.intervals, other.Overlaps)
+}
+
+// Len returns the total length of the set, in nanoseconds for times.
+func (s *Set[T]) Len() float64 {
+	var total float64
+	for _, i := range s.intervals {
+		total += i.Len()
+	}
+
+	return total
+}
+
+// Intersect returns the parts of the set within window.
+func (s *Set[T]) Intersect(window Interval[T]) []Interval[T] {
+	var parts []Interval[T]
+
+	for _, i := range s.intervals {
+		if shared, ok := i.Intersect(window); ok {
+			parts = append(parts, shared)
+		}
+	}
+
+	return parts
+}
+
+// Gaps returns the parts of window outside the set, such as the free
+// slots between maintenance windows.
+func (s *Set[T]) Gaps(window Interval[T]) []Interval[T] {
+	gaps := NewSet(window)
+	gaps.Remove(s.intervals...)
+
+	return gaps.intervals
+}
+
+// Coverage returns the fraction of window in the set, from 0 to 1, and 0
+// for an empty window.
+func (s *Set[T]) Coverage(window Interval[T]) float64 {
+	if window.IsEmpty() {
+		return 0
+	}
+
+	var covered float64
+	for _, i := range s.Intersect(window) {
+		covered += i.Len()
+	}
+
+	return covered / window.Len()
+}
@@ -0,0 +1,78 @@
+package interval_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/interval"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []interval.Interval[int]{
+		interval.New(0, 4), interval.New(6, 10),
+	}, interval.Merge(
+		interval.New(8, 10), interval.New(0, 2), interval.New(1, 3),
+		interval.New(3, 4), interval.New(6, 9), interval.New(7, 7),
+	))
+	assert.Empty(t, interval.Merge[int]())
+}
+
+func TestConflicts(t *testing.T) {
+	t.Parallel()
+
+	jobs := []interval.Interval[time.Time]{
+		hours(0, 2), hours(5, 6), hours(1, 3), hours(2, 5), hours(0, 10),
+		hours(4, 4),
+	}
+	assert.Equal(t, [][2]int{
+		{0, 2}, {0, 4}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+	}, interval.Conflicts(jobs))
+	assert.Empty(t, interval.Conflicts(jobs[:2]))
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	var empty interval.Set[int]
+	assert.True(t, empty.IsEmpty())
+	assert.False(t, empty.Contains(0))
+
+	s := interval.NewSet(interval.New(0, 5), interval.New(10, 15))
+	s.Add(interval.New(4, 7), interval.New(20, 30))
+	s.Remove(interval.New(12, 13), interval.New(25, 40))
+
+	assert.Equal(t, []interval.Interval[int]{
+		interval.New(0, 7), interval.New(10, 12), interval.New(13, 15),
+		interval.New(20, 25),
+	}, s.Intervals())
+	assert.InDelta(t, 16, s.Len(), 0)
+
+	for p, want := range map[int]bool{
+		-1: false, 0: true, 6: true, 7: false, 12: false, 13: true,
+		24: true, 25: false,
+	} {
+		assert.Equal(t, want, s.Contains(p), p)
+	}
+
+	assert.True(t, s.Overlaps(interval.New(11, 13)))
+	assert.False(t, s.Overlaps(interval.New(7, 10)))
+}
+
+func TestSetWindows(t *testing.T) {
+	t.Parallel()
+
+	windows := interval.NewSet(hours(2, 4), hours(3, 5), hours(22, 26))
+	day := hours(0, 24)
+
+	assert.Equal(t, []interval.Interval[time.Time]{hours(2, 5), hours(22, 24)},
+		windows.Intersect(day))
+	assert.Equal(t, []interval.Interval[time.Time]{hours(0, 2), hours(5, 22)},
+		windows.Gaps(day))
+	assert.InDelta(t, 5.0/24, windows.Coverage(day), 1e-9)
+	assert.Zero(t, windows.Coverage(hours(1, 1)))
+	assert.Equal(t, 7*time.Hour, time.Duration(windows.Len()))
+}
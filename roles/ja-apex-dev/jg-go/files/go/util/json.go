@@ -0,0 +1,107 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+)
+
+// ErrTrailingData is returned by DecodeStrict when the input holds more
+// than a single JSON value.
+var ErrTrailingData = errors.New("util: trailing data after JSON value")
+
+// JSONIndent is the indentation used by PrettyJSON.
+const JSONIndent = "  "
+
+// DecodeStrict decodes a single JSON value from r into v, failing on
+// unknown object fields and on trailing data.
+func DecodeStrict(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("util: decode JSON: %w", err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return ErrTrailingData
+	}
+
+	return nil
+}
+
+// UnmarshalStrict is DecodeStrict for in-memory data.
+func UnmarshalStrict(data []byte, v any) error {
+	return DecodeStrict(bytes.NewReader(data), v)
+}
+
+// MustMarshal marshals v to JSON, panicking on failure. It is meant for
+// values known to be serializable, such as static fixtures.
+func MustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("util: marshal JSON: %v", err))
+	}
+
+	return data
+}
+
+// PrettyJSON renders v as indented JSON without HTML escaping. Byte slices
+// and json.RawMessage are treated as encoded JSON and re-indented.
+func PrettyJSON(v any) (string, error) {
+	switch raw := v.(type) {
+	case json.RawMessage:
+		return indentJSON(raw)
+	case []byte:
+		return indentJSON(raw)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", JSONIndent)
+
+	if err := encoder.Encode(v); err != nil {
+		return "", fmt.Errorf("util: marshal JSON: %w", err)
+	}
+
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+func indentJSON(data []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", JSONIndent); err != nil {
+		return "", fmt.Errorf("util: indent JSON: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// Number returns the exact value of a Go number, a pointer to one or a
+// json.Number, and false for other values, so that the numbers decoded
+// with UseNumber compare without rounding to float64.
+func Number(value any) (*big.Rat, bool) {
+	if n, ok := value.(json.Number); ok {
+		return new(big.Rat).SetString(n.String())
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(value))
+
+	switch {
+	case v.CanInt():
+		return new(big.Rat).SetInt64(v.Int()), true
+	case v.CanUint():
+		return new(big.Rat).SetUint64(v.Uint()), true
+	case v.CanFloat():
+		r := new(big.Rat).SetFloat64(v.Float())
+
+		return r, r != nil
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,84 @@
+package util_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+type server struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestDecodeStrict(t *testing.T) {
+	t.Parallel()
+
+	var s server
+	require.NoError(t, util.DecodeStrict(
+		strings.NewReader(`{"host": "localhost", "port": 80}`), &s))
+	assert.Equal(t, server{Host: "localhost", Port: 80}, s)
+
+	err := util.UnmarshalStrict([]byte(`{"hots": "typo"}`), &s)
+	require.ErrorContains(t, err, `unknown field "hots"`)
+
+	err = util.UnmarshalStrict([]byte(`{"host": "a"} {"host": "b"}`), &s)
+	require.ErrorIs(t, err, util.ErrTrailingData)
+
+	require.NoError(t, util.UnmarshalStrict([]byte("{}\n  "), &s))
+}
+
+func TestMustMarshal(t *testing.T) {
+	t.Parallel()
+
+	assert.JSONEq(t, `{"host":"h","port":1}`,
+		string(util.MustMarshal(server{Host: "h", Port: 1})))
+	assert.Panics(t, func() { util.MustMarshal(math.Inf(1)) })
+}
+
+func TestPrettyJSON(t *testing.T) {
+	t.Parallel()
+
+	pretty, err := util.PrettyJSON(map[string]any{"a": []int{1}, "b": "<x>"})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": [\n    1\n  ],\n  \"b\": \"<x>\"\n}", pretty)
+
+	pretty, err = util.PrettyJSON(json.RawMessage(`{"a":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1\n}", pretty)
+
+	_, err = util.PrettyJSON([]byte(`{broken`))
+	require.Error(t, err)
+}
+
+func TestNumber(t *testing.T) {
+	t.Parallel()
+
+	seven := uint8(7)
+
+	for _, tt := range []struct {
+		value any
+		want  string
+	}{
+		{42, "42"},
+		{&seven, "7"},
+		{-1.5, "-3/2"},
+		{json.Number("9007199254740993"), "9007199254740993"},
+		{json.Number("1e3"), "1000"},
+	} {
+		got, ok := util.Number(tt.value)
+		require.True(t, ok, tt.value)
+		assert.Equal(t, tt.want, got.RatString(), tt.value)
+	}
+
+	for _, value := range []any{"1", nil, math.NaN(), json.Number("x")} {
+		_, ok := util.Number(value)
+		assert.False(t, ok, value)
+	}
+}
@@ -0,0 +1,90 @@
+package jsonpatch
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"example.com/go-template/util"
+)
+
+// Generate returns the patch turning from into to. It is made of remove,
+// add and replace operations in key order, descending into the maps and
+// lists present on both sides; lists are compared by index.
+func Generate(from, to any) Patch {
+	var g generator
+
+	g.diff(nil, from, to)
+
+	return g.patch
+}
+
+type generator struct {
+	patch Patch
+}
+
+// diff records the operations turning from into to at path.
+func (g *generator) diff(path []string, from, to any) {
+	switch from := from.(type) {
+	case map[string]any:
+		if to, ok := to.(map[string]any); ok {
+			g.diffMaps(path, from, to)
+
+			return
+		}
+	case []any:
+		if to, ok := to.([]any); ok {
+			g.diffLists(path, from, to)
+
+			return
+		}
+	}
+
+	if !equal(from, to) {
+		g.add(Replace, path, to)
+	}
+}
+
+func (g *generator) diffMaps(path []string, from, to map[string]any) {
+	for _, key := range slices.Sorted(maps.Keys(from)) {
+		if _, ok := to[key]; !ok {
+			g.add(Remove, with(path, key), nil)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(to)) {
+		if value, ok := from[key]; ok {
+			g.diff(with(path, key), value, to[key])
+		} else {
+			g.add(Add, with(path, key), to[key])
+		}
+	}
+}
+
+// diffLists compares the common items, then removes the extra items from
+// the end or appends the new ones.
+func (g *generator) diffLists(path []string, from, to []any) {
+	common := min(len(from), len(to))
+	for i := range common {
+		g.diff(with(path, strconv.Itoa(i)), from[i], to[i])
+	}
+
+	for i := len(from) - 1; i >= common; i-- {
+		g.add(Remove, with(path, strconv.Itoa(i)), nil)
+	}
+
+	for i := common; i < len(to); i++ {
+		g.add(Add, with(path, strconv.Itoa(i)), to[i])
+	}
+}
+
+func (g *generator) add(op Op, path []string, value any) {
+	g.patch = append(g.patch, Operation{
+		Op: op, Path: Pointer(path...), Value: util.DeepCopy(value),
+	})
+}
+
+// with returns path extended with token, never sharing its array.
+func with(path []string, token string) []string {
+	return append(slices.Clip(path), token)
+}
@@ -0,0 +1,317 @@
+// Package jsonpatch expresses the changes between JSON documents, such as
+// the drift of an actual configuration from the desired one, as JSON Patch
+// (RFC 6902) operation lists or JSON Merge Patch (RFC 7386) documents, to
+// store and replay them:
+//
+//	patch := jsonpatch.Generate(actual, desired)
+//	data, _ := json.Marshal(patch) // [{"op":"replace","path":"/port",...}]
+//	fixed, err := patch.Apply(actual)
+//
+// Documents are trees of map[string]any, []any and scalars, as decoded
+// from JSON or YAML; numbers compare by value whatever their Go type.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"example.com/go-template/util"
+)
+
+var (
+	// ErrInvalidPatch is returned for malformed operations.
+	ErrInvalidPatch = errors.New("jsonpatch: invalid patch")
+	// ErrPath is returned for paths missing from the document.
+	ErrPath = errors.New("jsonpatch: path not found")
+	// ErrTestFailed is returned when a test operation does not match.
+	ErrTestFailed = errors.New("jsonpatch: test failed")
+)
+
+// Op is the kind of an operation.
+type Op string
+
+// Operation kinds.
+const (
+	Add     Op = "add"
+	Remove  Op = "remove"
+	Replace Op = "replace"
+	Move    Op = "move"
+	Copy    Op = "copy"
+	Test    Op = "test"
+)
+
+// hasValue reports whether operations of the kind carry a value.
+func (op Op) hasValue() bool {
+	return op == Add || op == Replace || op == Test
+}
+
+// hasFrom reports whether operations of the kind carry a source path.
+func (op Op) hasFrom() bool {
+	return op == Move || op == Copy
+}
+
+// Operation is a single change of a Patch. Paths are JSON pointers
+// (RFC 6901), as built by Pointer.
+type Operation struct {
+	Op    Op     `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// operation mirrors Operation with a value kept even when null.
+type operation struct {
+	Op    Op      `json:"op"`
+	Path  string  `json:"path"`
+	From  *string `json:"from,omitempty"`
+	Value *any    `json:"value,omitempty"`
+}
+
+// rawOperation tells missing members from null ones when decoding.
+type rawOperation struct {
+	Op    Op              `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// check reports the missing members of the operation kind.
+func (in *rawOperation) check() error {
+	switch {
+	case !in.Op.hasValue() && !in.Op.hasFrom() && in.Op != Remove:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, in.Op)
+	case in.Path == nil:
+		return fmt.Errorf("%w: %s without path", ErrInvalidPatch, in.Op)
+	case in.Op.hasValue() && in.Value == nil:
+		return fmt.Errorf("%w: %s without value", ErrInvalidPatch, in.Op)
+	case in.Op.hasFrom() && in.From == nil:
+		return fmt.Errorf("%w: %s without from", ErrInvalidPatch, in.Op)
+	default:
+		return nil
+	}
+}
+
+// String renders the operation as "op path", with its source if any.
+func (o Operation) String() string {
+	if o.Op.hasFrom() {
+		return fmt.Sprintf("%s %s to %s", o.Op, o.From, o.Path)
+	}
+
+	return fmt.Sprintf("%s %s", o.Op, o.Path)
+}
+
+// MarshalJSON encodes the members of the operation kind only, including
+// null values.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	out := operation{Op: o.Op, Path: o.Path}
+	if o.Op.hasFrom() {
+		out.From = &o.From
+	}
+
+	if o.Op.hasValue() {
+		out.Value = &o.Value
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes an operation, failing with ErrInvalidPatch on
+// unknown kinds and missing members. Numbers of the value are kept as
+// json.Number, so that test operations compare them exactly.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var in rawOperation
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	if err := in.check(); err != nil {
+		return err
+	}
+
+	*o = Operation{Op: in.Op, Path: *in.Path}
+	if in.From != nil {
+		o.From = *in.From
+	}
+
+	if in.Value != nil {
+		decoder := json.NewDecoder(bytes.NewReader(in.Value))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&o.Value); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+	}
+
+	return nil
+}
+
+// Patch is a list of operations applied in order.
+type Patch []Operation
+
+// Decode parses a JSON patch document.
+func Decode(data []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		if !errors.Is(err, ErrInvalidPatch) {
+			err = fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+
+		return nil, err
+	}
+
+	return patch, nil
+}
+
+// Apply returns a copy of doc with the operations applied. Patches are
+// atomic: doc is never modified, and the first failing operation fails the
+// whole patch.
+func (p Patch) Apply(doc any) (any, error) {
+	doc = util.DeepCopy(doc)
+
+	for i, op := range p {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("%w (operation %d: %s)", err, i, op)
+		}
+	}
+
+	return doc, nil
+}
+
+// ApplyJSON applies the JSON patch document patch to the JSON document doc.
+func ApplyJSON(doc, patch []byte) ([]byte, error) {
+	ops, err := Decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformJSON(doc, ops.Apply)
+}
+
+// apply performs the operation on doc, which it may modify.
+func (o Operation) apply(doc any) (any, error) {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case Add:
+		return put(doc, path, util.DeepCopy(o.Value))
+	case Remove:
+		return del(doc, path)
+	case Replace:
+		if len(path) == 0 {
+			return util.DeepCopy(o.Value), nil
+		}
+
+		return edit(doc, path, assign(util.DeepCopy(o.Value)))
+	case Test:
+		return doc, o.test(doc, path)
+	default:
+		return o.transfer(doc, path)
+	}
+}
+
+// test checks the value at path.
+func (o Operation) test(doc any, path []string) error {
+	value, err := get(doc, path)
+	if err != nil {
+		return err
+	}
+
+	if !equal(value, o.Value) {
+		return ErrTestFailed
+	}
+
+	return nil
+}
+
+// transfer moves or copies the value at From to path.
+func (o Operation) transfer(doc any, path []string) (any, error) {
+	from, err := parsePointer(o.From)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := get(doc, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Op == Copy {
+		return put(doc, path, util.DeepCopy(value))
+	}
+
+	if o.From == o.Path {
+		return doc, nil
+	}
+
+	if strings.HasPrefix(o.Path, o.From+"/") {
+		return nil, fmt.Errorf("%w: cannot move %s into itself",
+			ErrInvalidPatch, o.From)
+	}
+
+	if doc, err = del(doc, from); err != nil {
+		return nil, err
+	}
+
+	return put(doc, path, value)
+}
+
+// put adds value at path, replacing the whole document for the root.
+func put(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return edit(doc, path, insert(value))
+}
+
+// del removes the value at path, which cannot be the root.
+func del(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the root", ErrInvalidPatch)
+	}
+
+	return edit(doc, path, remove)
+}
+
+// transformJSON decodes doc, transforms it and encodes the result.
+func transformJSON(doc []byte, transform func(any) (any, error)) (
+	[]byte, error,
+) {
+	value, err := decodeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err = transform(value); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpatch: %w", err)
+	}
+
+	return data, nil
+}
+
+// decodeJSON decodes a document, keeping numbers as json.Number to preserve
+// their precision.
+func decodeJSON(data []byte) (any, error) {
+	var value any
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("jsonpatch: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,233 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/jsonpatch"
+	"example.com/go-template/util/quick"
+)
+
+// parse decodes a JSON document.
+func parse(t *testing.T, doc string) any {
+	t.Helper()
+
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(doc), &value))
+
+	return value
+}
+
+// rfc6902Examples holds examples of RFC 6902, appendix A.
+var rfc6902Examples = map[string]struct{ doc, patch, want string }{
+	"add member": {
+		`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`,
+		`{"baz":"qux","foo":"bar"}`,
+	},
+	"add item": {
+		`{"foo":["bar","baz"]}`,
+		`[{"op":"add","path":"/foo/1","value":"qux"}]`,
+		`{"foo":["bar","qux","baz"]}`,
+	},
+	"remove item": {
+		`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`,
+		`{"foo":["bar","baz"]}`,
+	},
+	"replace": {
+		`{"baz":"qux","foo":"bar"}`,
+		`[{"op":"replace","path":"/baz","value":"boo"}]`,
+		`{"baz":"boo","foo":"bar"}`,
+	},
+	"move member": {
+		`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+		`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+		`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+	},
+	"move item": {
+		`{"foo":["all","grass","cows","eat"]}`,
+		`[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+		`{"foo":["all","cows","eat","grass"]}`,
+	},
+	"test": {
+		`{"baz":"qux","foo":["a",2,"c"]}`,
+		`[{"op":"test","path":"/baz","value":"qux"},
+			{"op":"test","path":"/foo/1","value":2}]`,
+		`{"baz":"qux","foo":["a",2,"c"]}`,
+	},
+	"add nested": {
+		`{"foo":"bar"}`,
+		`[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+		`{"child":{"grandchild":{}},"foo":"bar"}`,
+	},
+	"escaped": {
+		`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`,
+		`{"/":9,"~1":10}`,
+	},
+	"append": {
+		`{"foo":["bar"]}`,
+		`[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+		`{"foo":["bar",["abc","def"]]}`,
+	},
+	"copy and replace root": {
+		`{"a":{"b":1}}`,
+		`[{"op":"copy","from":"/a","path":"/c"},
+			{"op":"replace","path":"/c/b","value":null},
+			{"op":"replace","path":"","value":{"root":[1]}}]`,
+		`{"root":[1]}`,
+	},
+}
+
+func TestApplyJSON(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range rfc6902Examples {
+		got, err := jsonpatch.ApplyJSON([]byte(tc.doc), []byte(tc.patch))
+		require.NoError(t, err, name)
+		assert.JSONEq(t, tc.want, string(got), name)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+
+	doc := `{"foo":["bar"],"baz":"qux","big":9007199254740992}`
+
+	for _, tc := range []struct {
+		patch string
+		want  error
+	}{
+		{`[{"op":"remove","path":"/missing"}]`, jsonpatch.ErrPath},
+		{`[{"op":"add","path":"/foo/2","value":1}]`, jsonpatch.ErrPath},
+		{`[{"op":"add","path":"/foo/01","value":1}]`, jsonpatch.ErrPath},
+		{`[{"op":"replace","path":"/foo/-","value":1}]`, jsonpatch.ErrPath},
+		{`[{"op":"add","path":"/baz/x","value":1}]`, jsonpatch.ErrPath},
+		{
+			`[{"op":"test","path":"/baz","value":"quux"}]`,
+			jsonpatch.ErrTestFailed,
+		},
+		{
+			`[{"op":"test","path":"/big","value":9007199254740993}]`,
+			jsonpatch.ErrTestFailed,
+		},
+		{
+			`[{"op":"move","from":"/foo","path":"/foo/0"}]`,
+			jsonpatch.ErrInvalidPatch,
+		},
+		{`[{"op":"remove","path":""}]`, jsonpatch.ErrInvalidPatch},
+		{`[{"op":"add","path":"baz","value":1}]`, jsonpatch.ErrInvalidPatch},
+		{`[{"op":"add","path":"/x"}]`, jsonpatch.ErrInvalidPatch},
+		{`[{"op":"copy","path":"/x"}]`, jsonpatch.ErrInvalidPatch},
+		{`[{"op":"merge","path":"/x"}]`, jsonpatch.ErrInvalidPatch},
+		{`[{"op":"add","value":1}]`, jsonpatch.ErrInvalidPatch},
+		{`[{"op":"remove"}]`, jsonpatch.ErrInvalidPatch},
+		{`{"op":"remove"}`, jsonpatch.ErrInvalidPatch},
+		{
+			`[{"op":"add","path":"/x","value":1},{"op":"test"}]`,
+			jsonpatch.ErrInvalidPatch,
+		},
+	} {
+		_, err := jsonpatch.ApplyJSON([]byte(doc), []byte(tc.patch))
+		require.ErrorIs(t, err, tc.want, tc.patch)
+	}
+
+	_, err := jsonpatch.ApplyJSON([]byte(doc),
+		[]byte(`[{"op":"add","path":"/a","value":1},
+			{"op":"remove","path":"/b"}]`))
+	assert.EqualError(t, err, `jsonpatch: path not found: missing key "b" `+
+		`(operation 1: remove /b)`)
+}
+
+func TestApplyAtomic(t *testing.T) {
+	t.Parallel()
+
+	doc := parse(t, `{"list":[1,2],"keep":{"a":1}}`)
+	patch := jsonpatch.Patch{
+		{Op: jsonpatch.Add, Path: "/list/0", Value: 0},
+		{Op: jsonpatch.Remove, Path: "/keep/a"},
+		{Op: jsonpatch.Test, Path: "/list/0", Value: 1},
+	}
+
+	_, err := patch.Apply(doc)
+	require.ErrorIs(t, err, jsonpatch.ErrTestFailed)
+	assert.Equal(t, parse(t, `{"list":[1,2],"keep":{"a":1}}`), doc,
+		"the document is never modified")
+
+	got, err := patch[:2].Apply(doc)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"list": []any{0, 1.0, 2.0}, "keep": map[string]any{},
+	}, got)
+}
+
+func TestPatchJSON(t *testing.T) {
+	t.Parallel()
+
+	patch := jsonpatch.Patch{
+		{Op: jsonpatch.Replace, Path: jsonpatch.Pointer("a/b", "~")},
+		{Op: jsonpatch.Remove, Path: "/c", Value: "ignored"},
+		{Op: jsonpatch.Move, From: "/d", Path: "/e"},
+	}
+
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/a~1b/~0","value":null},
+		{"op":"remove","path":"/c"},
+		{"op":"move","from":"/d","path":"/e"}
+	]`, string(data))
+
+	decoded, err := jsonpatch.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, jsonpatch.Patch{patch[0],
+		{Op: jsonpatch.Remove, Path: "/c"}, patch[2]}, decoded)
+	assert.Equal(t, "move /d to /e", patch[2].String())
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	from := parse(t, `{"name":"web","ports":[80,443,8080],"env":{"A":"1"},
+		"old":true}`)
+	to := parse(t, `{"name":"web","ports":[80,8443],"env":{"A":"2","B":"3"},
+		"tags":["x"]}`)
+
+	assert.Equal(t, jsonpatch.Patch{
+		{Op: jsonpatch.Remove, Path: "/old"},
+		{Op: jsonpatch.Replace, Path: "/env/A", Value: "2"},
+		{Op: jsonpatch.Add, Path: "/env/B", Value: "3"},
+		{Op: jsonpatch.Replace, Path: "/ports/1", Value: 8443.0},
+		{Op: jsonpatch.Remove, Path: "/ports/2"},
+		{Op: jsonpatch.Add, Path: "/tags", Value: []any{"x"}},
+	}, jsonpatch.Generate(from, to))
+	assert.Empty(t, jsonpatch.Generate(from, from))
+	assert.Equal(t, jsonpatch.Patch{
+		{Op: jsonpatch.Replace, Path: "", Value: "scalar"},
+	}, jsonpatch.Generate(from, "scalar"))
+}
+
+func TestGenerateProperty(t *testing.T) {
+	t.Parallel()
+
+	docs := quick.Zip(quick.NestedMaps(3), quick.NestedMaps(3))
+	quick.Check(t, docs, func(t *quick.T, pair quick.Pair[
+		map[string]any, map[string]any,
+	],
+	) {
+		patch := jsonpatch.Generate(pair.First, pair.Second)
+
+		got, err := patch.Apply(pair.First)
+		require.NoError(t, err)
+		assert.Equal(t, pair.Second, got)
+
+		// Generated patches survive a JSON round trip.
+		data, err := json.Marshal(patch)
+		require.NoError(t, err)
+
+		decoded, err := jsonpatch.Decode(data)
+		require.NoError(t, err)
+		assert.Len(t, decoded, len(patch))
+	})
+}
@@ -0,0 +1,76 @@
+package jsonpatch
+
+import "example.com/go-template/util"
+
+// MergePatch returns a copy of target with the JSON merge patch applied
+// (RFC 7386): the members of a patch object are merged recursively into
+// the target object, null members remove the target ones, and any other
+// patch value, lists included, replaces the target whole.
+func MergePatch(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return util.DeepCopy(patch)
+	}
+
+	current, _ := target.(map[string]any)
+	result := make(map[string]any, len(current)+len(members))
+
+	for key, value := range current {
+		if _, patched := members[key]; !patched {
+			result[key] = util.DeepCopy(value)
+		}
+	}
+
+	for key, value := range members {
+		if value != nil {
+			result[key] = MergePatch(current[key], value)
+		}
+	}
+
+	return result
+}
+
+// CreateMergePatch returns the merge patch turning from into to. Merge
+// patches cannot set null values, which are removed when applying it.
+func CreateMergePatch(from, to any) any {
+	before, ok := from.(map[string]any)
+	after, isMap := to.(map[string]any)
+
+	if !ok || !isMap {
+		return util.DeepCopy(to)
+	}
+
+	patch := make(map[string]any)
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			patch[key] = nil
+		}
+	}
+
+	for key, value := range after {
+		previous, ok := before[key]
+
+		switch {
+		case !ok:
+			patch[key] = util.DeepCopy(value)
+		case !equal(previous, value):
+			patch[key] = CreateMergePatch(previous, value)
+		}
+	}
+
+	return patch
+}
+
+// MergePatchJSON applies the JSON merge patch document patch to the JSON
+// document doc.
+func MergePatchJSON(doc, patch []byte) ([]byte, error) {
+	members, err := decodeJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformJSON(doc, func(value any) (any, error) {
+		return MergePatch(value, members), nil
+	})
+}
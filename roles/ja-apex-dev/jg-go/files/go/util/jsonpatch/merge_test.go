@@ -0,0 +1,113 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/jsonpatch"
+	"example.com/go-template/util/quick"
+)
+
+func TestMergePatchJSON(t *testing.T) {
+	t.Parallel()
+
+	// Examples of RFC 7386, appendix A.
+	for _, tc := range []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"big":12345678901234567890}`, `{}`, `{"big":12345678901234567890}`},
+	} {
+		got, err := jsonpatch.MergePatchJSON([]byte(tc.doc), []byte(tc.patch))
+		require.NoError(t, err, tc.patch)
+		assert.JSONEq(t, tc.want, string(got), "%s + %s", tc.doc, tc.patch)
+	}
+
+	_, err := jsonpatch.MergePatchJSON([]byte(`{`), []byte(`{}`))
+	require.Error(t, err)
+}
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	target := map[string]any{"a": map[string]any{"b": 1, "c": 2}, "d": 3}
+	got := jsonpatch.MergePatch(target, map[string]any{
+		"a": map[string]any{"b": nil}, "e": []any{4},
+	})
+
+	assert.Equal(t, map[string]any{
+		"a": map[string]any{"c": 2}, "d": 3, "e": []any{4},
+	}, got)
+	assert.Equal(t, map[string]any{
+		"a": map[string]any{"b": 1, "c": 2}, "d": 3,
+	}, target, "the target is not modified")
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	t.Parallel()
+
+	from := map[string]any{
+		"name": "web", "replicas": 2, "labels": map[string]any{"a": "1"},
+		"old": true,
+	}
+	to := map[string]any{
+		"name": "web", "replicas": 3, "labels": map[string]any{"b": "2"},
+	}
+
+	assert.Equal(t, map[string]any{
+		"replicas": 3, "labels": map[string]any{"a": nil, "b": "2"},
+		"old": nil,
+	}, jsonpatch.CreateMergePatch(from, to))
+	assert.Equal(t, map[string]any{},
+		jsonpatch.CreateMergePatch(from, from))
+	assert.Equal(t, []any{1}, jsonpatch.CreateMergePatch(from, []any{1}))
+}
+
+func TestMergePatchProperty(t *testing.T) {
+	t.Parallel()
+
+	// Merge patches cannot set nulls: the generated documents drop them.
+	docs := quick.Map(quick.Zip(quick.NestedMaps(3), quick.NestedMaps(3)),
+		func(pair quick.Pair[map[string]any, map[string]any]) [2]any {
+			return [2]any{pair.First, withoutNulls(pair.Second)}
+		})
+
+	quick.Check(t, docs, func(t *quick.T, docs [2]any) {
+		patch := jsonpatch.CreateMergePatch(docs[0], docs[1])
+		assert.Equal(t, docs[1], jsonpatch.MergePatch(docs[0], patch))
+	})
+}
+
+// withoutNulls removes the null members of the objects of a document.
+func withoutNulls(doc any) any {
+	switch doc := doc.(type) {
+	case map[string]any:
+		for key, value := range doc {
+			if value == nil {
+				delete(doc, key)
+			} else {
+				doc[key] = withoutNulls(value)
+			}
+		}
+	case []any:
+		for i, item := range doc {
+			doc[i] = withoutNulls(item)
+		}
+	}
+
+	return doc
+}
@@ -0,0 +1,270 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"example.com/go-template/util"
+)
+
+// appendToken is the array index of RFC 6902 adding after the last item.
+const appendToken = "-"
+
+// escaper and unescaper convert reference tokens of RFC 6901.
+var (
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Pointer builds the JSON pointer referencing the given map keys and array
+// indexes, escaping "~" and "/".
+func Pointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(escaper.Replace(token))
+	}
+
+	return b.String()
+}
+
+// parsePointer splits a JSON pointer into unescaped reference tokens. The
+// empty pointer references the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: %q does not start with /", ErrInvalidPatch,
+			pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescaper.Replace(token)
+	}
+
+	return tokens, nil
+}
+
+// index parses the token of an item of an array of n items.
+func index(token string, n int) (int, error) {
+	i, err := insertionIndex(token, n)
+	if err == nil && i == n {
+		return 0, fmt.Errorf("%w: index %d out of range", ErrPath, i)
+	}
+
+	return i, err
+}
+
+// insertionIndex parses the token of an insertion into an array of n
+// items, which accepts n itself and the append token.
+func insertionIndex(token string, n int) (int, error) {
+	if token == appendToken {
+		return n, nil
+	}
+
+	i, err := strconv.Atoi(token)
+
+	switch {
+	case err != nil, token != strconv.Itoa(i), i < 0:
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrPath, token)
+	case i > n:
+		return 0, fmt.Errorf("%w: index %d out of range", ErrPath, i)
+	}
+
+	return i, nil
+}
+
+// child returns the value under token in the container doc.
+func child(doc any, token string) (any, error) {
+	switch doc := doc.(type) {
+	case map[string]any:
+		value, ok := doc[token]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing key %q", ErrPath, token)
+		}
+
+		return value, nil
+	case []any:
+		i, err := index(token, len(doc))
+		if err != nil {
+			return nil, err
+		}
+
+		return doc[i], nil
+	default:
+		return nil, fmt.Errorf("%w: %q in a scalar", ErrPath, token)
+	}
+}
+
+// get returns the value at tokens in doc.
+func get(doc any, tokens []string) (any, error) {
+	for _, token := range tokens {
+		var err error
+		if doc, err = child(doc, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// editFunc changes the container holding the last token of a path, and
+// returns it, possibly reallocated.
+type editFunc func(container any, token string) (any, error)
+
+// edit applies fn to the container of the value at tokens, storing the
+// result back into its own parent. tokens must not be empty.
+func edit(doc any, tokens []string, fn editFunc) (any, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+
+	value, err := child(doc, tokens[0])
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = edit(value, tokens[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// The container exists: child checked it.
+	switch doc := doc.(type) {
+	case map[string]any:
+		doc[tokens[0]] = value
+	case []any:
+		i, _ := strconv.Atoi(tokens[0])
+		doc[i] = value
+	}
+
+	return doc, nil
+}
+
+// insert adds value under token, inserting into arrays.
+func insert(value any) editFunc {
+	return func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			container[token] = value
+
+			return container, nil
+		case []any:
+			i, err := insertionIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+
+			return slices.Insert(container, i, value), nil
+		default:
+			return nil, fmt.Errorf("%w: %q in a scalar", ErrPath, token)
+		}
+	}
+}
+
+// assign replaces the value under token, which must exist.
+func assign(value any) editFunc {
+	return func(container any, token string) (any, error) {
+		if _, err := child(container, token); err != nil {
+			return nil, err
+		}
+
+		switch container := container.(type) {
+		case map[string]any:
+			container[token] = value
+		case []any:
+			i, _ := strconv.Atoi(token)
+			container[i] = value
+		}
+
+		return container, nil
+	}
+}
+
+// remove deletes the value under token, which must exist.
+func remove(container any, token string) (any, error) {
+	if _, err := child(container, token); err != nil {
+		return nil, err
+	}
+
+	switch container := container.(type) {
+	case map[string]any:
+		delete(container, token)
+
+		return container, nil
+	default:
+		items, _ := container.([]any)
+		i, _ := strconv.Atoi(token)
+
+		return slices.Delete(items, i, i+1), nil
+	}
+}
+
+// equal reports whether two documents are equal, comparing numbers by
+// value whatever their Go type.
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+
+		return ok && equalMaps(a, b)
+	case []any:
+		b, ok := b.([]any)
+
+		return ok && equalLists(a, b)
+	}
+
+	return equalScalars(a, b)
+}
+
+// equalScalars compares the leaves of documents.
+func equalScalars(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if x, ok := util.Number(a); ok {
+		y, ok := util.Number(b)
+
+		return ok && x.Cmp(y) == 0
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	return va.Comparable() && vb.Comparable() && a == b
+}
+
+func equalMaps(a, b map[string]any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key, value := range a {
+		other, ok := b[key]
+		if !ok || !equal(value, other) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalLists(a, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
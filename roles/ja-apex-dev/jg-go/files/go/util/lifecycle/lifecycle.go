@@ -0,0 +1,214 @@
+// Package lifecycle coordinates the start and graceful shutdown of service
+// components.
+//
+// A Runner starts the registered hooks in order, waits for SIGINT, SIGTERM,
+// context cancellation or an explicit Shutdown, and then stops the started
+// hooks in reverse order, each bounded by its own timeout.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultStopTimeout bounds every stop hook unless overridden.
+const DefaultStopTimeout = 10 * time.Second
+
+// ErrShutdown is the cause of a shutdown requested without error, as
+// logged by Run, which returns nil for it and for errors wrapping it.
+var ErrShutdown = errors.New("lifecycle: shutdown requested")
+
+// Hook is a component managed by the Runner. Start must return once the
+// component is running, moving long-running work to goroutines.
+type Hook struct {
+	Name        string
+	Start       func(ctx context.Context) error
+	Stop        func(ctx context.Context) error
+	StopTimeout time.Duration
+}
+
+// Option configures a Runner.
+type Option func(*Runner)
+
+// WithLogger sets the logger reporting hook transitions.
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Runner) {
+		r.logger = logger
+	}
+}
+
+// WithStopTimeout sets the default timeout of stop hooks.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.stopTimeout = timeout
+	}
+}
+
+// WithSignals replaces the signals triggering the shutdown. No signals
+// disables signal handling.
+func WithSignals(signals ...os.Signal) Option {
+	return func(r *Runner) {
+		r.signals = signals
+	}
+}
+
+// Runner starts and stops hooks. It must not be copied after first use.
+type Runner struct {
+	logger      *slog.Logger
+	stopTimeout time.Duration
+	signals     []os.Signal
+
+	mu       sync.Mutex
+	hooks    []Hook
+	shutdown chan struct{}
+	cause    error
+	once     sync.Once
+}
+
+// New creates a Runner listening to SIGINT and SIGTERM.
+func New(opts ...Option) *Runner {
+	r := &Runner{
+		logger:      slog.New(slog.DiscardHandler),
+		stopTimeout: DefaultStopTimeout,
+		signals:     []os.Signal{os.Interrupt, syscall.SIGTERM},
+		shutdown:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Append registers hooks, which must happen before Run.
+func (r *Runner) Append(hooks ...Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hooks...)
+}
+
+// Shutdown triggers the shutdown of a running Runner. A non-nil cause is
+// returned by Run, allowing components to report fatal failures; nil
+// stands for ErrShutdown.
+func (r *Runner) Shutdown(cause error) {
+	if cause == nil {
+		cause = ErrShutdown
+	}
+
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.cause = cause
+		r.mu.Unlock()
+
+		close(r.shutdown)
+	})
+}
+
+// Run starts the hooks, blocks until the shutdown is triggered and stops
+// them. It returns the start failure, the Shutdown cause and stop errors.
+func (r *Runner) Run(ctx context.Context) error {
+	r.mu.Lock()
+	hooks := append([]Hook(nil), r.hooks...)
+	r.mu.Unlock()
+
+	waitCtx := ctx
+	if len(r.signals) > 0 {
+		var stop context.CancelFunc
+
+		waitCtx, stop = signal.NotifyContext(ctx, r.signals...)
+		defer stop()
+	}
+
+	started, err := r.start(ctx, hooks)
+	if err == nil {
+		select {
+		case <-waitCtx.Done():
+			r.logger.InfoContext(ctx, "shutting down",
+				"reason", context.Cause(waitCtx))
+		case <-r.shutdown:
+			r.mu.Lock()
+			err = r.cause
+			r.mu.Unlock()
+			r.logger.InfoContext(ctx, "shutting down", "reason", err)
+
+			if errors.Is(err, ErrShutdown) {
+				err = nil
+			}
+		}
+	}
+
+	// Stop hooks must run even when the parent context is already done.
+	return errors.Join(err, r.stop(context.WithoutCancel(ctx), started))
+}
+
+func (r *Runner) start(ctx context.Context, hooks []Hook) ([]Hook, error) {
+	for i, hook := range hooks {
+		if hook.Start == nil {
+			continue
+		}
+
+		r.logger.DebugContext(ctx, "starting", "hook", hook.Name)
+
+		if err := hook.Start(ctx); err != nil {
+			return hooks[:i], fmt.Errorf("lifecycle: start %s: %w",
+				hook.Name, err)
+		}
+	}
+
+	return hooks, nil
+}
+
+func (r *Runner) stop(ctx context.Context, hooks []Hook) error {
+	var failures []error
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.Stop == nil {
+			continue
+		}
+
+		r.logger.DebugContext(ctx, "stopping", "hook", hook.Name)
+
+		if err := r.stopHook(ctx, hook); err != nil {
+			r.logger.ErrorContext(ctx, "stop failed",
+				"hook", hook.Name, "error", err)
+			failures = append(failures,
+				fmt.Errorf("lifecycle: stop %s: %w", hook.Name, err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+func (r *Runner) stopHook(ctx context.Context, hook Hook) error {
+	timeout := hook.StopTimeout
+	if timeout <= 0 {
+		timeout = r.stopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- hook.Stop(ctx)
+	}()
+
+	// A hook ignoring its context must not block the remaining ones.
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,153 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/log"
+)
+
+var errBroken = errors.New("broken")
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) hook(name string) lifecycle.Hook {
+	record := func(event string) func(context.Context) error {
+		return func(context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			r.events = append(r.events, event+" "+name)
+
+			return nil
+		}
+	}
+
+	return lifecycle.Hook{
+		Name:  name,
+		Start: record("start"),
+		Stop:  record("stop"),
+	}
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.events...)
+}
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	runner := lifecycle.New(lifecycle.WithSignals())
+	runner.Append(rec.hook("db"), rec.hook("cache"), rec.hook("http"))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.NoError(t, runner.Run(ctx))
+	assert.Equal(t, []string{
+		"start db", "start cache", "start http",
+		"stop http", "stop cache", "stop db",
+	}, rec.list())
+}
+
+func TestRunStartFailureStopsStarted(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	runner := lifecycle.New(lifecycle.WithSignals())
+	runner.Append(rec.hook("db"), lifecycle.Hook{
+		Name:  "broken",
+		Start: func(context.Context) error { return errBroken },
+		Stop: func(context.Context) error {
+			t.Error("stop called for a hook that failed to start")
+
+			return nil
+		},
+	}, rec.hook("http"))
+
+	err := runner.Run(t.Context())
+	require.ErrorIs(t, err, errBroken)
+	assert.Equal(t, []string{"start db", "stop db"}, rec.list())
+}
+
+func TestShutdownCause(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	runner := lifecycle.New(lifecycle.WithSignals())
+	runner.Append(rec.hook("worker"))
+
+	go runner.Shutdown(errBroken)
+
+	require.ErrorIs(t, runner.Run(t.Context()), errBroken)
+	assert.Equal(t, []string{"start worker", "stop worker"}, rec.list())
+}
+
+func TestShutdownWithoutCause(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	runner := lifecycle.New(
+		lifecycle.WithSignals(),
+		lifecycle.WithLogger(sink.Logger()),
+	)
+	runner.Shutdown(nil)
+
+	require.NoError(t, runner.Run(t.Context()))
+
+	var reason any
+
+	for _, entry := range sink.Entries() {
+		if entry.Message == "shutting down" {
+			reason = entry.Attrs["reason"]
+		}
+	}
+
+	assert.Equal(t, lifecycle.ErrShutdown, reason)
+}
+
+func TestShutdownWrappedCause(t *testing.T) {
+	t.Parallel()
+
+	runner := lifecycle.New(lifecycle.WithSignals())
+	runner.Shutdown(fmt.Errorf("%w: upgrade", lifecycle.ErrShutdown))
+
+	require.NoError(t, runner.Run(t.Context()))
+}
+
+func TestStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	runner := lifecycle.New(
+		lifecycle.WithSignals(),
+		lifecycle.WithStopTimeout(10*time.Millisecond),
+	)
+	runner.Append(rec.hook("first"), lifecycle.Hook{
+		Name: "stuck",
+		Stop: func(context.Context) error {
+			select {}
+		},
+	})
+	runner.Shutdown(nil)
+
+	err := runner.Run(t.Context())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "stop stuck")
+	assert.Equal(t, []string{"start first", "stop first"}, rec.list())
+}
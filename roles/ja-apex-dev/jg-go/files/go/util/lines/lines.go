@@ -0,0 +1,210 @@
+// Package lines processes the lines of a stream one at a time, holding in
+// memory only the current line, or a few chunks of lines when processing
+// concurrently, so that multi-gigabyte logs are handled in constant space:
+//
+//	bar := progress.NewBar(size, progress.WithBytes())
+//	err := lines.ForEachLine(file, func(number int, line []byte) error {
+//		return index(number, line)
+//	}, lines.WithWorkers(8), lines.WithProgress(bar.Set))
+//
+// Lines end with "\n" or "\r\n", never included in the lines, and the last
+// line of the stream may lack its terminator.
+package lines
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// DefaultChunkSize is the number of lines handed to a worker at once.
+const DefaultChunkSize = 1024
+
+// readSize is the size of the read buffer; longer lines are accumulated.
+const readSize = 64 << 10
+
+var (
+	// ErrStop is returned by a Func to stop the processing without error.
+	ErrStop = errors.New("lines: stop")
+	// ErrTooLong is returned for lines longer than the maximum length.
+	ErrTooLong = errors.New("lines: line too long")
+)
+
+// Func processes the line numbered from 1. The line is only valid until
+// the function returns.
+type Func func(number int, line []byte) error
+
+// Option configures ForEachLine.
+type Option func(*settings)
+
+type settings struct {
+	maxLength int
+	workers   int
+	chunkSize int
+	progress  func(read int64)
+}
+
+// WithMaxLength fails with ErrTooLong on lines longer than n bytes, which
+// are otherwise unlimited.
+func WithMaxLength(n int) Option {
+	return func(s *settings) {
+		s.maxLength = n
+	}
+}
+
+// WithWorkers processes the lines with n goroutines, or GOMAXPROCS when n is
+// not positive. Each worker calls the function on a chunk of consecutive
+// lines in order, but chunks are processed in any order: the function must
+// be safe for concurrent use.
+func WithWorkers(n int) Option {
+	return func(s *settings) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+
+		s.workers = n
+	}
+}
+
+// WithChunkSize sets the number of lines per chunk of WithWorkers,
+// DefaultChunkSize by default.
+func WithChunkSize(lines int) Option {
+	return func(s *settings) {
+		s.chunkSize = max(lines, 1)
+	}
+}
+
+// WithProgress calls fn with the number of bytes read so far after every
+// read from the stream, such as to set a progress bar. As reads are
+// buffered, the count runs ahead of the processed lines.
+func WithProgress(fn func(read int64)) Option {
+	return func(s *settings) {
+		s.progress = fn
+	}
+}
+
+// ForEachLine calls fn on every line of r. It stops at the first error of
+// fn, returned as is unless it is ErrStop, or of r, returned with the
+// number of the line.
+func ForEachLine(r io.Reader, fn Func, opts ...Option) error {
+	s := settings{workers: 1, chunkSize: DefaultChunkSize}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.progress != nil {
+		r = &countingReader{r: r, progress: s.progress}
+	}
+
+	lr := &reader{br: bufio.NewReaderSize(r, readSize), max: s.maxLength}
+	if s.workers > 1 {
+		return parallel(lr, fn, s)
+	}
+
+	for {
+		line, err := lr.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(lr.number, line); err != nil {
+			return stopped(err)
+		}
+	}
+}
+
+// stopped returns err, or nil for ErrStop.
+func stopped(err error) error {
+	if errors.Is(err, ErrStop) {
+		return nil
+	}
+
+	return err
+}
+
+// reader splits a stream into lines.
+type reader struct {
+	br     *bufio.Reader
+	max    int
+	number int
+	// long accumulates the lines longer than the read buffer.
+	long []byte
+}
+
+// next returns the next line, valid until the next call, or io.EOF.
+func (r *reader) next() ([]byte, error) {
+	r.number++
+	r.long = r.long[:0]
+
+	for {
+		chunk, err := r.br.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			// Leave room for the terminator, checked by end.
+			r.long = append(r.long, chunk...)
+			if r.max > 0 && len(r.long) > r.max+len("\r\n") {
+				return nil, r.fail(ErrTooLong)
+			}
+
+			continue
+		}
+
+		line := chunk
+		if len(r.long) > 0 {
+			r.long = append(r.long, chunk...)
+			line = r.long
+		}
+
+		return r.end(line, err)
+	}
+}
+
+// end checks the line read until err and removes its terminator.
+func (r *reader) end(line []byte, err error) ([]byte, error) {
+	switch {
+	case errors.Is(err, io.EOF) && len(line) == 0:
+		return nil, io.EOF
+	case err != nil && !errors.Is(err, io.EOF):
+		return nil, r.fail(err)
+	}
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+
+	if r.max > 0 && len(line) > r.max {
+		return nil, r.fail(ErrTooLong)
+	}
+
+	return line, nil
+}
+
+func (r *reader) fail(err error) error {
+	if errors.Is(err, ErrTooLong) {
+		return fmt.Errorf("%w (line %d)", err, r.number)
+	}
+
+	return fmt.Errorf("lines: line %d: %w", r.number, err)
+}
+
+// countingReader reports the bytes read from r.
+type countingReader struct {
+	r        io.Reader
+	read     int64
+	progress func(read int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.read += int64(n)
+		c.progress(c.read)
+	}
+
+	return n, err
+}
@@ -0,0 +1,183 @@
+package lines_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/lines"
+)
+
+// collect returns the lines of input by number.
+func collect(t *testing.T, r io.Reader, opts ...lines.Option) []string {
+	t.Helper()
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+
+	err := lines.ForEachLine(r, func(number int, line []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if number > len(got) {
+			got = append(got, make([]string, number-len(got))...)
+		}
+
+		got[number-1] = string(line)
+
+		return nil
+	}, opts...)
+	require.NoError(t, err)
+
+	return got
+}
+
+func TestForEachLine(t *testing.T) {
+	t.Parallel()
+
+	input := "first\r\nsecond\n\n\rfourth\r\nlast"
+	want := []string{"first", "second", "", "\rfourth", "last"}
+
+	assert.Equal(t, want, collect(t, strings.NewReader(input)))
+	assert.Equal(t, want, collect(t,
+		iotest.OneByteReader(strings.NewReader(input))))
+	assert.Equal(t, want[:4], collect(t, strings.NewReader(input[:24])),
+		"a trailing newline ends the last line")
+	assert.Empty(t, collect(t, strings.NewReader("")))
+}
+
+func TestForEachLineLong(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("0123456789", 100_000)
+	input := "short\n" + long + "\r\n" + long
+
+	assert.Equal(t, []string{"short", long, long},
+		collect(t, strings.NewReader(input)))
+
+	err := lines.ForEachLine(strings.NewReader(input),
+		func(int, []byte) error { return nil },
+		lines.WithMaxLength(len(long)-1))
+	require.ErrorIs(t, err, lines.ErrTooLong)
+	assert.EqualError(t, err, "lines: line too long (line 2)")
+
+	err = lines.ForEachLine(strings.NewReader("ab\nabc\n"),
+		func(int, []byte) error { return nil }, lines.WithMaxLength(2))
+	assert.EqualError(t, err, "lines: line too long (line 2)")
+}
+
+func TestForEachLineErrors(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("bad record")
+	input := "a\nb\nc\n"
+
+	var seen []int
+
+	err := lines.ForEachLine(strings.NewReader(input),
+		func(number int, _ []byte) error {
+			seen = append(seen, number)
+			if number == 2 {
+				return failure
+			}
+
+			return nil
+		})
+	require.ErrorIs(t, err, failure)
+	assert.Equal(t, []int{1, 2}, seen)
+
+	err = lines.ForEachLine(strings.NewReader(input),
+		func(number int, _ []byte) error {
+			if number == 2 {
+				return lines.ErrStop
+			}
+
+			return nil
+		})
+	require.NoError(t, err)
+
+	broken := io.MultiReader(strings.NewReader(input),
+		iotest.ErrReader(io.ErrUnexpectedEOF))
+	err = lines.ForEachLine(broken, func(int, []byte) error { return nil })
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.EqualError(t, err, "lines: line 4: unexpected EOF")
+}
+
+func TestForEachLineProgress(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("line\n", 1000)
+
+	var reads []int64
+
+	err := lines.ForEachLine(iotest.HalfReader(strings.NewReader(input)),
+		func(int, []byte) error { return nil },
+		lines.WithProgress(func(read int64) { reads = append(reads, read) }))
+	require.NoError(t, err)
+
+	require.NotEmpty(t, reads)
+	assert.True(t, slices.IsSorted(reads))
+	assert.Equal(t, int64(len(input)), reads[len(reads)-1])
+}
+
+func TestForEachLineWorkers(t *testing.T) {
+	t.Parallel()
+
+	var builder strings.Builder
+	for i := range 10_000 {
+		fmt.Fprintf(&builder, "%d\r\n", i+1)
+	}
+
+	got := collect(t, strings.NewReader(builder.String()),
+		lines.WithWorkers(4), lines.WithChunkSize(7))
+	require.Len(t, got, 10_000)
+
+	for i, line := range got {
+		assert.Equal(t, fmt.Sprint(i+1), line)
+	}
+
+	assert.Equal(t, []string{"a", "b"},
+		collect(t, strings.NewReader("a\nb"), lines.WithWorkers(0)))
+}
+
+func TestForEachLineWorkersErrors(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("x\n", 100_000)
+	failure := errors.New("bad record")
+
+	var calls atomic.Int64
+
+	err := lines.ForEachLine(strings.NewReader(input),
+		func(number int, _ []byte) error {
+			calls.Add(1)
+			if number == 500 {
+				return failure
+			}
+
+			return nil
+		}, lines.WithWorkers(4), lines.WithChunkSize(100))
+	require.ErrorIs(t, err, failure)
+	assert.Less(t, calls.Load(), int64(100_000), "workers stop early")
+
+	err = lines.ForEachLine(strings.NewReader(input),
+		func(int, []byte) error { return lines.ErrStop },
+		lines.WithWorkers(4))
+	require.NoError(t, err)
+
+	err = lines.ForEachLine(strings.NewReader("ok\n"+strings.Repeat("x", 9)),
+		func(int, []byte) error { return nil },
+		lines.WithWorkers(2), lines.WithMaxLength(8))
+	require.ErrorIs(t, err, lines.ErrTooLong)
+}
@@ -0,0 +1,139 @@
+package lines
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// chunk holds consecutive lines, copied out of the read buffer.
+type chunk struct {
+	first int
+	data  []byte
+	ends  []int
+}
+
+func newChunk(first, lines, bytes int) *chunk {
+	return &chunk{
+		first: first,
+		data:  make([]byte, 0, bytes),
+		ends:  make([]int, 0, lines),
+	}
+}
+
+// each calls fn on the lines of the chunk in order.
+func (c *chunk) each(fn Func) error {
+	start := 0
+
+	for i, end := range c.ends {
+		if err := fn(c.first+i, c.data[start:end]); err != nil {
+			return err
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// group holds the first error of the workers, stopping the others.
+type group struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// fail records err unless an error was recorded before.
+func (g *group) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		close(g.done)
+	})
+}
+
+// stopped reports whether a worker failed.
+func (g *group) stopped() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// work processes chunks until the channel is closed, only draining it once
+// a worker failed.
+func (g *group) work(chunks <-chan *chunk, fn Func) {
+	for c := range chunks {
+		if g.stopped() {
+			continue
+		}
+
+		if err := c.each(fn); err != nil {
+			g.fail(err)
+		}
+	}
+}
+
+// parallel reads chunks of lines processed by workers.
+func parallel(lr *reader, fn Func, s settings) error {
+	chunks := make(chan *chunk, s.workers)
+	g := &group{done: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	for range s.workers {
+		wg.Go(func() { g.work(chunks, fn) })
+	}
+
+	err := split(lr, chunks, g, s.chunkSize)
+	close(chunks)
+	wg.Wait()
+
+	if g.err != nil {
+		return stopped(g.err)
+	}
+
+	return err
+}
+
+// split sends the lines of lr to chunks in groups of size, until the end of
+// the stream or the failure of a worker.
+func split(lr *reader, chunks chan<- *chunk, g *group, size int) error {
+	c := newChunk(1, size, 0)
+
+	for !g.stopped() {
+		line, err := lr.next()
+		if err != nil {
+			if len(c.ends) > 0 {
+				chunks <- c
+			}
+
+			return ignoreEOF(err)
+		}
+
+		c.data = append(c.data, line...)
+		c.ends = append(c.ends, len(c.data))
+
+		if len(c.ends) < size {
+			continue
+		}
+
+		select {
+		case chunks <- c:
+		case <-g.done:
+			return nil
+		}
+
+		c = newChunk(lr.number+1, size, cap(c.data))
+	}
+
+	return nil
+}
+
+func ignoreEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
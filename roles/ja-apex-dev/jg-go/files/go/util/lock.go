@@ -0,0 +1,321 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLockPoll is the interval between attempts of Lockfile.Lock.
+const DefaultLockPoll = 100 * time.Millisecond
+
+// lockTokenSize is the number of random bytes identifying a lock.
+const lockTokenSize = 16
+
+var (
+	// ErrLocked is returned when another owner holds the lock.
+	ErrLocked = errors.New("util: file locked")
+	// ErrLockLost is returned by Unlock when the lock file was broken, or
+	// replaced, by another owner.
+	ErrLockLost = errors.New("util: lock lost")
+
+	// errBadLock is returned for lock files that do not decode.
+	errBadLock = errors.New("util: malformed lock file")
+)
+
+// LockOwner describes the holder of a lock, as recorded in the lock file.
+type LockOwner struct {
+	PID  int    `json:"pid"`
+	Host string `json:"host"`
+	// BootTime is the boot time of the host in seconds since the epoch,
+	// zero when unknown, telling apart the PIDs reused after a reboot.
+	BootTime int64     `json:"bootTime,omitempty"`
+	Acquired time.Time `json:"acquired"`
+	Token    string    `json:"token"`
+}
+
+// stale reports whether the owner is a process of this host that is gone.
+// The owners of other hosts cannot be checked and are never stale.
+func (o LockOwner) stale(host string, boot int64) bool {
+	if o.Host != host {
+		return false
+	}
+
+	if o.BootTime != 0 && boot != 0 && o.BootTime != boot {
+		return true
+	}
+
+	return !processAlive(o.PID)
+}
+
+// LockOption configures a Lockfile.
+type LockOption func(*Lockfile)
+
+// WithLockPoll sets the interval between attempts of Lock, DefaultLockPoll
+// by default.
+func WithLockPoll(d time.Duration) LockOption {
+	return func(l *Lockfile) {
+		l.poll = d
+	}
+}
+
+// Lockfile is an advisory lock between processes, held as long as its
+// file exists. The file records the PID and boot time of its owner, so
+// that the lock of a process that died without unlocking is broken by the
+// next attempt on the same host. A Lockfile is safe for concurrent use,
+// but does not exclude the goroutines sharing it.
+type Lockfile struct {
+	path string
+	poll time.Duration
+
+	mu    sync.Mutex
+	token string
+}
+
+// FileLock returns the lock held by the file path, typically next to the
+// state it protects, such as "state.json.lock".
+func FileLock(path string, opts ...LockOption) *Lockfile {
+	l := &Lockfile{path: path, poll: DefaultLockPoll}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Path returns the path of the lock file.
+func (l *Lockfile) Path() string {
+	return l.path
+}
+
+// Lock acquires the lock, retrying until ctx is done. The error then wraps
+// both ErrLocked and the cause of ctx.
+func (l *Lockfile) Lock(ctx context.Context) error {
+	ticker := time.NewTicker(l.poll)
+	defer ticker.Stop()
+
+	for {
+		err := l.TryLock()
+		if !errors.Is(err, ErrLocked) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", err, context.Cause(ctx))
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryLock acquires the lock, breaking it when stale, or fails with
+// ErrLocked at once. Locking a held Lockfile does nothing.
+func (l *Lockfile) TryLock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token != "" {
+		return nil
+	}
+
+	owner, err := currentOwner()
+	if err != nil {
+		return err
+	}
+
+	// A second attempt follows the removal of a stale lock.
+	for range 2 {
+		err = createLock(l.path, owner)
+		if !errors.Is(err, fs.ErrExist) {
+			break
+		}
+
+		if err = breakStale(l.path, owner); err != nil {
+			return err
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	l.token = owner.Token
+
+	return nil
+}
+
+// Unlock releases the lock. It fails with ErrLockLost if the lock file no
+// longer belongs to l, and does nothing if l is not locked.
+func (l *Lockfile) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token == "" {
+		return nil
+	}
+
+	token := l.token
+	l.token = ""
+
+	owner, err := readLock(l.path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist), err == nil && owner.Token != token:
+		return fmt.Errorf("%w: %s", ErrLockLost, l.path)
+	case err != nil:
+		return err
+	}
+
+	if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	return nil
+}
+
+// Owner returns the current holder of the lock.
+func (l *Lockfile) Owner() (LockOwner, error) {
+	return readLock(l.path)
+}
+
+// currentOwner describes this process as a new owner.
+func currentOwner() (LockOwner, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return LockOwner{}, fmt.Errorf("util: %w", err)
+	}
+
+	return LockOwner{
+		PID:      os.Getpid(),
+		Host:     host,
+		BootTime: bootTime(),
+		Acquired: time.Now().UTC(),
+		Token:    SecureToken(lockTokenSize),
+	}, nil
+}
+
+// createLock links a complete lock file into place, so that readers never
+// observe a partial one, failing with fs.ErrExist when a lock is held.
+func createLock(path string, owner LockOwner) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = json.NewEncoder(tmp).Encode(owner)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	if err := os.Link(tmp.Name(), path); err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	return nil
+}
+
+// breakStale removes the lock file at path if its owner is gone, and
+// fails with ErrLocked otherwise.
+func breakStale(path string, self LockOwner) error {
+	owner, err := readLock(path)
+
+	// Locks are linked complete, so a malformed file is broken as well.
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil && !errors.Is(err, errBadLock):
+		return err
+	case err == nil && !owner.stale(self.Host, self.BootTime):
+		return fmt.Errorf("%w: %s held by pid %d on %s", ErrLocked, path,
+			owner.PID, owner.Host)
+	}
+
+	// Move the file aside first: if another process broke the lock and
+	// acquired it meanwhile, its fresh lock is put back.
+	aside := path + ".stale-" + self.Token
+	if err := os.Rename(path, aside); err != nil {
+		return ignoreNotExist(err)
+	}
+	defer os.Remove(aside)
+
+	moved, err := readLock(aside)
+	if err == nil && moved.Token != owner.Token {
+		return ignoreExist(os.Link(aside, path))
+	}
+
+	return nil
+}
+
+// readLock decodes the lock file at path.
+func readLock(path string) (LockOwner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LockOwner{}, fmt.Errorf("util: %w", err)
+	}
+
+	var owner LockOwner
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return LockOwner{}, fmt.Errorf("%w %s: %w", errBadLock, path, err)
+	}
+
+	return owner, nil
+}
+
+func ignoreNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return fmt.Errorf("util: %w", err)
+}
+
+func ignoreExist(err error) error {
+	if err == nil || errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+
+	return fmt.Errorf("util: %w", err)
+}
+
+// bootTime returns the boot time of the host in seconds since the epoch,
+// read from /proc/stat, or zero when unknown.
+func bootTime() int64 {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "btime ")
+		if !ok {
+			continue
+		}
+
+		boot, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+
+		return boot
+	}
+
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package util
+
+import "os"
+
+// processAlive reports whether the process pid exists. Without signal 0,
+// finding the process is the only check available.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	_ = process.Release()
+
+	return true
+}
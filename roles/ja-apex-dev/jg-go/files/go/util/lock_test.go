@@ -0,0 +1,190 @@
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+// deadPID exceeds the largest PID of Linux and of the BSDs.
+const deadPID = 1 << 30
+
+// writeLock writes a lock file held by owner.
+func writeLock(t *testing.T, path string, owner util.LockOwner) {
+	t.Helper()
+
+	data, err := json.Marshal(owner)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestFileLock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.lock")
+	first := util.FileLock(path)
+	second := util.FileLock(path)
+
+	require.NoError(t, first.TryLock())
+	require.NoError(t, first.TryLock(), "already held")
+
+	err := second.TryLock()
+	require.ErrorIs(t, err, util.ErrLocked)
+	assert.Contains(t, err.Error(), "held by pid")
+
+	owner, err := second.Owner()
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), owner.PID)
+	assert.NotEmpty(t, owner.Token)
+
+	require.NoError(t, first.Unlock())
+	require.NoError(t, first.Unlock(), "not held")
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, second.TryLock())
+	require.NoError(t, second.Unlock())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no temporary file is left")
+}
+
+func TestFileLockWait(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.lock")
+	holder := util.FileLock(path)
+	require.NoError(t, holder.TryLock())
+
+	waiter := util.FileLock(path, util.WithLockPoll(time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	err := waiter.Lock(ctx)
+	require.ErrorIs(t, err, util.ErrLocked)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	time.AfterFunc(10*time.Millisecond, func() { _ = holder.Unlock() })
+	require.NoError(t, waiter.Lock(t.Context()))
+	require.NoError(t, waiter.Unlock())
+}
+
+func TestFileLockStale(t *testing.T) {
+	t.Parallel()
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	dead := filepath.Join(dir, "dead.lock")
+	writeLock(t, dead, util.LockOwner{PID: deadPID, Host: host, Token: "dead"})
+
+	malformed := filepath.Join(dir, "malformed.lock")
+	require.NoError(t, os.WriteFile(malformed, []byte("{"), 0o600))
+
+	for _, path := range []string{dead, malformed} {
+		lock := util.FileLock(path)
+		require.NoError(t, lock.TryLock(), path)
+
+		current, err := lock.Owner()
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), current.PID)
+		require.NoError(t, lock.Unlock())
+	}
+
+	foreign := filepath.Join(dir, "foreign.lock")
+	writeLock(t, foreign, util.LockOwner{PID: deadPID, Host: "elsewhere"})
+	require.ErrorIs(t, util.FileLock(foreign).TryLock(), util.ErrLocked,
+		"the processes of other hosts are not checked")
+
+	alive := filepath.Join(dir, "alive.lock")
+	writeLock(t, alive, util.LockOwner{PID: os.Getpid(), Host: host})
+	require.ErrorIs(t, util.FileLock(alive).TryLock(), util.ErrLocked)
+}
+
+func TestFileLockReboot(t *testing.T) {
+	t.Parallel()
+
+	if _, err := os.Stat("/proc/stat"); err != nil {
+		t.Skip("boot time not available")
+	}
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "state.lock")
+	writeLock(t, path, util.LockOwner{
+		PID: os.Getpid(), Host: host, BootTime: 1, Token: "before",
+	})
+
+	lock := util.FileLock(path)
+	require.NoError(t, lock.TryLock(), "a PID of a previous boot is stale")
+	require.NoError(t, lock.Unlock())
+}
+
+func TestFileLockLost(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.lock")
+	lock := util.FileLock(path)
+	require.NoError(t, lock.TryLock())
+
+	writeLock(t, path, util.LockOwner{PID: 1, Token: "other"})
+	require.ErrorIs(t, lock.Unlock(), util.ErrLockLost)
+	assert.FileExists(t, path, "the lock of the other owner is kept")
+
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, lock.TryLock())
+	require.NoError(t, os.Remove(path))
+	require.ErrorIs(t, lock.Unlock(), util.ErrLockLost)
+}
+
+func TestFileLockExclusion(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	var (
+		wg       sync.WaitGroup
+		holders  atomic.Int32
+		overlaps atomic.Int32
+	)
+
+	// hold takes the lock with a lock of its own and counts the overlaps.
+	hold := func(lock *util.Lockfile) {
+		if !assert.NoError(t, lock.Lock(t.Context())) {
+			return
+		}
+		defer func() { assert.NoError(t, lock.Unlock()) }()
+
+		if holders.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+
+		holders.Add(-1)
+	}
+
+	for range 8 {
+		wg.Go(func() {
+			lock := util.FileLock(path, util.WithLockPoll(time.Millisecond))
+			for range 10 {
+				hold(lock)
+			}
+		})
+	}
+
+	wg.Wait()
+	assert.Zero(t, overlaps.Load())
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package util
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether the process pid exists, including when it
+// belongs to another user.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(pid, 0)
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
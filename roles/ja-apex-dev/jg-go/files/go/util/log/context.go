@@ -0,0 +1,84 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+type attrsKey struct{}
+
+type loggerKey struct{}
+
+// WithAttrs returns a context carrying attrs in addition to the attributes
+// already stored in ctx. Loggers created by New add them to every record.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := Attrs(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// Attrs returns the attributes stored in ctx by WithAttrs.
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+
+	return attrs
+}
+
+// IntoContext stores the logger in ctx.
+func IntoContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored by IntoContext or slog.Default.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
+
+// ContextHandler decorates a handler with the attributes stored in the
+// context of every record.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler wraps next, unless it is already a ContextHandler.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	if handler, ok := next.(*ContextHandler); ok {
+		return handler
+	}
+
+	return &ContextHandler{next: next}
+}
+
+// Enabled implements slog.Handler.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+//
+//nolint:gocritic // Signature is mandated by slog.Handler.
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
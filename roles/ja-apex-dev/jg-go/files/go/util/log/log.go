@@ -0,0 +1,120 @@
+// Package log is a thin facade over log/slog with JSON or text output, level
+// configuration from the environment, context-scoped attributes and a sink
+// for asserting log output in tests.
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"example.com/go-template/util/redact"
+)
+
+// Supported output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Environment variables read by FromEnv.
+const (
+	EnvLevel  = "LOG_LEVEL"
+	EnvFormat = "LOG_FORMAT"
+)
+
+var (
+	// ErrUnknownLevel is returned by ParseLevel for unknown level names.
+	ErrUnknownLevel = errors.New("log: unknown level")
+	// ErrUnknownFormat is returned for formats other than text and json.
+	ErrUnknownFormat = errors.New("log: unknown format")
+)
+
+// Options configures New.
+type Options struct {
+	// Output defaults to os.Stderr.
+	Output io.Writer
+	// Format is FormatText (default) or FormatJSON.
+	Format string
+	// Level is the minimum enabled level, slog.LevelInfo by default.
+	Level slog.Leveler
+	// AddSource includes the source file and line of every record.
+	AddSource bool
+	// Redactor masks secrets in every record, including the attributes
+	// stored in the context, when set.
+	Redactor *redact.Redactor
+}
+
+// New creates a logger writing to the configured output. Attributes stored
+// in the context with WithAttrs are added to every record.
+func New(opts Options) (*slog.Logger, error) {
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:     opts.Level,
+		AddSource: opts.AddSource,
+	}
+
+	var handler slog.Handler
+
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(output, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+	}
+
+	if opts.Redactor != nil {
+		handler = redact.NewHandler(handler, opts.Redactor)
+	}
+
+	return slog.New(NewContextHandler(handler)), nil
+}
+
+// FromEnv creates a logger configured by LOG_LEVEL and LOG_FORMAT, with
+// opts providing the remaining settings and fallbacks.
+func FromEnv(opts Options) (*slog.Logger, error) {
+	if value, ok := os.LookupEnv(EnvLevel); ok {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Level = level
+	}
+
+	if value, ok := os.LookupEnv(EnvFormat); ok {
+		opts.Format = value
+	}
+
+	return New(opts)
+}
+
+// ParseLevel parses level names (debug, info, warn, warning, error) as well
+// as slog offsets such as "info+2".
+func ParseLevel(value string) (slog.Level, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if normalized == "warning" {
+		normalized = "warn"
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(normalized)); err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, value)
+	}
+
+	return level, nil
+}
+
+// Discard returns a logger that drops every record.
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// Entry is a record captured by a Sink. Attribute keys of groups are joined
+// with dots.
+type Entry struct {
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+type sinkState struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Sink is a slog.Handler recording every record in memory, intended for
+// asserting log output in tests.
+type Sink struct {
+	state  *sinkState
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewSink creates an empty sink.
+func NewSink() *Sink {
+	return &Sink{state: &sinkState{}}
+}
+
+// Logger returns a logger writing into the sink, including the context
+// attributes stored with WithAttrs.
+func (s *Sink) Logger() *slog.Logger {
+	return slog.New(NewContextHandler(s))
+}
+
+// Entries returns a copy of the captured entries.
+func (s *Sink) Entries() []Entry {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	return slices.Clone(s.state.entries)
+}
+
+// Messages returns the messages of the captured entries.
+func (s *Sink) Messages() []string {
+	entries := s.Entries()
+	messages := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+
+	return messages
+}
+
+// Reset drops the captured entries.
+func (s *Sink) Reset() {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	s.state.entries = nil
+}
+
+// Enabled implements slog.Handler, accepting every level.
+func (*Sink) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler.
+//
+//nolint:gocritic // Signature is mandated by slog.Handler.
+func (s *Sink) Handle(_ context.Context, record slog.Record) error {
+	attrs := map[string]any{}
+	prefix := joinGroups(s.groups)
+
+	for _, attr := range s.attrs {
+		flatten(attrs, "", attr)
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		flatten(attrs, prefix, attr)
+
+		return true
+	})
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	s.state.entries = append(s.state.entries, Entry{
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (s *Sink) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := joinGroups(s.groups)
+	scoped := make([]slog.Attr, 0, len(s.attrs)+len(attrs))
+	scoped = append(scoped, s.attrs...)
+
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr.Key = prefix + "." + attr.Key
+		}
+
+		scoped = append(scoped, attr)
+	}
+
+	return &Sink{state: s.state, attrs: scoped, groups: s.groups}
+}
+
+// WithGroup implements slog.Handler.
+func (s *Sink) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return s
+	}
+
+	return &Sink{
+		state:  s.state,
+		attrs:  s.attrs,
+		groups: append(slices.Clip(s.groups), name),
+	}
+}
+
+func joinGroups(groups []string) string {
+	prefix := ""
+	for _, group := range groups {
+		prefix = joinKey(prefix, group)
+	}
+
+	return prefix
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+func flatten(dst map[string]any, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		dst[joinKey(prefix, attr.Key)] = value.Any()
+
+		return
+	}
+
+	// Groups with an empty key are inlined, as in the slog handlers.
+	if attr.Key != "" {
+		prefix = joinKey(prefix, attr.Key)
+	}
+
+	for _, nested := range value.Group() {
+		flatten(dst, prefix, nested)
+	}
+}
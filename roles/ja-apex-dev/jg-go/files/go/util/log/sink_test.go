@@ -0,0 +1,66 @@
+package log_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/log"
+)
+
+func TestSinkCapturesAttrs(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	logger := sink.Logger().With("component", "api").WithGroup("req")
+
+	ctx := log.WithAttrs(t.Context(), slog.String("request_id", "r-1"))
+	logger.InfoContext(ctx, "handled", "status", 200,
+		slog.Group("user", "id", 7))
+
+	entries := sink.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, slog.LevelInfo, entries[0].Level)
+	assert.Equal(t, "handled", entries[0].Message)
+	assert.Equal(t, map[string]any{
+		"component":      "api",
+		"req.status":     int64(200),
+		"req.user.id":    int64(7),
+		"req.request_id": "r-1",
+	}, entries[0].Attrs)
+}
+
+func TestSinkReset(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	sink.Logger().Warn("one")
+	sink.Logger().Error("two")
+	assert.Equal(t, []string{"one", "two"}, sink.Messages())
+
+	sink.Reset()
+	assert.Empty(t, sink.Entries())
+}
+
+func TestContextLogger(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	ctx := log.IntoContext(t.Context(), sink.Logger())
+
+	log.FromContext(ctx).Info("from context")
+	assert.Equal(t, []string{"from context"}, sink.Messages())
+	assert.Equal(t, slog.Default(), log.FromContext(context.Background()))
+}
+
+func TestWithAttrsAccumulates(t *testing.T) {
+	t.Parallel()
+
+	ctx := log.WithAttrs(t.Context(), slog.Int("a", 1))
+	ctx = log.WithAttrs(ctx, slog.Int("b", 2))
+
+	assert.Len(t, log.Attrs(ctx), 2)
+}
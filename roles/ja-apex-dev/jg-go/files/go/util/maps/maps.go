@@ -0,0 +1,103 @@
+// Package maps provides generic helpers complementing the standard library
+// maps package, returning slices instead of iterators.
+package maps
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ConflictFunc resolves a key present in several merged maps, receiving the
+// accumulated value and the incoming one.
+type ConflictFunc[K comparable, V any] func(key K, current, incoming V) V
+
+// KeepFirst is a ConflictFunc keeping the value seen first.
+func KeepFirst[K comparable, V any](_ K, current, _ V) V {
+	return current
+}
+
+// KeepLast is a ConflictFunc keeping the value seen last.
+func KeepLast[K comparable, V any](_ K, _, incoming V) V {
+	return incoming
+}
+
+// Keys returns the keys of m in unspecified order.
+func Keys[M ~map[K]V, K comparable, V any](m M) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// SortedKeys returns the keys of m in ascending order.
+func SortedKeys[M ~map[K]V, K cmp.Ordered, V any](m M) []K {
+	keys := Keys(m)
+	slices.Sort(keys)
+
+	return keys
+}
+
+// Values returns the values of m in unspecified order.
+func Values[M ~map[K]V, K comparable, V any](m M) []V {
+	values := make([]V, 0, len(m))
+	for _, value := range m {
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// Invert swaps keys and values. When several keys share a value, the
+// surviving key is unspecified.
+func Invert[M ~map[K]V, K, V comparable](m M) map[V]K {
+	result := make(map[V]K, len(m))
+	for key, value := range m {
+		result[value] = key
+	}
+
+	return result
+}
+
+// Merge combines the maps into a new one, calling resolve for every key
+// found in more than one map. A nil resolve behaves like KeepLast.
+func Merge[M ~map[K]V, K comparable, V any](
+	resolve ConflictFunc[K, V], maps ...M,
+) M {
+	if resolve == nil {
+		resolve = KeepLast[K, V]
+	}
+
+	size := 0
+	for _, m := range maps {
+		size = max(size, len(m))
+	}
+
+	result := make(M, size)
+
+	for _, m := range maps {
+		for key, value := range m {
+			if current, ok := result[key]; ok {
+				value = resolve(key, current, value)
+			}
+
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// FilterKeys returns a new map with the entries whose key satisfies keep.
+func FilterKeys[M ~map[K]V, K comparable, V any](m M, keep func(K) bool) M {
+	result := make(M)
+
+	for key, value := range m {
+		if keep(key) {
+			result[key] = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,55 @@
+package maps_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/maps"
+)
+
+func TestKeysValues(t *testing.T) {
+	t.Parallel()
+
+	m := map[string]int{"b": 2, "a": 1, "c": 3}
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, maps.Keys(m))
+	assert.ElementsMatch(t, []int{1, 2, 3}, maps.Values(m))
+	assert.Equal(t, []string{"a", "b", "c"}, maps.SortedKeys(m))
+	assert.Empty(t, maps.SortedKeys(map[int]bool(nil)))
+}
+
+func TestInvert(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, map[int]string{1: "a", 2: "b"},
+		maps.Invert(map[string]int{"a": 1, "b": 2}))
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	base := map[string]int{"a": 1, "b": 2}
+	override := map[string]int{"b": 20, "c": 30}
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 20, "c": 30},
+		maps.Merge(nil, base, override))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 30},
+		maps.Merge(maps.KeepFirst, base, override))
+
+	sum := func(_ string, current, incoming int) int {
+		return current + incoming
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 22, "c": 30},
+		maps.Merge(sum, base, override))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, base)
+}
+
+func TestFilterKeys(t *testing.T) {
+	t.Parallel()
+
+	m := map[string]int{"app.port": 1, "app.host": 2, "db.dsn": 3}
+	assert.Equal(t, map[string]int{"app.port": 1, "app.host": 2},
+		maps.FilterKeys(m, func(k string) bool {
+			return strings.HasPrefix(k, "app.")
+		}))
+}
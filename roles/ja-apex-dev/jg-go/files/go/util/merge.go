@@ -0,0 +1,185 @@
+package util
+
+import (
+	"reflect"
+)
+
+// ListStrategy selects how DeepMerge combines two lists.
+type ListStrategy int
+
+// List strategies.
+const (
+	// ListReplace replaces the destination list with the source one.
+	ListReplace ListStrategy = iota
+	// ListAppend appends the source items to the destination list.
+	ListAppend
+	// ListMergeByKey deep merges the map items sharing the value of the
+	// merge key and appends the other source items.
+	ListMergeByKey
+)
+
+// DefaultMergeKey identifies list items for ListMergeByKey.
+const DefaultMergeKey = "name"
+
+// MergeOption configures DeepMerge.
+type MergeOption func(*mergeSettings)
+
+type mergeSettings struct {
+	lists       ListStrategy
+	paths       map[string]ListStrategy
+	key         string
+	nullDeletes bool
+}
+
+// WithListStrategy sets the strategy for every list, ListReplace by
+// default.
+func WithListStrategy(strategy ListStrategy) MergeOption {
+	return func(s *mergeSettings) {
+		s.lists = strategy
+	}
+}
+
+// WithPathListStrategy sets the strategy for the list at path, whose keys
+// are joined with dots, such as "spec.containers". Lists inside merged
+// items extend the path of their parent list: "spec.containers.ports".
+func WithPathListStrategy(path string, strategy ListStrategy) MergeOption {
+	return func(s *mergeSettings) {
+		if s.paths == nil {
+			s.paths = make(map[string]ListStrategy)
+		}
+
+		s.paths[path] = strategy
+	}
+}
+
+// WithMergeKey sets the key identifying list items for ListMergeByKey,
+// DefaultMergeKey by default.
+func WithMergeKey(key string) MergeOption {
+	return func(s *mergeSettings) {
+		s.key = key
+	}
+}
+
+// WithNullDeletes makes null source values delete the destination key
+// instead of setting it to nil, so that a layer can remove a default.
+func WithNullDeletes() MergeOption {
+	return func(s *mergeSettings) {
+		s.nullDeletes = true
+	}
+}
+
+// DeepMerge merges the src tree into dst and returns dst, allocating it
+// when nil. Nested maps are merged recursively, lists follow the
+// configured strategy and other source values replace destination ones.
+// Values taken from src are deep copied, so later changes to src do not
+// affect the result. Trees are made of map[string]any and []any, as
+// decoded from JSON or YAML.
+func DeepMerge(
+	dst, src map[string]any, opts ...MergeOption,
+) map[string]any {
+	s := mergeSettings{key: DefaultMergeKey}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+
+	s.mergeMaps(dst, src, "")
+
+	return dst
+}
+
+func (s *mergeSettings) mergeMaps(dst, src map[string]any, path string) {
+	for key, value := range src {
+		if value == nil && s.nullDeletes {
+			delete(dst, key)
+
+			continue
+		}
+
+		dst[key] = s.mergeValues(dst[key], value, joinPath(path, key))
+	}
+}
+
+// mergeValues returns the combination of the values found at path.
+func (s *mergeSettings) mergeValues(dst, src any, path string) any {
+	switch src := src.(type) {
+	case map[string]any:
+		if dst, ok := dst.(map[string]any); ok {
+			s.mergeMaps(dst, src, path)
+
+			return dst
+		}
+	case []any:
+		if dst, ok := dst.([]any); ok {
+			return s.mergeLists(dst, src, path)
+		}
+	}
+
+	return DeepCopy(src)
+}
+
+func (s *mergeSettings) mergeLists(dst, src []any, path string) []any {
+	strategy, ok := s.paths[path]
+	if !ok {
+		strategy = s.lists
+	}
+
+	switch strategy {
+	case ListAppend:
+		return append(dst, DeepCopy(src)...)
+	case ListMergeByKey:
+		return s.mergeByKey(dst, src, path)
+	default:
+		return DeepCopy(src)
+	}
+}
+
+// mergeByKey merges the source map items into the destination items with
+// the same key, appending the unmatched ones.
+func (s *mergeSettings) mergeByKey(dst, src []any, path string) []any {
+	index := make(map[any]map[string]any)
+
+	for _, item := range dst {
+		if item, ok := item.(map[string]any); ok && isComparable(item[s.key]) {
+			index[item[s.key]] = item
+		}
+	}
+
+	for _, item := range src {
+		incoming, ok := item.(map[string]any)
+		if !ok || !isComparable(incoming[s.key]) {
+			dst = append(dst, DeepCopy(item))
+
+			continue
+		}
+
+		if current, ok := index[incoming[s.key]]; ok {
+			s.mergeMaps(current, incoming, path)
+
+			continue
+		}
+
+		copied := DeepCopy(incoming)
+		index[incoming[s.key]] = copied
+		dst = append(dst, copied)
+	}
+
+	return dst
+}
+
+// isComparable reports whether a key value can index a map. Missing keys
+// do not identify items.
+func isComparable(value any) bool {
+	return value != nil && reflect.ValueOf(value).Comparable()
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
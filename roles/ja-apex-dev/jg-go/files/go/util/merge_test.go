@@ -0,0 +1,103 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util"
+)
+
+func defaults() map[string]any {
+	return map[string]any{
+		"name": "app",
+		"http": map[string]any{"port": 8080, "timeout": "5s"},
+		"tags": []any{"base"},
+		"users": []any{
+			map[string]any{"name": "admin", "shell": "bash"},
+			map[string]any{"name": "guest", "shell": "sh"},
+		},
+		"debug": false,
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	t.Parallel()
+
+	got := util.DeepMerge(defaults(), map[string]any{
+		"http":  map[string]any{"port": 9090},
+		"tags":  []any{"prod"},
+		"debug": nil,
+		"extra": map[string]any{"a": 1},
+	})
+
+	assert.Equal(t, map[string]any{
+		"name":  "app",
+		"http":  map[string]any{"port": 9090, "timeout": "5s"},
+		"tags":  []any{"prod"},
+		"users": defaults()["users"],
+		"debug": nil,
+		"extra": map[string]any{"a": 1},
+	}, got)
+}
+
+func TestDeepMergeStrategies(t *testing.T) {
+	t.Parallel()
+
+	src := map[string]any{
+		"tags": []any{"prod"},
+		"users": []any{
+			map[string]any{"name": "guest", "shell": "zsh"},
+			map[string]any{"name": "ops"},
+			"raw",
+		},
+		"debug": nil,
+	}
+
+	got := util.DeepMerge(defaults(), src,
+		util.WithListStrategy(util.ListAppend),
+		util.WithPathListStrategy("users", util.ListMergeByKey),
+		util.WithNullDeletes())
+
+	assert.Equal(t, []any{"base", "prod"}, got["tags"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "admin", "shell": "bash"},
+		map[string]any{"name": "guest", "shell": "zsh"},
+		map[string]any{"name": "ops"},
+		"raw",
+	}, got["users"])
+	assert.NotContains(t, got, "debug")
+}
+
+func TestDeepMergeByCustomKey(t *testing.T) {
+	t.Parallel()
+
+	dst := map[string]any{"hosts": []any{
+		map[string]any{"id": 1, "ports": []any{80}},
+	}}
+	src := map[string]any{"hosts": []any{
+		map[string]any{"id": 1, "ports": []any{443}},
+		map[string]any{"id": []any{"uncomparable"}},
+	}}
+
+	got := util.DeepMerge(dst, src,
+		util.WithListStrategy(util.ListMergeByKey),
+		util.WithMergeKey("id"),
+		util.WithPathListStrategy("hosts.ports", util.ListAppend))
+
+	assert.Equal(t, []any{
+		map[string]any{"id": 1, "ports": []any{80, 443}},
+		map[string]any{"id": []any{"uncomparable"}},
+	}, got["hosts"])
+}
+
+func TestDeepMergeCopiesSource(t *testing.T) {
+	t.Parallel()
+
+	nested := map[string]any{"port": 1}
+	got := util.DeepMerge(nil, map[string]any{"http": nested})
+
+	nested["port"] = 2
+
+	assert.Equal(t, map[string]any{"http": map[string]any{"port": 1}}, got)
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Counter is a value that only goes up, such as a number of requests.
+// Label values are given in the order of the label names passed when the
+// counter was created.
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+// Counter returns the counter name, creating it on first use.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	vec := prometheus.NewCounterVec(
+		prometheus.CounterOpts(r.opts(name, help)), labels)
+
+	return &Counter{vec: register(r, vec)}
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc(labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Inc()
+}
+
+// Add adds delta, which must not be negative, to the counter.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(delta)
+}
+
+// Gauge is a value that goes up and down, such as a queue length.
+type Gauge struct {
+	vec *prometheus.GaugeVec
+}
+
+// Gauge returns the gauge name, creating it on first use.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	vec := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts(r.opts(name, help)), labels)
+
+	return &Gauge{vec: register(r, vec)}
+}
+
+// Set sets the gauge to value.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(value)
+}
+
+// Add adds delta, possibly negative, to the gauge.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Add(delta)
+}
+
+// Histogram counts observations, such as latencies, in buckets.
+type Histogram struct {
+	vec *prometheus.HistogramVec
+}
+
+// Histogram returns the histogram name, creating it on first use. Nil
+// buckets select prometheus.DefBuckets, suited to latencies in seconds.
+func (r *Registry) Histogram(
+	name, help string, buckets []float64, labels ...string,
+) *Histogram {
+	opts := r.opts(name, help)
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   opts.Namespace,
+		Name:        opts.Name,
+		Help:        opts.Help,
+		ConstLabels: opts.ConstLabels,
+		Buckets:     buckets,
+	}, labels)
+
+	return &Histogram{vec: register(r, vec)}
+}
+
+// Observe records value.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+}
+
+// ObserveSince records the seconds elapsed since start, typically
+// deferred at the beginning of the measured operation.
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/metrics"
+)
+
+func TestInstruments(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New(
+		metrics.WithoutRuntimeCollectors(),
+		metrics.WithNamespace("app"),
+		metrics.WithConstLabels(map[string]string{"version": "1.0"}))
+
+	requests := registry.Counter("requests_total", "Requests.", "code")
+	requests.Inc("200")
+	requests.Add(2, "200")
+	registry.Counter("requests_total", "Requests.", "code").Inc("500")
+
+	queue := registry.Gauge("queue_length", "Queued jobs.")
+	queue.Set(5)
+	queue.Add(-2)
+
+	latency := registry.Histogram("latency_seconds", "Latency.",
+		[]float64{0.1, 1})
+	latency.Observe(0.5)
+	latency.ObserveSince(time.Now().Add(-2 * time.Second))
+
+	body := scrape(t, registry)
+
+	assert.Contains(t, body,
+		`app_requests_total{code="200",version="1.0"} 3`)
+	assert.Contains(t, body,
+		`app_requests_total{code="500",version="1.0"} 1`)
+	assert.Contains(t, body, `app_queue_length{version="1.0"} 3`)
+	assert.Contains(t, body,
+		`app_latency_seconds_bucket{version="1.0",le="1"} 1`)
+	assert.Contains(t, body, `app_latency_seconds_count{version="1.0"} 2`)
+}
+
+func TestConflictingMetricPanics(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	registry.Counter("jobs", "Jobs.")
+
+	assert.Panics(t, func() {
+		registry.Gauge("jobs", "Jobs.")
+	})
+}
@@ -0,0 +1,121 @@
+// Package metrics is a small facade over the Prometheus client: a Registry
+// creates counters, gauges and histograms, collects the process and Go
+// runtime metrics, and serves everything on a /metrics handler.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is the conventional path of the metrics endpoint.
+const Path = "/metrics"
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	namespace   string
+	constLabels prometheus.Labels
+	noRuntime   bool
+}
+
+// WithNamespace prefixes every metric name with namespace and an
+// underscore, usually the service name.
+func WithNamespace(namespace string) Option {
+	return func(s *settings) {
+		s.namespace = namespace
+	}
+}
+
+// WithConstLabels attaches labels with fixed values, such as the version,
+// to every metric created by the registry.
+func WithConstLabels(labels map[string]string) Option {
+	return func(s *settings) {
+		s.constLabels = labels
+	}
+}
+
+// WithoutRuntimeCollectors leaves out the process and Go runtime metrics,
+// which are collected by default.
+func WithoutRuntimeCollectors() Option {
+	return func(s *settings) {
+		s.noRuntime = true
+	}
+}
+
+// Registry creates and exports metrics. It is safe for concurrent use.
+type Registry struct {
+	settings
+
+	registry *prometheus.Registry
+}
+
+// New returns a registry already holding the runtime collectors.
+func New(opts ...Option) *Registry {
+	r := &Registry{registry: prometheus.NewRegistry()}
+	for _, opt := range opts {
+		opt(&r.settings)
+	}
+
+	if !r.noRuntime {
+		r.registry.MustRegister(
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+			collectors.NewGoCollector())
+	}
+
+	return r
+}
+
+// Register adds a custom collector, for metrics the facade does not cover.
+func (r *Registry) Register(collector prometheus.Collector) error {
+	if err := r.registry.Register(collector); err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+
+	return nil
+}
+
+// Handler serves the metrics in the Prometheus exposition format, usually
+// mounted on Path.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{
+		Registry: r.registry,
+	})
+}
+
+// opts returns the common options of a metric.
+func (r *Registry) opts(name, help string) prometheus.Opts {
+	return prometheus.Opts{
+		Namespace:   r.namespace,
+		Name:        name,
+		Help:        help,
+		ConstLabels: r.constLabels,
+	}
+}
+
+// register adds collector, returning the collector registered before with
+// the same description if any, so that metrics can be declared where they
+// are used. It panics on conflicting descriptions, which are programming
+// errors.
+func register[C prometheus.Collector](r *Registry, collector C) C {
+	err := r.registry.Register(collector)
+
+	var existing prometheus.AlreadyRegisteredError
+
+	switch {
+	case err == nil:
+		return collector
+	case errors.As(err, &existing):
+		if same, ok := existing.ExistingCollector.(C); ok {
+			return same
+		}
+	}
+
+	panic(fmt.Sprintf("metrics: %v", err))
+}
@@ -0,0 +1,51 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/metrics"
+)
+
+// scrape returns the exposition served by the registry.
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequestWithContext(
+		t.Context(), http.MethodGet, metrics.Path, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestRuntimeCollectors(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, scrape(t, metrics.New()), "go_goroutines")
+	assert.NotContains(t,
+		scrape(t, metrics.New(metrics.WithoutRuntimeCollectors())),
+		"go_goroutines")
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	collector := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "custom", Help: "Custom gauge.",
+	})
+
+	require.NoError(t, registry.Register(collector))
+	require.Error(t, registry.Register(collector))
+	assert.Contains(t, scrape(t, registry), "custom 0")
+}
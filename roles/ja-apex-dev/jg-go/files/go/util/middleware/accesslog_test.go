@@ -0,0 +1,43 @@
+package middleware_test
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/middleware"
+)
+
+func TestAccessLog(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	handler := middleware.Chain(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+
+			_, _ = w.Write([]byte("hello"))
+		}),
+		middleware.RequestID(),
+		middleware.AccessLog(sink.Logger()))
+
+	serve(handler, get(t, "/ok"))
+	serve(handler, get(t, "/fail"))
+
+	entries := sink.Entries()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, slog.LevelInfo, entries[0].Level)
+	assert.Equal(t, int64(200), entries[0].Attrs["status"])
+	assert.Equal(t, int64(5), entries[0].Attrs["bytes"])
+	assert.NotEmpty(t, entries[0].Attrs["request_id"])
+
+	assert.Equal(t, slog.LevelWarn, entries[1].Level)
+	assert.Equal(t, int64(502), entries[1].Attrs["status"])
+}
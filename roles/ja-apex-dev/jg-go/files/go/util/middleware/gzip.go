@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"cmp"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipWriters recycles compressors, whose allocation dominates the cost
+// of small responses.
+var gzipWriters = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// Gzip compresses responses for clients accepting gzip. Responses already
+// carrying a Content-Encoding, and responses without a body, are sent
+// unchanged.
+func Gzip() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			if !acceptsGzip(r) || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w}
+			defer gw.close()
+
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for encoding := range strings.SplitSeq(
+		r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(name, "gzip") &&
+			strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gzipResponseWriter decides on the first write whether to compress,
+// holding back the status of responses with a body until then.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	status  int
+	decided bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	switch {
+	case w.decided || status < http.StatusOK:
+		w.ResponseWriter.WriteHeader(status)
+	case !bodyAllowed(status):
+		w.decided = true
+		w.ResponseWriter.WriteHeader(status)
+	case w.status == 0:
+		w.status = status
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 && !w.decided {
+		return 0, nil
+	}
+
+	w.decide(p)
+
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+
+	return w.gz.Write(p)
+}
+
+// Flush sends the data compressed so far.
+func (w *gzipResponseWriter) Flush() {
+	w.decide(nil)
+
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide starts compressing unless the response is already encoded, and
+// sends the held back status. The content type is sniffed from the first
+// chunk p as http.ResponseWriter would, since it cannot do it on the
+// compressed data.
+func (w *gzipResponseWriter) decide(p []byte) {
+	if w.decided {
+		return
+	}
+
+	w.decided = true
+
+	header := w.Header()
+	if header.Get("Content-Encoding") == "" {
+		if _, ok := header["Content-Type"]; !ok && len(p) > 0 {
+			header.Set("Content-Type", http.DetectContentType(p))
+		}
+
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+
+		w.gz, _ = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(cmp.Or(w.status, http.StatusOK))
+}
+
+// close sends a held back status, flushes the compressed stream and
+// recycles the compressor.
+func (w *gzipResponseWriter) close() {
+	if w.status != 0 {
+		w.decide(nil)
+	}
+
+	if w.gz == nil {
+		return
+	}
+
+	// Errors come from a gone client and cannot be reported.
+	_ = w.gz.Close()
+	gzipWriters.Put(w.gz)
+	w.gz = nil
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent &&
+		status != http.StatusNotModified
+}
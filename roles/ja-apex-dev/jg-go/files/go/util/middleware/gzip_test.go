@@ -0,0 +1,99 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/middleware"
+)
+
+var body = strings.Repeat("compressible ", 100)
+
+func TestGzip(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Gzip()(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/encoded":
+				w.Header().Set("Content-Encoding", "br")
+			case "/empty":
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			_, _ = io.WriteString(w, body)
+		}))
+
+	req := get(t, "/")
+	req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	resp := serve(handler, req)
+
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", resp.Header().Get("Vary"))
+
+	reader, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	for _, path := range []string{"/encoded", "/empty"} {
+		req := get(t, path)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		assert.NotEqual(t, "gzip",
+			serve(handler, req).Header().Get("Content-Encoding"), path)
+	}
+
+	plain := serve(handler, get(t, "/"))
+	assert.Equal(t, body, plain.Body.String())
+
+	req = get(t, "/")
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	assert.Equal(t, body, serve(handler, req).Body.String())
+}
+
+func TestGzipContentType(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Gzip()(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/created":
+				w.WriteHeader(http.StatusCreated)
+			case "/html":
+				_, _ = io.WriteString(w, "<!DOCTYPE html><p>")
+			case "/json":
+				w.Header().Set("Content-Type", "application/json")
+			}
+
+			_, _ = io.WriteString(w, body)
+		}))
+
+	for path, want := range map[string]string{
+		"/":        "text/plain; charset=utf-8",
+		"/created": "text/plain; charset=utf-8",
+		"/html":    "text/html; charset=utf-8",
+		"/json":    "application/json",
+	} {
+		req := get(t, path)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp := serve(handler, req).Result()
+		assert.Equal(t, want, resp.Header.Get("Content-Type"), path)
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"), path)
+
+		if path == "/created" {
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package middleware provides composable net/http handler wrappers for
+// request correlation IDs, panic recovery, access logging, gzip
+// compression and timeouts.
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+	"unicode"
+
+	"example.com/go-template/util/httpx"
+	"example.com/go-template/util/id"
+	"example.com/go-template/util/log"
+)
+
+// maxRequestIDLength bounds the incoming IDs reused by RequestID.
+const maxRequestIDLength = 128
+
+// Middleware decorates a handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with the middlewares, the first one being the outermost:
+// Chain(h, a, b) serves requests through a, then b, then h.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
+
+// RequestID reuses the correlation ID sent in the httpx.HeaderRequestID
+// header, or generates a ULID when it is missing or malformed. The ID is
+// echoed in the response, stored with httpx.WithRequestID so that
+// outgoing httpx requests propagate it, and added to the log attributes of
+// the request context as "request_id".
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(httpx.HeaderRequestID)
+			if !validRequestID(requestID) {
+				requestID = id.NewULID().String()
+			}
+
+			ctx := httpx.WithRequestID(r.Context(), requestID)
+			ctx = log.WithAttrs(ctx, slog.String("request_id", requestID))
+
+			w.Header().Set(httpx.HeaderRequestID, requestID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Timeout fails requests running longer than timeout with 503 Service
+// Unavailable and cancels their context. Responses are buffered until the
+// handler returns, so streaming handlers must not use it.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, timeout, "")
+	}
+}
+
+// validRequestID accepts short IDs made of printable ASCII, so that
+// clients cannot inject arbitrary data into logs.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || r == ' ' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/httpx"
+	"example.com/go-template/util/id"
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/middleware"
+)
+
+// serve runs req through h and returns the recorded response.
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func get(t *testing.T, target string) *http.Request {
+	t.Helper()
+
+	return httptest.NewRequestWithContext(
+		t.Context(), http.MethodGet, target, nil)
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+
+	tag := func(name string) middleware.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					order = append(order, name)
+					next.ServeHTTP(w, r)
+				})
+		}
+	}
+
+	handler := middleware.Chain(http.NotFoundHandler(), tag("a"), tag("b"))
+	serve(handler, get(t, "/"))
+
+	assert.Equal(t, []string{"a", "b"}, order)
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	var seen string
+
+	handler := middleware.RequestID()(http.HandlerFunc(
+		func(_ http.ResponseWriter, r *http.Request) {
+			seen = httpx.RequestID(r.Context())
+			assert.NotEmpty(t, log.Attrs(r.Context()))
+		}))
+
+	req := get(t, "/")
+	req.Header.Set(httpx.HeaderRequestID, "abc-123")
+	resp := serve(handler, req)
+
+	assert.Equal(t, "abc-123", seen)
+	assert.Equal(t, "abc-123", resp.Header().Get(httpx.HeaderRequestID))
+
+	for _, bad := range []string{"", "with space", strings.Repeat("x", 200)} {
+		req := get(t, "/")
+		req.Header.Set(httpx.HeaderRequestID, bad)
+		serve(handler, req)
+
+		assert.True(t, id.IsULID(seen), bad)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Timeout(10 * time.Millisecond)(http.HandlerFunc(
+		func(_ http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+
+	assert.Equal(t, http.StatusServiceUnavailable,
+		serve(handler, get(t, "/")).Code)
+}
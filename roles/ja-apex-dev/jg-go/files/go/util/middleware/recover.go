@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover turns panics of the next handlers into 500 Internal Server
+// Error responses, logging the panic value and stack at error level. The
+// http.ErrAbortHandler sentinel is re-raised to abort the response as the
+// server expects.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := newRecorder(w)
+
+			defer func() {
+				if value := recover(); value != nil {
+					handlePanic(logger, recorder, r, value)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// handlePanic logs value and answers the request, unless the handler
+// already started the response.
+func handlePanic(
+	logger *slog.Logger, w *recorder, r *http.Request, value any,
+) {
+	if err, ok := value.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+		panic(value)
+	}
+
+	logger.ErrorContext(r.Context(), "http handler panicked",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"panic", fmt.Sprint(value),
+		"stack", string(debug.Stack()))
+
+	if !w.wroteHeader {
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+	}
+}
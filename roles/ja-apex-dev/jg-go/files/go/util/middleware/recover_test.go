@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/middleware"
+)
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	handler := middleware.Recover(sink.Logger())(http.HandlerFunc(
+		func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		}))
+
+	resp := serve(handler, get(t, "/users"))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	entries := sink.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "boom", entries[0].Attrs["panic"])
+	assert.Equal(t, "/users", entries[0].Attrs["path"])
+	assert.Contains(t, entries[0].Attrs["stack"], "recover_test.go")
+}
+
+func TestRecoverAfterHeader(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Recover(log.Discard())(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("late")
+		}))
+
+	assert.Equal(t, http.StatusAccepted, serve(handler, get(t, "/")).Code)
+}
+
+func TestRecoverAbort(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Recover(log.Discard())(http.HandlerFunc(
+		func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(handler, get(t, "/"))
+	})
+}
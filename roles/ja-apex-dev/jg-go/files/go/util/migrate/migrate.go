@@ -0,0 +1,197 @@
+// Package migrate applies versioned SQL migrations, usually embedded in the
+// binary, and records them in a versions table:
+//
+//	//go:embed migrations/*.sql
+//	var files embed.FS
+//
+//	migrations, _ := fs.Sub(files, "migrations")
+//	m, err := migrate.New(db, migrations)
+//	...
+//	err = m.Up(ctx)
+//
+// Migrations are pairs of files named 0001_create_users.up.sql and
+// 0001_create_users.down.sql, the down file being optional. Every
+// migration runs in a transaction, but drivers without transactional DDL
+// may still leave a failed migration half applied, so it stays marked
+// dirty and blocks further runs until Force records the actual state.
+package migrate
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// DefaultTable is the name of the versions table.
+const DefaultTable = "schema_migrations"
+
+var (
+	// ErrInvalid is returned for migration files with malformed names,
+	// duplicate versions and up files missing.
+	ErrInvalid = errors.New("migrate: invalid migration")
+	// ErrDirty is returned when a previous migration failed.
+	ErrDirty = errors.New("migrate: database is dirty")
+	// ErrIrreversible is returned when reverting a migration without a
+	// down file.
+	ErrIrreversible = errors.New("migrate: migration has no down file")
+	// ErrUnknown is returned for versions without a migration.
+	ErrUnknown = errors.New("migrate: unknown version")
+)
+
+// fileName matches the names of migration files.
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change.
+type Migration struct {
+	// Version orders the migrations. It is positive.
+	Version int64
+	// Name describes the change, such as "create_users".
+	Name string
+	// Up applies the change.
+	Up string
+	// Down reverts the change. It is empty for irreversible migrations.
+	Down string
+}
+
+// Load reads the migrations in the root directory of fsys, ordered by
+// version. Files not ending with .sql are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, name := range names {
+		if err := loadFile(fsys, name, byVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s has no up file",
+				ErrInvalid, m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// loadFile adds the migration file name to byVersion.
+func loadFile(fsys fs.FS, name string, byVersion map[int64]*Migration) error {
+	match := fileName.FindStringSubmatch(name)
+	if match == nil {
+		return fmt.Errorf("%w: file name %q", ErrInvalid, name)
+	}
+
+	version, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || version <= 0 {
+		return fmt.Errorf("%w: version of %q", ErrInvalid, name)
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	m := byVersion[version]
+	if m == nil {
+		m = &Migration{Version: version, Name: match[2]}
+		byVersion[version] = m
+	}
+
+	target := &m.Up
+	if match[3] == "down" {
+		target = &m.Down
+	}
+
+	if m.Name != match[2] || *target != "" {
+		return fmt.Errorf("%w: duplicate version %d", ErrInvalid, version)
+	}
+
+	*target = string(data)
+
+	return nil
+}
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	table  string
+	dryRun io.Writer
+}
+
+// WithTable records the versions in table instead of DefaultTable. The
+// name is used verbatim in statements, so it must be quoted as needed.
+func WithTable(table string) Option {
+	return func(s *settings) {
+		s.table = table
+	}
+}
+
+// WithDryRun prints the migration statements to w instead of running
+// them. The versions table is still read since it changes nothing, and
+// is assumed empty while it cannot be read, as before the first run.
+func WithDryRun(w io.Writer) Option {
+	return func(s *settings) {
+		s.dryRun = w
+	}
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	settings
+}
+
+// New returns a migrator of db with the migrations loaded from fsys.
+func New(db *sql.DB, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	s := settings{table: DefaultTable}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &Migrator{db: db, migrations: migrations, settings: s}, nil
+}
+
+// Migrations returns the loaded migrations, ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	return slices.Clone(m.migrations)
+}
+
+// check fails with ErrUnknown when version, other than 0, has no
+// migration.
+func (m *Migrator) check(version int64) error {
+	_, ok := slices.BinarySearchFunc(m.migrations, version,
+		func(m Migration, version int64) int {
+			return cmp.Compare(m.Version, version)
+		})
+	if !ok && version != 0 {
+		return fmt.Errorf("%w: %d", ErrUnknown, version)
+	}
+
+	return nil
+}
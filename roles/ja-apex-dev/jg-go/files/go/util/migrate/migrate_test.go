@@ -0,0 +1,192 @@
+package migrate_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"example.com/go-template/util/migrate"
+)
+
+// files returns migrations creating users, then posts without a down
+// file, plus a file to ignore.
+func files() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_users.up.sql": {
+			Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);\n"),
+		},
+		"0001_users.down.sql": {Data: []byte("DROP TABLE users;\n")},
+		"0002_posts.up.sql": {
+			Data: []byte("CREATE TABLE posts (id INTEGER);\n" +
+				"CREATE INDEX posts_id ON posts (id);\n"),
+		},
+		"README.md": {Data: []byte("not a migration\n")},
+	}
+}
+
+func open(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func tables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+
+	rows, err := db.QueryContext(t.Context(), "SELECT name FROM sqlite_master "+
+		"WHERE type = 'table' AND name != 'schema_migrations' ORDER BY name")
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	var names []string
+
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+
+		names = append(names, name)
+	}
+
+	require.NoError(t, rows.Err())
+
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := migrate.Load(files())
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, migrate.Migration{
+		Version: 1, Name: "users",
+		Up:   "CREATE TABLE users (id INTEGER PRIMARY KEY);\n",
+		Down: "DROP TABLE users;\n",
+	}, migrations[0])
+	assert.Equal(t, "posts", migrations[1].Name)
+	assert.Empty(t, migrations[1].Down)
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"name":      {"1-users.up.sql": {}},
+		"zero":      {"0_users.up.sql": {Data: []byte("x")}},
+		"no up":     {"1_users.down.sql": {Data: []byte("x")}},
+		"duplicate": {"1_a.up.sql": {}, "01_b.up.sql": {}},
+	} {
+		_, err := migrate.Load(fsys)
+		require.ErrorIs(t, err, migrate.ErrInvalid, name)
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	t.Parallel()
+
+	db := open(t)
+	m, err := migrate.New(db, files())
+	require.NoError(t, err)
+
+	pending, err := m.Pending(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	require.NoError(t, m.Up(t.Context()))
+	require.NoError(t, m.Up(t.Context()), "nothing left")
+	assert.Equal(t, []string{"posts", "users"}, tables(t, db))
+
+	version, dirty, err := m.Version(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.False(t, dirty)
+
+	require.ErrorIs(t, m.Down(t.Context()), migrate.ErrIrreversible)
+	require.ErrorIs(t, m.To(t.Context(), 3), migrate.ErrUnknown)
+
+	_, err = db.ExecContext(t.Context(), "DELETE FROM schema_migrations "+
+		"WHERE version = 2")
+	require.NoError(t, err)
+	require.NoError(t, m.Down(t.Context()))
+	assert.Equal(t, []string{"posts"}, tables(t, db))
+
+	version, _, err = m.Version(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, version)
+	require.NoError(t, m.Down(t.Context()), "nothing applied")
+}
+
+func TestDirty(t *testing.T) {
+	t.Parallel()
+
+	db := open(t)
+	broken := files()
+	broken["0002_posts.up.sql"] = &fstest.MapFile{
+		Data: []byte("CREATE TABLE posts (id INTEGER);\nnonsense;\n"),
+	}
+
+	m, err := migrate.New(db, broken, migrate.WithTable("versions"))
+	require.NoError(t, err)
+	require.ErrorContains(t, m.Up(t.Context()), "2 posts up")
+	assert.Equal(t, []string{"users", "versions"}, tables(t, db),
+		"posts rolled back")
+
+	version, dirty, err := m.Version(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.True(t, dirty)
+	require.ErrorIs(t, m.Up(t.Context()), migrate.ErrDirty)
+
+	require.NoError(t, m.Force(t.Context(), 1))
+
+	m, err = migrate.New(db, files(), migrate.WithTable("versions"))
+	require.NoError(t, err)
+	require.NoError(t, m.Up(t.Context()))
+
+	version, dirty, err = m.Version(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.False(t, dirty)
+}
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+
+	db := open(t)
+
+	var out strings.Builder
+
+	m, err := migrate.New(db, files(), migrate.WithDryRun(&out))
+	require.NoError(t, err)
+	require.NoError(t, m.To(t.Context(), 1))
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS schema_migrations "+
+		"(version BIGINT PRIMARY KEY, dirty INTEGER NOT NULL);\n"+
+		"-- 1 users up\n"+
+		"INSERT INTO schema_migrations (version, dirty) VALUES (1, 1);\n"+
+		"BEGIN;\n"+
+		"CREATE TABLE users (id INTEGER PRIMARY KEY);\n"+
+		"UPDATE schema_migrations SET dirty = 0 WHERE version = 1;\n"+
+		"COMMIT;\n", out.String())
+	assert.Empty(t, tables(t, db))
+
+	live, err := migrate.New(db, files())
+	require.NoError(t, err)
+	require.NoError(t, live.To(t.Context(), 1))
+
+	out.Reset()
+	require.NoError(t, m.Down(t.Context()))
+	assert.Equal(t, "-- 1 users down\n"+
+		"UPDATE schema_migrations SET dirty = 1 WHERE version = 1;\n"+
+		"BEGIN;\n"+
+		"DROP TABLE users;\n"+
+		"DELETE FROM schema_migrations WHERE version = 1;\n"+
+		"COMMIT;\n", out.String())
+	assert.Equal(t, []string{"users"}, tables(t, db))
+}
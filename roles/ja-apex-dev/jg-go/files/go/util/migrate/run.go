@@ -0,0 +1,314 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Up applies the migrations not applied yet, in version order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.latest())
+}
+
+// Down reverts the latest applied migration. It is a no-op when none is.
+func (m *Migrator) Down(ctx context.Context) error {
+	applied, err := m.clean(ctx)
+	if err != nil || len(applied) == 0 {
+		return err
+	}
+
+	versions := slices.Sorted(maps.Keys(applied))
+	if len(versions) == 1 {
+		return m.To(ctx, 0)
+	}
+
+	return m.To(ctx, versions[len(versions)-2])
+}
+
+// To reverts the applied migrations after version, latest first, then
+// applies the migrations up to version. Version 0 reverts everything.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if err := m.check(version); err != nil {
+		return err
+	}
+
+	applied, err := m.clean(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := m.revertAfter(ctx, applied, version); err != nil {
+		return err
+	}
+
+	return m.applyUpTo(ctx, applied, version)
+}
+
+// revertAfter reverts the applied migrations after version, latest first.
+func (m *Migrator) revertAfter(
+	ctx context.Context, applied map[int64]bool, version int64,
+) error {
+	for _, mig := range slices.Backward(m.migrations) {
+		if _, ok := applied[mig.Version]; !ok || mig.Version <= version {
+			continue
+		}
+
+		if err := m.revert(ctx, mig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyUpTo applies the migrations not applied yet up to version.
+func (m *Migrator) applyUpTo(
+	ctx context.Context, applied map[int64]bool, version int64,
+) error {
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok || mig.Version > version {
+			continue
+		}
+
+		if err := m.apply(ctx, mig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Version returns the latest applied version, 0 when none is, and whether
+// a migration failed.
+func (m *Migrator) Version(ctx context.Context) (int64, bool, error) {
+	applied, err := m.read(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+
+	for v, d := range applied {
+		version = max(version, v)
+		dirty = dirty || d
+	}
+
+	return version, dirty, nil
+}
+
+// Pending returns the migrations not applied yet, in version order.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+
+	return pending, nil
+}
+
+// Force records version as the applied state and clears the dirty marks,
+// after fixing the schema by hand following a failed migration. Version 0
+// records that nothing is applied.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if err := m.check(version); err != nil {
+		return err
+	}
+
+	if _, err := m.read(ctx); err != nil {
+		return err
+	}
+
+	statements := []string{
+		fmt.Sprintf("DELETE FROM %s WHERE version >= %d", m.table, version),
+		fmt.Sprintf("UPDATE %s SET dirty = 0", m.table),
+	}
+	if version != 0 {
+		statements = append(statements, fmt.Sprintf(
+			"INSERT INTO %s (version, dirty) VALUES (%d, 0)",
+			m.table, version))
+	}
+
+	return m.step(ctx, fmt.Sprintf("force %d", version), "", statements)
+}
+
+// apply runs the up file of mig, marking it dirty until it succeeds.
+func (m *Migrator) apply(ctx context.Context, mig Migration) error {
+	return m.step(ctx, fmt.Sprintf("%d %s up", mig.Version, mig.Name),
+		fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (%d, 1)",
+			m.table, mig.Version),
+		[]string{mig.Up, fmt.Sprintf(
+			"UPDATE %s SET dirty = 0 WHERE version = %d",
+			m.table, mig.Version)})
+}
+
+// revert runs the down file of mig, marking it dirty until it succeeds.
+func (m *Migrator) revert(ctx context.Context, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrIrreversible, mig.Version, mig.Name)
+	}
+
+	return m.step(ctx, fmt.Sprintf("%d %s down", mig.Version, mig.Name),
+		fmt.Sprintf("UPDATE %s SET dirty = 1 WHERE version = %d",
+			m.table, mig.Version),
+		[]string{mig.Down, fmt.Sprintf(
+			"DELETE FROM %s WHERE version = %d", m.table, mig.Version)})
+}
+
+// step runs the statement before, unless empty, then the statements of
+// the transaction, or prints them all under the title in dry runs.
+func (m *Migrator) step(
+	ctx context.Context, title, before string, transaction []string,
+) error {
+	if m.dryRun != nil {
+		return m.print(title, before, transaction)
+	}
+
+	if before != "" {
+		if _, err := m.db.ExecContext(ctx, before); err != nil {
+			return fmt.Errorf("migrate: %s: %w", title, err)
+		}
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate: %s: %w", title, err)
+	}
+
+	for _, statement := range transaction {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("migrate: %s: %w", title, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migrate: %s: %w", title, err)
+	}
+
+	return nil
+}
+
+// print writes the statements of a step to the dry-run output.
+func (m *Migrator) print(title, before string, transaction []string) error {
+	var b strings.Builder
+
+	b.WriteString("-- " + title + "\n")
+
+	if before != "" {
+		b.WriteString(terminated(before))
+	}
+
+	b.WriteString("BEGIN;\n")
+
+	for _, statement := range transaction {
+		b.WriteString(terminated(statement))
+	}
+
+	b.WriteString("COMMIT;\n")
+
+	if _, err := io.WriteString(m.dryRun, b.String()); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	return nil
+}
+
+// terminated returns statement ending with a semicolon and a newline.
+func terminated(statement string) string {
+	statement = strings.TrimSpace(statement)
+	if !strings.HasSuffix(statement, ";") {
+		statement += ";"
+	}
+
+	return statement + "\n"
+}
+
+// clean reads the versions table, failing with ErrDirty when a migration
+// failed.
+func (m *Migrator) clean(ctx context.Context) (map[int64]bool, error) {
+	applied, err := m.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range slices.Sorted(maps.Keys(applied)) {
+		if applied[version] {
+			return nil, fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+	}
+
+	return applied, nil
+}
+
+// read returns the applied versions with their dirty marks, creating the
+// versions table first. Dry runs print its creation instead, when the
+// table cannot be read.
+func (m *Migrator) read(ctx context.Context) (map[int64]bool, error) {
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s "+
+		"(version BIGINT PRIMARY KEY, dirty INTEGER NOT NULL)", m.table)
+
+	if m.dryRun == nil {
+		if _, err := m.db.ExecContext(ctx, create); err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+	}
+
+	applied, err := m.query(ctx)
+	if err != nil && m.dryRun != nil {
+		_, err = io.WriteString(m.dryRun, terminated(create))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	return applied, nil
+}
+
+// query reads the versions table.
+func (m *Migrator) query(ctx context.Context) (map[int64]bool, error) {
+	rows, err := m.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT version, dirty FROM %s", m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+
+	for rows.Next() {
+		var version, dirty int64
+		if err := rows.Scan(&version, &dirty); err != nil {
+			return nil, err
+		}
+
+		applied[version] = dirty != 0
+	}
+
+	return applied, rows.Err()
+}
+
+// latest returns the version of the last migration, or 0.
+func (m *Migrator) latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
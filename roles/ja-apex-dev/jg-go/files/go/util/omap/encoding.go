@@ -0,0 +1,220 @@
+package omap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrUnsupportedKey is returned when a key type cannot be represented as
+	// a JSON object key.
+	ErrUnsupportedKey = errors.New("omap: unsupported key type")
+	// ErrNotObject is returned when decoding anything but an object.
+	ErrNotObject = errors.New("omap: expected an object")
+)
+
+// MarshalJSON encodes the map as a JSON object in insertion order. Keys
+// follow encoding/json rules: strings, integers or encoding.TextMarshaler.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for key, value := range m.All() {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		text, err := encodeKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedKey, _ := json.Marshal(text)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+
+		encodedValue, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("omap: key %q: %w", text, err)
+		}
+
+		buf.Write(encodedValue)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the content of the map with a JSON object,
+// keeping the order of its keys.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	*m = OrderedMap[K, V]{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("omap: %w", err)
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if token != json.Delim('{') {
+		return ErrNotObject
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return fmt.Errorf("omap: %w", err)
+		}
+
+		// Object keys are always strings; the decoder rejects anything else.
+		text, _ := token.(string)
+
+		key, err := decodeKey[K](text)
+		if err != nil {
+			return err
+		}
+
+		var value V
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("omap: key %q: %w", text, err)
+		}
+
+		m.Set(key, value)
+	}
+
+	return nil
+}
+
+// MarshalYAML encodes the map as a YAML mapping in insertion order.
+func (m *OrderedMap[K, V]) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for key, value := range m.All() {
+		var keyNode, valueNode yaml.Node
+		if err := keyNode.Encode(key); err != nil {
+			return nil, fmt.Errorf("omap: %w", err)
+		}
+
+		if err := valueNode.Encode(value); err != nil {
+			return nil, fmt.Errorf("omap: key %v: %w", key, err)
+		}
+
+		node.Content = append(node.Content, &keyNode, &valueNode)
+	}
+
+	return node, nil
+}
+
+// UnmarshalYAML replaces the content of the map with a YAML mapping,
+// keeping the order of its keys.
+func (m *OrderedMap[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	*m = OrderedMap[K, V]{}
+
+	if node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return ErrNotObject
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var (
+			key   K
+			value V
+		)
+
+		if err := node.Content[i].Decode(&key); err != nil {
+			return fmt.Errorf("omap: %w", err)
+		}
+
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return fmt.Errorf("omap: key %v: %w", key, err)
+		}
+
+		m.Set(key, value)
+	}
+
+	return nil
+}
+
+// encodeKey renders a key the way encoding/json renders map keys.
+func encodeKey(key any) (string, error) {
+	if marshaler, ok := key.(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("omap: %w", err)
+		}
+
+		return string(text), nil
+	}
+
+	value := reflect.ValueOf(key)
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
+
+// decodeKey parses an object key into K, mirroring encodeKey.
+func decodeKey[K comparable](text string) (K, error) {
+	var key K
+
+	if unmarshaler, ok := any(&key).(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+			return key, fmt.Errorf("omap: key %q: %w", text, err)
+		}
+
+		return key, nil
+	}
+
+	value := reflect.ValueOf(&key).Elem()
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return key, fmt.Errorf("omap: key %q: %w", text, err)
+		}
+
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return key, fmt.Errorf("omap: key %q: %w", text, err)
+		}
+
+		value.SetUint(n)
+	default:
+		return key, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,137 @@
+// Package omap provides a map preserving insertion order, including when
+// marshaled to JSON or YAML.
+package omap
+
+import (
+	"iter"
+)
+
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *entry[K, V]
+}
+
+// OrderedMap is a map iterating in insertion order. Updating an existing key
+// keeps its position. The zero value is an empty map ready to use; it is not
+// safe for concurrent writes.
+type OrderedMap[K comparable, V any] struct {
+	index       map[K]*entry[K, V]
+	first, last *entry[K, V]
+}
+
+// New returns an empty map with room for size entries.
+func New[K comparable, V any](size int) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{index: make(map[K]*entry[K, V], size)}
+}
+
+// Collect builds a map from pairs in iteration order.
+func Collect[K comparable, V any](seq iter.Seq2[K, V]) *OrderedMap[K, V] {
+	m := New[K, V](0)
+	for key, value := range seq {
+		m.Set(key, value)
+	}
+
+	return m
+}
+
+// Set stores value under key, appending the key if it is new.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if e, ok := m.index[key]; ok {
+		e.value = value
+
+		return
+	}
+
+	if m.index == nil {
+		m.index = make(map[K]*entry[K, V])
+	}
+
+	e := &entry[K, V]{key: key, value: value, prev: m.last}
+	if m.last == nil {
+		m.first = e
+	} else {
+		m.last.next = e
+	}
+
+	m.last = e
+	m.index[key] = e
+}
+
+// Get returns the value stored under key.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.index[key]; ok {
+		return e.value, true
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// Has reports whether key is present.
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.index[key]
+
+	return ok
+}
+
+// Delete removes key, reporting whether it was present.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := m.index[key]
+	if !ok {
+		return false
+	}
+
+	if e.prev == nil {
+		m.first = e.next
+	} else {
+		e.prev.next = e.next
+	}
+
+	if e.next == nil {
+		m.last = e.prev
+	} else {
+		e.next.prev = e.prev
+	}
+
+	delete(m.index, key)
+
+	return true
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.index)
+}
+
+// All iterates over the entries in insertion order.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := m.first; e != nil; e = e.next {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns the keys in insertion order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for key := range m.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Values returns the values in insertion order.
+func (m *OrderedMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	for _, value := range m.All() {
+		values = append(values, value)
+	}
+
+	return values
+}
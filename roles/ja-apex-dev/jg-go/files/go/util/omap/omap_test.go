@@ -0,0 +1,105 @@
+package omap_test
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"example.com/go-template/util/omap"
+)
+
+func TestOrderedMap(t *testing.T) {
+	t.Parallel()
+
+	var m omap.OrderedMap[string, int]
+
+	m.Set("b", 1)
+	m.Set("a", 2)
+	m.Set("c", 3)
+	m.Set("b", 4)
+
+	assert.Equal(t, []string{"b", "a", "c"}, m.Keys())
+	assert.Equal(t, []int{4, 2, 3}, m.Values())
+
+	value, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	assert.True(t, m.Delete("b"))
+	assert.False(t, m.Delete("b"))
+	assert.True(t, m.Delete("c"))
+	assert.False(t, m.Has("c"))
+
+	m.Set("d", 5)
+	assert.Equal(t, []string{"a", "d"}, m.Keys())
+	assert.Equal(t, 2, m.Len())
+
+	_, ok = m.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestOrderedMapIteration(t *testing.T) {
+	t.Parallel()
+
+	m := omap.Collect(slices.All([]string{"x", "y", "z"}))
+	assert.Equal(t, []int{0, 1, 2}, m.Keys())
+
+	for key := range m.All() {
+		if key == 1 {
+			break
+		}
+	}
+
+	assert.Equal(t, map[int]string{0: "x", 1: "y", 2: "z"},
+		maps.Collect(m.All()))
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	t.Parallel()
+
+	input := `{"zeta":1,"alpha":{"nested":true},"mid":[1,2]}`
+
+	var m omap.OrderedMap[string, any]
+	require.NoError(t, json.Unmarshal([]byte(input), &m))
+	assert.Equal(t, []string{"zeta", "alpha", "mid"}, m.Keys())
+
+	data, err := json.Marshal(&m)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(data))
+
+	numbered := omap.New[int, string](2)
+	numbered.Set(10, "ten")
+	numbered.Set(2, "two")
+
+	data, err = json.Marshal(numbered)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"10":"ten","2":"two"}`, string(data))
+
+	var decoded omap.OrderedMap[int, string]
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []int{10, 2}, decoded.Keys())
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`[1]`), &m), omap.ErrNotObject)
+	require.Error(t, json.Unmarshal([]byte(`{"x":"y"}`), &decoded))
+}
+
+func TestOrderedMapYAML(t *testing.T) {
+	t.Parallel()
+
+	input := "zeta: 1\nalpha:\n    nested: true\nmid: 3\n"
+
+	var m omap.OrderedMap[string, any]
+	require.NoError(t, yaml.Unmarshal([]byte(input), &m))
+	assert.Equal(t, []string{"zeta", "alpha", "mid"}, m.Keys())
+
+	data, err := yaml.Marshal(&m)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(data))
+
+	require.ErrorIs(t, yaml.Unmarshal([]byte("- 1"), &m), omap.ErrNotObject)
+}
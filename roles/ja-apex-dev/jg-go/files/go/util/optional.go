@@ -0,0 +1,123 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Ptr returns a pointer to a copy of v, for literals in optional fields.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Deref returns the value p points to, or fallback when p is nil.
+func Deref[T any](p *T, fallback T) T {
+	if p == nil {
+		return fallback
+	}
+
+	return *p
+}
+
+// optionalState tells the three states of an Optional apart.
+type optionalState uint8
+
+const (
+	optionalAbsent optionalState = iota
+	optionalNull
+	optionalValue
+)
+
+// jsonNull is the JSON encoding of a null value.
+var jsonNull = []byte("null")
+
+// Optional holds a value that may be absent, explicitly null or set,
+// which pointers cannot tell apart when decoding JSON patches. The zero
+// Optional is absent; tag fields with omitzero to leave absent values out
+// of the encoded JSON.
+type Optional[T any] struct {
+	value T
+	state optionalState
+}
+
+// Some returns an Optional holding v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, state: optionalValue}
+}
+
+// Null returns an explicitly null Optional.
+func Null[T any]() Optional[T] {
+	return Optional[T]{state: optionalNull}
+}
+
+// IsSet reports whether o was given a value or an explicit null.
+func (o Optional[T]) IsSet() bool {
+	return o.state != optionalAbsent
+}
+
+// IsNull reports whether o is explicitly null.
+func (o Optional[T]) IsNull() bool {
+	return o.state == optionalNull
+}
+
+// Get returns the value of o and whether it holds one.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.state == optionalValue
+}
+
+// OrElse returns the value of o, or fallback when it holds none.
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.state != optionalValue {
+		return fallback
+	}
+
+	return o.value
+}
+
+// Ptr returns a pointer to the value of o, or nil when it holds none.
+func (o Optional[T]) Ptr() *T {
+	if o.state != optionalValue {
+		return nil
+	}
+
+	return Ptr(o.value)
+}
+
+// IsZero reports whether o is absent, for the omitzero JSON tag option.
+func (o Optional[T]) IsZero() bool {
+	return o.state == optionalAbsent
+}
+
+// MarshalJSON encodes the value of o, or null when it holds none.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != optionalValue {
+		return jsonNull, nil
+	}
+
+	data, err := json.Marshal(o.value)
+	if err != nil {
+		return nil, fmt.Errorf("util: marshal optional: %w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalJSON decodes a value into o, or marks it null on a JSON null.
+// Fields missing from the input are left absent.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), jsonNull) {
+		*o = Null[T]()
+
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("util: unmarshal optional: %w", err)
+	}
+
+	*o = Some(value)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package util_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+type patch struct {
+	Name  util.Optional[string] `json:"name,omitzero"`
+	Port  util.Optional[int]    `json:"port,omitzero"`
+	Debug util.Optional[bool]   `json:"debug,omitzero"`
+}
+
+func TestPtr(t *testing.T) {
+	t.Parallel()
+
+	p := util.Ptr(42)
+	*p++
+
+	assert.Equal(t, 43, util.Deref(p, 0))
+	assert.Equal(t, "none", util.Deref(nil, "none"))
+}
+
+func TestOptional(t *testing.T) {
+	t.Parallel()
+
+	var absent util.Optional[int]
+
+	assert.False(t, absent.IsSet())
+	assert.Equal(t, 7, absent.OrElse(7))
+	assert.Nil(t, absent.Ptr())
+
+	null := util.Null[int]()
+	assert.True(t, null.IsSet())
+	assert.True(t, null.IsNull())
+
+	_, ok := null.Get()
+	assert.False(t, ok)
+
+	some := util.Some(3)
+	value, ok := some.Get()
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	assert.Equal(t, 3, some.OrElse(7))
+	assert.Equal(t, util.Ptr(3), some.Ptr())
+}
+
+func TestOptionalJSON(t *testing.T) {
+	t.Parallel()
+
+	var p patch
+	require.NoError(t, json.Unmarshal(
+		[]byte(`{"name": "api", "port": null}`), &p))
+
+	assert.Equal(t, "api", p.Name.OrElse(""))
+	assert.True(t, p.Port.IsNull())
+	assert.False(t, p.Debug.IsSet())
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "api", "port": null}`, string(data))
+
+	require.Error(t, json.Unmarshal([]byte(`{"port": "x"}`), &p))
+}
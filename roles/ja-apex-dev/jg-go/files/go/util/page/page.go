@@ -0,0 +1,142 @@
+// Package page splits collections into pages for JSON APIs, either by page
+// number or with opaque cursors.
+package page
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Page size bounds applied to requested sizes.
+const (
+	DefaultSize = 20
+	MaxSize     = 100
+)
+
+// ErrInvalidCursor is returned when decoding a malformed or tampered
+// cursor.
+var ErrInvalidCursor = errors.New("page: invalid cursor")
+
+// Result is a page of items with the metadata clients need to fetch the
+// next one.
+type Result[T any] struct {
+	Items      []T    `json:"items"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	Total      int    `json:"total"`
+	Pages      int    `json:"pages"`
+	HasNext    bool   `json:"hasNext"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Cursor marks a position in a collection: an offset for in-memory slices
+// or the last key seen for keyset pagination in databases.
+type Cursor struct {
+	Offset int    `json:"o,omitempty"`
+	Key    string `json:"k,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor created by Cursor.Encode. The empty string
+// decodes to the zero cursor, the start of the collection.
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if c.Offset < 0 {
+		return Cursor{}, fmt.Errorf("%w: negative offset", ErrInvalidCursor)
+	}
+
+	return c, nil
+}
+
+// ClampSize bounds a requested page size to [1, MaxSize], using
+// DefaultSize for non-positive values.
+func ClampSize(size int) int {
+	if size <= 0 {
+		return DefaultSize
+	}
+
+	return min(size, MaxSize)
+}
+
+// Paginate returns the 1-based page of items. Pages below 1 are treated as
+// the first and the size is clamped with ClampSize. Items share the backing
+// array of the input.
+func Paginate[T any](items []T, page, size int) Result[T] {
+	size = ClampSize(size)
+	page = max(page, 1)
+
+	// Pages beyond the last are empty, without computing an offset that
+	// may overflow.
+	offset := len(items)
+	if page-1 <= len(items)/size {
+		offset = (page - 1) * size
+	}
+
+	result := slice(items, offset, size)
+	result.Page = page
+
+	return result
+}
+
+// PaginateCursor returns the page starting at cursor, setting NextCursor
+// when more items follow.
+func PaginateCursor[T any](items []T, cursor string, size int) (
+	Result[T], error,
+) {
+	c, err := DecodeCursor(cursor)
+	if err != nil {
+		return Result[T]{}, err
+	}
+
+	size = ClampSize(size)
+
+	result := slice(items, c.Offset, size)
+	result.Page = c.Offset/size + 1
+
+	if result.HasNext {
+		result.NextCursor = Cursor{Offset: c.Offset + size}.Encode()
+	}
+
+	return result, nil
+}
+
+func slice[T any](items []T, offset, size int) Result[T] {
+	total := len(items)
+	start := min(offset, total)
+	end := min(start+size, total)
+
+	// Keep "items": [] in JSON for empty pages.
+	page := items[start:end:end]
+	if page == nil {
+		page = []T{}
+	}
+
+	return Result[T]{
+		Items:   page,
+		Size:    size,
+		Total:   total,
+		Pages:   (total + size - 1) / size,
+		HasNext: end < total,
+	}
+}
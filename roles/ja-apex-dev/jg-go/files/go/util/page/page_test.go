@@ -0,0 +1,129 @@
+package page_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/page"
+)
+
+func numbers(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i + 1
+	}
+
+	return items
+}
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	items := numbers(7)
+
+	first := page.Paginate(items, 1, 3)
+	assert.Equal(t, page.Result[int]{
+		Items: []int{1, 2, 3}, Page: 1, Size: 3, Total: 7, Pages: 3,
+		HasNext: true,
+	}, first)
+
+	last := page.Paginate(items, 3, 3)
+	assert.Equal(t, []int{7}, last.Items)
+	assert.False(t, last.HasNext)
+
+	beyond := page.Paginate(items, 9, 3)
+	assert.Empty(t, beyond.Items)
+	assert.False(t, beyond.HasNext)
+
+	huge := page.Paginate(items, math.MaxInt, 20)
+	assert.Empty(t, huge.Items)
+	assert.Equal(t, math.MaxInt, huge.Page)
+
+	clamped := page.Paginate(numbers(250), 0, 1000)
+	assert.Equal(t, 1, clamped.Page)
+	assert.Len(t, clamped.Items, page.MaxSize)
+
+	assert.Equal(t, page.DefaultSize, page.Paginate(items, 1, 0).Size)
+
+	empty := page.Paginate([]int(nil), 1, 10)
+	assert.Zero(t, empty.Pages)
+	assert.NotNil(t, empty.Items)
+}
+
+func TestPaginateDoesNotLeakCapacity(t *testing.T) {
+	t.Parallel()
+
+	items := numbers(4)
+	result := page.Paginate(items, 1, 2)
+	result.Items = append(result.Items, 99)
+
+	assert.Equal(t, 3, items[2])
+}
+
+func TestPaginateCursor(t *testing.T) {
+	t.Parallel()
+
+	items := numbers(5)
+
+	var (
+		seen   []int
+		cursor string
+		pages  int
+	)
+
+	for {
+		result, err := page.PaginateCursor(items, cursor, 2)
+		require.NoError(t, err)
+
+		seen = append(seen, result.Items...)
+		pages++
+
+		if !result.HasNext {
+			assert.Empty(t, result.NextCursor)
+
+			break
+		}
+
+		cursor = result.NextCursor
+	}
+
+	assert.Equal(t, items, seen)
+	assert.Equal(t, 3, pages)
+
+	_, err := page.PaginateCursor(items, "not a cursor!", 2)
+	require.ErrorIs(t, err, page.ErrInvalidCursor)
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	c := page.Cursor{Offset: 40, Key: "2024-01-01/abc"}
+
+	decoded, err := page.DecodeCursor(c.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, c, decoded)
+
+	decoded, err = page.DecodeCursor("")
+	require.NoError(t, err)
+	assert.Zero(t, decoded)
+
+	for _, input := range []string{
+		"%%%", "bm90IGpzb24", page.Cursor{Offset: -1}.Encode(),
+	} {
+		_, err := page.DecodeCursor(input)
+		require.ErrorIs(t, err, page.ErrInvalidCursor, input)
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(page.Paginate([]string{"a", "b"}, 1, 1))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":["a"],"page":1,"size":1,"total":2,
+		"pages":2,"hasNext":true}`, string(data))
+}
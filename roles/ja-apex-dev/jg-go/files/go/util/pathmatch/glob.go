@@ -0,0 +1,85 @@
+package pathmatch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// GlobOption configures Glob.
+type GlobOption func(*globSettings)
+
+type globSettings struct {
+	ignore *Ignore
+	dirs   bool
+}
+
+// WithIgnore skips the paths excluded by ignore, without descending into
+// ignored directories.
+func WithIgnore(ignore *Ignore) GlobOption {
+	return func(s *globSettings) {
+		s.ignore = ignore
+	}
+}
+
+// WithDirs includes matching directories, which are left out by default.
+func WithDirs() GlobOption {
+	return func(s *globSettings) {
+		s.dirs = true
+	}
+}
+
+// Glob returns the files of fsys matching pattern, sorted lexically. Only
+// the directories below the literal prefix of the pattern are walked, and
+// a missing prefix yields no matches rather than an error.
+func Glob(fsys fs.FS, pattern string, opts ...GlobOption) ([]string, error) {
+	p, err := Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var s globSettings
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	g := &globber{globSettings: s, pattern: p}
+	if err := fs.WalkDir(fsys, p.literalPrefix(), g.visit); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(g.matches)
+
+	return g.matches, nil
+}
+
+// globber collects the matches of one Glob call.
+type globber struct {
+	globSettings
+
+	pattern *Pattern
+	matches []string
+}
+
+// visit records name if it matches, pruning ignored directories.
+func (g *globber) visit(name string, entry fs.DirEntry, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("pathmatch: %w", err)
+	case entry.IsDir() && g.ignore.MatchDir(name):
+		return fs.SkipDir
+	case entry.IsDir() && !g.dirs:
+		return nil
+	case !entry.IsDir() && g.ignore.Match(name):
+		return nil
+	}
+
+	if g.pattern.Match(name) {
+		g.matches = append(g.matches, name)
+	}
+
+	return nil
+}
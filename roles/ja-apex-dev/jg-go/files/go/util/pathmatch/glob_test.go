@@ -0,0 +1,66 @@
+package pathmatch_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/pathmatch"
+)
+
+func tree() fstest.MapFS {
+	return fstest.MapFS{
+		"go.mod":                 {},
+		"main.go":                {},
+		"cmd/app/main.go":        {},
+		"cmd/app/main_test.go":   {},
+		"util/util.go":           {},
+		"util/testdata/x.golden": {},
+		"vendor/dep/dep.go":      {},
+		"dist/app":               {},
+	}
+}
+
+func TestGlob(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"**/*.go": {
+			"cmd/app/main.go", "cmd/app/main_test.go", "main.go",
+			"util/util.go", "vendor/dep/dep.go",
+		},
+		"cmd/**/*_test.go":     {"cmd/app/main_test.go"},
+		"{go.mod,dist/*}":      {"dist/app", "go.mod"},
+		"*.{go,mod}":           {"go.mod", "main.go"},
+		"missing/**":           nil,
+		"util/testdata/x.gold": nil,
+	}
+	for pattern, want := range cases {
+		got, err := pathmatch.Glob(tree(), pattern)
+		require.NoError(t, err, pattern)
+		assert.Equal(t, want, got, pattern)
+	}
+}
+
+func TestGlobOptions(t *testing.T) {
+	t.Parallel()
+
+	ignore, err := pathmatch.NewIgnore("vendor/", "*_test.go", "dist")
+	require.NoError(t, err)
+
+	got, err := pathmatch.Glob(tree(), "**", pathmatch.WithIgnore(ignore))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"cmd/app/main.go", "go.mod", "main.go", "util/testdata/x.golden",
+		"util/util.go",
+	}, got)
+
+	got, err = pathmatch.Glob(tree(), "cmd/*", pathmatch.WithDirs())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cmd/app"}, got)
+
+	_, err = pathmatch.Glob(tree(), "[")
+	require.ErrorIs(t, err, pathmatch.ErrBadPattern)
+}
@@ -0,0 +1,167 @@
+package pathmatch
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Ignore holds .gitignore-style rules. Paths are slash-separated and
+// relative to the directory of the rules; nested ignore files are not
+// merged. A nil Ignore excludes nothing.
+type Ignore struct {
+	// rules holds every rule, applying to directories.
+	rules []ignoreRule
+	// fileRules leaves out the rules with a trailing slash.
+	fileRules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern *Pattern
+	negate  bool
+}
+
+// NewIgnore builds rules from the lines of an ignore file.
+func NewIgnore(lines ...string) (*Ignore, error) {
+	ignore := &Ignore{}
+
+	for i, line := range lines {
+		if err := ignore.add(line); err != nil {
+			return nil, fmt.Errorf("%w (line %d)", err, i+1)
+		}
+	}
+
+	return ignore, nil
+}
+
+// ParseIgnore reads the rules of an ignore file from r.
+func ParseIgnore(r io.Reader) (*Ignore, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("pathmatch: %w", err)
+	}
+
+	return NewIgnore(lines...)
+}
+
+// LoadIgnore reads the rules of the ignore file name in fsys, such as
+// ".gitignore". A missing file yields empty rules.
+func LoadIgnore(fsys fs.FS, name string) (*Ignore, error) {
+	file, err := fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Ignore{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("pathmatch: %w", err)
+	}
+	defer file.Close()
+
+	ignore, err := ParseIgnore(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return ignore, nil
+}
+
+// add parses a line: blank lines and comments are skipped, "!" re-includes
+// paths, a trailing slash restricts the rule to directories, and a slash
+// elsewhere anchors it to the root instead of matching at any depth.
+func (ig *Ignore) add(line string) error {
+	line = trimTrailingSpaces(line)
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+
+	var rule ignoreRule
+
+	if line[0] == '!' {
+		rule.negate = true
+		line = line[1:]
+	}
+
+	body, dirOnly := strings.CutSuffix(line, "/")
+
+	body, anchored := strings.CutPrefix(body, "/")
+	if !anchored && !strings.Contains(body, "/") {
+		body = doubleStar + "/" + body
+	}
+
+	pattern, err := Compile(body)
+	if err != nil {
+		return err
+	}
+
+	rule.pattern = pattern
+
+	ig.rules = append(ig.rules, rule)
+	if !dirOnly {
+		ig.fileRules = append(ig.fileRules, rule)
+	}
+
+	return nil
+}
+
+// Match reports whether the file name is excluded, either by a rule or
+// because a parent directory is. As with git, a file in an excluded
+// directory cannot be re-included.
+func (ig *Ignore) Match(name string) bool {
+	if ig == nil {
+		return false
+	}
+
+	return ig.parentExcluded(name) || excluded(name, ig.fileRules)
+}
+
+// MatchDir reports whether the directory name is excluded.
+func (ig *Ignore) MatchDir(name string) bool {
+	if ig == nil {
+		return false
+	}
+
+	return ig.parentExcluded(name) || excluded(name, ig.rules)
+}
+
+func (ig *Ignore) parentExcluded(name string) bool {
+	for i := range len(name) {
+		if name[i] == '/' && excluded(name[:i], ig.rules) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// excluded applies rules in order, the last matching one winning.
+func excluded(name string, rules []ignoreRule) bool {
+	result := false
+
+	for _, rule := range rules {
+		if rule.pattern.Match(name) {
+			result = !rule.negate
+		}
+	}
+
+	return result
+}
+
+// trimTrailingSpaces removes trailing spaces unless escaped with a
+// backslash.
+func trimTrailingSpaces(line string) string {
+	trimmed := strings.TrimRight(line, " ")
+	if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+		return trimmed + " "
+	}
+
+	return trimmed
+}
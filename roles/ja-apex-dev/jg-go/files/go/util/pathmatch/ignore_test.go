@@ -0,0 +1,85 @@
+package pathmatch_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/pathmatch"
+)
+
+const gitignore = `
+# Build output
+/dist
+*.log
+!keep.log
+build/
+docs/**/*.html
+\#notes
+node_modules
+!node_modules/kept.js
+`
+
+func TestIgnore(t *testing.T) {
+	t.Parallel()
+
+	ignore, err := pathmatch.ParseIgnore(
+		strings.NewReader(gitignore + "trailing\\  \n"))
+	require.NoError(t, err)
+
+	files := map[string]bool{
+		"dist/app":                 true,
+		"src/dist/app":             false,
+		"debug.log":                true,
+		"logs/debug.log":           true,
+		"keep.log":                 false,
+		"build/out.o":              true,
+		"src/build/out.o":          true,
+		"build":                    false,
+		"docs/a/b/index.html":      true,
+		"docs/index.html":          true,
+		"site/docs/index.html":     false,
+		"#notes":                   true,
+		"trailing ":                true,
+		"node_modules/kept.js":     true,
+		"web/node_modules/pkg.js":  true,
+		"src/main.go":              false,
+		"# Build output":           false,
+		"dist2/app":                false,
+		"logs/debug.log.gz":        false,
+		"docs/a/b/index.html.orig": false,
+	}
+	for name, want := range files {
+		assert.Equal(t, want, ignore.Match(name), name)
+	}
+
+	assert.True(t, ignore.MatchDir("build"))
+	assert.True(t, ignore.MatchDir("src/build"))
+	assert.False(t, ignore.MatchDir("src"))
+}
+
+func TestIgnoreNil(t *testing.T) {
+	t.Parallel()
+
+	var ignore *pathmatch.Ignore
+
+	assert.False(t, ignore.Match("any"))
+	assert.False(t, ignore.MatchDir("any"))
+}
+
+func TestLoadIgnore(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{".gitignore": {Data: []byte("*.tmp\n[\n")}}
+
+	_, err := pathmatch.LoadIgnore(fsys, ".gitignore")
+	require.ErrorIs(t, err, pathmatch.ErrBadPattern)
+	assert.ErrorContains(t, err, "line 2")
+
+	ignore, err := pathmatch.LoadIgnore(fsys, ".dockerignore")
+	require.NoError(t, err)
+	assert.False(t, ignore.Match("a.tmp"))
+}
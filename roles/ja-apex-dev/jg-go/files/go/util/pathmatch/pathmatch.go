@@ -0,0 +1,220 @@
+// Package pathmatch matches slash-separated paths against glob patterns
+// extending path.Match with "**" segments, which match any number of
+// directories, and shell-style brace expansion:
+//
+//	pathmatch.Match("src/**/*.{go,mod}", "src/cmd/app/main.go") // true
+//
+// Glob lists the matches of a pattern in a file system in lexical order,
+// optionally skipping the paths excluded by .gitignore-style rules parsed
+// with ParseIgnore.
+package pathmatch
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// doubleStar is the segment matching zero or more path segments.
+const doubleStar = "**"
+
+// ErrBadPattern is returned for malformed patterns.
+var ErrBadPattern = errors.New("pathmatch: syntax error in pattern")
+
+// Pattern is a compiled glob pattern. It is safe for concurrent use.
+type Pattern struct {
+	source       string
+	alternatives [][]string
+}
+
+// Compile parses pattern. Brace groups are expanded, and every segment
+// must be valid path.Match syntax or "**".
+func Compile(pattern string) (*Pattern, error) {
+	p := &Pattern{source: pattern}
+
+	for _, expanded := range ExpandBraces(pattern) {
+		segments := strings.Split(expanded, "/")
+		for _, segment := range segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("%w: %q", ErrBadPattern, pattern)
+			}
+		}
+
+		p.alternatives = append(p.alternatives, compact(segments))
+	}
+
+	return p, nil
+}
+
+// MustCompile is like Compile but panics on malformed patterns. It
+// simplifies the initialization of global patterns.
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Match reports whether name matches pattern, which is compiled on every
+// call; prefer Compile for repeated matches.
+func Match(pattern, name string) (bool, error) {
+	p, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return p.Match(name), nil
+}
+
+// String returns the source of the pattern.
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Match reports whether the slash-separated name matches the pattern.
+func (p *Pattern) Match(name string) bool {
+	segments := strings.Split(name, "/")
+
+	return slices.ContainsFunc(p.alternatives, func(pattern []string) bool {
+		return matchSegments(pattern, segments)
+	})
+}
+
+// literalPrefix returns the leading directories shared by every
+// alternative that contain no wildcards, or "." when there are none.
+func (p *Pattern) literalPrefix() string {
+	var prefix []string
+
+	for i, alternative := range p.alternatives {
+		literal := alternative[:len(alternative)-1]
+		if n := slices.IndexFunc(literal, hasMeta); n >= 0 {
+			literal = literal[:n]
+		}
+
+		if i == 0 {
+			prefix = literal
+		}
+
+		n := 0
+		for n < min(len(prefix), len(literal)) && prefix[n] == literal[n] {
+			n++
+		}
+
+		prefix = prefix[:n]
+	}
+
+	if len(prefix) == 0 {
+		return "."
+	}
+
+	return path.Join(prefix...)
+}
+
+// matchSegments matches name segment per segment up to the first "**".
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 && pattern[0] != doubleStar {
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	return matchDoubleStar(pattern[1:], name)
+}
+
+// matchDoubleStar lets a "**" segment absorb any number of leading
+// segments of name before matching the rest of the pattern.
+func matchDoubleStar(rest, name []string) bool {
+	for skip := range len(name) + 1 {
+		if matchSegments(rest, name[skip:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compact collapses consecutive "**" segments, which match the same paths
+// as one but multiply the backtracking of matchSegments.
+func compact(segments []string) []string {
+	return slices.CompactFunc(segments, func(a, b string) bool {
+		return a == doubleStar && b == doubleStar
+	})
+}
+
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
+
+// ExpandBraces expands the brace groups of pattern, as a shell would:
+// "{a,b}/*.{go,md}" yields "a/*.go", "a/*.md", "b/*.go" and "b/*.md".
+// Groups may nest. Braces without a comma, unbalanced or escaped with a
+// backslash are kept literally.
+func ExpandBraces(pattern string) []string {
+	for start := 0; start < len(pattern); start++ {
+		switch pattern[start] {
+		case '\\':
+			start++
+		case '{':
+			end, alternatives := braceGroup(pattern, start)
+			if len(alternatives) < 2 {
+				continue
+			}
+
+			var expanded []string
+
+			for _, alternative := range alternatives {
+				expanded = append(expanded, ExpandBraces(
+					pattern[:start]+alternative+pattern[end+1:])...)
+			}
+
+			return expanded
+		}
+	}
+
+	return []string{pattern}
+}
+
+// braceGroup returns the index of the brace closing the group opened at
+// start, and the alternatives separated by top-level commas. It returns no
+// alternatives for unbalanced groups.
+func braceGroup(pattern string, start int) (int, []string) {
+	depth := 0
+	from := start + 1
+
+	var alternatives []string
+
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[from:i])
+				from = i + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, append(alternatives, pattern[from:i])
+			}
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,68 @@
+package pathmatch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/pathmatch"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/app/main.go", true},
+		{"src/**", "src/a/b", true},
+		{"src/**", "src", true},
+		{"src/**/test", "src/test", true},
+		{"src/**/test", "src/a/b/test", true},
+		{"src/**/test", "src/a/b/test/x", false},
+		{"a/**/**/b", "a/x/b", true},
+		{"*.{go,mod}", "go.mod", true},
+		{"*.{go,mod}", "go.sum", false},
+		{"{cmd,internal/**}/*.go", "internal/x/y.go", true},
+		{"file?.[ch]", "file1.h", true},
+		{`\{a,b\}`, "{a,b}", true},
+		{"", "", true},
+	}
+	for _, c := range cases {
+		got, err := pathmatch.Match(c.pattern, c.name)
+		require.NoError(t, err, c.pattern)
+		assert.Equal(t, c.want, got, "%s ~ %s", c.pattern, c.name)
+	}
+}
+
+func TestCompileBadPattern(t *testing.T) {
+	t.Parallel()
+
+	_, err := pathmatch.Compile("src/[a-")
+	require.ErrorIs(t, err, pathmatch.ErrBadPattern)
+
+	assert.Panics(t, func() { pathmatch.MustCompile("{a,[}") })
+	assert.Equal(t, "**/*.go", pathmatch.MustCompile("**/*.go").String())
+}
+
+func TestExpandBraces(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"{a,b}/*.{go,md}": {"a/*.go", "a/*.md", "b/*.go", "b/*.md"},
+		"x{1,{2,3}}y":     {"x1y", "x2y", "x3y"},
+		"{,pre}fix":       {"fix", "prefix"},
+		"{single}":        {"{single}"},
+		"{open,":          {"{open,"},
+		`\{a,b}`:          {`\{a,b}`},
+		"plain":           {"plain"},
+	}
+	for pattern, want := range cases {
+		assert.Equal(t, want, pathmatch.ExpandBraces(pattern), pattern)
+	}
+}
@@ -0,0 +1,115 @@
+package pkgmgr
+
+import "strings"
+
+// backend describes the commands of a package manager.
+type backend struct {
+	name string
+	// binary is looked up in PATH by Detect.
+	binary  string
+	install []string
+	remove  []string
+	// query returns the command printing the installed version of pkg,
+	// which fails or prints something parse rejects when it is missing.
+	query func(pkg string) []string
+	parse func(stdout string) (string, bool)
+}
+
+// debianFrontend keeps debconf from prompting during apt operations.
+const debianFrontend = "DEBIAN_FRONTEND=noninteractive"
+
+var (
+	apt = backend{
+		name:    "apt",
+		binary:  "apt-get",
+		install: []string{"env", debianFrontend, "apt-get", "install", "-y"},
+		remove:  []string{"env", debianFrontend, "apt-get", "remove", "-y"},
+		query: func(pkg string) []string {
+			return []string{"dpkg-query", "-W",
+				"-f=${db:Status-Status} ${Version}", pkg}
+		},
+		parse: parseDpkg,
+	}
+	dnf = backend{
+		name:    "dnf",
+		binary:  "dnf",
+		install: []string{"dnf", "install", "-y"},
+		remove:  []string{"dnf", "remove", "-y"},
+		query: func(pkg string) []string {
+			return []string{"rpm", "-q", "--qf", "%{VERSION}-%{RELEASE}\n",
+				pkg}
+		},
+		parse: firstLine,
+	}
+	pacman = backend{
+		name:    "pacman",
+		binary:  "pacman",
+		install: []string{"pacman", "-S", "--needed", "--noconfirm"},
+		remove:  []string{"pacman", "-R", "--noconfirm"},
+		query: func(pkg string) []string {
+			return []string{"pacman", "-Q", pkg}
+		},
+		parse: lastField,
+	}
+	brew = backend{
+		name:    "brew",
+		binary:  "brew",
+		install: []string{"brew", "install"},
+		remove:  []string{"brew", "uninstall"},
+		query: func(pkg string) []string {
+			return []string{"brew", "list", "--versions", pkg}
+		},
+		parse: lastField,
+	}
+)
+
+// backends are tried in order by Detect. Homebrew comes last since it is
+// also found on Linux next to the system package manager.
+var backends = []backend{apt, dnf, pacman, brew}
+
+// Apt returns the manager of Debian and Ubuntu.
+func Apt(opts ...Option) Manager {
+	return newManager(apt, opts)
+}
+
+// Dnf returns the manager of Fedora and RHEL.
+func Dnf(opts ...Option) Manager {
+	return newManager(dnf, opts)
+}
+
+// Pacman returns the manager of Arch Linux.
+func Pacman(opts ...Option) Manager {
+	return newManager(pacman, opts)
+}
+
+// Brew returns the Homebrew manager.
+func Brew(opts ...Option) Manager {
+	return newManager(brew, opts)
+}
+
+// parseDpkg reads "installed 1.2-3"; packages that were removed but keep
+// their configuration files report another status.
+func parseDpkg(stdout string) (string, bool) {
+	status, version, _ := strings.Cut(strings.TrimSpace(stdout), " ")
+
+	return version, status == "installed" && version != ""
+}
+
+// firstLine returns the first line of the output, as rpm prints one per
+// installed architecture or version of a package.
+func firstLine(stdout string) (string, bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(stdout), "\n")
+
+	return line, line != ""
+}
+
+// lastField returns the last word of the output, the newest version for
+// Homebrew, which lists every installed one.
+func lastField(stdout string) (string, bool) {
+	fields := strings.Fields(stdout)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	return fields[len(fields)-1], true
+}
@@ -0,0 +1,63 @@
+package pkgmgr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/pkgmgr"
+)
+
+func TestBackends(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		manager func(...pkgmgr.Option) pkgmgr.Manager
+		query   string
+		output  string
+		install string
+	}{
+		{
+			pkgmgr.Apt, "dpkg-query -W -f=${db:Status-Status} ${Version} git",
+			"installed 1:2.43.0-1\n",
+			"env DEBIAN_FRONTEND=noninteractive apt-get install -y git",
+		},
+		{
+			pkgmgr.Dnf, "rpm -q --qf %{VERSION}-%{RELEASE}\n git",
+			"1:2.43.0-1\n1:2.43.0-1\n", "dnf install -y git",
+		},
+		{
+			pkgmgr.Pacman, "pacman -Q git", "git 1:2.43.0-1\n",
+			"pacman -S --needed --noconfirm git",
+		},
+		{
+			pkgmgr.Brew, "brew list --versions git", "git 1:2.43.0-1\n",
+			"brew install git",
+		},
+	}
+
+	for _, c := range cases {
+		f := &fake{outputs: map[string]string{c.query: c.output}}
+		m := c.manager(pkgmgr.WithRunner(f))
+
+		version, err := m.Version(t.Context(), "git")
+		require.NoError(t, err, m.Name())
+		assert.Equal(t, "1:2.43.0-1", version, m.Name())
+
+		f.outputs = map[string]string{c.install: ""}
+		require.NoError(t, m.Install(t.Context(), "git"), m.Name())
+		assert.Equal(t, c.install, f.calls[len(f.calls)-1], m.Name())
+	}
+}
+
+func TestApt(t *testing.T) {
+	t.Parallel()
+
+	query := "dpkg-query -W -f=${db:Status-Status} ${Version} vim"
+	f := &fake{outputs: map[string]string{query: "config-files 2:9.1-1"}}
+
+	ok, err := pkgmgr.Apt(pkgmgr.WithRunner(f)).IsInstalled(t.Context(), "vim")
+	require.NoError(t, err)
+	assert.False(t, ok, "removed packages keeping their configuration")
+}
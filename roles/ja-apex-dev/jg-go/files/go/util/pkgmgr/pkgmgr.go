@@ -0,0 +1,201 @@
+// Package pkgmgr installs, removes and queries system packages through
+// apt, dnf, pacman or Homebrew behind a common interface, so that setup
+// helpers shipped in roles/ work across distributions. Installs and
+// removals skip packages already in the requested state and can be dry
+// runs that only print the commands.
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"example.com/go-template/util/execx"
+)
+
+var (
+	// ErrNotInstalled is returned by Version for missing packages.
+	ErrNotInstalled = errors.New("pkgmgr: package not installed")
+	// ErrUnsupported is returned by Detect when no known package manager
+	// is available.
+	ErrUnsupported = errors.New("pkgmgr: no supported package manager")
+)
+
+// Manager is a system package manager.
+type Manager interface {
+	// Name returns the name of the package manager, such as "apt".
+	Name() string
+	// Install installs the packages that are missing.
+	Install(ctx context.Context, pkgs ...string) error
+	// Remove removes the packages that are installed.
+	Remove(ctx context.Context, pkgs ...string) error
+	// IsInstalled reports whether pkg is installed.
+	IsInstalled(ctx context.Context, pkg string) (bool, error)
+	// Version returns the installed version of pkg or ErrNotInstalled.
+	Version(ctx context.Context, pkg string) (string, error)
+}
+
+// Runner executes commands; *execx.Runner implements it.
+type Runner interface {
+	// Run executes name with args and waits for it.
+	Run(ctx context.Context, name string, args ...string) (execx.Result, error)
+}
+
+// Option configures a Manager.
+type Option func(*settings)
+
+type settings struct {
+	runner Runner
+	dryRun io.Writer
+}
+
+// WithRunner runs the commands with r, for instance an execx runner with
+// sudo elevation, a timeout or a logger. Homebrew refuses to run as root,
+// so do not elevate it.
+func WithRunner(r Runner) Option {
+	return func(s *settings) {
+		s.runner = r
+	}
+}
+
+// WithDryRun prints the install and remove commands to w, one per line,
+// instead of running them. Queries still run since they change nothing.
+func WithDryRun(w io.Writer) Option {
+	return func(s *settings) {
+		s.dryRun = w
+	}
+}
+
+// Detect returns the manager of the first package manager found in PATH,
+// trying apt, dnf, pacman and brew in that order.
+func Detect(opts ...Option) (Manager, error) {
+	for _, b := range backends {
+		if _, err := exec.LookPath(b.binary); err == nil {
+			return newManager(b, opts), nil
+		}
+	}
+
+	return nil, ErrUnsupported
+}
+
+// manager implements Manager on top of a backend.
+type manager struct {
+	backend
+	settings
+}
+
+func newManager(b backend, opts []Option) *manager {
+	s := settings{runner: execx.New()}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &manager{b, s}
+}
+
+// Name implements Manager.
+func (m *manager) Name() string {
+	return m.name
+}
+
+// Install implements Manager.
+func (m *manager) Install(ctx context.Context, pkgs ...string) error {
+	_, missing, err := m.partition(ctx, pkgs)
+	if err != nil {
+		return err
+	}
+
+	return m.run(ctx, m.install, missing)
+}
+
+// Remove implements Manager.
+func (m *manager) Remove(ctx context.Context, pkgs ...string) error {
+	installed, _, err := m.partition(ctx, pkgs)
+	if err != nil {
+		return err
+	}
+
+	return m.run(ctx, m.remove, installed)
+}
+
+// IsInstalled implements Manager.
+func (m *manager) IsInstalled(ctx context.Context, pkg string) (bool, error) {
+	_, err := m.Version(ctx, pkg)
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrNotInstalled):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Version implements Manager.
+func (m *manager) Version(ctx context.Context, pkg string) (string, error) {
+	argv := m.query(pkg)
+
+	result, err := m.runner.Run(ctx, argv[0], argv[1:]...)
+
+	var exitErr *execx.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("%w: %s", ErrNotInstalled, pkg)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("pkgmgr: %s: %w", m.name, err)
+	}
+
+	version, ok := m.parse(result.Stdout)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotInstalled, pkg)
+	}
+
+	return version, nil
+}
+
+// partition splits pkgs into the installed and the missing ones.
+func (m *manager) partition(
+	ctx context.Context, pkgs []string,
+) (installed, missing []string, err error) {
+	for _, pkg := range pkgs {
+		ok, err := m.IsInstalled(ctx, pkg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if ok {
+			installed = append(installed, pkg)
+		} else {
+			missing = append(missing, pkg)
+		}
+	}
+
+	return installed, missing, nil
+}
+
+// run runs argv followed by pkgs, or prints it in dry-run mode, doing
+// nothing when there are no packages.
+func (m *manager) run(ctx context.Context, argv, pkgs []string) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	argv = append(argv[:len(argv):len(argv)], pkgs...)
+
+	if m.dryRun != nil {
+		_, err := fmt.Fprintln(m.dryRun, strings.Join(argv, " "))
+
+		return err
+	}
+
+	if _, err := m.runner.Run(ctx, argv[0], argv[1:]...); err != nil {
+		return fmt.Errorf("pkgmgr: %s: %w", m.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package pkgmgr_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/pkgmgr"
+)
+
+// fake answers commands from a table keyed by the command line and
+// records them; unknown commands exit with status 1.
+type fake struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (f *fake) Run(
+	_ context.Context, name string, args ...string,
+) (execx.Result, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, line)
+
+	if out, ok := f.outputs[line]; ok {
+		return execx.Result{Stdout: out}, nil
+	}
+
+	result := execx.Result{ExitCode: 1}
+
+	return result, &execx.ExitError{Command: line, Result: result}
+}
+
+func TestInstall(t *testing.T) {
+	t.Parallel()
+
+	f := &fake{outputs: map[string]string{
+		"pacman -Q git":                     "git 2.46.0-1\n",
+		"pacman -S --needed --noconfirm jq": "",
+	}}
+	m := pkgmgr.Pacman(pkgmgr.WithRunner(f))
+
+	require.NoError(t, m.Install(t.Context(), "git", "jq"))
+	assert.Equal(t, "pacman -S --needed --noconfirm jq", f.calls[2])
+
+	f.calls = nil
+	require.NoError(t, m.Remove(t.Context(), "jq"))
+	assert.Equal(t, []string{"pacman -Q jq"}, f.calls, "nothing to remove")
+}
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+
+	f := &fake{outputs: map[string]string{
+		"brew list --versions wget": "wget 1.21 1.24.5\n",
+	}}
+
+	var out strings.Builder
+
+	m := pkgmgr.Brew(pkgmgr.WithRunner(f), pkgmgr.WithDryRun(&out))
+
+	require.NoError(t, m.Install(t.Context(), "wget", "fd"))
+	require.NoError(t, m.Remove(t.Context(), "wget"))
+	assert.Equal(t, "brew install fd\nbrew uninstall wget\n", out.String())
+	assert.Len(t, f.calls, 3, "only queries run")
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	f := &fake{outputs: map[string]string{
+		"brew list --versions wget": "wget 1.21 1.24.5\n",
+		"brew list --versions fd":   "",
+	}}
+	m := pkgmgr.Brew(pkgmgr.WithRunner(f))
+
+	version, err := m.Version(t.Context(), "wget")
+	require.NoError(t, err)
+	assert.Equal(t, "1.24.5", version)
+
+	_, err = m.Version(t.Context(), "fd")
+	require.ErrorIs(t, err, pkgmgr.ErrNotInstalled)
+
+	ok, err := m.IsInstalled(t.Context(), "rg")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("boom")
+	m := pkgmgr.Dnf(pkgmgr.WithRunner(runnerFunc(func() error {
+		return failure
+	})))
+
+	_, err := m.IsInstalled(t.Context(), "git")
+	require.ErrorIs(t, err, failure)
+	require.ErrorIs(t, m.Install(t.Context(), "git"), failure)
+	assert.Equal(t, "dnf", m.Name())
+}
+
+type runnerFunc func() error
+
+func (f runnerFunc) Run(
+	context.Context, string, ...string,
+) (execx.Result, error) {
+	return execx.Result{}, f()
+}
@@ -0,0 +1,44 @@
+package platform
+
+import "strings"
+
+// arches maps the architecture names of uname, distributions and
+// download pages to GOARCH.
+var arches = map[string]string{
+	"x86_64": "amd64", "x64": "amd64", "x86-64": "amd64",
+	"aarch64": "arm64", "armv8": "arm64", "armv8l": "arm64",
+	"i386": "386", "i486": "386", "i586": "386", "i686": "386", "x86": "386",
+	"armv7l": "arm", "armv7": "arm", "armv6l": "arm", "armhf": "arm",
+	"armel":     "arm",
+	"ppc64el":   "ppc64le",
+	"riscv64gc": "riscv64",
+}
+
+// machines maps GOARCH to the names reported by uname -m.
+var machines = map[string]string{
+	"amd64": "x86_64", "arm64": "aarch64", "386": "i386", "arm": "armv7l",
+}
+
+// NormalizeArch returns the GOARCH name of the architecture arch, as
+// "amd64" for "x86_64" and "arm64" for "aarch64". Unknown names are only
+// lowercased.
+func NormalizeArch(arch string) string {
+	arch = strings.ToLower(strings.TrimSpace(arch))
+	if goarch, ok := arches[arch]; ok {
+		return goarch
+	}
+
+	return arch
+}
+
+// Machine returns the uname -m name of the architecture arch, as "x86_64"
+// for "amd64" and "aarch64" for "arm64". It accepts any name known to
+// NormalizeArch.
+func Machine(arch string) string {
+	arch = NormalizeArch(arch)
+	if machine, ok := machines[arch]; ok {
+		return machine
+	}
+
+	return arch
+}
@@ -0,0 +1,28 @@
+package platform_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/platform"
+)
+
+func TestNormalizeArch(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"x86_64": "amd64", "AMD64": "amd64", "aarch64": "arm64",
+		"arm64": "arm64", "i686": "386", "armv7l": "arm",
+		"ppc64el": "ppc64le", " s390x\n": "s390x", "Mips": "mips",
+	}
+
+	for arch, want := range cases {
+		assert.Equal(t, want, platform.NormalizeArch(arch), arch)
+	}
+
+	assert.Equal(t, "x86_64", platform.Machine("amd64"))
+	assert.Equal(t, "aarch64", platform.Machine("aarch64"))
+	assert.Equal(t, "i386", platform.Machine("i686"))
+	assert.Equal(t, "riscv64", platform.Machine("riscv64"))
+}
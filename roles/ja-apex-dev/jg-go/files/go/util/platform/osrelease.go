@@ -0,0 +1,106 @@
+package platform
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// osReleasePaths are the locations of os-release, in order of precedence.
+var osReleasePaths = []string{"etc/os-release", "usr/lib/os-release"}
+
+// OSRelease holds the variables of an os-release file, such as ID and
+// VERSION_ID.
+type OSRelease map[string]string
+
+// Like returns the space-separated identifiers of ID_LIKE.
+func (r OSRelease) Like() []string {
+	return strings.Fields(r["ID_LIKE"])
+}
+
+// ParseOSRelease parses an os-release file as described in os-release(5):
+// shell-like assignments whose values may be quoted. Comments, blank lines
+// and lines that are not assignments are ignored.
+func ParseOSRelease(r io.Reader) (OSRelease, error) {
+	release := make(OSRelease)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+
+		release[key] = unquote(value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("platform: os-release: %w", err)
+	}
+
+	return release, nil
+}
+
+// unquote returns the value of an assignment: single-quoted values are
+// literal, and double-quoted ones may escape $, ", \ and ` with a
+// backslash.
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != value[len(value)-1] {
+		return value
+	}
+
+	switch value[0] {
+	case '\'':
+		return value[1 : len(value)-1]
+	case '"':
+		return unescape(value[1 : len(value)-1])
+	default:
+		return value
+	}
+}
+
+func unescape(value string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) &&
+			strings.IndexByte("$\"\\`", value[i+1]) >= 0 {
+			i++
+		}
+
+		b.WriteByte(value[i])
+	}
+
+	return b.String()
+}
+
+// osRelease reads the first os-release file found below the root, or
+// returns an empty release when there is none.
+func (s *settings) osRelease() (OSRelease, error) {
+	for _, name := range osReleasePaths {
+		f, err := os.Open(s.path(name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("platform: %w", err)
+		}
+
+		release, err := ParseOSRelease(f)
+		f.Close()
+
+		return release, err
+	}
+
+	return OSRelease{}, nil
+}
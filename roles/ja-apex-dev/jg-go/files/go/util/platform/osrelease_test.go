@@ -0,0 +1,39 @@
+package platform_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/platform"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	t.Parallel()
+
+	release, err := platform.ParseOSRelease(strings.NewReader(`
+# Comment
+NAME="Arch Linux"
+ID=arch
+BUILD_ID=rolling
+ANSI_COLOR='38;2;23;147;209'
+QUOTED="say \"hi\" for \$5 \\ \n"
+not an assignment
+HALF="open
+ID_LIKE=""
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, platform.OSRelease{
+		"NAME":       "Arch Linux",
+		"ID":         "arch",
+		"BUILD_ID":   "rolling",
+		"ANSI_COLOR": "38;2;23;147;209",
+		"QUOTED":     `say "hi" for $5 \ \n`,
+		"HALF":       `"open`,
+		"ID_LIKE":    "",
+	}, release)
+	assert.Empty(t, release.Like())
+}
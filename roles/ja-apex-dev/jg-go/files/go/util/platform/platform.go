@@ -0,0 +1,188 @@
+// Package platform detects the platform a helper runs on: the OS family,
+// the distribution and its version from /etc/os-release, the normalized
+// architecture, WSL and containers. Families use the names of the
+// ansible_os_family fact, so that Go helpers shipped in roles/ branch the
+// same way as the YAML tasks next to them:
+//
+//	info, err := platform.Detect()
+//	if err != nil {
+//		return err
+//	}
+//
+//	if info.Family == platform.FamilyArch && !info.Container() { ... }
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// Family is an OS family, named as the ansible_os_family fact.
+type Family string
+
+// Families of the supported platforms.
+const (
+	FamilyUnknown Family = ""
+	FamilyDebian  Family = "Debian"
+	FamilyRedHat  Family = "RedHat"
+	FamilyArch    Family = "Archlinux"
+	FamilySuse    Family = "Suse"
+	FamilyAlpine  Family = "Alpine"
+	FamilyGentoo  Family = "Gentoo"
+	FamilyDarwin  Family = "Darwin"
+	FamilyFreeBSD Family = "FreeBSD"
+	FamilyWindows Family = "Windows"
+)
+
+// families maps os-release identifiers to their family.
+var families = map[string]Family{
+	"debian": FamilyDebian, "ubuntu": FamilyDebian, "linuxmint": FamilyDebian,
+	"pop": FamilyDebian, "raspbian": FamilyDebian, "kali": FamilyDebian,
+	"rhel": FamilyRedHat, "fedora": FamilyRedHat, "centos": FamilyRedHat,
+	"rocky": FamilyRedHat, "almalinux": FamilyRedHat, "ol": FamilyRedHat,
+	"amzn": FamilyRedHat,
+	"arch": FamilyArch, "manjaro": FamilyArch, "endeavouros": FamilyArch,
+	"artix": FamilyArch,
+	"suse":  FamilySuse, "opensuse": FamilySuse, "sles": FamilySuse,
+	"opensuse-leap": FamilySuse, "opensuse-tumbleweed": FamilySuse,
+	"alpine": FamilyAlpine,
+	"gentoo": FamilyGentoo,
+}
+
+// kernels maps the GOOS of platforms other than Linux to their family.
+var kernels = map[string]Family{
+	"darwin": FamilyDarwin, "freebsd": FamilyFreeBSD, "windows": FamilyWindows,
+}
+
+// Info describes a platform.
+type Info struct {
+	// OS is the operating system as in GOOS, such as "linux".
+	OS string `json:"os"`
+	// Family is the OS family, FamilyUnknown for unknown distributions.
+	Family Family `json:"family"`
+	// Distro is the os-release ID of the distribution, such as "ubuntu".
+	Distro string `json:"distro,omitempty"`
+	// Like lists the os-release ID_LIKE distributions.
+	Like []string `json:"like,omitempty"`
+	// Version is the os-release VERSION_ID, such as "24.04".
+	Version string `json:"version,omitempty"`
+	// Codename is the os-release VERSION_CODENAME, such as "noble".
+	Codename string `json:"codename,omitempty"`
+	// Name is the os-release PRETTY_NAME.
+	Name string `json:"name,omitempty"`
+	// Arch is the architecture as in GOARCH, such as "arm64".
+	Arch string `json:"arch"`
+	// WSL is set under the Windows Subsystem for Linux.
+	WSL bool `json:"wsl,omitempty"`
+	// Runtime names the container runtime, such as "docker", or is empty
+	// outside of containers.
+	Runtime string `json:"runtime,omitempty"`
+}
+
+// Container reports whether the platform is a container.
+func (i Info) Container() bool {
+	return i.Runtime != ""
+}
+
+// Machine returns the architecture as reported by uname -m and the
+// ansible_architecture fact, such as "x86_64".
+func (i Info) Machine() string {
+	return Machine(i.Arch)
+}
+
+// IsLike reports whether the distribution is id or derives from it.
+func (i Info) IsLike(id string) bool {
+	return i.Distro == id || slices.Contains(i.Like, id)
+}
+
+// Option configures Detect.
+type Option func(*settings)
+
+// LookupFunc looks up an environment variable, as os.LookupEnv.
+type LookupFunc func(key string) (string, bool)
+
+type settings struct {
+	root   string
+	goos   string
+	goarch string
+	lookup LookupFunc
+}
+
+// WithRoot reads the system files below dir instead of /, for instance
+// to inspect a mounted image or a fixture in tests.
+func WithRoot(dir string) Option {
+	return func(s *settings) {
+		s.root = dir
+	}
+}
+
+// WithPlatform detects goos and goarch instead of the current platform.
+func WithPlatform(goos, goarch string) Option {
+	return func(s *settings) {
+		s.goos, s.goarch = goos, goarch
+	}
+}
+
+// WithLookup overrides the environment lookup, mostly useful in tests.
+func WithLookup(lookup LookupFunc) Option {
+	return func(s *settings) {
+		s.lookup = lookup
+	}
+}
+
+// Detect detects the current platform. Missing system files only leave
+// the fields they provide empty; other read errors are returned.
+func Detect(opts ...Option) (Info, error) {
+	s := settings{
+		root: "/", goos: runtime.GOOS, goarch: runtime.GOARCH,
+		lookup: os.LookupEnv,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	info := Info{
+		OS: s.goos, Family: kernels[s.goos], Arch: NormalizeArch(s.goarch),
+	}
+	if s.goos != "linux" {
+		return info, nil
+	}
+
+	release, err := s.osRelease()
+	if err != nil {
+		return info, err
+	}
+
+	info.Distro, info.Like = release["ID"], release.Like()
+	info.Version = release["VERSION_ID"]
+	info.Codename = release["VERSION_CODENAME"]
+	info.Name = release["PRETTY_NAME"]
+	info.Family = familyOf(info.Distro, info.Like)
+
+	if info.WSL, err = s.wsl(); err != nil {
+		return info, err
+	}
+
+	info.Runtime, err = s.container()
+
+	return info, err
+}
+
+// familyOf returns the family of the distribution id, or of the first
+// known distribution it derives from.
+func familyOf(id string, like []string) Family {
+	for _, name := range append([]string{id}, like...) {
+		if family, ok := families[name]; ok {
+			return family
+		}
+	}
+
+	return FamilyUnknown
+}
+
+// path returns the path of the system file name below the root.
+func (s *settings) path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(name))
+}
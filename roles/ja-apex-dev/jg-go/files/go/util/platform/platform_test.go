@@ -0,0 +1,149 @@
+package platform_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/platform"
+	"example.com/go-template/util/testx"
+)
+
+// env returns a lookup of the variables in vars only.
+func env(vars map[string]string) platform.LookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+
+		return value, ok
+	}
+}
+
+// detectIn detects a Linux amd64 platform from files and vars.
+func detectIn(
+	t *testing.T, files, vars map[string]string,
+) platform.Info {
+	t.Helper()
+
+	info, err := platform.Detect(
+		platform.WithRoot(testx.TempDirWithFiles(t, files)),
+		platform.WithPlatform("linux", "x86_64"),
+		platform.WithLookup(env(vars)))
+	require.NoError(t, err)
+
+	return info
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	info := detectIn(t, map[string]string{
+		"etc/os-release": "NAME=\"Pop!_OS\"\nID=pop\n" +
+			"ID_LIKE=\"ubuntu debian\"\nVERSION_ID=\"22.04\"\n" +
+			"VERSION_CODENAME=jammy\nPRETTY_NAME=\"Pop!_OS 22.04 LTS\"\n",
+		"proc/sys/kernel/osrelease": "6.8.0-generic\n",
+		"proc/1/cgroup":             "0::/init.scope\n",
+	}, nil)
+
+	assert.Equal(t, platform.Info{
+		OS: "linux", Family: platform.FamilyDebian, Distro: "pop",
+		Like: []string{"ubuntu", "debian"}, Version: "22.04",
+		Codename: "jammy", Name: "Pop!_OS 22.04 LTS", Arch: "amd64",
+	}, info)
+	assert.Equal(t, "x86_64", info.Machine())
+	assert.True(t, info.IsLike("ubuntu"))
+	assert.False(t, info.IsLike("fedora"))
+	assert.False(t, info.Container())
+}
+
+func TestDetectFamilies(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]platform.Family{
+		"ID=arch\n":                           platform.FamilyArch,
+		"ID=rocky\nID_LIKE=\"rhel centos\"\n": platform.FamilyRedHat,
+		"ID=my-spin\nID_LIKE=fedora\n":        platform.FamilyRedHat,
+		"ID=\"opensuse-tumbleweed\"\n":        platform.FamilySuse,
+		"ID=nixos\n":                          platform.FamilyUnknown,
+	}
+
+	for release, want := range cases {
+		info := detectIn(t, map[string]string{"etc/os-release": release}, nil)
+		assert.Equal(t, want, info.Family, release)
+	}
+
+	info := detectIn(t, map[string]string{
+		"usr/lib/os-release": "ID=alpine\nVERSION_ID=3.20.1\n",
+	}, nil)
+	assert.Equal(t, platform.FamilyAlpine, info.Family)
+	assert.Equal(t, "3.20.1", info.Version)
+
+	info = detectIn(t, map[string]string{"README": "no os-release\n"}, nil)
+	assert.Equal(t, platform.FamilyUnknown, info.Family)
+	assert.Empty(t, info.Distro)
+}
+
+func TestDetectOtherOS(t *testing.T) {
+	t.Parallel()
+
+	info, err := platform.Detect(
+		platform.WithRoot(t.TempDir()),
+		platform.WithPlatform("darwin", "arm64"),
+		platform.WithLookup(env(map[string]string{"container": "docker"})))
+	require.NoError(t, err)
+
+	assert.Equal(t, platform.Info{
+		OS: "darwin", Family: platform.FamilyDarwin, Arch: "arm64",
+	}, info)
+	assert.Equal(t, "aarch64", info.Machine())
+}
+
+func TestDetectWSL(t *testing.T) {
+	t.Parallel()
+
+	info := detectIn(t, map[string]string{
+		"proc/sys/kernel/osrelease": "5.15.153.1-microsoft-standard-WSL2\n",
+	}, nil)
+	assert.True(t, info.WSL)
+
+	info = detectIn(t, map[string]string{"etc/hostname": "box\n"},
+		map[string]string{"WSL_DISTRO_NAME": "Ubuntu"})
+	assert.True(t, info.WSL)
+
+	info = detectIn(t, map[string]string{
+		"proc/sys/kernel/osrelease": "6.10.0-arch1-1\n",
+	}, nil)
+	assert.False(t, info.WSL)
+}
+
+func TestDetectContainer(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		files   map[string]string
+		vars    map[string]string
+		runtime string
+	}{
+		{map[string]string{".dockerenv": ""}, nil, "docker"},
+		{map[string]string{"run/.containerenv": ""}, nil, "podman"},
+		{
+			map[string]string{".dockerenv": ""},
+			map[string]string{"container": "systemd-nspawn"},
+			"systemd-nspawn",
+		},
+		{
+			map[string]string{
+				"proc/1/cgroup": "12:pids:/kubepods/besteffort/pod1/docker-1\n",
+			},
+			nil, "kubernetes",
+		},
+		{map[string]string{"proc/1/cgroup": "1:name=systemd:/lxc/c1\n"},
+			nil, "lxc"},
+	}
+
+	for _, c := range cases {
+		info := detectIn(t, c.files, c.vars)
+		assert.Equal(t, c.runtime, info.Runtime)
+		assert.True(t, info.Container())
+	}
+}
@@ -0,0 +1,80 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// markers are files created by container runtimes inside containers.
+var markers = []struct{ path, runtime string }{
+	{".dockerenv", "docker"},
+	{"run/.containerenv", "podman"},
+}
+
+// cgroups maps the words found in the cgroup of init to the runtime
+// creating them, for cgroup v1 hosts without marker files.
+var cgroups = []struct{ word, runtime string }{
+	{"kubepods", "kubernetes"},
+	{"docker", "docker"},
+	{"libpod", "podman"},
+	{"lxc", "lxc"},
+}
+
+// wsl reports whether the kernel is the one of WSL, whose release names
+// Microsoft, or whether WSL set its environment variables.
+func (s *settings) wsl() (bool, error) {
+	if _, ok := s.lookup("WSL_DISTRO_NAME"); ok {
+		return true, nil
+	}
+
+	kernel, err := s.read("proc/sys/kernel/osrelease")
+
+	return strings.Contains(strings.ToLower(kernel), "microsoft"), err
+}
+
+// container returns the runtime of the container the process runs in.
+// The container variable set by systemd-nspawn, LXC and podman wins over
+// marker files, which win over the cgroup of init.
+func (s *settings) container() (string, error) {
+	if runtime, ok := s.lookup("container"); ok && runtime != "" {
+		return runtime, nil
+	}
+
+	for _, marker := range markers {
+		_, err := os.Stat(s.path(marker.path))
+		if err == nil {
+			return marker.runtime, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("platform: %w", err)
+		}
+	}
+
+	cgroup, err := s.read("proc/1/cgroup")
+	for _, c := range cgroups {
+		if strings.Contains(cgroup, c.word) {
+			return c.runtime, err
+		}
+	}
+
+	return "", err
+}
+
+// read returns the content of the system file name below the root, or an
+// empty string when it is missing.
+func (s *settings) read(name string) (string, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("platform: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,298 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Client calls the methods of a plugin. It is safe for concurrent use.
+type Client struct {
+	w         io.Closer
+	writeMu   sync.Mutex
+	enc       *json.Encoder
+	handshake Handshake
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	err     error
+	done    chan struct{}
+
+	cmd     *exec.Cmd
+	timeout time.Duration
+}
+
+// Start runs the plugin executable at path and performs the handshake,
+// within ctx. The plugin runs until Close.
+func Start(ctx context.Context, path string, opts ...Option) (*Client, error) {
+	s := newSettings(opts)
+
+	cmd := exec.Command(path, s.args...)
+	cmd.Env = append(os.Environ(), s.env...)
+	cmd.Stderr = s.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin: %w", err)
+	}
+
+	c := attach(stdout, stdin, s)
+	c.cmd = cmd
+
+	if err := c.negotiate(ctx, s.versions); err != nil {
+		_ = cmd.Process.Kill()
+		<-c.done
+		_ = cmd.Wait()
+
+		return nil, fmt.Errorf("%w (plugin %s)", err, path)
+	}
+
+	return c, nil
+}
+
+// NewClient performs the handshake with a plugin reading requests from w
+// and writing responses to r, such as the ends of pipes or a connection.
+func NewClient(
+	ctx context.Context, r io.Reader, w io.WriteCloser, opts ...Option,
+) (*Client, error) {
+	s := newSettings(opts)
+
+	c := attach(r, w, s)
+	if err := c.negotiate(ctx, s.versions); err != nil {
+		_ = w.Close()
+
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func attach(r io.Reader, w io.WriteCloser, s *settings) *Client {
+	c := &Client{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+		timeout: s.timeout,
+	}
+
+	go c.read(r)
+
+	return c
+}
+
+// negotiate performs the handshake.
+func (c *Client) negotiate(ctx context.Context, versions []int) error {
+	err := c.Call(ctx, HandshakeMethod, HandshakeRequest{Versions: versions},
+		&c.handshake)
+
+	var perr *Error
+
+	switch {
+	case errors.As(err, &perr) && perr.Code == CodeProtocol:
+		return fmt.Errorf("%w: %w", ErrIncompatible, err)
+	case err != nil:
+		return err
+	case !slices.Contains(versions, c.handshake.Version):
+		return fmt.Errorf("%w: plugin chose version %d", ErrIncompatible,
+			c.handshake.Version)
+	}
+
+	return nil
+}
+
+// Handshake returns the name, protocol version and methods of the plugin.
+func (c *Client) Handshake() Handshake {
+	return c.handshake
+}
+
+// read dispatches the responses until the plugin closes its output.
+func (c *Client) read(r io.Reader) {
+	dec := json.NewDecoder(r)
+
+	for {
+		var m message
+		if err := dec.Decode(&m); err != nil {
+			c.fail(err)
+
+			return
+		}
+
+		c.mu.Lock()
+		ch := c.pending[m.ID]
+		delete(c.pending, m.ID)
+		c.mu.Unlock()
+
+		if ch != nil {
+			ch <- &m
+		}
+	}
+}
+
+// fail ends the pending and future calls.
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if errors.Is(err, io.EOF) {
+		c.err = ErrClosed
+	} else {
+		c.err = fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+
+	clear(c.pending)
+	close(c.done)
+}
+
+// Call invokes method with params, both encoded as JSON, and decodes its
+// result into result unless nil. Errors reported by the plugin are
+// returned as *Error.
+func (c *Client) Call(
+	ctx context.Context, method string, params, result any,
+) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("plugin: encode params of %s: %w", method, err)
+	}
+
+	id, ch, err := c.register()
+	if err != nil {
+		return err
+	}
+
+	err = c.send(&message{ID: id, Method: method, Params: raw})
+	if err != nil {
+		c.unregister(id)
+
+		return err
+	}
+
+	select {
+	case m := <-ch:
+		return decodeResult(m, method, result)
+	case <-ctx.Done():
+		c.unregister(id)
+		c.cancel(id)
+
+		return fmt.Errorf("plugin: %s: %w", method, context.Cause(ctx))
+	case <-c.done:
+		select {
+		case m := <-ch:
+			return decodeResult(m, method, result)
+		default:
+			return fmt.Errorf("%w (method %s)", c.err, method)
+		}
+	}
+}
+
+// Invoke calls method with params and returns its decoded result.
+func Invoke[R any](
+	ctx context.Context, c *Client, method string, params any,
+) (R, error) {
+	var result R
+	err := c.Call(ctx, method, params, &result)
+
+	return result, err
+}
+
+// register allocates the id and response channel of a call.
+func (c *Client) register() (int64, chan *message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return 0, nil, c.err
+	}
+
+	c.nextID++
+	ch := make(chan *message, 1)
+	c.pending[c.nextID] = ch
+
+	return c.nextID, ch, nil
+}
+
+// unregister drops a call whose response will be ignored.
+func (c *Client) unregister(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, id)
+}
+
+// cancel tells the plugin to stop the handler of an abandoned call.
+func (c *Client) cancel(id int64) {
+	params, _ := json.Marshal(CancelRequest{ID: id})
+
+	// A lost connection has no handler left to stop.
+	_ = c.send(&message{Method: CancelMethod, Params: params})
+}
+
+func (c *Client) send(m *message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.enc.Encode(m); err != nil {
+		return fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+
+	return nil
+}
+
+func decodeResult(m *message, method string, result any) error {
+	if m.Error != nil {
+		return m.Error
+	}
+
+	if result == nil || len(m.Result) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(m.Result, result); err != nil {
+		return fmt.Errorf("plugin: decode result of %s: %w", method, err)
+	}
+
+	return nil
+}
+
+// Close closes the input of the plugin, which makes it exit, and waits for
+// started plugins to do so, killing them after the shutdown timeout.
+func (c *Client) Close() error {
+	c.writeMu.Lock()
+	err := c.w.Close()
+	c.writeMu.Unlock()
+
+	if c.cmd == nil {
+		return err
+	}
+
+	// Wait closes the output, which must be read to the end first.
+	select {
+	case <-c.done:
+	case <-time.After(c.timeout):
+		_ = c.cmd.Process.Kill()
+		<-c.done
+	}
+
+	if err := c.cmd.Wait(); err != nil {
+		return fmt.Errorf("plugin: %w", err)
+	}
+
+	return err
+}
@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Executable is a plugin found by Discover.
+type Executable struct {
+	// Name is the file name without the prefix and the .exe extension.
+	Name string
+	// Path is the path of the executable.
+	Path string
+}
+
+// Discover lists the executables of the PATH directories whose name starts
+// with prefix, sorted by name. Like for commands, the first directory
+// providing a name wins. Empty and relative entries are skipped, so that
+// the current directory never provides plugins.
+func Discover(prefix string, opts ...Option) []Executable {
+	s := newSettings(opts)
+
+	var found []Executable
+
+	seen := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(s.path) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+
+		for _, exe := range scan(dir, prefix) {
+			if !seen[exe.Name] {
+				seen[exe.Name] = true
+				found = append(found, exe)
+			}
+		}
+	}
+
+	slices.SortFunc(found, func(a, b Executable) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return found
+}
+
+// scan lists the plugins of a directory, which may not exist.
+func scan(dir, prefix string) []Executable {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found []Executable
+
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), prefix)
+		if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+			name = strings.TrimSuffix(name, ext)
+		}
+
+		if !ok || name == "" || entry.IsDir() {
+			continue
+		}
+
+		// LookPath checks that the file is an executable.
+		path, err := exec.LookPath(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			found = append(found, Executable{Name: name, Path: path})
+		}
+	}
+
+	return found
+}
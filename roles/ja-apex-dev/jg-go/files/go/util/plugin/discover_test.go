@@ -0,0 +1,52 @@
+package plugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/plugin"
+)
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	first, second := t.TempDir(), t.TempDir()
+
+	files := []struct {
+		dir, name string
+		perm      os.FileMode
+	}{
+		{first, "archible-lint", 0o755},
+		{first, "archible-data", 0o644},
+		{first, "other-tool", 0o755},
+		{second, "archible-lint", 0o755},
+		{second, "archible-fmt", 0o755},
+		{second, "archible-", 0o755},
+	}
+	for _, f := range files {
+		path := filepath.Join(f.dir, f.name)
+		require.NoError(t, os.WriteFile(path, nil, f.perm))
+	}
+
+	require.NoError(t, os.Mkdir(filepath.Join(first, "archible-dir"), 0o755))
+
+	path := strings.Join([]string{
+		"", "relative", first, filepath.Join(first, "missing"), second,
+	}, string(os.PathListSeparator))
+
+	assert.Equal(t, []plugin.Executable{
+		{Name: "fmt", Path: filepath.Join(second, "archible-fmt")},
+		{Name: "lint", Path: filepath.Join(first, "archible-lint")},
+	}, plugin.Discover("archible-", plugin.WithPath(path)))
+}
+
+func TestDiscover_empty(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, plugin.Discover("archible-", plugin.WithPath("")))
+}
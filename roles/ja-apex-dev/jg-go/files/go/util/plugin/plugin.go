@@ -0,0 +1,194 @@
+// Package plugin extends programs with external executables speaking JSON
+// over their standard streams, found on PATH by a name prefix like the
+// git-* and kubectl-* commands:
+//
+//	for _, exe := range plugin.Discover("archible-") {
+//		c, err := plugin.Start(ctx, exe.Path)
+//		...
+//		out, err := plugin.Invoke[Output](ctx, c, "render", input)
+//	}
+//
+// Each message is a JSON object on its own line. Requests have an id, a
+// method and params; responses repeat the id with a result or an error.
+// The host first calls the "plugin.handshake" method with the protocol
+// versions it speaks, and the plugin answers with its name, the version it
+// chose and its methods. A host abandoning a call sends a request to the
+// "plugin.cancel" method with the id of the call and without an id of its
+// own, which cancels the context of the handler and gets no response.
+// Plugins log to their standard error, which the host passes through, and
+// exit when their standard input is closed.
+//
+// Plugins are written with a Server:
+//
+//	s := plugin.NewServer("render")
+//	plugin.Register(s, "render", func(ctx context.Context, in Input) (
+//		Output, error,
+//	) { ... })
+//	err := s.ServeStdio(ctx)
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Version is the latest protocol version.
+const Version = 1
+
+// HandshakeMethod is the method negotiating the protocol version.
+const HandshakeMethod = "plugin.handshake"
+
+// CancelMethod is the method canceling a pending call.
+const CancelMethod = "plugin.cancel"
+
+// Error codes of the protocol.
+const (
+	CodeUnknownMethod = "unknown_method"
+	CodeInvalidParams = "invalid_params"
+	CodeProtocol      = "protocol"
+	CodeInternal      = "internal"
+)
+
+var (
+	// ErrIncompatible is returned by Start when the plugin speaks none of
+	// the protocol versions of the host.
+	ErrIncompatible = errors.New("plugin: incompatible protocol version")
+	// ErrClosed is returned by calls after the connection to the plugin is
+	// lost, typically because it exited.
+	ErrClosed = errors.New("plugin: connection closed")
+	// ErrUnknownMethod matches the errors of calls to missing methods.
+	ErrUnknownMethod = &Error{Code: CodeUnknownMethod}
+)
+
+// Error is a failed call, as reported by the plugin. Handlers return one
+// to choose the code, other errors being reported as CodeInternal.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns the code and the message of the plugin.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "plugin: " + e.Code
+	}
+
+	return "plugin: " + e.Code + ": " + e.Message
+}
+
+// Is matches errors with the same code, so that errors.Is(err,
+// ErrUnknownMethod) holds whatever the message.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Code == e.Code
+}
+
+// message is a request or a response.
+type message struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// HandshakeRequest is the params of the handshake.
+type HandshakeRequest struct {
+	// Versions lists the protocol versions of the host.
+	Versions []int `json:"versions"`
+}
+
+// CancelRequest is the params of a cancellation.
+type CancelRequest struct {
+	// ID is the id of the call to cancel.
+	ID int64 `json:"id"`
+}
+
+// Handshake is the result of the handshake.
+type Handshake struct {
+	// Name is the name the plugin gives itself.
+	Name string `json:"name"`
+	// Version is the protocol version chosen by the plugin.
+	Version int `json:"version"`
+	// Methods lists the methods of the plugin, sorted.
+	Methods []string `json:"methods"`
+}
+
+// Option configures Start, NewClient, NewServer and Discover.
+type Option func(*settings)
+
+type settings struct {
+	versions []int
+	args     []string
+	env      []string
+	stderr   io.Writer
+	timeout  time.Duration
+	path     string
+}
+
+// DefaultShutdownTimeout is how long Close waits for plugins to exit
+// before killing them.
+const DefaultShutdownTimeout = 5 * time.Second
+
+func newSettings(opts []Option) *settings {
+	s := &settings{
+		versions: []int{Version},
+		stderr:   os.Stderr,
+		timeout:  DefaultShutdownTimeout,
+		path:     os.Getenv("PATH"),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithVersions sets the protocol versions spoken, Version by default.
+func WithVersions(versions ...int) Option {
+	return func(s *settings) {
+		s.versions = versions
+	}
+}
+
+// WithArgs passes command line arguments to started plugins.
+func WithArgs(args ...string) Option {
+	return func(s *settings) {
+		s.args = args
+	}
+}
+
+// WithEnv adds "KEY=value" variables on top of the current environment
+// of started plugins.
+func WithEnv(env ...string) Option {
+	return func(s *settings) {
+		s.env = append(s.env, env...)
+	}
+}
+
+// WithStderr sets where the standard error of started plugins goes,
+// os.Stderr by default.
+func WithStderr(w io.Writer) Option {
+	return func(s *settings) {
+		s.stderr = w
+	}
+}
+
+// WithShutdownTimeout sets how long Close waits for plugins to exit.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.timeout = timeout
+	}
+}
+
+// WithPath sets the directory list searched by Discover, the PATH
+// variable by default.
+func WithPath(path string) Option {
+	return func(s *settings) {
+		s.path = path
+	}
+}
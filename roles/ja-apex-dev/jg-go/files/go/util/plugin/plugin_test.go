@@ -0,0 +1,311 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/plugin"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func greeter() *plugin.Server {
+	s := plugin.NewServer("greeter", plugin.WithVersions(1, 2))
+
+	plugin.Register(s, "greet", func(_ context.Context, g greeting) (
+		string, error,
+	) {
+		return "hello " + g.Name, nil
+	})
+	plugin.Register(s, "version", func(ctx context.Context, _ struct{}) (
+		int, error,
+	) {
+		return plugin.NegotiatedVersion(ctx), nil
+	})
+	plugin.Register(s, "fail", func(_ context.Context, code string) (
+		any, error,
+	) {
+		if code == "" {
+			return nil, errors.New("boom")
+		}
+
+		return nil, &plugin.Error{Code: code, Message: "failed"}
+	})
+	plugin.Register(s, "panic", func(context.Context, struct{}) (any, error) {
+		panic("oops")
+	})
+	plugin.Register(s, "block", func(ctx context.Context, _ struct{}) (
+		any, error,
+	) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+
+	return s
+}
+
+// connect serves s over pipes and returns the error of the handshake.
+func connect(
+	t *testing.T, s *plugin.Server, opts ...plugin.Option,
+) (*plugin.Client, error) {
+	t.Helper()
+
+	hostR, pluginW := io.Pipe()
+	pluginR, hostW := io.Pipe()
+
+	served := make(chan error, 1)
+
+	go func() {
+		// The test context is canceled before the cleanups run.
+		served <- s.Serve(context.Background(), pluginR, pluginW)
+
+		_ = pluginW.Close()
+	}()
+
+	c, err := plugin.NewClient(t.Context(), hostR, hostW, opts...)
+
+	t.Cleanup(func() {
+		if c != nil {
+			assert.NoError(t, c.Close())
+		}
+
+		assert.NoError(t, <-served)
+	})
+
+	return c, err
+}
+
+func TestHandshake(t *testing.T) {
+	t.Parallel()
+
+	c, err := connect(t, greeter(), plugin.WithVersions(1, 2, 3))
+	require.NoError(t, err)
+
+	assert.Equal(t, plugin.Handshake{
+		Name:    "greeter",
+		Version: 2,
+		Methods: []string{"block", "fail", "greet", "panic", "version"},
+	}, c.Handshake())
+
+	version, err := plugin.Invoke[int](t.Context(), c, "version", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+}
+
+func TestHandshake_incompatible(t *testing.T) {
+	t.Parallel()
+
+	_, err := connect(t, greeter(), plugin.WithVersions(3))
+	require.ErrorIs(t, err, plugin.ErrIncompatible)
+	assert.ErrorContains(t, err, "plugin speaks [1 2]")
+}
+
+func TestInvoke(t *testing.T) {
+	t.Parallel()
+
+	c, err := connect(t, greeter())
+	require.NoError(t, err)
+
+	out, err := plugin.Invoke[string](t.Context(), c, "greet",
+		greeting{Name: "world"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+}
+
+func TestInvoke_errors(t *testing.T) {
+	t.Parallel()
+
+	c, err := connect(t, greeter())
+	require.NoError(t, err)
+
+	tests := []struct {
+		method string
+		params any
+		code   string
+	}{
+		{"missing", nil, plugin.CodeUnknownMethod},
+		{"greet", "not an object", plugin.CodeInvalidParams},
+		{"fail", "", plugin.CodeInternal},
+		{"fail", "custom", "custom"},
+		{"panic", nil, plugin.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		err := c.Call(t.Context(), tt.method, tt.params, nil)
+
+		var perr *plugin.Error
+
+		require.ErrorAs(t, err, &perr, tt.method)
+		assert.Equal(t, tt.code, perr.Code, tt.method)
+	}
+
+	err = c.Call(t.Context(), "missing", nil, nil)
+	assert.ErrorIs(t, err, plugin.ErrUnknownMethod)
+}
+
+func TestCall_concurrent(t *testing.T) {
+	t.Parallel()
+
+	c, err := connect(t, greeter())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := range 50 {
+		wg.Go(func() {
+			name := fmt.Sprint(i)
+			out, err := plugin.Invoke[string](t.Context(), c, "greet",
+				greeting{Name: name})
+			assert.NoError(t, err)
+			assert.Equal(t, "hello "+name, out)
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestCall_canceled(t *testing.T) {
+	t.Parallel()
+
+	s := greeter()
+	stopped := make(chan error, 1)
+
+	plugin.Register(s, "wait", func(ctx context.Context, _ struct{}) (
+		any, error,
+	) {
+		<-ctx.Done()
+		stopped <- ctx.Err()
+
+		return nil, ctx.Err()
+	})
+
+	c, err := connect(t, s)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	err = c.Call(ctx, "wait", nil, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The handler is canceled by the plugin.cancel request.
+	select {
+	case err := <-stopped:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "handler not canceled")
+	}
+
+	// The connection survives the abandoned call.
+	_, err = plugin.Invoke[string](t.Context(), c, "greet", greeting{})
+	assert.NoError(t, err)
+}
+
+func TestCall_closed(t *testing.T) {
+	t.Parallel()
+
+	hostR, pluginW := io.Pipe()
+	pluginR, hostW := io.Pipe()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	served := make(chan error, 1)
+
+	go func() {
+		served <- greeter().Serve(ctx, pluginR, pluginW)
+
+		_ = pluginW.Close()
+	}()
+
+	c, err := plugin.NewClient(t.Context(), hostR, hostW)
+	require.NoError(t, err)
+
+	// Shutting down cancels the running handlers.
+	blocked := make(chan error, 1)
+
+	go func() {
+		blocked <- c.Call(t.Context(), "block", nil, nil)
+	}()
+
+	cancel()
+	require.ErrorIs(t, <-served, context.Canceled)
+	require.Error(t, <-blocked)
+
+	err = c.Call(t.Context(), "greet", greeting{}, nil)
+	require.ErrorIs(t, err, plugin.ErrClosed)
+	assert.NoError(t, c.Close())
+}
+
+func TestServe_noHandshake(t *testing.T) {
+	t.Parallel()
+
+	hostR, pluginW := io.Pipe()
+	pluginR, hostW := io.Pipe()
+
+	go func() {
+		_ = greeter().Serve(t.Context(), pluginR, pluginW)
+		_ = pluginW.Close()
+	}()
+
+	go func() {
+		_, _ = io.WriteString(hostW, `{"id":1,"method":"greet"}`+"\n")
+		_ = hostW.Close()
+	}()
+
+	out, err := io.ReadAll(hostR)
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"id":1,"error":{"code":"protocol","message":"no handshake"}}`,
+		string(out))
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+
+	// The plugin answers the handshake, then echoes its argument.
+	script := filepath.Join(t.TempDir(), "archible-echo")
+	require.NoError(t, os.WriteFile(script, []byte(`#!`+sh+`
+read -r line
+echo '{"id":1,"result":{"name":"echo","version":1,"methods":["echo"]}}'
+read -r line
+echo "{\"id\":2,\"result\":\"$1 $PLUGIN_VAR\"}"
+read -r line
+exit 0
+`), 0o755))
+
+	c, err := plugin.Start(t.Context(), script, plugin.WithArgs("hi"),
+		plugin.WithEnv("PLUGIN_VAR=there"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "echo", c.Handshake().Name)
+
+	out, err := plugin.Invoke[string](t.Context(), c, "echo", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hi there", out)
+	require.NoError(t, c.Close())
+}
+
+func TestStart_missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := plugin.Start(t.Context(), filepath.Join(t.TempDir(), "none"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,269 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Handler serves a method, given its raw JSON params. The result is
+// encoded as JSON.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+type versionKey struct{}
+
+// NegotiatedVersion returns the protocol version agreed on by the
+// handshake, from the context of a handler.
+func NegotiatedVersion(ctx context.Context) int {
+	version, _ := ctx.Value(versionKey{}).(int)
+
+	return version
+}
+
+// Server answers the requests of a host, in the plugin executable.
+type Server struct {
+	name     string
+	versions []int
+	handlers map[string]Handler
+}
+
+// NewServer returns a server for the plugin called name. Only
+// WithVersions applies.
+func NewServer(name string, opts ...Option) *Server {
+	return &Server{
+		name:     name,
+		versions: newSettings(opts).versions,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler of method, replacing any previous one.
+func (s *Server) Handle(method string, h Handler) {
+	s.handlers[method] = h
+}
+
+// Register registers a handler decoding its params into P.
+func Register[P, R any](
+	s *Server, method string, fn func(ctx context.Context, params P) (R, error),
+) {
+	s.Handle(method, func(
+		ctx context.Context, raw json.RawMessage,
+	) (any, error) {
+		var params P
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, &Error{
+					Code: CodeInvalidParams, Message: err.Error(),
+				}
+			}
+		}
+
+		return fn(ctx, params)
+	})
+}
+
+// ServeStdio serves the host on the standard streams of the process.
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// Serve answers the requests read from r on w, running handlers
+// concurrently, until r ends or ctx is done. It then cancels the context
+// of the running handlers and waits for them.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	c := &session{
+		server:  s,
+		enc:     json.NewEncoder(w),
+		cancels: make(map[int64]context.CancelFunc),
+	}
+	defer c.wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	requests := make(chan *message)
+	errs := make(chan error, 1)
+
+	go decode(ctx, r, requests, errs)
+
+	for {
+		select {
+		case m := <-requests:
+			c.dispatch(ctx, m)
+		case err := <-errs:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("plugin: %w", err)
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// decode sends the requests of r, then the error ending them, until ctx
+// is done.
+func decode(
+	ctx context.Context, r io.Reader, requests chan<- *message,
+	errs chan<- error,
+) {
+	dec := json.NewDecoder(r)
+
+	for {
+		var m message
+		if err := dec.Decode(&m); err != nil {
+			errs <- err
+
+			return
+		}
+
+		select {
+		case requests <- &m:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// session is the state of a single Serve call.
+type session struct {
+	server  *Server
+	writeMu sync.Mutex
+	enc     *json.Encoder
+	wg      sync.WaitGroup
+	version int
+
+	mu      sync.Mutex
+	cancels map[int64]context.CancelFunc
+}
+
+// dispatch answers the handshake inline and the other requests in the
+// background.
+func (c *session) dispatch(ctx context.Context, m *message) {
+	switch m.Method {
+	case HandshakeMethod:
+		result, err := c.handshake(m.Params)
+		c.reply(m.ID, result, err)
+
+		return
+	case CancelMethod:
+		c.cancel(m.Params)
+
+		return
+	}
+
+	if c.version == 0 {
+		c.reply(m.ID, nil, &Error{Code: CodeProtocol, Message: "no handshake"})
+
+		return
+	}
+
+	h, ok := c.server.handlers[m.Method]
+	if !ok {
+		c.reply(m.ID, nil, &Error{Code: CodeUnknownMethod, Message: m.Method})
+
+		return
+	}
+
+	ctx, cancel := context.WithCancel(
+		context.WithValue(ctx, versionKey{}, c.version))
+
+	c.mu.Lock()
+	c.cancels[m.ID] = cancel
+	c.mu.Unlock()
+
+	c.wg.Go(func() {
+		result, err := call(ctx, h, m.Params)
+
+		c.mu.Lock()
+		delete(c.cancels, m.ID)
+		c.mu.Unlock()
+		cancel()
+
+		c.reply(m.ID, result, err)
+	})
+}
+
+// cancel cancels the context of a running handler, if any.
+func (c *session) cancel(raw json.RawMessage) {
+	var req CancelRequest
+	if json.Unmarshal(raw, &req) != nil {
+		return
+	}
+
+	c.mu.Lock()
+	cancel := c.cancels[req.ID]
+	c.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// handshake chooses the highest version spoken by both sides.
+func (c *session) handshake(raw json.RawMessage) (any, error) {
+	var req HandshakeRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	common := slices.DeleteFunc(slices.Clone(req.Versions), func(v int) bool {
+		return !slices.Contains(c.server.versions, v)
+	})
+	if len(common) == 0 {
+		return nil, &Error{Code: CodeProtocol, Message: fmt.Sprintf(
+			"no common version, plugin speaks %v", c.server.versions)}
+	}
+
+	c.version = slices.Max(common)
+
+	return Handshake{
+		Name:    c.server.name,
+		Version: c.version,
+		Methods: slices.Sorted(maps.Keys(c.server.handlers)),
+	}, nil
+}
+
+// call runs a handler, reporting its panics as internal errors.
+func call(
+	ctx context.Context, h Handler, params json.RawMessage,
+) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return h(ctx, params)
+}
+
+// reply writes the response of a request.
+func (c *session) reply(id int64, result any, err error) {
+	m := &message{ID: id}
+
+	if err == nil {
+		m.Result, err = json.Marshal(result)
+	}
+
+	if err != nil {
+		var perr *Error
+		if !errors.As(err, &perr) {
+			perr = &Error{Code: CodeInternal, Message: err.Error()}
+		}
+
+		m.Result, m.Error = nil, perr
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	// A failed write means the host is gone, which ends the input too.
+	_ = c.enc.Encode(m)
+}
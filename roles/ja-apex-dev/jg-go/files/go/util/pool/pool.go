@@ -0,0 +1,169 @@
+// Package pool runs a function over a slice of items with bounded
+// concurrency, collecting the results in input order.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"example.com/go-template/util"
+)
+
+// ErrPanic wraps a panic recovered from a task.
+var ErrPanic = errors.New("pool: task panicked")
+
+// Func processes a single item.
+type Func[T, R any] func(ctx context.Context, item T) (R, error)
+
+// Run processes the items with at most workers goroutines, or GOMAXPROCS
+// when workers is not positive. The first error cancels the context passed
+// to the remaining tasks and is returned; items left unprocessed keep the
+// zero result. The cancellation cause of ctx is only returned when it made
+// Run skip items.
+func Run[T, R any](
+	ctx context.Context, items []T, workers int, fn Func[T, R],
+) ([]R, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]R, len(items))
+
+	var (
+		once  sync.Once
+		first error
+	)
+
+	dispatched := dispatch(ctx, len(items), workers, func(i int) {
+		result, err := call(ctx, items[i], fn)
+		if err != nil {
+			once.Do(func() {
+				first = fmt.Errorf("item %d: %w", i, err)
+				cancel(first)
+			})
+
+			return
+		}
+
+		results[i] = result
+	})
+
+	switch {
+	case first != nil:
+		return results, first
+	case dispatched < len(items):
+		return results, context.Cause(ctx)
+	default:
+		return results, nil
+	}
+}
+
+// RunAll is like Run but processes every item regardless of failures and
+// returns all errors joined in input order. Only cancellation of ctx stops
+// the processing early.
+func RunAll[T, R any](
+	ctx context.Context, items []T, workers int, fn Func[T, R],
+) ([]R, error) {
+	results := make([]R, len(items))
+	failures := make([]error, len(items))
+
+	dispatched := dispatch(ctx, len(items), workers, func(i int) {
+		result, err := call(ctx, items[i], fn)
+		if err != nil {
+			failures[i] = fmt.Errorf("item %d: %w", i, err)
+
+			return
+		}
+
+		results[i] = result
+	})
+
+	if dispatched < len(items) {
+		failures = append(failures, ctx.Err())
+	}
+
+	return results, errors.Join(failures...)
+}
+
+// Results is like RunAll but returns one util.Result per item, pairing
+// every value with its own error. Items skipped after ctx is done hold
+// the cancellation cause.
+func Results[T, R any](
+	ctx context.Context, items []T, workers int, fn Func[T, R],
+) []util.Result[R] {
+	results := make([]util.Result[R], len(items))
+	done := make([]bool, len(items))
+
+	dispatch(ctx, len(items), workers, func(i int) {
+		results[i] = util.ResultOf(call(ctx, items[i], fn))
+		done[i] = true
+	})
+
+	for i := range results {
+		if !done[i] {
+			results[i] = util.Err[R](context.Cause(ctx))
+		}
+	}
+
+	return results
+}
+
+// dispatch calls task for the indices [0, n) from a bounded set of goroutines,
+// stopping to hand out new indices once ctx is done. It returns the number
+// of indices handed out, n unless work was skipped.
+func dispatch(ctx context.Context, n, workers int, task func(int)) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(workers, n) {
+		wg.Go(func() {
+			for i := range indices {
+				task(i)
+			}
+		})
+	}
+
+	dispatched := feed(ctx, n, indices)
+
+	close(indices)
+	wg.Wait()
+
+	return dispatched
+}
+
+// feed sends the indices [0, n) until ctx is done and returns how many it
+// sent.
+func feed(ctx context.Context, n int, indices chan<- int) int {
+	for i := range n {
+		if ctx.Err() != nil {
+			return i
+		}
+
+		select {
+		case <-ctx.Done():
+			return i
+		case indices <- i:
+		}
+	}
+
+	return n
+}
+
+func call[T, R any](ctx context.Context, item T, fn Func[T, R]) (
+	result R, err error,
+) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	return fn(ctx, item)
+}
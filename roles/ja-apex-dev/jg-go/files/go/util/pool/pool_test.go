@@ -0,0 +1,161 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/pool"
+)
+
+var errOdd = errors.New("odd")
+
+func square(_ context.Context, n int) (int, error) {
+	return n * n, nil
+}
+
+func TestRunPreservesOrder(t *testing.T) {
+	t.Parallel()
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	results, err := pool.Run(t.Context(), items, 3, square)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 9, 16, 25, 36, 49, 64}, results)
+}
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var active, peak atomic.Int32
+
+	_, err := pool.Run(t.Context(), make([]int, 20), 4,
+		func(context.Context, int) (int, error) {
+			current := active.Add(1)
+			defer active.Add(-1)
+
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+
+			return 0, nil
+		})
+
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+}
+
+func TestRunStopsOnFirstError(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	_, err := pool.Run(t.Context(), make([]int, 100), 1,
+		func(ctx context.Context, _ int) (int, error) {
+			if calls.Add(1) == 3 {
+				return 0, errOdd
+			}
+
+			return 0, ctx.Err()
+		})
+
+	require.ErrorIs(t, err, errOdd)
+	assert.ErrorContains(t, err, "item 2")
+	assert.Less(t, calls.Load(), int32(100))
+}
+
+func TestRunAllCollectsErrors(t *testing.T) {
+	t.Parallel()
+
+	results, err := pool.RunAll(t.Context(), []int{1, 2, 3, 4}, 2,
+		func(_ context.Context, n int) (int, error) {
+			if n%2 == 1 {
+				return 0, errOdd
+			}
+
+			return n, nil
+		})
+
+	require.ErrorIs(t, err, errOdd)
+	assert.Equal(t, "item 0: odd\nitem 2: odd", err.Error())
+	assert.Equal(t, []int{0, 2, 0, 4}, results)
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	t.Parallel()
+
+	_, err := pool.Run(t.Context(), []int{1}, 0,
+		func(context.Context, int) (int, error) {
+			panic("boom")
+		})
+
+	require.ErrorIs(t, err, pool.ErrPanic)
+}
+
+func TestRunContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := pool.Run(ctx, []int{1, 2, 3}, 2, square)
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = pool.RunAll(ctx, []int{1, 2, 3}, 2, square)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunCanceledAfterLastItem(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	last := func(ctx context.Context, n int) (int, error) {
+		if n == 3 {
+			cancel()
+		}
+
+		return square(ctx, n)
+	}
+
+	results, err := pool.Run(ctx, []int{1, 2, 3}, 1, last)
+	require.NoError(t, err, "no item was skipped")
+	assert.Equal(t, []int{1, 4, 9}, results)
+
+	_, err = pool.RunAll(ctx, []int{1, 2, 3}, 1, square)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestResults(t *testing.T) {
+	t.Parallel()
+
+	results := pool.Results(t.Context(), []int{1, 2, 3}, 2,
+		func(_ context.Context, n int) (int, error) {
+			if n == 2 {
+				return 0, errOdd
+			}
+
+			return n * n, nil
+		})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, 1, results[0].UnwrapOr(0))
+	require.ErrorIs(t, results[1].Err(), errOdd)
+	assert.Equal(t, 9, results[2].UnwrapOr(0))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	for _, r := range pool.Results(ctx, []int{1, 2}, 1, square) {
+		require.ErrorIs(t, r.Err(), context.Canceled)
+	}
+}
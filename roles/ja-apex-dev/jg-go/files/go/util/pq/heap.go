@@ -0,0 +1,83 @@
+package pq
+
+// The queue is a binary min-heap of items by priority, then push order.
+
+// less reports whether the item at i comes before the item at j.
+func (q *Queue[V, P]) less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if q.before(a.priority, b.priority) {
+		return true
+	}
+
+	return !q.before(b.priority, a.priority) && a.seq < b.seq
+}
+
+func (q *Queue[V, P]) swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index, q.items[j].index = i, j
+}
+
+// up moves the item at i towards the root until in order.
+func (q *Queue[V, P]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.less(i, parent) {
+			return
+		}
+
+		q.swap(i, parent)
+		i = parent
+	}
+}
+
+// down moves the item at i towards the leaves until in order, and
+// reports whether it moved.
+func (q *Queue[V, P]) down(i int) bool {
+	start := i
+
+	for {
+		child := 2*i + 1
+		if child >= len(q.items) {
+			break
+		}
+
+		if right := child + 1; right < len(q.items) && q.less(right, child) {
+			child = right
+		}
+
+		if !q.less(child, i) {
+			break
+		}
+
+		q.swap(i, child)
+		i = child
+	}
+
+	return i > start
+}
+
+// fix restores the order after the item at i changed.
+func (q *Queue[V, P]) fix(i int) {
+	if !q.down(i) {
+		q.up(i)
+	}
+}
+
+// removeAt removes the item at i, marking it as no longer queued.
+func (q *Queue[V, P]) removeAt(i int) *Item[V, P] {
+	last := len(q.items) - 1
+	if i != last {
+		q.swap(i, last)
+	}
+
+	item := q.items[last]
+	q.items[last] = nil
+	q.items = q.items[:last]
+	item.index = -1
+
+	if i != last {
+		q.fix(i)
+	}
+
+	return item
+}
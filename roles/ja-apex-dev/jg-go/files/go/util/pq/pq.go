@@ -0,0 +1,125 @@
+// Package pq provides a generic priority queue, sparing the heap.Interface
+// boilerplate of container/heap: values are pushed with a priority and
+// popped in priority order, first in, first out among equal priorities,
+// and the handles returned by Push update or remove them in place:
+//
+//	q := pq.NewMin[string, int]()
+//	q.Push("lint", 2)
+//	deploy := q.Push("deploy", 1)
+//	q.Push("test", 2)
+//	q.Update(deploy, 3)
+//	q.Pop().Value // "lint", then "test" and "deploy"
+//
+// A Queue is not safe for concurrent use.
+package pq
+
+import "cmp"
+
+// Item is a value in a Queue, the handle to update or remove it.
+type Item[V, P any] struct {
+	// Value is the queued value.
+	Value V
+
+	priority P
+	seq      uint64
+	index    int
+}
+
+// Priority returns the priority of the item.
+func (i *Item[V, P]) Priority() P {
+	return i.priority
+}
+
+// Queued reports whether the item is still in its queue.
+func (i *Item[V, P]) Queued() bool {
+	return i.index >= 0
+}
+
+// Queue orders values by priority.
+type Queue[V, P any] struct {
+	items  []*Item[V, P]
+	before func(a, b P) bool
+	seq    uint64
+}
+
+// New returns a queue popping first the values whose priority is less
+// than the others according to less.
+func New[V, P any](less func(a, b P) bool) *Queue[V, P] {
+	return &Queue[V, P]{before: less}
+}
+
+// NewMin returns a queue popping the lowest priorities first.
+func NewMin[V any, P cmp.Ordered]() *Queue[V, P] {
+	return New[V](cmp.Less[P])
+}
+
+// NewMax returns a queue popping the highest priorities first.
+func NewMax[V any, P cmp.Ordered]() *Queue[V, P] {
+	return New[V](func(a, b P) bool { return cmp.Less(b, a) })
+}
+
+// Len returns the number of queued values.
+func (q *Queue[V, P]) Len() int {
+	return len(q.items)
+}
+
+// Push queues value with priority.
+func (q *Queue[V, P]) Push(value V, priority P) *Item[V, P] {
+	q.seq++
+	item := &Item[V, P]{
+		Value: value, priority: priority, seq: q.seq, index: len(q.items),
+	}
+	q.items = append(q.items, item)
+	q.up(item.index)
+
+	return item
+}
+
+// Peek returns the next item without removing it, and false when the
+// queue is empty.
+func (q *Queue[V, P]) Peek() (*Item[V, P], bool) {
+	if q.Len() == 0 {
+		return nil, false
+	}
+
+	return q.items[0], true
+}
+
+// Pop removes and returns the next item, or nil when the queue is empty.
+func (q *Queue[V, P]) Pop() *Item[V, P] {
+	if q.Len() == 0 {
+		return nil
+	}
+
+	return q.removeAt(0)
+}
+
+// Update changes the priority of a queued item, which then comes after
+// the items of equal priority. It reports whether the item was queued.
+func (q *Queue[V, P]) Update(item *Item[V, P], priority P) bool {
+	if !q.owns(item) {
+		return false
+	}
+
+	q.seq++
+	item.priority, item.seq = priority, q.seq
+	q.fix(item.index)
+
+	return true
+}
+
+// Remove removes a queued item and reports whether it was queued.
+func (q *Queue[V, P]) Remove(item *Item[V, P]) bool {
+	if !q.owns(item) {
+		return false
+	}
+
+	q.removeAt(item.index)
+
+	return true
+}
+
+func (q *Queue[V, P]) owns(item *Item[V, P]) bool {
+	return item.Queued() && item.index < q.Len() &&
+		q.items[item.index] == item
+}
@@ -0,0 +1,117 @@
+package pq_test
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/pq"
+	"example.com/go-template/util/quick"
+)
+
+// drain pops the values of q in order.
+func drain[V, P any](q *pq.Queue[V, P]) []V {
+	values := make([]V, 0, q.Len())
+	for q.Len() > 0 {
+		values = append(values, q.Pop().Value)
+	}
+
+	return values
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	q := pq.NewMin[string, int]()
+	assert.Nil(t, q.Pop())
+
+	_, ok := q.Peek()
+	assert.False(t, ok)
+
+	q.Push("lint", 2)
+	q.Push("build", 1)
+	q.Push("test", 2)
+	q.Push("vet", 2)
+
+	next, ok := q.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "build", next.Value)
+	assert.Equal(t, 1, next.Priority())
+	assert.Equal(t, 4, q.Len())
+
+	assert.Equal(t, []string{"build", "lint", "test", "vet"}, drain(q),
+		"equal priorities pop in push order")
+}
+
+func TestQueueUpdate(t *testing.T) {
+	t.Parallel()
+
+	q := pq.NewMax[string, float64]()
+	low := q.Push("low", 1)
+	q.Push("mid", 5)
+	high := q.Push("high", 9)
+	q.Push("other", 5)
+
+	assert.True(t, q.Update(low, 10))
+	assert.True(t, q.Update(high, 5))
+	assert.Equal(t, []string{"low", "mid", "other", "high"}, drain(q))
+
+	assert.False(t, low.Queued())
+	assert.False(t, q.Update(low, 1), "popped items are not updated")
+}
+
+func TestQueueRemove(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	q := pq.New[string](time.Time.Before)
+	q.Push("a", start.Add(time.Hour))
+	b := q.Push("b", start)
+	q.Push("c", start.Add(time.Minute))
+
+	assert.True(t, q.Remove(b))
+	assert.False(t, q.Remove(b))
+	assert.False(t, b.Queued())
+	other := pq.New[string](time.Time.Before)
+	assert.False(t, other.Remove(q.Push("d", start)),
+		"items of other queues are not removed")
+	assert.Equal(t, []string{"d", "c", "a"}, drain(q))
+}
+
+func TestQueueProperty(t *testing.T) {
+	t.Parallel()
+
+	ops := quick.SliceOf(quick.Ints(-5, 5), 100)
+	quick.Check(t, ops, func(t *quick.T, priorities []int) {
+		q := pq.NewMin[int, int]()
+		seqs := make([]int, len(priorities))
+
+		var items []*pq.Item[int, int]
+		for i, p := range priorities {
+			items = append(items, q.Push(i, p))
+			seqs[i] = i
+		}
+
+		// Every third item moves to the back of its priority class.
+		for i := 0; i < len(items); i += 3 {
+			q.Update(items[i], items[i].Priority())
+			seqs[i] = len(items) + i
+		}
+
+		want := make([]int, len(priorities))
+		for i := range want {
+			want[i] = i
+		}
+
+		slices.SortFunc(want, func(a, b int) int {
+			return cmp.Or(cmp.Compare(priorities[a], priorities[b]),
+				cmp.Compare(seqs[a], seqs[b]))
+		})
+
+		assert.Equal(t, want, drain(q))
+	})
+}
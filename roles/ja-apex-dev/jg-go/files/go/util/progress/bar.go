@@ -0,0 +1,194 @@
+package progress
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/term"
+)
+
+// Bar widths, in columns.
+const (
+	minBarWidth = 10
+	maxBarWidth = 40
+)
+
+// Bar tracks work of a known total, such as bytes to download or items to
+// process. It is safe for concurrent use.
+type Bar struct {
+	settings
+
+	mu       sync.Mutex
+	total    int64
+	current  int64
+	start    time.Time
+	drawn    time.Time
+	finished bool
+}
+
+// NewBar returns a bar for total units of work. A total of zero or less
+// shows the amount done without percentage nor ETA.
+func NewBar(total int64, opts ...Option) *Bar {
+	b := &Bar{settings: newSettings(opts), total: total}
+	b.start = b.clock.Now()
+
+	return b
+}
+
+// Add records n more units of work done, never going below zero.
+func (b *Bar) Add(n int64) {
+	b.update(func() {
+		b.current = max(b.current+n, 0)
+	})
+}
+
+// Set records that n units of work are done, clamped to [0, total] when
+// the total is known.
+func (b *Bar) Set(n int64) {
+	b.update(func() {
+		if b.total > 0 {
+			n = min(n, b.total)
+		}
+
+		b.current = max(n, 0)
+	})
+}
+
+// SetTotal changes the total, for work discovered on the way.
+func (b *Bar) SetTotal(total int64) {
+	b.update(func() {
+		b.total = total
+	})
+}
+
+// Write implements io.Writer by adding len(p) units, so that a bar can
+// observe a copy through io.MultiWriter or io.TeeReader.
+func (b *Bar) Write(p []byte) (int, error) {
+	b.Add(int64(len(p)))
+
+	return len(p), nil
+}
+
+// Current returns the units of work done.
+func (b *Bar) Current() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.current
+}
+
+// ETA estimates the remaining time from the average rate so far. It
+// reports false while no estimate is possible.
+func (b *Bar) ETA() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.estimate(b.clock.Now())
+}
+
+// Finish draws the final state and ends the line. Later updates are
+// ignored.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.finished {
+		return
+	}
+
+	b.finished = true
+	took := elapsed(b.clock.Since(b.start))
+
+	if b.terminal {
+		b.redraw(b.line(b.clock.Now()) + "\n")
+
+		return
+	}
+
+	b.log(fmt.Sprintf("%sdone, %s in %s", b.prefix(), b.amount(b.current),
+		took), "current", b.current, "elapsed", took)
+}
+
+// update applies change and redraws when the interval has passed.
+func (b *Bar) update(change func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.finished {
+		return
+	}
+
+	change()
+
+	now := b.clock.Now()
+	if !b.drawn.IsZero() && now.Sub(b.drawn) < b.interval() {
+		return
+	}
+
+	b.drawn = now
+
+	if b.terminal {
+		b.redraw(b.line(now))
+
+		return
+	}
+
+	b.log(b.line(now), "current", b.current, "total", b.total,
+		"percent", b.percent())
+}
+
+func (b *Bar) estimate(now time.Time) (time.Duration, bool) {
+	passed := now.Sub(b.start)
+	if b.total <= 0 || b.current <= 0 || passed <= 0 {
+		return 0, false
+	}
+
+	left := max(b.total-b.current, 0)
+	seconds := float64(left) * passed.Seconds() / float64(b.current)
+
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+func (b *Bar) percent() int {
+	if b.total <= 0 {
+		return 0
+	}
+
+	return int(min(b.current*100/b.total, 100))
+}
+
+// line renders the status: label, bar, percentage, amounts and ETA. The
+// bar only appears on terminals and fills the width left by the rest.
+func (b *Bar) line(now time.Time) string {
+	if b.total <= 0 {
+		return b.prefix() + b.amount(b.current)
+	}
+
+	status := fmt.Sprintf("%3d%% %s/%s", b.percent(), b.amount(b.current),
+		b.amount(b.total))
+	if eta, ok := b.estimate(now); ok && b.current < b.total {
+		status += " ETA " + elapsed(eta)
+	}
+
+	if !b.terminal {
+		return b.prefix() + strings.TrimLeft(status, " ")
+	}
+
+	width := term.Width(b.out) - len(b.prefix()) - len(status) - len("[] ")
+
+	return b.prefix() + b.bar(min(max(width, minBarWidth), maxBarWidth)) +
+		" " + status
+}
+
+// bar draws width cells filled in proportion to the work done.
+func (b *Bar) bar(width int) string {
+	filled := int(int64(width) * min(b.current, b.total) / b.total)
+	if filled == width {
+		return "[" + strings.Repeat("=", width) + "]"
+	}
+
+	return "[" + strings.Repeat("=", filled) + ">" +
+		strings.Repeat(" ", width-filled-1) + "]"
+}
@@ -0,0 +1,155 @@
+package progress_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/progress"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// syncBuffer is a buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestBarTerminal(t *testing.T) {
+	t.Setenv("COLUMNS", "50")
+
+	var out bytes.Buffer
+
+	fake := clock.NewFake(epoch)
+	bar := progress.NewBar(100, progress.WithOutput(&out),
+		progress.WithTerminal(), progress.WithClock(fake),
+		progress.WithLabel("copy"))
+
+	fake.Advance(2 * time.Second)
+	bar.Add(50)
+	bar.Add(10)
+	assert.Equal(t,
+		"\r\x1b[Kcopy [============>           ]  50% 50/100 ETA 2s",
+		out.String())
+
+	out.Reset()
+	fake.Advance(time.Second)
+	bar.Add(50)
+	bar.Finish()
+	bar.Add(1)
+	assert.Equal(t, ""+
+		"\r\x1b[Kcopy [==============================] 100% 110/100"+
+		"\r\x1b[Kcopy [==============================] 100% 110/100\n",
+		out.String())
+	assert.Equal(t, int64(110), bar.Current())
+}
+
+func TestBarSetClamps(t *testing.T) {
+	t.Parallel()
+
+	// Every update draws the terminal bar, which needs a valid amount.
+	fake := clock.NewFake(epoch)
+	bar := progress.NewBar(100, progress.WithOutput(io.Discard),
+		progress.WithTerminal(), progress.WithClock(fake))
+
+	bar.Set(-5)
+	assert.Zero(t, bar.Current())
+
+	fake.Advance(time.Second)
+	bar.Set(500)
+	assert.Equal(t, int64(100), bar.Current())
+
+	fake.Advance(time.Second)
+	bar.Add(-200)
+	assert.Zero(t, bar.Current())
+}
+
+func TestBarLog(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	fake := clock.NewFake(epoch)
+	bar := progress.NewBar(4<<20, progress.WithOutput(&out),
+		progress.WithClock(fake), progress.WithBytes(),
+		progress.WithLabel("go.tar.gz"))
+
+	fake.Advance(time.Second)
+	bar.Add(1 << 20)
+	fake.Advance(5 * time.Second)
+	bar.Add(1 << 20)
+	fake.Advance(5 * time.Second)
+	bar.Add(1 << 20)
+	bar.Finish()
+
+	assert.Equal(t, []string{
+		"go.tar.gz 25% 1.0 MiB/4.0 MiB ETA 3s",
+		"go.tar.gz 75% 3.0 MiB/4.0 MiB ETA 4s",
+		"go.tar.gz done, 3.0 MiB in 11s",
+	}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+}
+
+func TestBarLogger(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	bar := progress.NewBar(10, progress.WithLogger(sink.Logger()),
+		progress.WithOutput(io.Discard), progress.WithLabel("items"),
+		progress.WithClock(clock.NewFake(epoch)))
+
+	bar.Set(4)
+	bar.Finish()
+
+	entries := sink.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "progress", entries[0].Message)
+	assert.Equal(t, "items", entries[0].Attrs["label"])
+	assert.Equal(t, int64(40), entries[0].Attrs["percent"])
+	assert.Equal(t, int64(4), entries[1].Attrs["current"])
+}
+
+func TestBarWriteAndETA(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	bar := progress.NewBar(0, progress.WithOutput(io.Discard),
+		progress.WithClock(fake))
+
+	_, err := io.Copy(bar, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), bar.Current())
+
+	_, ok := bar.ETA()
+	assert.False(t, ok)
+
+	bar.SetTotal(20)
+	fake.Advance(10 * time.Second)
+
+	eta, ok := bar.ETA()
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Second, eta)
+}
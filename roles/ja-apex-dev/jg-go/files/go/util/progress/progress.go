@@ -0,0 +1,174 @@
+// Package progress reports the advancement of long operations with a bar
+// for measurable work and a spinner for indeterminate work:
+//
+//	bar := progress.NewBar(size, progress.WithLabel("go.tar.gz"),
+//		progress.WithBytes())
+//	_, err := io.Copy(io.MultiWriter(file, bar), body)
+//	bar.Finish()
+//
+// On a terminal, both redraw a single line in place. Otherwise, such as in
+// CI logs, they degrade to a status line every log interval, written to
+// the output or to a slog.Logger.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/term"
+)
+
+// Default intervals between updates.
+const (
+	DefaultRefresh     = 100 * time.Millisecond
+	DefaultLogInterval = 10 * time.Second
+)
+
+// clearLine moves to the start of the line and erases it.
+const clearLine = "\r\x1b[K"
+
+// Option configures bars and spinners.
+type Option func(*settings)
+
+type settings struct {
+	out         io.Writer
+	label       string
+	bytes       bool
+	terminal    bool
+	clock       clock.Clock
+	refresh     time.Duration
+	logInterval time.Duration
+	logger      *slog.Logger
+}
+
+// WithOutput sets the destination of the progress display, os.Stderr by
+// default.
+func WithOutput(w io.Writer) Option {
+	return func(s *settings) {
+		s.out = w
+	}
+}
+
+// WithLabel sets the text shown before the progress.
+func WithLabel(label string) Option {
+	return func(s *settings) {
+		s.label = label
+	}
+}
+
+// WithBytes formats amounts as byte sizes, such as "4.5 MiB", instead of
+// item counts.
+func WithBytes() Option {
+	return func(s *settings) {
+		s.bytes = true
+	}
+}
+
+// WithTerminal draws in place even when the output is not detected as a
+// terminal, as for pseudo terminals.
+func WithTerminal() Option {
+	return func(s *settings) {
+		s.terminal = true
+	}
+}
+
+// WithClock sets the time source, the system clock by default.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.clock = c
+	}
+}
+
+// WithRefresh sets the minimum interval between terminal redraws,
+// DefaultRefresh by default.
+func WithRefresh(d time.Duration) Option {
+	return func(s *settings) {
+		s.refresh = d
+	}
+}
+
+// WithLogInterval sets the interval between status lines when the output
+// is not a terminal, DefaultLogInterval by default.
+func WithLogInterval(d time.Duration) Option {
+	return func(s *settings) {
+		s.logInterval = d
+	}
+}
+
+// WithLogger sends the status lines written when the output is not a
+// terminal to logger, as info records with structured attributes.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *settings) {
+		s.logger = logger
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{
+		out:         os.Stderr,
+		refresh:     DefaultRefresh,
+		logInterval: DefaultLogInterval,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	s.clock = clock.OrReal(s.clock)
+	s.terminal = s.terminal || term.IsTerminal(s.out)
+
+	return s
+}
+
+// interval returns the minimum delay between two updates.
+func (s *settings) interval() time.Duration {
+	if s.terminal {
+		return s.refresh
+	}
+
+	return s.logInterval
+}
+
+// amount formats n in the configured unit.
+func (s *settings) amount(n int64) string {
+	if s.bytes {
+		return util.FormatBytes(n)
+	}
+
+	return fmt.Sprint(n)
+}
+
+// redraw replaces the current terminal line with line.
+func (s *settings) redraw(line string) {
+	_, _ = io.WriteString(s.out, clearLine+line)
+}
+
+// log writes a status line, or a record when a logger is configured.
+func (s *settings) log(line string, attrs ...any) {
+	if s.logger != nil {
+		s.logger.Info("progress", append([]any{"label", s.label}, attrs...)...)
+
+		return
+	}
+
+	_, _ = fmt.Fprintln(s.out, line)
+}
+
+// prefix returns the label followed by a space, if any.
+func (s *settings) prefix() string {
+	if s.label == "" {
+		return ""
+	}
+
+	return s.label + " "
+}
+
+// elapsed formats the time since start, rounded to seconds.
+func elapsed(d time.Duration) string {
+	return util.HumanDuration(d.Round(time.Second))
+}
@@ -0,0 +1,115 @@
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// spinnerFrames is the animation of terminal spinners.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner shows that indeterminate work is under way. It is safe for
+// concurrent use.
+type Spinner struct {
+	settings
+
+	mu    sync.Mutex
+	frame int
+	start time.Time
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewSpinner returns a stopped spinner.
+func NewSpinner(opts ...Option) *Spinner {
+	return &Spinner{settings: newSettings(opts)}
+}
+
+// Start draws the spinner and animates it until Stop. On other outputs
+// than terminals, it writes a status line every log interval instead.
+// Starting a running spinner does nothing.
+func (s *Spinner) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+
+	s.start = s.clock.Now()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	ticker := s.clock.NewTicker(s.interval())
+
+	s.draw()
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C():
+				s.mu.Lock()
+				s.frame++
+				s.draw()
+				s.mu.Unlock()
+			}
+		}
+	}(s.stop, s.done)
+}
+
+// SetLabel changes the label, showing the current step.
+func (s *Spinner) SetLabel(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.label = label
+}
+
+// Stop ends the animation and reports the time taken. Stopping a stopped
+// spinner does nothing.
+func (s *Spinner) Stop() {
+	s.mu.Lock()
+
+	stop, done := s.stop, s.done
+	if stop == nil {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.stop = nil
+	close(stop)
+	s.mu.Unlock()
+
+	<-done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	took := elapsed(s.clock.Since(s.start))
+	line := s.prefix() + "done in " + took
+
+	if s.terminal {
+		s.redraw(line + "\n")
+
+		return
+	}
+
+	s.log(line, "elapsed", took)
+}
+
+// draw shows the current frame, or a status line on other outputs.
+func (s *Spinner) draw() {
+	if s.terminal {
+		s.redraw(spinnerFrames[s.frame%len(spinnerFrames)] + " " + s.label)
+
+		return
+	}
+
+	took := elapsed(s.clock.Since(s.start))
+	s.log(s.prefix()+"running for "+took, "elapsed", took)
+}
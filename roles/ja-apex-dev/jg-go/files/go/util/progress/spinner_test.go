@@ -0,0 +1,68 @@
+package progress_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/progress"
+	"example.com/go-template/util/testx"
+)
+
+func TestSpinnerTerminal(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+
+	fake := clock.NewFake(epoch)
+	spinner := progress.NewSpinner(progress.WithOutput(&out),
+		progress.WithTerminal(), progress.WithClock(fake),
+		progress.WithLabel("resolving"))
+
+	spinner.Start()
+	spinner.Start()
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+
+	spinner.SetLabel("installing")
+	fake.Advance(progress.DefaultRefresh)
+	testx.RequireEventually(t, func() bool {
+		return strings.Contains(out.String(), "⠙ installing")
+	}, time.Second)
+
+	fake.Advance(2 * time.Second)
+	spinner.Stop()
+	spinner.Stop()
+
+	assert.True(t, strings.HasPrefix(out.String(), "\r\x1b[K⠋ resolving"))
+	assert.True(t, strings.HasSuffix(out.String(),
+		"\r\x1b[Kinstalling done in 2s\n"))
+}
+
+func TestSpinnerLog(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+
+	fake := clock.NewFake(epoch)
+	spinner := progress.NewSpinner(progress.WithOutput(&out),
+		progress.WithClock(fake), progress.WithLabel("provisioning"))
+
+	spinner.Start()
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+
+	fake.Advance(progress.DefaultLogInterval)
+	testx.RequireEventually(t, func() bool {
+		return strings.Count(out.String(), "\n") == 2
+	}, time.Second)
+
+	spinner.Stop()
+
+	assert.Equal(t, ""+
+		"provisioning running for 0s\n"+
+		"provisioning running for 10s\n"+
+		"provisioning done in 10s\n", out.String())
+}
@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"example.com/go-template/util/pq"
+)
+
+// Memory is a Store within the process, for tests and jobs that may be
+// lost on exit.
+type Memory struct {
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*pq.Item[*Job, *Job]
+	queues map[string]*pq.Queue[*Job, *Job]
+	dead   map[int64]*Job
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		jobs:   map[int64]*pq.Item[*Job, *Job]{},
+		queues: map[string]*pq.Queue[*Job, *Job]{},
+		dead:   map[int64]*Job{},
+	}
+}
+
+// Enqueue implements Store.
+func (m *Memory) Enqueue(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	job.ID = m.nextID
+
+	q, ok := m.queues[job.Queue]
+	if !ok {
+		q = pq.New[*Job](before)
+		m.queues[job.Queue] = q
+	}
+
+	stored := clone(job)
+	m.jobs[job.ID] = q.Push(stored, stored)
+
+	return nil
+}
+
+// Claim implements Store.
+func (m *Memory) Claim(
+	_ context.Context, queue string, n int, lease Lease,
+) ([]*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.queues[queue]
+	if !ok {
+		return nil, nil
+	}
+
+	var ready []*pq.Item[*Job, *Job]
+
+	for len(ready) < n {
+		next, ok := q.Peek()
+		if !ok || next.Value.RunAt.After(lease.Now) {
+			break
+		}
+
+		ready = append(ready, q.Pop())
+	}
+
+	claimed := make([]*Job, 0, len(ready))
+	for _, item := range ready {
+		job := item.Value
+		job.Attempts++
+		job.RunAt = lease.Until
+		m.jobs[job.ID] = q.Push(job, job)
+		claimed = append(claimed, clone(job))
+	}
+
+	return claimed, nil
+}
+
+// Complete implements Store.
+func (m *Memory) Complete(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.claimed(job)
+	if err != nil {
+		return err
+	}
+
+	m.queues[job.Queue].Remove(item)
+	delete(m.jobs, job.ID)
+
+	return nil
+}
+
+// Retry implements Store.
+func (m *Memory) Retry(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.claimed(job)
+	if err != nil {
+		return err
+	}
+
+	stored := item.Value
+	stored.RunAt, stored.LastError = job.RunAt, job.LastError
+	m.queues[job.Queue].Update(item, stored)
+
+	return nil
+}
+
+// Bury implements Store.
+func (m *Memory) Bury(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.claimed(job)
+	if err != nil {
+		return err
+	}
+
+	m.queues[job.Queue].Remove(item)
+	item.Value.LastError = job.LastError
+	m.dead[job.ID] = item.Value
+	delete(m.jobs, job.ID)
+
+	return nil
+}
+
+// claimed returns the item of job while it holds its claim. The caller
+// holds the lock.
+func (m *Memory) claimed(job *Job) (*pq.Item[*Job, *Job], error) {
+	item, ok := m.jobs[job.ID]
+
+	switch {
+	case !ok:
+		return nil, ErrNotFound
+	case item.Value.Attempts != job.Attempts:
+		return nil, fmt.Errorf("%w: attempt %d of job %d, now %d",
+			ErrLeaseLost, job.Attempts, job.ID, item.Value.Attempts)
+	default:
+		return item, nil
+	}
+}
+
+// Dead implements Store.
+func (m *Memory) Dead(_ context.Context, queue string) ([]*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var dead []*Job
+
+	for _, job := range m.dead {
+		if job.Queue == queue {
+			dead = append(dead, clone(job))
+		}
+	}
+
+	slices.SortFunc(dead, func(a, b *Job) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return dead, nil
+}
+
+// compareJobs orders jobs by RunAt, then ID.
+func compareJobs(a, b *Job) int {
+	return cmp.Or(a.RunAt.Compare(b.RunAt), cmp.Compare(a.ID, b.ID))
+}
+
+// before reports whether a is claimed before b.
+func before(a, b *Job) bool {
+	return compareJobs(a, b) < 0
+}
+
+// sortJobs orders jobs by RunAt, then ID.
+func sortJobs(jobs []*Job) {
+	slices.SortFunc(jobs, compareJobs)
+}
+
+func clone(job *Job) *Job {
+	c := *job
+
+	return &c
+}
@@ -0,0 +1,180 @@
+// Package queue persists background jobs and runs them with workers:
+//
+//	store, err := queue.NewSQLite(ctx, db)
+//	emails := queue.New(store, "emails")
+//	_, err = emails.Enqueue(ctx, payload, queue.WithDelay(time.Minute))
+//
+//	worker := emails.Worker(send, queue.WithConcurrency(8))
+//	worker.Register(runner)
+//
+// A claimed job is leased to its worker, which deletes it once handled.
+// Failed jobs are retried with exponential backoff until their attempts
+// are exhausted, then moved to the dead-letter list, and the jobs of a
+// crashed worker are claimed again when their lease expires.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// DefaultMaxAttempts bounds the attempts of the jobs enqueued without
+// WithMaxAttempts.
+const DefaultMaxAttempts = 5
+
+var (
+	// ErrNotFound is returned by stores for jobs they do not hold.
+	ErrNotFound = errors.New("queue: job not found")
+	// ErrLeaseLost is returned by stores for jobs claimed again since,
+	// after the lease of their worker expired.
+	ErrLeaseLost = errors.New("queue: job lease lost")
+)
+
+// Job is a unit of work of a queue.
+type Job struct {
+	// ID identifies the job within its store.
+	ID int64
+	// Queue is the name of the queue holding the job.
+	Queue string
+	// Payload is the input of the handler, usually JSON.
+	Payload []byte
+	// Attempts counts the claims of the job, including the current one.
+	Attempts int
+	// MaxAttempts bounds Attempts, after which the job is dead.
+	MaxAttempts int
+	// RunAt is the earliest time the job is claimed, or the end of its
+	// lease while claimed.
+	RunAt time.Time
+	// LastError is the error of the last failed attempt.
+	LastError string
+	// CreatedAt is the time the job was enqueued.
+	CreatedAt time.Time
+}
+
+// Lease is the time window of a claim.
+type Lease struct {
+	// Now selects the jobs whose RunAt is not after it.
+	Now time.Time
+	// Until hides the claimed jobs from other claims.
+	Until time.Time
+}
+
+// Store persists the jobs of any number of queues. Implementations are
+// safe for concurrent use.
+type Store interface {
+	// Enqueue saves job, setting its ID.
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim returns up to n jobs of queue ready at lease.Now, by RunAt
+	// then ID, moving their RunAt to lease.Until and counting the attempt.
+	Claim(
+		ctx context.Context, queue string, n int, lease Lease,
+	) ([]*Job, error)
+	// Complete deletes a handled job. Like Retry and Bury, it applies only
+	// to the claim whose Attempts job carries, and returns ErrLeaseLost
+	// once the job has been claimed again.
+	Complete(ctx context.Context, job *Job) error
+	// Retry saves the RunAt and LastError of a failed job.
+	Retry(ctx context.Context, job *Job) error
+	// Bury moves a job with its LastError to the dead-letter list.
+	Bury(ctx context.Context, job *Job) error
+	// Dead returns the dead jobs of queue, by ID.
+	Dead(ctx context.Context, queue string) ([]*Job, error)
+}
+
+// Option configures New.
+type Option func(*Queue)
+
+// WithClock sets the clock timing the jobs, clock.Real by default.
+func WithClock(c clock.Clock) Option {
+	return func(q *Queue) {
+		q.clock = c
+	}
+}
+
+// EnqueueOption configures Queue.Enqueue.
+type EnqueueOption func(*Job)
+
+// WithDelay postpones the first attempt by d.
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(j *Job) {
+		j.RunAt = j.RunAt.Add(d)
+	}
+}
+
+// WithRunAt postpones the first attempt until t.
+func WithRunAt(t time.Time) EnqueueOption {
+	return func(j *Job) {
+		j.RunAt = t
+	}
+}
+
+// WithMaxAttempts bounds the attempts of the job instead of
+// DefaultMaxAttempts.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(j *Job) {
+		j.MaxAttempts = n
+	}
+}
+
+// Queue is a named queue of a store.
+type Queue struct {
+	name  string
+	store Store
+	clock clock.Clock
+}
+
+// New returns the queue name of store.
+func New(store Store, name string, opts ...Option) *Queue {
+	q := &Queue{name: name, store: store, clock: clock.Real()}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// Name returns the name of the queue.
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Enqueue adds a job with payload, ready now unless delayed.
+func (q *Queue) Enqueue(
+	ctx context.Context, payload []byte, opts ...EnqueueOption,
+) (*Job, error) {
+	now := q.clock.Now()
+	job := &Job{
+		Queue:       q.name,
+		Payload:     payload,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+
+	if err := q.store.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("queue: enqueue to %s: %w", q.name, err)
+	}
+
+	return job, nil
+}
+
+// Dead returns the jobs whose attempts are exhausted, by ID.
+func (q *Queue) Dead(ctx context.Context) ([]*Job, error) {
+	jobs, err := q.store.Dead(ctx, q.name)
+	if err != nil {
+		return nil, fmt.Errorf("queue: dead jobs of %s: %w", q.name, err)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLiteTable is the table of the jobs of a SQLite store.
+const SQLiteTable = "queue_jobs"
+
+const (
+	sqliteSchema = `CREATE TABLE IF NOT EXISTS ` + SQLiteTable + ` (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	queue TEXT NOT NULL,
+	payload BLOB,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL,
+	run_at INTEGER NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	dead INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS ` + SQLiteTable + `_ready
+	ON ` + SQLiteTable + ` (queue, dead, run_at, id)`
+
+	jobColumns = `id, queue, payload, attempts, max_attempts, run_at,
+	last_error, created_at`
+)
+
+// SQLite is a Store in a SQLite database, which the jobs survive restarts
+// in. A single statement claims the jobs, so that concurrent workers,
+// even of several processes, never share one.
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite returns a store in db, creating its table if needed.
+func NewSQLite(ctx context.Context, db *sql.DB) (*SQLite, error) {
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return nil, fmt.Errorf("queue: create table: %w", err)
+	}
+
+	return &SQLite{db: db}, nil
+}
+
+// Enqueue implements Store.
+func (s *SQLite) Enqueue(ctx context.Context, job *Job) error {
+	result, err := s.db.ExecContext(ctx, `INSERT INTO `+SQLiteTable+
+		` (queue, payload, attempts, max_attempts, run_at, last_error,
+		created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		job.Queue, job.Payload, job.Attempts, job.MaxAttempts,
+		job.RunAt.UnixNano(), job.LastError, job.CreatedAt.UnixNano())
+	if err != nil {
+		return err
+	}
+
+	job.ID, err = result.LastInsertId()
+
+	return err
+}
+
+// Claim implements Store.
+func (s *SQLite) Claim(
+	ctx context.Context, queue string, n int, lease Lease,
+) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `UPDATE `+SQLiteTable+`
+		SET attempts = attempts + 1, run_at = ?
+		WHERE id IN (SELECT id FROM `+SQLiteTable+`
+			WHERE queue = ? AND dead = 0 AND run_at <= ?
+			ORDER BY run_at, id LIMIT ?)
+		RETURNING `+jobColumns,
+		lease.Until.UnixNano(), queue, lease.Now.UnixNano(), n)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := scanJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	sortJobs(jobs)
+
+	return jobs, nil
+}
+
+// Complete implements Store.
+func (s *SQLite) Complete(ctx context.Context, job *Job) error {
+	return s.update(ctx, job, `DELETE FROM `+SQLiteTable)
+}
+
+// Retry implements Store.
+func (s *SQLite) Retry(ctx context.Context, job *Job) error {
+	return s.update(ctx, job, `UPDATE `+SQLiteTable+
+		` SET run_at = ?, last_error = ?`, job.RunAt.UnixNano(), job.LastError)
+}
+
+// Bury implements Store.
+func (s *SQLite) Bury(ctx context.Context, job *Job) error {
+	return s.update(ctx, job, `UPDATE `+SQLiteTable+
+		` SET dead = 1, last_error = ?`, job.LastError)
+}
+
+// Dead implements Store.
+func (s *SQLite) Dead(ctx context.Context, queue string) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+jobColumns+` FROM `+
+		SQLiteTable+` WHERE queue = ? AND dead = 1 ORDER BY id`, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanJobs(rows)
+}
+
+// update runs a statement changing job while it holds its claim,
+// appending the condition to query. When it changes nothing, it reports
+// ErrLeaseLost if the job was claimed again, and ErrNotFound otherwise.
+func (s *SQLite) update(
+	ctx context.Context, job *Job, query string, args ...any,
+) error {
+	result, err := s.db.ExecContext(ctx,
+		query+` WHERE id = ? AND attempts = ? AND dead = 0`,
+		append(args, job.ID, job.Attempts)...)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	var attempts int
+
+	err = s.db.QueryRowContext(ctx, `SELECT attempts FROM `+SQLiteTable+
+		` WHERE id = ? AND dead = 0`, job.ID).Scan(&attempts)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("%w: attempt %d of job %d, now %d", ErrLeaseLost,
+			job.Attempts, job.ID, attempts)
+	}
+}
+
+// scanJobs reads and closes rows of jobColumns.
+func scanJobs(rows *sql.Rows) ([]*Job, error) {
+	defer func() { _ = rows.Close() }()
+
+	var jobs []*Job
+
+	for rows.Next() {
+		var (
+			job            Job
+			runAt, created int64
+		)
+
+		err := rows.Scan(&job.ID, &job.Queue, &job.Payload, &job.Attempts,
+			&job.MaxAttempts, &runAt, &job.LastError, &created)
+		if err != nil {
+			return nil, err
+		}
+
+		job.RunAt, job.CreatedAt = time.Unix(0, runAt), time.Unix(0, created)
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
@@ -0,0 +1,164 @@
+package queue_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"example.com/go-template/util/queue"
+)
+
+var start = time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+
+func openSQLite(t *testing.T) *queue.SQLite {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "queue.db")+
+		"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := queue.NewSQLite(t.Context(), db)
+	require.NoError(t, err)
+
+	return store
+}
+
+func stores(t *testing.T) map[string]queue.Store {
+	t.Helper()
+
+	return map[string]queue.Store{
+		"memory": queue.NewMemory(),
+		"sqlite": openSQLite(t),
+	}
+}
+
+// seed enqueues the jobs a, b and c to "emails", a a minute later than
+// the others, and one to "other".
+func seed(t *testing.T, store queue.Store) {
+	t.Helper()
+
+	for i, delay := range []time.Duration{time.Minute, 0, 0} {
+		require.NoError(t, store.Enqueue(t.Context(), &queue.Job{
+			Queue: "emails", Payload: []byte{byte('a' + i)},
+			MaxAttempts: 3, RunAt: start.Add(delay), CreatedAt: start,
+		}))
+	}
+
+	require.NoError(t, store.Enqueue(t.Context(), &queue.Job{
+		Queue: "other", MaxAttempts: 1, RunAt: start,
+	}))
+}
+
+func TestStoreClaim(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			seed(t, store)
+
+			lease := queue.Lease{Now: start, Until: start.Add(time.Hour)}
+
+			jobs, err := store.Claim(ctx, "emails", 10, lease)
+			require.NoError(t, err)
+			require.Len(t, jobs, 2)
+			assert.Equal(t, "b", string(jobs[0].Payload))
+			assert.Equal(t, "c", string(jobs[1].Payload))
+			assert.Equal(t, 1, jobs[0].Attempts)
+			assert.True(t, jobs[0].RunAt.Equal(lease.Until))
+			assert.True(t, jobs[0].CreatedAt.Equal(start))
+
+			again, err := store.Claim(ctx, "emails", 10, lease)
+			require.NoError(t, err)
+			assert.Empty(t, again, "leased jobs are hidden")
+
+			require.NoError(t, store.Complete(ctx, jobs[0]))
+			require.ErrorIs(t, store.Complete(ctx, jobs[0]), queue.ErrNotFound)
+
+			jobs[1].RunAt, jobs[1].LastError = start, "timeout"
+			require.NoError(t, store.Retry(ctx, jobs[1]))
+
+			jobs, err = store.Claim(ctx, "emails", 1, queue.Lease{
+				Now: start.Add(time.Minute), Until: start.Add(time.Hour),
+			})
+			require.NoError(t, err)
+			require.Len(t, jobs, 1)
+			assert.Equal(t, "c", string(jobs[0].Payload))
+			assert.Equal(t, 2, jobs[0].Attempts)
+			assert.Equal(t, "timeout", jobs[0].LastError)
+		})
+	}
+}
+
+func TestStoreLeaseLost(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			seed(t, store)
+
+			claim := func(now time.Time) *queue.Job {
+				jobs, err := store.Claim(ctx, "other", 1,
+					queue.Lease{Now: now, Until: now.Add(time.Minute)})
+				require.NoError(t, err)
+				require.Len(t, jobs, 1)
+
+				return jobs[0]
+			}
+
+			// The lease of stale expires, and current claims the job.
+			stale := claim(start)
+			current := claim(start.Add(time.Hour))
+
+			require.ErrorIs(t, store.Complete(ctx, stale), queue.ErrLeaseLost)
+			require.ErrorIs(t, store.Retry(ctx, stale), queue.ErrLeaseLost)
+			require.ErrorIs(t, store.Bury(ctx, stale), queue.ErrLeaseLost)
+
+			require.NoError(t, store.Complete(ctx, current))
+			require.ErrorIs(t, store.Complete(ctx, stale), queue.ErrNotFound)
+		})
+	}
+}
+
+func TestStoreBury(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			seed(t, store)
+
+			jobs, err := store.Claim(ctx, "emails", 1,
+				queue.Lease{Now: start, Until: start.Add(time.Hour)})
+			require.NoError(t, err)
+			require.Len(t, jobs, 1)
+
+			jobs[0].LastError = "failed"
+			require.NoError(t, store.Bury(ctx, jobs[0]))
+			require.ErrorIs(t, store.Retry(ctx, jobs[0]), queue.ErrNotFound)
+
+			dead, err := store.Dead(ctx, "emails")
+			require.NoError(t, err)
+			require.Len(t, dead, 1)
+			assert.Equal(t, "b", string(dead[0].Payload))
+			assert.Equal(t, "failed", dead[0].LastError)
+
+			empty, err := store.Dead(ctx, "other")
+			require.NoError(t, err)
+			assert.Empty(t, empty)
+		})
+	}
+}
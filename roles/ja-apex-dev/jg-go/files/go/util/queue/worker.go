@@ -0,0 +1,224 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/pool"
+)
+
+// Defaults of the worker options.
+const (
+	DefaultPollInterval = time.Second
+	DefaultLease        = 5 * time.Minute
+)
+
+// Handler handles a job. Its failures are retried, except those marked by
+// util.Permanent, which move the job to the dead-letter list at once.
+type Handler func(ctx context.Context, job *Job) error
+
+// WorkerOption configures Queue.Worker.
+type WorkerOption func(*Worker)
+
+// WithConcurrency handles up to n jobs at once, GOMAXPROCS by default.
+func WithConcurrency(n int) WorkerOption {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// WithPollInterval sets how long the worker waits when the queue is
+// empty, DefaultPollInterval by default.
+func WithPollInterval(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.interval = d
+	}
+}
+
+// WithLease sets how long a claimed job is hidden from other workers,
+// DefaultLease by default. The handler context expires with the lease.
+func WithLease(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.lease = d
+	}
+}
+
+// WithBackoff sets the delays between the attempts of a failed job, as
+// returned by policy.Backoff, util.DefaultRetryPolicy by default. Only
+// the delay fields of the policy are used.
+func WithBackoff(policy util.RetryPolicy) WorkerOption {
+	return func(w *Worker) {
+		w.backoff = policy
+	}
+}
+
+// WithLogger sets the logger reporting failed jobs and store errors.
+func WithLogger(logger *slog.Logger) WorkerOption {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// Worker claims the jobs of a queue and runs them with a pool of
+// goroutines.
+type Worker struct {
+	queue       *Queue
+	handler     Handler
+	concurrency int
+	interval    time.Duration
+	lease       time.Duration
+	backoff     util.RetryPolicy
+	logger      *slog.Logger
+}
+
+// Worker returns a worker running h for the jobs of q.
+func (q *Queue) Worker(h Handler, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		queue:       q,
+		handler:     h,
+		concurrency: runtime.GOMAXPROCS(0),
+		interval:    DefaultPollInterval,
+		lease:       DefaultLease,
+		backoff:     util.DefaultRetryPolicy(),
+		logger:      slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Run handles jobs until ctx is done, letting the claimed ones finish.
+func (w *Worker) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := w.Drain(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "claiming jobs failed",
+				"queue", w.queue.name, "error", err)
+		}
+
+		if n > 0 {
+			continue
+		}
+
+		_ = w.queue.clock.Sleep(ctx, w.interval)
+	}
+
+	return nil
+}
+
+// Drain claims a batch of ready jobs, handles them and returns how many
+// were claimed.
+func (w *Worker) Drain(ctx context.Context) (int, error) {
+	now := w.queue.clock.Now()
+
+	jobs, err := w.queue.store.Claim(ctx, w.queue.name, w.concurrency,
+		Lease{Now: now, Until: now.Add(w.lease)})
+	if err != nil {
+		return 0, fmt.Errorf("queue: claim from %s: %w", w.queue.name, err)
+	}
+
+	// The jobs finish even if ctx is done, so as not to wait for their
+	// lease to expire.
+	_ = pool.Results(context.WithoutCancel(ctx), jobs, w.concurrency,
+		func(ctx context.Context, job *Job) (struct{}, error) {
+			w.process(ctx, job)
+
+			return struct{}{}, nil
+		})
+
+	return len(jobs), nil
+}
+
+// Register appends the worker to runner, stopping it on shutdown once the
+// jobs in progress finish.
+func (w *Worker) Register(runner *lifecycle.Runner) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	runner.Append(lifecycle.Hook{
+		Name: "queue " + w.queue.name,
+		Start: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				_ = w.Run(ctx)
+			}()
+
+			return nil
+		},
+		Stop: func(stopCtx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return fmt.Errorf("queue: stop: %w", stopCtx.Err())
+			}
+		},
+	})
+}
+
+// process runs the handler for job and completes, retries or buries it.
+func (w *Worker) process(ctx context.Context, job *Job) {
+	runCtx, cancel := context.WithTimeout(ctx, w.lease)
+	defer cancel()
+
+	err := call(runCtx, w.handler, job)
+	if err == nil {
+		w.report(ctx, job, w.queue.store.Complete(ctx, job))
+
+		return
+	}
+
+	job.LastError = err.Error()
+
+	if job.Attempts >= job.MaxAttempts || util.IsPermanent(err) {
+		w.logger.ErrorContext(ctx, "job failed for good",
+			"queue", job.Queue, "job", job.ID, "attempts", job.Attempts,
+			"error", err)
+		w.report(ctx, job, w.queue.store.Bury(ctx, job))
+
+		return
+	}
+
+	w.logger.WarnContext(ctx, "job failed, retrying",
+		"queue", job.Queue, "job", job.ID, "attempts", job.Attempts,
+		"error", err)
+
+	job.RunAt = w.queue.clock.Now().Add(w.backoff.Backoff(job.Attempts))
+	w.report(ctx, job, w.queue.store.Retry(ctx, job))
+}
+
+// report logs the store failures, after which the job is claimed again at
+// the end of its lease. A lost lease means another worker owns the job.
+func (w *Worker) report(ctx context.Context, job *Job, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrLeaseLost):
+		w.logger.WarnContext(ctx, "job lease lost",
+			"queue", job.Queue, "job", job.ID, "error", err)
+	default:
+		w.logger.ErrorContext(ctx, "saving job failed",
+			"queue", job.Queue, "job", job.ID, "error", err)
+	}
+}
+
+// call runs h, turning a panic into an error.
+func call(ctx context.Context, h Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("queue: handler panicked: %v", r)
+		}
+	}()
+
+	return h(ctx, job)
+}
@@ -0,0 +1,180 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/queue"
+	"example.com/go-template/util/testx"
+)
+
+// fast polls the queue and retries the jobs without waiting.
+var fast = []queue.WorkerOption{
+	queue.WithPollInterval(time.Millisecond),
+	queue.WithBackoff(util.RetryPolicy{InitialDelay: time.Microsecond}),
+}
+
+// run runs w until the test ends.
+func run(t *testing.T, w *queue.Worker) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q := queue.New(store, "emails")
+			for range 20 {
+				_, err := q.Enqueue(t.Context(), []byte("hello"))
+				require.NoError(t, err)
+			}
+
+			var handled atomic.Int32
+
+			run(t, q.Worker(func(_ context.Context, job *queue.Job) error {
+				assert.Equal(t, "hello", string(job.Payload))
+				handled.Add(1)
+
+				return nil
+			}, append(fast, queue.WithConcurrency(4))...))
+
+			testx.RequireEventually(t, func() bool {
+				return handled.Load() == 20
+			}, 5*time.Second)
+		})
+	}
+}
+
+// failing returns a handler failing the "permanent" jobs for good and
+// the others on every attempt, counted in attempts.
+func failing(attempts *atomic.Int32) queue.Handler {
+	return func(_ context.Context, job *queue.Job) error {
+		if string(job.Payload) == "permanent" {
+			return util.Permanent(errors.New("malformed"))
+		}
+
+		attempts.Add(1)
+
+		if job.Attempts == 2 {
+			panic("broken")
+		}
+
+		return errors.New("unavailable")
+	}
+}
+
+func TestWorkerRetries(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q := queue.New(store, "flaky")
+			_, err := q.Enqueue(t.Context(), []byte("retried"),
+				queue.WithMaxAttempts(3))
+			require.NoError(t, err)
+			_, err = q.Enqueue(t.Context(), []byte("permanent"))
+			require.NoError(t, err)
+
+			var attempts atomic.Int32
+
+			run(t, q.Worker(failing(&attempts), fast...))
+
+			var dead []*queue.Job
+
+			testx.RequireEventually(t, func() bool {
+				dead, err = q.Dead(t.Context())
+				require.NoError(t, err)
+
+				return len(dead) == 2
+			}, 5*time.Second)
+
+			assert.EqualValues(t, 3, attempts.Load())
+			assert.Equal(t, "retried", string(dead[0].Payload))
+			assert.Equal(t, "unavailable", dead[0].LastError)
+			assert.Equal(t, "permanent", string(dead[1].Payload))
+			assert.Equal(t, "malformed", dead[1].LastError)
+		})
+	}
+}
+
+func TestWorkerDelay(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	q := queue.New(queue.NewMemory(), "later", queue.WithClock(fake))
+
+	_, err := q.Enqueue(t.Context(), nil, queue.WithDelay(time.Hour))
+	require.NoError(t, err)
+
+	var handled atomic.Int32
+
+	w := q.Worker(func(context.Context, *queue.Job) error {
+		handled.Add(1)
+
+		return nil
+	})
+
+	n, err := w.Drain(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	fake.Advance(time.Hour)
+
+	n, err = w.Drain(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.EqualValues(t, 1, handled.Load())
+}
+
+func TestWorkerRegister(t *testing.T) {
+	t.Parallel()
+
+	q := queue.New(openSQLite(t), "jobs")
+	_, err := q.Enqueue(t.Context(), nil)
+	require.NoError(t, err)
+
+	handled := make(chan struct{})
+	runner := lifecycle.New()
+	q.Worker(func(context.Context, *queue.Job) error {
+		close(handled)
+
+		return nil
+	}, fast...).Register(runner)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+
+	<-handled
+	cancel()
+	require.NoError(t, <-done)
+}
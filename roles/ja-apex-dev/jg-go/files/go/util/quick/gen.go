@@ -0,0 +1,239 @@
+package quick
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// Gen generates random values from r.
+type Gen[V any] func(r *rand.Rand) V
+
+// Just always generates v.
+func Just[V any](v V) Gen[V] {
+	return func(*rand.Rand) V {
+		return v
+	}
+}
+
+// OneOf picks one of values, which must not be empty.
+func OneOf[V any](values ...V) Gen[V] {
+	return func(r *rand.Rand) V {
+		return values[r.IntN(len(values))]
+	}
+}
+
+// Choose picks the value of one of gens, which must not be empty.
+func Choose[V any](gens ...Gen[V]) Gen[V] {
+	return func(r *rand.Rand) V {
+		return gens[r.IntN(len(gens))](r)
+	}
+}
+
+// Map generates the values of g transformed by fn.
+func Map[V, W any](g Gen[V], fn func(V) W) Gen[W] {
+	return func(r *rand.Rand) W {
+		return fn(g(r))
+	}
+}
+
+// Pair holds two generated values.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip generates pairs of the values of a and b.
+func Zip[A, B any](a Gen[A], b Gen[B]) Gen[Pair[A, B]] {
+	return func(r *rand.Rand) Pair[A, B] {
+		return Pair[A, B]{First: a(r), Second: b(r)}
+	}
+}
+
+// Ints generates integers in [low, high].
+func Ints(low, high int) Gen[int] {
+	return func(r *rand.Rand) int {
+		return low + r.IntN(high-low+1)
+	}
+}
+
+// Bools generates booleans.
+func Bools() Gen[bool] {
+	return func(r *rand.Rand) bool {
+		return r.IntN(2) == 0
+	}
+}
+
+// Durations generates durations in [low, high], zero and the bounds being
+// more frequent than other values.
+func Durations(low, high time.Duration) Gen[time.Duration] {
+	return func(r *rand.Rand) time.Duration {
+		switch r.IntN(8) {
+		case 0:
+			return low
+		case 1:
+			return high
+		case 2:
+			if low <= 0 && high >= 0 {
+				return 0
+			}
+		}
+
+		span := uint64(high) - uint64(low)
+		if span == math.MaxUint64 {
+			return time.Duration(r.Uint64())
+		}
+
+		return low + time.Duration(r.Uint64N(span+1))
+	}
+}
+
+// SliceOf generates slices of up to maxLen elements of elem.
+func SliceOf[V any](elem Gen[V], maxLen int) Gen[[]V] {
+	return func(r *rand.Rand) []V {
+		s := make([]V, r.IntN(maxLen+1))
+		for i := range s {
+			s[i] = elem(r)
+		}
+
+		return s
+	}
+}
+
+// MapOf generates maps of up to maxLen entries, fewer when keys repeat.
+func MapOf[K comparable, V any](
+	key Gen[K], value Gen[V], maxLen int,
+) Gen[map[K]V] {
+	return func(r *rand.Rand) map[K]V {
+		n := r.IntN(maxLen + 1)
+
+		m := make(map[K]V, n)
+		for range n {
+			m[key(r)] = value(r)
+		}
+
+		return m
+	}
+}
+
+// alphabet is the pool of the runes of Strings: ASCII letters, digits,
+// punctuation and whitespace, and a few multi-byte characters.
+var alphabet = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"0123456789 !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~\t\n" +
+	"éüßñøЖλ日本語한🙂")
+
+// Strings generates valid UTF-8 strings of up to maxLen runes.
+func Strings(maxLen int) Gen[string] {
+	return stringsOf(0, maxLen)
+}
+
+// NonEmptyStrings generates valid UTF-8 strings of 1 to maxLen runes.
+func NonEmptyStrings(maxLen int) Gen[string] {
+	return stringsOf(1, max(maxLen, 1))
+}
+
+func stringsOf(minLen, maxLen int) Gen[string] {
+	return func(r *rand.Rand) string {
+		runes := make([]rune, minLen+r.IntN(maxLen-minLen+1))
+		for i := range runes {
+			runes[i] = alphabet[r.IntN(len(alphabet))]
+		}
+
+		return string(runes)
+	}
+}
+
+// edgeCases are the pieces of UTF8Edges: combining sequences, emoji with
+// joiners and modifiers, flags, invisible and bidirectional characters,
+// case-mapping oddities and invalid encodings.
+var edgeCases = []string{
+	"", "a", " ", "\t", "\n", "\r\n", "\x00", "\u00e9", "e\u0301",
+	"e\u0301\u0302", "\U0001f44d", "\U0001f44d\U0001f3fd",
+	"\U0001f469\u200d\U0001f469\u200d\U0001f467", "\U0001f1eb\U0001f1f7",
+	"\u200b", "\u200d", "\ufeff", "\u202e", "\u00a0", "\u3000", "\u00df",
+	"\u0130", "\u01c5", "\ufb03", "\u65e5\u672c", "\U0010ffff",
+	"\xff", "\xc3", "\xe2\x82", "\xed\xa0\x80", "\xc0\xaf",
+}
+
+// UTF8Edges generates concatenations of up to maxPieces strings known to
+// break naive text handling, including invalid UTF-8.
+func UTF8Edges(maxPieces int) Gen[string] {
+	return func(r *rand.Rand) string {
+		var b strings.Builder
+		for range r.IntN(maxPieces + 1) {
+			b.WriteString(edgeCases[r.IntN(len(edgeCases))])
+		}
+
+		return b.String()
+	}
+}
+
+// Lines generates up to maxLines lines of line joined with newlines,
+// indented by spaces and tabs, as source snippets and YAML documents.
+func Lines(line Gen[string], maxLines int) Gen[string] {
+	indent := stringsFrom([]rune(" \t"), 4)
+
+	return func(r *rand.Rand) string {
+		lines := make([]string, r.IntN(maxLines+1))
+		for i := range lines {
+			lines[i] = indent(r) + line(r)
+		}
+
+		return strings.Join(lines, "\n")
+	}
+}
+
+func stringsFrom(runes []rune, maxLen int) Gen[string] {
+	return func(r *rand.Rand) string {
+		s := make([]rune, r.IntN(maxLen+1))
+		for i := range s {
+			s[i] = runes[r.IntN(len(runes))]
+		}
+
+		return string(s)
+	}
+}
+
+// NestedMaps generates map[string]any trees up to depth levels deep, as
+// decoded from JSON or YAML: the leaves are strings, float64 numbers,
+// booleans and nil, and the inner nodes maps and []any slices.
+func NestedMaps(depth int) Gen[map[string]any] {
+	key := NonEmptyStrings(8)
+
+	return func(r *rand.Rand) map[string]any {
+		m := make(map[string]any)
+		for range r.IntN(5) {
+			m[key(r)] = nestedValue(r, depth-1)
+		}
+
+		return m
+	}
+}
+
+func nestedValue(r *rand.Rand, depth int) any {
+	kinds := 4
+	if depth > 0 {
+		kinds = 6
+	}
+
+	switch r.IntN(kinds) {
+	case 0:
+		return Strings(8)(r)
+	case 1:
+		return float64(r.IntN(2000) - 1000)
+	case 2:
+		return r.IntN(2) == 0
+	case 3:
+		return nil
+	case 4:
+		return NestedMaps(depth)(r)
+	default:
+		s := make([]any, r.IntN(4))
+		for i := range s {
+			s[i] = nestedValue(r, depth-1)
+		}
+
+		return s
+	}
+}
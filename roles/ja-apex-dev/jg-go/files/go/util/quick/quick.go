@@ -0,0 +1,132 @@
+// Package quick checks properties of code against random inputs, with
+// generators for the values that tend to break it and a runner reporting
+// the first failing input through testify:
+//
+//	func TestDedentIndent(t *testing.T) {
+//		quick.Check(t, quick.Lines(quick.Strings(20), 5),
+//			func(t *quick.T, s string) {
+//				want := util.Dedent(s)
+//				assert.Equal(t, want, util.Dedent(util.Indent(want, "  ")))
+//			})
+//	}
+//
+// The inputs are reproducible: a failure reports the seed, which the
+// -quick.seed flag replays.
+package quick
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// DefaultRuns is the number of inputs checked by Check.
+const DefaultRuns = 100
+
+var seedFlag = flag.Uint64("quick.seed", 0,
+	"seed of the property tests inputs, random when zero")
+
+// Option configures Check.
+type Option func(*settings)
+
+type settings struct {
+	runs int
+	seed uint64
+}
+
+// WithRuns sets the number of inputs checked, DefaultRuns by default.
+func WithRuns(n int) Option {
+	return func(s *settings) {
+		s.runs = n
+	}
+}
+
+// WithSeed sets the seed of the inputs, the -quick.seed flag or a random
+// one by default.
+func WithSeed(seed uint64) Option {
+	return func(s *settings) {
+		s.seed = seed
+	}
+}
+
+// T records the failures of a property for an input. It implements the
+// TestingT interfaces of assert and require, FailNow ending the property.
+type T struct {
+	failures []string
+}
+
+// failNow unwinds the property on FailNow.
+type failNow struct{}
+
+// Errorf records a failure.
+func (t *T) Errorf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+// FailNow ends the property, failing it.
+func (t *T) FailNow() {
+	t.failures = append(t.failures, "FailNow called")
+
+	panic(failNow{})
+}
+
+// Helper does nothing, as the failures are reported by Check.
+func (*T) Helper() {}
+
+// Failed reports whether the property failed.
+func (t *T) Failed() bool {
+	return len(t.failures) > 0
+}
+
+// Check calls property with inputs generated by gen and fails the test
+// with the first input the property fails for, and its seed. It reports
+// whether the property held for all of them.
+func Check[V any](
+	tb testing.TB, gen Gen[V], property func(t *T, v V), opts ...Option,
+) bool {
+	tb.Helper()
+
+	s := settings{runs: DefaultRuns, seed: *seedFlag}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.seed == 0 {
+		s.seed = rand.Uint64()
+	}
+
+	r := rand.New(rand.NewPCG(s.seed, s.seed))
+
+	for i := range s.runs {
+		v := gen(r)
+
+		t := &T{}
+		call(t, v, property)
+
+		if t.Failed() {
+			tb.Errorf("quick: property failed on run %d (-quick.seed=%d)"+
+				" for input %#v:\n%s", i+1, s.seed, v,
+				strings.Join(t.failures, "\n"))
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// call runs property, recovering from FailNow and turning other panics
+// into failures.
+func call[V any](t *T, v V, property func(t *T, v V)) {
+	defer func() {
+		switch p := recover().(type) {
+		case nil, failNow:
+		default:
+			t.Errorf("panic: %v", p)
+		}
+	}()
+
+	property(t, v)
+}
@@ -0,0 +1,176 @@
+package quick_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/quick"
+)
+
+// recorder is a testing.TB recording the failures instead of reporting
+// them.
+type recorder struct {
+	testing.TB
+
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	ok := quick.Check(t, quick.Ints(0, 9), func(t *quick.T, n int) {
+		runs++
+
+		assert.Less(t, n, 10)
+	}, quick.WithRuns(50))
+
+	assert.True(t, ok)
+	assert.Equal(t, 50, runs)
+}
+
+func TestCheckFailure(t *testing.T) {
+	t.Parallel()
+
+	for name, property := range map[string]func(*quick.T, int){
+		"assert": func(t *quick.T, n int) {
+			assert.Less(t, n, 5, "small")
+		},
+		"require": func(t *quick.T, n int) {
+			require.Less(t, n, 5, "small")
+			panic("unreachable")
+		},
+		"panic": func(_ *quick.T, n int) {
+			if n >= 5 {
+				panic("too large")
+			}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := &recorder{TB: t}
+			ok := quick.Check(rec, quick.Ints(0, 9), property,
+				quick.WithSeed(42))
+
+			assert.False(t, ok)
+			require.Len(t, rec.failures, 1)
+			assert.Contains(t, rec.failures[0], "-quick.seed=42")
+			assert.NotContains(t, rec.failures[0], "unreachable")
+		})
+	}
+}
+
+func TestCheckSeed(t *testing.T) {
+	t.Parallel()
+
+	inputs := func() []string {
+		var got []string
+
+		quick.Check(t, quick.UTF8Edges(5), func(_ *quick.T, s string) {
+			got = append(got, s)
+		}, quick.WithSeed(7), quick.WithRuns(20))
+
+		return got
+	}
+
+	assert.Equal(t, inputs(), inputs())
+}
+
+func TestStrings(t *testing.T) {
+	t.Parallel()
+
+	quick.Check(t, quick.Strings(10), func(t *quick.T, s string) {
+		assert.True(t, utf8.ValidString(s))
+		assert.LessOrEqual(t, utf8.RuneCountInString(s), 10)
+	})
+
+	quick.Check(t, quick.NonEmptyStrings(3), func(t *quick.T, s string) {
+		assert.NotEmpty(t, s)
+		assert.LessOrEqual(t, utf8.RuneCountInString(s), 3)
+	})
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	gen := quick.Lines(quick.OneOf("a", "b"), 4)
+	quick.Check(t, gen, func(t *quick.T, s string) {
+		assert.LessOrEqual(t, strings.Count(s, "\n"), 3)
+		assert.Empty(t, strings.Trim(s, " \tab\n"))
+	})
+}
+
+func TestDurations(t *testing.T) {
+	t.Parallel()
+
+	gen := quick.Durations(-time.Hour, time.Hour)
+	quick.Check(t, gen, func(t *quick.T, d time.Duration) {
+		assert.GreaterOrEqual(t, d, -time.Hour)
+		assert.LessOrEqual(t, d, time.Hour)
+	})
+
+	quick.Check(t, quick.Durations(time.Duration(-1<<63), 1<<63-1),
+		func(*quick.T, time.Duration) {})
+}
+
+// depth returns the number of nested levels of maps and slices of v.
+func depth(v any) int {
+	deepest := 0
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, e := range v {
+			deepest = max(deepest, depth(e))
+		}
+	case []any:
+		for _, e := range v {
+			deepest = max(deepest, depth(e))
+		}
+	default:
+		return 0
+	}
+
+	return deepest + 1
+}
+
+func TestNestedMaps(t *testing.T) {
+	t.Parallel()
+
+	quick.Check(t, quick.NestedMaps(3), func(t *quick.T, m map[string]any) {
+		assert.LessOrEqual(t, depth(m), 3)
+	})
+}
+
+func TestCombinators(t *testing.T) {
+	t.Parallel()
+
+	gen := quick.Zip(
+		quick.SliceOf(quick.Just(1), 3),
+		quick.MapOf(quick.Ints(0, 1), quick.Bools(), 5),
+	)
+
+	quick.Check(t, gen, func(t *quick.T, p quick.Pair[[]int, map[int]bool]) {
+		assert.LessOrEqual(t, len(p.First), 3)
+		assert.LessOrEqual(t, len(p.Second), 2)
+	})
+
+	lengths := quick.Map(quick.Choose(quick.Just("ab"), quick.Just("c")),
+		func(s string) int { return len(s) })
+
+	quick.Check(t, lengths, func(t *quick.T, n int) {
+		assert.Contains(t, []int{1, 2}, n)
+	})
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"math/rand/v2"
+	"strings"
+)
+
+// Alphabets for RandomString.
+const (
+	AlphabetDigits       = "0123456789"
+	AlphabetLower        = "abcdefghijklmnopqrstuvwxyz"
+	AlphabetUpper        = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	AlphabetAlphanumeric = AlphabetDigits + AlphabetLower + AlphabetUpper
+	// AlphabetUnambiguous omits characters easily confused when read aloud
+	// or typed, such as 0/O and 1/l/I.
+	AlphabetUnambiguous = "23456789abcdefghjkmnpqrstuvwxyz"
+)
+
+// Random generates strings and tokens from a random source.
+type Random struct {
+	rng *rand.Rand
+}
+
+// cryptoSource is a rand.Source reading crypto/rand, which never fails.
+type cryptoSource struct{}
+
+// Uint64 implements rand.Source.
+func (cryptoSource) Uint64() uint64 {
+	var buf [8]byte
+
+	_, _ = cryptorand.Read(buf[:])
+
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// secureRandom is safe for concurrent use as its source holds no state.
+var secureRandom = &Random{rng: rand.New(cryptoSource{})}
+
+// NewInsecureRandom returns a generator seeded with seed, producing the same
+// sequence on every run. It is meant for tests only: its output is
+// predictable and must never be used for secrets. It is not safe for
+// concurrent use.
+func NewInsecureRandom(seed uint64) *Random {
+	return &Random{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// RandomString returns n characters drawn uniformly from alphabet using
+// crypto/rand. It panics if alphabet is empty.
+func RandomString(n int, alphabet string) string {
+	return secureRandom.String(n, alphabet)
+}
+
+// SecureToken returns n bytes from crypto/rand encoded as unpadded URL-safe
+// base64, suitable for session IDs, API keys and reset links. Use at least
+// 16 bytes.
+func SecureToken(n int) string {
+	return secureRandom.Token(n)
+}
+
+// String returns n characters drawn uniformly from alphabet. It panics if
+// alphabet is empty.
+func (r *Random) String(n int, alphabet string) string {
+	chars := []rune(alphabet)
+	if len(chars) == 0 {
+		panic("util: empty alphabet")
+	}
+
+	var out strings.Builder
+
+	out.Grow(n)
+
+	for range n {
+		out.WriteRune(chars[r.rng.IntN(len(chars))])
+	}
+
+	return out.String()
+}
+
+// Token returns n random bytes encoded as unpadded URL-safe base64.
+func (r *Random) Token(n int) string {
+	buf := make([]byte, max(n, 0))
+	for i := range buf {
+		buf[i] = byte(r.rng.Uint32())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
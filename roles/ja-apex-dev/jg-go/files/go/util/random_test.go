@@ -0,0 +1,62 @@
+package util_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+func TestRandomString(t *testing.T) {
+	t.Parallel()
+
+	s := util.RandomString(32, util.AlphabetDigits)
+	assert.Len(t, s, 32)
+	assert.Empty(t, strings.Trim(s, util.AlphabetDigits))
+	assert.NotEqual(t, s, util.RandomString(32, util.AlphabetDigits))
+
+	assert.Equal(t, "ééé", util.RandomString(3, "é"))
+	assert.Empty(t, util.RandomString(0, util.AlphabetLower))
+	assert.Panics(t, func() { util.RandomString(1, "") })
+}
+
+func TestRandomStringDistribution(t *testing.T) {
+	t.Parallel()
+
+	counts := map[rune]int{}
+	for _, c := range util.RandomString(30000, "abc") {
+		counts[c]++
+	}
+
+	for _, c := range "abc" {
+		assert.InDelta(t, 10000, counts[c], 600, string(c))
+	}
+}
+
+func TestSecureToken(t *testing.T) {
+	t.Parallel()
+
+	token := util.SecureToken(32)
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	assert.Len(t, raw, 32)
+	assert.NotEqual(t, token, util.SecureToken(32))
+	assert.Empty(t, util.SecureToken(0))
+}
+
+func TestInsecureRandom(t *testing.T) {
+	t.Parallel()
+
+	a := util.NewInsecureRandom(42)
+	b := util.NewInsecureRandom(42)
+
+	assert.Equal(t, a.String(16, util.AlphabetAlphanumeric),
+		b.String(16, util.AlphabetAlphanumeric))
+	assert.Equal(t, a.Token(16), b.Token(16))
+	assert.NotEqual(t, a.Token(16),
+		util.NewInsecureRandom(7).Token(16))
+}
@@ -0,0 +1,105 @@
+package rate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a Keyed bucket may stay unused before it
+// is dropped.
+const DefaultIdleTimeout = 10 * time.Minute
+
+// WithIdleTimeout sets how long Keyed keeps unused buckets. A dropped
+// bucket is recreated full, so the timeout should exceed the time needed
+// to refill a bucket.
+func WithIdleTimeout(idle time.Duration) Option {
+	return func(s *settings) {
+		s.idle = idle
+	}
+}
+
+type bucket struct {
+	limiter  *Limiter
+	lastUsed time.Time
+}
+
+// Keyed maintains one Limiter per key, such as a tenant or client IP, and
+// drops buckets left idle. It is safe for concurrent use.
+type Keyed[K comparable] struct {
+	limit    Limit
+	burst    int
+	settings settings
+	opts     []Option
+
+	mu        sync.Mutex
+	buckets   map[K]*bucket
+	lastSweep time.Time
+}
+
+// NewKeyed returns a keyed limiter whose buckets allow limit events per
+// second with bursts of up to burst events.
+func NewKeyed[K comparable](limit Limit, burst int, opts ...Option) *Keyed[K] {
+	s := newSettings(opts)
+
+	return &Keyed[K]{
+		limit:     limit,
+		burst:     burst,
+		settings:  s,
+		opts:      opts,
+		buckets:   make(map[K]*bucket),
+		lastSweep: s.clock.Now(),
+	}
+}
+
+// Allow reports whether an event for key may happen now.
+func (k *Keyed[K]) Allow(key K) bool {
+	return k.Limiter(key).Allow()
+}
+
+// Wait blocks until an event for key may happen or ctx ends.
+func (k *Keyed[K]) Wait(ctx context.Context, key K) error {
+	return k.Limiter(key).Wait(ctx)
+}
+
+// Limiter returns the bucket of key, creating it if needed.
+func (k *Keyed[K]) Limiter(key K) *Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.settings.clock.Now()
+	k.sweep(now)
+
+	b, ok := k.buckets[key]
+	if !ok {
+		b = &bucket{limiter: NewLimiter(k.limit, k.burst, k.opts...)}
+		k.buckets[key] = b
+	}
+
+	b.lastUsed = now
+
+	return b.limiter
+}
+
+// Len returns the number of live buckets.
+func (k *Keyed[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return len(k.buckets)
+}
+
+// sweep drops idle buckets, at most once per idle timeout.
+func (k *Keyed[K]) sweep(now time.Time) {
+	if now.Sub(k.lastSweep) < k.settings.idle {
+		return
+	}
+
+	k.lastSweep = now
+
+	for key, b := range k.buckets {
+		if now.Sub(b.lastUsed) >= k.settings.idle {
+			delete(k.buckets, key)
+		}
+	}
+}
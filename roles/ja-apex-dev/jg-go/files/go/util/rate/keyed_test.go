@@ -0,0 +1,35 @@
+package rate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/rate"
+)
+
+func TestKeyed(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	keyed := rate.NewKeyed[string](1, 1,
+		rate.WithClock(fake), rate.WithIdleTimeout(time.Minute))
+
+	assert.True(t, keyed.Allow("10.0.0.1"))
+	assert.False(t, keyed.Allow("10.0.0.1"))
+	assert.True(t, keyed.Allow("10.0.0.2"), "buckets are independent")
+	assert.Equal(t, 2, keyed.Len())
+
+	fake.Advance(30 * time.Second)
+	assert.True(t, keyed.Allow("10.0.0.1"))
+
+	fake.Advance(45 * time.Second)
+	keyed.Allow("10.0.0.3")
+	assert.Equal(t, 2, keyed.Len(), "idle bucket is dropped")
+
+	require.NoError(t, keyed.Wait(t.Context(), "10.0.0.2"))
+	assert.Same(t, keyed.Limiter("a"), keyed.Limiter("a"))
+}
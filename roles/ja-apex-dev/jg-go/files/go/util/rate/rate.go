@@ -0,0 +1,206 @@
+// Package rate provides token-bucket rate limiters, either single or keyed
+// by tenant, client IP or any other comparable key.
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+var (
+	// ErrExceedsBurst is returned when waiting for more tokens than the
+	// bucket can hold.
+	ErrExceedsBurst = errors.New("rate: request exceeds burst")
+	// ErrDeadline is returned by Wait when the context deadline would pass
+	// before the tokens are available.
+	ErrDeadline = errors.New("rate: wait would exceed context deadline")
+)
+
+// Limit is a rate of events per second.
+type Limit float64
+
+// Inf allows every event.
+const Inf = Limit(math.MaxFloat64)
+
+// Every converts a minimum interval between events into a Limit.
+func Every(interval time.Duration) Limit {
+	if interval <= 0 {
+		return Inf
+	}
+
+	return Limit(float64(time.Second) / float64(interval))
+}
+
+// Option configures limiters.
+type Option func(*settings)
+
+type settings struct {
+	clock clock.Clock
+	idle  time.Duration
+}
+
+// WithClock sets the clock used for refills and waits.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.clock = c
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{clock: clock.Real(), idle: DefaultIdleTimeout}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+// Limiter is a token bucket refilled at limit tokens per second up to
+// burst tokens. The bucket starts full. It is safe for concurrent use.
+type Limiter struct {
+	limit Limit
+	burst float64
+	clock clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a limiter allowing limit events per second with
+// bursts of up to burst events.
+func NewLimiter(limit Limit, burst int, opts ...Option) *Limiter {
+	s := newSettings(opts)
+
+	return &Limiter{
+		limit:  limit,
+		burst:  float64(burst),
+		clock:  s.clock,
+		tokens: float64(burst),
+		last:   s.clock.Now(),
+	}
+}
+
+// Allow reports whether an event may happen now, consuming a token if so.
+func (l *Limiter) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, consuming n tokens if
+// so.
+func (l *Limiter) AllowN(n int) bool {
+	if l.limit == Inf {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(l.clock.Now())
+
+	if l.tokens < float64(n) {
+		return false
+	}
+
+	l.tokens -= float64(n)
+
+	return true
+}
+
+// Wait blocks until an event may happen or ctx ends.
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n events may happen or ctx ends. It fails right away
+// when n exceeds the burst or the wait would outlast the ctx deadline.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l.limit == Inf {
+		return nil
+	}
+
+	if float64(n) > l.burst {
+		return fmt.Errorf("%w: %d > %v", ErrExceedsBurst, n, l.burst)
+	}
+
+	delay, err := l.reserve(ctx, float64(n))
+	if err != nil || delay == 0 {
+		return err
+	}
+
+	timer := l.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		l.cancel(float64(n))
+
+		return fmt.Errorf("rate: %w", ctx.Err())
+	}
+}
+
+// reserve takes n tokens, possibly going into debt, and returns how long
+// to wait until the debt is repaid.
+func (l *Limiter) reserve(ctx context.Context, n float64) (
+	time.Duration, error,
+) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.refill(now)
+
+	var delay time.Duration
+
+	missing := n - l.tokens
+	if missing > 0 && l.limit <= 0 {
+		return 0, fmt.Errorf("%w: bucket never refills", ErrExceedsBurst)
+	}
+
+	if missing > 0 {
+		delay = time.Duration(missing / float64(l.limit) * float64(time.Second))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		return 0, ErrDeadline
+	}
+
+	l.tokens -= n
+
+	return delay, nil
+}
+
+// cancel returns the tokens of an abandoned wait.
+func (l *Limiter) cancel(n float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(l.clock.Now())
+	l.tokens = min(l.tokens+n, l.burst)
+}
+
+// Tokens returns the number of tokens currently available, negative while
+// waiters are in debt.
+func (l *Limiter) Tokens() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(l.clock.Now())
+
+	return l.tokens
+}
+
+func (l *Limiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+elapsed.Seconds()*float64(l.limit))
+		l.last = now
+	}
+}
@@ -0,0 +1,92 @@
+package rate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/rate"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestEvery(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 10, float64(rate.Every(100*time.Millisecond)), 1e-9)
+	assert.Equal(t, rate.Inf, rate.Every(0))
+}
+
+func TestLimiterAllow(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(epoch)
+	limiter := rate.NewLimiter(2, 3, rate.WithClock(fake))
+
+	for range 3 {
+		assert.True(t, limiter.Allow())
+	}
+
+	assert.False(t, limiter.Allow())
+
+	fake.Advance(500 * time.Millisecond)
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	fake.Advance(time.Hour)
+	assert.InDelta(t, 3, limiter.Tokens(), 1e-9, "refill is capped")
+	assert.True(t, limiter.AllowN(3))
+	assert.False(t, limiter.AllowN(1))
+
+	assert.True(t, rate.NewLimiter(rate.Inf, 0).Allow())
+}
+
+func TestLimiterWait(t *testing.T) {
+	t.Parallel()
+
+	limiter := rate.NewLimiter(rate.Every(20*time.Millisecond), 1)
+	require.NoError(t, limiter.Wait(t.Context()))
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(t.Context()))
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+
+	require.ErrorIs(t, limiter.WaitN(t.Context(), 2), rate.ErrExceedsBurst)
+	require.NoError(t, rate.NewLimiter(rate.Inf, 0).Wait(t.Context()))
+	require.NoError(t, rate.NewLimiter(0, 1).Wait(t.Context()),
+		"the initial burst is available")
+}
+
+func TestLimiterWaitDeadline(t *testing.T) {
+	t.Parallel()
+
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	require.True(t, limiter.Allow())
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	require.ErrorIs(t, limiter.Wait(ctx), rate.ErrDeadline)
+	assert.InDelta(t, 0, limiter.Tokens(), 0.01, "failed waits take nothing")
+
+	never := rate.NewLimiter(0, 1)
+	require.True(t, never.Allow())
+	require.ErrorIs(t, never.Wait(t.Context()), rate.ErrExceedsBurst)
+}
+
+func TestLimiterWaitCancel(t *testing.T) {
+	t.Parallel()
+
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	require.True(t, limiter.Allow())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	require.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+	assert.InDelta(t, 0, limiter.Tokens(), 0.01, "tokens are returned")
+}
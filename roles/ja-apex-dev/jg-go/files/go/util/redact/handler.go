@@ -0,0 +1,86 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a slog.Handler masking secrets in the message and attributes
+// of every record before passing it to the next handler.
+type Handler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// NewHandler wraps next, masking with r or with New() when r is nil.
+func NewHandler(next slog.Handler, r *Redactor) *Handler {
+	if r == nil {
+		r = New()
+	}
+
+	return &Handler{next: next, redactor: r}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+//
+//nolint:gocritic // Signature is mandated by slog.Handler.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level,
+		h.redactor.String(record.Message), record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.attr(attr))
+
+		return true
+	})
+
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{
+		next:     h.next.WithAttrs(h.attrs(attrs)),
+		redactor: h.redactor,
+	}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// attr redacts a single attribute, resolving slog.LogValuer values first.
+func (h *Handler) attr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+
+	if h.redactor.IsSensitive(attr.Key) {
+		return slog.String(attr.Key, h.redactor.mask)
+	}
+
+	switch value.Kind() {
+	case slog.KindString:
+		value = slog.StringValue(h.redactor.String(value.String()))
+	case slog.KindGroup:
+		value = slog.GroupValue(h.attrs(value.Group())...)
+	case slog.KindAny:
+		value = slog.AnyValue(h.redactor.Value(value.Any()))
+	default:
+	}
+
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+func (h *Handler) attrs(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		out[i] = h.attr(attr)
+	}
+
+	return out
+}
@@ -0,0 +1,47 @@
+package redact_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/log"
+	"example.com/go-template/util/redact"
+)
+
+type credentials string
+
+// LogValue exposes the raw value, as careless types do.
+func (c credentials) LogValue() slog.Value {
+	return slog.StringValue("token=" + string(c))
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	sink := log.NewSink()
+	logger := slog.New(redact.NewHandler(sink, nil)).
+		With("password", "hunter2")
+
+	logger.Info("login with Bearer abc123",
+		"user", "app",
+		slog.Group("db", "dsn", "mysql://app:pw@db/app"),
+		"auth", credentials("xyz"),
+		"err", errors.New("api-key: k3y"),
+		"attempt", 2)
+
+	entries := sink.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "login with Bearer [REDACTED]", entries[0].Message)
+	assert.Equal(t, map[string]any{
+		"password": redact.Mask,
+		"user":     "app",
+		"db.dsn":   "mysql://app:[REDACTED]@db/app",
+		"auth":     "token=[REDACTED]",
+		"err":      "api-key: [REDACTED]",
+		"attempt":  int64(2),
+	}, entries[0].Attrs)
+}
@@ -0,0 +1,262 @@
+// Package redact masks secrets such as tokens, passwords and cloud keys in
+// strings, maps and structs before they are logged or printed, and provides
+// a slog.Handler applying the same masking to every record.
+package redact
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util"
+)
+
+// Mask replaces the redacted values by default.
+const Mask = "[REDACTED]"
+
+// secretGroup names the capture group of a pattern holding the secret, so
+// that surrounding context such as "password=" stays readable.
+const secretGroup = "secret"
+
+// Pattern is a named regular expression matching secrets. When it defines
+// a capture group named "secret", only that group is masked.
+type Pattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultPatterns match common credentials in free text.
+var DefaultPatterns = []Pattern{
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\bgh[opsu]_[A-Za-z0-9]{36,}\b`)},
+	{"jwt", regexp.MustCompile(
+		`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{"bearer", regexp.MustCompile(
+		`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]+=*)`)},
+	{"url_password", regexp.MustCompile(
+		`://[^/\s:@]+:(?P<secret>[^/\s@]+)@`)},
+	{"assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|` +
+		`secret|token|api[_-]?key)\b"?\s*[=:]\s*"?(?P<secret>[^\s"&,;]+)`)},
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----` +
+		`[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// DefaultKeys are the suffixes of map keys and attribute names whose values
+// are always masked, compared ignoring case, "-" and "_".
+var DefaultKeys = []string{
+	"password", "passwd", "secret", "token", "apikey", "accesskey",
+	"secretkey", "privatekey", "authorization", "cookie", "credentials",
+}
+
+// Option configures New.
+type Option func(*Redactor)
+
+// WithPattern registers an additional pattern.
+func WithPattern(name string, expr *regexp.Regexp) Option {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, Pattern{Name: name, Regexp: expr})
+	}
+}
+
+// WithKeys registers additional sensitive key suffixes.
+func WithKeys(keys ...string) Option {
+	return func(r *Redactor) {
+		for _, key := range keys {
+			r.keys = append(r.keys, normalizeKey(key))
+		}
+	}
+}
+
+// WithMask overrides the replacement text.
+func WithMask(mask string) Option {
+	return func(r *Redactor) {
+		r.mask = mask
+	}
+}
+
+// WithoutDefaults drops DefaultPatterns and DefaultKeys, keeping only the
+// registered ones.
+func WithoutDefaults() Option {
+	return func(r *Redactor) {
+		r.patterns = nil
+		r.keys = nil
+	}
+}
+
+// Redactor masks secrets. It is immutable once created and safe for
+// concurrent use.
+type Redactor struct {
+	patterns []Pattern
+	keys     []string
+	mask     string
+}
+
+// New creates a Redactor with the default patterns and keys.
+func New(opts ...Option) *Redactor {
+	r := &Redactor{
+		patterns: slices.Clone(DefaultPatterns),
+		mask:     Mask,
+	}
+	for _, key := range DefaultKeys {
+		r.keys = append(r.keys, normalizeKey(key))
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// String masks every pattern match in s.
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		s = r.replace(p.Regexp, s)
+	}
+
+	return s
+}
+
+// IsSensitive reports whether the values stored under key are masked
+// regardless of their content.
+func (r *Redactor) IsSensitive(key string) bool {
+	normalized := normalizeKey(key)
+
+	for _, suffix := range r.keys {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Map returns a copy of m with the values of sensitive keys masked and the
+// other values redacted recursively.
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]any, len(m))
+	for key, value := range m {
+		if r.IsSensitive(key) {
+			out[key] = r.mask
+		} else {
+			out[key] = r.Value(value)
+		}
+	}
+
+	return out
+}
+
+// Value returns a redacted copy of v: strings and errors are matched
+// against the patterns, maps and slices are walked, and structs are
+// converted with util.ToMap first. Structs that cannot be converted are
+// masked entirely; other values are returned unchanged.
+func (r *Redactor) Value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case error:
+		return r.String(v.Error())
+	case map[string]any:
+		return r.Map(v)
+	case map[string]string:
+		return r.stringMap(v)
+	case []any:
+		return r.slice(v)
+	case []string:
+		return r.stringSlice(v)
+	}
+
+	if !isStruct(v) {
+		return v
+	}
+
+	m, err := util.ToMap(v)
+	if err != nil {
+		return r.mask
+	}
+
+	return r.Map(m)
+}
+
+// replace masks the matches of expr in s, or only their secret group.
+func (r *Redactor) replace(expr *regexp.Regexp, s string) string {
+	group := expr.SubexpIndex(secretGroup)
+	if group < 0 {
+		return expr.ReplaceAllLiteralString(s, r.mask)
+	}
+
+	matches := expr.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 {
+			continue
+		}
+
+		b.WriteString(s[last:start])
+		b.WriteString(r.mask)
+		last = end
+	}
+
+	b.WriteString(s[last:])
+
+	return b.String()
+}
+
+func (r *Redactor) stringMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for key, value := range m {
+		if r.IsSensitive(key) {
+			out[key] = r.mask
+		} else {
+			out[key] = r.String(value)
+		}
+	}
+
+	return out
+}
+
+func (r *Redactor) slice(values []any) []any {
+	out := make([]any, len(values))
+	for i, value := range values {
+		out[i] = r.Value(value)
+	}
+
+	return out
+}
+
+func (r *Redactor) stringSlice(values []string) []string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = r.String(value)
+	}
+
+	return out
+}
+
+// isStruct reports whether v is a struct or a non-nil pointer to one.
+func isStruct(v any) bool {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	return value.Kind() == reflect.Struct
+}
+
+// normalizeKey lowercases key and drops the separators, so that "API-Key",
+// "api_key" and "apiKey" compare equal.
+func normalizeKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+}
@@ -0,0 +1,178 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/table"
+)
+
+// WriteJSON writes the report as an indented JSON object.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+
+	return nil
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML document with a test suite
+// named after the run and a test case per step. Failures hold the error,
+// and the standard output of the test cases lists the changes.
+func (r Report) WriteJUnit(w io.Writer) error {
+	suite := junitSuite{
+		Name:      r.Name,
+		Tests:     len(r.Steps),
+		Failures:  r.Count(Failed),
+		Skipped:   r.Count(Skipped),
+		Time:      seconds(r.Duration),
+		Timestamp: r.Start.UTC().Format(time.RFC3339),
+	}
+
+	for _, step := range r.Steps {
+		suite.Cases = append(suite.Cases, junitCaseOf(r.Name, step))
+	}
+
+	doc := junitSuites{
+		Name:     r.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+
+	_, err = io.WriteString(w, xml.Header+string(data)+"\n")
+	if err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+
+	return nil
+}
+
+func junitCaseOf(run string, step Step) junitCase {
+	c := junitCase{
+		Name:      step.Name,
+		Classname: run,
+		Time:      seconds(step.Duration),
+		SystemOut: strings.Join(step.Changes, "\n"),
+	}
+
+	switch step.Status {
+	case Failed:
+		c.Failure = &junitMessage{Message: step.Error, Text: step.Error}
+	case Skipped:
+		c.Skipped = &junitMessage{Message: step.Reason}
+	}
+
+	return c
+}
+
+// seconds formats d as JUnit does.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// statusColors paint the status column of the summary.
+var statusColors = map[Status]table.Color{
+	OK:      table.Green,
+	Changed: table.Yellow,
+	Failed:  table.Red,
+	Skipped: table.Cyan,
+}
+
+// WriteSummary writes a table of the steps followed by a recap line, such
+// as "bootstrap: ok=3 changed=1 failed=0 skipped=1 in 2m 5s". Options
+// apply to the table, colored by status on terminals.
+func (r Report) WriteSummary(w io.Writer, opts ...table.Option) error {
+	opts = append([]table.Option{
+		table.WithCellColor(func(column, cell string) table.Color {
+			if column != "STATUS" {
+				return ""
+			}
+
+			return statusColors[Status(cell)]
+		}),
+	}, opts...)
+
+	t := table.New([]string{"STEP", "STATUS", "DURATION", "DETAILS"},
+		opts...)
+	for _, step := range r.Steps {
+		t.Append(step.Name, string(step.Status),
+			util.HumanDuration(step.Duration), details(step))
+	}
+
+	if err := t.Render(w); err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+
+	_, err := fmt.Fprintf(w,
+		"\n%s: ok=%d changed=%d failed=%d skipped=%d in %s\n", r.Name,
+		r.Count(OK), r.Count(Changed), r.Count(Failed), r.Count(Skipped),
+		util.HumanDuration(r.Duration))
+	if err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+
+	return nil
+}
+
+// details summarizes the error, skip reason or changes of step.
+func details(step Step) string {
+	switch step.Status {
+	case Failed:
+		return step.Error
+	case Skipped:
+		return step.Reason
+	default:
+		return strings.Join(step.Changes, "; ")
+	}
+}
@@ -0,0 +1,72 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/report"
+)
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	r := sample(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+
+	var decoded report.Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, r, decoded)
+	assert.Contains(t, buf.String(), `"status": "changed"`)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, sample(t).WriteJUnit(&buf))
+
+	assert.Equal(t, xml.Header+
+		`<testsuites name="bootstrap" tests="4" failures="1" skipped="1"`+
+		` time="124.000">
+  <testsuite name="bootstrap" tests="4" failures="1" skipped="1"`+
+		` time="124.000" timestamp="2026-10-14T12:00:00Z">
+    <testcase name="check disk" classname="bootstrap" time="1.000"></testcase>
+    <testcase name="install nginx" classname="bootstrap" time="120.000">
+      <system-out>installed nginx&#xA;started</system-out>
+    </testcase>
+    <testcase name="configure &lt;tls&gt;" classname="bootstrap" time="3.000">
+      <failure message="reload failed">reload failed</failure>
+      <system-out>wrote cert</system-out>
+    </testcase>
+    <testcase name="restore backup" classname="bootstrap" time="0.000">
+      <skipped message="no backup"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestWriteSummary(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, sample(t).WriteSummary(&buf))
+
+	assert.Equal(t, ""+
+		"STEP             STATUS   DURATION  DETAILS\n"+
+		"---------------  -------  --------  ------------------------\n"+
+		"check disk       ok       1s\n"+
+		"install nginx    changed  2m        installed nginx; started\n"+
+		"configure <tls>  failed   3s        reload failed\n"+
+		"restore backup   skipped  0s        no backup\n"+
+		"\n"+
+		"bootstrap: ok=1 changed=1 failed=1 skipped=1 in 2m 4s\n",
+		buf.String())
+}
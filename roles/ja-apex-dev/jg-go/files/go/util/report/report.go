@@ -0,0 +1,197 @@
+// Package report collects the outcome of the steps of a provisioning run
+// and renders it for machines and people: JSON for tooling, JUnit XML for
+// CI dashboards and a summary table with an Ansible-like recap for
+// terminals.
+//
+//	rec := report.New("bootstrap")
+//	err := rec.Run(ctx, "install packages", func(
+//		ctx context.Context, step *report.Step,
+//	) error {
+//		step.Change("installed nginx 1.26")
+//		return nil
+//	})
+//	...
+//	err = rec.Report().WriteSummary(os.Stdout)
+package report
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// Status is the outcome of a step.
+type Status string
+
+// Step statuses, as reported by Ansible.
+const (
+	// OK is a step that succeeded without changes.
+	OK Status = "ok"
+	// Changed is a step that succeeded with changes.
+	Changed Status = "changed"
+	// Failed is a step that returned an error.
+	Failed Status = "failed"
+	// Skipped is a step that did not apply.
+	Skipped Status = "skipped"
+)
+
+// Step is the outcome of a step of the run.
+type Step struct {
+	// Name identifies the step.
+	Name string `json:"name"`
+	// Status is the outcome of the step.
+	Status Status `json:"status"`
+	// Start is when the step started.
+	Start time.Time `json:"start"`
+	// Duration is how long the step ran.
+	Duration time.Duration `json:"duration_ns"`
+	// Changes describes the changes made by the step.
+	Changes []string `json:"changes,omitempty"`
+	// Error is the error of a failed step.
+	Error string `json:"error,omitempty"`
+	// Reason tells why a step was skipped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Change records a change made by the step, formatted as fmt.Sprintf does
+// with args.
+func (s *Step) Change(format string, args ...any) {
+	s.Changes = append(s.Changes, fmt.Sprintf(format, args...))
+}
+
+// Skip marks the step as skipped for reason.
+func (s *Step) Skip(reason string) {
+	s.Status, s.Reason = Skipped, reason
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	// Name identifies the run.
+	Name string `json:"name"`
+	// Start is when the run started.
+	Start time.Time `json:"start"`
+	// Duration is how long the run lasted so far.
+	Duration time.Duration `json:"duration_ns"`
+	// Steps lists the steps in the order they finished.
+	Steps []Step `json:"steps"`
+}
+
+// Count returns the number of steps with status.
+func (r Report) Count(status Status) int {
+	n := 0
+
+	for _, step := range r.Steps {
+		if step.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Failed reports whether any step failed.
+func (r Report) Failed() bool {
+	return r.Count(Failed) > 0
+}
+
+// Option configures New.
+type Option func(*Recorder)
+
+// WithClock sets the clock timing the steps.
+func WithClock(c clock.Clock) Option {
+	return func(r *Recorder) {
+		r.clock = c
+	}
+}
+
+// Recorder collects the steps of a run. It is safe for concurrent use.
+type Recorder struct {
+	name  string
+	clock clock.Clock
+	start time.Time
+
+	mu    sync.Mutex
+	steps []Step
+}
+
+// New starts recording the run called name.
+func New(name string, opts ...Option) *Recorder {
+	r := &Recorder{name: name, clock: clock.Real()}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.start = r.clock.Now()
+
+	return r
+}
+
+// Run runs fn as the step called name and records its outcome, which is
+// Failed when fn returns an error or panics, Skipped when fn calls Skip,
+// Changed when it calls Change and OK otherwise. It returns the error of
+// fn, and re-raises its panics once recorded.
+func (r *Recorder) Run(
+	ctx context.Context, name string,
+	fn func(ctx context.Context, step *Step) error,
+) (err error) {
+	step := &Step{Name: name, Start: r.clock.Now()}
+
+	defer func() {
+		value := recover()
+		if value != nil {
+			err = fmt.Errorf("panic: %v", value)
+		}
+
+		step.Duration = r.clock.Since(step.Start)
+		r.Add(finish(*step, err))
+
+		if value != nil {
+			panic(value)
+		}
+	}()
+
+	return fn(ctx, step)
+}
+
+// finish sets the status of step from its outcome.
+func finish(step Step, err error) Step {
+	switch {
+	case err != nil:
+		step.Status, step.Error = Failed, err.Error()
+	case step.Status == Skipped:
+	case len(step.Changes) > 0:
+		step.Status = Changed
+	default:
+		step.Status = OK
+	}
+
+	return step
+}
+
+// Add records a step run elsewhere. A step without status is OK.
+func (r *Recorder) Add(step Step) {
+	if step.Status == "" {
+		step.Status = OK
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.steps = append(r.steps, step)
+}
+
+// Report returns the steps recorded so far.
+func (r *Recorder) Report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Report{
+		Name:     r.name,
+		Start:    r.start,
+		Duration: r.clock.Since(r.start),
+		Steps:    append([]Step(nil), r.steps...),
+	}
+}
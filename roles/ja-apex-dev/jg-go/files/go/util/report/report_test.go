@@ -0,0 +1,116 @@
+package report_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/report"
+)
+
+var epoch = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+// sample records a run with a step of every status.
+func sample(t *testing.T) report.Report {
+	t.Helper()
+
+	fake := clock.NewFake(epoch)
+	rec := report.New("bootstrap", report.WithClock(fake))
+
+	steps := []struct {
+		name string
+		fn   func(ctx context.Context, step *report.Step) error
+	}{
+		{"check disk", func(context.Context, *report.Step) error {
+			fake.Advance(time.Second)
+
+			return nil
+		}},
+		{"install nginx", func(_ context.Context, step *report.Step) error {
+			fake.Advance(2 * time.Minute)
+			step.Change("installed %s", "nginx")
+			step.Change("started")
+
+			return nil
+		}},
+		{"configure <tls>", func(_ context.Context, step *report.Step) error {
+			fake.Advance(3 * time.Second)
+			step.Change("wrote cert")
+
+			return errors.New("reload failed")
+		}},
+		{"restore backup", func(_ context.Context, step *report.Step) error {
+			step.Skip("no backup")
+
+			return nil
+		}},
+	}
+
+	for _, s := range steps {
+		_ = rec.Run(t.Context(), s.name, s.fn)
+	}
+
+	return rec.Report()
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	r := sample(t)
+
+	assert.Equal(t, "bootstrap", r.Name)
+	assert.Equal(t, epoch, r.Start)
+	assert.Equal(t, 2*time.Minute+4*time.Second, r.Duration)
+	assert.True(t, r.Failed())
+
+	require.Len(t, r.Steps, 4)
+	assert.Equal(t, report.Step{
+		Name:     "install nginx",
+		Status:   report.Changed,
+		Start:    epoch.Add(time.Second),
+		Duration: 2 * time.Minute,
+		Changes:  []string{"installed nginx", "started"},
+	}, r.Steps[1])
+
+	for status, n := range map[report.Status]int{
+		report.OK: 1, report.Changed: 1, report.Failed: 1, report.Skipped: 1,
+	} {
+		assert.Equal(t, n, r.Count(status), status)
+	}
+
+	assert.Equal(t, "reload failed", r.Steps[2].Error)
+	assert.Equal(t, "no backup", r.Steps[3].Reason)
+}
+
+func TestRecorderRun(t *testing.T) {
+	t.Parallel()
+
+	rec := report.New("run")
+
+	errStep := errors.New("step")
+	fail := func(context.Context, *report.Step) error {
+		return errStep
+	}
+	require.ErrorIs(t, rec.Run(t.Context(), "fail", fail), errStep)
+
+	boom := func(context.Context, *report.Step) error {
+		panic("boom")
+	}
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = rec.Run(t.Context(), "panic", boom)
+	})
+
+	rec.Add(report.Step{Name: "external"})
+
+	r := rec.Report()
+	require.Len(t, r.Steps, 3)
+	assert.Equal(t, report.Failed, r.Steps[1].Status)
+	assert.Equal(t, "panic: boom", r.Steps[1].Error)
+	assert.Equal(t, report.OK, r.Steps[2].Status)
+	assert.False(t, report.Report{}.Failed())
+}
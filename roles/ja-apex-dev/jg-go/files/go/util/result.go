@@ -0,0 +1,133 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Result carries either a value or the error that prevented it, so that
+// pipelines can send both through a single channel or slice.
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok returns a successful Result holding v.
+func Ok[T any](v T) Result[T] {
+	return Result[T]{value: v}
+}
+
+// Err returns a failed Result holding err.
+func Err[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// ResultOf wraps the usual (value, error) pair of a call into a Result.
+func ResultOf[T any](v T, err error) Result[T] {
+	if err != nil {
+		return Err[T](err)
+	}
+
+	return Ok(v)
+}
+
+// IsOk reports whether r holds a value.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Err returns the error of r, nil when it holds a value.
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Get returns the value and error of r as a regular pair.
+func (r Result[T]) Get() (T, error) {
+	return r.value, r.err
+}
+
+// UnwrapOr returns the value of r, or fallback when it failed.
+func (r Result[T]) UnwrapOr(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+
+	return r.value
+}
+
+// String formats r for logs and test failures.
+func (r Result[T]) String() string {
+	if r.err != nil {
+		return "Err(" + r.err.Error() + ")"
+	}
+
+	return fmt.Sprintf("Ok(%v)", r.value)
+}
+
+// Map applies fn to the value of r, passing failures through unchanged.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Err[U](r.err)
+	}
+
+	return Ok(fn(r.value))
+}
+
+// AndThen chains a fallible step after r, passing failures through
+// unchanged.
+func AndThen[T, U any](r Result[T], fn func(T) (U, error)) Result[U] {
+	if r.err != nil {
+		return Err[U](r.err)
+	}
+
+	return ResultOf(fn(r.value))
+}
+
+// Collect splits results into their values, zero for failures, and all
+// errors joined in order.
+func Collect[T any](results []Result[T]) ([]T, error) {
+	values := make([]T, len(results))
+	failures := make([]error, 0, len(results))
+
+	for i, r := range results {
+		values[i] = r.value
+		if r.err != nil {
+			failures = append(failures, r.err)
+		}
+	}
+
+	return values, errors.Join(failures...)
+}
+
+// Either holds exactly one of two values, for branches that are not
+// errors, such as a cache hit or a value to compute.
+type Either[L, R any] struct {
+	left    L
+	right   R
+	isRight bool
+}
+
+// Left returns an Either holding the left value v.
+func Left[L, R any](v L) Either[L, R] {
+	return Either[L, R]{left: v}
+}
+
+// Right returns an Either holding the right value v.
+func Right[L, R any](v R) Either[L, R] {
+	return Either[L, R]{right: v, isRight: true}
+}
+
+// IsRight reports whether e holds its right value.
+func (e Either[L, R]) IsRight() bool {
+	return e.isRight
+}
+
+// LeftValue returns the left value of e and whether e holds it.
+func (e Either[L, R]) LeftValue() (L, bool) {
+	return e.left, !e.isRight
+}
+
+// RightValue returns the right value of e and whether e holds it.
+func (e Either[L, R]) RightValue() (R, bool) {
+	return e.right, e.isRight
+}
@@ -0,0 +1,66 @@
+package util_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+var errBroken = errors.New("broken")
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+
+	ok := util.ResultOf(strconv.Atoi("21"))
+	doubled := util.Map(ok, func(n int) int { return n * 2 })
+
+	assert.True(t, doubled.IsOk())
+	assert.Equal(t, 42, doubled.UnwrapOr(0))
+	assert.Equal(t, "Ok(42)", doubled.String())
+
+	parsed := util.AndThen(util.Ok("x"), strconv.Atoi)
+	require.Error(t, parsed.Err())
+	assert.Equal(t, -1, parsed.UnwrapOr(-1))
+
+	failed := util.Map(util.Err[int](errBroken), strconv.Itoa)
+	_, err := failed.Get()
+	require.ErrorIs(t, err, errBroken)
+	assert.Equal(t, "Err(broken)", failed.String())
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	values, err := util.Collect([]util.Result[int]{
+		util.Ok(1), util.Err[int](errBroken), util.Ok(3),
+	})
+
+	assert.Equal(t, []int{1, 0, 3}, values)
+	require.ErrorIs(t, err, errBroken)
+
+	_, err = util.Collect([]util.Result[int]{util.Ok(1)})
+	require.NoError(t, err)
+}
+
+func TestEither(t *testing.T) {
+	t.Parallel()
+
+	left := util.Left[string, int]("cached")
+	value, ok := left.LeftValue()
+	assert.True(t, ok)
+	assert.Equal(t, "cached", value)
+	assert.False(t, left.IsRight())
+
+	right := util.Right[string](7)
+	n, ok := right.RightValue()
+	assert.True(t, ok)
+	assert.Equal(t, 7, n)
+
+	_, ok = right.LeftValue()
+	assert.False(t, ok)
+}
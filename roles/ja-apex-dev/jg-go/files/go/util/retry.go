@@ -0,0 +1,210 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"example.com/go-template/util/clock"
+)
+
+// ErrRetriesExhausted is joined with the last error once all attempts fail.
+var ErrRetriesExhausted = errors.New("util: retries exhausted")
+
+// RetryPolicy describes how Retry schedules attempts. Zero fields fall back
+// to the values of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialDelay is the delay before the second attempt.
+	InitialDelay time.Duration
+	// MaxDelay caps the exponential growth of the delay, and the delays
+	// requested by RetryAfter.
+	MaxDelay time.Duration
+	// Multiplier is the factor applied to the delay after every attempt.
+	Multiplier float64
+	// Jitter is the fraction of every delay that is randomized, in [0, 1].
+	Jitter float64
+	// AttemptTimeout bounds every attempt, unless zero.
+	AttemptTimeout time.Duration
+	// Retryable decides whether an error is worth another attempt. By
+	// default every error except those marked by Permanent is retried.
+	Retryable func(error) bool
+	// Clock schedules the delays between attempts, the system clock by
+	// default.
+	Clock clock.Clock
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts and exponential backoff
+// starting at 100ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = def.InitialDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+
+	if p.Multiplier < 1 {
+		p.Multiplier = def.Multiplier
+	}
+
+	p.Jitter = min(max(p.Jitter, 0), 1)
+	p.Clock = clock.OrReal(p.Clock)
+
+	return p
+}
+
+// Backoff returns the delay after the given attempt, starting from 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	cfg := p.withDefaults()
+
+	delay := float64(cfg.InitialDelay) *
+		math.Pow(cfg.Multiplier, float64(max(attempt-1, 0)))
+	delay = min(delay, float64(cfg.MaxDelay))
+
+	if cfg.Jitter > 0 {
+		//nolint:gosec // Jitter does not need a secure source.
+		delay -= delay * cfg.Jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as non-retryable, stopping Retry immediately.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked by Permanent.
+func IsPermanent(err error) bool {
+	var target *permanentError
+
+	return errors.As(err, &target)
+}
+
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter annotates err with the delay to wait before the next attempt,
+// overriding the policy backoff (e.g. from a Retry-After header). The delay
+// is still bounded by the MaxDelay of the policy.
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, the
+// attempts are exhausted or ctx is done. Every attempt receives its own
+// context bounded by AttemptTimeout.
+func Retry(
+	ctx context.Context, policy RetryPolicy, fn func(context.Context) error,
+) error {
+	policy = policy.withDefaults()
+
+	var last error
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(err, last)
+		}
+
+		last = runAttempt(ctx, policy.AttemptTimeout, fn)
+		if last == nil {
+			return nil
+		}
+
+		if !policy.retryable(last) {
+			return last
+		}
+
+		if attempt >= policy.MaxAttempts {
+			return fmt.Errorf("%w after %d attempts: %w",
+				ErrRetriesExhausted, attempt, last)
+		}
+
+		err := policy.Clock.Sleep(ctx, policy.delay(attempt, last))
+		if err != nil {
+			return errors.Join(err, last)
+		}
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var hint *retryAfterError
+	if errors.As(err, &hint) {
+		return min(hint.delay, p.MaxDelay)
+	}
+
+	return p.Backoff(attempt)
+}
+
+func runAttempt(
+	ctx context.Context, timeout time.Duration, fn func(context.Context) error,
+) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return fn(attemptCtx)
+}
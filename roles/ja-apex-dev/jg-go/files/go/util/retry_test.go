@@ -0,0 +1,212 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/clock"
+)
+
+var errFlaky = errors.New("flaky")
+
+func fastPolicy() util.RetryPolicy {
+	return util.RetryPolicy{
+		MaxAttempts:  4,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     2 * time.Millisecond,
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := util.Retry(t.Context(), fastPolicy(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errFlaky
+		}
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := util.Retry(t.Context(), fastPolicy(), func(context.Context) error {
+		calls++
+
+		return errFlaky
+	})
+
+	require.ErrorIs(t, err, util.ErrRetriesExhausted)
+	require.ErrorIs(t, err, errFlaky)
+	assert.Equal(t, 4, calls)
+}
+
+func TestRetryPermanent(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := util.Retry(t.Context(), fastPolicy(), func(context.Context) error {
+		calls++
+
+		return util.Permanent(errFlaky)
+	})
+
+	require.ErrorIs(t, err, errFlaky)
+	assert.True(t, util.IsPermanent(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryRetryablePredicate(t *testing.T) {
+	t.Parallel()
+
+	policy := fastPolicy()
+	policy.Retryable = func(err error) bool { return !errors.Is(err, errFlaky) }
+
+	calls := 0
+	err := util.Retry(t.Context(), policy, func(context.Context) error {
+		calls++
+
+		return errFlaky
+	})
+
+	require.ErrorIs(t, err, errFlaky)
+	assert.NotErrorIs(t, err, util.ErrRetriesExhausted)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	policy := util.RetryPolicy{MaxAttempts: 10, InitialDelay: time.Hour}
+
+	calls := 0
+	err := util.Retry(ctx, policy, func(context.Context) error {
+		calls++
+
+		cancel()
+
+		return errFlaky
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, errFlaky)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	policy := fastPolicy()
+	policy.MaxAttempts = 2
+	policy.AttemptTimeout = time.Millisecond
+
+	err := util.Retry(t.Context(), policy, func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, err, util.ErrRetriesExhausted)
+}
+
+func TestRetryAfterOverridesBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := util.RetryPolicy{MaxAttempts: 2, InitialDelay: time.Hour}
+
+	calls := 0
+	err := util.Retry(t.Context(), policy, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return util.RetryAfter(errFlaky, time.Millisecond)
+		}
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryAfterBoundedByMaxDelay(t *testing.T) {
+	t.Parallel()
+
+	policy := util.RetryPolicy{MaxAttempts: 2, MaxDelay: time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	calls := 0
+	err := util.Retry(ctx, policy, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return util.RetryAfter(errFlaky, time.Hour)
+		}
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryClock(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	policy := util.RetryPolicy{
+		MaxAttempts: 3, InitialDelay: time.Hour, Clock: fake,
+	}
+
+	done := make(chan error)
+
+	go func() {
+		done <- util.Retry(t.Context(), policy, func(context.Context) error {
+			return errFlaky
+		})
+	}()
+
+	for _, delay := range []time.Duration{time.Hour, 2 * time.Hour} {
+		require.NoError(t, fake.BlockUntil(t.Context(), 1))
+		fake.Advance(delay)
+	}
+
+	require.ErrorIs(t, <-done, util.ErrRetriesExhausted)
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := util.RetryPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+	}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(10))
+
+	policy.Jitter = 0.5
+	for attempt := 1; attempt < 5; attempt++ {
+		delay := policy.Backoff(attempt)
+		assert.LessOrEqual(t, delay, 5*time.Second)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+	}
+}
@@ -0,0 +1,295 @@
+package sched
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSyntax is returned for malformed schedules.
+var ErrSyntax = errors.New("sched: invalid schedule")
+
+// searchYears bounds the search of the next time matching a cron
+// expression, for impossible dates such as February 30.
+const searchYears = 5
+
+// Schedule computes the run times of a job.
+type Schedule interface {
+	// Next returns the first run time after after, or the zero time when
+	// there is none.
+	Next(after time.Time) time.Time
+}
+
+// Every returns a schedule running every d, which must be positive.
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+type interval time.Duration
+
+// Next implements Schedule.
+func (i interval) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(i))
+}
+
+// String returns the @every syntax of the schedule.
+func (i interval) String() string {
+	return "@every " + time.Duration(i).String()
+}
+
+// macros are the shorthands of common cron expressions.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression, a macro such as @daily or a fixed
+// interval such as "@every 90s".
+func Parse(spec string) (Schedule, error) {
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrSyntax, spec)
+		}
+
+		return Every(d), nil
+	}
+
+	return ParseCron(spec)
+}
+
+// MustParse is like Parse but panics on malformed schedules, for
+// constants.
+func MustParse(spec string) Schedule {
+	schedule, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return schedule
+}
+
+// field describes a field of cron expressions.
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	months = []string{
+		"", "jan", "feb", "mar", "apr", "may", "jun",
+		"jul", "aug", "sep", "oct", "nov", "dec",
+	}
+	weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+	fields = [...]field{
+		{"minute", 0, 59, nil},
+		{"hour", 0, 23, nil},
+		{"day of month", 1, 31, nil},
+		{"month", 1, 12, months},
+		// 7 is Sunday too, folded into 0.
+		{"day of week", 0, 7, weekdays},
+	}
+)
+
+// Cron is a schedule in the five-field cron syntax: minute, hour, day of
+// month, month and day of week. Fields accept *, values, ranges (1-5),
+// steps (*/15, 0-30/10) and lists of them (1,15), and month and weekday
+// names (jan, mon). As in cron, a day matches when either of the day
+// fields does, unless one of them is *.
+type Cron struct {
+	expr string
+	// sets holds a bit per allowed value of every field.
+	sets [len(fields)]uint64
+	// anyDay records whether the day fields are *.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// ParseCron parses a cron expression or macro.
+func ParseCron(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[spec]; ok {
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: %q: want %d fields", ErrSyntax, expr,
+			len(fields))
+	}
+
+	c := &Cron{
+		expr:          expr,
+		anyDayOfMonth: parts[2] == "*",
+		anyDayOfWeek:  parts[4] == "*",
+	}
+
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s: %w", ErrSyntax, expr,
+				fields[i].name, err)
+		}
+
+		c.sets[i] = set
+	}
+
+	if c.sets[4]&(1<<7) != 0 {
+		c.sets[4] |= 1
+	}
+
+	return c, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next implements Schedule, in the location of after. The wall clock
+// times skipped by a daylight saving transition never match, while those
+// repeated by one match twice.
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+
+	for t.Before(limit) {
+		next, ok := c.advance(t)
+		if ok {
+			return t
+		}
+
+		t = next
+	}
+
+	return time.Time{}
+}
+
+// advance reports whether t matches, or returns the first time that may
+// match after t, skipping the non-matching month, day, hour or minute.
+// Hours and minutes are skipped in absolute time, since the wall clock
+// ones may not exist or be ambiguous around daylight saving transitions.
+func (c *Cron) advance(t time.Time) (time.Time, bool) {
+	year, month, day := t.Date()
+	hour, minute, loc := t.Hour(), t.Minute(), t.Location()
+
+	switch {
+	case !c.has(3, int(month)):
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, loc), false
+	case !c.dayMatches(t):
+		return time.Date(year, month, day+1, 0, 0, 0, 0, loc), false
+	case !c.has(1, hour):
+		return t.Add(time.Duration(60-minute) * time.Minute), false
+	case !c.has(0, minute):
+		return t.Add(time.Minute), false
+	default:
+		return t, true
+	}
+}
+
+// dayMatches applies the cron rule combining the day fields.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dayOfMonth := c.has(2, t.Day())
+	dayOfWeek := c.has(4, int(t.Weekday()))
+
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
+
+func (c *Cron) has(field, value int) bool {
+	return c.sets[field]&(1<<value) != 0
+}
+
+// parseField returns the bit set of the values allowed by a field.
+func parseField(spec string, f field) (uint64, error) {
+	var set uint64
+
+	for part := range strings.SplitSeq(spec, ",") {
+		low, high, step, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+
+	return set, nil
+}
+
+// parseRange parses *, a value or a range, with an optional step.
+func parseRange(part string, f field) (low, high, step int, err error) {
+	base, step, err := splitStep(part)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	lowText, highText, isRange := strings.Cut(base, "-")
+
+	switch {
+	case base == "*":
+		return f.min, f.max, max(step, 1), nil
+	case isRange:
+		low, err = f.value(lowText)
+		if err == nil {
+			high, err = f.value(highText)
+		}
+	default:
+		// A value with a step, such as 5/10, ranges up to the maximum.
+		low, err = f.value(base)
+		high = low
+
+		if step > 0 {
+			high = f.max
+		}
+	}
+
+	if err == nil && low > high {
+		err = fmt.Errorf("range %q", base)
+	}
+
+	return low, high, max(step, 1), err
+}
+
+// splitStep splits the step off a part, returning 0 without one.
+func splitStep(part string) (string, int, error) {
+	base, text, ok := strings.Cut(part, "/")
+	if !ok {
+		return base, 0, nil
+	}
+
+	step, err := strconv.Atoi(text)
+	if err != nil || step <= 0 {
+		return "", 0, fmt.Errorf("step %q", text)
+	}
+
+	return base, step, nil
+}
+
+// value parses a number or name within the bounds of the field.
+func (f field) value(text string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(text, name) {
+			return i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(text)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %q", text)
+	}
+
+	return n, nil
+}
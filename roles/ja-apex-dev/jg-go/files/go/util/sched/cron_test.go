@@ -0,0 +1,116 @@
+package sched_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/sched"
+)
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	// A Wednesday.
+	after := time.Date(2024, 1, 10, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 10, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 10, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 10, 13, 0, 0, 0, time.UTC)},
+		{"5,10 3 * * *", time.Date(2024, 1, 11, 3, 5, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"30 8 * * MON-FRI", time.Date(2024, 1, 11, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * sat", time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 10, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := sched.ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(after))
+			assert.Equal(t, tt.expr, schedule.String())
+		})
+	}
+}
+
+func TestCronNextImpossible(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := sched.ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestCronNextLocation(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	schedule := sched.MustParse("@daily")
+
+	got := schedule.Next(time.Date(2024, 1, 10, 23, 0, 0, 0, time.UTC).In(loc))
+	assert.Equal(t, time.Date(2024, 1, 12, 0, 0, 0, 0, loc), got)
+}
+
+func TestCronNextDST(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC).In(loc)
+	}
+
+	tests := []struct {
+		name        string
+		expr        string
+		after, want time.Time
+	}{
+		// 2024-03-10 02:00 EST jumps to 03:00 EDT.
+		{"across spring", "0 5 * * *", utc(3, 9, 17, 0), utc(3, 10, 9, 0)},
+		{"skipped", "30 2 * * *", utc(3, 10, 6, 59), utc(3, 11, 6, 30)},
+		// 2024-11-03 02:00 EDT falls back to 01:00 EST.
+		{"repeated", "45 1 * * *", utc(11, 3, 6, 30), utc(11, 3, 6, 45)},
+		{"hourly", "0 * * * *", utc(11, 3, 5, 30), utc(11, 3, 6, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := sched.MustParse(tt.expr).Next(tt.after)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.After(tt.after))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := sched.Parse("@every 90s")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 10, 10, 17, 30, 0, time.UTC)
+	assert.Equal(t, start.Add(90*time.Second), schedule.Next(start))
+
+	for _, spec := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * foo *", "5-1 * * * *", "*/0 * * * *",
+		"@every 0s", "@every soon", "@weekdays",
+	} {
+		_, err := sched.Parse(spec)
+		require.ErrorIs(t, err, sched.ErrSyntax, spec)
+	}
+}
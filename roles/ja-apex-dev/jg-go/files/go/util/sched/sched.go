@@ -0,0 +1,444 @@
+// Package sched runs jobs on cron expressions or fixed intervals:
+//
+//	s := sched.New(sched.WithLogger(logger), sched.WithMetrics(registry))
+//	_ = s.Add("cleanup", sched.MustParse("@every 10m"), cleanup,
+//		sched.WithJitter(time.Minute))
+//	s.Register(runner)
+//
+// A single goroutine times the jobs, ordered by their next run in a
+// pq.Queue. A job is skipped while its previous run has not returned, a
+// panicking run is recovered and reported as a failure, and every run is
+// counted and timed. On shutdown, the scheduler stops starting runs and
+// waits for those in progress, which see their context canceled once the
+// stop timeout expires.
+package sched
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/metrics"
+	"example.com/go-template/util/pq"
+)
+
+// Errors returned by Add and Start.
+var (
+	ErrDuplicate = errors.New("sched: duplicate job")
+	ErrStarted   = errors.New("sched: already started")
+	ErrPanic     = errors.New("sched: job panicked")
+)
+
+// Statuses of the runs, as the status label of the metrics.
+const (
+	StatusOK      = "ok"
+	StatusError   = "error"
+	StatusPanic   = "panic"
+	StatusSkipped = "skipped"
+)
+
+// Func is the work of a job. Its context is canceled when the scheduler
+// stops for longer than the stop timeout, or when the job times out.
+type Func func(ctx context.Context) error
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	clock    clock.Clock
+	logger   *slog.Logger
+	location *time.Location
+	metrics  *metrics.Registry
+}
+
+// WithClock sets the clock timing the runs, clock.Real by default.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.clock = c
+	}
+}
+
+// WithLogger sets the logger reporting failed and skipped runs.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *settings) {
+		s.logger = logger
+	}
+}
+
+// WithLocation evaluates cron expressions in loc instead of time.Local.
+func WithLocation(loc *time.Location) Option {
+	return func(s *settings) {
+		s.location = loc
+	}
+}
+
+// WithMetrics exports the sched_job_runs_total counter, the
+// sched_job_duration_seconds histogram and the
+// sched_job_last_success_timestamp_seconds gauge, labeled by job.
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(s *settings) {
+		s.metrics = registry
+	}
+}
+
+// JobOption configures Add.
+type JobOption func(*job)
+
+// WithJitter delays every run by a random duration up to max, spreading
+// the jobs of several instances sharing a schedule.
+func WithJitter(maxDelay time.Duration) JobOption {
+	return func(j *job) {
+		j.jitter = maxDelay
+	}
+}
+
+// WithTimeout cancels the context of every run after d.
+func WithTimeout(d time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// WithOverlap lets a run start while the previous one is in progress,
+// instead of skipping it.
+func WithOverlap() JobOption {
+	return func(j *job) {
+		j.overlap = true
+	}
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Func
+	jitter   time.Duration
+	timeout  time.Duration
+	overlap  bool
+	running  atomic.Bool
+}
+
+// Scheduler runs jobs on their schedules. It is safe for concurrent use.
+type Scheduler struct {
+	settings
+	instruments
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	// stopLoop ends the loop timing the jobs, and cancelRuns the runs.
+	stopLoop, cancelRuns context.CancelFunc
+	loop, runs           sync.WaitGroup
+}
+
+// New returns a scheduler without jobs.
+func New(opts ...Option) *Scheduler {
+	s := settings{
+		clock:    clock.Real(),
+		logger:   slog.New(slog.DiscardHandler),
+		location: time.Local,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &Scheduler{
+		settings:    s,
+		instruments: newInstruments(s.metrics),
+		jobs:        map[string]*job{},
+	}
+}
+
+// Add schedules fn under name, which must be unique. Jobs are added
+// before Start.
+func (s *Scheduler) Add(
+	name string, schedule Schedule, fn Func, opts ...JobOption,
+) error {
+	j := &job{name: name, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("%w: adding %s", ErrStarted, name)
+	}
+
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, name)
+	}
+
+	s.jobs[name] = j
+
+	return nil
+}
+
+// Start starts timing the jobs and returns. The runs outlive ctx, until
+// Stop.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrStarted
+	}
+
+	s.started = true
+
+	base := context.WithoutCancel(ctx)
+
+	var loopCtx, runCtx context.Context
+	loopCtx, s.stopLoop = context.WithCancel(base)
+	runCtx, s.cancelRuns = context.WithCancel(base)
+
+	due := pq.New[*job](time.Time.Before)
+	now := s.clock.Now()
+
+	for _, name := range slices.Sorted(maps.Keys(s.jobs)) {
+		j := s.jobs[name]
+		if next, ok := s.next(loopCtx, j, now); ok {
+			due.Push(j, next)
+		}
+	}
+
+	s.loop.Go(func() {
+		s.dispatch(loopCtx, runCtx, due)
+	})
+
+	return nil
+}
+
+// Stop stops starting runs and waits for those in progress until ctx is
+// done, then cancels their context and returns the error of ctx.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+
+	s.stopLoop()
+	s.loop.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelRuns()
+
+		return nil
+	case <-ctx.Done():
+		s.cancelRuns()
+
+		return fmt.Errorf("sched: stop: %w", ctx.Err())
+	}
+}
+
+// Register appends the scheduler to runner, started with the other hooks
+// and stopped on shutdown within lifecycle.DefaultStopTimeout.
+func (s *Scheduler) Register(runner *lifecycle.Runner) {
+	runner.Append(lifecycle.Hook{
+		Name:  "sched",
+		Start: s.Start,
+		Stop:  s.Stop,
+	})
+}
+
+// Run runs the jobs until ctx is done, then stops as Stop without a
+// deadline. It is the alternative to Register for programs running
+// nothing else.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if err := s.Start(ctx); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+
+	return s.Stop(context.WithoutCancel(ctx))
+}
+
+// dispatch waits for the earliest job of due with a single timer,
+// triggering the jobs falling due until loopCtx is done or no job has a
+// next run.
+func (s *Scheduler) dispatch(
+	loopCtx, runCtx context.Context, due *pq.Queue[*job, time.Time],
+) {
+	for {
+		first, ok := due.Peek()
+		if !ok {
+			return
+		}
+
+		now := s.clock.Now()
+		timer := s.clock.NewTimer(max(first.Priority().Sub(now), 0))
+
+		select {
+		case <-loopCtx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C():
+		}
+
+		s.fire(loopCtx, runCtx, due, s.clock.Now())
+	}
+}
+
+// fire triggers the jobs of due falling due by now, and reschedules them.
+func (s *Scheduler) fire(
+	loopCtx, runCtx context.Context, due *pq.Queue[*job, time.Time],
+	now time.Time,
+) {
+	for {
+		item, ok := due.Peek()
+		if !ok || item.Priority().After(now) {
+			return
+		}
+
+		s.trigger(runCtx, item.Value)
+
+		if next, ok := s.next(loopCtx, item.Value, now); ok {
+			due.Update(item, next)
+		} else {
+			due.Remove(item)
+		}
+	}
+}
+
+// next returns the time of the run of j after now, jitter included, and
+// false when it has none.
+func (s *Scheduler) next(
+	ctx context.Context, j *job, now time.Time,
+) (time.Time, bool) {
+	next := j.schedule.Next(now.In(s.location))
+	if next.IsZero() {
+		s.logger.WarnContext(ctx, "job has no next run", "job", j.name)
+
+		return time.Time{}, false
+	}
+
+	return next.Add(j.delay()), true
+}
+
+// trigger starts a run of j, unless the previous one is in progress.
+func (s *Scheduler) trigger(ctx context.Context, j *job) {
+	if !j.overlap && !j.running.CompareAndSwap(false, true) {
+		s.logger.WarnContext(ctx, "job still running, skipped",
+			"job", j.name)
+		s.record(j.name, StatusSkipped)
+
+		return
+	}
+
+	s.runs.Go(func() {
+		if !j.overlap {
+			defer j.running.Store(false)
+		}
+
+		s.execute(ctx, j)
+	})
+}
+
+// execute runs j and records the outcome.
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+
+		defer cancel()
+	}
+
+	start := s.clock.Now()
+	err := call(ctx, j.fn)
+	s.observe(j.name, s.clock.Since(start))
+
+	switch {
+	case errors.Is(err, ErrPanic):
+		s.logger.ErrorContext(ctx, "job panicked", "job", j.name, "error", err)
+		s.record(j.name, StatusPanic)
+	case err != nil:
+		s.logger.ErrorContext(ctx, "job failed", "job", j.name, "error", err)
+		s.record(j.name, StatusError)
+	default:
+		s.record(j.name, StatusOK)
+		s.succeeded(j.name, s.clock.Now())
+	}
+}
+
+// delay returns the random delay of a run.
+func (j *job) delay() time.Duration {
+	if j.jitter <= 0 {
+		return 0
+	}
+
+	//nolint:gosec // Jitter does not need a secure source.
+	return rand.N(j.jitter)
+}
+
+// call runs fn, turning a panic into an error wrapping ErrPanic.
+func call(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// instruments records the runs when metrics are enabled.
+type instruments struct {
+	runs        *metrics.Counter
+	duration    *metrics.Histogram
+	lastSuccess *metrics.Gauge
+}
+
+func newInstruments(registry *metrics.Registry) instruments {
+	if registry == nil {
+		return instruments{}
+	}
+
+	return instruments{
+		runs: registry.Counter("sched_job_runs_total",
+			"Runs of the scheduled jobs by status.", "job", "status"),
+		duration: registry.Histogram("sched_job_duration_seconds",
+			"Duration of the runs of the scheduled jobs.", nil, "job"),
+		lastSuccess: registry.Gauge("sched_job_last_success_timestamp_seconds",
+			"Unix time of the last successful run of the scheduled jobs.",
+			"job"),
+	}
+}
+
+func (i instruments) record(name, status string) {
+	if i.runs != nil {
+		i.runs.Inc(name, status)
+	}
+}
+
+func (i instruments) observe(name string, d time.Duration) {
+	if i.duration != nil {
+		i.duration.Observe(d.Seconds(), name)
+	}
+}
+
+func (i instruments) succeeded(name string, t time.Time) {
+	if i.lastSuccess != nil {
+		i.lastSuccess.Set(float64(t.Unix()), name)
+	}
+}
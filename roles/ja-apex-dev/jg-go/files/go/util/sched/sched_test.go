@@ -0,0 +1,287 @@
+package sched_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/lifecycle"
+	"example.com/go-template/util/metrics"
+	"example.com/go-template/util/sched"
+	"example.com/go-template/util/testx"
+)
+
+var start = time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequestWithContext(
+		t.Context(), http.MethodGet, metrics.Path, nil))
+
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+// tick moves fake by d once the scheduler waits for the next run.
+func tick(t *testing.T, fake *clock.Fake, d time.Duration) {
+	t.Helper()
+
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+	fake.Advance(d)
+}
+
+func TestSchedulerRuns(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	s := sched.New(sched.WithClock(fake), sched.WithMetrics(registry))
+
+	var runs atomic.Int32
+	require.NoError(t, s.Add("tick", sched.Every(time.Minute),
+		func(context.Context) error {
+			runs.Add(1)
+
+			return nil
+		}))
+	require.NoError(t, s.Start(t.Context()))
+
+	for i := range int32(3) {
+		tick(t, fake, time.Minute)
+		testx.RequireEventually(t, func() bool {
+			return runs.Load() == i+1
+		}, time.Second)
+	}
+
+	require.NoError(t, s.Stop(t.Context()))
+
+	exposition := scrape(t, registry)
+	assert.Contains(t, exposition,
+		`sched_job_runs_total{job="tick",status="ok"} 3`)
+	assert.Contains(t, exposition,
+		`sched_job_duration_seconds_count{job="tick"} 3`)
+	assert.Contains(t, exposition,
+		`sched_job_last_success_timestamp_seconds{job="tick"}`)
+}
+
+func TestSchedulerCron(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start.Add(59 * time.Minute))
+	s := sched.New(sched.WithClock(fake), sched.WithLocation(time.UTC))
+
+	ran := make(chan time.Time, 1)
+	require.NoError(t, s.Add("hourly", sched.MustParse("@hourly"),
+		func(context.Context) error {
+			ran <- fake.Now()
+
+			return nil
+		}))
+	require.NoError(t, s.Start(t.Context()))
+
+	tick(t, fake, time.Minute)
+	assert.Equal(t, start.Add(time.Hour), <-ran)
+	require.NoError(t, s.Stop(t.Context()))
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	s := sched.New(sched.WithClock(fake))
+
+	ran := make(chan time.Time, 1)
+	require.NoError(t, s.Add("jittered", sched.Every(time.Minute),
+		func(context.Context) error {
+			ran <- fake.Now()
+
+			return nil
+		}, sched.WithJitter(time.Second)))
+	require.NoError(t, s.Start(t.Context()))
+
+	tick(t, fake, time.Minute+time.Second)
+
+	at := <-ran
+	assert.False(t, at.Before(start.Add(time.Minute)))
+	require.NoError(t, s.Stop(t.Context()))
+}
+
+func TestSchedulerWithoutNextRun(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	s := sched.New(sched.WithClock(fake))
+
+	never := func(context.Context) error {
+		t.Error("impossible job ran")
+
+		return nil
+	}
+	require.NoError(t, s.Add("never", sched.MustParse("0 0 30 2 *"), never))
+
+	var runs atomic.Int32
+	require.NoError(t, s.Add("tick", sched.Every(time.Minute),
+		func(context.Context) error {
+			runs.Add(1)
+
+			return nil
+		}))
+	require.NoError(t, s.Start(t.Context()))
+
+	tick(t, fake, time.Minute)
+	testx.RequireEventually(t, func() bool {
+		return runs.Load() == 1
+	}, time.Second)
+	require.NoError(t, s.Stop(t.Context()))
+}
+
+func TestSchedulerSkipsOverlap(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	s := sched.New(sched.WithClock(fake), sched.WithMetrics(registry))
+
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	require.NoError(t, s.Add("slow", sched.Every(time.Minute),
+		func(context.Context) error {
+			started <- struct{}{}
+			<-release
+
+			return nil
+		}))
+	require.NoError(t, s.Start(t.Context()))
+
+	tick(t, fake, time.Minute)
+	<-started
+	tick(t, fake, time.Minute)
+	testx.RequireEventually(t, func() bool {
+		return strings.Contains(scrape(t, registry),
+			`sched_job_runs_total{job="slow",status="skipped"} 1`)
+	}, time.Second)
+	close(release)
+
+	require.NoError(t, s.Stop(t.Context()))
+	assert.Empty(t, started)
+}
+
+func TestSchedulerFailures(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	registry := metrics.New(metrics.WithoutRuntimeCollectors())
+	s := sched.New(sched.WithClock(fake), sched.WithMetrics(registry))
+
+	require.NoError(t, s.Add("boom", sched.Every(time.Minute),
+		func(context.Context) error {
+			panic("boom")
+		}))
+	require.NoError(t, s.Add("fail", sched.Every(time.Minute),
+		func(context.Context) error {
+			return errors.New("failed")
+		}))
+	require.NoError(t, s.Start(t.Context()))
+
+	for i := 1; i <= 2; i++ {
+		tick(t, fake, time.Minute)
+		testx.RequireEventually(t, func() bool {
+			exposition := scrape(t, registry)
+
+			return strings.Contains(exposition, fmt.Sprintf(
+				`sched_job_runs_total{job="boom",status="panic"} %d`, i)) &&
+				strings.Contains(exposition, fmt.Sprintf(
+					`sched_job_runs_total{job="fail",status="error"} %d`, i))
+		}, time.Second)
+	}
+
+	require.NoError(t, s.Stop(t.Context()))
+}
+
+func TestSchedulerStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	s := sched.New(sched.WithClock(fake))
+
+	started, canceled := make(chan struct{}), make(chan struct{})
+	require.NoError(t, s.Add("stuck", sched.Every(time.Minute),
+		func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+
+			return ctx.Err()
+		}))
+	require.NoError(t, s.Start(t.Context()))
+
+	tick(t, fake, time.Minute)
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+	<-canceled
+}
+
+func TestSchedulerAdd(t *testing.T) {
+	t.Parallel()
+
+	s := sched.New()
+	noop := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Add("job", sched.Every(time.Hour), noop))
+	require.ErrorIs(t, s.Add("job", sched.Every(time.Hour), noop),
+		sched.ErrDuplicate)
+
+	require.NoError(t, s.Start(t.Context()))
+	require.ErrorIs(t, s.Start(t.Context()), sched.ErrStarted)
+	require.ErrorIs(t, s.Add("late", sched.Every(time.Hour), noop),
+		sched.ErrStarted)
+	require.NoError(t, s.Stop(t.Context()))
+}
+
+func TestSchedulerRegister(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(start)
+	s := sched.New(sched.WithClock(fake))
+
+	ran := make(chan struct{})
+	require.NoError(t, s.Add("once", sched.Every(time.Minute),
+		func(context.Context) error {
+			close(ran)
+
+			return nil
+		}))
+
+	runner := lifecycle.New()
+	s.Register(runner)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+
+	tick(t, fake, time.Minute)
+	<-ran
+	cancel()
+	require.NoError(t, <-done)
+}
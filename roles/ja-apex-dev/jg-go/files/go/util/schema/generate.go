@@ -0,0 +1,383 @@
+package schema
+
+import (
+	"encoding"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/go-template/util"
+)
+
+// TagName is the struct tag holding the constraints of Generate.
+const TagName = "schema"
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	timeType            = reflect.TypeFor[time.Time]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// scalars maps the Go kinds of scalars to their JSON type.
+var scalars = map[reflect.Kind]Type{
+	reflect.Bool:    Boolean,
+	reflect.Int:     Integer,
+	reflect.Int8:    Integer,
+	reflect.Int16:   Integer,
+	reflect.Int32:   Integer,
+	reflect.Int64:   Integer,
+	reflect.Uint:    Integer,
+	reflect.Uint8:   Integer,
+	reflect.Uint16:  Integer,
+	reflect.Uint32:  Integer,
+	reflect.Uint64:  Integer,
+	reflect.Float32: Number,
+	reflect.Float64: Number,
+	reflect.String:  String,
+}
+
+// constraints are the options of the schema tag taking a value.
+var constraints = map[string]func(s *Schema, value string) error{
+	"enum":    setEnum,
+	"format":  setFormat,
+	"min":     setMin,
+	"max":     setMax,
+	"pattern": setPattern,
+}
+
+// Option configures Generate.
+type Option func(*generator)
+
+// WithNameTag names members after the tag, such as "config" or "yaml",
+// instead of the json one. Fields without it are named by their lowercase
+// Go name, as the config package does.
+func WithNameTag(tag string) Option {
+	return func(g *generator) {
+		g.nameTag = tag
+	}
+}
+
+// Generate derives the schema of the type of v, typically a configuration
+// struct, for documentation and validation. Struct fields are named like
+// encoding/json does, untagged embedded structs are flattened and unknown
+// members are rejected. The description and default come from the usage
+// and default tags of the config package, and constraints from the schema
+// tag:
+//
+//	Port int    `json:"port" usage:"listen port" schema:"required,min=1"`
+//	Mode string `json:"mode" default:"fast" schema:"enum=fast|safe"`
+//	Name string `json:"name" schema:"min=1,max=64,pattern=^[a-z]+$"`
+//
+// min and max bound lengths, item counts or values depending on the type.
+// The pattern option consumes the rest of the tag and must come last.
+// Durations, times and text marshalers are strings.
+func Generate(v any, opts ...Option) (*Schema, error) {
+	g := &generator{nameTag: "json", visiting: make(map[reflect.Type]bool)}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+
+	return g.schema(t)
+}
+
+// generator holds the state of a single Generate call.
+type generator struct {
+	nameTag  string
+	visiting map[reflect.Type]bool
+}
+
+func (g *generator) schema(t reflect.Type) (*Schema, error) {
+	t = indirectType(t)
+	if s := scalar(t); s != nil {
+		return s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		return g.array(t)
+	case reflect.Map:
+		return g.object(t)
+	case reflect.Struct:
+		return g.structure(t)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+}
+
+// scalar describes the types of scalars, which are textual if they
+// implement encoding.TextMarshaler, or returns nil.
+func scalar(t reflect.Type) *Schema {
+	switch {
+	case t == durationType:
+		return &Schema{Type: String, Format: "duration"}
+	case t == timeType:
+		return &Schema{Type: String, Format: "date-time"}
+	case t.Implements(textMarshalerType),
+		reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return &Schema{Type: String}
+	}
+
+	if typ, ok := scalars[t.Kind()]; ok {
+		return &Schema{Type: typ}
+	}
+
+	return nil
+}
+
+// array describes slices, except byte slices encoded as base64 strings.
+func (g *generator) array(t reflect.Type) (*Schema, error) {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return &Schema{Type: String, Format: "byte"}, nil
+	}
+
+	items, err := g.schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{Type: Array, Items: items}, nil
+}
+
+// object describes maps, whose keys must be strings.
+func (g *generator) object(t reflect.Type) (*Schema, error) {
+	if t.Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+
+	values, err := g.schema(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{Type: Object, AdditionalProperties: values}, nil
+}
+
+// structure describes structs, which cannot contain themselves.
+func (g *generator) structure(t reflect.Type) (*Schema, error) {
+	if g.visiting[t] {
+		return nil, fmt.Errorf("%w: recursive %s", ErrUnsupportedType, t)
+	}
+
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+
+	s := &Schema{
+		Type:                 Object,
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: never(),
+	}
+	if err := g.fields(s, t); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// fields adds the fields of t to the properties of s.
+func (g *generator) fields(s *Schema, t reflect.Type) error {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		name, hasName := g.name(sf)
+
+		switch {
+		case name == "-":
+		case sf.Anonymous && !hasName && isStruct(sf.Type):
+			if err := g.fields(s, indirectType(sf.Type)); err != nil {
+				return err
+			}
+		case sf.IsExported():
+			if err := g.field(s, sf, name); err != nil {
+				return fmt.Errorf("%w (field %s)", err, sf.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// field adds the property name described by sf to s.
+func (g *generator) field(
+	s *Schema, sf reflect.StructField, name string,
+) error {
+	property, err := g.schema(sf.Type)
+	if err != nil {
+		return err
+	}
+
+	property.Description = sf.Tag.Get("usage")
+
+	if text, ok := sf.Tag.Lookup("default"); ok {
+		if property.Default, err = literal(property.Type, text); err != nil {
+			return err
+		}
+	}
+
+	for option, value := range options(sf.Tag.Get(TagName)) {
+		if option == "required" {
+			s.Required = append(s.Required, name)
+		} else if err := constrain(property, option, value); err != nil {
+			return err
+		}
+	}
+
+	s.Properties[name] = property
+
+	return nil
+}
+
+// constrain applies a schema tag option taking a value to s.
+func constrain(s *Schema, option, value string) error {
+	set, ok := constraints[option]
+	if !ok {
+		return fmt.Errorf("%w: unknown option %q", ErrBadTag, option)
+	}
+
+	return set(s, value)
+}
+
+// name returns the member name of sf and whether a tag sets it.
+func (g *generator) name(sf reflect.StructField) (string, bool) {
+	tag, _, _ := strings.Cut(sf.Tag.Get(g.nameTag), ",")
+
+	switch {
+	case tag != "":
+		return tag, true
+	case g.nameTag == "json":
+		return sf.Name, false
+	default:
+		return strings.ToLower(sf.Name), false
+	}
+}
+
+// options yields the options of a schema tag as name and value.
+func options(tag string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for rest := tag; rest != ""; {
+			var part string
+			if strings.HasPrefix(rest, "pattern=") {
+				part, rest = rest, ""
+			} else {
+				part, rest, _ = strings.Cut(rest, ",")
+			}
+
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if !yield(name, value) {
+				return
+			}
+		}
+	}
+}
+
+func setEnum(s *Schema, value string) error {
+	for text := range strings.SplitSeq(value, "|") {
+		v, err := literal(s.Type, text)
+		if err != nil {
+			return err
+		}
+
+		s.Enum = append(s.Enum, v)
+	}
+
+	return nil
+}
+
+func setFormat(s *Schema, value string) error {
+	s.Format = value
+
+	return nil
+}
+
+func setPattern(s *Schema, value string) error {
+	if _, err := compile(value); err != nil {
+		return fmt.Errorf("%w: %w", ErrBadTag, err)
+	}
+
+	s.Pattern = value
+
+	return nil
+}
+
+func setMin(s *Schema, value string) error {
+	return bound(s, value, limits{&s.MinLength, &s.MinItems, &s.Minimum})
+}
+
+func setMax(s *Schema, value string) error {
+	return bound(s, value, limits{&s.MaxLength, &s.MaxItems, &s.Maximum})
+}
+
+// limits are the fields of a bound for each type.
+type limits struct {
+	length, items **int
+	value         **float64
+}
+
+// bound sets the length, item count or value limit of s, depending on its
+// type.
+func bound(s *Schema, value string, to limits) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bound %q", ErrBadTag, value)
+	}
+
+	switch s.Type {
+	case String:
+		*to.length = util.Ptr(int(f))
+	case Array:
+		*to.items = util.Ptr(int(f))
+	case Integer, Number:
+		*to.value = util.Ptr(f)
+	default:
+		return fmt.Errorf("%w: bound on %q", ErrBadTag, s.Type)
+	}
+
+	return nil
+}
+
+// literal parses the text of a default or enum value of type t.
+func literal(t Type, text string) (any, error) {
+	var (
+		value any
+		err   error
+	)
+
+	switch t {
+	case Integer:
+		value, err = strconv.ParseInt(text, 10, 64)
+	case Number:
+		value, err = strconv.ParseFloat(text, 64)
+	case Boolean:
+		value, err = strconv.ParseBool(text)
+	default:
+		value = text
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not %s", ErrBadTag, text, t)
+	}
+
+	return value, nil
+}
+
+func isStruct(t reflect.Type) bool {
+	return indirectType(t).Kind() == reflect.Struct
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,183 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/schema"
+)
+
+type common struct {
+	Debug bool `json:"debug" usage:"verbose logs" default:"false"`
+}
+
+type server struct {
+	Name string         `json:"name" schema:"required,min=1,pattern=^[a-z,]+$"`
+	Port int            `json:"port" default:"80" schema:"min=1,max=65535"`
+	Mode string         `json:"mode,omitempty" schema:"enum=fast|safe"`
+	Addr netip.Addr     `json:"addr"`
+	Tags []string       `json:"tags" schema:"max=4"`
+	Env  map[string]any `json:"env"`
+}
+
+type appConfig struct {
+	common
+
+	Servers []*server     `json:"servers" schema:"required"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	Started time.Time     `json:"started"`
+	Ratio   float32       `json:"ratio" schema:"enum=0.5|1"`
+	Secret  []byte        `json:"secret"`
+	Ignored string        `json:"-"`
+	hidden  string
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	s, err := schema.Generate(&appConfig{hidden: "unused"})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["servers"],
+		"properties": {
+			"debug": {"type": "boolean", "description": "verbose logs",
+				"default": false},
+			"servers": {"type": "array", "items": {
+				"type": "object",
+				"additionalProperties": false,
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string", "minLength": 1,
+						"pattern": "^[a-z,]+$"},
+					"port": {"type": "integer", "default": 80, "minimum": 1,
+						"maximum": 65535},
+					"mode": {"type": "string", "enum": ["fast", "safe"]},
+					"addr": {"type": "string"},
+					"tags": {"type": "array", "items": {"type": "string"},
+						"maxItems": 4},
+					"env": {"type": "object", "additionalProperties": {}}
+				}
+			}},
+			"timeout": {"type": "string", "format": "duration",
+				"default": "5s"},
+			"started": {"type": "string", "format": "date-time"},
+			"ratio": {"type": "number", "enum": [0.5, 1]},
+			"secret": {"type": "string", "format": "byte"}
+		}
+	}`, string(data))
+}
+
+func TestGenerateValidate(t *testing.T) {
+	t.Parallel()
+
+	s, err := schema.Generate(appConfig{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate(parseYAML(t, `
+debug: true
+servers:
+  - {name: web, port: 8080, addr: 10.0.0.1, env: {A: 1}}
+timeout: 1m
+`)))
+
+	err = s.Validate(parseYAML(t, `
+servers:
+  - {name: web, port: 0, mode: slow, tags: [a, b, c, d, e]}
+ratio: 2
+debug: yes please
+`))
+	assert.Equal(t, []string{
+		"debug", "ratio", "servers[0].mode", "servers[0].port",
+		"servers[0].tags",
+	}, mustViolations(t, err).Paths())
+}
+
+func TestGenerateNameTag(t *testing.T) {
+	t.Parallel()
+
+	type settings struct {
+		ListenAddr string `config:"listen"`
+		LogLevel   string
+		Skipped    int `config:"-"`
+	}
+
+	s, err := schema.Generate(settings{}, schema.WithNameTag("config"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"listen", "loglevel"},
+		keys(s.Properties))
+
+	s, err = schema.Generate(settings{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ListenAddr", "LogLevel", "Skipped"},
+		keys(s.Properties))
+}
+
+// keys lists the keys of the properties.
+func keys(properties map[string]*schema.Schema) []string {
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+type node struct {
+	Children []node `json:"children"`
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		v    any
+		want error
+	}{
+		{&struct{ C chan int }{}, schema.ErrUnsupportedType},
+		{struct{ M map[int]string }{}, schema.ErrUnsupportedType},
+		{node{}, schema.ErrUnsupportedType},
+		{struct {
+			A int `schema:"min=x"`
+		}{}, schema.ErrBadTag},
+		{struct {
+			A int `schema:"foo"`
+		}{}, schema.ErrBadTag},
+		{struct {
+			A bool `schema:"max=1"`
+		}{}, schema.ErrBadTag},
+		{struct {
+			A int `default:"x"`
+		}{}, schema.ErrBadTag},
+		{struct {
+			A string `schema:"pattern=("`
+		}{}, schema.ErrBadTag},
+		{struct {
+			A float64 `schema:"enum=1|one"`
+		}{}, schema.ErrBadTag},
+	} {
+		_, err := schema.Generate(tc.v)
+		require.ErrorIs(t, err, tc.want, "%T", tc.v)
+	}
+
+	_, err := schema.Generate(nil)
+	require.ErrorIs(t, err, schema.ErrUnsupportedType)
+
+	s, err := schema.Generate(map[string][]int{})
+	require.NoError(t, err)
+	assert.Equal(t, &schema.Schema{
+		Type: schema.Object,
+		AdditionalProperties: &schema.Schema{
+			Type: schema.Array, Items: &schema.Schema{Type: schema.Integer},
+		},
+	}, s)
+}
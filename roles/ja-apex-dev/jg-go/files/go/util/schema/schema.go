@@ -0,0 +1,173 @@
+// Package schema checks decoded configuration documents, as produced by
+// the YAML and JSON decoders, against a subset of JSON Schema and reports
+// every violation with its path:
+//
+//	s, _ := schema.Generate(Config{})
+//	err := s.Validate(doc) // schema: servers[0].port: must be integer, ...
+//
+// The supported keywords are type, enum, pattern, minLength, maxLength,
+// minimum, maximum, items, minItems, maxItems, properties, required and
+// additionalProperties; description, default and format are kept for
+// documentation only. Schemas encode to and decode from JSON, including
+// the boolean schemas true and false.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidSchema is returned for schemas that cannot be applied,
+	// such as those with a malformed pattern. Such errors abort validation
+	// instead of being reported as violations.
+	ErrInvalidSchema = errors.New("schema: invalid schema")
+	// ErrUnsupportedType is returned by Generate for Go types without a
+	// JSON equivalent, such as channels and recursive structs.
+	ErrUnsupportedType = errors.New("schema: unsupported type")
+	// ErrBadTag is returned by Generate for malformed struct tags.
+	ErrBadTag = errors.New("schema: bad tag")
+)
+
+// Type is the JSON type of a value.
+type Type string
+
+// JSON types. Integer is the subset of Number without a fractional part.
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Integer Type = "integer"
+	Number  Type = "number"
+	String  Type = "string"
+	Array   Type = "array"
+	Object  Type = "object"
+)
+
+// matches reports whether a value of type got is a t.
+func (t Type) matches(got Type) bool {
+	return t == got || t == Number && got == Integer
+}
+
+// Schema describes the values of a document. The zero value accepts
+// anything, like the boolean schema true.
+type Schema struct {
+	// Type restricts values to a JSON type, any if empty.
+	Type Type `json:"type,omitempty"`
+	// Description documents the value.
+	Description string `json:"description,omitempty"`
+	// Default documents the value used when missing.
+	Default any `json:"default,omitempty"`
+	// Format documents the syntax of strings, such as "duration".
+	Format string `json:"format,omitempty"`
+	// Enum lists the allowed values, numbers comparing by value.
+	Enum []any `json:"enum,omitempty"`
+
+	// Pattern is a regular expression strings must contain a match of.
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Items is the schema of every array item.
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// Properties holds the schemas of the known object members.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Required lists the members objects must have.
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is the schema of the other members, which are
+	// allowed when nil.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+
+	// Never makes the schema reject every value, like the boolean schema
+	// false.
+	Never bool `json:"-"`
+}
+
+// never is the schema rejecting every value.
+func never() *Schema {
+	return &Schema{Never: true}
+}
+
+// plain has the fields of Schema without its methods.
+type plain Schema
+
+// MarshalJSON encodes schemas rejecting everything as false.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	if s.Never {
+		return []byte("false"), nil
+	}
+
+	return json.Marshal(plain(s))
+}
+
+// UnmarshalJSON decodes a schema object or a boolean schema.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var accept bool
+	if err := json.Unmarshal(data, &accept); err == nil {
+		*s = Schema{Never: !accept}
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// Violation describes a value failing its schema.
+type Violation struct {
+	// Path locates the value, such as "servers[0].port". The whole
+	// document has the empty path.
+	Path string `json:"path"`
+	// Keyword is the failing schema keyword, such as "required".
+	Keyword string `json:"keyword"`
+	// Message is a human-readable description.
+	Message string `json:"message"`
+}
+
+// Violations is the error returned when a document fails its schema.
+type Violations []Violation
+
+// Error lists every violation.
+func (v Violations) Error() string {
+	parts := make([]string, len(v))
+	for i, violation := range v {
+		path := violation.Path
+		if path == "" {
+			path = "(root)"
+		}
+
+		parts[i] = path + ": " + violation.Message
+	}
+
+	return "schema: " + strings.Join(parts, "; ")
+}
+
+// Paths returns the paths of the failing values.
+func (v Violations) Paths() []string {
+	paths := make([]string, len(v))
+	for i, violation := range v {
+		paths[i] = violation.Path
+	}
+
+	return paths
+}
+
+// member returns the path of the member key of the object at path.
+func member(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// item returns the path of the item i of the array at path.
+func item(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
@@ -0,0 +1,187 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/schema"
+)
+
+// serverSchema describes a list of servers, in the JSON syntax.
+const serverSchema = `{
+	"type": "object",
+	"required": ["servers"],
+	"additionalProperties": false,
+	"properties": {
+		"servers": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["name", "port"],
+				"properties": {
+					"name": {"type": "string", "pattern": "^[a-z]+$",
+						"maxLength": 8},
+					"port": {"type": "integer", "minimum": 1,
+						"maximum": 65535},
+					"mode": {"enum": ["fast", "safe", 1]},
+					"labels": {"type": "object",
+						"additionalProperties": {"type": "string"}}
+				}
+			}
+		}
+	}
+}`
+
+// parseSchema decodes a schema from JSON.
+func parseSchema(t *testing.T, data string) *schema.Schema {
+	t.Helper()
+
+	var s schema.Schema
+	require.NoError(t, json.Unmarshal([]byte(data), &s))
+
+	return &s
+}
+
+// parseYAML decodes a YAML document.
+func parseYAML(t *testing.T, doc string) any {
+	t.Helper()
+
+	var value any
+	require.NoError(t, yaml.Unmarshal([]byte(doc), &value))
+
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	s := parseSchema(t, serverSchema)
+
+	require.NoError(t, s.Validate(parseYAML(t, `
+servers:
+  - {name: web, port: 80, mode: fast, labels: {tier: front}}
+  - {name: db, port: 5432.0, mode: 1}
+`)))
+
+	err := s.Validate(parseYAML(t, `
+servers:
+  - {name: Web, port: 0, mode: slow}
+  - {port: "80", labels: {tier: 1}}
+  - {name: toolongname, port: 1.5}
+extra: true
+`))
+
+	var violations schema.Violations
+	require.ErrorAs(t, err, &violations)
+	assert.Equal(t, schema.Violations{
+		{"extra", "additionalProperties", "is not allowed"},
+		{"servers[0].mode", "enum", `must be one of "fast", "safe", 1`},
+		{"servers[0].name", "pattern", "must match ^[a-z]+$"},
+		{"servers[0].port", "minimum", "must be at least 1"},
+		{"servers[1].name", "required", "is required"},
+		{"servers[1].labels.tier", "type", "must be string, got integer"},
+		{"servers[1].port", "type", "must be integer, got string"},
+		{"servers[2].name", "maxLength", "must be at most 8 characters long"},
+		{"servers[2].port", "type", "must be integer, got number"},
+	}, violations)
+	assert.Contains(t, err.Error(), "schema: extra: is not allowed; ")
+
+	err = s.Validate(map[string]any{"servers": []any{}})
+	assert.Equal(t, schema.Violations{
+		{"servers", "minItems", "must have at least 1 items"},
+	}, mustViolations(t, err))
+
+	err = s.Validate([]string{"servers"})
+	require.EqualError(t, err, "schema: (root): must be object, got array")
+}
+
+func TestValidateGoValues(t *testing.T) {
+	t.Parallel()
+
+	s := parseSchema(t, `{"type": "object", "properties": {
+		"count": {"type": "integer", "maximum": 3, "enum": [1, 2.0, 5]},
+		"ratio": {"type": "number"},
+		"tags": {"type": "array", "maxItems": 1, "items": {"type": "string"}},
+		"name": {"type": "string", "minLength": 2},
+		"on": {"type": "boolean"}
+	}}`)
+
+	type label string
+
+	require.NoError(t, s.Validate(map[string]any{
+		"count": uint8(2), "ratio": json.Number("0.5"), "tags": []label{"a"},
+		"name": util.Ptr("é€"), "on": true,
+	}))
+
+	err := s.Validate(map[string]string{"count": "1", "name": "é"})
+	assert.Equal(t, []string{"count", "name"},
+		mustViolations(t, err).Paths())
+
+	err = s.Validate(map[string]any{
+		"count": 5, "tags": []string{"a", "b"}, "on": nil,
+	})
+	assert.Equal(t, schema.Violations{
+		{"count", "maximum", "must be at most 3"},
+		{"on", "type", "must be boolean, got null"},
+		{"tags", "maxItems", "must have at most 1 items"},
+	}, mustViolations(t, err))
+
+	// Numbers compare exactly, beyond the precision of float64.
+	big := &schema.Schema{Enum: []any{int64(1 << 53)}}
+	require.NoError(t, big.Validate(json.Number("9007199254740992")))
+	require.Error(t, big.Validate(int64(1<<53+1)))
+}
+
+// mustViolations returns the violations of err.
+func mustViolations(t *testing.T, err error) schema.Violations {
+	t.Helper()
+
+	var violations schema.Violations
+	require.ErrorAs(t, err, &violations)
+
+	return violations
+}
+
+func TestValidateInvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	s := parseSchema(t, `{"items": {"pattern": "("}}`)
+
+	err := s.Validate([]any{"x"})
+	require.ErrorIs(t, err, schema.ErrInvalidSchema)
+	assert.Contains(t, err.Error(), "(path [0])")
+
+	require.NoError(t, s.Validate([]any{1}), "patterns only apply to strings")
+}
+
+func TestSchemaJSON(t *testing.T) {
+	t.Parallel()
+
+	s := parseSchema(t, `{"type": "object",
+		"properties": {"a": true, "b": false}, "additionalProperties": false}`)
+
+	assert.Equal(t, &schema.Schema{
+		Type: schema.Object,
+		Properties: map[string]*schema.Schema{
+			"a": {}, "b": {Never: true},
+		},
+		AdditionalProperties: &schema.Schema{Never: true},
+	}, s)
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object", "properties": {"a": {}, "b": false},
+		"additionalProperties": false}`, string(data))
+
+	err = s.Validate(map[string]any{"a": 1, "b": 2, "c": 3})
+	assert.Equal(t, schema.Violations{
+		{"b", "false", "is not allowed"},
+		{"c", "additionalProperties", "is not allowed"},
+	}, mustViolations(t, err))
+}
@@ -0,0 +1,334 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"example.com/go-template/util"
+)
+
+// patterns caches the compiled regular expressions by source.
+var patterns sync.Map
+
+// Validate checks doc, a tree of maps, slices and scalars, against the
+// schema. It returns Violations when values fail their schemas.
+func (s *Schema) Validate(doc any) error {
+	v := &validator{}
+	if err := v.validate(s, doc, ""); err != nil {
+		return err
+	}
+
+	if len(v.violations) > 0 {
+		return v.violations
+	}
+
+	return nil
+}
+
+// validator accumulates the violations of a single Validate call.
+type validator struct {
+	violations Violations
+}
+
+// add records a violation of the value at path.
+func (v *validator) add(path, keyword, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate checks value, found at path, against s.
+func (v *validator) validate(s *Schema, value any, path string) error {
+	got := typeOf(value)
+
+	switch {
+	case s.Never:
+		v.add(path, "false", "is not allowed")
+
+		return nil
+	case s.Type != "" && !s.Type.matches(got):
+		v.add(path, "type", "must be %s, got %s", s.Type, describe(value))
+
+		return nil
+	case len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool {
+		return equal(e, value)
+	}):
+		v.add(path, "enum", "must be one of %s", list(s.Enum))
+	}
+
+	return v.validateType(s, got, value, path)
+}
+
+// validateType applies the keywords of the type got of value.
+func (v *validator) validateType(
+	s *Schema, got Type, value any, path string,
+) error {
+	switch got {
+	case String:
+		str, _ := indirect(value).(string)
+
+		return v.validateString(s, str, path)
+	case Integer, Number:
+		n, _ := util.Number(value)
+		f, _ := n.Float64()
+		v.validateNumber(s, f, path)
+	case Array:
+		return v.validateArray(s, array(value), path)
+	case Object:
+		return v.validateObject(s, object(value), path)
+	default:
+	}
+
+	return nil
+}
+
+// validateString applies the length and pattern keywords.
+func (v *validator) validateString(s *Schema, str, path string) error {
+	if n := utf8.RuneCountInString(str); s.MinLength != nil &&
+		n < *s.MinLength {
+		v.add(path, "minLength", "must be at least %d characters long",
+			*s.MinLength)
+	} else if s.MaxLength != nil && n > *s.MaxLength {
+		v.add(path, "maxLength", "must be at most %d characters long",
+			*s.MaxLength)
+	}
+
+	if s.Pattern == "" {
+		return nil
+	}
+
+	re, err := compile(s.Pattern)
+	if err != nil {
+		return fmt.Errorf("%w (path %s)", err, path)
+	}
+
+	if !re.MatchString(str) {
+		v.add(path, "pattern", "must match %s", s.Pattern)
+	}
+
+	return nil
+}
+
+// validateNumber applies the minimum and maximum keywords.
+func (v *validator) validateNumber(s *Schema, n float64, path string) {
+	switch {
+	case s.Minimum != nil && n < *s.Minimum:
+		v.add(path, "minimum", "must be at least %g", *s.Minimum)
+	case s.Maximum != nil && n > *s.Maximum:
+		v.add(path, "maximum", "must be at most %g", *s.Maximum)
+	}
+}
+
+// validateArray applies the item count keywords and the item schema.
+func (v *validator) validateArray(s *Schema, items []any, path string) error {
+	switch {
+	case s.MinItems != nil && len(items) < *s.MinItems:
+		v.add(path, "minItems", "must have at least %d items", *s.MinItems)
+	case s.MaxItems != nil && len(items) > *s.MaxItems:
+		v.add(path, "maxItems", "must have at most %d items", *s.MaxItems)
+	}
+
+	if s.Items == nil {
+		return nil
+	}
+
+	for i, value := range items {
+		if err := v.validate(s.Items, value, item(path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateObject checks the members in key order for stable reports.
+func (v *validator) validateObject(
+	s *Schema, members map[string]any, path string,
+) error {
+	for _, key := range s.Required {
+		if _, ok := members[key]; !ok {
+			v.add(member(path, key), "required", "is required")
+		}
+	}
+
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		err := v.validateMember(s, members[key], member(path, key), key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateMember checks the member key against its property schema, or
+// else the additional properties one.
+func (v *validator) validateMember(
+	s *Schema, value any, path, key string,
+) error {
+	sub, ok := s.Properties[key]
+
+	switch {
+	case ok:
+		return v.validate(sub, value, path)
+	case s.AdditionalProperties == nil:
+		return nil
+	case s.AdditionalProperties.Never:
+		v.add(path, "additionalProperties", "is not allowed")
+
+		return nil
+	default:
+		return v.validate(s.AdditionalProperties, value, path)
+	}
+}
+
+// compile returns the cached regular expression of pattern.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(pattern); ok {
+		re, _ := cached.(*regexp.Regexp)
+
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	patterns.Store(pattern, re)
+
+	return re, nil
+}
+
+// kinds maps the Go kinds of scalars to their JSON type.
+var kinds = map[reflect.Kind]Type{
+	reflect.Bool:   Boolean,
+	reflect.String: String,
+	reflect.Slice:  Array,
+	reflect.Array:  Array,
+}
+
+// typeOf returns the JSON type of value, or "" for values without one.
+func typeOf(value any) Type {
+	if n, ok := util.Number(value); ok {
+		if n.IsInt() {
+			return Integer
+		}
+
+		return Number
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch {
+	case !v.IsValid(), v.Kind() == reflect.Pointer && v.IsNil():
+		return Null
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		return Object
+	default:
+		return kinds[v.Kind()]
+	}
+}
+
+// describe names the JSON type of value for messages.
+func describe(value any) string {
+	if got := typeOf(value); got != "" {
+		return string(got)
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// indirect dereferences pointers and converts named scalar types to their
+// underlying type.
+func indirect(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Invalid, reflect.Pointer:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+// array returns the items of a slice or array value.
+func array(value any) []any {
+	if items, ok := value.([]any); ok {
+		return items
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(value))
+
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+
+	return items
+}
+
+// object returns the members of a map value with string keys.
+func object(value any) map[string]any {
+	if members, ok := value.(map[string]any); ok {
+		return members
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(value))
+
+	members := make(map[string]any, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		members[iter.Key().String()] = iter.Value().Interface()
+	}
+
+	return members
+}
+
+// equal compares an enum value to a document value, numbers by value.
+func equal(want, value any) bool {
+	if x, ok := util.Number(want); ok {
+		y, ok := util.Number(value)
+
+		return ok && x.Cmp(y) == 0
+	}
+
+	return reflect.DeepEqual(indirect(want), indirect(value))
+}
+
+// list renders the enum values as JSON.
+func list(values []any) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			data = fmt.Append(nil, value)
+		}
+
+		parts[i] = string(data)
+	}
+
+	return strings.Join(parts, ", ")
+}
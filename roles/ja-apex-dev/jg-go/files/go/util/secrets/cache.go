@@ -0,0 +1,172 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"example.com/go-template/util/cache"
+	"example.com/go-template/util/clock"
+)
+
+// Hooks observe a Cache. They run outside of its lock.
+type Hooks struct {
+	// OnRotate is called when a secret loaded again differs from the
+	// previous value, so that clients and connection pools can be rebuilt.
+	OnRotate func(name, value string)
+	// OnError reports failed refreshes made by Watch; the previous value
+	// stays cached.
+	OnError func(name string, err error)
+}
+
+// Option configures NewCache.
+type Option func(*settings)
+
+type settings struct {
+	ttl   time.Duration
+	clock clock.Clock
+	hooks Hooks
+}
+
+// WithTTL reloads secrets older than ttl on their next lookup. Zero, the
+// default, keeps them until Refresh.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *settings) {
+		s.ttl = ttl
+	}
+}
+
+// WithClock sets the clock used for expiry and by Watch.
+func WithClock(c clock.Clock) Option {
+	return func(s *settings) {
+		s.clock = c
+	}
+}
+
+// WithHooks sets the rotation and error hooks.
+func WithHooks(hooks Hooks) Option {
+	return func(s *settings) {
+		s.hooks = hooks
+	}
+}
+
+// Cache is a Provider remembering the secrets of another one. Lookups of
+// the same missing secret share a single call to the underlying provider
+// and errors are not cached.
+type Cache struct {
+	provider Provider
+	entries  *cache.Cache[string, string]
+	clock    clock.Clock
+	hooks    Hooks
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewCache wraps provider.
+func NewCache(provider Provider, opts ...Option) *Cache {
+	s := settings{}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	c := clock.OrReal(s.clock)
+
+	return &Cache{
+		provider: provider,
+		entries: cache.New[string, string](
+			cache.WithTTL(s.ttl), cache.WithClock(c)),
+		clock:  c,
+		hooks:  s.hooks,
+		values: make(map[string]string),
+	}
+}
+
+// Lookup implements Provider.
+func (c *Cache) Lookup(ctx context.Context, name string) (string, error) {
+	value, err := c.entries.GetOrLoad(ctx, name,
+		func(ctx context.Context) (string, error) {
+			return c.load(ctx, name)
+		})
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s: %w", name, err)
+	}
+
+	return value, nil
+}
+
+// Refresh loads every secret looked up so far again, calling OnRotate for
+// the changed ones. Secrets that fail to load keep their previous value;
+// the errors are returned joined.
+func (c *Cache) Refresh(ctx context.Context) error {
+	var failures []error
+
+	c.reload(ctx, func(name string, err error) {
+		failures = append(failures, fmt.Errorf("secrets: %s: %w", name, err))
+	})
+
+	return errors.Join(failures...)
+}
+
+// Watch refreshes the secrets every interval until ctx is done, reporting
+// failures to OnError, and returns the cause of ctx.
+func (c *Cache) Watch(ctx context.Context, interval time.Duration) error {
+	fail := c.hooks.OnError
+	if fail == nil {
+		fail = func(string, error) {}
+	}
+
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-ticker.C():
+			c.reload(ctx, fail)
+		}
+	}
+}
+
+// reload reads the known secrets, passing failures to fail.
+func (c *Cache) reload(ctx context.Context, fail func(string, error)) {
+	c.mu.Lock()
+	names := slices.Sorted(maps.Keys(c.values))
+	c.mu.Unlock()
+
+	for _, name := range names {
+		value, err := c.load(ctx, name)
+		if err != nil {
+			fail(name, err)
+
+			continue
+		}
+
+		c.entries.Set(name, value)
+	}
+}
+
+// load reads name from the provider and records its value, calling
+// OnRotate when it changed.
+func (c *Cache) load(ctx context.Context, name string) (string, error) {
+	value, err := c.provider.Lookup(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	previous, seen := c.values[name]
+	c.values[name] = value
+	c.mu.Unlock()
+
+	if seen && previous != value && c.hooks.OnRotate != nil {
+		c.hooks.OnRotate(name, value)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,167 @@
+package secrets_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/clock"
+	"example.com/go-template/util/secrets"
+	"example.com/go-template/util/testx"
+)
+
+// vault is a provider whose values change under the test.
+type vault struct {
+	mu     sync.Mutex
+	values map[string]string
+	calls  atomic.Int64
+}
+
+// Lookup implements secrets.Provider.
+func (v *vault) Lookup(_ context.Context, name string) (string, error) {
+	v.calls.Add(1)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	value, ok := v.values[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (v *vault) set(name, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if value == "" {
+		delete(v.values, name)
+	} else {
+		v.values[name] = value
+	}
+}
+
+// rotations records the OnRotate calls.
+type rotations struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (r *rotations) add(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names = append(r.names, name+"="+value)
+}
+
+func (r *rotations) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.names...)
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	source := &vault{values: map[string]string{"token": "v1"}}
+	rotated := &rotations{}
+	c := secrets.NewCache(source, secrets.WithTTL(time.Minute),
+		secrets.WithClock(fake),
+		secrets.WithHooks(secrets.Hooks{OnRotate: rotated.add}))
+
+	for range 3 {
+		value, err := c.Lookup(t.Context(), "token")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value)
+	}
+
+	assert.Equal(t, int64(1), source.calls.Load())
+
+	source.set("token", "v2")
+	fake.Advance(time.Minute)
+
+	value, err := c.Lookup(t.Context(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+	assert.Equal(t, []string{"token=v2"}, rotated.get())
+
+	_, err = c.Lookup(t.Context(), "missing")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+func TestCacheRefresh(t *testing.T) {
+	t.Parallel()
+
+	source := &vault{values: map[string]string{"a": "1", "b": "1"}}
+	c := secrets.NewCache(source)
+
+	for _, name := range []string{"a", "b"} {
+		_, err := c.Lookup(t.Context(), name)
+		require.NoError(t, err)
+	}
+
+	source.set("a", "2")
+	source.set("b", "")
+
+	err := c.Refresh(t.Context())
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+	require.ErrorContains(t, err, "secrets: b:")
+
+	value, err := c.Lookup(t.Context(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, "2", value)
+
+	value, err = c.Lookup(t.Context(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, "1", value, "failed refreshes keep the previous value")
+}
+
+func TestCacheWatch(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	source := &vault{values: map[string]string{"token": "v1"}}
+	rotated := &rotations{}
+
+	var failures atomic.Int64
+
+	c := secrets.NewCache(source, secrets.WithClock(fake),
+		secrets.WithHooks(secrets.Hooks{
+			OnRotate: rotated.add,
+			OnError:  func(string, error) { failures.Add(1) },
+		}))
+
+	_, err := c.Lookup(t.Context(), "token")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- c.Watch(ctx, time.Hour) }()
+
+	require.NoError(t, fake.BlockUntil(t.Context(), 1))
+	source.set("token", "v2")
+	fake.Advance(time.Hour)
+	testx.RequireEventually(t, func() bool {
+		return len(rotated.get()) == 1
+	}, time.Second)
+
+	source.set("token", "")
+	fake.Advance(time.Hour)
+	testx.RequireEventually(t, func() bool {
+		return failures.Load() == 1
+	}, time.Second)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, []string{"token=v2"}, rotated.get())
+}
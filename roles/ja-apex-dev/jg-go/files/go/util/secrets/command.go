@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"example.com/go-template/util/execx"
+)
+
+// Placeholder is replaced by the secret name in the arguments of Command.
+const Placeholder = "{name}"
+
+// Command returns a provider printing secrets with an external tool, such
+// as Command("pass", "show", Placeholder) for the GnuPG password store.
+// A non-zero exit status is reported as ErrNotFound, since that is how
+// these tools say the entry is missing; the trailing line break of the
+// output is dropped.
+func Command(argv ...string) Provider {
+	return ProviderFunc(func(ctx context.Context, name string) (string, error) {
+		args := slices.Clone(argv[1:])
+		for i, arg := range args {
+			if arg == Placeholder {
+				args[i] = name
+			}
+		}
+
+		result, err := execx.Run(ctx, argv[0], args...)
+
+		var exitErr *execx.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%w: %s: %w", ErrNotFound, name, err)
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("secrets: %w", err)
+		}
+
+		return trimValue(result.Stdout), nil
+	})
+}
+
+// Keyring returns a provider reading the login keyring of the desktop
+// session, GNOME Keyring or KWallet, through the Secret Service tool
+// secret-tool. Secrets are stored with the attributes service and account,
+// for instance with "secret-tool store --label=token service app account
+// token". SSH agents only sign data and never hand out stored secrets, so
+// they cannot back a provider.
+func Keyring(service string) Provider {
+	return Command("secret-tool", "lookup",
+		"service", service, "account", Placeholder)
+}
@@ -0,0 +1,28 @@
+package secrets_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/secrets"
+)
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	provider := secrets.Command("sh", "-c",
+		`test "$0" = token && echo s3cr3t`, secrets.Placeholder)
+
+	value, err := provider.Lookup(t.Context(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	_, err = provider.Lookup(t.Context(), "other")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	_, err = secrets.Command("/nonexistent/tool").Lookup(t.Context(), "x")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, secrets.ErrNotFound)
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"example.com/go-template/util"
+)
+
+// FileSuffix marks variables holding the path of a file with the secret,
+// following the convention of the official Docker images.
+const FileSuffix = "_FILE"
+
+// Env returns a provider reading environment variables. The name is
+// converted to upper snake case and prefixed with prefix and an underscore
+// unless prefix is empty, so that "db-password" with prefix "APP" reads
+// APP_DB_PASSWORD. When that variable is unset, the file named by
+// APP_DB_PASSWORD_FILE is read instead.
+func Env(prefix string) Provider {
+	return ProviderFunc(func(_ context.Context, name string) (string, error) {
+		key := envKey(prefix, name)
+
+		if value, ok := os.LookupEnv(key); ok {
+			return value, nil
+		}
+
+		path, ok := os.LookupEnv(key + FileSuffix)
+		if !ok {
+			return "", notFound(name)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secrets: %s%s: %w", key, FileSuffix, err)
+		}
+
+		return trimValue(string(data)), nil
+	})
+}
+
+func envKey(prefix, name string) string {
+	key := strings.ToUpper(util.ToSnakeCase(name))
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "_" + key
+}
@@ -0,0 +1,38 @@
+package secrets_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/secrets"
+	"example.com/go-template/util/testx"
+)
+
+//nolint:paralleltest // Modifies the process environment.
+func TestEnv(t *testing.T) {
+	dir := testx.TempDirWithFiles(t, map[string]string{"pw": "hunter2\n"})
+
+	t.Setenv("APP_API_TOKEN", "t0ken")
+	t.Setenv("APP_DB_PASSWORD_FILE", filepath.Join(dir, "pw"))
+	t.Setenv("APP_BROKEN_FILE", filepath.Join(dir, "missing"))
+
+	provider := secrets.Env("APP")
+
+	value, err := provider.Lookup(t.Context(), "api-token")
+	require.NoError(t, err)
+	assert.Equal(t, "t0ken", value)
+
+	value, err = provider.Lookup(t.Context(), "dbPassword")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	_, err = provider.Lookup(t.Context(), "unset")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	_, err = provider.Lookup(t.Context(), "broken")
+	require.ErrorContains(t, err, "APP_BROKEN_FILE")
+	require.NotErrorIs(t, err, secrets.ErrNotFound)
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DockerDir is where Docker and Compose mount secrets. Kubernetes mounts
+// them wherever the volume of the pod says.
+const DockerDir = "/run/secrets"
+
+// Dir returns a provider reading secrets from the files of dir, one file
+// per secret named after it, as mounted by Docker and Kubernetes. The
+// trailing line break of the files is dropped.
+func Dir(dir string) Provider {
+	return ProviderFunc(func(_ context.Context, name string) (string, error) {
+		if !filepath.IsLocal(name) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", notFound(name)
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("secrets: %w", err)
+		}
+
+		return trimValue(string(data)), nil
+	})
+}
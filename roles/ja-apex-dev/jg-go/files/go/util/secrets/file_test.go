@@ -0,0 +1,34 @@
+package secrets_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/secrets"
+	"example.com/go-template/util/testx"
+)
+
+func TestDir(t *testing.T) {
+	t.Parallel()
+
+	provider := secrets.Dir(testx.TempDirWithFiles(t, map[string]string{
+		"db-password":  "hunter2\r\n",
+		"tls/cert.pem": "CERT",
+	}))
+
+	value, err := provider.Lookup(t.Context(), "db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	value, err = provider.Lookup(t.Context(), "tls/cert.pem")
+	require.NoError(t, err)
+	assert.Equal(t, "CERT", value)
+
+	_, err = provider.Lookup(t.Context(), "missing")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	_, err = provider.Lookup(t.Context(), "../etc/passwd")
+	require.ErrorIs(t, err, secrets.ErrInvalidName)
+}
@@ -0,0 +1,73 @@
+// Package secrets reads credentials such as API tokens and passwords from
+// environment variables, mounted secret files or the user keyring behind a
+// single Provider interface, with caching and rotation callbacks.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when a provider does not hold the secret.
+	ErrNotFound = errors.New("secrets: not found")
+	// ErrInvalidName is returned for names a provider cannot represent,
+	// such as paths escaping the secrets directory.
+	ErrInvalidName = errors.New("secrets: invalid name")
+)
+
+// Provider resolves secrets by name.
+type Provider interface {
+	// Lookup returns the value of the named secret, or an error wrapping
+	// ErrNotFound when the provider does not hold it.
+	Lookup(ctx context.Context, name string) (string, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Lookup implements Provider.
+func (f ProviderFunc) Lookup(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// Chain returns a provider trying providers in order, moving on to the
+// next one only when a secret is not found, so that for instance
+// environment variables override mounted files.
+func Chain(providers ...Provider) Provider {
+	return ProviderFunc(func(ctx context.Context, name string) (string, error) {
+		for _, p := range providers {
+			value, err := p.Lookup(ctx, name)
+			if !errors.Is(err, ErrNotFound) {
+				return value, err
+			}
+		}
+
+		return "", notFound(name)
+	})
+}
+
+// Static returns a provider serving the given values, mostly useful in
+// tests and for local development.
+func Static(values map[string]string) Provider {
+	return ProviderFunc(func(_ context.Context, name string) (string, error) {
+		value, ok := values[name]
+		if !ok {
+			return "", notFound(name)
+		}
+
+		return value, nil
+	})
+}
+
+func notFound(name string) error {
+	return fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// trimValue drops the trailing line break that editors and "echo" leave in
+// secret files and command output.
+func trimValue(value string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")
+}
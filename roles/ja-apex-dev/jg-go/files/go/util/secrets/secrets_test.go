@@ -0,0 +1,49 @@
+package secrets_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/secrets"
+)
+
+var errVault = errors.New("vault sealed")
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	chain := secrets.Chain(
+		secrets.Static(map[string]string{"token": "override"}),
+		secrets.Static(map[string]string{"token": "base", "user": "app"}),
+	)
+
+	value, err := chain.Lookup(t.Context(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "override", value)
+
+	value, err = chain.Lookup(t.Context(), "user")
+	require.NoError(t, err)
+	assert.Equal(t, "app", value)
+
+	_, err = chain.Lookup(t.Context(), "missing")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+func TestChainStopsOnFailure(t *testing.T) {
+	t.Parallel()
+
+	failing := secrets.ProviderFunc(
+		func(context.Context, string) (string, error) {
+			return "", errVault
+		})
+
+	chain := secrets.Chain(failing,
+		secrets.Static(map[string]string{"token": "base"}))
+
+	_, err := chain.Lookup(t.Context(), "token")
+	require.ErrorIs(t, err, errVault)
+}
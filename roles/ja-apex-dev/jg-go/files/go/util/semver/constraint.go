@@ -0,0 +1,300 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidConstraint is returned for malformed constraint expressions.
+var ErrInvalidConstraint = errors.New("semver: invalid constraint")
+
+// Constraint is a parsed constraint expression. Alternatives are separated
+// by "||" and every alternative is a list of terms separated by spaces or
+// commas, all of which must hold:
+//
+//	1.2.3, =1.2.3  exactly 1.2.3
+//	1.2, 1.2.x     >=1.2.0 <1.3.0
+//	*, x           any release
+//	!=1.2.3        anything but 1.2.3
+//	>1.2, >=1.2    >=1.3.0 and >=1.2.0, likewise for < and <=
+//	~1.2.3         >=1.2.3 <1.3.0; ~1 is >=1.0.0 <2.0.0
+//	^1.2.3         >=1.2.3 <2.0.0; ^0.2.3 is >=0.2.3 <0.3.0
+//
+// As with npm, prerelease versions only satisfy an alternative that
+// mentions a prerelease of the same MAJOR.MINOR.PATCH, so ">=1.0" rejects
+// 2.0.0-rc.1.
+type Constraint struct {
+	text   string
+	groups [][]comparator
+}
+
+type comparator struct {
+	// op is one of =, !=, >, >=, < and <=.
+	op      string
+	version Version
+}
+
+// ParseConstraint parses a constraint expression.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{text: strings.TrimSpace(s)}
+
+	for alternative := range strings.SplitSeq(s, "||") {
+		group, err := parseGroup(alternative)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("%w %q: %w",
+				ErrInvalidConstraint, s, err)
+		}
+
+		c.groups = append(c.groups, group)
+	}
+
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics on invalid input.
+func MustParseConstraint(s string) Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// Satisfies reports whether the version string satisfies the constraint
+// expression. Use Coerce first for the free-form output of tools.
+func Satisfies(version, constraint string) (bool, error) {
+	v, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
+
+// Check reports whether v satisfies the constraint.
+func (c Constraint) Check(v Version) bool {
+	for _, group := range c.groups {
+		if groupHolds(group, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the expression the constraint was parsed from.
+func (c Constraint) String() string {
+	return c.text
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Constraint) MarshalText() ([]byte, error) {
+	return []byte(c.text), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Constraint) UnmarshalText(text []byte) error {
+	parsed, err := ParseConstraint(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+
+	return nil
+}
+
+func groupHolds(group []comparator, v Version) bool {
+	for _, cmp := range group {
+		if !cmp.holds(v) {
+			return false
+		}
+	}
+
+	if v.Prerelease == "" {
+		return true
+	}
+
+	for _, cmp := range group {
+		if cmp.version.Prerelease != "" && sameRelease(cmp.version, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameRelease(a, b Version) bool {
+	return a.Major == b.Major && a.Minor == b.Minor && a.Patch == b.Patch
+}
+
+func (c comparator) holds(v Version) bool {
+	order := Compare(v, c.version)
+
+	switch c.op {
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	default:
+		return order == 0
+	}
+}
+
+var (
+	errEmpty    = errors.New("empty alternative")
+	errOperator = errors.New("dangling operator")
+	errWildcard = errors.New("operator cannot take a wildcard")
+	errPartial  = errors.New("!= needs a full version")
+)
+
+// operators lists the term prefixes, longest first.
+var operators = []string{">=", "<=", "!=", ">", "<", "=", "^", "~"}
+
+// parseGroup parses the terms of one alternative.
+func parseGroup(text string) ([]comparator, error) {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(tokens) == 0 {
+		return nil, errEmpty
+	}
+
+	group := []comparator{}
+
+	for i := 0; i < len(tokens); i++ {
+		term := tokens[i]
+
+		// Allow a space between an operator and its version.
+		if slices.Contains(operators, term) {
+			if i++; i == len(tokens) {
+				return nil, errOperator
+			}
+
+			term += tokens[i]
+		}
+
+		expanded, err := parseTerm(term)
+		if err != nil {
+			return nil, err
+		}
+
+		group = append(group, expanded...)
+	}
+
+	return group, nil
+}
+
+// parseTerm expands a single term into primitive comparators.
+func parseTerm(term string) ([]comparator, error) {
+	op := ""
+
+	for _, candidate := range operators {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+
+			break
+		}
+	}
+
+	v, parts, err := parsePartial(term[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	if parts == 0 {
+		return expandWildcard(op)
+	}
+
+	switch op {
+	case "^":
+		return span(v, caretIndex(v, parts)), nil
+	case "~":
+		return span(v, min(parts-1, 1)), nil
+	case "", "=":
+		if parts == fullParts {
+			return []comparator{{op: "=", version: v}}, nil
+		}
+
+		return span(v, parts-1), nil
+	default:
+		return expandComparison(op, v, parts)
+	}
+}
+
+// expandComparison handles the relational operators on partial versions.
+func expandComparison(op string, v Version, parts int) ([]comparator, error) {
+	if parts == fullParts {
+		return []comparator{{op: op, version: v}}, nil
+	}
+
+	switch op {
+	case "!=":
+		return nil, errPartial
+	case ">":
+		return []comparator{{op: ">=", version: bump(v, parts-1)}}, nil
+	case "<=":
+		return []comparator{{op: "<", version: bump(v, parts-1)}}, nil
+	default:
+		return []comparator{{op: op, version: v}}, nil
+	}
+}
+
+// expandWildcard handles terms such as "*" and ">=x", which match every
+// release.
+func expandWildcard(op string) ([]comparator, error) {
+	switch op {
+	case "", "=", ">=", "<=", "^", "~":
+		return nil, nil
+	default:
+		return nil, errWildcard
+	}
+}
+
+// caretIndex returns the component bumped by "^": the first non-zero one
+// given, or the last one given if they are all zero.
+func caretIndex(v Version, parts int) int {
+	numbers := [fullParts]uint64{v.Major, v.Minor, v.Patch}
+	for i, n := range numbers[:parts] {
+		if n != 0 {
+			return i
+		}
+	}
+
+	return parts - 1
+}
+
+// span returns the range from v up to v with component index bumped.
+func span(v Version, index int) []comparator {
+	return []comparator{
+		{op: ">=", version: v},
+		{op: "<", version: bump(v, index)},
+	}
+}
+
+// bump increments component index of v and zeroes the following ones.
+func bump(v Version, index int) Version {
+	switch index {
+	case 0:
+		return Version{Major: v.Major + 1}
+	case 1:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
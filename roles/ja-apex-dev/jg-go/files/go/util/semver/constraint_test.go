@@ -0,0 +1,99 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/semver"
+)
+
+func TestConstraint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		match      []string
+		reject     []string
+	}{
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.9"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, []string{"1.0.0-rc.1"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{">=1.4 <2.0", []string{"1.4.0", "1.9.9"}, []string{"1.3.9", "2.0.0"}},
+		{">= 1.4, < 2", []string{"1.5.0"}, []string{"2.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.9.0"}, []string{"2.0.0"}},
+		{"^1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0", "1.1.0"}},
+		{"^0.2.3", []string{"0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0.0", []string{"0.0.9"}, []string{"0.1.0"}},
+		{"1.x || >=3", []string{"1.5.0", "3.1.0"}, []string{"2.0.0"}},
+		{">=1.0", []string{"1.0.0"}, []string{"2.0.0-rc.1"}},
+		{
+			">=2.0.0-rc.1",
+			[]string{"2.0.0-rc.2", "2.0.0", "2.1.0"},
+			[]string{"2.0.0-beta", "2.1.0-rc.1"},
+		},
+	}
+
+	for _, test := range tests {
+		c, err := semver.ParseConstraint(test.constraint)
+		require.NoError(t, err, test.constraint)
+		assert.Equal(t, test.constraint, c.String())
+
+		for _, v := range test.match {
+			assert.True(t, c.Check(semver.MustParse(v)), "%s %s",
+				test.constraint, v)
+		}
+
+		for _, v := range test.reject {
+			assert.False(t, c.Check(semver.MustParse(v)), "%s %s",
+				test.constraint, v)
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, invalid := range []string{
+		"", "1.2 ||", ">=", ">*", "!=1.2", "^1.x.3", "1.2.3-", "=>1.2",
+	} {
+		_, err := semver.ParseConstraint(invalid)
+		require.ErrorIs(t, err, semver.ErrInvalidConstraint, invalid)
+	}
+
+	assert.Panics(t, func() { semver.MustParseConstraint("") })
+}
+
+func TestSatisfies(t *testing.T) {
+	t.Parallel()
+
+	ok, err := semver.Satisfies("1.22.3", ">=1.21 <2")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = semver.Satisfies("1.22", ">=1.21")
+	require.ErrorIs(t, err, semver.ErrInvalidVersion)
+
+	_, err = semver.Satisfies("1.22.0", "~")
+	require.ErrorIs(t, err, semver.ErrInvalidConstraint)
+}
+
+func TestConstraintText(t *testing.T) {
+	t.Parallel()
+
+	var c semver.Constraint
+	require.NoError(t, c.UnmarshalText([]byte("^1.2")))
+	assert.True(t, c.Check(semver.MustParse("1.4.0")))
+
+	text, err := c.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "^1.2", string(text))
+	require.Error(t, c.UnmarshalText([]byte("^")))
+}
@@ -0,0 +1,333 @@
+// Package semver parses Semantic Versioning 2.0.0 versions, orders them
+// and matches them against constraint expressions such as "^1.2" or
+// ">=1.4 <2.0 || 3.x":
+//
+//	ok, err := semver.Satisfies("1.22.3", ">=1.21 <2")
+//
+// Versions may carry a leading "v". Coerce extracts a version from the
+// output of tools, such as "go version go1.22.3 linux/amd64".
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned for strings that are not a version.
+var ErrInvalidVersion = errors.New("semver: invalid version")
+
+// Version is a parsed semantic version. The zero value is 0.0.0.
+type Version struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+	// Prerelease holds the dot-separated identifiers after "-", if any.
+	Prerelease string
+	// Build holds the metadata after "+", ignored by comparisons.
+	Build string
+}
+
+// Parse parses a full MAJOR.MINOR.PATCH version with optional prerelease
+// and build metadata, ignoring a leading "v".
+func Parse(s string) (Version, error) {
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if parts != fullParts {
+		return Version{}, fmt.Errorf("%w %q: want MAJOR.MINOR.PATCH",
+			ErrInvalidVersion, s)
+	}
+
+	return v, nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is intended for
+// constants.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+var coercible = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// Coerce extracts the first version-like number sequence from s, filling
+// missing minor and patch numbers with zero. Prerelease and build parts are
+// dropped, so "go1.22rc1" coerces to 1.22.0.
+func Coerce(s string) (Version, error) {
+	match := coercible.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("%w %q", ErrInvalidVersion, s)
+	}
+
+	var numbers [fullParts]uint64
+
+	for i, digits := range match[1:] {
+		if digits == "" {
+			break
+		}
+
+		n, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w %q: %w", ErrInvalidVersion, s, err)
+		}
+
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]},
+		nil
+}
+
+// String formats v without a leading "v".
+func (v Version) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d.%d.%d", v.Major, v.Minor, v.Patch)
+
+	if v.Prerelease != "" {
+		b.WriteString("-" + v.Prerelease)
+	}
+
+	if v.Build != "" {
+		b.WriteString("+" + v.Build)
+	}
+
+	return b.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *Version) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+
+	return nil
+}
+
+// Compare returns -1, 0 or +1 as v sorts before, with or after other.
+// Prereleases sort before their release and build metadata is ignored.
+func (v Version) Compare(other Version) int {
+	return Compare(v, other)
+}
+
+// Less reports whether v sorts before other.
+func (v Version) Less(other Version) bool {
+	return Compare(v, other) < 0
+}
+
+// Compare orders versions by precedence, as Version.Compare. It suits
+// slices.SortFunc.
+func Compare(a, b Version) int {
+	for _, pair := range [fullParts][2]uint64{
+		{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch},
+	} {
+		if pair[0] != pair[1] {
+			return cmpUint(pair[0], pair[1])
+		}
+	}
+
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// comparePrerelease orders dot-separated identifiers, numeric ones
+// numerically and below alphanumeric ones. No prerelease sorts last.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := range min(len(left), len(right)) {
+		if c := compareIdentifier(left[i], right[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmpUint(uint64(len(left)), uint64(len(right)))
+}
+
+func compareIdentifier(a, b string) int {
+	x, errA := strconv.ParseUint(a, 10, 64)
+	y, errB := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case errA == nil && errB == nil:
+		return cmpUint(x, y)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func cmpUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// fullParts is the number of numeric components of a full version.
+const fullParts = 3
+
+var identifier = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// parsePartial parses a version that may lack its minor and patch numbers
+// or replace them with a wildcard, returning how many numbers were given.
+// Prerelease and build parts require all three numbers.
+func parsePartial(s string) (Version, int, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	text, build, hasBuild := strings.Cut(text, "+")
+	text, pre, hasPre := strings.Cut(text, "-")
+
+	v := Version{Prerelease: pre, Build: build}
+
+	parts, err := parseNumbers(text, &v)
+	if err != nil {
+		return Version{}, 0, fmt.Errorf("%w %q: %w", ErrInvalidVersion, s, err)
+	}
+
+	if (hasPre || hasBuild) && parts != fullParts {
+		return Version{}, 0, fmt.Errorf("%w %q: suffix on a partial version",
+			ErrInvalidVersion, s)
+	}
+
+	if hasPre {
+		if err := checkPrerelease(pre); err != nil {
+			return Version{}, 0, fmt.Errorf("%w %q: prerelease %w",
+				ErrInvalidVersion, s, err)
+		}
+	}
+
+	if hasBuild {
+		if err := checkIdentifiers(build); err != nil {
+			return Version{}, 0, fmt.Errorf("%w %q: build %w",
+				ErrInvalidVersion, s, err)
+		}
+	}
+
+	return v, parts, nil
+}
+
+var (
+	errNumber     = errors.New("malformed number")
+	errIdentifier = errors.New("has a malformed identifier")
+)
+
+// parseNumbers fills the numbers of v from "1", "1.2", "1.2.3" or their
+// wildcard forms such as "1.x" and "*".
+func parseNumbers(text string, v *Version) (int, error) {
+	fields := strings.Split(text, ".")
+	if len(fields) > fullParts {
+		return 0, errNumber
+	}
+
+	targets := [fullParts]*uint64{&v.Major, &v.Minor, &v.Patch}
+
+	for i, field := range fields {
+		if isWildcard(field) {
+			// Anything after a wildcard must be a wildcard too.
+			if !allWildcards(fields[i:]) {
+				return 0, errNumber
+			}
+
+			return i, nil
+		}
+
+		n, err := parseNumber(field)
+		if err != nil {
+			return 0, err
+		}
+
+		*targets[i] = n
+	}
+
+	return len(fields), nil
+}
+
+func parseNumber(field string) (uint64, error) {
+	if field == "" || (len(field) > 1 && field[0] == '0') {
+		return 0, errNumber
+	}
+
+	n, err := strconv.ParseUint(field, 10, 64)
+	if err != nil {
+		return 0, errNumber
+	}
+
+	return n, nil
+}
+
+// checkIdentifiers validates the dot-separated identifiers of a prerelease
+// or build part.
+func checkIdentifiers(suffix string) error {
+	for id := range strings.SplitSeq(suffix, ".") {
+		if !identifier.MatchString(id) {
+			return errIdentifier
+		}
+	}
+
+	return nil
+}
+
+// checkPrerelease also rejects numeric identifiers with leading zeros.
+func checkPrerelease(pre string) error {
+	if err := checkIdentifiers(pre); err != nil {
+		return err
+	}
+
+	for id := range strings.SplitSeq(pre, ".") {
+		if _, err := parseNumber(id); err != nil && isDigits(id) {
+			return errIdentifier
+		}
+	}
+
+	return nil
+}
+
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+func isWildcard(field string) bool {
+	return field == "x" || field == "X" || field == "*"
+}
+
+func allWildcards(fields []string) bool {
+	for _, field := range fields {
+		if !isWildcard(field) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,97 @@
+package semver_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/semver"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	v, err := semver.Parse("v1.22.3-rc.1+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, semver.Version{
+		Major: 1, Minor: 22, Patch: 3, Prerelease: "rc.1", Build: "build.5",
+	}, v)
+	assert.Equal(t, "1.22.3-rc.1+build.5", v.String())
+
+	for _, invalid := range []string{
+		"", "1", "1.2", "1.2.3.4", "01.2.3", "1.2.x", "1.2.3-", "1.2.3-01",
+		"1.2.3+a..b", "a.b.c", "-1.2.3",
+	} {
+		_, err := semver.Parse(invalid)
+		require.ErrorIs(t, err, semver.ErrInvalidVersion, invalid)
+	}
+
+	assert.Panics(t, func() { semver.MustParse("1") })
+}
+
+func TestCoerce(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"go version go1.22.3 linux/amd64": "1.22.3",
+		"go1.22rc1":                       "1.22.0",
+		"Python 3.12":                     "3.12.0",
+		"v7":                              "7.0.0",
+	}
+
+	for input, want := range tests {
+		v, err := semver.Coerce(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, v.String(), input)
+	}
+
+	_, err := semver.Coerce("unknown")
+	require.ErrorIs(t, err, semver.ErrInvalidVersion)
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	// Ordered by precedence, as in the specification.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1",
+		"1.1.0", "2.0.0",
+	}
+
+	versions := make([]semver.Version, len(ordered))
+	for i, s := range ordered {
+		versions[i] = semver.MustParse(s)
+	}
+
+	slices.Reverse(versions)
+	slices.SortFunc(versions, semver.Compare)
+
+	for i, v := range versions {
+		assert.Equal(t, ordered[i], v.String())
+	}
+
+	a, b := semver.MustParse("1.0.0+a"), semver.MustParse("1.0.0+b")
+	assert.Zero(t, a.Compare(b), "build metadata is ignored")
+	assert.True(t, versions[0].Less(versions[1]))
+}
+
+func TestVersionText(t *testing.T) {
+	t.Parallel()
+
+	var got struct {
+		Version semver.Version `json:"version"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"version":"v2.1.0"}`), &got))
+	assert.Equal(t, semver.MustParse("2.1.0"), got.Version)
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":"2.1.0"}`, string(data))
+
+	require.Error(t, json.Unmarshal([]byte(`{"version":"2.1"}`), &got))
+}
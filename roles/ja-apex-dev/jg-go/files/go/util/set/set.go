@@ -0,0 +1,140 @@
+// Package set provides a generic hash set built on a map.
+package set
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Set is an unordered collection of unique values. The zero value is an
+// empty read-only set; use New or make before adding items.
+type Set[T comparable] map[T]struct{}
+
+// New returns a set holding items.
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+
+	return s
+}
+
+// Collect builds a set from an iterator.
+func Collect[T comparable](seq iter.Seq[T]) Set[T] {
+	s := New[T]()
+	for item := range seq {
+		s[item] = struct{}{}
+	}
+
+	return s
+}
+
+// Add inserts items into the set.
+func (s Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+// Remove deletes items from the set.
+func (s Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s, item)
+	}
+}
+
+// Contains reports whether item is in the set.
+func (s Set[T]) Contains(item T) bool {
+	_, ok := s[item]
+
+	return ok
+}
+
+// Len returns the number of items.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Clone returns a copy of the set.
+func (s Set[T]) Clone() Set[T] {
+	clone := make(Set[T], len(s))
+	maps.Copy(clone, s)
+
+	return clone
+}
+
+// Union returns the items present in s or other.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	result := s.Clone()
+	maps.Copy(result, other)
+
+	return result
+}
+
+// Intersect returns the items present in both s and other.
+func (s Set[T]) Intersect(other Set[T]) Set[T] {
+	small, large := s, other
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+
+	result := New[T]()
+
+	for item := range small {
+		if large.Contains(item) {
+			result[item] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// Difference returns the items of s missing from other.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	result := New[T]()
+
+	for item := range s {
+		if !other.Contains(item) {
+			result[item] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// SubsetOf reports whether every item of s is in other.
+func (s Set[T]) SubsetOf(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+
+	for item := range s {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Equal reports whether both sets hold the same items.
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.SubsetOf(other)
+}
+
+// All iterates over the items in unspecified order. Use Sorted or
+// SortedFunc for a deterministic order.
+func (s Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s)
+}
+
+// Sorted returns the items in ascending order.
+func Sorted[T cmp.Ordered](s Set[T]) []T {
+	return slices.Sorted(maps.Keys(s))
+}
+
+// SortedFunc returns the items ordered by compare.
+func SortedFunc[T comparable](s Set[T], compare func(a, b T) int) []T {
+	return slices.SortedFunc(maps.Keys(s), compare)
+}
@@ -0,0 +1,60 @@
+package set_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/set"
+)
+
+func TestSetBasics(t *testing.T) {
+	t.Parallel()
+
+	s := set.New("a", "b", "a")
+	assert.Equal(t, 2, s.Len())
+	assert.True(t, s.Contains("a"))
+	assert.False(t, s.Contains("c"))
+
+	s.Add("c")
+	s.Remove("a", "missing")
+	assert.Equal(t, []string{"b", "c"}, set.Sorted(s))
+
+	var empty set.Set[string]
+	assert.False(t, empty.Contains("a"))
+	assert.Equal(t, 0, empty.Len())
+}
+
+func TestSetAlgebra(t *testing.T) {
+	t.Parallel()
+
+	a := set.New(1, 2, 3)
+	b := set.New(3, 4)
+
+	assert.Equal(t, []int{1, 2, 3, 4}, set.Sorted(a.Union(b)))
+	assert.Equal(t, []int{3}, set.Sorted(a.Intersect(b)))
+	assert.Equal(t, []int{1, 2}, set.Sorted(a.Difference(b)))
+	assert.Equal(t, []int{1, 2, 3}, set.Sorted(a), "operands are unchanged")
+
+	assert.True(t, set.New(1, 2).SubsetOf(a))
+	assert.False(t, b.SubsetOf(a))
+	assert.True(t, a.Equal(set.New(3, 2, 1)))
+	assert.False(t, a.Equal(b))
+}
+
+func TestSetIteration(t *testing.T) {
+	t.Parallel()
+
+	s := set.Collect(slices.Values([]string{"b", "A", "c"}))
+	assert.ElementsMatch(t, []string{"A", "b", "c"}, slices.Collect(s.All()))
+	assert.Equal(t, []string{"A", "b", "c"},
+		set.SortedFunc(s, func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}))
+
+	clone := s.Clone()
+	clone.Add("d")
+	assert.False(t, s.Contains("d"))
+}
@@ -0,0 +1,104 @@
+package shellrc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Shell is a supported shell, which decides the profile location and the
+// syntax of generated lines.
+type Shell string
+
+// Supported shells.
+const (
+	Bash Shell = "bash"
+	Zsh  Shell = "zsh"
+	Fish Shell = "fish"
+)
+
+// Current returns the login shell of the user from $SHELL, defaulting to
+// Bash for unknown shells.
+func Current() Shell {
+	switch shell := Shell(filepath.Base(os.Getenv("SHELL"))); shell {
+	case Zsh, Fish:
+		return shell
+	default:
+		return Bash
+	}
+}
+
+// Profile returns the interactive configuration file of the shell:
+// ~/.bashrc, $ZDOTDIR/.zshrc or $XDG_CONFIG_HOME/fish/config.fish.
+func (s Shell) Profile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("shellrc: %w", err)
+	}
+
+	switch s {
+	case Zsh:
+		return filepath.Join(envOr("ZDOTDIR", home), ".zshrc"), nil
+	case Fish:
+		config := envOr("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+		return filepath.Join(config, "fish", "config.fish"), nil
+	default:
+		return filepath.Join(home, ".bashrc"), nil
+	}
+}
+
+// Export returns the line exporting the environment variable name, which
+// must be a valid identifier, with value taken literally.
+func (s Shell) Export(name, value string) string {
+	if s == Fish {
+		return "set -gx " + name + " " + s.quote(value)
+	}
+
+	return "export " + name + "=" + s.quote(value)
+}
+
+// PrependPath returns the line putting dir first in PATH unless it is
+// already there, so that sourcing the profile twice does not grow PATH.
+func (s Shell) PrependPath(dir string) string {
+	q := s.quote(dir)
+	if s == Fish {
+		return "contains -- " + q + " $PATH; or set -gx PATH " + q + " $PATH"
+	}
+
+	return `case ":$PATH:" in *:` + q + `:*) ;; *) export PATH=` + q +
+		`":$PATH" ;; esac`
+}
+
+// AppendPath is PrependPath putting dir last.
+func (s Shell) AppendPath(dir string) string {
+	q := s.quote(dir)
+	if s == Fish {
+		return "contains -- " + q + " $PATH; or set -gx PATH $PATH " + q
+	}
+
+	return `case ":$PATH:" in *:` + q + `:*) ;; *) export PATH="$PATH:"` + q +
+		` ;; esac`
+}
+
+// quote wraps value in single quotes, in which neither shell expands
+// anything. Fish allows escaping quotes inside, POSIX shells need to
+// close the quotes around them.
+func (s Shell) quote(value string) string {
+	if s == Fish {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+
+		return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}
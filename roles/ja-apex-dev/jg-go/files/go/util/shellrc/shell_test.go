@@ -0,0 +1,69 @@
+package shellrc_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/shellrc"
+)
+
+//nolint:paralleltest // Modifies the process environment.
+func TestProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ZDOTDIR", filepath.Join(home, "zsh"))
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("SHELL", "/usr/bin/zsh")
+
+	assert.Equal(t, shellrc.Zsh, shellrc.Current())
+
+	cases := map[shellrc.Shell]string{
+		shellrc.Bash: filepath.Join(home, ".bashrc"),
+		shellrc.Zsh:  filepath.Join(home, "zsh", ".zshrc"),
+		shellrc.Fish: filepath.Join(home, ".config", "fish", "config.fish"),
+	}
+
+	for shell, want := range cases {
+		path, err := shellrc.Shell(shell).Profile()
+		require.NoError(t, err)
+		assert.Equal(t, want, path, shell)
+	}
+
+	t.Setenv("SHELL", "/bin/tcsh")
+	assert.Equal(t, shellrc.Bash, shellrc.Current())
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	fish := shellrc.Fish
+	assert.Equal(t, `set -gx NAME 'it\'s'`, fish.Export("NAME", "it's"))
+	assert.Equal(t, "contains -- '/opt/bin' $PATH; "+
+		"or set -gx PATH '/opt/bin' $PATH", fish.PrependPath("/opt/bin"))
+	assert.Equal(t, "contains -- '/opt/bin' $PATH; "+
+		"or set -gx PATH $PATH '/opt/bin'", fish.AppendPath("/opt/bin"))
+	assert.Equal(t, `export NAME='it'\''s $HOME'`,
+		shellrc.Bash.Export("NAME", "it's $HOME"))
+}
+
+func TestPathLines(t *testing.T) {
+	t.Parallel()
+
+	sh := shellrc.Bash
+	script := strings.Join([]string{
+		"PATH=/usr/bin",
+		sh.PrependPath("/opt/my bin"), sh.PrependPath("/opt/my bin"),
+		sh.AppendPath("/tail"), sh.AppendPath("/usr/bin"),
+		sh.Export("GREETING", "it's"),
+		`printf '%s|%s' "$PATH" "$GREETING"`,
+	}, "\n")
+
+	out, err := exec.Command("/bin/sh", "-c", script).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/my bin:/usr/bin:/tail|it's", string(out))
+}
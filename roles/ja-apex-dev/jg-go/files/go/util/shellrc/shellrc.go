@@ -0,0 +1,159 @@
+// Package shellrc manages blocks of lines in shell profiles such as
+// ~/.bashrc, ~/.zshrc and config.fish. Each block is delimited by markers
+// naming its owner, so that it can be updated or removed without touching
+// the lines around it, and rewriting a file only happens when a block
+// actually changes:
+//
+//	sh := shellrc.Current()
+//	profile, _ := sh.Profile()
+//	changed, err := shellrc.SetBlock(profile, "go",
+//		sh.Export("GOPATH", "/home/me/go"),
+//		sh.PrependPath("/home/me/go/bin"))
+package shellrc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util/state"
+)
+
+var (
+	// ErrInvalidName is returned for block names that cannot be markers.
+	ErrInvalidName = errors.New("shellrc: invalid block name")
+	// ErrUnterminated is returned when a block has a begin marker but no
+	// end marker; the file is left alone rather than guessed at.
+	ErrUnterminated = errors.New("shellrc: unterminated block")
+)
+
+// Markers delimiting blocks, followed by the block name.
+const (
+	BeginMarker = "# BEGIN "
+	EndMarker   = "# END "
+)
+
+// SetBlock makes the block name of the file at path hold lines, replacing
+// its previous content in place or appending it to the file, which is
+// created when missing. It reports whether the file changed.
+func SetBlock(path, name string, lines ...string) (bool, error) {
+	current, start, end, err := load(path, name)
+	if err != nil {
+		return false, err
+	}
+
+	block := slices.Concat([]string{BeginMarker + name}, lines,
+		[]string{EndMarker + name})
+
+	if start < 0 {
+		// Separate the block from the previous content by a blank line.
+		if len(current) > 0 && current[len(current)-1] != "" {
+			current = append(current, "")
+		}
+
+		return write(path, append(current, block...))
+	}
+
+	return write(path, slices.Concat(current[:start], block, current[end+1:]))
+}
+
+// RemoveBlock deletes the block name from the file at path, if present,
+// and reports whether the file changed. The blank line separating a
+// block at the end of the file goes with it.
+func RemoveBlock(path, name string) (bool, error) {
+	current, start, end, err := load(path, name)
+	if err != nil || start < 0 {
+		return false, err
+	}
+
+	if end == len(current)-1 && start > 0 && current[start-1] == "" {
+		start--
+	}
+
+	return write(path, slices.Concat(current[:start], current[end+1:]))
+}
+
+// ReadBlock returns the lines of the block name in the file at path and
+// whether it exists. A missing file has no blocks.
+func ReadBlock(path, name string) ([]string, bool, error) {
+	current, start, end, err := load(path, name)
+	if err != nil || start < 0 {
+		return nil, false, err
+	}
+
+	return current[start+1 : end], true, nil
+}
+
+// load reads the lines of the file at path and locates the block name.
+func load(path, name string) (lines []string, start, end int, err error) {
+	if lines, err = readLines(path); err != nil {
+		return nil, 0, 0, err
+	}
+
+	start, end, err = find(lines, name)
+
+	return lines, start, end, err
+}
+
+// find returns the indices of the markers of the block name, or -1 when
+// it is missing.
+func find(lines []string, name string) (start, end int, err error) {
+	if name == "" || strings.ContainsAny(name, "\r\n") ||
+		strings.TrimSpace(name) != name {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	start = slices.Index(lines, BeginMarker+name)
+	if start < 0 {
+		return -1, -1, nil
+	}
+
+	end = slices.Index(lines[start:], EndMarker+name)
+	if end < 0 {
+		return 0, 0, fmt.Errorf("%w: %s", ErrUnterminated, name)
+	}
+
+	return start, start + end, nil
+}
+
+func readLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("shellrc: %w", err)
+	}
+
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+
+	return strings.Split(text, "\n"), nil
+}
+
+// write stores lines at path when they differ from its content, keeping
+// the mode and owner of an existing file.
+func write(path string, lines []string) (bool, error) {
+	var data bytes.Buffer
+	for _, line := range lines {
+		data.WriteString(line + "\n")
+	}
+
+	// Profile edits are quick local writes, not worth a context argument.
+	changes, err := state.Apply(context.Background(),
+		state.Dir(filepath.Dir(path)), state.File(path, data.Bytes()))
+	if err != nil {
+		return false, fmt.Errorf("shellrc: %w", err)
+	}
+
+	return len(changes) > 0, nil
+}
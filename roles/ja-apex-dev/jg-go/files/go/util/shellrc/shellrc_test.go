@@ -0,0 +1,115 @@
+package shellrc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/shellrc"
+	"example.com/go-template/util/testx"
+)
+
+func TestSetBlock(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		".bashrc": "alias ll='ls -l'\n",
+	})
+	path := filepath.Join(dir, ".bashrc")
+
+	changed, err := shellrc.SetBlock(path, "go", "export A=1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = shellrc.SetBlock(path, "rust", "export B=1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = shellrc.SetBlock(path, "go", "export A=2", "export C=3")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = shellrc.SetBlock(path, "go", "export A=2", "export C=3")
+	require.NoError(t, err)
+	assert.False(t, changed, "an unchanged block leaves the file alone")
+
+	assertFile(t, path, "alias ll='ls -l'\n\n"+
+		"# BEGIN go\nexport A=2\nexport C=3\n# END go\n\n"+
+		"# BEGIN rust\nexport B=1\n# END rust\n")
+
+	lines, ok, err := shellrc.ReadBlock(path, "go")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"export A=2", "export C=3"}, lines)
+}
+
+func TestSetBlockSymlink(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"dotfiles/bashrc": "alias ll='ls -l'\n",
+	})
+	path := filepath.Join(dir, ".bashrc")
+	require.NoError(t, os.Symlink("dotfiles/bashrc", path))
+
+	changed, err := shellrc.SetBlock(path, "go", "export A=1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	assertFile(t, filepath.Join(dir, "dotfiles/bashrc"),
+		"alias ll='ls -l'\n\n# BEGIN go\nexport A=1\n# END go\n")
+}
+
+func TestRemoveBlock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "fish", "config.fish")
+
+	_, err := shellrc.SetBlock(path, "a", "set -gx A 1")
+	require.NoError(t, err)
+	_, err = shellrc.SetBlock(path, "b", "set -gx B 1")
+	require.NoError(t, err)
+
+	changed, err := shellrc.RemoveBlock(path, "b")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assertFile(t, path, "# BEGIN a\nset -gx A 1\n# END a\n")
+
+	changed, err = shellrc.RemoveBlock(path, "b")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, ok, err := shellrc.ReadBlock(path+".missing", "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestBlockErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		".zshrc": "# BEGIN broken\nexport A=1\n",
+	})
+	path := filepath.Join(dir, ".zshrc")
+
+	_, err := shellrc.SetBlock(path, "broken", "x")
+	require.ErrorIs(t, err, shellrc.ErrUnterminated)
+	assertFile(t, path, "# BEGIN broken\nexport A=1\n")
+
+	_, err = shellrc.RemoveBlock(path, "two\nlines")
+	require.ErrorIs(t, err, shellrc.ErrInvalidName)
+
+	_, _, err = shellrc.ReadBlock(path, "")
+	require.ErrorIs(t, err, shellrc.ErrInvalidName)
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(data))
+}
@@ -0,0 +1,118 @@
+// Package slices provides generic helpers complementing the standard library
+// slices package.
+package slices
+
+// Map returns a new slice with fn applied to every item.
+func Map[S ~[]E, E, R any](items S, fn func(E) R) []R {
+	result := make([]R, 0, len(items))
+	for _, item := range items {
+		result = append(result, fn(item))
+	}
+
+	return result
+}
+
+// Filter returns a new slice with the items for which keep returns true.
+func Filter[S ~[]E, E any](items S, keep func(E) bool) S {
+	result := make(S, 0, len(items))
+
+	for _, item := range items {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// Reduce folds the items into a single value starting from initial.
+func Reduce[S ~[]E, E, R any](items S, initial R, fn func(R, E) R) R {
+	acc := initial
+	for _, item := range items {
+		acc = fn(acc, item)
+	}
+
+	return acc
+}
+
+// Unique returns the items without duplicates, preserving first occurrence
+// order.
+func Unique[S ~[]E, E comparable](items S) S {
+	seen := make(map[E]struct{}, len(items))
+	result := make(S, 0, len(items))
+
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+
+		seen[item] = struct{}{}
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// Chunk splits the items into consecutive chunks of at most size items.
+// The chunks share the backing array of items. Chunk panics if size is not
+// positive.
+func Chunk[S ~[]E, E any](items S, size int) []S {
+	if size <= 0 {
+		panic("slices: chunk size must be positive")
+	}
+
+	result := make([]S, 0, (len(items)+size-1)/size)
+
+	for start := 0; start < len(items); start += size {
+		end := min(start+size, len(items))
+		result = append(result, items[start:end:end])
+	}
+
+	return result
+}
+
+// Flatten concatenates the nested slices into a single slice.
+func Flatten[S ~[]E, E any](nested []S) S {
+	total := 0
+	for _, items := range nested {
+		total += len(items)
+	}
+
+	result := make(S, 0, total)
+	for _, items := range nested {
+		result = append(result, items...)
+	}
+
+	return result
+}
+
+// GroupBy groups the items by the key returned from fn, preserving the item
+// order within every group.
+func GroupBy[S ~[]E, E any, K comparable](items S, fn func(E) K) map[K]S {
+	result := make(map[K]S)
+
+	for _, item := range items {
+		key := fn(item)
+		result[key] = append(result[key], item)
+	}
+
+	return result
+}
+
+// Partition splits the items into those matching the predicate and the rest.
+func Partition[S ~[]E, E any](
+	items S, pred func(E) bool,
+) (matched, rest S) {
+	matched = make(S, 0, len(items))
+	rest = make(S, 0, len(items))
+
+	for _, item := range items {
+		if pred(item) {
+			matched = append(matched, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+
+	return matched, rest
+}
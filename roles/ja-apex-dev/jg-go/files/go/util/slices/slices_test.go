@@ -0,0 +1,84 @@
+package slices_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/slices"
+)
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"1", "2"}, slices.Map([]int{1, 2}, strconv.Itoa))
+	assert.Empty(t, slices.Map([]int(nil), strconv.Itoa))
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	even := func(n int) bool { return n%2 == 0 }
+	assert.Equal(t, []int{2, 4}, slices.Filter([]int{1, 2, 3, 4}, even))
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	sum := func(acc, n int) int { return acc + n }
+	assert.Equal(t, 10, slices.Reduce([]int{1, 2, 3, 4}, 0, sum))
+}
+
+func TestUnique(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"b", "a", "c"},
+		slices.Unique([]string{"b", "a", "b", "c", "a"}))
+}
+
+func TestChunk(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}},
+		slices.Chunk([]int{1, 2, 3, 4, 5}, 2))
+	assert.Empty(t, slices.Chunk([]int{}, 3))
+	assert.Panics(t, func() { slices.Chunk([]int{1}, 0) })
+}
+
+func TestChunkDoesNotOverwrite(t *testing.T) {
+	t.Parallel()
+
+	items := []int{1, 2, 3}
+	chunks := slices.Chunk(items, 2)
+	_ = append(chunks[0], 9)
+
+	assert.Equal(t, []int{1, 2, 3}, items)
+}
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []int{1, 2, 3}, slices.Flatten([][]int{{1}, {}, {2, 3}}))
+}
+
+func TestGroupBy(t *testing.T) {
+	t.Parallel()
+
+	groups := slices.GroupBy(
+		[]string{"apple", "avocado", "banana"},
+		func(s string) string { return s[:1] },
+	)
+	assert.Equal(t, map[string][]string{
+		"a": {"apple", "avocado"},
+		"b": {"banana"},
+	}, groups)
+}
+
+func TestPartition(t *testing.T) {
+	t.Parallel()
+
+	upper, rest := slices.Partition(
+		[]string{"A", "b", "C"},
+		func(s string) bool { return strings.ToUpper(s) == s },
+	)
+	assert.Equal(t, []string{"A", "C"}, upper)
+	assert.Equal(t, []string{"b"}, rest)
+}
@@ -0,0 +1,136 @@
+package sshx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ErrHostKeyChanged is returned when a host presents another key than the
+// one recorded for it, which may be a machine in the middle.
+var ErrHostKeyChanged = errors.New("sshx: host key changed")
+
+// HostKeyPolicy builds the host key check of a connection.
+type HostKeyPolicy func() (ssh.HostKeyCallback, error)
+
+// DefaultKnownHosts returns the known_hosts file of the user.
+func DefaultKnownHosts() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// KnownHosts accepts only the hosts listed in the files, by default
+// DefaultKnownHosts, as OpenSSH does with StrictHostKeyChecking=yes.
+func KnownHosts(files ...string) HostKeyPolicy {
+	return func() (ssh.HostKeyCallback, error) {
+		if len(files) == 0 {
+			files = []string{DefaultKnownHosts()}
+		}
+
+		check, err := knownhosts.New(files...)
+		if err != nil {
+			return nil, fmt.Errorf("sshx: %w", err)
+		}
+
+		return func(host string, remote net.Addr, key ssh.PublicKey) error {
+			return keyError(check(host, remote, key))
+		}, nil
+	}
+}
+
+// TrustOnFirstUse accepts unknown hosts and records their key in file,
+// created when missing, but rejects keys that differ from the recorded
+// ones, as OpenSSH does with StrictHostKeyChecking=accept-new.
+func TrustOnFirstUse(file string) HostKeyPolicy {
+	t := &tofu{file: file}
+
+	return func() (ssh.HostKeyCallback, error) {
+		err := os.MkdirAll(filepath.Dir(file), 0o700)
+		if err == nil {
+			err = appendLine(file, "")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("sshx: %w", err)
+		}
+
+		return t.check, nil
+	}
+}
+
+// tofu records the keys of new hosts in a known_hosts file.
+type tofu struct {
+	file string
+	mu   sync.Mutex
+}
+
+func (t *tofu) check(host string, remote net.Addr, key ssh.PublicKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Reload to see the keys recorded by earlier connections.
+	check, err := knownhosts.New(t.file)
+	if err != nil {
+		return fmt.Errorf("sshx: %w", err)
+	}
+
+	var keyErr *knownhosts.KeyError
+	if err := check(host, remote, key); !errors.As(err, &keyErr) ||
+		len(keyErr.Want) > 0 {
+		return keyError(err)
+	}
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(host)}, key)
+	if err := appendLine(t.file, line+"\n"); err != nil {
+		return fmt.Errorf("sshx: %w", err)
+	}
+
+	return nil
+}
+
+// InsecureIgnoreHostKey accepts any host key. It is meant for tests and
+// throwaway machines only, since it allows machines in the middle.
+func InsecureIgnoreHostKey() HostKeyPolicy {
+	return func() (ssh.HostKeyCallback, error) {
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec // Opt-in.
+	}
+}
+
+// keyError reports changed keys with ErrHostKeyChanged.
+func keyError(err error) error {
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) && len(keyErr.Want) > 0 {
+		return fmt.Errorf("%w: %w", ErrHostKeyChanged, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("sshx: %w", err)
+	}
+
+	return nil
+}
+
+func appendLine(file, line string) error {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(line); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
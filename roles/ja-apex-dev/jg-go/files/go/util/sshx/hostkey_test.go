@@ -0,0 +1,58 @@
+package sshx_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"example.com/go-template/util/sshx"
+)
+
+func TestTrustOnFirstUse(t *testing.T) {
+	t.Parallel()
+
+	s := newServer(t)
+	file := filepath.Join(t.TempDir(), "ssh", "known_hosts")
+	policy := sshx.WithHostKeyPolicy(sshx.TrustOnFirstUse(file))
+
+	s.dial(t, policy)
+	s.dial(t, policy)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, knownhosts.Line([]string{knownhosts.Normalize(s.addr)},
+		s.hostKey)+"\n", string(data), "the key is recorded once")
+
+	s.dial(t, sshx.WithHostKeyPolicy(sshx.KnownHosts(file)))
+
+	other := newServer(t)
+	line := knownhosts.Line([]string{knownhosts.Normalize(other.addr)},
+		s.hostKey)
+	require.NoError(t, os.WriteFile(file, []byte(line+"\n"), 0o600))
+
+	_, err = sshx.Dial(t.Context(), other.addr, sshx.WithUser("test"),
+		sshx.WithSigner(other.client), policy)
+	require.ErrorIs(t, err, sshx.ErrHostKeyChanged)
+}
+
+func TestKnownHosts(t *testing.T) {
+	t.Parallel()
+
+	s := newServer(t)
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, err := sshx.Dial(t.Context(), s.addr, sshx.WithUser("test"),
+		sshx.WithSigner(s.client),
+		sshx.WithHostKeyPolicy(sshx.KnownHosts(file)))
+	require.Error(t, err, "unknown hosts are rejected")
+	assert.NotErrorIs(t, err, sshx.ErrHostKeyChanged)
+
+	_, err = sshx.Dial(t.Context(), s.addr, sshx.WithSigner(s.client),
+		sshx.WithHostKeyPolicy(sshx.KnownHosts(file+".missing")))
+	require.Error(t, err)
+}
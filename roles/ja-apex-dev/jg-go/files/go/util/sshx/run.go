@@ -0,0 +1,133 @@
+package sshx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"example.com/go-template/util/execx"
+)
+
+// RunOption configures Run.
+type RunOption func(*runSettings)
+
+type runSettings struct {
+	timeout time.Duration
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// WithTimeout kills commands running longer than timeout, failing with
+// execx.ErrTimeout.
+func WithTimeout(timeout time.Duration) RunOption {
+	return func(s *runSettings) {
+		s.timeout = timeout
+	}
+}
+
+// WithStdin feeds stdin to the command.
+func WithStdin(stdin io.Reader) RunOption {
+	return func(s *runSettings) {
+		s.stdin = stdin
+	}
+}
+
+// WithOutput streams the output of the command to stdout and stderr while
+// it runs, on top of capturing it in the result. Either may be nil.
+func WithOutput(stdout, stderr io.Writer) RunOption {
+	return func(s *runSettings) {
+		s.stdout = stdout
+		s.stderr = stderr
+	}
+}
+
+// Run executes command with the shell of the remote user and waits for
+// it. As with execx, a non-zero exit status yields an *execx.ExitError
+// alongside the result. When ctx ends, the command is sent SIGKILL and its
+// session is closed.
+func (c *Client) Run(
+	ctx context.Context, command string, opts ...RunOption,
+) (execx.Result, error) {
+	var s runSettings
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeoutCause(ctx, s.timeout, execx.ErrTimeout)
+		defer cancel()
+	}
+
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return execx.Result{}, fmt.Errorf("sshx: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	session.Stdin = s.stdin
+	session.Stdout = tee(&stdout, s.stdout)
+	session.Stderr = tee(&stderr, s.stderr)
+
+	start := time.Now()
+	err = wait(ctx, session, command)
+	result := execx.Result{
+		Stdout: stdout.String(), Stderr: stderr.String(),
+		Duration: time.Since(start),
+	}
+
+	return result, wrap(ctx, command, &result, err)
+}
+
+// wait runs command in session until it exits or ctx ends.
+func wait(ctx context.Context, session *ssh.Session, command string) error {
+	if err := session.Start(command); err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+	})
+	defer stop()
+
+	return session.Wait()
+}
+
+func wrap(
+	ctx context.Context, command string, result *execx.Result, err error,
+) error {
+	var exitErr *ssh.ExitError
+
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return fmt.Errorf("sshx: %s: %w", command, context.Cause(ctx))
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitStatus()
+
+		return &execx.ExitError{Command: command, Result: *result, Err: err}
+	default:
+		result.ExitCode = -1
+
+		return fmt.Errorf("sshx: %s: %w", command, err)
+	}
+}
+
+func tee(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+
+	return io.MultiWriter(buf, w)
+}
@@ -0,0 +1,49 @@
+package sshx_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/sshx"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	client := newServer(t).dial(t)
+
+	var live strings.Builder
+
+	result, err := client.Run(t.Context(), "cat; echo oops >&2; exit 3",
+		sshx.WithStdin(strings.NewReader("data\n")),
+		sshx.WithOutput(&live, nil))
+
+	var exitErr *execx.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "data\n", result.Stdout)
+	assert.Equal(t, "oops\n", result.Stderr)
+	assert.Equal(t, "data\n", live.String())
+	assert.Equal(t, result, exitErr.Result)
+}
+
+func TestRunTimeout(t *testing.T) {
+	t.Parallel()
+
+	client := newServer(t).dial(t)
+
+	start := time.Now()
+	_, err := client.Run(t.Context(), "sleep 10",
+		sshx.WithTimeout(50*time.Millisecond))
+	require.ErrorIs(t, err, execx.ErrTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	result, err := client.Run(t.Context(), "echo still usable")
+	require.NoError(t, err)
+	assert.Equal(t, "still usable\n", result.Stdout)
+}
@@ -0,0 +1,278 @@
+// Package sshx runs commands on remote hosts over SSH and uploads files
+// through SFTP, for Go tools that need a remote step without a full
+// Ansible run. Clients authenticate with the SSH agent or key files and
+// check host keys against known_hosts files.
+package sshx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrNoAuth is returned by Dial when no agent or key is available.
+var ErrNoAuth = errors.New("sshx: no authentication method")
+
+// DefaultPort is used for addresses without a port.
+const DefaultPort = "22"
+
+// defaultKeys are the key files tried when no authentication is set, in
+// the home directory of the user.
+var defaultKeys = []string{".ssh/id_ed25519", ".ssh/id_ecdsa", ".ssh/id_rsa"}
+
+// Option configures Dial.
+type Option func(*settings)
+
+type settings struct {
+	user     string
+	signers  []ssh.Signer
+	keyFiles []string
+	agent    bool
+	policy   HostKeyPolicy
+	timeout  time.Duration
+}
+
+// WithUser logs in as user instead of the current user.
+func WithUser(name string) Option {
+	return func(s *settings) {
+		s.user = name
+	}
+}
+
+// WithKeyFile authenticates with the unencrypted private key at path.
+// Keys protected by a passphrase belong in the agent.
+func WithKeyFile(path string) Option {
+	return func(s *settings) {
+		s.keyFiles = append(s.keyFiles, path)
+	}
+}
+
+// WithSigner authenticates with signers, such as keys kept in memory.
+func WithSigner(signers ...ssh.Signer) Option {
+	return func(s *settings) {
+		s.signers = append(s.signers, signers...)
+	}
+}
+
+// WithAgent authenticates with the keys of the agent at $SSH_AUTH_SOCK.
+func WithAgent() Option {
+	return func(s *settings) {
+		s.agent = true
+	}
+}
+
+// WithHostKeyPolicy sets how host keys are checked, KnownHosts of
+// ~/.ssh/known_hosts by default.
+func WithHostKeyPolicy(policy HostKeyPolicy) Option {
+	return func(s *settings) {
+		s.policy = policy
+	}
+}
+
+// WithDialTimeout bounds the connection and the handshake, 30 seconds by
+// default.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.timeout = timeout
+	}
+}
+
+// Client is a connection to a remote host, safe for concurrent use.
+type Client struct {
+	conn    *ssh.Client
+	closers []io.Closer
+}
+
+// Dial connects to addr, a host with an optional port. Without
+// authentication options, it uses the agent when $SSH_AUTH_SOCK is set
+// and the default key files of the user that exist.
+func Dial(ctx context.Context, addr string, opts ...Option) (*Client, error) {
+	s := settings{timeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, DefaultPort)
+	}
+
+	client := &Client{}
+
+	config, err := client.config(s)
+	if err != nil {
+		client.closeAll()
+
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if client.conn, err = handshake(ctx, addr, config); err != nil {
+		client.closeAll()
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	c.closeAll()
+
+	if err != nil {
+		return fmt.Errorf("sshx: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) closeAll() {
+	for _, closer := range c.closers {
+		_ = closer.Close()
+	}
+}
+
+// config builds the client configuration, keeping the agent connection
+// open for the lifetime of the client.
+func (c *Client) config(s settings) (*ssh.ClientConfig, error) {
+	if s.user == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, fmt.Errorf("sshx: %w", err)
+		}
+
+		s.user = current.Username
+	}
+
+	if s.policy == nil {
+		s.policy = KnownHosts()
+	}
+
+	hostKey, err := s.policy()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := c.auth(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User: s.user, Auth: auth, HostKeyCallback: hostKey, Timeout: s.timeout,
+	}, nil
+}
+
+// auth returns the authentication methods of the settings.
+func (c *Client) auth(s settings) ([]ssh.AuthMethod, error) {
+	if len(s.signers)+len(s.keyFiles) == 0 && !s.agent {
+		s.agent = os.Getenv("SSH_AUTH_SOCK") != ""
+		s.keyFiles = existingKeys()
+	}
+
+	signers := s.signers
+
+	for _, path := range s.keyFiles {
+		signer, err := readKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		signers = append(signers, signer)
+	}
+
+	var methods []ssh.AuthMethod
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if s.agent {
+		conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+		if err != nil {
+			return nil, fmt.Errorf("sshx: agent: %w", err)
+		}
+
+		c.closers = append(c.closers, conn)
+		methods = append(methods,
+			ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoAuth
+	}
+
+	return methods, nil
+}
+
+func readKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("sshx: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("sshx: %s: %w", path, err)
+	}
+
+	return signer, nil
+}
+
+func existingKeys() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+
+	for _, name := range defaultKeys {
+		path := filepath.Join(home, name)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+
+	return found
+}
+
+// handshake connects to addr, aborting the handshake when ctx ends.
+func handshake(
+	ctx context.Context, addr string, config *ssh.ClientConfig,
+) (*ssh.Client, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("sshx: %w", err)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, addr, config)
+	if !stop() {
+		err = context.Cause(ctx)
+	}
+
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("sshx: %s: %w", addr, err)
+	}
+
+	return ssh.NewClient(sshConn, channels, requests), nil
+}
@@ -0,0 +1,176 @@
+package sshx_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os/exec"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"example.com/go-template/util/sshx"
+)
+
+// server is an SSH server on the loopback interface running commands with
+// the local shell and serving SFTP.
+type server struct {
+	addr    string
+	hostKey ssh.PublicKey
+	client  ssh.Signer
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	return signer
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+
+	host, client := newSigner(t), newSigner(t)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(
+			_ ssh.ConnMetadata, key ssh.PublicKey,
+		) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(client.PublicKey().Marshal()) {
+				return nil, errors.New("unknown key")
+			}
+
+			return &ssh.Permissions{}, nil
+		},
+	}
+	config.AddHostKey(host)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveConn(conn, config)
+		}
+	}()
+
+	return &server{listener.Addr().String(), host.PublicKey(), client}
+}
+
+// dial connects to the server, trusting its key.
+func (s *server) dial(t *testing.T, opts ...sshx.Option) *sshx.Client {
+	t.Helper()
+
+	opts = append([]sshx.Option{
+		sshx.WithUser("test"), sshx.WithSigner(s.client),
+		sshx.WithHostKeyPolicy(sshx.InsecureIgnoreHostKey()),
+	}, opts...)
+
+	client, err := sshx.Dial(t.Context(), s.addr, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go serveSession(channel, requests)
+	}
+}
+
+func serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	var cmd *exec.Cmd
+
+	for req := range requests {
+		switch req.Type {
+		case "exec":
+			cmd = exec.Command("sh", "-c", string(req.Payload[4:]))
+			_ = req.Reply(true, nil)
+
+			go execute(channel, cmd)
+		case "subsystem":
+			_ = req.Reply(true, nil)
+
+			go func() {
+				server, _ := sftp.NewServer(channel)
+				_ = server.Serve()
+				_ = channel.Close()
+			}()
+		case "signal":
+			if cmd != nil && cmd.Process != nil {
+				_ = cmd.Process.Kill()
+			}
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func execute(channel ssh.Channel, cmd *exec.Cmd) {
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = channel, channel, channel.Stderr()
+	_ = cmd.Run()
+
+	status := make([]byte, 4)
+	binary.BigEndian.PutUint32(status, uint32(cmd.ProcessState.ExitCode()))
+	_, _ = channel.SendRequest("exit-status", false, status)
+	_ = channel.Close()
+}
+
+func TestDial(t *testing.T) {
+	t.Parallel()
+
+	s := newServer(t)
+	client := s.dial(t)
+
+	result, err := client.Run(t.Context(), "echo hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", result.Stdout)
+
+	_, err = sshx.Dial(t.Context(), s.addr, sshx.WithUser("test"),
+		sshx.WithSigner(newSigner(t)),
+		sshx.WithHostKeyPolicy(sshx.InsecureIgnoreHostKey()))
+	require.Error(t, err, "unknown client key")
+}
+
+func TestDialKeyFile(t *testing.T) {
+	t.Parallel()
+
+	s := newServer(t)
+
+	_, err := sshx.Dial(t.Context(), s.addr,
+		sshx.WithKeyFile(t.TempDir()+"/missing"))
+	require.Error(t, err)
+
+	_, err = sshx.Dial(t.Context(), "127.0.0.1:1", sshx.WithUser("test"),
+		sshx.WithSigner(s.client),
+		sshx.WithHostKeyPolicy(sshx.InsecureIgnoreHostKey()))
+	require.Error(t, err, "connection refused")
+}
@@ -0,0 +1,137 @@
+package sshx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/hashx"
+)
+
+// Upload writes src to the remote file at dst with perm, atomically: the
+// data goes to a temporary file next to dst, whose SHA-256 digest is
+// checked with sha256sum on the host before it is renamed over dst. A
+// mismatch fails with hashx.ErrChecksumMismatch and leaves dst untouched.
+func (c *Client) Upload(
+	ctx context.Context, src io.Reader, dst string, perm fs.FileMode,
+) (err error) {
+	client, err := sftp.NewClient(c.conn)
+	if err != nil {
+		return fmt.Errorf("sshx: sftp: %w", err)
+	}
+	defer client.Close()
+
+	tmp := path.Join(path.Dir(dst),
+		"."+path.Base(dst)+".tmp-"+util.RandomString(8, tmpAlphabet))
+
+	defer func() {
+		if err != nil {
+			_ = client.Remove(tmp)
+		}
+	}()
+
+	sum, err := write(ctx, client, tmp, src)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Chmod(tmp, perm); err != nil {
+		return fmt.Errorf("sshx: chmod %s: %w", tmp, err)
+	}
+
+	if err := c.verify(ctx, tmp, sum); err != nil {
+		return err
+	}
+
+	if err := client.PosixRename(tmp, dst); err != nil {
+		return fmt.Errorf("sshx: rename %s: %w", dst, err)
+	}
+
+	return nil
+}
+
+// UploadFile uploads the local file src to dst, keeping its permission
+// bits.
+func (c *Client) UploadFile(ctx context.Context, src, dst string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("sshx: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("sshx: %w", err)
+	}
+
+	return c.Upload(ctx, file, dst, info.Mode().Perm())
+}
+
+const tmpAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// write copies src into the remote file name and returns its digest.
+func write(
+	ctx context.Context, client *sftp.Client, name string, src io.Reader,
+) (string, error) {
+	file, err := client.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return "", fmt.Errorf("sshx: sftp: %w", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), contextReader{ctx, src})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("sshx: upload %s: %w", name, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verify compares the digest of the remote file name with sum.
+func (c *Client) verify(ctx context.Context, name, sum string) error {
+	result, err := c.Run(ctx, "sha256sum -- "+shellQuote(name))
+	if err != nil {
+		return err
+	}
+
+	remote, _, _ := strings.Cut(result.Stdout, " ")
+	if !hashx.Equal(remote, sum) {
+		return fmt.Errorf("%w: %s is %s, want %s",
+			hashx.ErrChecksumMismatch, name, remote, sum)
+	}
+
+	return nil
+}
+
+// contextReader stops reading once ctx ends.
+type contextReader struct {
+	ctx context.Context //nolint:containedctx // Scoped to one upload.
+	r   io.Reader
+}
+
+// Read implements io.Reader.
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,58 @@
+package sshx_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/hashx"
+	"example.com/go-template/util/testx"
+)
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+
+	client := newServer(t).dial(t)
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "app.conf")
+
+	err := client.Upload(t.Context(), strings.NewReader("port=80\n"), dst,
+		0o640)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "port=80\n", string(data))
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left")
+}
+
+func TestUploadFile(t *testing.T) {
+	t.Parallel()
+
+	client := newServer(t).dial(t)
+	src := testx.TempDirWithFiles(t, map[string]string{"run.sh": "exit 0\n"})
+	require.NoError(t, os.Chmod(filepath.Join(src, "run.sh"), 0o755))
+
+	dst := filepath.Join(t.TempDir(), "run.sh")
+	require.NoError(t, client.UploadFile(t.Context(),
+		filepath.Join(src, "run.sh"), dst))
+	require.NoError(t, hashx.VerifyFile(dst, hashx.SHA256([]byte("exit 0\n"))))
+
+	err := client.UploadFile(t.Context(), filepath.Join(src, "missing"), dst)
+	require.Error(t, err)
+
+	err = client.Upload(t.Context(), strings.NewReader("x"),
+		filepath.Join(src, "missing-dir", "file"), 0o644)
+	require.Error(t, err)
+}
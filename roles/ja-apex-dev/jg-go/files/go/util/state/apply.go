@@ -0,0 +1,157 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"example.com/go-template/util/fsx"
+)
+
+// Apply brings the resources to their declared state, in order, and
+// returns the changes it performed. Each resource is planned right before
+// it is applied, so earlier resources are taken into account, and nothing
+// is touched when a resource is already in place. Apply stops at the first
+// failure, returning the changes made so far.
+func Apply(ctx context.Context, resources ...Resource) (Changes, error) {
+	var done Changes
+
+	for _, r := range resources {
+		if err := ctx.Err(); err != nil {
+			return done, fmt.Errorf("state: %w", err)
+		}
+
+		changes, err := diff(r)
+		if err != nil {
+			return done, err
+		}
+
+		for _, c := range changes {
+			if err := perform(c); err != nil {
+				return done, err
+			}
+
+			done = append(done, c)
+		}
+	}
+
+	return done, nil
+}
+
+// perform applies a single change.
+func perform(c Change) error {
+	var err error
+
+	switch r := c.resource; c.Action {
+	case ActionRemove:
+		err = os.RemoveAll(r.Path)
+	case ActionCreate:
+		err = create(r)
+	case ActionWrite:
+		err = write(r)
+	case ActionLink:
+		err = link(r)
+	case ActionChmod:
+		err = os.Chmod(r.Path, r.Mode)
+	case ActionChown:
+		err = os.Lchown(r.Path, c.owner.uid, c.owner.gid)
+	}
+
+	if err != nil {
+		return fmt.Errorf("state: %s %s: %w", c.Action, c.Path, err)
+	}
+
+	return nil
+}
+
+// create makes a missing file or directory with its parents.
+func create(r Resource) error {
+	if r.Kind == KindDir {
+		return fsx.EnsureDir(r.Path, r.modeOr())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.Path), DefaultDirMode); err != nil {
+		return err
+	}
+
+	return fsx.AtomicWriteFile(r.Path, r.Content, r.modeOr())
+}
+
+// write replaces the content of an existing file. The replacement is a new
+// file, so the mode and ownership of the old one are carried over unless
+// declared otherwise.
+func write(r Resource) error {
+	info, err := os.Lstat(r.Path)
+	if err != nil {
+		return err
+	}
+
+	perm := info.Mode().Perm()
+	if r.Mode != 0 {
+		perm = r.Mode
+	}
+
+	if err := fsx.AtomicWriteFile(r.Path, r.Content, perm); err != nil {
+		return err
+	}
+
+	return keepOwner(r.Path, info)
+}
+
+// link points a symbolic link at its target, replacing an existing link
+// atomically through a temporary one renamed over it.
+func link(r Resource) error {
+	dir := filepath.Dir(r.Path)
+	if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, "."+filepath.Base(r.Path)+".tmp-link")
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Symlink(r.Target, tmp); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, r.Path); err != nil {
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	return nil
+}
+
+// ids are numeric user and group IDs, where -1 stands for any.
+type ids struct {
+	uid int
+	gid int
+}
+
+// matches reports whether current satisfies the wanted ids.
+func (want ids) matches(current ids) bool {
+	return (want.uid < 0 || want.uid == current.uid) &&
+		(want.gid < 0 || want.gid == current.gid)
+}
+
+// keepOwner gives path the ownership recorded in info, when it differs.
+// Systems without Unix ownership have nothing to keep.
+func keepOwner(path string, info fs.FileInfo) error {
+	current, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	previous, known := ownerOf(info)
+	now, _ := ownerOf(current)
+
+	if known != nil || previous.matches(now) {
+		return nil
+	}
+
+	return os.Lchown(path, previous.uid, previous.gid)
+}
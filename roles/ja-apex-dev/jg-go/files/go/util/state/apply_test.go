@@ -0,0 +1,131 @@
+package state_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/state"
+	"example.com/go-template/util/testx"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"app.conf": "old", "junk/file": "",
+	})
+	require.NoError(t, os.Chmod(filepath.Join(dir, "app.conf"), 0o640))
+	require.NoError(t, os.Symlink("nowhere", filepath.Join(dir, "current")))
+
+	resources := []state.Resource{
+		state.Dir(filepath.Join(dir, "etc/app"), state.WithMode(0o750)),
+		state.File(filepath.Join(dir, "etc/app/env"), []byte("A=1\n"),
+			state.WithMode(0o600)),
+		state.File(filepath.Join(dir, "app.conf"), []byte("new")),
+		state.Symlink(filepath.Join(dir, "current"), "etc/app"),
+		state.Absent(filepath.Join(dir, "junk")),
+	}
+
+	changes, err := state.Apply(t.Context(), resources...)
+	require.NoError(t, err)
+	assert.Len(t, changes, 5)
+
+	assertMode(t, filepath.Join(dir, "etc/app"), 0o750)
+	assertMode(t, filepath.Join(dir, "etc/app/env"), 0o600)
+	assertMode(t, filepath.Join(dir, "app.conf"), 0o640)
+
+	data, err := os.ReadFile(filepath.Join(dir, "current/env"))
+	require.NoError(t, err)
+	assert.Equal(t, "A=1\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "junk/file"))
+
+	changes, err = state.Apply(t.Context(), resources...)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "a second run changes nothing")
+
+	changes, err = state.Plan(resources...)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestApplyChmod(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{"bin/tool": "x"})
+	path := filepath.Join(dir, "bin/tool")
+
+	changes, err := state.Apply(t.Context(),
+		state.File(path, nil, state.WithMode(0o755)))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, state.ActionChmod, changes[0].Action)
+	assertMode(t, path, 0o755)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(data), "nil content is left alone")
+}
+
+func TestApplyThroughSymlink(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"dotfiles/bashrc": "old",
+	})
+	link := filepath.Join(dir, ".bashrc")
+	require.NoError(t, os.Symlink("dotfiles/bashrc", link))
+	require.NoError(t, os.Symlink("missing", filepath.Join(dir, "dangling")))
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(dir, "dotfiles"))
+	require.NoError(t, err)
+
+	changes, err := state.Apply(t.Context(), state.File(link, []byte("new")),
+		state.Dir(filepath.Join(dir, "dotfiles")))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, filepath.Join(resolved, "bashrc"), changes[0].Path)
+
+	target, err := os.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "dotfiles/bashrc", target, "the link stays")
+
+	data, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	_, err = state.Plan(state.File(filepath.Join(dir, "dangling"), nil))
+	require.ErrorIs(t, err, state.ErrConflict)
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{"file": ""})
+
+	changes, err := state.Apply(t.Context(),
+		state.Dir(filepath.Join(dir, "ok")),
+		state.Dir(filepath.Join(dir, "file")),
+		state.Dir(filepath.Join(dir, "never")))
+	require.ErrorIs(t, err, state.ErrConflict)
+	assert.Len(t, changes, 1)
+	assert.NoDirExists(t, filepath.Join(dir, "never"))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = state.Apply(ctx, state.Dir(filepath.Join(dir, "x")))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, info.Mode().Perm(), path)
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package state
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// errNoOwner is returned on systems without Unix ownership.
+var errNoOwner = errors.New("state: ownership is not supported")
+
+func ownerOf(fs.FileInfo) (ids, error) {
+	return ids{}, errNoOwner
+}
+
+func lookup(string, string) (ids, error) {
+	return ids{}, errNoOwner
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// errNoOwner is returned for file information without ownership.
+var errNoOwner = errors.New("state: ownership not available")
+
+// ownerOf returns the owner and group of a file.
+func ownerOf(info fs.FileInfo) (ids, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ids{}, errNoOwner
+	}
+
+	return ids{int(stat.Uid), int(stat.Gid)}, nil
+}
+
+// lookup resolves user and group names or numeric IDs. Empty names map
+// to -1, which leaves them unchanged.
+func lookup(owner, group string) (ids, error) {
+	uid, err := resolve(owner, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+
+		return u.Uid, nil
+	})
+	if err != nil {
+		return ids{}, err
+	}
+
+	gid, err := resolve(group, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+
+		return g.Gid, nil
+	})
+	if err != nil {
+		return ids{}, err
+	}
+
+	return ids{uid, gid}, nil
+}
+
+// resolve turns a name into an ID with find, accepting numeric IDs as is.
+func resolve(name string, find func(string) (string, error)) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	id, err := find(name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrUnknownOwner, name, err)
+	}
+
+	return strconv.Atoi(id)
+}
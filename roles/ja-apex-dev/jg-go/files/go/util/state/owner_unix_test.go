@@ -0,0 +1,45 @@
+//go:build unix
+
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/state"
+	"example.com/go-template/util/testx"
+)
+
+func TestOwner(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{"file": "x"})
+	path := filepath.Join(dir, "file")
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+
+	changes, err := state.Plan(state.File(path, nil,
+		state.WithOwner(uid, gid)))
+	require.NoError(t, err)
+	assert.Empty(t, changes, "numeric IDs of the current owner match")
+
+	changes, err = state.Plan(state.File(filepath.Join(dir, "new"), nil,
+		state.WithOwner(uid, "")))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "chown "+filepath.Join(dir, "new")+" ("+uid+":)",
+		changes[1].String())
+
+	changes, err = state.Apply(t.Context(), state.File(filepath.Join(dir,
+		"new"), nil, state.WithOwner(uid, gid)))
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+
+	_, err = state.Plan(state.File(path, nil,
+		state.WithOwner("no-such-user-here", "")))
+	require.ErrorIs(t, err, state.ErrUnknownOwner)
+}
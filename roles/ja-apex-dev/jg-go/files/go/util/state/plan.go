@@ -0,0 +1,280 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action is the kind of a change.
+type Action int
+
+// Actions, in the order Apply performs them for a resource.
+const (
+	ActionRemove Action = iota
+	ActionCreate
+	ActionWrite
+	ActionLink
+	ActionChmod
+	ActionChown
+)
+
+var actionNames = [...]string{"remove", "create", "write", "link", "chmod",
+	"chown"}
+
+// String returns the lowercase name of the action.
+func (a Action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return "unknown"
+	}
+
+	return actionNames[a]
+}
+
+// MarshalText implements encoding.TextMarshaler, so that plans print
+// action names in JSON.
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Change is a single operation needed to reach the declared state.
+type Change struct {
+	Action Action `json:"action"`
+	Path   string `json:"path"`
+	// Detail describes the new state, such as "0644" or "root:root".
+	Detail string `json:"detail,omitempty"`
+
+	resource Resource
+	owner    ids
+}
+
+// String formats the change as "action path (detail)".
+func (c Change) String() string {
+	if c.Detail == "" {
+		return c.Action.String() + " " + c.Path
+	}
+
+	return fmt.Sprintf("%s %s (%s)", c.Action, c.Path, c.Detail)
+}
+
+// Changes is the plan of a set of resources.
+type Changes []Change
+
+// String prints one change per line.
+func (c Changes) String() string {
+	lines := make([]string, len(c))
+	for i, change := range c {
+		lines[i] = change.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Plan returns the changes the resources need without performing them.
+// Resources are planned against the current filesystem, so a plan for a
+// file inside a directory that is yet to be created reports creating both.
+func Plan(resources ...Resource) (Changes, error) {
+	var changes Changes
+
+	for _, r := range resources {
+		planned, err := diff(r)
+		if err != nil {
+			return changes, err
+		}
+
+		changes = append(changes, planned...)
+	}
+
+	return changes, nil
+}
+
+// diff returns the changes of a single resource.
+func diff(r Resource) (Changes, error) {
+	r, info, err := stat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return planMissing(r)
+	}
+
+	if r.Kind == KindAbsent {
+		return Changes{{Action: ActionRemove, Path: r.Path, resource: r}}, nil
+	}
+
+	if kindOf(info) != r.Kind {
+		return nil, fmt.Errorf("%w: %s is a %s, not a %s",
+			ErrConflict, r.Path, kindOf(info), r.Kind)
+	}
+
+	changes, err := planContent(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Mode != 0 && r.Kind != KindSymlink && info.Mode().Perm() != r.Mode {
+		changes = append(changes, change(ActionChmod, r, mode(r.Mode)))
+	}
+
+	return planOwner(r, info, changes)
+}
+
+// stat returns the file info of the path of r, nil when it is missing,
+// and r redirected by follow.
+func stat(r Resource) (Resource, fs.FileInfo, error) {
+	info, err := os.Lstat(r.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil, nil
+	}
+
+	if err != nil {
+		return r, nil, fmt.Errorf("state: %w", err)
+	}
+
+	return follow(r, info)
+}
+
+// follow redirects a file or directory declared on a symbolic link, such
+// as a ~/.bashrc kept in a dotfiles repository, to the path the link
+// resolves to, so that the link stays in place. Dangling links are left
+// to conflict.
+func follow(r Resource, info fs.FileInfo) (Resource, fs.FileInfo, error) {
+	if kindOf(info) != KindSymlink ||
+		(r.Kind != KindFile && r.Kind != KindDir) {
+		return r, info, nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(r.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, info, nil
+	}
+
+	if err == nil {
+		info, err = os.Stat(resolved)
+	}
+
+	if err != nil {
+		return r, nil, fmt.Errorf("state: %w", err)
+	}
+
+	r.Path = resolved
+
+	return r, info, nil
+}
+
+// planMissing returns the changes creating a resource.
+func planMissing(r Resource) (Changes, error) {
+	var changes Changes
+
+	switch r.Kind {
+	case KindAbsent:
+		return nil, nil
+	case KindSymlink:
+		changes = Changes{change(ActionLink, r, "-> "+r.Target)}
+	default:
+		changes = Changes{change(ActionCreate, r, r.Kind.String()+" "+
+			mode(r.modeOr()))}
+	}
+
+	return planOwner(r, nil, changes)
+}
+
+// planContent compares the content of an existing file or the target of an
+// existing link.
+func planContent(r Resource) (Changes, error) {
+	switch {
+	case r.Kind == KindFile && r.Content != nil:
+		current, err := os.ReadFile(r.Path)
+		if err != nil {
+			return nil, fmt.Errorf("state: %w", err)
+		}
+
+		if bytes.Equal(current, r.Content) {
+			return nil, nil
+		}
+
+		detail := fmt.Sprintf("%d bytes", len(r.Content))
+
+		return Changes{change(ActionWrite, r, detail)}, nil
+	case r.Kind == KindSymlink:
+		target, err := os.Readlink(r.Path)
+		if err != nil {
+			return nil, fmt.Errorf("state: %w", err)
+		}
+
+		if target == r.Target {
+			return nil, nil
+		}
+
+		return Changes{change(ActionLink, r, "-> "+r.Target)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// planOwner appends the chown needed by r, if any, to changes. A nil info
+// stands for a path that is yet to be created.
+func planOwner(r Resource, info fs.FileInfo, changes Changes) (Changes, error) {
+	if r.Owner == "" && r.Group == "" {
+		return changes, nil
+	}
+
+	want, err := lookup(r.Owner, r.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	if info != nil {
+		current, err := ownerOf(info)
+		if err != nil {
+			return nil, err
+		}
+
+		if want.matches(current) {
+			return changes, nil
+		}
+	}
+
+	c := change(ActionChown, r, r.Owner+":"+r.Group)
+	c.owner = want
+
+	return append(changes, c), nil
+}
+
+func change(action Action, r Resource, detail string) Change {
+	return Change{Action: action, Path: r.Path, Detail: detail, resource: r}
+}
+
+// modeOr returns the declared mode or the default of the kind.
+func (r Resource) modeOr() fs.FileMode {
+	switch {
+	case r.Mode != 0:
+		return r.Mode
+	case r.Kind == KindDir:
+		return DefaultDirMode
+	default:
+		return DefaultFileMode
+	}
+}
+
+func kindOf(info fs.FileInfo) Kind {
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		return KindSymlink
+	case info.IsDir():
+		return KindDir
+	case info.Mode().IsRegular():
+		return KindFile
+	default:
+		return -1
+	}
+}
+
+func mode(m fs.FileMode) string {
+	return fmt.Sprintf("%04o", uint32(m.Perm()))
+}
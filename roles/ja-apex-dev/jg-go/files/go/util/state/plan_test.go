@@ -0,0 +1,78 @@
+package state_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/state"
+	"example.com/go-template/util/testx"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"same.conf": "x", "old.conf": "old", "junk/file": "",
+	})
+	for _, name := range []string{"same.conf", "old.conf"} {
+		require.NoError(t, os.Chmod(filepath.Join(dir, name), 0o644))
+	}
+
+	require.NoError(t, os.Symlink("same.conf", filepath.Join(dir, "link")))
+
+	changes, err := state.Plan(
+		state.File(filepath.Join(dir, "same.conf"), []byte("x"),
+			state.WithMode(0o644)),
+		state.File(filepath.Join(dir, "old.conf"), []byte("new"),
+			state.WithMode(0o600)),
+		state.File(filepath.Join(dir, "new.conf"), nil),
+		state.Dir(filepath.Join(dir, "sub"), state.WithMode(0o700)),
+		state.Symlink(filepath.Join(dir, "link"), "old.conf"),
+		state.Absent(filepath.Join(dir, "junk")),
+		state.Absent(filepath.Join(dir, "missing")),
+	)
+	require.NoError(t, err)
+
+	want := []string{
+		"write " + dir + "/old.conf (3 bytes)",
+		"chmod " + dir + "/old.conf (0600)",
+		"create " + dir + "/new.conf (file 0644)",
+		"create " + dir + "/sub (directory 0700)",
+		"link " + dir + "/link (-> old.conf)",
+		"remove " + dir + "/junk",
+	}
+	assert.Equal(t, filepath.FromSlash(strings.Join(want, "\n")),
+		changes.String())
+
+	data, err := json.Marshal(changes[5])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"remove","path":`+
+		quote(t, filepath.Join(dir, "junk"))+`}`, string(data))
+}
+
+func TestPlanConflict(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{"file": ""})
+
+	_, err := state.Plan(state.Dir(filepath.Join(dir, "file")))
+	require.ErrorIs(t, err, state.ErrConflict)
+
+	_, err = state.Plan(state.Symlink(dir, "x"))
+	require.ErrorIs(t, err, state.ErrConflict)
+}
+
+func quote(t *testing.T, s string) string {
+	t.Helper()
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	return string(data)
+}
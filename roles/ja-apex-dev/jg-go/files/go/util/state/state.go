@@ -0,0 +1,123 @@
+// Package state ensures that files, directories and symbolic links are in
+// a declared state, idempotently. Plan compares the resources with the
+// filesystem and lists the changes they need, as a dry run; Apply performs
+// those changes and nothing else. Small helper binaries use it in place of
+// chains of shell tasks that are hard to make idempotent.
+//
+// Files and directories declared on symbolic links are managed through
+// them: the changes apply to the resolved paths and the links stay.
+package state
+
+import (
+	"errors"
+	"io/fs"
+)
+
+var (
+	// ErrConflict is returned when a path exists with another type than
+	// declared, such as a file where a directory is expected. Conflicts
+	// are never resolved by deleting data; declare the path Absent first.
+	ErrConflict = errors.New("state: conflicting file type")
+	// ErrUnknownOwner is returned for users and groups that do not exist.
+	ErrUnknownOwner = errors.New("state: unknown owner")
+)
+
+// Default modes of created files and directories.
+const (
+	DefaultFileMode fs.FileMode = 0o644
+	DefaultDirMode  fs.FileMode = 0o755
+)
+
+// Kind is the type of a resource.
+type Kind int
+
+// Resource kinds.
+const (
+	KindFile Kind = iota
+	KindDir
+	KindSymlink
+	KindAbsent
+)
+
+// String returns the lowercase name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindFile:
+		return "file"
+	case KindDir:
+		return "directory"
+	case KindSymlink:
+		return "symlink"
+	case KindAbsent:
+		return "absent"
+	default:
+		return "unknown"
+	}
+}
+
+// Resource is the declared state of a path.
+type Resource struct {
+	Kind Kind
+	Path string
+	// Content is the data of a file. Nil leaves the content of existing
+	// files alone and creates empty ones.
+	Content []byte
+	// Target is the destination of a symbolic link.
+	Target string
+	// Mode holds the permission bits of a file or directory. Zero keeps
+	// the mode of existing paths and uses the defaults for new ones.
+	Mode fs.FileMode
+	// Owner and Group are names or numeric IDs; empty leaves them
+	// unchanged.
+	Owner string
+	Group string
+}
+
+// Attr sets an optional attribute of a resource.
+type Attr func(*Resource)
+
+// WithMode sets the permission bits.
+func WithMode(mode fs.FileMode) Attr {
+	return func(r *Resource) {
+		r.Mode = mode.Perm()
+	}
+}
+
+// WithOwner sets the owning user and group, either of which may be empty.
+func WithOwner(owner, group string) Attr {
+	return func(r *Resource) {
+		r.Owner = owner
+		r.Group = group
+	}
+}
+
+// File declares a regular file holding content.
+func File(path string, content []byte, attrs ...Attr) Resource {
+	return build(Resource{Kind: KindFile, Path: path, Content: content}, attrs)
+}
+
+// Dir declares a directory, created with its parents when missing.
+func Dir(path string, attrs ...Attr) Resource {
+	return build(Resource{Kind: KindDir, Path: path}, attrs)
+}
+
+// Symlink declares a symbolic link to target. Only WithOwner applies to
+// links.
+func Symlink(path, target string, attrs ...Attr) Resource {
+	return build(Resource{Kind: KindSymlink, Path: path, Target: target},
+		attrs)
+}
+
+// Absent declares that nothing exists at path, removing directories with
+// their content.
+func Absent(path string) Resource {
+	return Resource{Kind: KindAbsent, Path: path}
+}
+
+func build(r Resource, attrs []Attr) Resource {
+	for _, attr := range attrs {
+		attr(&r)
+	}
+
+	return r
+}
@@ -0,0 +1,27 @@
+package state_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/state"
+)
+
+func TestConstructors(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, state.Resource{
+		Kind: state.KindFile, Path: "a", Content: []byte("x"), Mode: 0o600,
+		Owner: "root", Group: "wheel",
+	}, state.File("a", []byte("x"), state.WithMode(0o4600),
+		state.WithOwner("root", "wheel")))
+
+	assert.Equal(t, state.Resource{Kind: state.KindDir, Path: "d"},
+		state.Dir("d"))
+	assert.Equal(t, state.Resource{
+		Kind: state.KindSymlink, Path: "l", Target: "t",
+	}, state.Symlink("l", "t"))
+	assert.Equal(t, "absent", state.Absent("x").Kind.String())
+	assert.Equal(t, "unknown", state.Kind(9).String())
+}
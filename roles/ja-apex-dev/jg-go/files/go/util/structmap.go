@@ -0,0 +1,578 @@
+package util
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotStruct is returned by ToMap for values other than structs and
+	// struct pointers.
+	ErrNotStruct = errors.New("util: value must be a struct")
+	// ErrInvalidTarget is returned by FromMap for targets other than
+	// non-nil struct pointers.
+	ErrInvalidTarget = errors.New("util: target must be a struct pointer")
+	// ErrConvert is returned by FromMap for values not assignable to their
+	// field.
+	ErrConvert = errors.New("util: cannot convert value")
+)
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// ToMap converts the struct v, or the struct it points to, into a tree of
+// map[string]any and []any keyed like encoding/json would: fields are
+// named by their json tag, "-" hides them, omitempty and omitzero drop
+// empty values and untagged embedded structs are flattened. Values
+// implementing encoding.TextMarshaler, such as time.Time, become strings;
+// other values keep their Go type instead of becoming float64 as after a
+// JSON round-trip.
+func ToMap(v any) (map[string]any, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w, got %T", ErrNotStruct, v)
+	}
+
+	return structToMap(value)
+}
+
+// FromMap fills the struct pointed to by target from m, the reverse of
+// ToMap. Keys are matched to json names ignoring case and unknown keys are
+// skipped. Strings are decoded into encoding.TextUnmarshaler fields,
+// numbers are converted between numeric kinds when they fit, and nested
+// maps and lists fill structs, maps and slices.
+func FromMap(m map[string]any, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() ||
+		value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w, got %T", ErrInvalidTarget, target)
+	}
+
+	return mapToStruct(m, value.Elem(), "")
+}
+
+// jsonField is a field of a struct as seen by encoding/json.
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	omitZero  bool
+}
+
+// jsonFields lists the fields of t, promoting the fields of untagged
+// embedded structs unless shadowed by a shallower field of the same name.
+func jsonFields(t reflect.Type) []jsonField {
+	return embeddedFields(t, map[reflect.Type]bool{})
+}
+
+// embeddedFields is jsonFields for a struct embedded in the types of
+// seen, whose fields are not promoted again so that a type embedding a
+// pointer to itself ends.
+func embeddedFields(t reflect.Type, seen map[reflect.Type]bool) []jsonField {
+	if seen[t] {
+		return nil
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	var fields []jsonField
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		tag, hasTag := sf.Tag.Lookup("json")
+
+		switch {
+		case tag == "-":
+		case sf.Anonymous && !hasTag &&
+			indirectType(sf.Type).Kind() == reflect.Struct:
+			fields = append(fields, promoted(sf, i, seen)...)
+		case sf.IsExported():
+			fields = append(fields, taggedField(sf, i, tag))
+		}
+	}
+
+	return shallowest(fields)
+}
+
+// promoted lists the fields of the embedded struct sf, found at index i.
+func promoted(
+	sf reflect.StructField, i int, seen map[reflect.Type]bool,
+) []jsonField {
+	fields := embeddedFields(indirectType(sf.Type), seen)
+	for j := range fields {
+		fields[j].index = append([]int{i}, fields[j].index...)
+	}
+
+	return fields
+}
+
+func taggedField(sf reflect.StructField, i int, tag string) jsonField {
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+
+	f := jsonField{name: name, index: []int{i}}
+
+	for option := range strings.SplitSeq(options, ",") {
+		f.omitEmpty = f.omitEmpty || option == "omitempty"
+		f.omitZero = f.omitZero || option == "omitzero"
+	}
+
+	return f
+}
+
+// shallowest keeps, for every name, the field with the shortest index.
+func shallowest(fields []jsonField) []jsonField {
+	best := make(map[string]int, len(fields))
+	kept := fields[:0]
+
+	for _, f := range fields {
+		i, seen := best[f.name]
+
+		switch {
+		case !seen:
+			best[f.name] = len(kept)
+			kept = append(kept, f)
+		case len(f.index) < len(kept[i].index):
+			kept[i] = f
+		}
+	}
+
+	return kept
+}
+
+func structToMap(value reflect.Value) (map[string]any, error) {
+	fields := jsonFields(value.Type())
+	result := make(map[string]any, len(fields))
+
+	for _, f := range fields {
+		field, err := value.FieldByIndexErr(f.index)
+		if err != nil || f.omitEmpty && isEmpty(field) ||
+			f.omitZero && field.IsZero() {
+			continue
+		}
+
+		converted, err := toTree(field)
+		if err != nil {
+			return nil, fmt.Errorf("util: field %s: %w", f.name, err)
+		}
+
+		result[f.name] = converted
+	}
+
+	return result, nil
+}
+
+// toTree converts a field value into its map representation.
+func toTree(value reflect.Value) (any, error) {
+	if isNil(value) {
+		return nil, nil //nolint:nilnil // A nil field is a valid nil value.
+	}
+
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("marshal text: %w", err)
+		}
+
+		return string(text), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return toTree(value.Elem())
+	case reflect.Struct:
+		return structToMap(value)
+	case reflect.Map:
+		return mapToTree(value)
+	case reflect.Slice, reflect.Array:
+		return sliceToTree(value)
+	default:
+		return value.Interface(), nil
+	}
+}
+
+func mapToTree(value reflect.Value) (any, error) {
+	result := make(map[string]any, value.Len())
+
+	for iter := value.MapRange(); iter.Next(); {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		converted, err := toTree(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = converted
+	}
+
+	return result, nil
+}
+
+// mapKey formats a map key as encoding/json would.
+func mapKey(key reflect.Value) (string, error) {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("%w: map key of type %s", ErrConvert,
+			key.Type())
+	}
+}
+
+func sliceToTree(value reflect.Value) (any, error) {
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		// Byte slices are data rather than lists, as in encoding/json.
+		return value.Interface(), nil
+	}
+
+	result := make([]any, value.Len())
+
+	for i := range value.Len() {
+		converted, err := toTree(value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = converted
+	}
+
+	return result, nil
+}
+
+func mapToStruct(m map[string]any, value reflect.Value, path string) error {
+	for _, f := range jsonFields(value.Type()) {
+		raw, ok := lookupFold(m, f.name)
+		if !ok {
+			continue
+		}
+
+		field, ok := fieldAlloc(value, f.index)
+		if !ok {
+			continue
+		}
+
+		if err := assign(field, raw, joinPath(path, f.name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookupFold finds key in m, preferring an exact match over a case
+// insensitive one.
+func lookupFold(m map[string]any, key string) (any, bool) {
+	if value, ok := m[key]; ok {
+		return value, true
+	}
+
+	for candidate, value := range m {
+		if strings.EqualFold(candidate, key) {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// fieldAlloc returns the field at index, allocating nil embedded struct
+// pointers on the way. It reports false when such a pointer is
+// unexported and cannot be allocated.
+func fieldAlloc(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, step := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if !allocate(value) {
+				return reflect.Value{}, false
+			}
+
+			value = value.Elem()
+		}
+
+		value = value.Field(step)
+	}
+
+	return value, true
+}
+
+// allocate points the nil pointer ptr to a new zero value, reporting
+// whether ptr is usable.
+func allocate(ptr reflect.Value) bool {
+	switch {
+	case !ptr.IsNil():
+		return true
+	case !ptr.CanSet():
+		return false
+	default:
+		ptr.Set(reflect.New(ptr.Type().Elem()))
+
+		return true
+	}
+}
+
+// assign stores raw into dst, converting it as described by FromMap.
+func assign(dst reflect.Value, raw any, path string) error {
+	if raw == nil {
+		dst.SetZero()
+
+		return nil
+	}
+
+	src := reflect.ValueOf(raw)
+
+	if ok, err := assignDirect(dst, src); err != nil {
+		return fmt.Errorf("util: field %s: %w", path, err)
+	} else if ok {
+		return nil
+	}
+
+	return assignNested(dst, src, path)
+}
+
+// assignNested fills pointers, structs, slices and maps from raw.
+func assignNested(dst, src reflect.Value, path string) error {
+	switch dst.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(dst.Type().Elem())
+		if err := assign(elem.Elem(), src.Interface(), path); err != nil {
+			return err
+		}
+
+		dst.Set(elem)
+
+		return nil
+	case reflect.Struct:
+		if m, ok := src.Interface().(map[string]any); ok {
+			return mapToStruct(m, dst, path)
+		}
+	case reflect.Slice:
+		return assignSlice(dst, src, path)
+	case reflect.Map:
+		return assignMap(dst, src, path)
+	}
+
+	return convertError(dst, src.Interface(), path)
+}
+
+// assignDirect handles the values needing no recursion: assignable ones,
+// text and numeric conversions.
+func assignDirect(dst, src reflect.Value) (bool, error) {
+	switch {
+	case src.Type().AssignableTo(dst.Type()):
+		dst.Set(src)
+
+		return true, nil
+	case src.Kind() == reflect.String &&
+		reflect.PointerTo(dst.Type()).Implements(textUnmarshalerType):
+		unmarshaler, _ := dst.Addr().Interface().(encoding.TextUnmarshaler)
+
+		return true, unmarshaler.UnmarshalText([]byte(src.String()))
+	case isNumber(src.Kind()) && isNumber(dst.Kind()):
+		return convertNumber(dst, src), nil
+	case src.Kind() == dst.Kind() && src.Type().ConvertibleTo(dst.Type()):
+		// Named types sharing a kind, such as a string enum.
+		dst.Set(src.Convert(dst.Type()))
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func assignSlice(dst, src reflect.Value, path string) error {
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		return convertError(dst, src.Interface(), path)
+	}
+
+	result := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+
+	for i := range src.Len() {
+		err := assign(result.Index(i), src.Index(i).Interface(),
+			fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return err
+		}
+	}
+
+	dst.Set(result)
+
+	return nil
+}
+
+func assignMap(dst, src reflect.Value, path string) error {
+	if src.Kind() != reflect.Map || dst.Type().Key().Kind() != reflect.String {
+		return convertError(dst, src.Interface(), path)
+	}
+
+	result := reflect.MakeMapWithSize(dst.Type(), src.Len())
+
+	for iter := src.MapRange(); iter.Next(); {
+		key := fmt.Sprint(iter.Key().Interface())
+		elem := reflect.New(dst.Type().Elem()).Elem()
+
+		if err := assign(elem, iter.Value().Interface(),
+			joinPath(path, key)); err != nil {
+			return err
+		}
+
+		result.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()),
+			elem)
+	}
+
+	dst.Set(result)
+
+	return nil
+}
+
+// convertNumber converts between numeric kinds, reporting false when the
+// value does not fit or loses its fraction.
+func convertNumber(dst, src reflect.Value) bool {
+	switch {
+	case dst.CanInt():
+		v, ok := toInt(src)
+		ok = ok && !dst.OverflowInt(v)
+
+		if ok {
+			dst.SetInt(v)
+		}
+
+		return ok
+	case dst.CanUint():
+		v, ok := toUint(src)
+		ok = ok && !dst.OverflowUint(v)
+
+		if ok {
+			dst.SetUint(v)
+		}
+
+		return ok
+	default:
+		return setFloat(dst, src)
+	}
+}
+
+// setFloat sets the float dst to src when it is exact.
+func setFloat(dst, src reflect.Value) bool {
+	v, ok := toFloat(src)
+	if !ok || dst.OverflowFloat(v) ||
+		dst.Kind() == reflect.Float32 && float64(float32(v)) != v {
+		return false
+	}
+
+	dst.SetFloat(v)
+
+	return true
+}
+
+// toInt returns the numeric src as an int64 when it is a whole number in
+// range.
+func toInt(src reflect.Value) (int64, bool) {
+	switch {
+	case src.CanInt():
+		return src.Int(), true
+	case src.CanUint():
+		return int64(src.Uint()), src.Uint() <= math.MaxInt64
+	default:
+		f := src.Float()
+
+		return int64(f), f == math.Trunc(f) && f >= math.MinInt64 &&
+			f < math.MaxInt64
+	}
+}
+
+// toUint returns the numeric src as a uint64 when it is a non-negative
+// whole number in range.
+func toUint(src reflect.Value) (uint64, bool) {
+	switch {
+	case src.CanInt():
+		return uint64(src.Int()), src.Int() >= 0
+	case src.CanUint():
+		return src.Uint(), true
+	default:
+		f := src.Float()
+
+		return uint64(f), f == math.Trunc(f) && f >= 0 && f < math.MaxUint64
+	}
+}
+
+// toFloat returns the numeric src as a float64 when it is exact.
+func toFloat(src reflect.Value) (float64, bool) {
+	switch {
+	case src.CanInt():
+		f := float64(src.Int())
+
+		return f, f < math.MaxInt64 && int64(f) == src.Int()
+	case src.CanUint():
+		f := float64(src.Uint())
+
+		return f, f < math.MaxUint64 && uint64(f) == src.Uint()
+	default:
+		return src.Float(), true
+	}
+}
+
+func convertError(dst reflect.Value, raw any, path string) error {
+	return fmt.Errorf("%w: field %s: %T into %s", ErrConvert, path, raw,
+		dst.Type())
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+func isNil(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return value.IsNil()
+	default:
+		return !value.IsValid()
+	}
+}
+
+// isEmpty reports whether omitempty drops value, as in encoding/json.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Pointer,
+		reflect.Interface:
+		return value.IsZero()
+	default:
+		return false
+	}
+}
+
+// indirectType returns the type t points to, or t itself.
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,192 @@
+package util_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+)
+
+type level string
+
+type Meta struct {
+	Owner string `json:"owner"`
+	Name  string `json:"shadowed"`
+}
+
+type endpoint struct {
+	*Meta
+
+	Name    string            `json:"name"`
+	Port    int               `json:"port,omitempty"`
+	Addr    netip.Addr        `json:"addr"`
+	Created time.Time         `json:"created"`
+	Level   level             `json:"level"`
+	Tags    []string          `json:"tags,omitempty"`
+	Labels  map[string]string `json:"labels"`
+	Backup  *endpoint         `json:"backup"`
+	Secret  string            `json:"-"`
+	Plain   bool
+	private int
+}
+
+var created = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func sample() endpoint {
+	return endpoint{
+		Meta:    &Meta{Owner: "ops", Name: "hidden"},
+		Name:    "api",
+		Port:    8080,
+		Addr:    netip.MustParseAddr("10.0.0.1"),
+		Created: created,
+		Level:   "debug",
+		Labels:  map[string]string{"env": "prod"},
+		Backup:  &endpoint{Name: "standby"},
+		Secret:  "hunter2",
+		Plain:   true,
+		private: 1,
+	}
+}
+
+func TestToMap(t *testing.T) {
+	t.Parallel()
+
+	srv := sample()
+
+	got, err := util.ToMap(&srv)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"owner":    "ops",
+		"shadowed": "hidden",
+		"name":     "api",
+		"port":     8080,
+		"addr":     "10.0.0.1",
+		"created":  "2026-10-14T12:00:00Z",
+		"level":    level("debug"),
+		"labels":   map[string]any{"env": "prod"},
+		"backup": map[string]any{
+			"name":    "standby",
+			"addr":    "",
+			"created": "0001-01-01T00:00:00Z",
+			"level":   level(""),
+			"labels":  nil,
+			"backup":  nil,
+			"Plain":   false,
+		},
+		"Plain": true,
+	}, got)
+
+	_, err = util.ToMap([]int{1})
+	require.ErrorIs(t, err, util.ErrNotStruct)
+}
+
+func TestFromMap(t *testing.T) {
+	t.Parallel()
+
+	want := sample()
+	want.Secret, want.private = "", 0
+
+	tree, err := util.ToMap(want)
+	require.NoError(t, err)
+
+	var got endpoint
+	require.NoError(t, util.FromMap(tree, &got))
+	assert.Equal(t, want, got)
+}
+
+func TestFromMapConversions(t *testing.T) {
+	t.Parallel()
+
+	var got endpoint
+	require.NoError(t, util.FromMap(map[string]any{
+		"NAME":    "api",
+		"port":    float64(443),
+		"created": created,
+		"tags":    []any{"a", "b"},
+		"labels":  map[string]any{"env": "dev"},
+		"level":   "info",
+		"backup":  nil,
+	}, &got))
+
+	assert.Equal(t, endpoint{
+		Name:    "api",
+		Port:    443,
+		Created: created,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"env": "dev"},
+		Level:   "info",
+	}, got)
+}
+
+func TestFromMapErrors(t *testing.T) {
+	t.Parallel()
+
+	var got endpoint
+
+	require.ErrorIs(t, util.FromMap(nil, got), util.ErrInvalidTarget)
+
+	err := util.FromMap(map[string]any{"port": 1.5}, &got)
+	require.ErrorIs(t, err, util.ErrConvert)
+	assert.ErrorContains(t, err, "port")
+
+	err = util.FromMap(map[string]any{
+		"backup": map[string]any{"tags": []any{"a", 2}},
+	}, &got)
+	require.ErrorIs(t, err, util.ErrConvert)
+	assert.ErrorContains(t, err, "backup.tags[1]")
+
+	err = util.FromMap(map[string]any{"addr": "not-an-ip"}, &got)
+	assert.ErrorContains(t, err, "field addr")
+}
+
+func TestFromMapNumbers(t *testing.T) {
+	t.Parallel()
+
+	type numbers struct {
+		Small uint8   `json:"small"`
+		Count uint    `json:"count"`
+		Delta int8    `json:"delta"`
+		Ratio float32 `json:"ratio"`
+	}
+
+	var got numbers
+	require.NoError(t, util.FromMap(map[string]any{
+		"small": 255, "count": uint64(1) << 40, "delta": float64(-128),
+		"ratio": 0.5,
+	}, &got))
+	assert.Equal(t, numbers{255, 1 << 40, -128, 0.5}, got)
+
+	for _, raw := range []map[string]any{
+		{"small": -1},
+		{"small": 256},
+		{"count": -1},
+		{"count": float64(-1)},
+		{"delta": uint(128)},
+		{"delta": 1e20},
+	} {
+		require.ErrorIs(t, util.FromMap(raw, &got), util.ErrConvert, raw)
+	}
+}
+
+// Node embeds a pointer to its own type.
+type Node struct {
+	*Node
+
+	Value int `json:"value"`
+}
+
+func TestSelfEmbedding(t *testing.T) {
+	t.Parallel()
+
+	m, err := util.ToMap(Node{Value: 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"value": 1}, m)
+
+	var got Node
+	require.NoError(t, util.FromMap(map[string]any{"value": 2}, &got))
+	assert.Equal(t, 2, got.Value)
+}
@@ -0,0 +1,237 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/state"
+)
+
+// SystemDir holds the units installed by the administrator.
+const SystemDir = "/etc/systemd/system"
+
+// Runner executes commands; *execx.Runner implements it.
+type Runner interface {
+	// Run executes name with args and waits for it.
+	Run(ctx context.Context, name string, args ...string) (execx.Result, error)
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithRunner runs systemctl with r, for instance an execx runner with sudo
+// elevation.
+func WithRunner(r Runner) Option {
+	return func(m *Manager) {
+		m.runner = r
+	}
+}
+
+// WithDir installs units in dir instead of SystemDir.
+func WithDir(dir string) Option {
+	return func(m *Manager) {
+		m.dir = dir
+	}
+}
+
+// WithUserScope manages the units of the calling user, installed under
+// $XDG_CONFIG_HOME/systemd/user, through systemctl --user.
+func WithUserScope() Option {
+	return func(m *Manager) {
+		m.user = true
+	}
+}
+
+// Manager installs units and controls them through systemctl.
+type Manager struct {
+	runner Runner
+	dir    string
+	user   bool
+}
+
+// New returns a manager of the system units.
+func New(opts ...Option) *Manager {
+	m := &Manager{runner: execx.New()}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Dir returns the directory units are installed in.
+func (m *Manager) Dir() (string, error) {
+	switch {
+	case m.dir != "":
+		return m.dir, nil
+	case !m.user:
+		return SystemDir, nil
+	}
+
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("systemd: %w", err)
+	}
+
+	return filepath.Join(config, "systemd", "user"), nil
+}
+
+// Install renders the units and writes those that changed, reloading the
+// systemd configuration when any did. It returns the changes made, which
+// are empty when every unit was already up to date.
+func (m *Manager) Install(
+	ctx context.Context, units ...Unit,
+) (state.Changes, error) {
+	dir, err := m.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []state.Resource{state.Dir(dir)}
+
+	for _, u := range units {
+		data, err := u.Render()
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, state.File(filepath.Join(dir, u.Name),
+			data, state.WithMode(0o644)))
+	}
+
+	changes, err := state.Apply(ctx, resources...)
+	if err != nil || len(changes) == 0 {
+		return changes, err
+	}
+
+	return changes, m.DaemonReload(ctx)
+}
+
+// DaemonReload makes systemd read the unit files again.
+func (m *Manager) DaemonReload(ctx context.Context) error {
+	return m.systemctl(ctx, "daemon-reload")
+}
+
+// Enable enables the units, without starting them.
+func (m *Manager) Enable(ctx context.Context, units ...string) error {
+	return m.systemctl(ctx, "enable", units...)
+}
+
+// Disable disables the units, without stopping them.
+func (m *Manager) Disable(ctx context.Context, units ...string) error {
+	return m.systemctl(ctx, "disable", units...)
+}
+
+// Start starts the units.
+func (m *Manager) Start(ctx context.Context, units ...string) error {
+	return m.systemctl(ctx, "start", units...)
+}
+
+// Stop stops the units.
+func (m *Manager) Stop(ctx context.Context, units ...string) error {
+	return m.systemctl(ctx, "stop", units...)
+}
+
+// Restart restarts the units, starting those that are not running.
+func (m *Manager) Restart(ctx context.Context, units ...string) error {
+	return m.systemctl(ctx, "restart", units...)
+}
+
+// Status is the state of a unit as reported by systemctl show.
+type Status struct {
+	Name string `json:"name"`
+	// LoadState is "loaded", "not-found" or "masked", among others.
+	LoadState string `json:"load_state"`
+	// ActiveState is "active", "inactive", "failed" or a transition.
+	ActiveState string `json:"active_state"`
+	// SubState is specific to the unit type, such as "running".
+	SubState string `json:"sub_state"`
+	// UnitFileState is "enabled", "disabled" or "static", among others.
+	UnitFileState string `json:"unit_file_state"`
+	// Result is "success" or the reason of the last failure.
+	Result         string `json:"result"`
+	MainPID        int    `json:"main_pid"`
+	ExecMainStatus int    `json:"exec_main_status"`
+}
+
+// IsActive reports whether the unit is running or has run successfully.
+func (s Status) IsActive() bool {
+	return s.ActiveState == "active"
+}
+
+// IsEnabled reports whether the unit starts on its own.
+func (s Status) IsEnabled() bool {
+	return strings.HasPrefix(s.UnitFileState, "enabled")
+}
+
+// statusProperties are the properties of systemctl show read by Status.
+const statusProperties = "LoadState,ActiveState,SubState,UnitFileState," +
+	"Result,MainPID,ExecMainStatus"
+
+// Status returns the state of a unit. Unknown units are not an error and
+// have the load state "not-found".
+func (m *Manager) Status(ctx context.Context, unit string) (Status, error) {
+	result, err := m.run(ctx, "show", "--property="+statusProperties, unit)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return parseStatus(unit, result.Stdout), nil
+}
+
+// parseStatus reads the KEY=value lines of systemctl show.
+func parseStatus(unit, output string) Status {
+	status := Status{Name: unit}
+	fields := map[string]*string{
+		"LoadState": &status.LoadState, "ActiveState": &status.ActiveState,
+		"SubState": &status.SubState, "UnitFileState": &status.UnitFileState,
+		"Result": &status.Result,
+	}
+
+	for line := range strings.Lines(output) {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+
+		switch key {
+		case "MainPID":
+			status.MainPID, _ = strconv.Atoi(value)
+		case "ExecMainStatus":
+			status.ExecMainStatus, _ = strconv.Atoi(value)
+		default:
+			if field, ok := fields[key]; ok {
+				*field = value
+			}
+		}
+	}
+
+	return status
+}
+
+func (m *Manager) systemctl(
+	ctx context.Context, command string, units ...string,
+) error {
+	_, err := m.run(ctx, command, units...)
+
+	return err
+}
+
+// run runs systemctl command with args in the scope of the manager.
+func (m *Manager) run(
+	ctx context.Context, command string, args ...string,
+) (execx.Result, error) {
+	argv := []string{command}
+	if m.user {
+		argv = []string{"--user", command}
+	}
+
+	result, err := m.runner.Run(ctx, "systemctl", append(argv, args...)...)
+	if err != nil {
+		return result, fmt.Errorf("systemd: %w", err)
+	}
+
+	return result, nil
+}
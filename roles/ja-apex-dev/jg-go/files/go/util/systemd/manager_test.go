@@ -0,0 +1,101 @@
+package systemd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/execx"
+	"example.com/go-template/util/systemd"
+)
+
+// fake records the commands and answers them with stdout.
+type fake struct {
+	stdout string
+	calls  []string
+}
+
+func (f *fake) Run(
+	_ context.Context, name string, args ...string,
+) (execx.Result, error) {
+	f.calls = append(f.calls, strings.Join(append([]string{name}, args...),
+		" "))
+
+	return execx.Result{Stdout: f.stdout}, nil
+}
+
+func TestInstall(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "system")
+	f := &fake{}
+	m := systemd.New(systemd.WithDir(dir), systemd.WithRunner(f))
+
+	changes, err := m.Install(t.Context(), service())
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, []string{"systemctl daemon-reload"}, f.calls)
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "ExecStart=/usr/bin/app serve\n")
+
+	changes, err = m.Install(t.Context(), service())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Len(t, f.calls, 1, "unchanged units are not reloaded")
+
+	_, err = m.Install(t.Context(), systemd.Unit{Name: "bad.service"})
+	require.ErrorIs(t, err, systemd.ErrInvalidUnit)
+}
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	f := &fake{}
+	m := systemd.New(systemd.WithRunner(f), systemd.WithUserScope())
+
+	require.NoError(t, m.Enable(t.Context(), "a.service", "b.timer"))
+	require.NoError(t, m.Disable(t.Context(), "a.service"))
+	require.NoError(t, m.Start(t.Context(), "a.service"))
+	require.NoError(t, m.Stop(t.Context(), "a.service"))
+	require.NoError(t, m.Restart(t.Context(), "a.service"))
+	assert.Equal(t, []string{
+		"systemctl --user enable a.service b.timer",
+		"systemctl --user disable a.service",
+		"systemctl --user start a.service",
+		"systemctl --user stop a.service",
+		"systemctl --user restart a.service",
+	}, f.calls)
+
+	dir, err := systemd.New().Dir()
+	require.NoError(t, err)
+	assert.Equal(t, systemd.SystemDir, dir)
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	f := &fake{stdout: "LoadState=loaded\nActiveState=active\n" +
+		"SubState=running\nUnitFileState=enabled\nResult=success\n" +
+		"MainPID=4242\nExecMainStatus=0\n"}
+	m := systemd.New(systemd.WithRunner(f))
+
+	status, err := m.Status(t.Context(), "app.service")
+	require.NoError(t, err)
+	assert.Equal(t, systemd.Status{
+		Name: "app.service", LoadState: "loaded", ActiveState: "active",
+		SubState: "running", UnitFileState: "enabled", Result: "success",
+		MainPID: 4242,
+	}, status)
+	assert.True(t, status.IsActive())
+	assert.True(t, status.IsEnabled())
+	assert.Equal(t, "systemctl show --property=LoadState,ActiveState,"+
+		"SubState,UnitFileState,Result,MainPID,ExecMainStatus app.service",
+		f.calls[0])
+}
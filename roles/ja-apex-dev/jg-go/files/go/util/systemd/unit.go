@@ -0,0 +1,311 @@
+// Package systemd renders unit files from typed structs, installs them
+// idempotently and drives systemctl. Units are validated before they are
+// rendered, so that a typo in a restart policy or a relative ExecStart
+// fails the helper instead of the boot.
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidUnit is returned for units that systemd would reject.
+var ErrInvalidUnit = errors.New("systemd: invalid unit")
+
+// Unit is a service or timer unit file.
+type Unit struct {
+	// Name is the file name, such as "app.service" or "app.timer".
+	Name          string
+	Description   string
+	Documentation []string
+	After         []string
+	Before        []string
+	Wants         []string
+	Requires      []string
+	// Service holds the [Service] section of .service units.
+	Service *Service
+	// Timer holds the [Timer] section of .timer units.
+	Timer   *Timer
+	Install Install
+}
+
+// Service is the [Service] section.
+type Service struct {
+	// Type defaults to "simple".
+	Type         string
+	ExecStart    string
+	ExecStartPre []string
+	ExecReload   string
+	User         string
+	Group        string
+	// WorkingDirectory must be absolute.
+	WorkingDirectory string
+	Environment      map[string]string
+	EnvironmentFile  []string
+	// Restart defaults to "no".
+	Restart    string
+	RestartSec time.Duration
+}
+
+// Timer is the [Timer] section; at least one trigger is required.
+type Timer struct {
+	OnCalendar         []string
+	OnBootSec          time.Duration
+	OnUnitActiveSec    time.Duration
+	RandomizedDelaySec time.Duration
+	// Persistent catches up on runs missed while the machine was off.
+	Persistent bool
+	// Unit defaults to the service of the same name.
+	Unit string
+}
+
+// Install is the [Install] section used by systemctl enable.
+type Install struct {
+	WantedBy   []string
+	RequiredBy []string
+	Alias      []string
+}
+
+var (
+	serviceTypes = []string{"simple", "exec", "forking", "oneshot", "dbus",
+		"notify", "notify-reload", "idle"}
+	restartPolicies = []string{"no", "on-success", "on-failure",
+		"on-abnormal", "on-watchdog", "on-abort", "always"}
+)
+
+// Render validates the unit and returns the content of its file.
+func (u Unit) Render() ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+
+	var w writer
+
+	w.section("Unit")
+	w.key("Description", u.Description)
+	w.list("Documentation", u.Documentation)
+	w.list("After", u.After)
+	w.list("Before", u.Before)
+	w.list("Wants", u.Wants)
+	w.list("Requires", u.Requires)
+
+	if u.Service != nil {
+		u.Service.render(&w)
+	}
+
+	if u.Timer != nil {
+		u.Timer.render(&w)
+	}
+
+	if len(u.Install.WantedBy)+len(u.Install.RequiredBy)+
+		len(u.Install.Alias) > 0 {
+		w.section("Install")
+		w.list("WantedBy", u.Install.WantedBy)
+		w.list("RequiredBy", u.Install.RequiredBy)
+		w.list("Alias", u.Install.Alias)
+	}
+
+	return []byte(w.String()), nil
+}
+
+// Validate reports the first problem of the unit, wrapping ErrInvalidUnit.
+func (u Unit) Validate() error {
+	err := noNewlines(u)
+
+	switch {
+	case err != nil:
+	case u.Name == "" || strings.Contains(u.Name, "/"):
+		err = fmt.Errorf("bad name %q", u.Name)
+	case u.Description == "":
+		err = errors.New("missing description")
+	default:
+		err = u.validateSection()
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidUnit, u.Name, err)
+	}
+
+	return nil
+}
+
+// validateSection checks the section matching the type of the unit.
+func (u Unit) validateSection() error {
+	switch path.Ext(u.Name) {
+	case ".service":
+		if u.Service != nil && u.Timer == nil {
+			return u.Service.validate()
+		}
+	case ".timer":
+		if u.Timer != nil && u.Service == nil {
+			return u.Timer.validate()
+		}
+	}
+
+	return fmt.Errorf("%s needs exactly its own section", u.Name)
+}
+
+func (s *Service) validate() error {
+	switch {
+	case s.Type != "" && !slices.Contains(serviceTypes, s.Type):
+		return fmt.Errorf("unknown type %q", s.Type)
+	case s.Restart != "" && !slices.Contains(restartPolicies, s.Restart):
+		return fmt.Errorf("unknown restart policy %q", s.Restart)
+	case s.WorkingDirectory != "" && !path.IsAbs(s.WorkingDirectory):
+		return fmt.Errorf("relative working directory %q", s.WorkingDirectory)
+	}
+
+	return s.validateCommands()
+}
+
+// validateCommands checks ExecStart, which is required, and the optional
+// commands.
+func (s *Service) validateCommands() error {
+	if s.ExecStart == "" {
+		return errors.New("missing ExecStart")
+	}
+
+	commands := append([]string{s.ExecStart}, s.ExecStartPre...)
+	if s.ExecReload != "" {
+		commands = append(commands, s.ExecReload)
+	}
+
+	for _, command := range commands {
+		if err := validateCommand(command); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCommand checks that a command line starts with an absolute
+// executable, after the special prefixes such as "-" for ignored failures.
+func validateCommand(command string) error {
+	executable, _, _ := strings.Cut(strings.TrimLeft(command, "@-:+!"), " ")
+	if !path.IsAbs(executable) {
+		return fmt.Errorf("command %q needs an absolute executable", command)
+	}
+
+	return nil
+}
+
+func (t *Timer) validate() error {
+	if len(t.OnCalendar) == 0 && t.OnBootSec <= 0 && t.OnUnitActiveSec <= 0 {
+		return errors.New("timer has no trigger")
+	}
+
+	return nil
+}
+
+// noNewlines rejects line breaks, which would smuggle in extra keys.
+func noNewlines(u Unit) error {
+	values := slices.Concat(u.Documentation, u.After, u.Before, u.Wants,
+		u.Requires, u.Install.WantedBy, u.Install.RequiredBy, u.Install.Alias,
+		[]string{u.Name, u.Description})
+
+	if s := u.Service; s != nil {
+		values = slices.Concat(values, s.ExecStartPre, s.EnvironmentFile,
+			slices.Collect(maps.Keys(s.Environment)),
+			slices.Collect(maps.Values(s.Environment)),
+			[]string{s.Type, s.ExecStart, s.ExecReload, s.User, s.Group,
+				s.WorkingDirectory, s.Restart})
+	}
+
+	if t := u.Timer; t != nil {
+		values = slices.Concat(values, t.OnCalendar, []string{t.Unit})
+	}
+
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("line break in %q", value)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) render(w *writer) {
+	w.section("Service")
+	w.key("Type", s.Type)
+	w.list("ExecStartPre", s.ExecStartPre)
+	w.key("ExecStart", s.ExecStart)
+	w.key("ExecReload", s.ExecReload)
+	w.key("User", s.User)
+	w.key("Group", s.Group)
+	w.key("WorkingDirectory", s.WorkingDirectory)
+
+	for _, name := range slices.Sorted(maps.Keys(s.Environment)) {
+		w.key("Environment", quote(name+"="+s.Environment[name]))
+	}
+
+	w.list("EnvironmentFile", s.EnvironmentFile)
+	w.key("Restart", s.Restart)
+	w.duration("RestartSec", s.RestartSec)
+}
+
+func (t *Timer) render(w *writer) {
+	w.section("Timer")
+	w.list("OnCalendar", t.OnCalendar)
+	w.duration("OnBootSec", t.OnBootSec)
+	w.duration("OnUnitActiveSec", t.OnUnitActiveSec)
+	w.duration("RandomizedDelaySec", t.RandomizedDelaySec)
+
+	if t.Persistent {
+		w.key("Persistent", "true")
+	}
+
+	w.key("Unit", t.Unit)
+}
+
+// writer builds the INI-like syntax of unit files.
+type writer struct {
+	strings.Builder
+}
+
+func (w *writer) section(name string) {
+	if w.Len() > 0 {
+		w.WriteString("\n")
+	}
+
+	w.WriteString("[" + name + "]\n")
+}
+
+// key writes a non-empty value.
+func (w *writer) key(name, value string) {
+	if value != "" {
+		w.WriteString(name + "=" + value + "\n")
+	}
+}
+
+// list writes one line per value, which systemd appends to each other.
+func (w *writer) list(name string, values []string) {
+	for _, value := range values {
+		w.key(name, value)
+	}
+}
+
+// duration writes a positive duration in seconds, or milliseconds when it
+// is not a whole number of seconds.
+func (w *writer) duration(name string, d time.Duration) {
+	switch {
+	case d <= 0:
+	case d%time.Second == 0:
+		w.key(name, fmt.Sprintf("%ds", d/time.Second))
+	default:
+		w.key(name, fmt.Sprintf("%dms", d/time.Millisecond))
+	}
+}
+
+// quote wraps an assignment in double quotes, escaping its quotes and
+// backslashes.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
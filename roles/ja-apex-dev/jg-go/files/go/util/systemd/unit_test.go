@@ -0,0 +1,166 @@
+package systemd_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/systemd"
+)
+
+func service() systemd.Unit {
+	return systemd.Unit{
+		Name:        "app.service",
+		Description: "Demo application",
+		After:       []string{"network-online.target"},
+		Wants:       []string{"network-online.target"},
+		Service: &systemd.Service{
+			Type:         "notify",
+			ExecStartPre: []string{"-/usr/bin/app migrate"},
+			ExecStart:    "/usr/bin/app serve",
+			User:         "app",
+			Environment:  map[string]string{"NAME": `say "hi"`, "A": "1"},
+			Restart:      "on-failure",
+			RestartSec:   1500 * time.Millisecond,
+		},
+		Install: systemd.Install{WantedBy: []string{"multi-user.target"}},
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	data, err := service().Render()
+	require.NoError(t, err)
+	assert.Equal(t, `[Unit]
+Description=Demo application
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=notify
+ExecStartPre=-/usr/bin/app migrate
+ExecStart=/usr/bin/app serve
+User=app
+Environment="A=1"
+Environment="NAME=say \"hi\""
+Restart=on-failure
+RestartSec=1500ms
+
+[Install]
+WantedBy=multi-user.target
+`, string(data))
+}
+
+func TestRenderTimer(t *testing.T) {
+	t.Parallel()
+
+	data, err := systemd.Unit{
+		Name:        "backup.timer",
+		Description: "Nightly backup",
+		Timer: &systemd.Timer{
+			OnCalendar:         []string{"*-*-* 03:00:00"},
+			RandomizedDelaySec: 10 * time.Minute,
+			Persistent:         true,
+		},
+		Install: systemd.Install{WantedBy: []string{"timers.target"}},
+	}.Render()
+	require.NoError(t, err)
+	assert.Equal(t, "[Unit]\nDescription=Nightly backup\n\n"+
+		"[Timer]\nOnCalendar=*-*-* 03:00:00\nRandomizedDelaySec=600s\n"+
+		"Persistent=true\n\n[Install]\nWantedBy=timers.target\n", string(data))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]func(u *systemd.Unit){
+		"name":        func(u *systemd.Unit) { u.Name = "../app.service" },
+		"description": func(u *systemd.Unit) { u.Description = "" },
+		"section":     func(u *systemd.Unit) { u.Name = "app.timer" },
+		"type":        func(u *systemd.Unit) { u.Service.Type = "daemon" },
+		"restart":     func(u *systemd.Unit) { u.Service.Restart = "maybe" },
+		"exec":        func(u *systemd.Unit) { u.Service.ExecStart = "app" },
+		"missing":     func(u *systemd.Unit) { u.Service.ExecStart = "" },
+		"pre": func(u *systemd.Unit) {
+			u.Service.ExecStartPre = []string{"-app"}
+		},
+		"dir": func(u *systemd.Unit) { u.Service.WorkingDirectory = "srv" },
+		"newline": func(u *systemd.Unit) {
+			u.Service.Environment = map[string]string{"A": "1\nUser=root"}
+		},
+		"timer": func(u *systemd.Unit) {
+			u.Name, u.Service = "app.timer", nil
+			u.Timer = &systemd.Timer{}
+		},
+	}
+
+	for name, mutate := range cases {
+		u := service()
+		mutate(&u)
+
+		_, err := u.Render()
+		require.ErrorIs(t, err, systemd.ErrInvalidUnit, name)
+	}
+}
+
+// textFields returns the index paths of the string, []string and
+// map[string]string fields of t, through nested structs and pointers.
+func textFields(t reflect.Type, prefix []int) [][]int {
+	var paths [][]int
+
+	for i := range t.NumField() {
+		path := append(slices.Clone(prefix), i)
+
+		switch field := t.Field(i).Type; {
+		case field.Kind() == reflect.String,
+			field == reflect.TypeFor[[]string](),
+			field == reflect.TypeFor[map[string]string]():
+			paths = append(paths, path)
+		case field.Kind() == reflect.Struct:
+			paths = append(paths, textFields(field, path)...)
+		case field.Kind() == reflect.Pointer:
+			paths = append(paths, textFields(field.Elem(), path)...)
+		}
+	}
+
+	return paths
+}
+
+func TestRenderRejectsNewlines(t *testing.T) {
+	t.Parallel()
+
+	const injected = "/srv\nExecStartPost=/bin/sh -c id"
+
+	timer := systemd.Unit{
+		Name:        "backup.timer",
+		Description: "Nightly backup",
+		Timer:       &systemd.Timer{OnCalendar: []string{"daily"}},
+	}
+
+	for _, path := range textFields(reflect.TypeFor[systemd.Unit](), nil) {
+		for _, u := range []systemd.Unit{service(), timer} {
+			field, err := reflect.ValueOf(&u).Elem().FieldByIndexErr(path)
+			if err != nil {
+				continue // The section of the other unit type.
+			}
+
+			switch field.Kind() {
+			case reflect.String:
+				field.SetString(injected)
+			case reflect.Slice:
+				field.Set(reflect.ValueOf([]string{injected}))
+			default:
+				field.Set(reflect.ValueOf(map[string]string{"A": injected}))
+			}
+
+			_, err = u.Render()
+			require.ErrorIs(t, err, systemd.ErrInvalidUnit, path)
+			assert.ErrorContains(t, err, "line break", path)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package table
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/term"
+)
+
+// columnGap separates the columns of Plain output.
+const columnGap = "  "
+
+// minMarkdownWidth is the shortest delimiter row cell, "---".
+const minMarkdownWidth = 3
+
+// Render writes the table to w in the configured format. Colors are only
+// written when term.ColorEnabled reports that w supports them.
+func (t *Table) Render(w io.Writer) error {
+	columns, err := t.selected()
+	if err != nil {
+		return err
+	}
+
+	if !term.ColorEnabled(w) {
+		t = t.uncolored()
+	}
+
+	var out string
+
+	switch t.format {
+	case Plain:
+		out = t.plain(columns)
+	case Markdown:
+		out = t.markdown(columns)
+	case CSV:
+		return t.csv(w, columns)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownFormat, t.format)
+	}
+
+	if _, err := io.WriteString(w, out); err != nil {
+		return fmt.Errorf("table: %w", err)
+	}
+
+	return nil
+}
+
+// uncolored returns a copy of t without colors.
+func (t *Table) uncolored() *Table {
+	plain := *t
+	plain.headerColor = ""
+	plain.cellColor = nil
+
+	return &plain
+}
+
+// selected returns the indices of the rendered columns.
+func (t *Table) selected() ([]int, error) {
+	if t.columns == nil {
+		columns := make([]int, len(t.header))
+		for i := range columns {
+			columns[i] = i
+		}
+
+		return columns, nil
+	}
+
+	columns := make([]int, len(t.columns))
+
+	for i, name := range t.columns {
+		index := slices.IndexFunc(t.header, func(column string) bool {
+			return strings.EqualFold(column, name)
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+
+		columns[i] = index
+	}
+
+	return columns, nil
+}
+
+// grid returns the displayed header and rows of the selected columns, on
+// a single line each and truncated to the maximum width.
+func (t *Table) grid(columns []int, escape func(string) string) [][]string {
+	grid := make([][]string, 0, len(t.rows)+1)
+
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = escape(t.display(cellAt(row, column)))
+		}
+
+		grid = append(grid, cells)
+	}
+
+	return grid
+}
+
+// display puts cell on a single line and truncates it.
+func (t *Table) display(cell string) string {
+	cell = strings.Join(strings.Fields(cell), " ")
+	if t.maxWidth > 0 {
+		cell = util.Ellipsize(cell, t.maxWidth, util.DefaultEllipsis)
+	}
+
+	return cell
+}
+
+// plain aligns the columns, painting the header and cells when colors
+// are configured. Padding is added after painting, so escape sequences do
+// not count towards the widths.
+func (t *Table) plain(columns []int) string {
+	grid := t.grid(columns, func(s string) string { return s })
+	widths := columnWidths(grid, 0)
+
+	var b strings.Builder
+
+	for r, cells := range grid {
+		colors := make([]Color, len(cells))
+		for i, cell := range cells {
+			colors[i] = t.headerColor
+			if r > 0 {
+				colors[i] = t.colorOf(columns[i], cell)
+			}
+		}
+
+		b.WriteString(plainRow(cells, widths, colors))
+
+		if r == 0 {
+			b.WriteString(plainRow(rule(widths), widths, nil))
+		}
+	}
+
+	return b.String()
+}
+
+// plainRow joins padded cells, without trailing spaces.
+func plainRow(cells []string, widths []int, colors []Color) string {
+	var b strings.Builder
+
+	for i, cell := range cells {
+		if i > 0 {
+			b.WriteString(columnGap)
+		}
+
+		b.WriteString(cellAt(colors, i).Paint(cell))
+
+		if i < len(cells)-1 {
+			b.WriteString(padding(cell, widths[i]))
+		}
+	}
+
+	return strings.TrimRight(b.String(), " ") + "\n"
+}
+
+// colorOf returns the color of a cell in the column at index column.
+func (t *Table) colorOf(column int, cell string) Color {
+	if t.cellColor == nil {
+		return ""
+	}
+
+	return t.cellColor(cellAt(t.header, column), cell)
+}
+
+// markdown pads the cells of every column to the same width, so that the
+// source stays readable, and escapes the pipes within cells.
+func (t *Table) markdown(columns []int) string {
+	grid := t.grid(columns, strings.NewReplacer("|", `\|`).Replace)
+	widths := columnWidths(grid, minMarkdownWidth)
+	grid = slices.Insert(grid, 1, rule(widths))
+
+	var b strings.Builder
+
+	for _, cells := range grid {
+		for i, cell := range cells {
+			b.WriteString("| " + cell + padding(cell, widths[i]) + " ")
+		}
+
+		b.WriteString("|\n")
+	}
+
+	return b.String()
+}
+
+// csv writes the unmodified cells of the selected columns.
+func (t *Table) csv(w io.Writer, columns []int) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, len(columns))
+
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		for i, column := range columns {
+			record[i] = cellAt(row, column)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("table: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("table: %w", err)
+	}
+
+	return nil
+}
+
+// columnWidths returns the widest cell of each column, at least minimum.
+func columnWidths(grid [][]string, minimum int) []int {
+	widths := make([]int, len(grid[0]))
+	for i := range widths {
+		widths[i] = minimum
+	}
+
+	for _, cells := range grid {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], util.GraphemeLen(cell))
+		}
+	}
+
+	return widths
+}
+
+// rule returns the dashes underlining the header.
+func rule(widths []int) []string {
+	dashes := make([]string, len(widths))
+	for i, width := range widths {
+		dashes[i] = strings.Repeat("-", width)
+	}
+
+	return dashes
+}
+
+// padding returns the spaces aligning cell to width.
+func padding(cell string, width int) string {
+	return strings.Repeat(" ", max(width-util.GraphemeLen(cell), 0))
+}
+
+// cellAt returns row[i], or the zero value for short rows.
+func cellAt[T any](row []T, i int) T {
+	if i < len(row) {
+		return row[i]
+	}
+
+	var zero T
+
+	return zero
+}
@@ -0,0 +1,107 @@
+package table_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/table"
+)
+
+func sample(opts ...table.Option) *table.Table {
+	tbl := table.New([]string{"NAME", "STATUS", "MESSAGE"}, opts...)
+	tbl.Append("web-1", "ready", "serving")
+	tbl.Append("database", "failed", "disk | full\nretrying")
+	tbl.Append("café", "ready")
+
+	return tbl
+}
+
+func TestRenderPlain(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ""+
+		"NAME      STATUS  MESSAGE\n"+
+		"--------  ------  --------------------\n"+
+		"web-1     ready   serving\n"+
+		"database  failed  disk | full retrying\n"+
+		"café      ready\n", render(t, sample()))
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tbl := sample(table.WithFormat(table.Markdown),
+		table.WithColumns("name", "message"))
+
+	assert.Equal(t, ""+
+		"| NAME     | MESSAGE               |\n"+
+		"| -------- | --------------------- |\n"+
+		"| web-1    | serving               |\n"+
+		"| database | disk \\| full retrying |\n"+
+		"| café     |                       |\n", render(t, tbl))
+}
+
+func TestRenderMaxWidth(t *testing.T) {
+	t.Parallel()
+
+	tbl := sample(table.WithColumns("message", "name"), table.WithMaxWidth(6))
+
+	assert.Equal(t, ""+
+		"MESSA…  NAME\n"+
+		"------  ------\n"+
+		"servi…  web-1\n"+
+		"disk …  datab…\n"+
+		"        café\n", render(t, tbl))
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestRenderColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+
+	tbl := table.New([]string{"NAME", "STATUS"},
+		table.WithColumns("status"),
+		table.WithHeaderColor(table.Bold),
+		table.WithCellColor(func(column, cell string) table.Color {
+			if column == "STATUS" && cell == "failed" {
+				return table.Red
+			}
+
+			return ""
+		}))
+	tbl.Append("web", "ready")
+	tbl.Append("db", "failed")
+
+	assert.Equal(t, ""+
+		"\x1b[1mSTATUS\x1b[0m\n"+
+		"------\n"+
+		"ready\n"+
+		"\x1b[31mfailed\x1b[0m\n", render(t, tbl))
+
+	t.Setenv("FORCE_COLOR", "")
+	assert.Equal(t, "STATUS\n------\nready\nfailed\n", render(t, tbl))
+}
+
+func TestRenderErrors(t *testing.T) {
+	t.Parallel()
+
+	err := sample(table.WithColumns("name", "age")).Render(nil)
+	require.ErrorIs(t, err, table.ErrUnknownColumn)
+
+	err = sample(table.WithFormat("html")).Render(nil)
+	require.ErrorIs(t, err, table.ErrUnknownFormat)
+
+	err = sample().Render(failingWriter{})
+	require.ErrorIs(t, err, errWrite)
+}
+
+var errWrite = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
@@ -0,0 +1,266 @@
+// Package table renders rows as aligned plain text, Markdown or CSV for
+// command-line status output:
+//
+//	t, err := table.FromStructs(services,
+//		table.WithColumns("name", "status"),
+//		table.WithMaxWidth(30),
+//		table.WithHeaderColor(table.Bold))
+//	if err == nil {
+//		err = t.Render(os.Stdout)
+//	}
+//
+// Struct fields are named by their `table` tag, or by their Go name when
+// untagged; a tag of "-" hides the field. Cells are formatted with
+// fmt.Sprint, so fmt.Stringer implementations are honored, and nil
+// pointers render empty.
+package table
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagName is the struct tag naming the column of a field.
+const TagName = "table"
+
+var (
+	// ErrNotStruct is returned by FromStructs for non-struct row types.
+	ErrNotStruct = errors.New("table: row type must be a struct")
+	// ErrUnknownColumn is returned when a selected column does not exist.
+	ErrUnknownColumn = errors.New("table: unknown column")
+	// ErrUnknownFormat is returned when rendering an unknown format.
+	ErrUnknownFormat = errors.New("table: unknown format")
+)
+
+// Format is an output format.
+type Format string
+
+// Output formats.
+const (
+	// Plain aligns columns with spaces under a dashed header rule.
+	Plain Format = "plain"
+	// Markdown renders a GitHub-flavored Markdown table.
+	Markdown Format = "markdown"
+	// CSV renders comma-separated values. Cells are neither truncated nor
+	// colored.
+	CSV Format = "csv"
+)
+
+// ParseFormat parses a format name, as given to a command-line flag.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case Plain, Markdown, CSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownFormat, name)
+	}
+}
+
+// Color is an ANSI SGR parameter, such as "1" for bold text.
+type Color string
+
+// Common colors.
+const (
+	Bold    Color = "1"
+	Faint   Color = "2"
+	Red     Color = "31"
+	Green   Color = "32"
+	Yellow  Color = "33"
+	Blue    Color = "34"
+	Magenta Color = "35"
+	Cyan    Color = "36"
+)
+
+// Paint wraps s in the escape sequences of c. The empty color returns s.
+func (c Color) Paint(s string) string {
+	if c == "" {
+		return s
+	}
+
+	return "\x1b[" + string(c) + "m" + s + "\x1b[0m"
+}
+
+// Option configures a table.
+type Option func(*settings)
+
+type settings struct {
+	format      Format
+	columns     []string
+	maxWidth    int
+	headerColor Color
+	cellColor   func(column, cell string) Color
+}
+
+// WithFormat sets the output format, Plain by default.
+func WithFormat(format Format) Option {
+	return func(s *settings) {
+		s.format = format
+	}
+}
+
+// WithColumns selects and orders the rendered columns by header name,
+// ignoring case. All columns are rendered by default.
+func WithColumns(names ...string) Option {
+	return func(s *settings) {
+		s.columns = names
+	}
+}
+
+// WithMaxWidth ellipsizes cells longer than width grapheme clusters in
+// Plain and Markdown output. Zero, the default, disables truncation.
+func WithMaxWidth(width int) Option {
+	return func(s *settings) {
+		s.maxWidth = max(width, 0)
+	}
+}
+
+// WithHeaderColor paints the header of Plain output.
+func WithHeaderColor(color Color) Option {
+	return func(s *settings) {
+		s.headerColor = color
+	}
+}
+
+// WithCellColor paints the cells of Plain output with the color chosen by
+// fn from the column name and the cell text, such as red for a "failed"
+// status.
+func WithCellColor(fn func(column, cell string) Color) Option {
+	return func(s *settings) {
+		s.cellColor = fn
+	}
+}
+
+// Table holds a header and rows of cells.
+type Table struct {
+	settings
+
+	header []string
+	rows   [][]string
+}
+
+// New returns an empty table with the given column names.
+func New(header []string, opts ...Option) *Table {
+	t := &Table{settings: settings{format: Plain}, header: header}
+	for _, opt := range opts {
+		opt(&t.settings)
+	}
+
+	return t
+}
+
+// FromRows returns a table whose header is the first of rows.
+func FromRows(rows [][]string, opts ...Option) *Table {
+	if len(rows) == 0 {
+		return New(nil, opts...)
+	}
+
+	t := New(rows[0], opts...)
+	t.rows = rows[1:]
+
+	return t
+}
+
+// FromStructs returns a table with a column per exported field of T, or of
+// the type T points to, and a row per element of rows.
+func FromStructs[T any](rows []T, opts ...Option) (*Table, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w, got %s", ErrNotStruct, typ)
+	}
+
+	fields := fieldsOf(typ)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+
+	t := New(header, opts...)
+
+	for _, row := range rows {
+		value := reflect.Indirect(reflect.ValueOf(row))
+		if !value.IsValid() {
+			continue
+		}
+
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			cells[i] = formatCell(value, f.index)
+		}
+
+		t.rows = append(t.rows, cells)
+	}
+
+	return t, nil
+}
+
+// Append adds a row. Missing cells render empty and extra cells are
+// dropped.
+func (t *Table) Append(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Len returns the number of rows.
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+type field struct {
+	name  string
+	index []int
+}
+
+// fieldsOf lists the visible exported fields of t, including promoted
+// ones, skipping the embedded structs themselves.
+func fieldsOf(t reflect.Type) []field {
+	var fields []field
+
+	for _, sf := range reflect.VisibleFields(t) {
+		embedded := sf.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+
+		tag := sf.Tag.Get(TagName)
+		if !sf.IsExported() || tag == "-" ||
+			sf.Anonymous && embedded.Kind() == reflect.Struct {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+
+		fields = append(fields, field{name: name, index: sf.Index})
+	}
+
+	return fields
+}
+
+// formatCell formats the field at index of value, rendering nil pointers
+// and fields of nil embedded pointers empty.
+func formatCell(value reflect.Value, index []int) string {
+	field, err := value.FieldByIndexErr(index)
+	if err != nil {
+		return ""
+	}
+
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return ""
+		}
+
+		if _, ok := field.Interface().(fmt.Stringer); !ok {
+			field = field.Elem()
+		}
+	}
+
+	return fmt.Sprint(field.Interface())
+}
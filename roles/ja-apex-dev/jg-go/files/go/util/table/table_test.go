@@ -0,0 +1,93 @@
+package table_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/table"
+)
+
+type meta struct {
+	Region string `table:"region"`
+}
+
+type service struct {
+	meta
+
+	Name    string        `table:"name"`
+	Status  string        `table:"status"`
+	Uptime  time.Duration `table:"uptime"`
+	Replica *int          `table:"replicas"`
+	Secret  string        `table:"-"`
+	note    string
+}
+
+func render(t *testing.T, tbl *table.Table) string {
+	t.Helper()
+
+	var out strings.Builder
+	require.NoError(t, tbl.Render(&out))
+
+	return out.String()
+}
+
+func TestFromStructs(t *testing.T) {
+	t.Parallel()
+
+	replicas := 3
+	rows := []*service{
+		{
+			meta: meta{Region: "eu"}, Name: "web", Status: "ready",
+			Uptime: 90 * time.Second, Replica: &replicas, Secret: "x",
+			note: "hidden",
+		},
+		nil,
+		{Name: "db", Status: "failed"},
+	}
+
+	tbl, err := table.FromStructs(rows, table.WithFormat(table.CSV))
+	require.NoError(t, err)
+	assert.Equal(t, 2, tbl.Len())
+	assert.Equal(t, "region,name,status,uptime,replicas\n"+
+		"eu,web,ready,1m30s,3\n"+
+		",db,failed,0s,\n", render(t, tbl))
+}
+
+func TestFromStructsNotStruct(t *testing.T) {
+	t.Parallel()
+
+	_, err := table.FromStructs([]string{"a"})
+	require.ErrorIs(t, err, table.ErrNotStruct)
+}
+
+func TestFromRows(t *testing.T) {
+	t.Parallel()
+
+	tbl := table.FromRows([][]string{{"a", "b"}, {"1"}, {"2", "3", "4"}},
+		table.WithFormat(table.CSV))
+	assert.Equal(t, "a,b\n1,\n2,3\n", render(t, tbl))
+
+	assert.Equal(t, 0, table.FromRows(nil).Len())
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := table.ParseFormat("Markdown")
+	require.NoError(t, err)
+	assert.Equal(t, table.Markdown, format)
+
+	_, err = table.ParseFormat("html")
+	require.ErrorIs(t, err, table.ErrUnknownFormat)
+}
+
+func TestColorPaint(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "\x1b[31mfailed\x1b[0m", table.Red.Paint("failed"))
+	assert.Equal(t, "plain", table.Color("").Paint("plain"))
+}
@@ -0,0 +1,134 @@
+package term
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// SGR parameters of the supported styles.
+const (
+	sgrBold      = "1"
+	sgrDim       = "2"
+	sgrUnderline = "4"
+	sgrRed       = "31"
+	sgrGreen     = "32"
+	sgrYellow    = "33"
+	sgrBlue      = "34"
+	sgrCyan      = "36"
+)
+
+// Styler applies ANSI styles when its output supports them and returns
+// text unchanged otherwise. The zero value never styles.
+type Styler struct {
+	enabled bool
+}
+
+// NewStyler returns a styler for text written to w, enabled according to
+// ColorEnabled.
+func NewStyler(w io.Writer) Styler {
+	return Styler{enabled: ColorEnabled(w)}
+}
+
+// Enabled reports whether s emits escape sequences.
+func (s Styler) Enabled() bool {
+	return s.enabled
+}
+
+// Bold renders text in bold.
+func (s Styler) Bold(text string) string {
+	return s.paint(sgrBold, text)
+}
+
+// Dim renders text with reduced intensity.
+func (s Styler) Dim(text string) string {
+	return s.paint(sgrDim, text)
+}
+
+// Underline underlines text.
+func (s Styler) Underline(text string) string {
+	return s.paint(sgrUnderline, text)
+}
+
+// Red renders text in red.
+func (s Styler) Red(text string) string {
+	return s.paint(sgrRed, text)
+}
+
+// Green renders text in green.
+func (s Styler) Green(text string) string {
+	return s.paint(sgrGreen, text)
+}
+
+// Yellow renders text in yellow.
+func (s Styler) Yellow(text string) string {
+	return s.paint(sgrYellow, text)
+}
+
+// Blue renders text in blue.
+func (s Styler) Blue(text string) string {
+	return s.paint(sgrBlue, text)
+}
+
+// Cyan renders text in cyan.
+func (s Styler) Cyan(text string) string {
+	return s.paint(sgrCyan, text)
+}
+
+func (s Styler) paint(sgr, text string) string {
+	if !s.enabled || text == "" {
+		return text
+	}
+
+	return "\x1b[" + sgr + "m" + text + "\x1b[0m"
+}
+
+// stdout is the styler of the package-level functions, detected once.
+var stdout = sync.OnceValue(func() Styler {
+	return NewStyler(os.Stdout)
+})
+
+// Stdout returns the styler for standard output.
+func Stdout() Styler {
+	return stdout()
+}
+
+// Bold renders text in bold on standard output.
+func Bold(text string) string {
+	return stdout().Bold(text)
+}
+
+// Dim renders text with reduced intensity on standard output.
+func Dim(text string) string {
+	return stdout().Dim(text)
+}
+
+// Underline underlines text on standard output.
+func Underline(text string) string {
+	return stdout().Underline(text)
+}
+
+// Red renders text in red on standard output.
+func Red(text string) string {
+	return stdout().Red(text)
+}
+
+// Green renders text in green on standard output.
+func Green(text string) string {
+	return stdout().Green(text)
+}
+
+// Yellow renders text in yellow on standard output.
+func Yellow(text string) string {
+	return stdout().Yellow(text)
+}
+
+// Blue renders text in blue on standard output.
+func Blue(text string) string {
+	return stdout().Blue(text)
+}
+
+// Cyan renders text in cyan on standard output.
+func Cyan(text string) string {
+	return stdout().Cyan(text)
+}
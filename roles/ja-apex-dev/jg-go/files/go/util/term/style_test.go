@@ -0,0 +1,39 @@
+package term_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/term"
+)
+
+//nolint:paralleltest // Modifies the process environment.
+func TestStyler(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+
+	styler := term.NewStyler(&bytes.Buffer{})
+	assert.True(t, styler.Enabled())
+	assert.Equal(t, "\x1b[1mdone\x1b[0m", styler.Bold("done"))
+	assert.Equal(t, "\x1b[2mskipped\x1b[0m", styler.Dim("skipped"))
+	assert.Equal(t, "\x1b[31mfailed\x1b[0m", styler.Red("failed"))
+	assert.Equal(t, "\x1b[32mok\x1b[0m", styler.Green("ok"))
+	assert.Empty(t, styler.Red(""))
+
+	t.Setenv("FORCE_COLOR", "")
+
+	styler = term.NewStyler(&bytes.Buffer{})
+	assert.False(t, styler.Enabled())
+	assert.Equal(t, "failed", styler.Red("failed"))
+}
+
+func TestStylerZero(t *testing.T) {
+	t.Parallel()
+
+	var styler term.Styler
+
+	assert.Equal(t, "text", styler.Bold("text"))
+	assert.Equal(t, "text", styler.Underline("text"))
+}
@@ -0,0 +1,70 @@
+// Package term detects terminal capabilities and styles text with ANSI
+// escape sequences that disappear when output is piped:
+//
+//	fmt.Println(term.Bold("done"), term.Dim("in 3s"))
+//
+//	styler := term.NewStyler(os.Stderr)
+//	fmt.Fprintln(os.Stderr, styler.Red("failed"))
+//
+// Colors follow the NO_COLOR (https://no-color.org) and FORCE_COLOR
+// conventions and are disabled for the dumb terminal.
+package term
+
+import (
+	"io"
+	"os"
+	"strconv"
+
+	xterm "golang.org/x/term"
+)
+
+// DefaultWidth is the width assumed for output that is not a terminal.
+const DefaultWidth = 80
+
+// fder is implemented by *os.File and other writers backed by a file
+// descriptor.
+type fder interface {
+	Fd() uintptr
+}
+
+// IsTerminal reports whether w writes to a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(fder)
+
+	return ok && xterm.IsTerminal(int(f.Fd()))
+}
+
+// ColorEnabled reports whether ANSI styling should be written to w: a
+// non-empty NO_COLOR disables it, then a non-empty FORCE_COLOR enables it
+// even for pipes. Otherwise w must be a terminal other than TERM=dumb.
+func ColorEnabled(w io.Writer) bool {
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		return false
+	case os.Getenv("FORCE_COLOR") != "":
+		return true
+	case os.Getenv("TERM") == "dumb":
+		return false
+	default:
+		return IsTerminal(w)
+	}
+}
+
+// Width returns the number of columns of the terminal behind w. For other
+// writers, it falls back to the COLUMNS environment variable, then to
+// DefaultWidth.
+func Width(w io.Writer) int {
+	if f, ok := w.(fder); ok && IsTerminal(w) {
+		if width, _, err := xterm.GetSize(int(f.Fd())); err == nil &&
+			width > 0 {
+			return width
+		}
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil &&
+		columns > 0 {
+		return columns
+	}
+
+	return DefaultWidth
+}
@@ -0,0 +1,54 @@
+package term_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/term"
+)
+
+func TestIsTerminal(t *testing.T) {
+	t.Parallel()
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	defer reader.Close()
+	defer writer.Close()
+
+	assert.False(t, term.IsTerminal(writer))
+	assert.False(t, term.IsTerminal(&bytes.Buffer{}))
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestColorEnabled(t *testing.T) {
+	cases := []struct {
+		noColor, forceColor, term string
+		want                      bool
+	}{
+		{"", "", "xterm", false},
+		{"", "1", "xterm", true},
+		{"", "1", "dumb", true},
+		{"1", "1", "xterm", false},
+	}
+	for _, c := range cases {
+		t.Setenv("NO_COLOR", c.noColor)
+		t.Setenv("FORCE_COLOR", c.forceColor)
+		t.Setenv("TERM", c.term)
+
+		assert.Equal(t, c.want, term.ColorEnabled(&bytes.Buffer{}), c)
+	}
+}
+
+//nolint:paralleltest // Modifies the process environment.
+func TestWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "132")
+	assert.Equal(t, 132, term.Width(&bytes.Buffer{}))
+
+	t.Setenv("COLUMNS", "wide")
+	assert.Equal(t, term.DefaultWidth, term.Width(&bytes.Buffer{}))
+}
@@ -0,0 +1,77 @@
+// Package testx layers helpers over testify for common test chores:
+// polling for asynchronous conditions, comparing output against golden
+// files and laying out temporary directory trees.
+//
+// Golden files live in the testdata directory of the package under test
+// and are rewritten by running the tests with the -update flag:
+//
+//	go test ./... -run TestRender -update
+package testx
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultTick is the polling interval of RequireEventually.
+const DefaultTick = 10 * time.Millisecond
+
+// GoldenDir is the directory holding golden files, relative to the package
+// under test.
+const GoldenDir = "testdata"
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// RequireEventually polls cond every DefaultTick and fails the test now if
+// it does not hold within timeout.
+func RequireEventually(
+	t testing.TB, cond func() bool, timeout time.Duration, msgAndArgs ...any,
+) {
+	t.Helper()
+
+	require.Eventually(t, cond, timeout, min(DefaultTick, timeout),
+		msgAndArgs...)
+}
+
+// Golden compares got with the golden file GoldenDir/name.golden, failing
+// the test with a diff on mismatch. With -update the file is written
+// instead.
+func Golden[T ~string | ~[]byte](t testing.TB, name string, got T) {
+	t.Helper()
+
+	path := filepath.Join(GoldenDir, name+".golden")
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o600))
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with -update to create it")
+	assert.Equal(t, string(want), string(got), "golden file %s", path)
+}
+
+// TempDirWithFiles returns a temporary directory removed after the test,
+// populated with files mapping slash-separated relative paths to contents.
+// Parent directories are created as needed.
+func TempDirWithFiles(t testing.TB, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return dir
+}
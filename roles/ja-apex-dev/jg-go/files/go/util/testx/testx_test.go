@@ -0,0 +1,49 @@
+package testx_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/testx"
+)
+
+func TestRequireEventually(t *testing.T) {
+	t.Parallel()
+
+	var ready atomic.Bool
+
+	time.AfterFunc(20*time.Millisecond, func() { ready.Store(true) })
+
+	testx.RequireEventually(t, ready.Load, time.Second)
+}
+
+func TestGolden(t *testing.T) {
+	t.Parallel()
+
+	testx.Golden(t, "greeting", "Hello, golden!\n")
+	testx.Golden(t, "greeting", []byte("Hello, golden!\n"))
+}
+
+func TestTempDirWithFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"config.yaml":     "name: demo\n",
+		"nested/a/b.txt":  "deep",
+		"nested/empty.md": "",
+	})
+
+	data, err := os.ReadFile(filepath.Join(dir, "nested", "a", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "deep", string(data))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "nested"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,115 @@
+package util
+
+import (
+	"strings"
+)
+
+// Indent prefixes every non-empty line of s with prefix. Empty lines stay
+// empty, so that embedded YAML or code gains no trailing whitespace.
+func Indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Dedent removes the longest run of spaces and tabs shared by the start of
+// every non-blank line of s, and empties blank lines. It undoes Indent and
+// lets indented raw string literals hold snippets.
+func Dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	common := ""
+	found := false
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		margin := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			common, found = margin, true
+
+			continue
+		}
+
+		common = commonPrefix(common, margin)
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = line[len(common):]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Wrap breaks the lines of s at spaces so that they hold at most width
+// grapheme clusters. Words longer than width are kept whole on their own
+// line. Each wrapped line keeps the indentation of its source line, and
+// runs of spaces between words collapse into one. A width of zero or less
+// returns s unchanged.
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func wrapLine(line string, width int) string {
+	margin := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	words := strings.Fields(line)
+
+	if len(words) == 0 || GraphemeLen(line) <= width {
+		return line
+	}
+
+	var b strings.Builder
+
+	b.WriteString(margin)
+	used := GraphemeLen(margin)
+	start := used
+
+	for _, word := range words {
+		size := GraphemeLen(word)
+
+		switch {
+		case used == start:
+		case used+1+size > width:
+			b.WriteString("\n" + margin)
+			used = start
+		default:
+			b.WriteByte(' ')
+			used++
+		}
+
+		b.WriteString(word)
+		used += size
+	}
+
+	return b.String()
+}
+
+func commonPrefix(a, b string) string {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return a[:i]
+		}
+	}
+
+	return a[:n]
+}
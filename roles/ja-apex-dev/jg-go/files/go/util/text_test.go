@@ -0,0 +1,84 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/quick"
+)
+
+func TestIndent(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "  a: 1\n\n  b: 2\n", util.Indent("a: 1\n\nb: 2\n", "  "))
+	assert.Equal(t, "", util.Indent("", "\t"))
+}
+
+func TestDedent(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"\t\tif ok {\n\t\t\treturn\n\t\t}\n": "if ok {\n\treturn\n}\n",
+		"    a\n  b\n      c":                "  a\nb\n    c",
+		"  a\n   \n  b":                      "a\n\nb",
+		"\ta\n  b":                           "\ta\n  b",
+		"no margin":                          "no margin",
+		"":                                   "",
+	}
+	for input, want := range cases {
+		assert.Equal(t, want, util.Dedent(input), input)
+	}
+
+	assert.Equal(t, "x:\n  y", util.Dedent(util.Indent("x:\n  y", "    ")))
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input string
+		width int
+		want  string
+	}{
+		{"the quick brown fox jumps", 10, "the quick\nbrown fox\njumps"},
+		{"short", 10, "short"},
+		{"a veryveryverylongword b", 8, "a\nveryveryverylongword\nb"},
+		{"  indented text wraps here", 12, "  indented\n  text wraps\n  here"},
+		{"one two\n\nthree four", 7, "one two\n\nthree\nfour"},
+		{"héllo wörld ünïcode", 11, "héllo wörld\nünïcode"},
+		{"unchanged   spacing", 0, "unchanged   spacing"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, util.Wrap(c.input, c.width), c.input)
+	}
+}
+
+func TestDedentIndentProperty(t *testing.T) {
+	t.Parallel()
+
+	lines := quick.Lines(quick.Choose(quick.Strings(20), quick.UTF8Edges(3)), 6)
+	quick.Check(t, lines, func(t *quick.T, s string) {
+		want := util.Dedent(s)
+		assert.Equal(t, want, util.Dedent(util.Indent(want, "\t ")))
+	})
+}
+
+func FuzzDedent(f *testing.F) {
+	for _, seed := range []string{
+		"  a\n    b\n  c", "\ta\n\t\tb", "\t a\n \tb", "  \n  a\n \t\n", "",
+		"a\n  b", "  a\n  b", "\r\n  a\r\n  b",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		got := util.Dedent(s)
+
+		assert.Equal(t, strings.Count(s, "\n"), strings.Count(got, "\n"))
+		assert.Equal(t, got, util.Dedent(got))
+		assert.Equal(t, got, util.Dedent(util.Indent(got, "  ")))
+	})
+}
@@ -0,0 +1,103 @@
+// Package tpl renders text/template templates with a function map exposing
+// the util string helpers. Templates fail on missing map keys instead of
+// printing "<no value>".
+package tpl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"example.com/go-template/util"
+)
+
+// FuncMap returns the functions available to templates. Arguments are
+// ordered so the subject can be piped, as in {{ .Name | truncate 10 }}.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"join": func(sep string, items []string) string {
+			return util.JoinStringsWith(sep, items...)
+		},
+		"joinList": func(items []string) string {
+			return util.JoinStrings(items...)
+		},
+		"snake":     util.ToSnakeCase,
+		"kebab":     util.ToKebabCase,
+		"camel":     util.ToCamelCase,
+		"pascal":    util.ToPascalCase,
+		"title":     util.ToTitle,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"truncate":  truncate,
+		"ellipsize": ellipsize,
+		"indent":    indent,
+		"nindent":   nindent,
+		"dedent":    util.Dedent,
+		"wrap":      wrap,
+	}
+}
+
+// New returns an empty template with FuncMap and the missing-key error
+// mode.
+func New(name string) *template.Template {
+	return template.New(name).Option("missingkey=error").Funcs(FuncMap())
+}
+
+// RenderString parses and executes text with data.
+func RenderString(text string, data any) (string, error) {
+	t, err := New("inline").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("tpl: parse: %w", err)
+	}
+
+	return execute(t, data)
+}
+
+// RenderFile parses and executes the template stored at path with data.
+func RenderFile(path string, data any) (string, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("tpl: %w", err)
+	}
+
+	t, err := New(filepath.Base(path)).Parse(string(text))
+	if err != nil {
+		return "", fmt.Errorf("tpl: parse %s: %w", path, err)
+	}
+
+	return execute(t, data)
+}
+
+func execute(t *template.Template, data any) (string, error) {
+	var out strings.Builder
+	if err := t.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("tpl: render: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+func truncate(n int, s string) string {
+	return util.Truncate(s, n)
+}
+
+func ellipsize(n int, s string) string {
+	return util.Ellipsize(s, n, util.DefaultEllipsis)
+}
+
+// indent prefixes every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	return util.Indent(s, strings.Repeat(" ", max(n, 0)))
+}
+
+func wrap(width int, s string) string {
+	return util.Wrap(s, width)
+}
+
+// nindent is indent preceded by a newline, for values placed after a key.
+func nindent(n int, s string) string {
+	return "\n" + indent(n, s)
+}
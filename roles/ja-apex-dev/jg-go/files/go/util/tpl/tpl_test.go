@@ -0,0 +1,66 @@
+package tpl_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/tpl"
+)
+
+func TestRenderString(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"Name":  "HTTPServer config",
+		"Hosts": []string{"a", "b", "c"},
+		"Body":  "key: value\nother: 1",
+	}
+
+	for text, want := range map[string]string{
+		`{{ .Name | snake }}`:              "http_server_config",
+		`{{ .Name | kebab }}`:              "http-server-config",
+		`{{ .Name | pascal }}`:             "HttpServerConfig",
+		`{{ .Hosts | join "," }}`:          "a,b,c",
+		`{{ .Hosts | joinList }}`:          "a, b, c",
+		`{{ .Name | truncate 4 }}`:         "HTTP",
+		`{{ .Name | ellipsize 5 }}`:        "HTTP…",
+		`spec:{{ .Body | nindent 2 }}`:     "spec:\n  key: value\n  other: 1",
+		`{{ "x\n\ny" | indent 1 }}`:        " x\n\n y",
+		`{{ "  a\n    b" | dedent }}`:      "a\n  b",
+		`{{ "aa bb cc" | wrap 5 }}`:        "aa bb\ncc",
+		`{{ "  padded " | trim | upper }}`: "PADDED",
+	} {
+		got, err := tpl.RenderString(text, data)
+		require.NoError(t, err, text)
+		assert.Equal(t, want, got, text)
+	}
+}
+
+func TestRenderStringErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := tpl.RenderString(`{{ .Missing }}`, map[string]any{})
+	require.ErrorContains(t, err, "Missing")
+
+	_, err = tpl.RenderString(`{{ .Name`, nil)
+	require.ErrorContains(t, err, "tpl: parse")
+}
+
+func TestRenderFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "service.conf.tmpl")
+	require.NoError(t, os.WriteFile(path,
+		[]byte(`name = {{ .Name | kebab }}`), 0o600))
+
+	got, err := tpl.RenderFile(path, struct{ Name string }{"MyService"})
+	require.NoError(t, err)
+	assert.Equal(t, "name = my-service", got)
+
+	_, err = tpl.RenderFile(filepath.Join(t.TempDir(), "missing"), nil)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the tracer used by StartSpan.
+const InstrumentationName = "example.com/go-template"
+
+// StartSpan starts a span named name as a child of the span in ctx, if
+// any. The span must be ended by the caller, usually with a deferred End.
+func StartSpan(
+	ctx context.Context, name string, attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name,
+		trace.WithAttributes(attrs...))
+}
+
+// RecordError marks span as failed with err and returns err, so that it
+// fits in return statements. A nil err leaves the span untouched.
+func RecordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// TraceID returns the trace ID of the span in ctx, or an empty string
+// outside of a trace, for correlating logs with traces.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// Inject writes the trace context of ctx into the headers of an outgoing
+// request.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns ctx carrying the trace context found in the headers of
+// an incoming request.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx,
+		propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,57 @@
+package tracing_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"example.com/go-template/util/tracing"
+)
+
+var errQuery = errors.New("query failed")
+
+//nolint:paralleltest // Modifies the global tracer provider.
+func TestStartSpan(t *testing.T) {
+	exporter := install(t)
+
+	ctx, parent := tracing.StartSpan(t.Context(), "request",
+		attribute.String("route", "/users"))
+	_, child := tracing.StartSpan(ctx, "query")
+
+	require.ErrorIs(t, tracing.RecordError(child, errQuery), errQuery)
+	require.NoError(t, tracing.RecordError(parent, nil))
+	child.End()
+	parent.End()
+	flush(t)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	query, request := spans[0], spans[1]
+	assert.Equal(t, codes.Error, query.Status.Code)
+	assert.Equal(t, request.SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Contains(t, request.Attributes, attribute.String("route", "/users"))
+	assert.Equal(t, codes.Unset, request.Status.Code)
+}
+
+//nolint:paralleltest // Modifies the global tracer provider.
+func TestPropagation(t *testing.T) {
+	install(t)
+
+	assert.Empty(t, tracing.TraceID(t.Context()))
+
+	ctx, span := tracing.StartSpan(t.Context(), "client")
+	defer span.End()
+
+	header := http.Header{}
+	tracing.Inject(ctx, header)
+	assert.NotEmpty(t, header.Get("Traceparent"))
+
+	remote := tracing.Extract(t.Context(), header)
+	assert.Equal(t, tracing.TraceID(ctx), tracing.TraceID(remote))
+}
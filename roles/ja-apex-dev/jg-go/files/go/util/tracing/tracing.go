@@ -0,0 +1,150 @@
+// Package tracing bootstraps OpenTelemetry distributed tracing in one
+// call and wraps the span and propagation APIs services use daily.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Environment variables configuring the OTLP exporter. Exporting is only
+// enabled when one of them is set; the exporter reads the other standard
+// OTEL_EXPORTER_OTLP_* variables, such as headers, by itself.
+const (
+	EnvEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// Option configures Setup.
+type Option func(*settings)
+
+type settings struct {
+	service  string
+	version  string
+	ratio    float64
+	exporter sdktrace.SpanExporter
+}
+
+// WithService sets the service name and version reported with every span.
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence.
+func WithService(name, version string) Option {
+	return func(s *settings) {
+		s.service, s.version = name, version
+	}
+}
+
+// WithSampleRatio samples the given fraction of the traces started here,
+// following the decision of the caller for propagated ones. Every trace is
+// sampled by default.
+func WithSampleRatio(ratio float64) Option {
+	return func(s *settings) {
+		s.ratio = ratio
+	}
+}
+
+// WithExporter replaces the OTLP exporter, for tests or other backends.
+func WithExporter(exporter sdktrace.SpanExporter) Option {
+	return func(s *settings) {
+		s.exporter = exporter
+	}
+}
+
+// Setup installs a global tracer provider and the W3C trace context and
+// baggage propagators. Spans are exported with OTLP over HTTP when an
+// endpoint is set in the environment, and only propagated otherwise. The
+// returned function flushes pending spans and must be called on shutdown.
+func Setup(
+	ctx context.Context, opts ...Option,
+) (shutdown func(context.Context) error, err error) {
+	s := settings{ratio: 1}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	res, err := newResource(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(s.ratio))),
+	}
+
+	exporter, err := newExporter(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("tracing: shutdown: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// newResource describes the service, letting the environment override the
+// configured names.
+func newResource(ctx context.Context, s settings) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	if s.service != "" {
+		attrs = append(attrs, attribute.String("service.name", s.service))
+	}
+
+	if s.version != "" {
+		attrs = append(attrs, attribute.String("service.version", s.version))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv())
+	if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+		return nil, fmt.Errorf("tracing: resource: %w", err)
+	}
+
+	return res, nil
+}
+
+// newExporter returns the configured exporter, or nil when spans are not
+// exported.
+func newExporter(
+	ctx context.Context, s settings,
+) (sdktrace.SpanExporter, error) {
+	if s.exporter != nil {
+		return s.exporter, nil
+	}
+
+	if os.Getenv(EnvEndpoint) == "" && os.Getenv(EnvTracesEndpoint) == "" {
+		return nil, nil //nolint:nilnil // No endpoint disables exporting.
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: exporter: %w", err)
+	}
+
+	return exporter, nil
+}
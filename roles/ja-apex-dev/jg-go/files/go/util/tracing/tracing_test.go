@@ -0,0 +1,77 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"example.com/go-template/util/tracing"
+)
+
+// install sets up a provider exporting to memory for the test.
+func install(t *testing.T, opts ...tracing.Option) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.Setup(t.Context(),
+		append(opts, tracing.WithExporter(exporter))...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	return exporter
+}
+
+// flush exports the ended spans without waiting for the batcher.
+func flush(t *testing.T) {
+	t.Helper()
+
+	provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, provider.ForceFlush(t.Context()))
+}
+
+//nolint:paralleltest // Modifies the global tracer provider.
+func TestSetup(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=test")
+
+	exporter := install(t, tracing.WithService("api", "1.2.3"))
+
+	_, span := tracing.StartSpan(t.Context(), "work")
+	span.End()
+	flush(t)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	attrs := spans[0].Resource.Attributes()
+	assert.Contains(t, attrs, attribute.String("service.name", "api"))
+	assert.Contains(t, attrs, attribute.String("service.version", "1.2.3"))
+	assert.Contains(t, attrs,
+		attribute.String("deployment.environment", "test"))
+}
+
+//nolint:paralleltest // Modifies the global tracer provider.
+func TestSetupWithoutEndpoint(t *testing.T) {
+	t.Setenv(tracing.EnvEndpoint, "")
+	t.Setenv(tracing.EnvTracesEndpoint, "")
+
+	shutdown, err := tracing.Setup(t.Context(), tracing.WithSampleRatio(0))
+	require.NoError(t, err)
+
+	ctx, span := tracing.StartSpan(t.Context(), "unsampled")
+	span.End()
+
+	assert.NotEmpty(t, tracing.TraceID(ctx), "trace IDs still propagate")
+	assert.False(t, span.SpanContext().IsSampled())
+	require.NoError(t, shutdown(t.Context()))
+}
@@ -0,0 +1,249 @@
+// Package trie provides prefix trees mapping string keys to values, to find
+// the stored prefixes of a key or iterate the keys under a prefix. New
+// compares keys byte by byte; NewPath compares slash-separated segments, so
+// that "src/app" is a prefix of "src/app/main.go" but not of
+// "src/application":
+//
+//	routes := trie.NewPath[string]()
+//	routes.Insert("/api", "api")
+//	routes.Insert("/api/users", "users")
+//	routes.LongestPrefix("/api/users/42") // "api/users", "users", true
+//
+// A Trie is not safe for concurrent writes.
+package trie
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// pathSeparator separates the segments of path keys.
+const pathSeparator = "/"
+
+type node[V any] struct {
+	children map[string]*node[V]
+	key      string
+	value    V
+	set      bool
+}
+
+// Trie maps keys to values. The zero value is an empty trie comparing keys
+// byte by byte.
+type Trie[V any] struct {
+	root node[V]
+	sep  string
+	size int
+}
+
+// New returns an empty trie comparing keys byte by byte.
+func New[V any]() *Trie[V] {
+	return &Trie[V]{}
+}
+
+// NewPath returns an empty trie comparing the slash-separated segments of
+// keys. Empty segments are ignored, so "/a//b/" and "a/b" are the same key,
+// stored and returned as "a/b".
+func NewPath[V any]() *Trie[V] {
+	return &Trie[V]{sep: pathSeparator}
+}
+
+// Len returns the number of keys.
+func (t *Trie[V]) Len() int {
+	return t.size
+}
+
+// Insert stores value under key and reports whether the key is new.
+func (t *Trie[V]) Insert(key string, value V) bool {
+	n := &t.root
+
+	for segment := range t.segments(key) {
+		child, ok := n.children[segment]
+		if !ok {
+			if n.children == nil {
+				n.children = map[string]*node[V]{}
+			}
+
+			child = &node[V]{}
+			n.children[segment] = child
+		}
+
+		n = child
+	}
+
+	added := !n.set
+	if added {
+		t.size++
+	}
+
+	n.key, n.value, n.set = t.clean(key), value, true
+
+	return added
+}
+
+// Get returns the value stored under key.
+func (t *Trie[V]) Get(key string) (V, bool) {
+	n := t.find(key)
+	if n == nil {
+		var zero V
+
+		return zero, false
+	}
+
+	return n.value, n.set
+}
+
+// Delete removes key and reports whether it was stored.
+func (t *Trie[V]) Delete(key string) bool {
+	path := []*node[V]{&t.root}
+	segments := slices.Collect(t.segments(key))
+
+	for _, segment := range segments {
+		next := path[len(path)-1].children[segment]
+		if next == nil {
+			return false
+		}
+
+		path = append(path, next)
+	}
+
+	n := path[len(path)-1]
+	if !n.set {
+		return false
+	}
+
+	*n = node[V]{children: n.children}
+	t.size--
+
+	// Prune the nodes left without keys, deepest first.
+	for i := len(segments); i > 0; i-- {
+		if path[i].set || len(path[i].children) > 0 {
+			break
+		}
+
+		delete(path[i-1].children, segments[i-1])
+	}
+
+	return true
+}
+
+// LongestPrefix returns the longest stored key that is a prefix of key,
+// with its value.
+func (t *Trie[V]) LongestPrefix(
+	key string,
+) (prefix string, value V, ok bool) {
+	for prefix, value = range t.Prefixes(key) {
+		ok = true
+	}
+
+	return prefix, value, ok
+}
+
+// Prefixes iterates the stored keys that are prefixes of key, including key
+// itself, shortest first.
+func (t *Trie[V]) Prefixes(key string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		for n := range t.nodes(key) {
+			if n.set && !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// All iterates every key in lexical order of their segments.
+func (t *Trie[V]) All() iter.Seq2[string, V] {
+	return t.Walk("")
+}
+
+// Walk iterates the keys having prefix as a prefix, including prefix
+// itself, in lexical order of their segments.
+func (t *Trie[V]) Walk(prefix string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		if n := t.find(prefix); n != nil {
+			n.walk(yield)
+		}
+	}
+}
+
+// walk yields the keys of n and its descendants, reporting whether the
+// iteration should go on.
+func (n *node[V]) walk(yield func(string, V) bool) bool {
+	if n.set && !yield(n.key, n.value) {
+		return false
+	}
+
+	for _, segment := range slices.Sorted(maps.Keys(n.children)) {
+		if !n.children[segment].walk(yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// segments iterates the bytes of key, or its non-empty path segments.
+func (t *Trie[V]) segments(key string) iter.Seq[string] {
+	if t.sep == "" {
+		return byteSegments(key)
+	}
+
+	return func(yield func(string) bool) {
+		for segment := range strings.SplitSeq(key, t.sep) {
+			if segment != "" && !yield(segment) {
+				return
+			}
+		}
+	}
+}
+
+// byteSegments iterates the bytes of key as strings.
+func byteSegments(key string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := range len(key) {
+			if !yield(key[i : i+1]) {
+				return
+			}
+		}
+	}
+}
+
+// nodes iterates the nodes along key from the root, stopping at the first
+// missing one.
+func (t *Trie[V]) nodes(key string) iter.Seq[*node[V]] {
+	return func(yield func(*node[V]) bool) {
+		n := &t.root
+		if !yield(n) {
+			return
+		}
+
+		for segment := range t.segments(key) {
+			if n = n.children[segment]; n == nil || !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// find returns the node of key, or nil.
+func (t *Trie[V]) find(key string) *node[V] {
+	n := &t.root
+
+	for segment := range t.segments(key) {
+		if n = n.children[segment]; n == nil {
+			return nil
+		}
+	}
+
+	return n
+}
+
+// clean returns the stored form of key.
+func (t *Trie[V]) clean(key string) string {
+	if t.sep == "" {
+		return key
+	}
+
+	return strings.Join(slices.Collect(t.segments(key)), t.sep)
+}
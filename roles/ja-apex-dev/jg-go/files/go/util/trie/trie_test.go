@@ -0,0 +1,178 @@
+package trie_test
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util/quick"
+	"example.com/go-template/util/trie"
+)
+
+func TestTrie(t *testing.T) {
+	t.Parallel()
+
+	tr := trie.New[int]()
+	assert.True(t, tr.Insert("app", 1))
+	assert.True(t, tr.Insert("apple", 2))
+	assert.True(t, tr.Insert("ap", 3))
+	assert.False(t, tr.Insert("app", 4), "replaced")
+	assert.Equal(t, 3, tr.Len())
+
+	value, ok := tr.Get("app")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+
+	_, ok = tr.Get("appl")
+	assert.False(t, ok, "inner nodes hold no value")
+
+	prefix, value, ok := tr.LongestPrefix("applesauce")
+	assert.True(t, ok)
+	assert.Equal(t, "apple", prefix)
+	assert.Equal(t, 2, value)
+
+	prefix, _, ok = tr.LongestPrefix("application")
+	assert.True(t, ok)
+	assert.Equal(t, "app", prefix)
+
+	_, _, ok = tr.LongestPrefix("a")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"ap", "app"}, keys(tr.Prefixes("appl")))
+}
+
+func TestTrieWalk(t *testing.T) {
+	t.Parallel()
+
+	var tr trie.Trie[bool]
+	for _, key := range []string{"go", "gopher", "golang", "rust", "", "g"} {
+		tr.Insert(key, true)
+	}
+
+	assert.Equal(t, []string{"", "g", "go", "golang", "gopher", "rust"},
+		keys(tr.All()))
+	assert.Equal(t, []string{"go", "golang", "gopher"}, keys(tr.Walk("go")))
+	assert.Equal(t, []string{"golang"}, keys(tr.Walk("gol")))
+	assert.Empty(t, keys(tr.Walk("java")))
+
+	var visited []string
+
+	for key := range tr.All() {
+		if visited = append(visited, key); key == "go" {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"", "g", "go"}, visited)
+}
+
+func TestTrieDelete(t *testing.T) {
+	t.Parallel()
+
+	tr := trie.New[int]()
+	tr.Insert("team", 1)
+	tr.Insert("tea", 2)
+
+	assert.False(t, tr.Delete("te"))
+	assert.False(t, tr.Delete("teams"))
+	assert.True(t, tr.Delete("team"))
+	assert.False(t, tr.Delete("team"))
+	assert.Equal(t, 1, tr.Len())
+
+	prefix, _, _ := tr.LongestPrefix("team")
+	assert.Equal(t, "tea", prefix)
+
+	assert.True(t, tr.Delete("tea"))
+	assert.Empty(t, keys(tr.All()))
+	assert.Zero(t, tr.Len())
+}
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	routes := trie.NewPath[string]()
+	routes.Insert("/", "index")
+	routes.Insert("/api", "api")
+	routes.Insert("/api/users/", "users")
+	assert.False(t, routes.Insert("api//users", "people"))
+
+	for path, want := range map[string]string{
+		"/api/users/42":     "api/users",
+		"/api/usersettings": "api",
+		"/apidocs":          "",
+		"api":               "api",
+	} {
+		prefix, _, ok := routes.LongestPrefix(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, want, prefix, path)
+	}
+
+	value, _ := routes.Get("/api/users")
+	assert.Equal(t, "people", value)
+	assert.Equal(t, []string{"api", "api/users"}, keys(routes.Walk("api/")))
+}
+
+func TestPathIgnore(t *testing.T) {
+	t.Parallel()
+
+	excluded := trie.NewPath[struct{}]()
+	for _, dir := range []string{"node_modules", "build/cache", ".git"} {
+		excluded.Insert(dir, struct{}{})
+	}
+
+	for path, want := range map[string]bool{
+		"node_modules/left-pad/index.js": true,
+		"build/cache/obj.o":              true,
+		"build/out/app":                  false,
+		"src/node_modules.go":            false,
+		".gitignore":                     false,
+	} {
+		_, _, ok := excluded.LongestPrefix(path)
+		assert.Equal(t, want, ok, path)
+	}
+}
+
+func TestTrieProperty(t *testing.T) {
+	t.Parallel()
+
+	words := quick.SliceOf(quick.Strings(4), 20)
+	quick.Check(t, quick.Zip(words, quick.Strings(6)),
+		func(t *quick.T, input quick.Pair[[]string, string]) {
+			tr := trie.New[int]()
+			stored := map[string]int{}
+
+			for i, word := range input.First {
+				tr.Insert(word, i)
+				stored[word] = i
+			}
+
+			want, found := "", false
+
+			for word := range stored {
+				if strings.HasPrefix(input.Second, word) &&
+					(!found || len(word) > len(want)) {
+					want, found = word, true
+				}
+			}
+
+			prefix, value, ok := tr.LongestPrefix(input.Second)
+			assert.Equal(t, found, ok)
+			assert.Equal(t, want, prefix)
+			assert.Equal(t, stored[want], value)
+			assert.Equal(t, slices.Sorted(maps.Keys(stored)), keys(tr.All()))
+		})
+}
+
+// keys collects the keys of seq in order.
+func keys[V any](seq iter.Seq2[string, V]) []string {
+	var collected []string
+	for key := range seq {
+		collected = append(collected, key)
+	}
+
+	return collected
+}
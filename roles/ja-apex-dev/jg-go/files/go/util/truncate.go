@@ -0,0 +1,49 @@
+package util
+
+import (
+	"github.com/rivo/uniseg"
+)
+
+// DefaultEllipsis is the suffix commonly passed to Ellipsize.
+const DefaultEllipsis = "…"
+
+// GraphemeLen returns the number of user-perceived characters (extended
+// grapheme clusters) in s.
+func GraphemeLen(s string) int {
+	return uniseg.GraphemeClusterCount(s)
+}
+
+// Truncate returns at most n grapheme clusters of s, never splitting a
+// multi-byte character, combining sequence or emoji.
+func Truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	state := -1
+	rest := s
+	count := 0
+
+	for rest != "" && count < n {
+		_, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		count++
+	}
+
+	return s[:len(s)-len(rest)]
+}
+
+// Ellipsize shortens s to at most n grapheme clusters including suffix,
+// which replaces the removed tail. Strings that fit are returned unchanged,
+// and s is simply truncated when suffix itself does not fit.
+func Ellipsize(s string, n int, suffix string) string {
+	if GraphemeLen(s) <= n {
+		return s
+	}
+
+	keep := n - GraphemeLen(suffix)
+	if keep < 0 {
+		return Truncate(s, n)
+	}
+
+	return Truncate(s, keep) + suffix
+}
@@ -0,0 +1,79 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/go-template/util"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input string
+		n     int
+		want  string
+	}{
+		{"hello", 3, "hel"},
+		{"hello", 10, "hello"},
+		{"hello", 0, ""},
+		{"héllo", 2, "hé"},
+		{"日本語テキスト", 3, "日本語"},
+		{"école", 1, "é"},
+		{"👍🏽👍", 1, "👍🏽"},
+		{"🇺🇦🇵🇱", 1, "🇺🇦"},
+		{"👩‍👩‍👧 family", 2, "👩‍👩‍👧 "},
+	}
+	for _, tc := range cases {
+		got := util.Truncate(tc.input, tc.n)
+		assert.Equal(t, tc.want, got, tc.input)
+		assert.True(t, utf8.ValidString(got), tc.input)
+	}
+}
+
+func TestEllipsize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "hello", util.Ellipsize("hello", 5, util.DefaultEllipsis))
+	assert.Equal(t, "hell…", util.Ellipsize("hello!", 5, util.DefaultEllipsis))
+	assert.Equal(t, "he...", util.Ellipsize("hello!", 5, "..."))
+	assert.Equal(t, "he", util.Ellipsize("hello!", 2, "..."))
+	assert.Equal(t, "日本…", util.Ellipsize("日本語テキスト", 3, "…"))
+}
+
+func TestGraphemeLen(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 5, util.GraphemeLen("héllo"))
+	assert.Equal(t, 2, util.GraphemeLen("👍🏽🇺🇦"))
+}
+
+func FuzzTruncate(f *testing.F) {
+	f.Add("hello", 3)
+	f.Add("héllo wörld", 4)
+	f.Add("👩‍👩‍👧‍👦 family", 1)
+	f.Add("é́", 1)
+	f.Add("\xff\xfe invalid", 2)
+	f.Add("", -1)
+
+	f.Fuzz(func(t *testing.T, s string, n int) {
+		got := util.Truncate(s, n)
+
+		assert.True(t, strings.HasPrefix(s, got))
+		assert.LessOrEqual(t, util.GraphemeLen(got), max(n, 0))
+
+		if utf8.ValidString(s) {
+			assert.True(t, utf8.ValidString(got))
+		}
+
+		if n >= util.GraphemeLen(s) {
+			assert.Equal(t, s, got)
+		}
+
+		ellipsized := util.Ellipsize(s, n, util.DefaultEllipsis)
+		assert.LessOrEqual(t, util.GraphemeLen(ellipsized), max(n, 0))
+	})
+}
@@ -1,9 +1,114 @@
+// Package util provides small helpers shared across the template. They
+// only depend on the standard library, except for the grapheme-aware
+// truncation, which segments text with github.com/rivo/uniseg.
 package util
 
 import (
 	"strings"
 )
 
+// DefaultSeparator is the separator used by JoinStrings.
+const DefaultSeparator = ", "
+
+// JoinOption configures a Joiner.
+type JoinOption func(*Joiner)
+
+// Joiner joins string items with a separator, optionally skipping empty
+// items, trimming whitespace and using a conjunction before the last item.
+type Joiner struct {
+	sep         string
+	conjunction string
+	skipEmpty   bool
+	trimSpace   bool
+}
+
+// WithSkipEmpty drops empty items (after trimming, if enabled).
+func WithSkipEmpty() JoinOption {
+	return func(j *Joiner) {
+		j.skipEmpty = true
+	}
+}
+
+// WithTrimSpace trims leading and trailing whitespace of every item.
+func WithTrimSpace() JoinOption {
+	return func(j *Joiner) {
+		j.trimSpace = true
+	}
+}
+
+// WithConjunction replaces the last separator with the given word, producing
+// human-readable lists such as "A, B and C".
+func WithConjunction(word string) JoinOption {
+	return func(j *Joiner) {
+		j.conjunction = word
+	}
+}
+
+// NewJoiner creates a Joiner using the separator and options.
+func NewJoiner(sep string, opts ...JoinOption) *Joiner {
+	j := &Joiner{sep: sep}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return j
+}
+
+// Join joins the items according to the Joiner configuration.
+func (j *Joiner) Join(items ...string) string {
+	parts := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if j.trimSpace {
+			item = strings.TrimSpace(item)
+		}
+
+		if j.skipEmpty && item == "" {
+			continue
+		}
+
+		parts = append(parts, item)
+	}
+
+	if j.conjunction == "" || len(parts) < 2 {
+		return strings.Join(parts, j.sep)
+	}
+
+	last := len(parts) - 1
+
+	return strings.Join(parts[:last], j.sep) +
+		" " + j.conjunction + " " + parts[last]
+}
+
+// JoinStringsWith joins the items using the separator. It allocates the
+// result only, and nothing for fewer than two items.
+func JoinStringsWith(sep string, items ...string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+
+	n := len(sep) * (len(items) - 1)
+	for _, item := range items {
+		n += len(item)
+	}
+
+	var b strings.Builder
+	b.Grow(n)
+	b.WriteString(items[0])
+
+	for _, item := range items[1:] {
+		b.WriteString(sep)
+		b.WriteString(item)
+	}
+
+	return b.String()
+}
+
+// JoinStrings joins the items using DefaultSeparator. It allocates the
+// result only, for use in logging and formatting loops.
 func JoinStrings(parts ...string) string {
-	return strings.Join(parts, ", ")
+	return JoinStringsWith(DefaultSeparator, parts...)
 }
@@ -1,11 +1,84 @@
 package util_test
 
 import (
+	"strconv"
 	"testing"
+
 	"github.com/stretchr/testify/assert"
+
 	"example.com/go-template/util"
+	"example.com/go-template/util/benchx"
 )
 
+var sink string
+
 func TestJoinStringsSeparator(t *testing.T) {
 	assert.Equal(t, "One, Two", util.JoinStrings("One", "Two"))
 }
+
+func TestJoinStringsWith(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "a/b/c", util.JoinStringsWith("/", "a", "b", "c"))
+	assert.Empty(t, util.JoinStringsWith("/"))
+}
+
+//nolint:paralleltest // Allocations are counted process-wide.
+func TestJoinStringsAllocs(t *testing.T) {
+	parts := []string{"alpha", "beta", "gamma", "delta", "epsilon"}
+
+	for n := range len(parts) + 1 {
+		// The result is the only allocation, and a single part is
+		// returned as is.
+		want := 0.0
+		if n >= 2 {
+			want = 1
+		}
+
+		benchx.AssertAllocsPerOp(t, want, func() {
+			sink = util.JoinStrings(parts[:n]...)
+		}, "%d parts", n)
+	}
+
+	benchx.AssertAllocsPerOp(t, 1, func() {
+		sink = util.JoinStrings("alpha", "beta", "gamma")
+	}, "variadic arguments")
+}
+
+func TestJoinerOptions(t *testing.T) {
+	t.Parallel()
+
+	joiner := util.NewJoiner(
+		", ",
+		util.WithTrimSpace(),
+		util.WithSkipEmpty(),
+		util.WithConjunction("and"),
+	)
+
+	assert.Equal(t, "One, Two and Three",
+		joiner.Join(" One", "", "Two ", "  ", "Three"))
+	assert.Equal(t, "One and Two", joiner.Join("One", "Two"))
+	assert.Equal(t, "One", joiner.Join("One", " "))
+	assert.Empty(t, joiner.Join())
+}
+
+func BenchmarkJoinStrings(b *testing.B) {
+	parts := []string{"alpha", "beta", "gamma", "delta", "epsilon", "zeta"}
+
+	for _, n := range []int{2, 3, 4, 6} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for b.Loop() {
+				sink = util.JoinStrings(parts[:n]...)
+			}
+		})
+	}
+
+	b.Run("literal", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for b.Loop() {
+			sink = util.JoinStrings("alpha", "beta", "gamma")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"example.com/go-template/util"
+)
+
+// measure returns the number compared by min and max: the rune count of
+// strings, the length of collections and the value of numbers.
+func measure(value reflect.Value) (float64, string, error) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), "characters",
+			nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), "items", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return float64(value.Int()), "", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), "", nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "", nil
+	default:
+		return 0, "", fmt.Errorf("%w: cannot measure %s", ErrBadRule,
+			value.Type())
+	}
+}
+
+// bound parses the limit of min and max and measures value.
+func bound(value reflect.Value, param string) (
+	n, limit float64, unit string, err error,
+) {
+	limit, err = strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("%w: limit %q", ErrBadRule, param)
+	}
+
+	n, unit, err = measure(value)
+
+	return n, limit, unit, err
+}
+
+// minRule enforces a lower bound, see measure.
+func minRule(value reflect.Value, param string) error {
+	n, limit, unit, err := bound(value, param)
+
+	switch {
+	case err != nil:
+		return err
+	case n >= limit:
+		return nil
+	case unit != "":
+		return fmt.Errorf("must have at least %s %s", param, unit)
+	default:
+		return fmt.Errorf("must be at least %s", param)
+	}
+}
+
+// maxRule enforces an upper bound, see measure.
+func maxRule(value reflect.Value, param string) error {
+	n, limit, unit, err := bound(value, param)
+
+	switch {
+	case err != nil:
+		return err
+	case n <= limit:
+		return nil
+	case unit != "":
+		return fmt.Errorf("must have at most %s %s", param, unit)
+	default:
+		return fmt.Errorf("must be at most %s", param)
+	}
+}
+
+// oneOfRule accepts space-separated choices compared with the formatted
+// value.
+func oneOfRule(value reflect.Value, param string) error {
+	choices := strings.Fields(param)
+	if len(choices) == 0 {
+		return fmt.Errorf("%w: oneof without choices", ErrBadRule)
+	}
+
+	if slices.Contains(choices, fmt.Sprint(value.Interface())) {
+		return nil
+	}
+
+	joiner := util.NewJoiner(util.DefaultSeparator,
+		util.WithConjunction("or"))
+
+	return errors.New("must be one of " + joiner.Join(choices...))
+}
+
+// patterns caches compiled expressions by source.
+var patterns sync.Map
+
+// regexpRule matches strings against the rest of the tag.
+func regexpRule(value reflect.Value, param string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("%w: regexp on %s", ErrBadRule, value.Type())
+	}
+
+	cached, ok := patterns.Load(param)
+	if !ok {
+		pattern, err := regexp.Compile(param)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBadRule, err)
+		}
+
+		cached, _ = patterns.LoadOrStore(param, pattern)
+	}
+
+	pattern, _ := cached.(*regexp.Regexp)
+	if !pattern.MatchString(value.String()) {
+		return fmt.Errorf("must match %s", param)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package validate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/validate"
+)
+
+type limits struct {
+	Name    string        `validate:"min=2,max=4"`
+	Tags    []string      `validate:"max=2"`
+	Ratio   float64       `validate:"min=0.5"`
+	Timeout time.Duration `validate:"max=1000000000"`
+	Level   string        `validate:"oneof=debug info warn"`
+	Code    int           `validate:"oneof=200 404"`
+	Slug    *string       `validate:"regexp=^[a-z]+(-[a-z]+)*$"`
+}
+
+func TestBuiltinRules(t *testing.T) {
+	t.Parallel()
+
+	slug := "ok-slug"
+	require.NoError(t, validate.Struct(limits{
+		Name: "ñañá", Tags: []string{"a"}, Ratio: 0.5, Timeout: time.Second,
+		Level: "info", Code: 404, Slug: &slug,
+	}))
+
+	require.NoError(t, validate.Struct(limits{}),
+		"zero values skip every rule but required")
+
+	bad := "Bad,Slug"
+	err := validate.Struct(limits{
+		Name: "x", Tags: []string{"a", "b", "c"}, Ratio: 0.1,
+		Timeout: time.Minute, Level: "trace", Code: 500, Slug: &bad,
+	})
+
+	var violations validate.Violations
+	require.ErrorAs(t, err, &violations)
+
+	messages := make(map[string]string, len(violations))
+	for _, violation := range violations {
+		messages[violation.Field] = violation.Message
+	}
+
+	assert.Equal(t, map[string]string{
+		"Name":    "must have at least 2 characters",
+		"Tags":    "must have at most 2 items",
+		"Ratio":   "must be at least 0.5",
+		"Timeout": "must be at most 1000000000",
+		"Level":   "must be one of debug, info or warn",
+		"Code":    "must be one of 200 or 404",
+		"Slug":    "must match ^[a-z]+(-[a-z]+)*$",
+	}, messages)
+}
+
+func TestBadRuleParameters(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorIs(t, validate.Struct(struct {
+		N int `validate:"max=lots"`
+	}{1}), validate.ErrBadRule)
+
+	require.ErrorIs(t, validate.Struct(struct {
+		S string `validate:"regexp=("`
+	}{"x"}), validate.ErrBadRule)
+
+	require.ErrorIs(t, validate.Struct(struct {
+		N int `validate:"regexp=^1$"`
+	}{1}), validate.ErrBadRule)
+
+	require.ErrorIs(t, validate.Struct(struct {
+		S string `validate:"oneof="`
+	}{"x"}), validate.ErrBadRule)
+}
@@ -0,0 +1,338 @@
+// Package validate checks struct fields against rules declared in
+// `validate` tags and reports every violation with its field path:
+//
+//	type Request struct {
+//		Name  string   `json:"name" validate:"required,max=64"`
+//		Role  string   `json:"role" validate:"oneof=admin user"`
+//		Email string   `json:"email" validate:"regexp=^[^@]+@[^@]+$"`
+//		Tags  []string `json:"tags" validate:"max=10"`
+//	}
+//
+// Rules other than required are skipped for zero values, so optional
+// fields are only checked when set. A non-nil pointer is set, even to a
+// zero value, and its rules apply to the value it points to. The regexp
+// rule consumes the rest of the tag and must come last. Nested structs,
+// pointers, slices and maps are walked recursively.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag holding the rules.
+const TagName = "validate"
+
+var (
+	// ErrInvalidTarget is returned when validating anything but a struct.
+	ErrInvalidTarget = errors.New("validate: target must be a struct")
+	// ErrUnknownRule is returned for tags naming an unregistered rule.
+	ErrUnknownRule = errors.New("validate: unknown rule")
+	// ErrBadRule is wrapped by rules applied to an unsupported type or
+	// given a malformed parameter. Such errors abort validation instead of
+	// being reported as violations.
+	ErrBadRule = errors.New("validate: bad rule")
+)
+
+// Func checks value against param, the text after "=" in the tag. Any
+// error is reported as a violation using its text as the message, unless
+// it wraps ErrBadRule. Pointers are dereferenced and zero values never
+// reach the function.
+type Func func(value reflect.Value, param string) error
+
+// Violation describes a field failing a rule.
+type Violation struct {
+	// Field is the path to the field, such as "servers[0].port".
+	Field string `json:"field"`
+	// Rule is the failing rule name.
+	Rule string `json:"rule"`
+	// Message is a human-readable description.
+	Message string `json:"message"`
+}
+
+// Violations is the error returned when fields fail their rules.
+type Violations []Violation
+
+// Error lists every violation.
+func (v Violations) Error() string {
+	parts := make([]string, len(v))
+	for i, violation := range v {
+		parts[i] = violation.Field + ": " + violation.Message
+	}
+
+	return "validate: " + strings.Join(parts, "; ")
+}
+
+// Fields returns the paths of the failing fields.
+func (v Violations) Fields() []string {
+	fields := make([]string, len(v))
+	for i, violation := range v {
+		fields[i] = violation.Field
+	}
+
+	return fields
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+// Validator holds the registered rules. The zero value is not usable; use
+// New. It is safe for concurrent use.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Func
+	tags  sync.Map
+}
+
+// New returns a validator with the built-in rules: required, min, max,
+// oneof and regexp.
+func New() *Validator {
+	v := &Validator{rules: make(map[string]Func)}
+	v.Register("min", minRule)
+	v.Register("max", maxRule)
+	v.Register("oneof", oneOfRule)
+	v.Register("regexp", regexpRule)
+
+	return v
+}
+
+// Register adds or replaces the rule called name.
+func (v *Validator) Register(name string, fn Func) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rules[name] = fn
+}
+
+// Struct validates target, a struct or a pointer to one. It returns
+// Violations when fields fail their rules.
+func (v *Validator) Struct(target any) error {
+	value := reflect.ValueOf(target)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("%w, got %T", ErrInvalidTarget, target)
+	}
+
+	w := &walker{validator: v}
+	if err := w.walkStruct(value, ""); err != nil {
+		return err
+	}
+
+	if len(w.violations) > 0 {
+		return w.violations
+	}
+
+	return nil
+}
+
+var defaultValidator = New()
+
+// Struct validates target with the default validator.
+func Struct(target any) error {
+	return defaultValidator.Struct(target)
+}
+
+// Register adds a rule to the default validator.
+func Register(name string, fn Func) {
+	defaultValidator.Register(name, fn)
+}
+
+// walker accumulates the violations of a single Struct call.
+type walker struct {
+	validator  *Validator
+	violations Violations
+}
+
+func (w *walker) walkStruct(value reflect.Value, path string) error {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+
+		tag := field.Tag.Get(TagName)
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = joinPath(path, fieldName(field))
+		}
+
+		if err := w.check(value.Field(i), fieldPath, tag); err != nil {
+			return err
+		}
+
+		if err := w.descend(value.Field(i), fieldPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// descend validates the structs nested in value.
+func (w *walker) descend(value reflect.Value, path string) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return w.walkStruct(value, path)
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			err := w.descend(value.Index(i), path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return w.descendMap(value, path)
+	default:
+	}
+
+	return nil
+}
+
+// descendMap walks map values in key order for stable reports.
+func (w *walker) descendMap(value reflect.Value, path string) error {
+	keys := value.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+
+	for _, key := range keys {
+		err := w.descend(value.MapIndex(key), path+"["+fmt.Sprint(key)+"]")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check applies the rules of tag to a single field.
+func (w *walker) check(value reflect.Value, path, tag string) error {
+	if tag == "" {
+		return nil
+	}
+
+	rules, err := w.validator.parse(tag)
+	if err != nil {
+		return fmt.Errorf("%w (field %s)", err, path)
+	}
+
+	// A non-nil pointer is present even to a zero value, which is the
+	// usual reason to make a field a pointer.
+	pointer := value.Kind() == reflect.Pointer
+
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Pointer && (pointer || !value.IsZero()) {
+		return w.apply(value, path, rules)
+	}
+
+	if slices.ContainsFunc(rules, func(r rule) bool {
+		return r.name == "required"
+	}) {
+		w.violations = append(w.violations, Violation{
+			Field: path, Rule: "required", Message: "is required",
+		})
+	}
+
+	return nil
+}
+
+// apply runs the rules other than required on a set value.
+func (w *walker) apply(value reflect.Value, path string, rules []rule) error {
+	for _, r := range rules {
+		if r.name == "required" {
+			continue
+		}
+
+		err := w.validator.lookup(r.name)(value, r.param)
+
+		switch {
+		case errors.Is(err, ErrBadRule):
+			return fmt.Errorf("%w (field %s)", err, path)
+		case err != nil:
+			w.violations = append(w.violations, Violation{
+				Field: path, Rule: r.name, Message: err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) lookup(name string) Func {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.rules[name]
+}
+
+// parse splits a tag into rules, caching the result.
+func (v *Validator) parse(tag string) ([]rule, error) {
+	if cached, ok := v.tags.Load(tag); ok {
+		rules, _ := cached.([]rule)
+
+		return rules, nil
+	}
+
+	var rules []rule
+
+	for rest := tag; rest != ""; {
+		var part string
+		if strings.HasPrefix(rest, "regexp=") {
+			part, rest = rest, ""
+		} else {
+			part, rest, _ = strings.Cut(rest, ",")
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		if name != "required" && v.lookup(name) == nil {
+			return nil, fmt.Errorf("%w %q", ErrUnknownRule, name)
+		}
+
+		rules = append(rules, rule{name: name, param: param})
+	}
+
+	v.tags.Store(tag, rules)
+
+	return rules, nil
+}
+
+// fieldName prefers the JSON name so paths match API payloads.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,133 @@
+package validate_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/validate"
+)
+
+type server struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" validate:"min=1,max=65535"`
+}
+
+type Meta struct {
+	Owner string `validate:"required"`
+}
+
+type config struct {
+	Meta
+
+	Name    string            `json:"name" validate:"required,max=8"`
+	Primary *server           `json:"primary" validate:"required"`
+	Servers []server          `json:"servers" validate:"min=1"`
+	Named   map[string]server `json:"named"`
+	Ignored string            `validate:"-"`
+	hidden  string            `validate:"required"`
+}
+
+func violations(t *testing.T, err error) validate.Violations {
+	t.Helper()
+
+	var result validate.Violations
+	require.ErrorAs(t, err, &result)
+
+	return result
+}
+
+func TestStructValid(t *testing.T) {
+	t.Parallel()
+
+	cfg := config{
+		Meta:    Meta{Owner: "ops"},
+		Name:    "api",
+		Primary: &server{Host: "a", Port: 80},
+		Servers: []server{{Host: "b"}},
+	}
+	require.NoError(t, validate.Struct(cfg))
+	require.NoError(t, validate.Struct(&cfg))
+	assert.Empty(t, cfg.hidden)
+}
+
+func TestStructPaths(t *testing.T) {
+	t.Parallel()
+
+	err := validate.Struct(&config{
+		Name:    "too-long-name",
+		Servers: []server{{Host: "a", Port: 1}, {Port: 70000}},
+		Named:   map[string]server{"z": {}, "b": {Host: "b", Port: -1}},
+	})
+
+	assert.Equal(t, []string{
+		"Owner", "name", "primary", "servers[1].host", "servers[1].port",
+		"named[b].port", "named[z].host",
+	}, violations(t, err).Fields())
+	assert.ErrorContains(t, err,
+		"validate: Owner: is required; name: must have at most 8 characters")
+}
+
+func TestStructPointers(t *testing.T) {
+	t.Parallel()
+
+	type options struct {
+		Retries *int  `validate:"required,max=3"`
+		Verbose *bool `validate:"required"`
+	}
+
+	zero, off := 0, false
+	require.NoError(t, validate.Struct(options{&zero, &off}),
+		"pointers to zero values are set")
+
+	many := 4
+	assert.Equal(t, []string{"Retries", "Verbose"},
+		violations(t, validate.Struct(options{Retries: &many})).Fields())
+}
+
+func TestStructErrors(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorIs(t, validate.Struct("text"), validate.ErrInvalidTarget)
+	require.ErrorIs(t, validate.Struct((*config)(nil)),
+		validate.ErrInvalidTarget)
+
+	require.ErrorIs(t, validate.Struct(struct {
+		Field string `validate:"unknown"`
+	}{"x"}), validate.ErrUnknownRule)
+
+	require.ErrorIs(t, validate.Struct(struct {
+		Field bool `validate:"min=1"`
+	}{true}), validate.ErrBadRule)
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	v := validate.New()
+	v.Register("prefix", func(value reflect.Value, param string) error {
+		if !strings.HasPrefix(value.String(), param) {
+			return errors.New("must start with " + param)
+		}
+
+		return nil
+	})
+
+	type bucket struct {
+		Name string `validate:"required,prefix=s3-"`
+	}
+
+	require.NoError(t, v.Struct(bucket{Name: "s3-logs"}))
+
+	got := violations(t, v.Struct(bucket{Name: "logs"}))
+	assert.Equal(t, validate.Violations{{
+		Field: "Name", Rule: "prefix", Message: "must start with s3-",
+	}}, got)
+
+	require.ErrorIs(t, validate.Struct(bucket{Name: "x"}),
+		validate.ErrUnknownRule, "rules are per validator")
+}
@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	armorBegin = "-----BEGIN AGE ENCRYPTED FILE-----"
+	armorEnd   = "-----END AGE ENCRYPTED FILE-----"
+	// maxArmored bounds the size of armored files, which are decoded at
+	// once.
+	maxArmored = 16 << 20
+)
+
+// Armor returns a writer encoding the encrypted file written to it as
+// PEM-like ASCII text into dst. Close writes the end line; it does not
+// close dst.
+func Armor(dst io.Writer) io.WriteCloser {
+	lines := &lineWriter{dst: dst}
+
+	return &armorWriter{
+		dst:     dst,
+		lines:   lines,
+		encoder: base64.NewEncoder(base64.StdEncoding, lines),
+	}
+}
+
+// armorWriter writes the begin line before the first write.
+type armorWriter struct {
+	dst     io.Writer
+	lines   *lineWriter
+	encoder io.WriteCloser
+	started bool
+}
+
+// Write encodes p after the begin line.
+func (a *armorWriter) Write(p []byte) (int, error) {
+	if err := a.start(); err != nil {
+		return 0, err
+	}
+
+	return a.encoder.Write(p)
+}
+
+// Close flushes the encoded data and writes the end line.
+func (a *armorWriter) Close() error {
+	if err := a.start(); err != nil {
+		return err
+	}
+
+	if err := a.encoder.Close(); err != nil {
+		return err
+	}
+
+	end := armorEnd + "\n"
+	if a.lines.column > 0 {
+		end = "\n" + end
+	}
+
+	_, err := io.WriteString(a.dst, end)
+
+	return err
+}
+
+func (a *armorWriter) start() error {
+	if a.started {
+		return nil
+	}
+
+	a.started = true
+	_, err := io.WriteString(a.dst, armorBegin+"\n")
+
+	return err
+}
+
+// lineWriter wraps the base64 text at the stanza body width.
+type lineWriter struct {
+	dst    io.Writer
+	column int
+}
+
+// Write copies p, breaking lines every columns characters.
+func (l *lineWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		if l.column == columns {
+			if _, err := io.WriteString(l.dst, "\n"); err != nil {
+				return written, err
+			}
+
+			l.column = 0
+		}
+
+		n := min(len(p), columns-l.column)
+		if _, err := l.dst.Write(p[:n]); err != nil {
+			return written, err
+		}
+
+		l.column += n
+		written += n
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+// armored reports whether the file starts with the begin line, possibly
+// after blanks.
+func armored(r *bufio.Reader) bool {
+	start, _ := r.Peek(len(armorBegin) + 64)
+
+	return bytes.HasPrefix(bytes.TrimLeft(start, " \t\r\n"), []byte(armorBegin))
+}
+
+// dearmor decodes an armored file, which must only have full lines of
+// padded base64 but the last one between the begin and end lines.
+func dearmor(r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxArmored+1))
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	if len(data) > maxArmored {
+		return nil, fmt.Errorf("%w: armored file too large", ErrInvalidHeader)
+	}
+
+	text := strings.ReplaceAll(strings.TrimSpace(string(data)), "\r\n", "\n")
+
+	body, hasBegin := strings.CutPrefix(text, armorBegin+"\n")
+
+	body, hasEnd := strings.CutSuffix(body, "\n"+armorEnd)
+	if !hasBegin || !hasEnd {
+		return nil, fmt.Errorf("%w: bad armor", ErrInvalidHeader)
+	}
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if len(line) > columns || len(line) < columns && i < len(lines)-1 {
+			return nil, fmt.Errorf("%w: bad armor line %d", ErrInvalidHeader,
+				i+2)
+		}
+	}
+
+	decoded, err := base64.StdEncoding.Strict().DecodeString(
+		strings.Join(lines, ""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad armor: %w", ErrInvalidHeader, err)
+	}
+
+	return bytes.NewReader(decoded), nil
+}
@@ -0,0 +1,164 @@
+package vault
+
+import (
+	"errors"
+	"strings"
+)
+
+// bech32Charset maps 5-bit groups to the characters of BIP 173.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// errBech32 is wrapped by the errors of decodeBech32.
+var errBech32 = errors.New("malformed bech32 string")
+
+var bech32Generator = [5]uint32{
+	0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3,
+}
+
+// polymod computes the BCH checksum of the 5-bit values.
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+
+		for i, g := range bech32Generator {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+
+	return chk
+}
+
+// expandHRP spreads the human-readable part over 5-bit values.
+func expandHRP(hrp string) []byte {
+	values := make([]byte, 0, 2*len(hrp)+1)
+	for _, c := range []byte(hrp) {
+		values = append(values, c>>5)
+	}
+
+	values = append(values, 0)
+	for _, c := range []byte(hrp) {
+		values = append(values, c&31)
+	}
+
+	return values
+}
+
+// encodeBech32 encodes data with the lowercase human-readable part hrp.
+// Unlike BIP 173, the length is not limited to 90 characters, as in age.
+func encodeBech32(hrp string, data []byte) string {
+	values := toGroups(data)
+
+	mod := polymod(append(append(expandHRP(hrp), values...),
+		0, 0, 0, 0, 0, 0)) ^ 1
+	for i := range 6 {
+		values = append(values, byte(mod>>(5*(5-i)))&31)
+	}
+
+	var b strings.Builder
+
+	b.WriteString(hrp + "1")
+
+	for _, v := range values {
+		b.WriteByte(bech32Charset[v])
+	}
+
+	return b.String()
+}
+
+// decodeBech32 returns the lowercase human-readable part and the data of
+// s, which must not mix cases.
+func decodeBech32(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errBech32
+	}
+
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errBech32
+	}
+
+	hrp := s[:pos]
+	if strings.ContainsFunc(hrp, func(c rune) bool {
+		return c < '!' || c > '~'
+	}) {
+		return "", nil, errBech32
+	}
+
+	values, ok := groupValues(s[pos+1:])
+	if !ok || polymod(append(expandHRP(hrp), values...)) != 1 {
+		return "", nil, errBech32
+	}
+
+	data, err := fromGroups(values[:len(values)-6])
+	if err != nil {
+		return "", nil, err
+	}
+
+	return hrp, data, nil
+}
+
+// groupValues maps the characters of the data part to 5-bit groups.
+func groupValues(data string) ([]byte, bool) {
+	values := make([]byte, 0, len(data))
+	for _, c := range []byte(data) {
+		v := strings.IndexByte(bech32Charset, c)
+		if v < 0 {
+			return nil, false
+		}
+
+		values = append(values, byte(v))
+	}
+
+	return values, true
+}
+
+// toGroups splits bytes into 5-bit groups, padding the last one.
+func toGroups(data []byte) []byte {
+	var (
+		acc    uint32
+		bits   uint
+		groups = make([]byte, 0, (len(data)*8+4)/5+6)
+	)
+
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		for bits += 8; bits >= 5; bits -= 5 {
+			groups = append(groups, byte(acc>>(bits-5))&31)
+		}
+	}
+
+	if bits > 0 {
+		groups = append(groups, byte(acc<<(5-bits))&31)
+	}
+
+	return groups
+}
+
+// fromGroups joins 5-bit groups into bytes, rejecting non-zero padding.
+func fromGroups(groups []byte) ([]byte, error) {
+	var (
+		acc  uint32
+		bits uint
+		data = make([]byte, 0, len(groups)*5/8)
+	)
+
+	for _, g := range groups {
+		acc = acc<<5 | uint32(g)
+		for bits += 5; bits >= 8; bits -= 8 {
+			data = append(data, byte(acc>>(bits-8)))
+		}
+	}
+
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return nil, errBech32
+	}
+
+	return data, nil
+}
@@ -0,0 +1,186 @@
+package vault
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables read by Discover, shared with SOPS: the first one
+// holds identities, the second one the path of an identity file.
+const (
+	KeyEnv     = "SOPS_AGE_KEY"
+	KeyFileEnv = "SOPS_AGE_KEY_FILE"
+)
+
+// ErrNoIdentity is returned by Discover when no identity is found.
+var ErrNoIdentity = errors.New("vault: no identity found")
+
+// LookupFunc resolves an environment variable.
+type LookupFunc func(key string) (string, bool)
+
+// Option configures Discover.
+type Option func(*discovery)
+
+// WithLookup resolves environment variables with lookup instead of
+// os.LookupEnv.
+func WithLookup(lookup LookupFunc) Option {
+	return func(d *discovery) {
+		d.lookup = lookup
+	}
+}
+
+type discovery struct {
+	lookup LookupFunc
+}
+
+// Discover returns the identities found in the standard locations, in
+// order: the KeyEnv variable, the file named by KeyFileEnv, then the
+// keys.txt files of SOPS and age under the configuration directory,
+// $XDG_CONFIG_HOME or ~/.config. Missing files are skipped, unless named
+// by KeyFileEnv.
+func Discover(opts ...Option) ([]Identity, error) {
+	d := &discovery{lookup: os.LookupEnv}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	identities, err := d.fromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range d.files() {
+		found, err := readIdentities(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		identities = append(identities, found...)
+	}
+
+	if len(identities) == 0 {
+		return nil, ErrNoIdentity
+	}
+
+	return identities, nil
+}
+
+// fromEnv returns the identities of the variables.
+func (d *discovery) fromEnv() ([]Identity, error) {
+	var identities []Identity
+
+	if keys, ok := d.lookup(KeyEnv); ok && keys != "" {
+		found, err := ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("%w (variable %s)", err, KeyEnv)
+		}
+
+		identities = append(identities, found...)
+	}
+
+	if path, ok := d.lookup(KeyFileEnv); ok && path != "" {
+		found, err := readIdentities(path)
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, found...)
+	}
+
+	return identities, nil
+}
+
+// files lists the identity files under the configuration directory.
+func (d *discovery) files() []string {
+	dir, _ := d.lookup("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, ok := d.lookup("HOME")
+		if !ok || home == "" {
+			return nil
+		}
+
+		dir = filepath.Join(home, ".config")
+	}
+
+	return []string{
+		filepath.Join(dir, "sops", "age", "keys.txt"),
+		filepath.Join(dir, "age", "keys.txt"),
+	}
+}
+
+// readIdentities parses the identity file at path.
+func readIdentities(path string) ([]Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w (file %s)", err, path)
+	}
+
+	return identities, nil
+}
+
+// ParseIdentities reads an identity file, as written by age-keygen: one
+// "AGE-SECRET-KEY-1..." per line, blank lines and "#" comments ignored.
+func ParseIdentities(r io.Reader) ([]Identity, error) {
+	var identities []Identity
+
+	err := parseKeys(r, func(key string) error {
+		identity, err := ParseX25519Identity(key)
+		if err == nil {
+			identities = append(identities, identity)
+		}
+
+		return err
+	})
+
+	return identities, err
+}
+
+// ParseRecipients reads a recipient file: one "age1..." per line, blank
+// lines and "#" comments ignored.
+func ParseRecipients(r io.Reader) ([]Recipient, error) {
+	var recipients []Recipient
+
+	err := parseKeys(r, func(key string) error {
+		recipient, err := ParseX25519Recipient(key)
+		if err == nil {
+			recipients = append(recipients, recipient)
+		}
+
+		return err
+	})
+
+	return recipients, err
+}
+
+// parseKeys calls parse with every key of a key file.
+func parseKeys(r io.Reader, parse func(key string) error) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := parse(line); err != nil {
+			return fmt.Errorf("%w (line %d)", err, n)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+
+	return nil
+}
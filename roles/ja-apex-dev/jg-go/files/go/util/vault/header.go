@@ -0,0 +1,274 @@
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// intro is the first line of files.
+	intro = "age-encryption.org/v1\n"
+	// stanzaPrefix starts the first line of stanzas.
+	stanzaPrefix = "-> "
+	// footer precedes the MAC of the header.
+	footer = "---"
+	// columns is the length of the wrapped lines of stanza bodies.
+	columns = 64
+)
+
+// b64 encodes stanzas and MACs: canonical base64 without padding.
+var b64 = base64.RawStdEncoding.Strict()
+
+// header is the list of stanzas starting files.
+type header struct {
+	stanzas []*Stanza
+	// raw holds the header as read, up to the footer, to check its MAC.
+	raw []byte
+	mac []byte
+}
+
+// encode writes the header up to the footer, excluding its MAC.
+func (h *header) encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(intro)
+
+	for _, s := range h.stanzas {
+		line := strings.Join(append([]string{s.Type}, s.Args...), " ")
+		if !validLine(line) || hasEmptyField(s) {
+			return nil, fmt.Errorf("%w: bad stanza %q", ErrRecipients, line)
+		}
+
+		buf.WriteString(stanzaPrefix + line + "\n")
+
+		body := b64.EncodeToString(s.Body)
+		for len(body) >= columns {
+			buf.WriteString(body[:columns] + "\n")
+			body = body[columns:]
+		}
+
+		buf.WriteString(body + "\n")
+	}
+
+	buf.WriteString(footer)
+
+	return buf.Bytes(), nil
+}
+
+// marshal writes the header authenticated with the file key to w.
+func (h *header) marshal(w io.Writer, fileKey []byte) error {
+	data, err := h.encode()
+	if err != nil {
+		return err
+	}
+
+	mac, err := headerMAC(fileKey, data)
+	if err != nil {
+		return err
+	}
+
+	data = fmt.Appendf(data, " %s\n", b64.EncodeToString(mac))
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+
+	return nil
+}
+
+// unwrap finds the file key with the first matching identity and checks
+// the MAC of the header with it.
+func (h *header) unwrap(identities []Identity) ([]byte, error) {
+	for _, identity := range identities {
+		fileKey, err := identity.Unwrap(h.stanzas)
+
+		switch {
+		case errors.Is(err, ErrNoMatch):
+			continue
+		case err != nil:
+			return nil, err
+		case len(fileKey) != fileKeySize:
+			return nil, fmt.Errorf("%w: bad file key", ErrInvalidHeader)
+		}
+
+		mac, err := headerMAC(fileKey, h.raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if !hmac.Equal(mac, h.mac) {
+			return nil, fmt.Errorf("%w: MAC mismatch", ErrInvalidHeader)
+		}
+
+		return fileKey, nil
+	}
+
+	return nil, ErrNoMatch
+}
+
+// headerMAC authenticates the header up to its footer.
+func headerMAC(fileKey, data []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nil, "header", sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return mac.Sum(nil), nil
+}
+
+// parseHeader reads the header of a file, leaving r at its payload.
+func parseHeader(r *bufio.Reader) (*header, error) {
+	p := &headerParser{r: r, h: &header{}}
+
+	line, err := p.line()
+	if err != nil || line != intro {
+		return nil, fmt.Errorf("%w: not an age file", ErrInvalidHeader)
+	}
+
+	for {
+		line, err := p.line()
+		if err != nil {
+			return nil, err
+		}
+
+		if rest, ok := strings.CutPrefix(line, footer); ok {
+			return p.footer(rest)
+		}
+
+		if err := p.stanza(line); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// headerParser accumulates the raw header while reading it.
+type headerParser struct {
+	r *bufio.Reader
+	h *header
+}
+
+// line reads a line, bounded by the buffer of the reader.
+func (p *headerParser) line() (string, error) {
+	line, err := p.r.ReadSlice('\n')
+	if err != nil {
+		return "", fmt.Errorf("%w: truncated", ErrInvalidHeader)
+	}
+
+	p.h.raw = append(p.h.raw, line...)
+
+	return string(line), nil
+}
+
+// stanza parses a stanza starting with line and its body.
+func (p *headerParser) stanza(line string) error {
+	args, ok := strings.CutPrefix(strings.TrimSuffix(line, "\n"), stanzaPrefix)
+	if !ok || !validLine(args) {
+		return fmt.Errorf("%w: bad stanza %q", ErrInvalidHeader, line)
+	}
+
+	fields := strings.Split(args, " ")
+	s := &Stanza{Type: fields[0], Args: fields[1:]}
+
+	if hasEmptyField(s) {
+		return fmt.Errorf("%w: bad stanza %q", ErrInvalidHeader, line)
+	}
+
+	body, err := p.body()
+	if err != nil {
+		return err
+	}
+
+	s.Body = body
+	p.h.stanzas = append(p.h.stanzas, s)
+
+	return nil
+}
+
+// body reads the wrapped body of a stanza, which ends with its first line
+// shorter than the others.
+func (p *headerParser) body() ([]byte, error) {
+	var body strings.Builder
+
+	for {
+		line, err := p.line()
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) > columns || !validLine(line) && line != "" {
+			return nil, fmt.Errorf("%w: bad stanza body", ErrInvalidHeader)
+		}
+
+		body.WriteString(line)
+
+		if len(line) < columns {
+			break
+		}
+	}
+
+	decoded, err := b64.DecodeString(body.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad stanza body", ErrInvalidHeader)
+	}
+
+	return decoded, nil
+}
+
+// footer parses the MAC following the footer mark, excluded from the raw
+// header.
+func (p *headerParser) footer(rest string) (*header, error) {
+	p.h.raw = p.h.raw[:len(p.h.raw)-len(rest)]
+
+	encoded, ok := strings.CutPrefix(strings.TrimSuffix(rest, "\n"), " ")
+	if !ok || !validLine(encoded) {
+		return nil, fmt.Errorf("%w: bad MAC", ErrInvalidHeader)
+	}
+
+	mac, err := b64.DecodeString(encoded)
+	if err != nil || len(mac) != sha256.Size {
+		return nil, fmt.Errorf("%w: bad MAC", ErrInvalidHeader)
+	}
+
+	p.h.mac = mac
+
+	return p.h, nil
+}
+
+// validLine reports whether line only has printable ASCII characters and
+// spaces, which excludes the line breaks base64 decoding would skip.
+func validLine(line string) bool {
+	for _, c := range []byte(line) {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return line != ""
+}
+
+// hasEmptyField reports whether the stanza has an empty type or
+// argument.
+func hasEmptyField(s *Stanza) bool {
+	if s.Type == "" {
+		return true
+	}
+
+	for _, arg := range s.Args {
+		if arg == "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package vault
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptType  = "scrypt"
+	scryptLabel = "age-encryption.org/v1/scrypt"
+	scryptSalt  = 16
+	// DefaultWorkFactor is the base 2 logarithm of the scrypt cost used
+	// by age for recipients, about one second of work.
+	DefaultWorkFactor = 18
+	// MaxWorkFactor is the highest cost identities accept by default.
+	MaxWorkFactor = 22
+)
+
+// ErrWorkFactor is returned for passphrase files too costly to decrypt.
+var ErrWorkFactor = errors.New("vault: work factor too high")
+
+// ScryptOption configures passphrase recipients and identities.
+type ScryptOption func(*scryptKey)
+
+// WithWorkFactor sets the base 2 logarithm of the scrypt cost of
+// recipients, or the maximum accepted by identities.
+func WithWorkFactor(logN int) ScryptOption {
+	return func(k *scryptKey) {
+		k.workFactor = logN
+	}
+}
+
+type scryptKey struct {
+	passphrase []byte
+	workFactor int
+}
+
+func newScryptKey(
+	passphrase string, workFactor int, opts []ScryptOption,
+) (scryptKey, error) {
+	k := scryptKey{passphrase: []byte(passphrase), workFactor: workFactor}
+	for _, opt := range opts {
+		opt(&k)
+	}
+
+	switch {
+	case passphrase == "":
+		return k, fmt.Errorf("%w: empty passphrase", ErrInvalidKey)
+	case k.workFactor < 1 || k.workFactor > 30:
+		return k, fmt.Errorf("%w: work factor %d", ErrInvalidKey, k.workFactor)
+	}
+
+	return k, nil
+}
+
+// derive computes the wrapping key of the salt and work factor.
+func (k scryptKey) derive(salt []byte, logN int) ([]byte, error) {
+	key, err := scrypt.Key(k.passphrase, append([]byte(scryptLabel), salt...),
+		1<<logN, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	return key, nil
+}
+
+// ScryptRecipient encrypts files with a passphrase. It must be the only
+// recipient of a file.
+type ScryptRecipient struct {
+	scryptKey
+}
+
+// NewScryptRecipient returns a recipient for the passphrase.
+func NewScryptRecipient(
+	passphrase string, opts ...ScryptOption,
+) (*ScryptRecipient, error) {
+	k, err := newScryptKey(passphrase, DefaultWorkFactor, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScryptRecipient{k}, nil
+}
+
+// Wrap encrypts the file key with a key derived from the passphrase.
+func (r *ScryptRecipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	salt := make([]byte, scryptSalt)
+	_, _ = rand.Read(salt)
+
+	key, err := r.derive(salt, r.workFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := sealKey(key, fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Stanza{{
+		Type: scryptType,
+		Args: []string{b64.EncodeToString(salt), strconv.Itoa(r.workFactor)},
+		Body: body,
+	}}, nil
+}
+
+// ScryptIdentity decrypts files encrypted with a passphrase.
+type ScryptIdentity struct {
+	scryptKey
+}
+
+// NewScryptIdentity returns an identity for the passphrase.
+func NewScryptIdentity(
+	passphrase string, opts ...ScryptOption,
+) (*ScryptIdentity, error) {
+	k, err := newScryptKey(passphrase, MaxWorkFactor, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScryptIdentity{k}, nil
+}
+
+// Unwrap decrypts the scrypt stanza, which must be the only one.
+func (i *ScryptIdentity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	if len(stanzas) != 1 || stanzas[0].Type != scryptType {
+		return nil, ErrNoMatch
+	}
+
+	salt, logN, err := parseScrypt(stanzas[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if logN > i.workFactor {
+		return nil, fmt.Errorf("%w: %d exceeds %d", ErrWorkFactor, logN,
+			i.workFactor)
+	}
+
+	key, err := i.derive(salt, logN)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey, err := openKey(key, stanzas[0].Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: incorrect passphrase", ErrNoMatch)
+	}
+
+	return fileKey, nil
+}
+
+// parseScrypt decodes the salt and the work factor of a scrypt stanza.
+func parseScrypt(s *Stanza) (salt []byte, logN int, err error) {
+	bad := fmt.Errorf("%w: bad scrypt stanza", ErrInvalidHeader)
+
+	if len(s.Args) != 2 || len(s.Body) != fileKeySize+
+		chacha20poly1305.Overhead {
+		return nil, 0, bad
+	}
+
+	salt, err = b64.DecodeString(s.Args[0])
+	if err != nil || len(salt) != scryptSalt {
+		return nil, 0, bad
+	}
+
+	logN, err = strconv.Atoi(s.Args[1])
+	if err != nil || logN < 1 || strconv.Itoa(logN) != s.Args[1] {
+		return nil, 0, bad
+	}
+
+	return salt, logN, nil
+}
@@ -0,0 +1,227 @@
+package vault
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// nonceSize is the size of the random nonce preceding the payload.
+	nonceSize = 16
+	// chunkSize is the plaintext size of every chunk but the last.
+	chunkSize = 64 << 10
+	// encryptedChunkSize adds the authentication tag to chunkSize.
+	encryptedChunkSize = chunkSize + chacha20poly1305.Overhead
+	// lastFlag marks the nonce of the last chunk.
+	lastFlag = 1
+)
+
+// errClosed is returned when writing to a closed writer.
+var errClosed = errors.New("vault: write to closed writer")
+
+// chunks encrypts or decrypts the payload chunks in order: each one uses
+// its index as nonce, flagged for the last one.
+type chunks struct {
+	aead  cipher.AEAD
+	nonce [chacha20poly1305.NonceSize]byte
+}
+
+func newChunks(fileKey, nonce []byte) (*chunks, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nonce, "payload",
+		chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	return &chunks{aead: aead}, nil
+}
+
+// next moves to the nonce of the next chunk.
+func (c *chunks) next() {
+	for i := len(c.nonce) - 2; i >= 0; i-- {
+		c.nonce[i]++
+		if c.nonce[i] != 0 {
+			return
+		}
+	}
+}
+
+// first reports whether the current chunk starts the payload.
+func (c *chunks) first() bool {
+	return c.nonce == [len(c.nonce)]byte{}
+}
+
+// writer encrypts chunks to dst.
+type writer struct {
+	*chunks
+
+	dst    io.Writer
+	buf    []byte
+	out    []byte
+	closed bool
+}
+
+func newWriter(dst io.Writer, fileKey, nonce []byte) (*writer, error) {
+	c, err := newChunks(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		chunks: c,
+		dst:    dst,
+		buf:    make([]byte, 0, chunkSize),
+		out:    make([]byte, 0, encryptedChunkSize),
+	}, nil
+}
+
+// Write buffers p, writing the chunks it fills but the last one, which
+// may be the last of the payload.
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errClosed
+	}
+
+	written := 0
+
+	for len(p) > 0 {
+		if len(w.buf) == chunkSize {
+			if err := w.flush(0); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close writes the last chunk. It does not close dst.
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	return w.flush(lastFlag)
+}
+
+// flush encrypts and writes the buffered chunk.
+func (w *writer) flush(flag byte) error {
+	w.nonce[len(w.nonce)-1] = flag
+	w.out = w.aead.Seal(w.out[:0], w.nonce[:], w.buf, nil)
+	w.buf = w.buf[:0]
+	w.next()
+
+	if _, err := w.dst.Write(w.out); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+
+	return nil
+}
+
+// reader decrypts chunks from src.
+type reader struct {
+	*chunks
+
+	src    io.Reader
+	in     []byte
+	plain  []byte
+	unread []byte
+	err    error
+}
+
+func newReader(src io.Reader, fileKey, nonce []byte) (*reader, error) {
+	c, err := newChunks(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{
+		chunks: c,
+		src:    src,
+		in:     make([]byte, encryptedChunkSize),
+		plain:  make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Read returns the plaintext of the authenticated chunks.
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.unread) == 0 && r.err == nil {
+		r.unread, r.err = r.chunk()
+	}
+
+	if len(r.unread) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.unread)
+	r.unread = r.unread[n:]
+
+	return n, nil
+}
+
+// chunk reads and decrypts the next chunk, returning io.EOF with the
+// last one.
+func (r *reader) chunk() ([]byte, error) {
+	n, err := io.ReadFull(r.src, r.in)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil, fmt.Errorf("%w: missing last chunk", ErrCorrupt)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return r.last(r.in[:n])
+	case err != nil:
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	r.nonce[len(r.nonce)-1] = 0
+
+	plain, err := r.aead.Open(r.plain[:0], r.nonce[:], r.in, nil)
+	if err != nil {
+		// A full chunk may also be the last one.
+		return r.last(r.in)
+	}
+
+	r.next()
+
+	return plain, nil
+}
+
+// last decrypts the last chunk, which must end the payload and can only be
+// empty for empty payloads.
+func (r *reader) last(in []byte) ([]byte, error) {
+	if len(in) == chacha20poly1305.Overhead && !r.first() {
+		return nil, fmt.Errorf("%w: empty last chunk", ErrCorrupt)
+	}
+
+	r.nonce[len(r.nonce)-1] = lastFlag
+
+	plain, err := r.aead.Open(r.plain[:0], r.nonce[:], in, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+
+	var extra [1]byte
+	if n, _ := io.ReadFull(r.src, extra[:]); n > 0 {
+		return nil, fmt.Errorf("%w: data after the last chunk", ErrCorrupt)
+	}
+
+	return plain, io.EOF
+}
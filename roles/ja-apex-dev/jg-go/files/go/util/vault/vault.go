@@ -0,0 +1,232 @@
+// Package vault encrypts small secret files in the age format
+// (age-encryption.org/v1), so that roles can ship secrets encrypted with
+// the age and SOPS tools and Go programs can read them, and the reverse:
+//
+//	identity, _ := vault.GenerateX25519Identity()
+//	data, _ := vault.Seal([]byte("token"), identity.Recipient())
+//	plain, _ := vault.Open(data, identity) // "token"
+//
+// Files are encrypted for X25519 recipients ("age1...") or a passphrase.
+// Decrypt and Encrypt stream large payloads, Discover finds identities in
+// the standard locations and ASCII armored files are read transparently.
+// SSH keys and plugins of age are not supported.
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"example.com/go-template/util/fsx"
+)
+
+// fileKeySize is the size of the random key of each file.
+const fileKeySize = 16
+
+var (
+	// ErrInvalidKey is returned for malformed recipients and identities.
+	ErrInvalidKey = errors.New("vault: invalid key")
+	// ErrRecipients is returned by Encrypt without recipient, or when
+	// mixing a passphrase with other recipients.
+	ErrRecipients = errors.New("vault: invalid recipients")
+	// ErrInvalidHeader is returned for files not in the age format or
+	// whose header was modified.
+	ErrInvalidHeader = errors.New("vault: invalid header")
+	// ErrNoMatch is returned when no identity decrypts the file. Identities
+	// return it for stanzas that are not theirs.
+	ErrNoMatch = errors.New("vault: no identity matched")
+	// ErrCorrupt is returned for truncated or modified payloads.
+	ErrCorrupt = errors.New("vault: corrupt payload")
+)
+
+// Stanza is the file key wrapped for one recipient, as stored in the
+// header of files.
+type Stanza struct {
+	// Type names the recipient kind, such as "X25519".
+	Type string
+	// Args are the public parameters of the wrapping.
+	Args []string
+	// Body is the wrapped file key.
+	Body []byte
+}
+
+// Recipient wraps file keys for the holder of an identity.
+type Recipient interface {
+	// Wrap encrypts the file key into stanzas.
+	Wrap(fileKey []byte) ([]*Stanza, error)
+}
+
+// Identity unwraps the file keys wrapped for it.
+type Identity interface {
+	// Unwrap decrypts the file key from the stanzas of a file, or returns
+	// an error wrapping ErrNoMatch when none of them is for the identity.
+	Unwrap(stanzas []*Stanza) ([]byte, error)
+}
+
+// Encrypt returns a writer encrypting the data written to it for the
+// recipients into dst. Close must be called to write the last chunk; it
+// does not close dst.
+func Encrypt(dst io.Writer, recipients ...Recipient) (io.WriteCloser, error) {
+	fileKey := make([]byte, fileKeySize)
+	_, _ = rand.Read(fileKey)
+
+	h, err := wrap(fileKey, recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.marshal(dst, fileKey); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	_, _ = rand.Read(nonce)
+
+	if _, err := dst.Write(nonce); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	return newWriter(dst, fileKey, nonce)
+}
+
+// wrap builds the header of a file key for the recipients.
+func wrap(fileKey []byte, recipients []Recipient) (*header, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: none given", ErrRecipients)
+	}
+
+	h := &header{}
+
+	for _, r := range recipients {
+		stanzas, err := r.Wrap(fileKey)
+		if err != nil {
+			return nil, fmt.Errorf("vault: %w", err)
+		}
+
+		h.stanzas = append(h.stanzas, stanzas...)
+	}
+
+	for _, s := range h.stanzas {
+		if s.Type == scryptType && len(h.stanzas) > 1 {
+			return nil, fmt.Errorf("%w: a passphrase must be the only one",
+				ErrRecipients)
+		}
+	}
+
+	return h, nil
+}
+
+// Decrypt returns a reader decrypting src with the first matching
+// identity. The header is checked before returning; the payload is
+// authenticated chunk by chunk while reading, so data read before an
+// error wrapping ErrCorrupt must be discarded.
+func Decrypt(src io.Reader, identities ...Identity) (io.Reader, error) {
+	br := bufio.NewReader(src)
+	if armored(br) {
+		dearmored, err := dearmor(br)
+		if err != nil {
+			return nil, err
+		}
+
+		br = bufio.NewReader(dearmored)
+	}
+
+	h, err := parseHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey, err := h.unwrap(identities)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(br, nonce); err != nil {
+		return nil, fmt.Errorf("%w: missing nonce", ErrCorrupt)
+	}
+
+	return newReader(br, fileKey, nonce)
+}
+
+// Seal encrypts plaintext for the recipients.
+func Seal(plaintext []byte, recipients ...Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Open decrypts ciphertext, armored or not, with the identities.
+func Open(ciphertext []byte, identities ...Identity) ([]byte, error) {
+	r, err := Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plaintext, nil
+}
+
+// ReadFile decrypts the file at path with the identities.
+func ReadFile(path string, identities ...Identity) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	plaintext, err := Open(data, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("%w (file %s)", err, path)
+	}
+
+	return plaintext, nil
+}
+
+// WriteFile atomically writes plaintext to path encrypted for the
+// recipients. The file is ASCII armored, so that it can be committed and
+// reviewed like other text files, and readable by its owner only.
+func WriteFile(path string, plaintext []byte, recipients ...Recipient) error {
+	var buf bytes.Buffer
+
+	armor := Armor(&buf)
+
+	w, err := Encrypt(armor, recipients...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(plaintext); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := armor.Close(); err != nil {
+		return err
+	}
+
+	return fsx.AtomicWriteFile(path, buf.Bytes(), 0o600)
+}
@@ -0,0 +1,275 @@
+package vault_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/quick"
+	"example.com/go-template/util/vault"
+)
+
+// chunk is the plaintext size of the chunks of payloads.
+const chunk = 64 << 10
+
+// generate returns a random identity.
+func generate(t *testing.T) *vault.X25519Identity {
+	t.Helper()
+
+	identity, err := vault.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	return identity
+}
+
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, eve := generate(t), generate(t), generate(t)
+
+	for _, size := range []int{0, 1, chunk - 1, chunk, chunk + 1, 3 * chunk} {
+		plaintext := bytes.Repeat([]byte{'x'}, size)
+
+		data, err := vault.Seal(plaintext, alice.Recipient(), bob.Recipient())
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, []byte("age-encryption.org/v1\n")))
+
+		got, err := vault.Open(data, eve, bob)
+		require.NoError(t, err, size)
+		assert.Equal(t, plaintext, got, size)
+
+		_, err = vault.Open(data, eve)
+		require.ErrorIs(t, err, vault.ErrNoMatch)
+	}
+
+	_, err := vault.Seal(nil)
+	require.ErrorIs(t, err, vault.ErrRecipients)
+}
+
+func TestSealProperty(t *testing.T) {
+	t.Parallel()
+
+	identity := generate(t)
+
+	quick.Check(t, quick.Strings(64), func(t *quick.T, plaintext string) {
+		data, err := vault.Seal([]byte(plaintext), identity.Recipient())
+		require.NoError(t, err)
+
+		got, err := vault.Open(data, identity)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, string(got))
+	})
+}
+
+func TestDecryptStream(t *testing.T) {
+	t.Parallel()
+
+	identity := generate(t)
+	plaintext := strings.Repeat("0123456789", chunk/4)
+
+	var buf bytes.Buffer
+
+	w, err := vault.Encrypt(&buf, identity.Recipient())
+	require.NoError(t, err)
+
+	for part := range strings.SplitSeq(plaintext, "9") {
+		_, err := io.WriteString(w, part+"9")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("late"))
+	require.Error(t, err)
+
+	r, err := vault.Decrypt(&buf, identity)
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext+"9", string(got))
+}
+
+// stanza changes the base64 character of data at i to another valid one,
+// which flipping a bit does not guarantee.
+func stanza(data []byte, i int) []byte {
+	modified := bytes.Clone(data)
+	if modified[i] == 'A' {
+		modified[i] = 'B'
+	} else {
+		modified[i] = 'A'
+	}
+
+	return modified
+}
+
+func TestOpenCorrupt(t *testing.T) {
+	t.Parallel()
+
+	identity := generate(t)
+
+	data, err := vault.Seal(bytes.Repeat([]byte{1}, chunk+10),
+		identity.Recipient())
+	require.NoError(t, err)
+
+	header := bytes.Index(data, []byte("\n---")) + 1
+
+	flip := func(i int) []byte {
+		modified := bytes.Clone(data)
+		modified[i] ^= 1
+
+		return modified
+	}
+
+	for name, tc := range map[string]struct {
+		data []byte
+		want error
+	}{
+		"mac":       {flip(header + 10), vault.ErrInvalidHeader},
+		"stanza":    {stanza(data, header-3), vault.ErrNoMatch},
+		"payload":   {flip(len(data) - 20), vault.ErrCorrupt},
+		"truncated": {data[:len(data)-30], vault.ErrCorrupt},
+		"last full": {data[:len(data)-26-16], vault.ErrCorrupt},
+		"trailing":  {append(bytes.Clone(data), 0), vault.ErrCorrupt},
+		"header":    {data[:header], vault.ErrInvalidHeader},
+		"not age":   {[]byte("plain text\n"), vault.ErrInvalidHeader},
+		"no nonce":  {data[:header+48], vault.ErrCorrupt},
+	} {
+		_, err := vault.Open(tc.data, identity)
+		require.ErrorIs(t, err, tc.want, name)
+	}
+}
+
+func TestScrypt(t *testing.T) {
+	t.Parallel()
+
+	recipient, err := vault.NewScryptRecipient("hunter2",
+		vault.WithWorkFactor(10))
+	require.NoError(t, err)
+
+	data, err := vault.Seal([]byte("secret"), recipient)
+	require.NoError(t, err)
+
+	right, err := vault.NewScryptIdentity("hunter2")
+	require.NoError(t, err)
+
+	got, err := vault.Open(data, generate(t), right)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(got))
+
+	wrong, err := vault.NewScryptIdentity("hunter3")
+	require.NoError(t, err)
+
+	_, err = vault.Open(data, wrong)
+	require.ErrorIs(t, err, vault.ErrNoMatch)
+
+	cheap, err := vault.NewScryptIdentity("hunter2", vault.WithWorkFactor(9))
+	require.NoError(t, err)
+
+	_, err = vault.Open(data, cheap)
+	require.ErrorIs(t, err, vault.ErrWorkFactor)
+
+	_, err = vault.Seal(nil, recipient, generate(t).Recipient())
+	require.ErrorIs(t, err, vault.ErrRecipients)
+
+	_, err = vault.NewScryptRecipient("")
+	require.ErrorIs(t, err, vault.ErrInvalidKey)
+}
+
+func TestArmor(t *testing.T) {
+	t.Parallel()
+
+	identity := generate(t)
+	path := filepath.Join(t.TempDir(), "secret.age")
+
+	require.NoError(t, vault.WriteFile(path, []byte("token"),
+		identity.Recipient()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, "-----BEGIN AGE ENCRYPTED FILE-----", lines[0])
+	assert.Equal(t, "-----END AGE ENCRYPTED FILE-----", lines[len(lines)-1])
+
+	for _, line := range lines[1 : len(lines)-2] {
+		assert.Len(t, line, 64)
+	}
+
+	got, err := vault.ReadFile(path, identity)
+	require.NoError(t, err)
+	assert.Equal(t, "token", string(got))
+
+	got, err = vault.Open([]byte("\r\n"+strings.ReplaceAll(string(data),
+		"\n", "\r\n")), identity)
+	require.NoError(t, err, "blanks and CRLF line endings are accepted")
+	assert.Equal(t, "token", string(got))
+
+	_, err = vault.Open([]byte(strings.Replace(string(data), "\n", "", 2)),
+		identity)
+	require.ErrorIs(t, err, vault.ErrInvalidHeader)
+
+	_, err = vault.ReadFile(path+".missing", identity)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// ageRecipient is the public key of testdata/identity.txt, which the
+// testdata files were encrypted for with age v1.3.2.
+const ageRecipient = "age1frgrmewf6ju4jp7swax845756xznn" +
+	"jgt3g248q7y02466dtpsavqc280s0"
+
+func TestAgeFiles(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Open(filepath.Join("testdata", "identity.txt"))
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	identities, err := vault.ParseIdentities(file)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+
+	passphrase, err := vault.NewScryptIdentity("correct horse battery staple")
+	require.NoError(t, err)
+
+	// Whole chunks, the last one being final rather than followed by an
+	// empty chunk.
+	long := bytes.Repeat([]byte("0123456789abcdef"), 2*chunk/16)
+
+	for _, tt := range []struct {
+		name     string
+		identity vault.Identity
+		want     string
+	}{
+		{"x25519.age", identities[0], string(long)},
+		{"empty.age", identities[0], ""},
+		{"armor.age", identities[0], "hello from age\n"},
+		{"scrypt.age", passphrase, "hello from age\n"},
+	} {
+		got, err := vault.ReadFile(filepath.Join("testdata", tt.name),
+			tt.identity)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, string(got), tt.name)
+	}
+
+	data, err := os.ReadFile(filepath.Join("testdata", "x25519.age"))
+	require.NoError(t, err)
+
+	_, err = vault.Open(data[:len(data)-chunk-16], identities[0])
+	require.ErrorIs(t, err, vault.ErrCorrupt, "first chunk is not final")
+
+	// Seal lays out the payload as age does.
+	recipient, err := vault.ParseX25519Recipient(ageRecipient)
+	require.NoError(t, err)
+
+	sealed, err := vault.Seal(long, recipient)
+	require.NoError(t, err)
+	assert.Len(t, sealed, len(data))
+}
@@ -0,0 +1,224 @@
+package vault
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	x25519Type  = "X25519"
+	x25519Label = "age-encryption.org/v1/X25519"
+	// recipientHRP and identityHRP prefix the encoded keys.
+	recipientHRP = "age"
+	identityHRP  = "AGE-SECRET-KEY-"
+)
+
+// X25519Recipient is the public key of an X25519Identity, encoded as
+// "age1...".
+type X25519Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// ParseX25519Recipient decodes a recipient encoded as "age1...".
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	hrp, data, err := decodeBech32(s)
+	if err != nil || hrp != recipientHRP {
+		return nil, fmt.Errorf("%w: not an age recipient", ErrInvalidKey)
+	}
+
+	key, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return &X25519Recipient{key: key}, nil
+}
+
+// String encodes the recipient as "age1...".
+func (r *X25519Recipient) String() string {
+	return encodeBech32(recipientHRP, r.key.Bytes())
+}
+
+// Wrap encrypts the file key with a secret shared between an ephemeral
+// key and the recipient.
+func (r *X25519Recipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	share := ephemeral.PublicKey().Bytes()
+
+	body, err := x25519Seal(shared, share, r.key.Bytes(), fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Stanza{{
+		Type: x25519Type, Args: []string{b64.EncodeToString(share)},
+		Body: body,
+	}}, nil
+}
+
+// X25519Identity is a private key, encoded as "AGE-SECRET-KEY-1...", as
+// generated by age-keygen.
+type X25519Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateX25519Identity returns a random identity.
+func GenerateX25519Identity() (*X25519Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	return &X25519Identity{key: key}, nil
+}
+
+// ParseX25519Identity decodes an identity encoded as
+// "AGE-SECRET-KEY-1...".
+func ParseX25519Identity(s string) (*X25519Identity, error) {
+	hrp, data, err := decodeBech32(s)
+	if err != nil || hrp != strings.ToLower(identityHRP) {
+		return nil, fmt.Errorf("%w: not an age identity", ErrInvalidKey)
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return &X25519Identity{key: key}, nil
+}
+
+// Recipient returns the public key of the identity.
+func (i *X25519Identity) Recipient() *X25519Recipient {
+	return &X25519Recipient{key: i.key.PublicKey()}
+}
+
+// String encodes the identity as "AGE-SECRET-KEY-1...".
+func (i *X25519Identity) String() string {
+	return strings.ToUpper(encodeBech32(strings.ToLower(identityHRP),
+		i.key.Bytes()))
+}
+
+// Unwrap decrypts the first X25519 stanza wrapped for the identity.
+func (i *X25519Identity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	recipient := i.key.PublicKey().Bytes()
+
+	for _, s := range stanzas {
+		if s.Type != x25519Type {
+			continue
+		}
+
+		share, err := parseShare(s)
+		if err != nil {
+			return nil, err
+		}
+
+		shared, err := i.key.ECDH(share)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+		}
+
+		fileKey, err := x25519Open(shared, share.Bytes(), recipient, s.Body)
+		if err == nil {
+			return fileKey, nil
+		}
+	}
+
+	return nil, ErrNoMatch
+}
+
+// parseShare decodes the ephemeral share of an X25519 stanza.
+func parseShare(s *Stanza) (*ecdh.PublicKey, error) {
+	if len(s.Args) != 1 || len(s.Body) != fileKeySize+
+		chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("%w: bad X25519 stanza", ErrInvalidHeader)
+	}
+
+	data, err := b64.DecodeString(s.Args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad X25519 stanza", ErrInvalidHeader)
+	}
+
+	share, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+
+	return share, nil
+}
+
+// x25519Key derives the wrapping key of a stanza.
+func x25519Key(shared, share, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, share...), recipient...)
+
+	key, err := hkdf.Key(sha256.New, shared, salt, x25519Label,
+		chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	return key, nil
+}
+
+func x25519Seal(shared, share, recipient, fileKey []byte) ([]byte, error) {
+	key, err := x25519Key(shared, share, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	return sealKey(key, fileKey)
+}
+
+func x25519Open(shared, share, recipient, body []byte) ([]byte, error) {
+	key, err := x25519Key(shared, share, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	return openKey(key, body)
+}
+
+// sealKey encrypts a file key with a single-use wrapping key, hence the
+// zero nonce.
+func sealKey(key, fileKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+
+	return aead.Seal(nil, nonce, fileKey, nil), nil
+}
+
+// openKey decrypts a file key sealed by sealKey.
+func openKey(key, body []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+
+	fileKey, err := aead.Open(nil, nonce, body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoMatch, err)
+	}
+
+	return fileKey, nil
+}
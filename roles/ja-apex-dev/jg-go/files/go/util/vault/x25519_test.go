@@ -0,0 +1,142 @@
+package vault_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/vault"
+)
+
+func TestX25519Keys(t *testing.T) {
+	t.Parallel()
+
+	identity := generate(t)
+
+	encoded := identity.String()
+	assert.True(t, strings.HasPrefix(encoded, "AGE-SECRET-KEY-1"), encoded)
+	assert.Len(t, encoded, 74)
+
+	parsed, err := vault.ParseX25519Identity(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, parsed.String())
+
+	recipient := identity.Recipient().String()
+	assert.True(t, strings.HasPrefix(recipient, "age1"), recipient)
+	assert.Len(t, recipient, 62)
+
+	parsedRecipient, err := vault.ParseX25519Recipient(recipient)
+	require.NoError(t, err)
+	assert.Equal(t, recipient, parsedRecipient.String())
+
+	// The recipient of the age documentation.
+	_, err = vault.ParseX25519Recipient(
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p")
+	require.NoError(t, err)
+
+	// Another last character breaks the checksum.
+	last := "q"
+	if strings.HasSuffix(recipient, last) {
+		last = "p"
+	}
+
+	for _, bad := range []string{
+		recipient[:len(recipient)-1] + last,
+		strings.ToUpper(recipient[:10]) + recipient[10:],
+		encoded,
+		"age1",
+		"",
+	} {
+		_, err := vault.ParseX25519Recipient(bad)
+		require.ErrorIs(t, err, vault.ErrInvalidKey, bad)
+	}
+
+	_, err = vault.ParseX25519Identity(recipient)
+	require.ErrorIs(t, err, vault.ErrInvalidKey)
+}
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	first, second := generate(t), generate(t)
+
+	identities, err := vault.ParseIdentities(strings.NewReader(
+		"# created: 2026-01-01T00:00:00Z\n" +
+			"# public key: " + first.Recipient().String() + "\n" +
+			first.String() + "\n\n  " + second.String() + "  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []vault.Identity{first, second}, identities)
+
+	_, err = vault.ParseIdentities(strings.NewReader("# keys\nnot-a-key\n"))
+	require.ErrorIs(t, err, vault.ErrInvalidKey)
+	assert.Contains(t, err.Error(), "(line 2)")
+
+	recipients, err := vault.ParseRecipients(strings.NewReader(
+		"# team\n" + first.Recipient().String() + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []vault.Recipient{first.Recipient()}, recipients)
+}
+
+// env returns a lookup function serving vars.
+func env(vars map[string]string) vault.LookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+
+		return value, ok
+	}
+}
+
+// writeKeys writes the identities to path, creating its directory.
+func writeKeys(t *testing.T, path string, keys ...*vault.X25519Identity) {
+	t.Helper()
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key.String()
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path,
+		[]byte(strings.Join(lines, "\n")), 0o600))
+}
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	inline, file := generate(t), generate(t)
+	sops, age := generate(t), generate(t)
+
+	writeKeys(t, filepath.Join(home, "keys.txt"), file)
+	writeKeys(t, filepath.Join(home, ".config/sops/age/keys.txt"), sops)
+	writeKeys(t, filepath.Join(home, ".config/age/keys.txt"), age)
+
+	identities, err := vault.Discover(vault.WithLookup(env(map[string]string{
+		"HOME":               home,
+		vault.KeyEnv:         inline.String(),
+		vault.KeyFileEnv:     filepath.Join(home, "keys.txt"),
+		"UNRELATED_VARIABLE": "x",
+	})))
+	require.NoError(t, err)
+	assert.Equal(t, []vault.Identity{inline, file, sops, age}, identities)
+
+	identities, err = vault.Discover(vault.WithLookup(env(map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config", "age"),
+	})))
+	require.ErrorIs(t, err, vault.ErrNoIdentity, "missing files are skipped")
+	assert.Empty(t, identities)
+
+	_, err = vault.Discover(vault.WithLookup(env(map[string]string{
+		vault.KeyFileEnv: filepath.Join(home, "missing.txt"),
+	})))
+	require.ErrorIs(t, err, os.ErrNotExist, "named files must exist")
+
+	_, err = vault.Discover(vault.WithLookup(env(map[string]string{
+		vault.KeyEnv: "AGE-SECRET-KEY-1",
+	})))
+	require.ErrorIs(t, err, vault.ErrInvalidKey)
+}
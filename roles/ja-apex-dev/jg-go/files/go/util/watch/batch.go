@@ -0,0 +1,47 @@
+package watch
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// batch accumulates the events of a burst until it has been quiet for
+// the debounce period.
+type batch struct {
+	debounce time.Duration
+	timer    *time.Timer
+	ops      map[string]Op
+}
+
+func newBatch(debounce time.Duration) *batch {
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	return &batch{debounce: debounce, timer: timer, ops: make(map[string]Op)}
+}
+
+// add records op on path and postpones the flush.
+func (b *batch) add(path string, op Op) {
+	b.ops[path] |= op
+	b.timer.Reset(b.debounce)
+}
+
+// flush returns the events of the batch in path order and empties it.
+func (b *batch) flush() []Event {
+	events := make([]Event, 0, len(b.ops))
+	for _, path := range slices.Sorted(maps.Keys(b.ops)) {
+		events = append(events, Event{Path: path, Op: b.ops[path]})
+	}
+
+	clear(b.ops)
+
+	return events
+}
+
+// deliver calls fn with the events of the batch, if any.
+func (b *batch) deliver(fn func([]Event)) {
+	if events := b.flush(); len(events) > 0 {
+		fn(events)
+	}
+}
@@ -0,0 +1,310 @@
+// Package watch watches a directory tree for file changes with fsnotify,
+// filtering paths with include globs and .gitignore-style excludes and
+// debouncing bursts of events, such as those of an editor saving or a
+// checkout, into a single callback:
+//
+//	err := watch.Watch(ctx, ".", func(events []watch.Event) {
+//		render(events)
+//	}, watch.WithInclude("**/*.go", "templates/**"))
+//
+// Directories created while watching are watched too.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"example.com/go-template/util/pathmatch"
+)
+
+// DefaultDebounce is the quiet period closing a batch of events.
+const DefaultDebounce = 100 * time.Millisecond
+
+// DefaultExclude lists the directories skipped unless WithExclude
+// replaces them.
+var DefaultExclude = []string{".git/", "node_modules/"}
+
+// Op is a set of file operations.
+type Op uint8
+
+// File operations.
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+	Rename
+	Chmod
+)
+
+// opNames are the names of the operations in bit order.
+var opNames = []string{"create", "write", "remove", "rename", "chmod"}
+
+// notifyOps are the fsnotify operations in bit order.
+var notifyOps = []fsnotify.Op{
+	fsnotify.Create, fsnotify.Write, fsnotify.Remove, fsnotify.Rename,
+	fsnotify.Chmod,
+}
+
+// String returns the operations separated by "|", such as "create|write".
+func (op Op) String() string {
+	var names []string
+
+	for i, name := range opNames {
+		if op&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// convert returns the operations of an fsnotify event.
+func convert(op fsnotify.Op) Op {
+	var converted Op
+
+	for i, notifyOp := range notifyOps {
+		if op.Has(notifyOp) {
+			converted |= 1 << i
+		}
+	}
+
+	return converted
+}
+
+// Event is a change of a path during a batch.
+type Event struct {
+	// Path is the slash-separated path relative to the root.
+	Path string
+	// Op accumulates the operations seen in the batch.
+	Op Op
+}
+
+// Option configures New.
+type Option func(*settings)
+
+type settings struct {
+	include  []string
+	exclude  []string
+	debounce time.Duration
+}
+
+// WithInclude only reports the paths matching one of the glob patterns,
+// in pathmatch syntax.
+func WithInclude(patterns ...string) Option {
+	return func(s *settings) {
+		s.include = append(s.include, patterns...)
+	}
+}
+
+// WithExclude skips the paths matching .gitignore-style rules instead of
+// DefaultExclude. Excluded directories are not watched at all.
+func WithExclude(rules ...string) Option {
+	return func(s *settings) {
+		s.exclude = rules
+	}
+}
+
+// WithDebounce sets the quiet period closing a batch of events.
+func WithDebounce(d time.Duration) Option {
+	return func(s *settings) {
+		s.debounce = d
+	}
+}
+
+// Watcher watches a directory tree.
+type Watcher struct {
+	root     string
+	include  []*pathmatch.Pattern
+	exclude  *pathmatch.Ignore
+	debounce time.Duration
+	notify   *fsnotify.Watcher
+}
+
+// New watches the directory tree at root.
+func New(root string, opts ...Option) (*Watcher, error) {
+	s := settings{exclude: DefaultExclude, debounce: DefaultDebounce}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	w := &Watcher{root: filepath.Clean(root), debounce: s.debounce}
+
+	for _, pattern := range s.include {
+		compiled, err := pathmatch.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("watch: %w", err)
+		}
+
+		w.include = append(w.include, compiled)
+	}
+
+	exclude, err := pathmatch.NewIgnore(s.exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("watch: %w", err)
+	}
+
+	w.exclude = exclude
+
+	if w.notify, err = fsnotify.NewWatcher(); err != nil {
+		return nil, fmt.Errorf("watch: %w", err)
+	}
+
+	if _, err := w.addTree(w.root); err != nil {
+		w.notify.Close()
+
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Watch watches root and calls fn with every batch of events until ctx
+// is done.
+func Watch(
+	ctx context.Context, root string, fn func([]Event), opts ...Option,
+) error {
+	w, err := New(root, opts...)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return w.Run(ctx, fn)
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	if err := w.notify.Close(); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
+
+// Run calls fn with the events of every batch, in path order, until ctx
+// is done, returning nil, or the watch fails. fn runs on the goroutine of
+// Run, so batches never overlap.
+func (w *Watcher) Run(ctx context.Context, fn func([]Event)) error {
+	b := newBatch(w.debounce)
+	defer b.timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return nil
+			}
+
+			w.handle(event, b)
+		case err := <-w.notify.Errors:
+			return failure(err)
+		case <-b.timer.C:
+			b.deliver(fn)
+		}
+	}
+}
+
+// failure wraps an error received from fsnotify, which is nil once the
+// watcher is closed.
+func failure(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("watch: %w", err)
+}
+
+// handle records an fsnotify event, watching created directories.
+func (w *Watcher) handle(event fsnotify.Event, b *batch) {
+	if event.Has(fsnotify.Create) {
+		created, err := w.addTree(event.Name)
+		if err == nil {
+			for _, path := range created {
+				b.add(path, Create)
+			}
+		}
+	}
+
+	rel, ok := w.relative(event.Name)
+	if ok && w.reported(rel) {
+		b.add(rel, convert(event.Op))
+	}
+}
+
+// addTree watches dir and the directories below it, unless excluded, and
+// returns the reported files found below it. It is a no-op for files.
+func (w *Watcher) addTree(dir string) ([]string, error) {
+	var found []string
+
+	err := filepath.WalkDir(dir,
+		func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			return w.visit(dir, path, entry, &found)
+		})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("watch: %w", err)
+	}
+
+	return found, nil
+}
+
+// visit watches the directories below dir and collects their files for
+// addTree. A file at dir itself is left out, as it has its own event.
+func (w *Watcher) visit(
+	dir, path string, entry fs.DirEntry, found *[]string,
+) error {
+	rel, _ := w.relative(path)
+
+	switch {
+	case !entry.IsDir():
+		if path != dir && w.reported(rel) {
+			*found = append(*found, rel)
+		}
+
+		return nil
+	case rel != "." && w.exclude.MatchDir(rel):
+		return fs.SkipDir
+	default:
+		return w.notify.Add(path)
+	}
+}
+
+// relative returns the slash-separated path of name below the root.
+func (w *Watcher) relative(name string) (string, bool) {
+	rel, err := filepath.Rel(w.root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+
+		string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// reported reports whether the events of rel pass the filters.
+func (w *Watcher) reported(rel string) bool {
+	if rel == "." || w.exclude.Match(rel) {
+		return false
+	}
+
+	return len(w.include) == 0 ||
+		slices.ContainsFunc(w.include, func(p *pathmatch.Pattern) bool {
+			return p.Match(rel)
+		})
+}
@@ -0,0 +1,142 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/testx"
+	"example.com/go-template/util/watch"
+)
+
+// batches runs w and returns the channel of its batches.
+func batches(t *testing.T, w *watch.Watcher) <-chan []watch.Event {
+	t.Helper()
+
+	ch := make(chan []watch.Event, 16)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.Run(t.Context(), func(events []watch.Event) { ch <- events })
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, w.Close())
+		require.NoError(t, <-done)
+	})
+
+	return ch
+}
+
+// next returns the next batch or fails after a timeout.
+func next(t *testing.T, ch <-chan []watch.Event) []watch.Event {
+	t.Helper()
+
+	select {
+	case events := <-ch:
+		return events
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no events")
+
+		return nil
+	}
+}
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"main.go":             "package main",
+		"README.md":           "docs",
+		"node_modules/x/a.go": "package x",
+	})
+
+	w, err := watch.New(dir, watch.WithInclude("**/*.go"),
+		watch.WithDebounce(50*time.Millisecond))
+	require.NoError(t, err)
+
+	ch := batches(t, w)
+
+	write(t, dir, "main.go", "package main // v2")
+	write(t, dir, "README.md", "ignored")
+	write(t, dir, "node_modules/x/a.go", "ignored")
+
+	events := next(t, ch)
+	require.Len(t, events, 1)
+	assert.Equal(t, "main.go", events[0].Path)
+	assert.NotZero(t, events[0].Op&watch.Write)
+
+	write(t, dir, "pkg/sub/new.go", "package sub")
+
+	testx.RequireEventually(t, func() bool {
+		for _, event := range next(t, ch) {
+			if event.Path == "pkg/sub/new.go" {
+				return true
+			}
+		}
+
+		return false
+	}, 5*time.Second)
+
+	write(t, dir, "pkg/sub/later.go", "package sub")
+	assert.Contains(t, next(t, ch), watch.Event{
+		Path: "pkg/sub/later.go", Op: watch.Create | watch.Write,
+	})
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "main.go")))
+	assert.Equal(t, []watch.Event{{Path: "main.go", Op: watch.Remove}},
+		next(t, ch))
+}
+
+func TestWatchExclude(t *testing.T) {
+	t.Parallel()
+
+	dir := testx.TempDirWithFiles(t, map[string]string{
+		"build/out.txt": "x", "src/in.txt": "x",
+	})
+
+	w, err := watch.New(dir, watch.WithExclude("build/", "*.tmp"),
+		watch.WithDebounce(20*time.Millisecond))
+	require.NoError(t, err)
+
+	ch := batches(t, w)
+
+	write(t, dir, "build/out.txt", "y")
+	write(t, dir, "src/edit.tmp", "y")
+	write(t, dir, "src/in.txt", "y")
+
+	events := next(t, ch)
+	require.Len(t, events, 1)
+	assert.Equal(t, "src/in.txt", events[0].Path)
+}
+
+func TestNewErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := watch.New(t.TempDir(), watch.WithInclude("[bad"))
+	require.Error(t, err)
+
+	w, err := watch.New(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err, "a missing root has nothing to watch yet")
+	require.NoError(t, w.Close())
+}
+
+func TestOpString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "create|write", (watch.Create | watch.Write).String())
+	assert.Equal(t, "chmod", watch.Chmod.String())
+	assert.Empty(t, watch.Op(0).String())
+}
@@ -0,0 +1,344 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"example.com/go-template/util/fsx"
+)
+
+// DefaultWorkspacePattern names the directories of TempWorkspace, as
+// os.MkdirTemp does.
+const DefaultWorkspacePattern = "workspace-*"
+
+var (
+	// ErrWorkspaceOwner is returned by TempWorkspace for owners that are
+	// neither a context nor a Cleaner.
+	ErrWorkspaceOwner = errors.New("util: unsupported workspace owner")
+	// ErrWorkspaceClosed is returned once a workspace is removed or
+	// promoted.
+	ErrWorkspaceClosed = errors.New("util: workspace closed")
+	// ErrOutsideWorkspace is returned for names escaping the workspace.
+	ErrOutsideWorkspace = errors.New("util: name outside of the workspace")
+)
+
+// Cleaner registers functions to run when its owner is done, as testing.TB
+// does.
+type Cleaner interface {
+	// Cleanup registers fn to run when the owner is done.
+	Cleanup(fn func())
+}
+
+// WorkspaceOption configures TempWorkspace.
+type WorkspaceOption func(*workspaceSettings)
+
+type workspaceSettings struct {
+	parent  string
+	pattern string
+}
+
+// WithWorkspaceParent creates the workspace in dir instead of os.TempDir.
+// Promote renames the workspace into place when dir is on the file system
+// of the destination, and has to copy it otherwise.
+func WithWorkspaceParent(dir string) WorkspaceOption {
+	return func(s *workspaceSettings) {
+		s.parent = dir
+	}
+}
+
+// WithWorkspacePattern names the workspace directory, as os.MkdirTemp
+// does, DefaultWorkspacePattern by default.
+func WithWorkspacePattern(pattern string) WorkspaceOption {
+	return func(s *workspaceSettings) {
+		s.pattern = pattern
+	}
+}
+
+// liveWorkspaces holds the workspaces to remove on RemoveTempWorkspaces.
+var liveWorkspaces = struct {
+	sync.Mutex
+	set map[*Workspace]struct{}
+}{set: map[*Workspace]struct{}{}}
+
+// Workspace is an isolated temporary directory tracking the files created
+// in it, removed by Close or when its owner is done, unless its content is
+// promoted to a destination first. It is safe for concurrent use.
+type Workspace struct {
+	dir string
+
+	mu     sync.Mutex
+	files  []string
+	closed bool
+	stop   func() bool
+}
+
+// TempWorkspace creates a workspace bound to owner: a context.Context
+// removes it once done, and a Cleaner such as testing.TB removes it at
+// cleanup, *Cleanups also reporting the removal errors. A nil owner
+// leaves the removal to Close and RemoveTempWorkspaces.
+func TempWorkspace(owner any, opts ...WorkspaceOption) (*Workspace, error) {
+	switch owner.(type) {
+	case nil, context.Context, Cleaner:
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrWorkspaceOwner, owner)
+	}
+
+	s := workspaceSettings{pattern: DefaultWorkspacePattern}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	dir, err := os.MkdirTemp(s.parent, s.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("util: %w", err)
+	}
+
+	ws := &Workspace{dir: dir}
+
+	liveWorkspaces.Lock()
+	liveWorkspaces.set[ws] = struct{}{}
+	liveWorkspaces.Unlock()
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	switch owner := owner.(type) {
+	case context.Context:
+		ws.stop = context.AfterFunc(owner, func() { _ = ws.Close() })
+	case *Cleanups:
+		owner.Defer(ws.Close)
+	case Cleaner:
+		owner.Cleanup(func() { _ = ws.Close() })
+	}
+
+	return ws, nil
+}
+
+// RemoveTempWorkspaces removes the workspaces neither closed nor promoted
+// yet. Go runs no hook when the process exits: call it deferred from main,
+// or from a lifecycle stop hook, so that interrupted runs leave no
+// temporary directories behind.
+func RemoveTempWorkspaces() error {
+	liveWorkspaces.Lock()
+	live := slices.Collect(maps.Keys(liveWorkspaces.set))
+	liveWorkspaces.Unlock()
+
+	errs := make([]error, 0, len(live))
+	for _, ws := range live {
+		errs = append(errs, ws.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Dir returns the path of the workspace.
+func (ws *Workspace) Dir() string {
+	return ws.dir
+}
+
+// Path returns the path of name, relative to the workspace.
+func (ws *Workspace) Path(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideWorkspace, name)
+	}
+
+	return filepath.Join(ws.dir, name), nil
+}
+
+// Files returns the names of the tracked files in creation order.
+func (ws *Workspace) Files() []string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return slices.Clone(ws.files)
+}
+
+// Track records name as created in the workspace, such as by an external
+// tool.
+func (ws *Workspace) Track(name string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	_, err := ws.record(name)
+
+	return err
+}
+
+// Create creates or truncates the file name, and its parent directories,
+// as os.Create does.
+func (ws *Workspace) Create(name string) (*os.File, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	path, err := ws.record(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("util: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("util: %w", err)
+	}
+
+	return file, nil
+}
+
+// WriteFile writes data to the file name, creating its parent directories.
+func (ws *Workspace) WriteFile(
+	name string, data []byte, perm fs.FileMode,
+) error {
+	file, err := ws.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	return nil
+}
+
+// Promote moves the content of the workspace to dst with the permissions
+// perm, replacing dst as a whole, and closes the workspace. Readers of dst
+// observe the previous content until it is renamed into place, which is
+// restored on failure.
+func (ws *Workspace) Promote(dst string, perm fs.FileMode) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed {
+		return ErrWorkspaceClosed
+	}
+
+	if err := os.Chmod(ws.dir, perm.Perm()); err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	if err := promote(ws.dir, dst); err != nil {
+		return err
+	}
+
+	return ws.release()
+}
+
+// Close removes the workspace. Closing it again does nothing.
+func (ws *Workspace) Close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.release()
+}
+
+// record tracks name and returns its path.
+func (ws *Workspace) record(name string) (string, error) {
+	if ws.closed {
+		return "", ErrWorkspaceClosed
+	}
+
+	path, err := ws.Path(name)
+	if err != nil {
+		return "", err
+	}
+
+	if name = filepath.Clean(name); !slices.Contains(ws.files, name) {
+		ws.files = append(ws.files, name)
+	}
+
+	return path, nil
+}
+
+// release removes the directory, if not promoted, and forgets the
+// workspace.
+func (ws *Workspace) release() error {
+	if ws.closed {
+		return nil
+	}
+
+	ws.closed = true
+	if ws.stop != nil {
+		ws.stop()
+	}
+
+	liveWorkspaces.Lock()
+	delete(liveWorkspaces.set, ws)
+	liveWorkspaces.Unlock()
+
+	if err := os.RemoveAll(ws.dir); err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	return nil
+}
+
+// promote moves the directory src to dst through a staging directory next
+// to dst, which also keeps the previous dst until the end. On failure, src
+// is left in place.
+func promote(src, dst string) error {
+	staging, err := os.MkdirTemp(filepath.Dir(dst),
+		"."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("util: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	staged := filepath.Join(staging, "next")
+
+	if err := os.Rename(src, staged); err != nil {
+		// src is on another file system.
+		if err := fsx.CopyDir(src, staged); err != nil {
+			return err
+		}
+	} else {
+		defer func() {
+			if err != nil {
+				_ = os.Rename(staged, src)
+			}
+		}()
+	}
+
+	err = replaceDir(staged, dst, filepath.Join(staging, "previous"))
+
+	return err
+}
+
+// replaceDir renames src to dst, moving an existing dst to previous and
+// restoring it on failure.
+func replaceDir(src, dst, previous string) error {
+	err := os.Rename(dst, previous)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("util: %w", err)
+	}
+
+	replaced := err == nil
+
+	if err := os.Rename(src, dst); err != nil {
+		if replaced {
+			_ = os.Rename(previous, dst)
+		}
+
+		return fmt.Errorf("util: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package util_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util"
+	"example.com/go-template/util/testx"
+)
+
+func TestTempWorkspace(t *testing.T) {
+	t.Parallel()
+
+	ws, err := util.TempWorkspace(t, util.WithWorkspaceParent(t.TempDir()),
+		util.WithWorkspacePattern("extract-*"))
+	require.NoError(t, err)
+	assert.Contains(t, filepath.Base(ws.Dir()), "extract-")
+
+	require.NoError(t, ws.WriteFile("bin/tool", []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, ws.WriteFile("README", []byte("tool"), 0o644))
+
+	file, err := ws.Create("bin/../bin/tool")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.NoError(t, ws.Track("share/doc"))
+	assert.Equal(t, []string{"bin/tool", "README", "share/doc"}, ws.Files())
+
+	info, err := os.Stat(filepath.Join(ws.Dir(), "bin", "tool"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	_, err = ws.Path("../escape")
+	require.ErrorIs(t, err, util.ErrOutsideWorkspace)
+	require.ErrorIs(t, ws.WriteFile("/etc/passwd", nil, 0o600),
+		util.ErrOutsideWorkspace)
+
+	require.NoError(t, ws.Close())
+	require.NoError(t, ws.Close())
+	assert.NoDirExists(t, ws.Dir())
+	require.ErrorIs(t, ws.Track("late"), util.ErrWorkspaceClosed)
+	require.ErrorIs(t, ws.Promote(t.TempDir(), 0o755), util.ErrWorkspaceClosed)
+}
+
+func TestTempWorkspaceOwners(t *testing.T) {
+	t.Parallel()
+
+	var dir string
+
+	t.Run("cleanup", func(t *testing.T) {
+		ws, err := util.TempWorkspace(t)
+		require.NoError(t, err)
+
+		dir = ws.Dir()
+		assert.DirExists(t, dir)
+	})
+	assert.NoDirExists(t, dir, "removed at the cleanup of the test")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	ws, err := util.TempWorkspace(ctx)
+	require.NoError(t, err)
+	assert.DirExists(t, ws.Dir())
+
+	cancel()
+	testx.RequireEventually(t, func() bool {
+		_, err := os.Stat(ws.Dir())
+
+		return os.IsNotExist(err)
+	}, time.Second)
+
+	_, err = util.TempWorkspace("owner")
+	require.ErrorIs(t, err, util.ErrWorkspaceOwner)
+}
+
+//nolint:paralleltest // Removes the workspaces of the whole process.
+func TestRemoveTempWorkspaces(t *testing.T) {
+	parent := t.TempDir()
+
+	first, err := util.TempWorkspace(nil, util.WithWorkspaceParent(parent))
+	require.NoError(t, err)
+
+	second, err := util.TempWorkspace(nil, util.WithWorkspaceParent(parent))
+	require.NoError(t, err)
+	require.NoError(t, second.Close())
+
+	require.NoError(t, util.RemoveTempWorkspaces())
+	assert.NoDirExists(t, first.Dir())
+
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestWorkspacePromote(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dst := filepath.Join(root, "go")
+
+	install := func(version string) {
+		t.Helper()
+
+		ws, err := util.TempWorkspace(t, util.WithWorkspaceParent(root))
+		require.NoError(t, err)
+		require.NoError(t, ws.WriteFile("VERSION", []byte(version), 0o644))
+		require.NoError(t, ws.Promote(dst, 0o755))
+		assert.NoDirExists(t, ws.Dir())
+	}
+
+	install("go1.25")
+	require.NoError(t, os.WriteFile(filepath.Join(dst, "stale"), nil, 0o600))
+	install("go1.26")
+
+	version, err := os.ReadFile(filepath.Join(dst, "VERSION"))
+	require.NoError(t, err)
+	assert.Equal(t, "go1.26", string(version))
+	assert.NoFileExists(t, filepath.Join(dst, "stale"),
+		"the destination is replaced as a whole")
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no staging directory is left")
+}
+
+func TestWorkspacePromoteFailure(t *testing.T) {
+	t.Parallel()
+
+	ws, err := util.TempWorkspace(t)
+	require.NoError(t, err)
+	require.NoError(t, ws.WriteFile("data", []byte("kept"), 0o600))
+
+	missing := filepath.Join(t.TempDir(), "missing", "dst")
+	require.Error(t, ws.Promote(missing, 0o700))
+
+	data, err := os.ReadFile(filepath.Join(ws.Dir(), "data"))
+	require.NoError(t, err)
+	assert.Equal(t, "kept", string(data), "the workspace is left intact")
+}
@@ -0,0 +1,69 @@
+package yamlx
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// CloneNode returns a deep copy of node. Anchors and aliases are preserved:
+// every alias in the copy points to the copied anchor rather than back
+// into the original tree, so the copy can be edited and re-encoded on its
+// own. Shared and recursive structures are copied once.
+func CloneNode(node *yaml.Node) *yaml.Node {
+	return copyNode(node, make(map[*yaml.Node]*yaml.Node))
+}
+
+func copyNode(node *yaml.Node, seen map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	if clone, ok := seen[node]; ok {
+		return clone
+	}
+
+	clone := new(yaml.Node)
+	*clone = *node
+	seen[node] = clone
+
+	clone.Alias = copyNode(node.Alias, seen)
+
+	if node.Content != nil {
+		clone.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			clone.Content[i] = copyNode(child, seen)
+		}
+	}
+
+	return clone
+}
+
+// DeepCopy copies the maps and slices of a value decoded into any. Aliases
+// decode to values shared between every place they appear, so editing one
+// occurrence silently edits the others; copying first keeps them apart.
+func DeepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = DeepCopy(item)
+		}
+
+		return result
+	case map[any]any:
+		result := make(map[any]any, len(v))
+		for key, item := range v {
+			result[key] = DeepCopy(item)
+		}
+
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = DeepCopy(item)
+		}
+
+		return result
+	default:
+		return v
+	}
+}
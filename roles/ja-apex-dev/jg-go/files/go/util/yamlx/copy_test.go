@@ -0,0 +1,63 @@
+package yamlx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"example.com/go-template/util/yamlx"
+)
+
+func TestCloneNode(t *testing.T) {
+	t.Parallel()
+
+	input := "base: &base\n  port: 80\nwith: *base\n"
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(input), &root))
+
+	clone := yamlx.CloneNode(&root)
+	mapping := clone.Content[0]
+	anchor, alias := mapping.Content[1], mapping.Content[3]
+
+	assert.Same(t, anchor, alias.Alias, "aliases point into the copy")
+	assert.NotSame(t, root.Content[0].Content[1], anchor)
+
+	anchor.Content[1].Value = "8080"
+
+	edited, err := yaml.Marshal(clone)
+	require.NoError(t, err)
+	assert.Equal(t, "base: &base\n    port: 8080\nwith: *base\n",
+		string(edited))
+
+	original, err := yaml.Marshal(&root)
+	require.NoError(t, err)
+	assert.Contains(t, string(original), "port: 80\n")
+
+	assert.Nil(t, yamlx.CloneNode(nil))
+}
+
+func TestDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	var value map[string]any
+	require.NoError(t, yaml.Unmarshal(
+		[]byte("a: &x {list: [1]}\nb: *x\n"), &value))
+
+	copied, ok := yamlx.DeepCopy(value).(map[string]any)
+	require.True(t, ok)
+
+	b, ok := copied["b"].(map[string]any)
+	require.True(t, ok)
+
+	list, ok := b["list"].([]any)
+	require.True(t, ok)
+
+	b["extra"] = true
+	list[0] = 2
+
+	assert.Equal(t, map[string]any{"list": []any{1}}, copied["a"])
+	assert.Equal(t, map[string]any{"list": []any{1}}, value["b"])
+}
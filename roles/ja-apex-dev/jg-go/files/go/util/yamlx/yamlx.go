@@ -0,0 +1,147 @@
+// Package yamlx mirrors the util JSON helpers for YAML: strict decoding,
+// multi-document streams, node cloning and conversion to JSON.
+package yamlx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrTrailingData is returned by DecodeStrict when the input holds more
+// than one non-empty document.
+var ErrTrailingData = errors.New("yamlx: trailing data after YAML document")
+
+// DecodeStrict decodes a single YAML document from r into v, failing on
+// unknown struct fields and on further non-empty documents.
+func DecodeStrict(r io.Reader, v any) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("yamlx: decode YAML: %w", err)
+	}
+
+	for {
+		var next yaml.Node
+
+		err := decoder.Decode(&next)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil || !isEmpty(&next) {
+			return ErrTrailingData
+		}
+	}
+}
+
+// UnmarshalStrict is DecodeStrict for in-memory data.
+func UnmarshalStrict(data []byte, v any) error {
+	return DecodeStrict(bytes.NewReader(data), v)
+}
+
+// DecodeAll strictly decodes every document of a "---" separated stream,
+// such as a bundle of Kubernetes manifests. Empty documents, including the
+// one left by a trailing separator, are skipped.
+func DecodeAll[T any](r io.Reader) ([]T, error) {
+	decoder := yaml.NewDecoder(r)
+
+	var documents []T
+
+	for index := 0; ; index++ {
+		var node yaml.Node
+
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("yamlx: document %d: %w", index, err)
+		}
+
+		if isEmpty(&node) {
+			continue
+		}
+
+		var document T
+		if err := decodeNodeStrict(&node, &document); err != nil {
+			return nil, fmt.Errorf("yamlx: document %d: %w", index, err)
+		}
+
+		documents = append(documents, document)
+	}
+}
+
+// decodeNodeStrict decodes node with unknown field checks, which
+// yaml.Node.Decode does not support.
+func decodeNodeStrict(node *yaml.Node, v any) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	return decoder.Decode(v)
+}
+
+func isEmpty(node *yaml.Node) bool {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+
+	return node.Kind == 0 || node.Kind == yaml.DocumentNode ||
+		(node.Kind == yaml.ScalarNode && node.Tag == "!!null")
+}
+
+// YAMLToJSON converts a YAML document to JSON. Aliases and merge keys are
+// resolved, non-string mapping keys are formatted as strings and object
+// keys are sorted.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("yamlx: decode YAML: %w", err)
+	}
+
+	encoded, err := json.Marshal(jsonValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("yamlx: encode JSON: %w", err)
+	}
+
+	return encoded, nil
+}
+
+// jsonValue rewrites the map[any]any produced for non-string keys into
+// maps encoding/json accepts.
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = jsonValue(item)
+		}
+
+		return result
+	case map[string]any:
+		for key, item := range v {
+			v[key] = jsonValue(item)
+		}
+
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
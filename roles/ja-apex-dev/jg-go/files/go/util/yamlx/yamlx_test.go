@@ -0,0 +1,77 @@
+package yamlx_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/go-template/util/yamlx"
+)
+
+type service struct {
+	Name string `yaml:"name"`
+	Port int    `yaml:"port"`
+}
+
+func TestDecodeStrict(t *testing.T) {
+	t.Parallel()
+
+	var s service
+	require.NoError(t, yamlx.DecodeStrict(
+		strings.NewReader("name: api\nport: 80\n---\n"), &s))
+	assert.Equal(t, service{Name: "api", Port: 80}, s)
+
+	err := yamlx.UnmarshalStrict([]byte("name: api\nprot: 80\n"), &s)
+	require.ErrorContains(t, err, "field prot not found")
+
+	err = yamlx.UnmarshalStrict([]byte("name: a\n---\nname: b\n"), &s)
+	require.ErrorIs(t, err, yamlx.ErrTrailingData)
+
+	require.NoError(t, yamlx.UnmarshalStrict(nil, &s))
+}
+
+func TestDecodeAll(t *testing.T) {
+	t.Parallel()
+
+	input := "---\nname: a\nport: 1\n---\n---\nname: b\nport: 2\n---\n"
+
+	services, err := yamlx.DecodeAll[service](strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []service{{"a", 1}, {"b", 2}}, services)
+
+	_, err = yamlx.DecodeAll[service](
+		strings.NewReader("name: a\n---\nname: b\nextra: 1\n"))
+	require.ErrorContains(t, err, "yamlx: document 1")
+
+	_, err = yamlx.DecodeAll[service](strings.NewReader("name: [\n"))
+	require.ErrorContains(t, err, "yamlx: document 0")
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	t.Parallel()
+
+	input := `
+defaults: &defaults
+  replicas: 2
+  labels: [web]
+app:
+  <<: *defaults
+  name: api
+ports:
+  80: http
+  443: https
+`
+
+	data, err := yamlx.YAMLToJSON([]byte(input))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"defaults": {"replicas": 2, "labels": ["web"]},
+		"app": {"replicas": 2, "labels": ["web"], "name": "api"},
+		"ports": {"80": "http", "443": "https"}
+	}`, string(data))
+
+	_, err = yamlx.YAMLToJSON([]byte("a: ["))
+	require.Error(t, err)
+}